@@ -9,7 +9,10 @@ require (
 	github.com/gookit/event v1.2.0
 	github.com/jackc/pgx/v5 v5.7.5
 	github.com/joho/godotenv v1.5.1
+	github.com/robfig/cron/v3 v3.0.1
 	github.com/rs/zerolog v1.34.0
+	go.etcd.io/bbolt v1.4.3
+	golang.org/x/text v0.24.0
 )
 
 require (
@@ -23,5 +26,4 @@ require (
 	golang.org/x/crypto v0.37.0 // indirect
 	golang.org/x/sync v0.13.0 // indirect
 	golang.org/x/sys v0.32.0 // indirect
-	golang.org/x/text v0.24.0 // indirect
 )
@@ -0,0 +1,205 @@
+// Command apigen writes the Go client of the REST API from its route table, run through go generate
+// in internal/apiclient whenever a route changes
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/format"
+	"log"
+	"os"
+	"path"
+	"provisioning-assistant/internal/api"
+	"reflect"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+const clientPackage = "apiclient"
+
+func main() {
+	out := flag.String("out", "client_gen.go", "arquivo gerado com os métodos do cliente")
+	flag.Parse()
+
+	source, err := generate(api.Routes())
+	if err != nil {
+		log.Fatalf("falha ao gerar cliente da API: %v", err)
+	}
+
+	if err := os.WriteFile(*out, source, 0o644); err != nil {
+		log.Fatalf("falha ao escrever cliente da API: %v", err)
+	}
+}
+
+// generator accumulates the methods of the client and the packages they reference
+type generator struct {
+	body    bytes.Buffer
+	imports map[string]bool
+}
+
+// generate writes one method per route called by the internal consumers, the external ones are left out
+func generate(routes []api.Route) ([]byte, error) {
+	g := &generator{imports: map[string]bool{"context": true}}
+
+	for _, route := range routes {
+		if route.External {
+			continue
+		}
+		g.method(route)
+	}
+
+	var source bytes.Buffer
+	fmt.Fprintf(&source, "// Code generated by apigen from the routes of internal/api. DO NOT EDIT.\n\npackage %s\n\nimport (\n", clientPackage)
+	for _, pkg := range slices.Sorted(mapKeys(g.imports)) {
+		fmt.Fprintf(&source, "\t%q\n", pkg)
+	}
+	source.WriteString(")\n")
+	source.Write(g.body.Bytes())
+
+	return format.Source(source.Bytes())
+}
+
+// method writes the client method of a route, with a struct for its query parameters when it takes any
+func (g *generator) method(route api.Route) {
+	path, params := api.PathParams(route.Path)
+
+	args := []string{"ctx context.Context"}
+	for _, param := range params {
+		args = append(args, param+" string")
+	}
+
+	query := "nil"
+	if len(route.Query) > 0 {
+		g.imports["net/url"] = true
+		args = append(args, "query "+route.Operation+"Query")
+		query = "query.values()"
+		g.queryType(route)
+	}
+
+	body := "nil"
+	if route.Request != nil {
+		args = append(args, "body "+g.typeName(reflect.TypeOf(route.Request)))
+		body = "body"
+	}
+
+	result := ""
+	switch {
+	case route.Response != nil:
+		result = g.typeName(reflect.TypeOf(route.Response))
+	case route.Media != "":
+		result = "string"
+	}
+
+	fmt.Fprintf(&g.body, "\n// %s calls %s %s. %s\n", route.Operation, route.Method, route.Path, sentence(route.Summary))
+	if result == "" {
+		fmt.Fprintf(&g.body, "func (c *Client) %s(%s) error {\n", route.Operation, strings.Join(args, ", "))
+		fmt.Fprintf(&g.body, "\treturn c.do(ctx, %q, %s, %s, %s, nil)\n}\n", route.Method, g.pathExpr(path, params), query, body)
+		return
+	}
+
+	fmt.Fprintf(&g.body, "func (c *Client) %s(%s) (%s, error) {\n", route.Operation, strings.Join(args, ", "), result)
+	fmt.Fprintf(&g.body, "\tvar out %s\n", result)
+	fmt.Fprintf(&g.body, "\terr := c.do(ctx, %q, %s, %s, %s, &out)\n", route.Method, g.pathExpr(path, params), query, body)
+	g.body.WriteString("\treturn out, err\n}\n")
+}
+
+// queryType writes the struct holding the query parameters of a route, a zero field is not sent
+func (g *generator) queryType(route api.Route) {
+	name := route.Operation + "Query"
+
+	fmt.Fprintf(&g.body, "\n// %s holds the query parameters of %s, a zero field is not sent\ntype %s struct {\n", name, route.Operation, name)
+	for _, param := range route.Query {
+		kind := "string"
+		if param.Integer {
+			kind = "int"
+		}
+		fmt.Fprintf(&g.body, "\t%s %s\n", exported(param.Name), kind)
+	}
+	g.body.WriteString("}\n")
+
+	fmt.Fprintf(&g.body, "\nfunc (q %s) values() url.Values {\n\tvalues := url.Values{}\n", name)
+	for _, param := range route.Query {
+		field := exported(param.Name)
+		if param.Integer {
+			g.imports["strconv"] = true
+			fmt.Fprintf(&g.body, "\tif q.%s != 0 {\n\t\tvalues.Set(%q, strconv.Itoa(q.%s))\n\t}\n", field, param.Name, field)
+			continue
+		}
+		fmt.Fprintf(&g.body, "\tif q.%s != \"\" {\n\t\tvalues.Set(%q, q.%s)\n\t}\n", field, param.Name, field)
+	}
+	g.body.WriteString("\treturn values\n}\n")
+}
+
+// pathExpr builds the expression of the request path, escaping each wildcard
+func (g *generator) pathExpr(path string, params []string) string {
+	if len(params) == 0 {
+		return fmt.Sprintf("%q", path)
+	}
+
+	g.imports["net/url"] = true
+	var parts []string
+	rest := path
+	for _, param := range params {
+		before, after, _ := strings.Cut(rest, "{"+param+"}")
+		if before != "" {
+			parts = append(parts, fmt.Sprintf("%q", before))
+		}
+		parts = append(parts, "url.PathEscape("+param+")")
+		rest = after
+	}
+	if rest != "" {
+		parts = append(parts, fmt.Sprintf("%q", rest))
+	}
+	return strings.Join(parts, " + ")
+}
+
+// typeName writes a type as referenced from the client package, importing the package declaring it
+func (g *generator) typeName(t reflect.Type) string {
+	if t.Name() != "" {
+		if t.PkgPath() == "" {
+			return t.Name()
+		}
+		g.imports[t.PkgPath()] = true
+		return path.Base(t.PkgPath()) + "." + t.Name()
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		return "*" + g.typeName(t.Elem())
+	case reflect.Slice:
+		return "[]" + g.typeName(t.Elem())
+	case reflect.Map:
+		return "map[" + g.typeName(t.Key()) + "]" + g.typeName(t.Elem())
+	case reflect.Interface:
+		return "any"
+	default:
+		return t.String()
+	}
+}
+
+// exported turns a parameter name into the name of a struct field
+func exported(name string) string {
+	runes := []rune(name)
+	runes[0] = unicode.ToUpper(runes[0])
+	return string(runes)
+}
+
+// sentence ends the summary of a route with a period
+func sentence(summary string) string {
+	if summary == "" || strings.HasSuffix(summary, ".") {
+		return summary
+	}
+	return summary + "."
+}
+
+func mapKeys(m map[string]bool) func(yield func(string) bool) {
+	return func(yield func(string) bool) {
+		for key := range m {
+			if !yield(key) {
+				return
+			}
+		}
+	}
+}
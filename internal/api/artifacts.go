@@ -0,0 +1,54 @@
+package api
+
+import (
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"provisioning-assistant/internal/domain"
+	"provisioning-assistant/internal/services"
+	"strconv"
+)
+
+// handleGetArtifact streams a generated file to the holder of a signed retrieval link, no token needed
+func (s *Server) handleGetArtifact(w http.ResponseWriter, r *http.Request) {
+	key := r.PathValue("key")
+	query := r.URL.Query()
+
+	if err := s.artifacts.VerifyLink(key, query.Get("expires"), query.Get("signature")); err != nil {
+		status := http.StatusForbidden
+		if errors.Is(err, services.ErrArtifactLinkExpired) {
+			status = http.StatusGone
+		}
+		s.logger.WithError(err).WithField("key", key).Warn("Acesso a artefato negado")
+		s.writeError(w, status, err)
+		return
+	}
+
+	reader, artifact, err := s.artifacts.Open(r.Context(), key)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, domain.ErrArtifactNotFound) {
+			status = http.StatusNotFound
+		}
+		s.writeError(w, status, err)
+		return
+	}
+	defer reader.Close()
+
+	contentType := artifact.ContentType
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": artifact.Name()}))
+	if artifact.Size > 0 {
+		w.Header().Set("Content-Length", strconv.FormatInt(artifact.Size, 10))
+	}
+	w.WriteHeader(http.StatusOK)
+
+	if _, err := io.Copy(w, reader); err != nil {
+		s.logger.WithError(err).WithField("key", key).Warn("Falha ao enviar artefato")
+	}
+}
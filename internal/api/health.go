@@ -0,0 +1,61 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"provisioning-assistant/internal/buildinfo"
+	"provisioning-assistant/internal/services"
+	"time"
+)
+
+const ReadinessCheckTimeout = 3 * time.Second
+
+// ReadinessCheck reports whether a dependency is able to serve traffic
+type ReadinessCheck func(ctx context.Context) error
+
+// Liveness is the answer of the liveness probe
+type Liveness struct {
+	Status string         `json:"status"`
+	Build  buildinfo.Info `json:"build"`
+}
+
+// Readiness is the answer of the readiness probe, each check with "ok" or its error
+type Readiness struct {
+	Checks   map[string]string      `json:"checks"`
+	Leader   map[string]bool        `json:"leader,omitempty"`
+	SafeMode []services.Degradation `json:"safe_mode,omitempty"`
+}
+
+// handleLiveness answers the liveness probe while the process is running, with the build serving it
+func (s *Server) handleLiveness(w http.ResponseWriter, r *http.Request) {
+	s.writeJSON(w, http.StatusOK, Liveness{Status: "ok", Build: buildinfo.Read()})
+}
+
+// handleReadiness runs every readiness check and reports the leadership state of background jobs and the
+// subsystems left off by the safe mode, which do not make the process unready
+func (s *Server) handleReadiness(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), ReadinessCheckTimeout)
+	defer cancel()
+
+	status := http.StatusOK
+	checks := make(map[string]string, len(s.readinessChecks))
+
+	for name, check := range s.readinessChecks {
+		if err := check(ctx); err != nil {
+			status = http.StatusServiceUnavailable
+			checks[name] = err.Error()
+			continue
+		}
+		checks[name] = "ok"
+	}
+
+	response := Readiness{Checks: checks}
+	if s.leaderService != nil {
+		response.Leader = s.leaderService.Status()
+	}
+	if s.safeMode != nil && s.safeMode.IsActive() {
+		response.SafeMode = s.safeMode.Degradations()
+	}
+
+	s.writeJSON(w, status, response)
+}
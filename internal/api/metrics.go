@@ -0,0 +1,75 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"provisioning-assistant/internal/services"
+	"strings"
+)
+
+const metricsPrefix = services.MetricsPrefix
+
+// handleMetrics exposes the conversation flow metrics and the operational gauges in the Prometheus
+// text format. They live in the process that talks to the technicians, an API-only process reports no sessions.
+func (s *Server) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	snapshot := s.flowMetrics.Snapshot()
+
+	var builder strings.Builder
+
+	writeMetricHeader(&builder, "flow_state_entries_total", "counter", "Sessions that entered a state")
+	for _, metrics := range snapshot.States {
+		fmt.Fprintf(&builder, "%sflow_state_entries_total{state=%q} %d\n", metricsPrefix, metrics.State, metrics.Entries)
+	}
+
+	writeMetricHeader(&builder, "flow_state_abandoned_total", "counter", "Sessions that expired in a state")
+	for _, metrics := range snapshot.States {
+		fmt.Fprintf(&builder, "%sflow_state_abandoned_total{state=%q} %d\n", metricsPrefix, metrics.State, metrics.Abandoned)
+	}
+
+	writeMetricHeader(&builder, "flow_state_invalid_inputs_total", "counter", "Inputs rejected in a state")
+	for _, metrics := range snapshot.States {
+		fmt.Fprintf(&builder, "%sflow_state_invalid_inputs_total{state=%q} %d\n", metricsPrefix, metrics.State, metrics.InvalidInputs)
+	}
+
+	writeMetricHeader(&builder, "flow_state_dwell_seconds", "summary", "Time spent in a state before leaving it")
+	for _, metrics := range snapshot.States {
+		fmt.Fprintf(&builder, "%sflow_state_dwell_seconds_sum{state=%q} %g\n", metricsPrefix, metrics.State, metrics.DwellTotal.Seconds())
+		fmt.Fprintf(&builder, "%sflow_state_dwell_seconds_count{state=%q} %d\n", metricsPrefix, metrics.State, metrics.Exits)
+	}
+
+	writeMetricHeader(&builder, "flow_state_dwell_max_seconds", "gauge", "Longest time spent in a state before leaving it")
+	for _, metrics := range snapshot.States {
+		fmt.Fprintf(&builder, "%sflow_state_dwell_max_seconds{state=%q} %g\n", metricsPrefix, metrics.State, metrics.DwellMax.Seconds())
+	}
+
+	writeMetricHeader(&builder, "flow_transitions_total", "counter", "State transitions made by sessions")
+	for _, transition := range snapshot.Transitions {
+		fmt.Fprintf(&builder, "%sflow_transitions_total{from=%q,to=%q} %d\n", metricsPrefix, transition.From, transition.To, transition.Count)
+	}
+
+	for _, gauge := range s.alertService.Gauges() {
+		writeMetricHeader(&builder, gauge.Name, "gauge", gauge.Help)
+		fmt.Fprintf(&builder, "%s%s %g\n", metricsPrefix, gauge.Name, gauge.Value)
+	}
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write([]byte(builder.String())); err != nil {
+		s.logger.WithError(err).Error("Falha ao escrever métricas")
+	}
+}
+
+// writeMetricHeader writes the HELP and TYPE lines of a metric family
+func writeMetricHeader(builder *strings.Builder, name, kind, help string) {
+	fmt.Fprintf(builder, "# HELP %s%s %s\n", metricsPrefix, name, help)
+	fmt.Fprintf(builder, "# TYPE %s%s %s\n", metricsPrefix, name, kind)
+}
+
+// handleAlertRules serves the alerting rules for the exported gauges as a Prometheus rules file
+func (s *Server) handleAlertRules(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/yaml; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write([]byte(s.alertService.PrometheusRules())); err != nil {
+		s.logger.WithError(err).Error("Falha ao escrever regras de alerta")
+	}
+}
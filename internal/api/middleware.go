@@ -0,0 +1,63 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"provisioning-assistant/internal/domain"
+	"provisioning-assistant/internal/services"
+	"strconv"
+	"strings"
+)
+
+type contextKey string
+
+const tokenContextKey contextKey = "service_account_token"
+
+// requireScope authenticates the bearer token and checks it grants the scope
+func (s *Server) requireScope(scope domain.Scope, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		plaintext, ok := bearerToken(r)
+		if !ok {
+			s.writeError(w, http.StatusUnauthorized, errors.New("token de acesso ausente"))
+			return
+		}
+
+		token, err := s.tokenService.Verify(r.Context(), plaintext, scope)
+		if err != nil {
+			s.logger.WithError(err).WithFields(map[string]any{
+				"path":  r.URL.Path,
+				"scope": scope,
+			}).Warn("Acesso à API negado")
+			s.writeError(w, http.StatusForbidden, err)
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), tokenContextKey, token)
+		next(w, r.WithContext(ctx))
+	}
+}
+
+// shed answers 503 to a non-critical report while the UNM is saturated, the client is told when to retry
+func (s *Server) shed(operation string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !s.loadShedder.Allow(operation) {
+			w.Header().Set("Retry-After", strconv.Itoa(int(services.DefaultShedCooldown.Seconds())))
+			s.writeError(w, http.StatusServiceUnavailable, services.ErrLoadShed)
+			return
+		}
+		next(w, r)
+	}
+}
+
+// bearerToken extracts the token from the Authorization header
+func bearerToken(r *http.Request) (string, bool) {
+	header := r.Header.Get("Authorization")
+
+	token, found := strings.CutPrefix(header, "Bearer ")
+	if !found || strings.TrimSpace(token) == "" {
+		return "", false
+	}
+
+	return strings.TrimSpace(token), true
+}
@@ -0,0 +1,226 @@
+package api
+
+import (
+	"net/http"
+	"provisioning-assistant/internal/buildinfo"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	openAPIVersion = "3.1.0"
+	openAPITitle   = "Assistente de provisionamento"
+	schemaPrefix   = "#/components/schemas/"
+)
+
+var (
+	timeType     = reflect.TypeFor[time.Time]()
+	durationType = reflect.TypeFor[time.Duration]()
+
+	// pathParamPattern finds the wildcards of a route, {key...} matching the rest of the path
+	pathParamPattern = regexp.MustCompile(`\{(\w+)(\.\.\.)?\}`)
+)
+
+// OpenAPI describes the routes of the API as an OpenAPI document, with the schemas read from the Go types
+// the handlers take and answer with
+func OpenAPI() map[string]any {
+	schemas := &schemaSet{components: make(map[string]any)}
+	paths := make(map[string]map[string]any)
+
+	for _, route := range Routes() {
+		path, params := PathParams(route.Path)
+
+		var parameters []any
+		for _, param := range params {
+			parameters = append(parameters, map[string]any{"name": param, "in": "path", "required": true, "schema": map[string]any{"type": "string"}})
+		}
+		for _, param := range route.Query {
+			parameters = append(parameters, param.describe("query"))
+		}
+		for _, param := range route.Headers {
+			parameters = append(parameters, param.describe("header"))
+		}
+
+		operation := map[string]any{
+			"operationId": route.Operation,
+			"summary":     route.Summary,
+			"responses":   route.responses(schemas),
+		}
+		if len(parameters) > 0 {
+			operation["parameters"] = parameters
+		}
+		if route.Scope != "" {
+			operation["security"] = []any{map[string]any{"bearer": []string{string(route.Scope)}}}
+		}
+		if route.Request != nil {
+			operation["requestBody"] = map[string]any{
+				"required": true,
+				"content":  map[string]any{"application/json": map[string]any{"schema": schemas.of(reflect.TypeOf(route.Request))}},
+			}
+		}
+
+		if paths[path] == nil {
+			paths[path] = make(map[string]any)
+		}
+		paths[path][strings.ToLower(route.Method)] = operation
+	}
+
+	return map[string]any{
+		"openapi": openAPIVersion,
+		"info":    map[string]any{"title": openAPITitle, "version": buildinfo.Read().Version},
+		"paths":   paths,
+		"components": map[string]any{
+			"schemas":         schemas.components,
+			"securitySchemes": map[string]any{"bearer": map[string]any{"type": "http", "scheme": "bearer"}},
+		},
+	}
+}
+
+// PathParams returns the path of a route as written in the OpenAPI document, along with its wildcards in order
+func PathParams(path string) (string, []string) {
+	var params []string
+	for _, match := range pathParamPattern.FindAllStringSubmatch(path, -1) {
+		params = append(params, match[1])
+	}
+	return pathParamPattern.ReplaceAllString(path, "{$1}"), params
+}
+
+// handleOpenAPI serves the OpenAPI document of the API
+func (s *Server) handleOpenAPI(w http.ResponseWriter, r *http.Request) {
+	s.writeJSON(w, http.StatusOK, OpenAPI())
+}
+
+// responses describes the success answer of the route and its errors
+func (route Route) responses(schemas *schemaSet) map[string]any {
+	success := map[string]any{"description": http.StatusText(route.Status)}
+	switch {
+	case route.Response != nil:
+		success["content"] = map[string]any{"application/json": map[string]any{"schema": schemas.of(reflect.TypeOf(route.Response))}}
+	case route.Media == "application/octet-stream":
+		success["content"] = map[string]any{route.Media: map[string]any{"schema": map[string]any{"type": "string", "format": "binary"}}}
+	case route.Media != "":
+		success["content"] = map[string]any{route.Media: map[string]any{"schema": map[string]any{"type": "string"}}}
+	}
+
+	responses := map[string]any{strconv.Itoa(route.Status): success}
+	for _, status := range route.Errors {
+		responses[strconv.Itoa(status)] = map[string]any{
+			"description": http.StatusText(status),
+			"content":     map[string]any{"application/json": map[string]any{"schema": schemas.of(reflect.TypeFor[ErrorResponse]())}},
+		}
+	}
+	return responses
+}
+
+// describe writes the parameter in the OpenAPI form
+func (p Parameter) describe(in string) map[string]any {
+	kind := "string"
+	if p.Integer {
+		kind = "integer"
+	}
+	return map[string]any{
+		"name":        p.Name,
+		"in":          in,
+		"description": p.Description,
+		"required":    p.Required,
+		"schema":      map[string]any{"type": kind},
+	}
+}
+
+// schemaSet collects the schemas of the named types referenced by the document
+type schemaSet struct {
+	components map[string]any
+}
+
+// of returns the schema of a type, named structs go to the components and are referenced
+func (s *schemaSet) of(t reflect.Type) map[string]any {
+	switch t {
+	case timeType:
+		return map[string]any{"type": "string", "format": "date-time"}
+	case durationType:
+		return map[string]any{"type": "integer", "description": "duração em nanossegundos"}
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		return s.of(t.Elem())
+	case reflect.Struct:
+		if t.Name() == "" {
+			return s.object(t)
+		}
+		if _, exists := s.components[t.Name()]; !exists {
+			// Reserved first so a type referencing itself does not recurse forever
+			s.components[t.Name()] = nil
+			s.components[t.Name()] = s.object(t)
+		}
+		return map[string]any{"$ref": schemaPrefix + t.Name()}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return map[string]any{"type": "string", "format": "byte"}
+		}
+		return map[string]any{"type": "array", "items": s.of(t.Elem())}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": s.of(t.Elem())}
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	default:
+		return map[string]any{}
+	}
+}
+
+// object describes the JSON fields of a struct, those always present as required
+func (s *schemaSet) object(t reflect.Type) map[string]any {
+	properties := make(map[string]any)
+	var required []string
+	s.fields(t, properties, &required)
+
+	schema := map[string]any{"type": "object", "properties": properties}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
+
+// fields adds the JSON fields of a struct, the ones of embedded structs without a name included
+func (s *schemaSet) fields(t reflect.Type, properties map[string]any, required *[]string) {
+	for i := range t.NumField() {
+		field := t.Field(i)
+		name, options, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Pointer {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				s.fields(embedded, properties, required)
+				continue
+			}
+		}
+
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		properties[name] = s.of(field.Type)
+		if !strings.Contains(options, "omitempty") && field.Type.Kind() != reflect.Pointer {
+			*required = append(*required, name)
+		}
+	}
+}
@@ -0,0 +1,216 @@
+package api
+
+import (
+	"net/http"
+	"provisioning-assistant/internal/domain"
+	"provisioning-assistant/internal/services"
+)
+
+// Route is an endpoint of the API. The table registers the handlers on the mux and is described by the
+// OpenAPI document, from which the client in internal/apiclient is generated.
+type Route struct {
+	Method    string
+	Path      string
+	Operation string
+	Summary   string
+
+	// Scope is the token scope required, empty for the endpoints without a bearer token
+	Scope domain.Scope
+
+	// Shed names the non-critical operation refused while the UNM is saturated
+	Shed string
+
+	Query   []Parameter
+	Headers []Parameter
+
+	// Request is the JSON body taken by the endpoint, nil when it takes none
+	Request any
+
+	// Response is the JSON answered on success, Media the content type of an answer in another format
+	Response any
+	Media    string
+	Status   int
+
+	// Errors are the other statuses of the endpoint, answered with an ErrorResponse
+	Errors []int
+
+	// External endpoints are called by the ERP or through signed links and are left out of the client
+	External bool
+
+	Handle func(s *Server, w http.ResponseWriter, r *http.Request)
+}
+
+// Parameter is a query parameter or header of a route
+type Parameter struct {
+	Name        string
+	Description string
+	Integer     bool
+	Required    bool
+}
+
+// Routes lists every endpoint of the API
+func Routes() []Route {
+	return []Route{
+		{
+			Method:    http.MethodGet,
+			Path:      "/healthz",
+			Operation: "Liveness",
+			Summary:   "Informa que o processo está no ar e a versão em execução",
+			Response:  Liveness{},
+			Status:    http.StatusOK,
+			Handle:    (*Server).handleLiveness,
+		},
+		{
+			Method:    http.MethodGet,
+			Path:      "/readyz",
+			Operation: "Readiness",
+			Summary:   "Executa as verificações de prontidão das dependências, com o mesmo corpo e status 503 quando alguma falha",
+			Response:  Readiness{},
+			Status:    http.StatusOK,
+			Handle:    (*Server).handleReadiness,
+		},
+		{
+			Method:    http.MethodGet,
+			Path:      "/metrics",
+			Operation: "Metrics",
+			Summary:   "Métricas do fluxo de conversa e indicadores operacionais no formato do Prometheus",
+			Scope:     domain.ScopeReadReports,
+			Media:     "text/plain",
+			Status:    http.StatusOK,
+			Errors:    []int{http.StatusUnauthorized, http.StatusForbidden},
+			Handle:    (*Server).handleMetrics,
+		},
+		{
+			Method:    http.MethodGet,
+			Path:      "/api/openapi.json",
+			Operation: "OpenAPI",
+			Summary:   "Documento OpenAPI desta API",
+			Response:  map[string]any{},
+			Status:    http.StatusOK,
+			Handle:    (*Server).handleOpenAPI,
+		},
+		{
+			Method:    http.MethodGet,
+			Path:      "/api/alerts/rules",
+			Operation: "AlertRules",
+			Summary:   "Regras de alerta dos indicadores exportados como arquivo de regras do Prometheus",
+			Scope:     domain.ScopeReadReports,
+			Media:     "application/yaml",
+			Status:    http.StatusOK,
+			Errors:    []int{http.StatusUnauthorized, http.StatusForbidden},
+			Handle:    (*Server).handleAlertRules,
+		},
+		{
+			Method:    http.MethodGet,
+			Path:      "/api/audits",
+			Operation: "ListAudits",
+			Summary:   "Lista os registros de auditoria do mais antigo ao mais recente, paginados pelo cursor after",
+			Scope:     domain.ScopeReadReports,
+			Query: []Parameter{
+				{Name: "after", Description: "ID do registro a partir do qual continuar"},
+				{Name: "limit", Description: "Quantidade máxima de registros", Integer: true},
+			},
+			Response: []*domain.AuditRecord{},
+			Status:   http.StatusOK,
+			Errors:   []int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden},
+			Handle:   (*Server).handleListAudits,
+		},
+		{
+			Method:    http.MethodGet,
+			Path:      "/api/audits/{id}",
+			Operation: "GetAudit",
+			Summary:   "Retorna um registro de auditoria",
+			Scope:     domain.ScopeReadReports,
+			Response:  &domain.AuditRecord{},
+			Status:    http.StatusOK,
+			Errors:    []int{http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound},
+			Handle:    (*Server).handleGetAudit,
+		},
+		{
+			Method:    http.MethodGet,
+			Path:      "/api/audits/{id}/attachments",
+			Operation: "GetAuditAttachments",
+			Summary:   "Lista as fotos da instalação de um registro de auditoria",
+			Scope:     domain.ScopeReadReports,
+			Response:  []domain.AuditAttachment{},
+			Status:    http.StatusOK,
+			Errors:    []int{http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound},
+			Handle:    (*Server).handleGetAuditAttachments,
+		},
+		{
+			Method:    http.MethodGet,
+			Path:      "/api/reports/olt-health",
+			Operation: "OltHealth",
+			Summary:   "Latência TL1 e falhas de cada OLT na última semana",
+			Scope:     domain.ScopeReadReports,
+			Shed:      services.ShedOltHealth,
+			Response:  &services.OltHealthReport{},
+			Status:    http.StatusOK,
+			Errors:    []int{http.StatusUnauthorized, http.StatusForbidden, http.StatusServiceUnavailable},
+			Handle:    (*Server).handleOltHealth,
+		},
+		{
+			Method:    http.MethodGet,
+			Path:      "/api/reports/pon-capacity",
+			Operation: "PonCapacity",
+			Summary:   "Crescimento da ocupação de cada PON com a data prevista de esgotamento",
+			Scope:     domain.ScopeReadReports,
+			Shed:      services.ShedPonCapacity,
+			Response:  &services.PonCapacityReport{},
+			Status:    http.StatusOK,
+			Errors:    []int{http.StatusUnauthorized, http.StatusForbidden, http.StatusServiceUnavailable},
+			Handle:    (*Server).handlePonCapacity,
+		},
+		{
+			Method:    http.MethodPost,
+			Path:      "/api/webhooks/erp",
+			Operation: "ErpWebhook",
+			Summary:   "Recebe uma alteração de protocolo enviada pelo ERP, assinada com o segredo compartilhado",
+			Headers: []Parameter{
+				{Name: "X-Erp-Timestamp", Description: "Momento do envio em segundos Unix", Required: true},
+				{Name: "X-Erp-Signature", Description: "sha256=<hex> do HMAC-SHA256 de \"<timestamp>.<corpo>\"", Required: true},
+			},
+			Request:  domain.ErpEvent{},
+			Response: WebhookReceipt{},
+			Status:   http.StatusAccepted,
+			Errors: []int{
+				http.StatusUnauthorized,
+				http.StatusRequestEntityTooLarge,
+				http.StatusUnprocessableEntity,
+				http.StatusServiceUnavailable,
+			},
+			External: true,
+			Handle:   (*Server).handleErpWebhook,
+		},
+		{
+			Method:    http.MethodGet,
+			Path:      services.ArtifactRoutePrefix + "{key...}",
+			Operation: "GetArtifact",
+			Summary:   "Baixa um arquivo gerado pelo link assinado, sem token",
+			Query: []Parameter{
+				{Name: "expires", Description: "Validade do link em segundos Unix", Integer: true, Required: true},
+				{Name: "signature", Description: "Assinatura do link", Required: true},
+			},
+			Media:    "application/octet-stream",
+			Status:   http.StatusOK,
+			Errors:   []int{http.StatusForbidden, http.StatusNotFound, http.StatusGone},
+			External: true,
+			Handle:   (*Server).handleGetArtifact,
+		},
+	}
+}
+
+// handler binds the route to the server, behind the load shedding and the token check it declares
+func (route Route) handler(s *Server) http.HandlerFunc {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		route.Handle(s, w, r)
+	}
+
+	if route.Shed != "" {
+		handler = s.shed(route.Shed, handler)
+	}
+	if route.Scope != "" {
+		handler = s.requireScope(route.Scope, handler)
+	}
+	return handler
+}
@@ -0,0 +1,203 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"provisioning-assistant/internal/domain"
+	"provisioning-assistant/internal/services"
+	"provisioning-assistant/internal/validation"
+	"strconv"
+	"time"
+)
+
+const (
+	ReadHeaderTimeout = 10 * time.Second
+	ShutdownTimeout   = 10 * time.Second
+)
+
+// ErrorResponse is the body of every answer outside the 2xx range
+type ErrorResponse struct {
+	Error    string              `json:"error"`
+	Problems validation.Problems `json:"problems,omitempty"`
+}
+
+// Server exposes the reporting REST API
+type Server struct {
+	httpServer      *http.Server
+	auditService    *services.AuditService
+	tokenService    *services.TokenService
+	leaderService   *services.LeaderService
+	artifacts       *services.ArtifactService
+	flowMetrics     *services.FlowMetrics
+	alertService    *services.AlertService
+	safeMode        *services.SafeModeService
+	ponCapacity     *services.PonCapacityService
+	erpWebhooks     *services.ErpWebhookService
+	loadShedder     *services.LoadShedder
+	readinessChecks map[string]ReadinessCheck
+	logger          domain.Logger
+}
+
+// NewServer creates a new reporting API server bound to the given address
+func NewServer(
+	addr string,
+	auditService *services.AuditService,
+	tokenService *services.TokenService,
+	leaderService *services.LeaderService,
+	artifacts *services.ArtifactService,
+	flowMetrics *services.FlowMetrics,
+	alertService *services.AlertService,
+	safeMode *services.SafeModeService,
+	ponCapacity *services.PonCapacityService,
+	erpWebhooks *services.ErpWebhookService,
+	loadShedder *services.LoadShedder,
+	readinessChecks map[string]ReadinessCheck,
+	logger domain.Logger,
+) *Server {
+	s := &Server{
+		auditService:    auditService,
+		tokenService:    tokenService,
+		leaderService:   leaderService,
+		artifacts:       artifacts,
+		flowMetrics:     flowMetrics,
+		alertService:    alertService,
+		safeMode:        safeMode,
+		ponCapacity:     ponCapacity,
+		erpWebhooks:     erpWebhooks,
+		loadShedder:     loadShedder,
+		readinessChecks: readinessChecks,
+		logger:          logger,
+	}
+
+	s.httpServer = &http.Server{
+		Addr:              addr,
+		Handler:           s.routes(),
+		ReadHeaderTimeout: ReadHeaderTimeout,
+	}
+
+	return s
+}
+
+// Start serves HTTP requests until the context is cancelled
+func (s *Server) Start(ctx context.Context) error {
+	errChan := make(chan error, 1)
+
+	go func() {
+		s.logger.Infof("API de relatórios ouvindo em %s", s.httpServer.Addr)
+		if err := s.httpServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			errChan <- err
+		}
+		close(errChan)
+	}()
+
+	select {
+	case err := <-errChan:
+		return err
+	case <-ctx.Done():
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), ShutdownTimeout)
+		defer cancel()
+		return s.httpServer.Shutdown(shutdownCtx)
+	}
+}
+
+// routes registers all API endpoints
+func (s *Server) routes() http.Handler {
+	mux := http.NewServeMux()
+	for _, route := range Routes() {
+		mux.HandleFunc(route.Method+" "+route.Path, route.handler(s))
+	}
+	return mux
+}
+
+// handleListAudits returns the audit records oldest first, paged with ?after=<id>&limit=<n>. When a page
+// is full the ID to resume from goes in the X-Next-After header.
+func (s *Server) handleListAudits(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	limit := 0
+	if value := query.Get("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed <= 0 {
+			s.writeError(w, http.StatusBadRequest, fmt.Errorf("limite inválido: %s", value))
+			return
+		}
+		limit = parsed
+	}
+
+	records, err := s.auditService.ListAfter(r.Context(), query.Get("after"), limit)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, services.ErrInvalidAuditCursor) {
+			status = http.StatusBadRequest
+		}
+		s.writeError(w, status, err)
+		return
+	}
+
+	if limit > 0 && len(records) == limit {
+		w.Header().Set("X-Next-After", records[len(records)-1].ID)
+	}
+	s.writeJSON(w, http.StatusOK, records)
+}
+
+// handleGetAudit returns a single audit record
+func (s *Server) handleGetAudit(w http.ResponseWriter, r *http.Request) {
+	record, err := s.auditService.GetRecord(r.Context(), r.PathValue("id"))
+	if err != nil {
+		s.writeError(w, http.StatusNotFound, err)
+		return
+	}
+
+	s.writeJSON(w, http.StatusOK, record)
+}
+
+// handleGetAuditAttachments returns the proof-of-installation files of an audit record
+func (s *Server) handleGetAuditAttachments(w http.ResponseWriter, r *http.Request) {
+	record, err := s.auditService.GetRecord(r.Context(), r.PathValue("id"))
+	if err != nil {
+		s.writeError(w, http.StatusNotFound, err)
+		return
+	}
+
+	s.writeJSON(w, http.StatusOK, record.Attachments)
+}
+
+// handleOltHealth returns the TL1 latency and failures of each OLT over the last week
+func (s *Server) handleOltHealth(w http.ResponseWriter, r *http.Request) {
+	report, err := s.auditService.OltHealth(r.Context())
+	if err != nil {
+		s.writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	s.writeJSON(w, http.StatusOK, report)
+}
+
+// handlePonCapacity returns the occupancy growth of each PON with its projected exhaustion date
+func (s *Server) handlePonCapacity(w http.ResponseWriter, r *http.Request) {
+	report, err := s.ponCapacity.Report(r.Context())
+	if err != nil {
+		s.writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	s.writeJSON(w, http.StatusOK, report)
+}
+
+// writeJSON encodes a value as JSON response
+func (s *Server) writeJSON(w http.ResponseWriter, status int, value any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	if err := json.NewEncoder(w).Encode(value); err != nil {
+		s.logger.WithError(err).Error("Falha ao codificar resposta da API")
+	}
+}
+
+// writeError encodes an error message as JSON response, with the problem list when the error carries one
+func (s *Server) writeError(w http.ResponseWriter, status int, err error) {
+	s.writeJSON(w, status, ErrorResponse{Error: err.Error(), Problems: validation.From(err)})
+}
@@ -0,0 +1,49 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"provisioning-assistant/internal/services"
+	"provisioning-assistant/internal/validation"
+)
+
+// WebhookReceipt acknowledges a delivery of the ERP webhook, "accepted" or "duplicate"
+type WebhookReceipt struct {
+	ID     string `json:"id"`
+	Status string `json:"status"`
+}
+
+// handleErpWebhook takes a protocol change pushed by the ERP, signed with the shared secret in the
+// X-Erp-Signature header over the X-Erp-Timestamp header and the body
+func (s *Server) handleErpWebhook(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, services.ErpWebhookMaxBody))
+	if err != nil {
+		s.writeError(w, http.StatusRequestEntityTooLarge, fmt.Errorf("corpo do webhook excede %d bytes", services.ErpWebhookMaxBody))
+		return
+	}
+
+	event, err := s.erpWebhooks.Ingest(r.Context(), r.Header.Get("X-Erp-Timestamp"), r.Header.Get("X-Erp-Signature"), body)
+	switch {
+	case errors.Is(err, services.ErrErpEventDuplicate):
+		s.writeJSON(w, http.StatusOK, WebhookReceipt{ID: event.ID, Status: "duplicate"})
+		return
+	case errors.Is(err, services.ErrErpWebhookDisabled):
+		s.writeError(w, http.StatusServiceUnavailable, err)
+		return
+	case errors.Is(err, services.ErrErpWebhookSignature), errors.Is(err, services.ErrErpWebhookExpired):
+		s.logger.WithError(err).WithField("remote", r.RemoteAddr).Warn("Entrega do webhook do ERP recusada")
+		s.writeError(w, http.StatusUnauthorized, err)
+		return
+	case err != nil:
+		status := http.StatusInternalServerError
+		if validation.From(err) != nil {
+			status = http.StatusUnprocessableEntity
+		}
+		s.writeError(w, status, err)
+		return
+	}
+
+	s.writeJSON(w, http.StatusAccepted, WebhookReceipt{ID: event.ID, Status: "accepted"})
+}
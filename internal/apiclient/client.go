@@ -0,0 +1,110 @@
+// Package apiclient is the typed Go client of the REST API, its methods are generated from the route table
+// of internal/api so they follow the handlers as the endpoints change
+package apiclient
+
+//go:generate go run ../api/apigen -out client_gen.go
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"provisioning-assistant/internal/validation"
+	"strings"
+	"time"
+)
+
+const defaultTimeout = 30 * time.Second
+
+// Client calls the API with a bearer token
+type Client struct {
+	baseURL    string
+	token      string
+	httpClient *http.Client
+}
+
+// Error is an answer of the API outside the 2xx range
+type Error struct {
+	Status   int                 `json:"-"`
+	Message  string              `json:"error"`
+	Problems validation.Problems `json:"problems,omitempty"`
+}
+
+func (e *Error) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("API respondeu %d", e.Status)
+	}
+	return fmt.Sprintf("API respondeu %d: %s", e.Status, e.Message)
+}
+
+// New creates a client of the API at baseURL, the token may be empty for the endpoints without a scope
+func New(baseURL, token string, httpClient *http.Client) *Client {
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: defaultTimeout}
+	}
+	return &Client{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		token:      token,
+		httpClient: httpClient,
+	}
+}
+
+// do sends the request and decodes the answer into out, a *string receiving the body as is
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, body, out any) error {
+	target := c.baseURL + path
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+
+	var reader io.Reader
+	if body != nil {
+		payload, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("falha ao codificar requisição: %w", err)
+		}
+		reader = bytes.NewReader(payload)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, target, reader)
+	if err != nil {
+		return fmt.Errorf("falha ao criar requisição: %w", err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("falha ao chamar %s %s: %w", method, path, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		apiErr := &Error{Status: resp.StatusCode}
+		_ = json.NewDecoder(resp.Body).Decode(apiErr)
+		return apiErr
+	}
+
+	switch out := out.(type) {
+	case nil:
+		return nil
+	case *string:
+		raw, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return fmt.Errorf("falha ao ler resposta: %w", err)
+		}
+		*out = string(raw)
+		return nil
+	default:
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			return fmt.Errorf("falha ao decodificar resposta: %w", err)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,936 @@
+package app
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"provisioning-assistant/internal/api"
+	"provisioning-assistant/internal/buildinfo"
+	"provisioning-assistant/internal/clock"
+	"provisioning-assistant/internal/database"
+	"provisioning-assistant/internal/domain"
+	"provisioning-assistant/internal/handler"
+	"provisioning-assistant/internal/ids"
+	"provisioning-assistant/internal/locale"
+	"provisioning-assistant/internal/logger"
+	"provisioning-assistant/internal/repository"
+	"provisioning-assistant/internal/scheduler"
+	"provisioning-assistant/internal/services"
+	"provisioning-assistant/internal/telegram"
+	"provisioning-assistant/internal/tl1"
+	"provisioning-assistant/internal/unm"
+
+	"github.com/gookit/event"
+)
+
+type Application struct {
+	mode         Mode
+	logger       domain.Logger
+	db           database.DB
+	ownsDB       bool
+	leaderLock   *database.AdvisoryLock
+	broker       *database.Broker
+	store        database.KV
+	config       *Config
+	services     *Services
+	handlers     *Handlers
+	scheduler    *scheduler.Scheduler
+	channel      ChannelFactory
+	eventManager *event.Manager
+}
+
+type Services struct {
+	Provisioning  *services.ProvisioningService
+	User          *services.UserService
+	Session       *services.SessionService
+	ERP           *services.ErpService
+	ProtocolCheck *services.ProtocolCheckService
+	Audit         *services.AuditService
+	Token         *services.TokenService
+	Challenge     *services.ChallengeService
+	AccessGuard   *services.AccessGuardService
+	LastJob       *services.LastJobService
+	Binding       *services.BindingService
+	Leader        *services.LeaderService
+	Ack           *services.AckService
+	Circuit       *services.ProvisioningCircuitService
+	Operation     *services.OperationService
+	Backup        *services.BackupService
+	Archive       *services.ArchiveService
+	Artifacts     *services.ArtifactService
+	Feature       *services.FeatureService
+	Training      *services.TrainingService
+	Tl1Console    *services.Tl1ConsoleService
+	Credentials   *services.CredentialCheckService
+	Queue         *services.ProvisioningQueue
+	Idempotency   *services.IdempotencyService
+	Templates     *services.PlanTemplateService
+	Catalog       *services.TemplateCatalogService
+	Feedback      *services.FeedbackService
+	Alerts        *services.AlertService
+	Maintenance   *services.MaintenanceService
+	Olts          *services.OltSuggestionService
+	OnuHistory    *services.OnuHistoryService
+	Watchdog      *services.DependencyWatchdogService
+	Changelog     *services.ChangelogService
+	SafeMode      *services.SafeModeService
+	Export        *services.ExportService
+	WorkOrders    *services.WorkOrderService
+	PonCapacity   *services.PonCapacityService
+	Orphans       *services.OrphanOnuService
+	Deprovision   *services.DeprovisionService
+	Probe         *services.UnmProbeService
+	ErpWebhooks   *services.ErpWebhookService
+	LoadShed      *services.LoadShedder
+	State         domain.StateRepository
+	Clock         clock.Clock
+}
+
+type Handlers struct {
+	Message *handler.MessageHandler
+}
+
+// New creates a new application instance, wiring the default dependencies unless replaced by options
+func New(config *Config, opts ...Option) (*Application, error) {
+	o := &options{mode: config.Mode}
+	for _, opt := range opts {
+		opt(o)
+	}
+	if o.mode == "" {
+		o.mode = ModeAll
+	}
+	if o.clock == nil {
+		o.clock = clock.System
+	}
+
+	if err := config.validate(o); err != nil {
+		return nil, err
+	}
+	buildinfo.SetConfigDigest(config.Digest())
+
+	log := o.logger
+	if log == nil {
+		zlog, err := initializeLogger(config.LogLevel)
+		if err != nil {
+			return nil, fmt.Errorf("falha ao inicializar logger: %w", err)
+		}
+		log = zlog
+	}
+
+	db, ownsDB := o.db, false
+	if db == nil {
+		postgres, err := initializeDatabase(config.DatabaseDSN)
+		if err != nil {
+			return nil, fmt.Errorf("falha ao inicializar banco de dados: %w", err)
+		}
+		db, ownsDB = postgres, true
+	}
+
+	eventManager := o.eventManager
+	if eventManager == nil {
+		eventManager = event.NewManager("app")
+	}
+
+	var leaderLock *database.AdvisoryLock
+	if config.LeaderElection {
+		leaderLock = database.NewAdvisoryLock(config.DatabaseDSN)
+	}
+
+	// The embedded store keeps the state of a single process, only one process may hold its file. Split
+	// processes read each other's records, so they keep them in the database they coordinate through.
+	var store database.KV
+	switch {
+	case o.mode.IsSplit():
+		sharedStore, err := database.OpenSharedStore(context.Background(), config.DatabaseDSN)
+		if err != nil {
+			return nil, fmt.Errorf("falha ao abrir armazenamento compartilhado: %w", err)
+		}
+		store = sharedStore
+	case config.StateFile != "":
+		kvStore, err := database.OpenKVStore(config.StateFile)
+		if err != nil {
+			return nil, fmt.Errorf("falha ao abrir armazenamento local %s: %w", config.StateFile, err)
+		}
+		store = kvStore
+	}
+
+	services, err := initializeServices(config, o, db, store, leaderLock, eventManager, log)
+	if err != nil {
+		return nil, fmt.Errorf("falha ao inicializar serviços: %w", err)
+	}
+
+	channel := o.channel
+	if channel == nil {
+		channel = func(eventManager *event.Manager, logger domain.Logger) (Channel, error) {
+			return telegram.NewTelegram(config.TelegramToken, services.State, logger, eventManager)
+		}
+	}
+
+	formatter, err := locale.NewFormatter(config.Timezone)
+	if err != nil {
+		return nil, fmt.Errorf("falha ao configurar localização: %w", err)
+	}
+
+	jobScheduler := scheduler.New(services.Leader, formatter.Location(), services.Clock, log)
+	handlers := initializeHandlers(config, services, jobScheduler, formatter, log, eventManager)
+
+	if err := initializeScheduler(jobScheduler, config, services, handlers, log); err != nil {
+		return nil, fmt.Errorf("falha ao inicializar agendador: %w", err)
+	}
+
+	var broker *database.Broker
+	if o.mode.IsSplit() {
+		broker = database.NewBroker(config.DatabaseDSN)
+	}
+
+	app := &Application{
+		mode:         o.mode,
+		config:       config,
+		logger:       log,
+		db:           db,
+		ownsDB:       ownsDB,
+		leaderLock:   leaderLock,
+		broker:       broker,
+		store:        store,
+		services:     services,
+		handlers:     handlers,
+		scheduler:    jobScheduler,
+		channel:      channel,
+		eventManager: eventManager,
+	}
+	app.registerLifecycleListeners()
+
+	return app, nil
+}
+
+// Run starts the components of the configured mode and blocks until the context is cancelled or one of them
+// fails. Either way the draining listeners run before the components are cancelled.
+func (app *Application) Run(ctx context.Context) error {
+	componentCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	defer cancel()
+
+	// A drifted ERP schema is reported now and by the readiness probe, not in the middle of a conversation
+	schemaCtx, cancelSchema := context.WithTimeout(ctx, api.ReadinessCheckTimeout)
+	_ = app.services.ERP.CheckSchema(schemaCtx)
+	cancelSchema()
+
+	var components []func(ctx context.Context) error
+
+	if app.mode.RunsBot() {
+		channel, err := app.channel(app.eventManager, app.logger)
+		if err != nil {
+			return fmt.Errorf("falha ao criar canal de mensagens: %w", err)
+		}
+
+		app.handlers.Message.RegisterEventListeners()
+		components = append(components, func(ctx context.Context) error {
+			channel.Start(ctx)
+			return nil
+		})
+
+		if app.broker != nil {
+			components = append(components, func(ctx context.Context) error {
+				return relayOutbound(ctx, app.eventManager, app.broker, app.logger)
+			}, func(ctx context.Context) error {
+				return relayErpEvents(ctx, app.eventManager, app.broker, app.logger)
+			})
+		}
+	} else if app.broker != nil {
+		// Processes without the chat channel hand their messages and the ERP events over to the bot process
+		publishOutbound(app.eventManager, app.broker, app.logger)
+		publishErpEvents(app.eventManager, app.broker, app.logger)
+	}
+
+	if app.mode.RunsAPI() && app.config.APIAddr != "" {
+		apiServer := api.NewServer(
+			app.config.APIAddr,
+			app.services.Audit,
+			app.services.Token,
+			app.services.Leader,
+			app.services.Artifacts,
+			app.services.Session.Metrics(),
+			app.services.Alerts,
+			app.services.SafeMode,
+			app.services.PonCapacity,
+			app.services.ErpWebhooks,
+			app.services.LoadShed,
+			map[string]api.ReadinessCheck{
+				"erp_database": app.db.Ping,
+				"erp_schema":   app.services.ERP.SchemaReady,
+			},
+			app.logger,
+		)
+		components = append(components, func(ctx context.Context) error {
+			if err := apiServer.Start(ctx); err != nil {
+				return fmt.Errorf("falha na API de relatórios: %w", err)
+			}
+			return nil
+		})
+	}
+
+	if scope, ok := app.schedulerScope(); ok {
+		components = append(components, func(ctx context.Context) error {
+			app.scheduler.Start(ctx, scope)
+			return nil
+		})
+	}
+
+	app.publishLifecycle(domain.LifecycleStarting, "")
+
+	var drainOnce sync.Once
+	drain := func(reason string) {
+		drainOnce.Do(func() {
+			app.publishLifecycle(domain.LifecycleDraining, reason)
+			cancel()
+		})
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, len(components))
+
+	for _, component := range components {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			reason := "componente encerrado"
+			if err := component(componentCtx); err != nil {
+				errs <- err
+				reason = err.Error()
+			}
+			drain(reason)
+		}()
+	}
+
+	stopped := make(chan struct{})
+	go func() {
+		select {
+		case <-ctx.Done():
+			drain("sinal de encerramento")
+		case <-stopped:
+		}
+	}()
+
+	app.logStartupMessages()
+	app.publishLifecycle(domain.LifecycleReady, "")
+
+	wg.Wait()
+	close(stopped)
+	drain("componentes encerrados")
+	app.publishLifecycle(domain.LifecycleStopped, "")
+	close(errs)
+
+	return <-errs
+}
+
+// schedulerScope returns the jobs this process schedules, bot processes keep the jobs bound to their memory
+func (app *Application) schedulerScope() (scheduler.Scope, bool) {
+	switch {
+	case app.mode.RunsBot() && app.mode.RunsWorker():
+		return scheduler.ScopeAll, true
+	case app.mode.RunsBot():
+		return scheduler.ScopeBot, true
+	case app.mode.RunsWorker():
+		return scheduler.ScopeWorker, true
+	default:
+		return 0, false
+	}
+}
+
+// Close performs cleanup operations
+func (app *Application) Close() {
+	if app.broker != nil {
+		if err := app.broker.Close(context.Background()); err != nil {
+			app.logger.WithError(err).Warn("Falha ao encerrar conexão do broker")
+		}
+	}
+
+	if app.leaderLock != nil {
+		if err := app.leaderLock.Close(context.Background()); err != nil {
+			app.logger.WithError(err).Warn("Falha ao encerrar conexão de liderança")
+		}
+	}
+
+	if app.store != nil {
+		if err := app.store.Close(); err != nil {
+			app.logger.WithError(err).Warn("Falha ao fechar armazenamento local")
+		}
+	}
+
+	if app.db != nil && app.ownsDB {
+		err := app.db.Close(context.Background())
+		if err != nil {
+			panic(err)
+		}
+	}
+}
+
+// logStartupMessages displays startup information
+func (app *Application) logStartupMessages() {
+	info := buildinfo.Read()
+	app.logger.WithFields(map[string]any{
+		"version":       info.Version,
+		"commit":        info.ShortCommit(),
+		"config_digest": info.ConfigDigest,
+		"modified":      info.Modified,
+	}).Info("🏷️ Versão " + info.Version)
+	app.logger.Info("🤖 Assistente iniciado no modo " + string(app.mode))
+	app.logger.Info("📡 Conectado ao UNM em " + app.config.UNMHost)
+	app.logger.Info("🗄️ Conectado ao banco de dados")
+	for _, degradation := range app.services.SafeMode.Degradations() {
+		app.logger.WithFields(map[string]any{
+			"setting": degradation.Setting,
+			"reason":  degradation.Reason,
+		}).Warn("🛟 Modo de segurança: " + degradation.Feature + " desativado")
+	}
+	app.logger.Info("✅ Pronto para provisionar equipamentos")
+}
+
+// initializeLogger creates and configures the application logger
+func initializeLogger(logLevel string) (*logger.ZLogXAdapter, error) {
+	logConfig := &logger.Config{
+		Level:          logLevel,
+		DateTimeLayout: "02/01/2006 15:04:05",
+		Colored:        true,
+		JSONFormat:     false,
+		UseEmoji:       true,
+	}
+
+	log, err := logger.New(logConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	return &logger.ZLogXAdapter{ZLogX: log}, nil
+}
+
+// initializeDatabase creates and connects to the database
+func initializeDatabase(dsn string) (*database.PostgresDB, error) {
+	ctx := context.Background()
+	return database.NewPostgres(ctx, dsn)
+}
+
+// initializeServices creates all application services with their dependencies
+func initializeServices(config *Config, opts *options, db database.DB, store database.KV, leaderLock *database.AdvisoryLock, eventManager *event.Manager, logger domain.Logger) (*Services, error) {
+	erpRepository := repository.NewErpRepository(db)
+
+	transporter := opts.oltDriver
+	if transporter == nil {
+		tl1Transport, err := tl1.NewTransport(config.UNMHost, uint16(config.UNMPort))
+		if err != nil {
+			return nil, fmt.Errorf("falha ao criar transporte TL1: %w", err)
+		}
+		tl1Transport.SetMaxResponseSize(config.TL1MaxResponse)
+		transporter = tl1Transport
+	}
+
+	sessions := opts.sessions
+	if sessions == nil {
+		sessions = services.NewSessionService(opts.clock)
+	}
+
+	credentials, err := newCredentialProvider(config, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	unmClient := unm.New(config.UNMUsername, config.UNMPassword, transporter, logger)
+	unmClient.SetCredentialProvider(credentials)
+	unmClient.SetPonIDFormat(config.PonIDFormat)
+	unmClient.SetStepTimeouts(config.StepTimeouts)
+	unmClient.SetWatchdog(config.TL1Watchdog, func(fired unm.WatchdogEvent) {
+		eventManager.Fire("unm.watchdog.fired", event.M{"event": &fired})
+	})
+	unmClient.SetTransportErrorHook(func(err error) {
+		eventManager.Fire("app.error", event.M{"event": &domain.ErrorEvent{Source: "tl1", Err: err}})
+	})
+
+	idGenerator := ids.NewGenerator(opts.clock, nil)
+	var auditRepository domain.AuditRepository = repository.NewAuditRepository(idGenerator)
+	var tokenRepository domain.TokenRepository = repository.NewTokenRepository()
+	var bindingRepository domain.BindingRepository = repository.NewBindingRepository()
+	var stateRepository domain.StateRepository = repository.NewStateRepository()
+	if store != nil {
+		bindingRepository = repository.NewKVBindingRepository(store)
+		stateRepository = repository.NewKVStateRepository(store)
+	}
+
+	// The API process serves the audits recorded and authenticates the tokens issued by the bot process
+	if opts.mode.IsSplit() {
+		auditRepository = repository.NewKVAuditRepository(store, idGenerator)
+		tokenRepository = repository.NewKVTokenRepository(store)
+	}
+
+	// Training sessions provision against the UNM simulator and, when configured, a copy of the ERP data
+	sandboxClient := unm.New("treinamento", "treinamento", unm.NewSimulator(), logger)
+	sandboxClient.SetPonIDFormat(config.PonIDFormat)
+
+	// The UNM sessions end with the process, the server frees them now instead of when they time out
+	eventManager.On("app.lifecycle", event.ListenerFunc(func(e event.Event) error {
+		lifecycle, ok := e.Get("event").(*domain.LifecycleEvent)
+		if !ok || lifecycle.Phase != domain.LifecycleStopped {
+			return nil
+		}
+
+		if err := errors.Join(unmClient.Close(), sandboxClient.Close()); err != nil {
+			logger.WithError(err).Warn("Falha ao encerrar sessões do UNM")
+		}
+		return nil
+	}))
+
+	var sandboxErpRepository domain.ErpRepository
+	if config.TrainingErpFile != "" {
+		snapshot, err := repository.LoadSnapshotErpRepository(config.TrainingErpFile)
+		if err != nil {
+			return nil, err
+		}
+		sandboxErpRepository = snapshot
+	}
+
+	auditService := services.NewAuditService(auditRepository, repository.NewAuditRepository(idGenerator), opts.clock, logger)
+
+	artifactStore := newArtifactStore(config)
+	artifactService := services.NewArtifactService(artifactStore, config.Artifacts, opts.clock, logger)
+
+	// The responses the client could not understand are kept for the parser, off the path of the command
+	tl1Captures := services.NewTl1CaptureService(artifactService, logger)
+	unmClient.SetResponseDumpHook(func(dump unm.ResponseDump) {
+		go func() {
+			if _, err := tl1Captures.SaveDump(context.Background(), dump); err != nil {
+				logger.WithError(err).Warn("Falha ao guardar resposta TL1 não reconhecida")
+			}
+		}()
+	})
+
+	templateService := services.NewPlanTemplateService(config.PlanTemplates, stateRepository, logger)
+	if err := templateService.Restore(context.Background()); err != nil {
+		return nil, err
+	}
+
+	// The alert gauges read the provisioning, ERP, circuit and queue services
+	signalThresholds := services.NewSignalThresholdService(config.SignalThresholds)
+	permissions := services.NewTl1PermissionService(config.Tl1Permissions, logger)
+	queue := services.NewProvisioningQueue(config.ProvisioningSlots)
+	loadShedder := services.NewLoadShedder(queue, config.LoadShed, opts.clock, logger)
+	unmClient.SetLatencyHook(loadShedder.ObserveLatency)
+	featureService := services.NewFeatureService(stateRepository, config.FeaturesEnabled, config.Rollout, opts.clock, logger)
+	provisioningService := services.NewProvisioningService(unmClient, sandboxClient, templateService, signalThresholds, config.OnuNaming, config.CommandBudget, permissions, loadShedder, featureService, opts.clock, logger)
+	erpService := services.NewErpService(erpRepository, sandboxErpRepository, config.ErpRetry, opts.clock, logger)
+	circuitService := services.NewProvisioningCircuitService(config.Circuit, opts.clock)
+	probeService := services.NewUnmProbeService(
+		map[string]services.DependencyProbe{transporter.GetAddress(): provisioningService.PingUnm},
+		circuitService,
+		config.UnmProbe,
+		opts.clock,
+		logger,
+	)
+	bindingService := services.NewBindingService(bindingRepository, logger)
+	archiveService := services.NewArchiveService(auditRepository, artifactService, config.ArchiveRetention, logger)
+
+	// A backup that cannot be read or applied is reported by the safe mode, the bot starts with what it has
+	backupService := services.NewBackupService(auditRepository, bindingRepository, tokenRepository, stateRepository, templateService, artifactService, config.BackupPassphrase, logger)
+	if config.BackupRestoreFile != "" {
+		if _, err := backupService.RestoreFile(context.Background(), config.BackupRestoreFile); err != nil {
+			config.degrade("BACKUP_RESTORE_FILE", "restauração do backup inicial", err)
+		}
+	}
+
+	maintenanceService := services.NewMaintenanceService(stateRepository, opts.clock, logger)
+
+	services := &Services{
+		Provisioning:  provisioningService,
+		User:          services.NewUserService(),
+		Session:       sessions,
+		ERP:           erpService,
+		ProtocolCheck: services.NewProtocolCheckService(config.ProtocolStatus, auditService, logger),
+		Audit:         auditService,
+		Token:         services.NewTokenService(tokenRepository, logger),
+		Challenge:     services.NewChallengeService(config.CaptchaEnabled, opts.clock),
+		AccessGuard:   services.NewAccessGuardService(opts.clock),
+		LastJob:       services.NewLastJobService(stateRepository, opts.clock, logger),
+		Binding:       bindingService,
+		Leader:        services.NewLeaderService(newLeaderLock(leaderLock), logger),
+		Ack:           services.NewAckService(stateRepository, config.AckTimeout, opts.clock, logger),
+		Circuit:       circuitService,
+		Probe:         probeService,
+		Operation:     services.NewOperationService(services.DefaultOperationTTL, opts.clock),
+		Backup:        backupService,
+		Archive:       archiveService,
+		Artifacts:     artifactService,
+		Feature:       featureService,
+		State:         stateRepository,
+		Clock:         opts.clock,
+		Training:      services.NewTrainingService(stateRepository, logger),
+		Tl1Console: services.NewTl1ConsoleService(
+			map[string]*unm.UNMClient{"producao": unmClient, "simulador": sandboxClient},
+			config.Tl1ConsoleUsers,
+			config.Tl1ConsoleVerbs,
+			permissions,
+			tl1Captures,
+			stateRepository,
+			logger,
+		),
+		Credentials: services.NewCredentialCheckService(credentialEndpoints(config, opts, credentials, logger), config.Credentials, logger),
+		Queue:       queue,
+		LoadShed:    loadShedder,
+		Idempotency: services.NewIdempotencyService(stateRepository, config.IdempotencyWindow, opts.clock, logger),
+		Templates:   templateService,
+		Catalog:     services.NewTemplateCatalogService(templateService, artifactService, logger),
+		Feedback:    services.NewFeedbackService(stateRepository, logger),
+		Alerts:      services.NewAlertService(provisioningService, erpService, circuitService, queue, probeService),
+		Maintenance: maintenanceService,
+		Olts:        services.NewOltSuggestionService(auditService, signalThresholds, opts.clock, logger),
+		OnuHistory:  services.NewOnuHistoryService(auditService, archiveService, logger),
+		Watchdog: services.NewDependencyWatchdogService(
+			map[string]services.DependencyProbe{"unm": provisioningService.PingUnm, "erp": erpService.Ping},
+			opts.clock,
+			logger,
+		),
+		Changelog:  services.NewChangelogService(buildinfo.Read().Version, buildinfo.Changelog(), bindingService, logger),
+		SafeMode:   services.NewSafeModeService(config.Degraded),
+		Export:     services.NewExportService(auditService, artifactService, logger),
+		WorkOrders: services.NewWorkOrderService(erpService, bindingService, stateRepository, config.WorkOrders, opts.clock, logger),
+		PonCapacity: services.NewPonCapacityService(
+			auditService,
+			provisioningService,
+			maintenanceService,
+			stateRepository,
+			config.PonSize,
+			opts.clock,
+			logger,
+		),
+		Orphans: services.NewOrphanOnuService(auditService, erpService, provisioningService, stateRepository, opts.clock, logger),
+		Deprovision: services.NewDeprovisionService(
+			erpService,
+			auditService,
+			provisioningService,
+			config.Deprovision,
+			opts.clock,
+			logger,
+		),
+		ErpWebhooks: services.NewErpWebhookService(
+			stateRepository,
+			func(ctx context.Context, erpEvent *domain.ErpEvent) error {
+				// The delivery is answered before the technicians are told
+				err, _ := eventManager.Fire("erp.event.received", event.M{"ctx": context.WithoutCancel(ctx), "event": erpEvent})
+				return err
+			},
+			config.ErpWebhook,
+			opts.clock,
+			logger,
+		),
+	}
+
+	return services, nil
+}
+
+// newArtifactStore selects where generated files are kept, local disk or an S3-compatible bucket. A bucket
+// that cannot be configured falls back to the local disk.
+func newArtifactStore(config *Config) domain.ArtifactStore {
+	if config.ArtifactStore == "s3" {
+		store, err := repository.NewS3ArtifactStore(config.S3)
+		if err == nil {
+			return store
+		}
+		config.degrade("ARTIFACT_STORE", "armazenamento externo de artefatos", err)
+	}
+
+	return repository.NewLocalArtifactStore(config.ArtifactDir)
+}
+
+// credentialEndpoints lists the UNM endpoints whose account is verified daily, each check opening its own
+// connection so the provisioning session is left alone. Custom OLT drivers have no second connection to open.
+func credentialEndpoints(config *Config, opts *options, credentials unm.CredentialProvider, logger domain.Logger) map[string]services.CredentialEndpoint {
+	if opts.oltDriver != nil {
+		return nil
+	}
+
+	return map[string]services.CredentialEndpoint{
+		config.UNMHost: func() (*unm.UNMClient, error) {
+			transport, err := tl1.NewTransport(config.UNMHost, uint16(config.UNMPort))
+			if err != nil {
+				return nil, fmt.Errorf("falha ao criar transporte TL1: %w", err)
+			}
+			transport.SetMaxResponseSize(config.TL1MaxResponse)
+
+			client := unm.New(config.UNMUsername, config.UNMPassword, transport, logger)
+			client.SetCredentialProvider(credentials)
+			return client, nil
+		},
+	}
+}
+
+// newCredentialProvider reads the UNM accounts from the secrets file when configured, nil keeps the
+// UNM_USERNAME and UNM_PASSWORD account
+func newCredentialProvider(config *Config, logger domain.Logger) (unm.CredentialProvider, error) {
+	if config.UNMCredentials == "" {
+		return nil, nil
+	}
+
+	fallback := unm.Credential{Username: config.UNMUsername, Password: config.UNMPassword}
+	secrets, err := services.LoadCredentialSecrets(config.UNMCredentials, fallback, logger)
+	if err != nil {
+		return nil, fmt.Errorf("UNM_CREDENTIALS_FILE: %w", err)
+	}
+	return secrets, nil
+}
+
+// newLeaderLock converts the optional advisory lock into a leader lock, nil means single replica mode
+func newLeaderLock(lock *database.AdvisoryLock) domain.LeaderLock {
+	if lock == nil {
+		return nil
+	}
+	return lock
+}
+
+// initializeHandlers creates all application handlers with shared event manager
+func initializeHandlers(config *Config, services *Services, scheduler *scheduler.Scheduler, formatter *locale.Formatter, logger domain.Logger, eventManager *event.Manager) *Handlers {
+	return &Handlers{
+		Message: handler.NewMessageHandler(
+			eventManager,
+			services.Provisioning,
+			services.User,
+			services.Session,
+			services.ERP,
+			services.ProtocolCheck,
+			services.Audit,
+			services.Token,
+			services.Challenge,
+			services.AccessGuard,
+			services.LastJob,
+			services.Binding,
+			services.Ack,
+			services.Circuit,
+			services.Operation,
+			services.Backup,
+			services.Archive,
+			services.Feature,
+			services.Training,
+			services.Tl1Console,
+			services.Credentials,
+			services.Queue,
+			services.Artifacts,
+			services.Idempotency,
+			services.Templates,
+			services.Catalog,
+			services.Feedback,
+			services.Alerts,
+			services.Maintenance,
+			services.Olts,
+			services.OnuHistory,
+			services.Watchdog,
+			services.Changelog,
+			services.SafeMode,
+			services.Export,
+			services.WorkOrders,
+			services.PonCapacity,
+			services.Orphans,
+			services.Deprovision,
+			services.Probe,
+			services.LoadShed,
+			scheduler,
+			handler.ConsentPolicy{
+				Required: config.ConsentRequired,
+				Tenant:   config.ConsentTenant,
+				Version:  config.ConsentVersion,
+				Notice:   config.PrivacyNotice,
+			},
+			handler.InputPolicy{
+				MaxInvalidAttempts: config.MaxInvalidInputs,
+				SupportContact:     config.SupportContact,
+				Normalization:      config.InputNormalization,
+			},
+			config.SuccessMessage,
+			config.Nudges,
+			config.SpeedTest,
+			config.Greeting,
+			config.Keyboards,
+			config.AdminChatIDs,
+			config.EscalationChatIDs,
+			config.FeedbackChatIDs,
+			config.ErrorChatIDs,
+			handler.InteractionMode(config.InteractionMode),
+			services.Clock,
+			formatter,
+			logger,
+		),
+	}
+}
+
+// initializeScheduler registers the background jobs and applies the configured schedules
+func initializeScheduler(jobScheduler *scheduler.Scheduler, config *Config, services *Services, handlers *Handlers, logger domain.Logger) error {
+	// The end-of-day logout keeps a valid schedule while disabled so the schedules file can still enable it
+	logoutCron, err := scheduler.DailyAt(cmp.Or(config.LogoutAt, "23:00"))
+	if err != nil {
+		return err
+	}
+
+	// Sessions, pending alerts and the dependency readings turning users away live in the memory of the bot
+	// process, so their jobs are local. The others read the shared store and run on the workers.
+	jobs := []scheduler.Job{
+		{
+			Name:    "session_purge",
+			Cron:    "*/10 * * * *",
+			Enabled: true,
+			Local:   true,
+			Run: func(ctx context.Context) error {
+				if purged := services.Session.PurgeExpired(); purged > 0 {
+					logger.WithField("sessions", purged).Debug("Sessões expiradas removidas")
+				}
+				return nil
+			},
+		},
+		{
+			Name:    "stalled_session_nudge",
+			Cron:    "* * * * *",
+			Enabled: config.Nudges.After > 0,
+			Local:   true,
+			Run:     handlers.Message.NudgeStalledSessions,
+		},
+		{
+			Name:    "end_of_day_logout",
+			Cron:    logoutCron,
+			Enabled: config.LogoutAt != "",
+			Local:   true,
+			Run:     handlers.Message.EndOfDayLogout,
+		},
+		{
+			Name:      "audit_digest",
+			Cron:      "0 8 * * *",
+			Enabled:   true,
+			Jitter:    time.Minute,
+			Exclusive: true,
+			Run:       handlers.Message.SendAuditDigest,
+		},
+		{
+			Name:      "feedback_digest",
+			Cron:      "0 9 * * 1",
+			Enabled:   true,
+			Jitter:    time.Minute,
+			Exclusive: true,
+			Run:       handlers.Message.SendFeedbackDigest,
+		},
+		{
+			Name:    "alert_escalation",
+			Cron:    "* * * * *",
+			Enabled: true,
+			Local:   true,
+			Run:     handlers.Message.EscalateOverdueAlerts,
+		},
+		{
+			Name:    "dependency_watchdog",
+			Cron:    "* * * * *",
+			Enabled: services.Watchdog.IsEnabled(),
+			Local:   true,
+			Run:     handlers.Message.WatchDependencies,
+		},
+		{
+			Name:    "unm_probe",
+			Cron:    "* * * * *",
+			Enabled: services.Probe.IsEnabled(),
+			Local:   true,
+			Run:     handlers.Message.ProbeUnm,
+		},
+		{
+			Name:      "work_order_greeting",
+			Cron:      "*/5 * * * *",
+			Enabled:   services.WorkOrders.IsEnabled(),
+			Exclusive: true,
+			Run:       handlers.Message.GreetAssignedTechnicians,
+		},
+		{
+			Name:      "pon_occupancy_snapshot",
+			Cron:      "0 2 * * *",
+			Enabled:   true,
+			Jitter:    5 * time.Minute,
+			Exclusive: true,
+			Run: services.LoadShed.Deferring("pon_occupancy_snapshot", func(ctx context.Context) error {
+				_, err := services.PonCapacity.Snapshot(ctx)
+				return err
+			}),
+		},
+		{
+			Name:      "pon_capacity_digest",
+			Cron:      "0 8 1 * *",
+			Enabled:   true,
+			Jitter:    time.Minute,
+			Exclusive: true,
+			Run:       handlers.Message.SendCapacityDigest,
+		},
+		{
+			Name:      "orphan_onu_detection",
+			Cron:      "0 6 * * *",
+			Enabled:   true,
+			Jitter:    5 * time.Minute,
+			Exclusive: true,
+			Run:       services.LoadShed.Deferring("orphan_onu_detection", handlers.Message.DetectOrphanOnus),
+		},
+		{
+			Name:      "cancelled_deprovisioning",
+			Cron:      "0 5 * * *",
+			Enabled:   config.Deprovision.Scheduled,
+			Jitter:    5 * time.Minute,
+			Exclusive: true,
+			Run:       services.LoadShed.Deferring("cancelled_deprovisioning", handlers.Message.DeprovisionCancelled),
+		},
+		{
+			Name:      "unm_credentials",
+			Cron:      "0 7 * * *",
+			Enabled:   services.Credentials.IsEnabled(),
+			Jitter:    5 * time.Minute,
+			Exclusive: true,
+			Run:       handlers.Message.CheckUnmCredentials,
+		},
+		{
+			Name:      "artifact_retention",
+			Cron:      "0 4 * * *",
+			Enabled:   services.Artifacts.Retention() > 0,
+			Exclusive: true,
+			Run: func(ctx context.Context) error {
+				_, err := services.Artifacts.Purge(ctx)
+				return err
+			},
+		},
+		{
+			Name:      "idempotency_purge",
+			Cron:      "15 * * * *",
+			Enabled:   services.Idempotency.IsEnabled(),
+			Exclusive: true,
+			Run: func(ctx context.Context) error {
+				_, err := services.Idempotency.Purge(ctx)
+				return err
+			},
+		},
+		{
+			Name:      "erp_event_purge",
+			Cron:      "20 * * * *",
+			Enabled:   services.ErpWebhooks.IsEnabled(),
+			Exclusive: true,
+			Run: func(ctx context.Context) error {
+				_, err := services.ErpWebhooks.Purge(ctx)
+				return err
+			},
+		},
+		{
+			Name:      "audit_archival",
+			Cron:      "30 3 * * *",
+			Enabled:   services.Archive.IsEnabled(),
+			Exclusive: true,
+			Run: func(ctx context.Context) error {
+				_, err := services.Archive.Archive(ctx)
+				return err
+			},
+		},
+	}
+
+	for _, job := range jobs {
+		if err := jobScheduler.Register(job); err != nil {
+			return err
+		}
+	}
+
+	return jobScheduler.Configure(config.Schedules)
+}
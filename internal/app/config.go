@@ -0,0 +1,490 @@
+package app
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"provisioning-assistant/internal/domain"
+	"provisioning-assistant/internal/handler"
+	"provisioning-assistant/internal/locale"
+	"provisioning-assistant/internal/naming"
+	"provisioning-assistant/internal/repository"
+	"provisioning-assistant/internal/scheduler"
+	"provisioning-assistant/internal/services"
+	"provisioning-assistant/internal/tl1"
+	"provisioning-assistant/internal/unm"
+)
+
+type Config struct {
+	TelegramToken      string
+	DatabaseDSN        string
+	UNMHost            string
+	UNMPort            int
+	UNMUsername        string
+	UNMPassword        string
+	UNMCredentials     string
+	LogLevel           string
+	Mode               Mode
+	Timezone           string
+	APIAddr            string
+	InteractionMode    string
+	CaptchaEnabled     bool
+	AdminChatIDs       []int64
+	EscalationChatIDs  []int64
+	FeedbackChatIDs    []int64
+	ErrorChatIDs       []int64
+	AckTimeout         time.Duration
+	LogoutAt           string
+	Circuit            services.CircuitPolicy
+	ErpRetry           services.ErpRetryPolicy
+	ProtocolStatus     services.ProtocolStatusPolicy
+	Credentials        services.CredentialPolicy
+	WorkOrders         services.WorkOrderPolicy
+	Deprovision        services.DeprovisionPolicy
+	ErpWebhook         services.ErpWebhookPolicy
+	LoadShed           services.LoadShedPolicy
+	UnmProbe           services.UnmProbePolicy
+	PonSize            int
+	MaxInvalidInputs   int
+	SupportContact     string
+	InputNormalization map[domain.SessionState]handler.Normalization
+	SuccessMessage     handler.SuccessMessagePolicy
+	Keyboards          *handler.KeyboardCatalog
+	Nudges             handler.NudgePolicy
+	SpeedTest          handler.SpeedTestPolicy
+	Greeting           handler.GreetingPolicy
+	ConsentRequired    bool
+	ConsentTenant      string
+	ConsentVersion     string
+	PrivacyNotice      string
+	PlanTemplates      []domain.ProvisioningTemplate
+	SignalThresholds   services.SignalThresholdPolicy
+	OnuNaming          *naming.Policy
+	LeaderElection     bool
+	Schedules          map[string]scheduler.JobConfig
+	BackupPassphrase   string
+	BackupRestoreFile  string
+	ArchiveRetention   time.Duration
+	StateFile          string
+	FeaturesEnabled    []string
+	Rollout            services.RolloutPolicy
+	TrainingErpFile    string
+	Tl1ConsoleUsers    []int64
+	Tl1ConsoleVerbs    []string
+	Tl1Permissions     services.Tl1PermissionPolicy
+	ProvisioningSlots  int
+	DrainTimeout       time.Duration
+	IdempotencyWindow  time.Duration
+	PonIDFormat        unm.PonIDFormat
+	TL1Watchdog        int
+	TL1MaxResponse     int
+	CommandBudget      unm.CommandBudget
+	StepTimeouts       unm.StepTimeouts
+	ArtifactStore      string
+	ArtifactDir        string
+	Artifacts          services.ArtifactPolicy
+	S3                 repository.S3Config
+
+	// Degraded lists the optional subsystems left off because their configuration is invalid
+	Degraded []services.Degradation
+}
+
+// LoadConfig loads configuration from environment variables
+func LoadConfig() (*Config, error) {
+	config := &Config{
+		TelegramToken:     getEnv("TELEGRAM_BOT_TOKEN", ""),
+		DatabaseDSN:       getEnv("ERP_DATABASE_URL", ""),
+		UNMHost:           getEnv("UNM_HOST", ""),
+		UNMPort:           getEnvAsInt("UNM_PORT", 3337),
+		UNMUsername:       getEnv("UNM_USERNAME", ""),
+		UNMPassword:       getEnv("UNM_PASSWORD", ""),
+		UNMCredentials:    getEnv("UNM_CREDENTIALS_FILE", ""),
+		LogLevel:          getEnv("LOG_LEVEL", "debug"),
+		Mode:              Mode(getEnv("RUN_MODE", string(ModeAll))),
+		Timezone:          getEnv("TIMEZONE", locale.DefaultTimezone),
+		APIAddr:           getEnv("API_ADDR", ""),
+		InteractionMode:   getEnv("INTERACTION_MODE", string(handler.InteractionInstant)),
+		CaptchaEnabled:    getEnvAsBool("CAPTCHA_ENABLED", false),
+		AdminChatIDs:      getEnvAsInt64Slice("ADMIN_CHAT_IDS"),
+		EscalationChatIDs: getEnvAsInt64Slice("ESCALATION_CHAT_IDS"),
+		FeedbackChatIDs:   getEnvAsInt64Slice("FEEDBACK_CHAT_IDS"),
+		ErrorChatIDs:      getEnvAsInt64Slice("ERROR_CHAT_IDS"),
+		AckTimeout:        time.Duration(getEnvAsInt("ACK_TIMEOUT_MINUTES", 15)) * time.Minute,
+		LogoutAt:          getEnv("LOGOUT_AT", ""),
+		MaxInvalidInputs:  getEnvAsInt("MAX_INVALID_ATTEMPTS", handler.DefaultMaxInvalidAttempts),
+		SupportContact:    getEnv("SUPPORT_CONTACT", ""),
+		SuccessMessage: handler.SuccessMessagePolicy{
+			Sections:  getEnvAsStringSlice("SUCCESS_MESSAGE_SECTIONS"),
+			NextSteps: getEnv("SUCCESS_MESSAGE_NEXT_STEPS", ""),
+		},
+		SpeedTest: handler.SpeedTestPolicy{
+			Enabled:    getEnvAsBool("SPEED_TEST_SURVEY", false),
+			MinPercent: getEnvAsInt("SPEED_TEST_MIN_PERCENT", handler.DefaultSpeedTestMinPercent),
+		},
+		Greeting: handler.GreetingPolicy{
+			Returning:    getEnvAsBool("GREETING_RETURNING", true),
+			QuickActions: getEnvAsInt("GREETING_QUICK_ACTIONS", handler.DefaultQuickActions),
+		},
+		Circuit: services.CircuitPolicy{
+			Window:         time.Duration(getEnvAsInt("CIRCUIT_WINDOW_MINUTES", 30)) * time.Minute,
+			MinSamples:     getEnvAsInt("CIRCUIT_MIN_SAMPLES", services.DefaultCircuitMinSamples),
+			MinSuccessRate: float64(getEnvAsInt("CIRCUIT_MIN_SUCCESS_PERCENT", 50)) / 100,
+			Cooldown:       time.Duration(getEnvAsInt("CIRCUIT_COOLDOWN_MINUTES", 15)) * time.Minute,
+		},
+		ErpRetry: services.ErpRetryPolicy{
+			MinTimeout: time.Duration(getEnvAsInt("ERP_MIN_TIMEOUT_SECONDS", 5)) * time.Second,
+			MaxTimeout: time.Duration(getEnvAsInt("ERP_MAX_TIMEOUT_SECONDS", 30)) * time.Second,
+			MaxRetries: getEnvAsInt("ERP_MAX_RETRIES", services.DefaultErpMaxRetries),
+		},
+		ProtocolStatus: services.ProtocolStatusPolicy{
+			ClosedStatuses:    getEnvAsStringSlice("ERP_CLOSED_STATUSES"),
+			CancelledStatuses: getEnvAsStringSlice("ERP_CANCELLED_STATUSES"),
+		},
+		Credentials: services.CredentialPolicy{
+			WarnDays: getEnvAsInt("UNM_PASSWORD_WARN_DAYS", services.DefaultCredentialWarnDays),
+		},
+		WorkOrders: services.WorkOrderPolicy{
+			Enabled:  getEnvAsBool("WORK_ORDER_GREETING", false),
+			Lookback: time.Duration(getEnvAsInt("WORK_ORDER_LOOKBACK_HOURS", int(services.DefaultWorkOrderLookback.Hours()))) * time.Hour,
+		},
+		Deprovision: services.DeprovisionPolicy{
+			Lookback:  time.Duration(getEnvAsInt("DEPROVISION_LOOKBACK_DAYS", int(services.DefaultDeprovisionLookback.Hours()/24))) * 24 * time.Hour,
+			Interval:  time.Duration(getEnvAsInt("DEPROVISION_INTERVAL_SECONDS", int(services.DefaultDeprovisionInterval.Seconds()))) * time.Second,
+			MaxBatch:  getEnvAsInt("DEPROVISION_MAX_BATCH", services.DefaultDeprovisionMaxBatch),
+			Scheduled: getEnvAsBool("DEPROVISION_SCHEDULED", false),
+		},
+		ErpWebhook: services.ErpWebhookPolicy{
+			Secret:    getEnv("ERP_WEBHOOK_SECRET", ""),
+			Tolerance: time.Duration(getEnvAsInt("ERP_WEBHOOK_TOLERANCE_SECONDS", int(services.DefaultErpWebhookTolerance.Seconds()))) * time.Second,
+		},
+		LoadShed: services.LoadShedPolicy{
+			Enabled:     getEnvAsBool("LOAD_SHEDDING", true),
+			Latency:     time.Duration(getEnvAsInt("LOAD_SHED_LATENCY_MS", int(services.DefaultShedLatency.Milliseconds()))) * time.Millisecond,
+			QueueDepth:  getEnvAsInt("LOAD_SHED_QUEUE_DEPTH", services.DefaultShedQueueDepth),
+			Cooldown:    time.Duration(getEnvAsInt("LOAD_SHED_COOLDOWN_SECONDS", int(services.DefaultShedCooldown.Seconds()))) * time.Second,
+			MaxDeferral: time.Duration(getEnvAsInt("LOAD_SHED_MAX_DEFERRAL_MINUTES", int(services.DefaultShedMaxDeferral.Minutes()))) * time.Minute,
+		},
+		UnmProbe: services.UnmProbePolicy{
+			Enabled:  getEnvAsBool("UNM_PROBE", true),
+			Failures: getEnvAsInt("UNM_PROBE_FAILURES", services.DefaultProbeFailures),
+		},
+		PonSize:           getEnvAsInt("PON_SIZE", services.DefaultPonSize),
+		ConsentRequired:   getEnvAsBool("CONSENT_REQUIRED", true),
+		ConsentTenant:     getEnv("CONSENT_TENANT", "default"),
+		ConsentVersion:    getEnv("CONSENT_VERSION", "2"),
+		LeaderElection:    getEnvAsBool("LEADER_ELECTION", false),
+		BackupPassphrase:  getEnv("BACKUP_PASSPHRASE", ""),
+		BackupRestoreFile: getEnv("BACKUP_RESTORE_FILE", ""),
+		ArchiveRetention:  time.Duration(getEnvAsInt("ARCHIVE_AFTER_DAYS", 90)) * 24 * time.Hour,
+		StateFile:         getEnv("STATE_FILE", ""),
+		FeaturesEnabled:   getEnvAsStringSlice("FEATURES_ENABLED"),
+		Rollout: services.RolloutPolicy{
+			MaxErrorRate: float64(getEnvAsInt("ROLLOUT_MAX_ERROR_PERCENT", 20)) / 100,
+			MinSamples:   getEnvAsInt("ROLLOUT_MIN_SAMPLES", services.DefaultRolloutMinSamples),
+			Window:       time.Duration(getEnvAsInt("ROLLOUT_WINDOW_MINUTES", 60)) * time.Minute,
+		},
+		TrainingErpFile:   getEnv("TRAINING_ERP_FILE", ""),
+		Tl1ConsoleUsers:   getEnvAsInt64Slice("TL1_CONSOLE_USER_IDS"),
+		Tl1ConsoleVerbs:   getEnvAsStringSlice("TL1_CONSOLE_VERBS"),
+		ProvisioningSlots: getEnvAsInt("PROVISIONING_SLOTS", services.DefaultProvisioningSlots),
+		DrainTimeout:      time.Duration(getEnvAsInt("DRAIN_TIMEOUT_SECONDS", 120)) * time.Second,
+		TL1Watchdog:       getEnvAsInt("TL1_WATCHDOG_TIMEOUTS", unm.DefaultWatchdogThreshold),
+		TL1MaxResponse:    getEnvAsInt("TL1_MAX_RESPONSE_KB", tl1.DefaultMaxResponseSize>>10) << 10,
+		CommandBudget: unm.CommandBudget{
+			MaxCommands: getEnvAsInt("TL1_JOB_MAX_COMMANDS", unm.DefaultMaxJobCommands),
+			MaxDuration: time.Duration(getEnvAsInt("TL1_JOB_MAX_SECONDS", int(unm.DefaultMaxJobDuration.Seconds()))) * time.Second,
+		},
+		StepTimeouts: unm.StepTimeouts{
+			Login: time.Duration(getEnvAsInt("TL1_LOGIN_TIMEOUT_SECONDS", int(unm.DefaultStepTimeouts.Login.Seconds()))) * time.Second,
+			Add:   time.Duration(getEnvAsInt("TL1_ADD_TIMEOUT_SECONDS", int(unm.DefaultStepTimeouts.Add.Seconds()))) * time.Second,
+			Wan:   time.Duration(getEnvAsInt("TL1_WAN_TIMEOUT_SECONDS", int(unm.DefaultStepTimeouts.Wan.Seconds()))) * time.Second,
+			Lan:   time.Duration(getEnvAsInt("TL1_LAN_TIMEOUT_SECONDS", int(unm.DefaultStepTimeouts.Lan.Seconds()))) * time.Second,
+			Info:  time.Duration(getEnvAsInt("TL1_INFO_TIMEOUT_SECONDS", int(unm.DefaultStepTimeouts.Info.Seconds()))) * time.Second,
+		},
+		IdempotencyWindow: time.Duration(getEnvAsInt("IDEMPOTENCY_WINDOW_MINUTES", int(services.DefaultIdempotencyWindow.Minutes()))) * time.Minute,
+		ArtifactStore:     getEnv("ARTIFACT_STORE", "local"),
+		ArtifactDir:       getEnv("ARTIFACT_DIR", "artifacts"),
+		Artifacts: services.ArtifactPolicy{
+			Retention:  time.Duration(getEnvAsInt("ARTIFACT_RETENTION_DAYS", 30)) * 24 * time.Hour,
+			BaseURL:    getEnv("ARTIFACT_BASE_URL", ""),
+			SigningKey: getEnv("ARTIFACT_SIGNING_KEY", ""),
+			LinkTTL:    time.Duration(getEnvAsInt("ARTIFACT_LINK_TTL_HOURS", 24)) * time.Hour,
+		},
+		S3: repository.S3Config{
+			Endpoint:  getEnv("S3_ENDPOINT", ""),
+			Region:    getEnv("S3_REGION", ""),
+			Bucket:    getEnv("S3_BUCKET", ""),
+			AccessKey: getEnv("S3_ACCESS_KEY", ""),
+			SecretKey: getEnv("S3_SECRET_KEY", ""),
+		},
+	}
+
+	if value := getEnv("UNM_PASSWORD_EXPIRES_AT", ""); value != "" {
+		expiresAt, err := time.ParseInLocation(time.DateOnly, value, time.Local)
+		if err != nil {
+			return nil, fmt.Errorf("valor inválido para UNM_PASSWORD_EXPIRES_AT: %s (use AAAA-MM-DD)", value)
+		}
+		config.Credentials.ExpiresAt = expiresAt
+	}
+
+	if path := getEnv("PRIVACY_NOTICE_FILE", ""); path != "" {
+		notice, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("falha ao ler aviso de privacidade: %w", err)
+		}
+		config.PrivacyNotice = string(notice)
+	}
+
+	// An optional file that cannot be used is ignored and reported, the process starts in safe mode
+	templates, err := services.LoadPlanTemplates(getEnv("PLAN_TEMPLATES_FILE", ""))
+	if err != nil {
+		config.degrade("PLAN_TEMPLATES_FILE", "modelos de plano", err)
+	}
+	for _, template := range templates {
+		if err := handler.ValidateSuccessSections(template.SuccessSections); err != nil {
+			config.degrade("PLAN_TEMPLATES_FILE", "modelos de plano", fmt.Errorf("template %s: %w", template.Name, err))
+			templates = nil
+			break
+		}
+	}
+	config.PlanTemplates = templates
+
+	thresholds, err := services.LoadSignalThresholds(getEnv("SIGNAL_THRESHOLDS_FILE", ""))
+	if err != nil {
+		config.degrade("SIGNAL_THRESHOLDS_FILE", "limites de sinal personalizados", err)
+		thresholds = services.SignalThresholdPolicy{}
+	}
+	config.SignalThresholds = thresholds
+
+	keyboards, err := handler.LoadKeyboardCatalog(getEnv("KEYBOARDS_FILE", ""))
+	if err != nil {
+		config.degrade("KEYBOARDS_FILE", "teclados personalizados", err)
+		keyboards = handler.DefaultKeyboardCatalog()
+	}
+	config.Keyboards = keyboards
+
+	nudgeAfter := time.Duration(getEnvAsInt("NUDGE_AFTER_MINUTES", int(handler.DefaultNudgeDelay.Minutes()))) * time.Minute
+	nudges, err := handler.LoadNudgePolicy(getEnv("NUDGES_FILE", ""), nudgeAfter)
+	if err != nil {
+		config.degrade("NUDGES_FILE", "lembretes de sessões paradas", err)
+		nudges = handler.NudgePolicy{}
+	}
+	config.Nudges = nudges
+
+	if err := handler.ValidateSuccessSections(config.SuccessMessage.Sections); err != nil {
+		return nil, fmt.Errorf("SUCCESS_MESSAGE_SECTIONS: %w", err)
+	}
+
+	onuNaming, err := naming.NewPolicy(
+		getEnv("ONU_NAME_TEMPLATE", naming.DefaultTemplate),
+		getEnvAsInt("ONU_NAME_MAX_LENGTH", naming.DefaultMaxLength),
+		getEnvAsBool("ONU_NAME_UPPERCASE", false),
+	)
+	if err != nil {
+		return nil, err
+	}
+	config.OnuNaming = onuNaming
+
+	ponIDFormat, err := unm.NewPonIDFormat(getEnv("PON_ID_FORMAT", unm.PonIDFormatFiberhome))
+	if err != nil {
+		return nil, fmt.Errorf("PON_ID_FORMAT: %w", err)
+	}
+	config.PonIDFormat = ponIDFormat
+
+	// Each role may narrow or widen its TL1 operation classes, e.g. TL1_OPERATIONS_SUPERVISOR=query,provision
+	config.Tl1Permissions = make(services.Tl1PermissionPolicy)
+	for _, role := range []domain.Role{domain.RoleTechnician, domain.RoleSupervisor, domain.RoleAdmin, domain.RoleSystem} {
+		key := "TL1_OPERATIONS_" + strings.ToUpper(string(role))
+		values := getEnvAsStringSlice(key)
+		if len(values) == 0 {
+			continue
+		}
+
+		operations, err := services.ParseTl1Operations(values)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", key, err)
+		}
+		config.Tl1Permissions[role] = operations
+	}
+
+	// Steps cleaned other than the full normalization, e.g. INPUT_NORMALIZATION=waiting_protocol=trim
+	normalization, err := handler.ParseNormalizationOverrides(getEnvAsStringSlice("INPUT_NORMALIZATION"))
+	if err != nil {
+		return nil, fmt.Errorf("INPUT_NORMALIZATION: %w", err)
+	}
+	config.InputNormalization = normalization
+
+	// Per-category artifact limits, e.g. ARTIFACT_RETENTIONS=tl1-dumps=3 and ARTIFACT_QUOTAS=tl1-dumps=100
+	retentions, err := services.ParseArtifactRetentions(getEnvAsStringSlice("ARTIFACT_RETENTIONS"))
+	if err != nil {
+		config.degrade("ARTIFACT_RETENTIONS", "retenção por categoria de artefato", err)
+	}
+	config.Artifacts.Retentions = retentions
+
+	quotas, err := services.ParseArtifactQuotas(getEnvAsStringSlice("ARTIFACT_QUOTAS"))
+	if err != nil {
+		config.degrade("ARTIFACT_QUOTAS", "cotas por categoria de artefato", err)
+	}
+	config.Artifacts.Quotas = quotas
+
+	schedules, err := scheduler.LoadConfig(getEnv("SCHEDULER_FILE", ""))
+	if err != nil {
+		config.degrade("SCHEDULER_FILE", "agendamentos personalizados", err)
+		schedules = nil
+	}
+	config.Schedules = schedules
+
+	return config, nil
+}
+
+// validate ensures the configuration values required by the selected mode and options are present, the
+// optional subsystems with invalid values are turned off and listed in Degraded
+func (c *Config) validate(opts *options) error {
+	if !opts.mode.IsValid() {
+		return fmt.Errorf("valor inválido para RUN_MODE: %s (use all, bot, api ou worker)", opts.mode)
+	}
+
+	required := map[string]string{}
+
+	// The secrets file may carry the accounts of every endpoint instead
+	if c.UNMCredentials == "" {
+		required["UNM_USERNAME"] = c.UNMUsername
+		required["UNM_PASSWORD"] = c.UNMPassword
+	}
+
+	if opts.channel == nil && opts.mode.RunsBot() {
+		required["TELEGRAM_BOT_TOKEN"] = c.TelegramToken
+	}
+
+	// Split processes exchange messages through LISTEN/NOTIFY on the ERP database and keep their shared records there
+	if opts.db == nil || c.LeaderElection || opts.mode.IsSplit() {
+		required["ERP_DATABASE_URL"] = c.DatabaseDSN
+	}
+
+	if opts.oltDriver == nil {
+		required["UNM_HOST"] = c.UNMHost
+	}
+
+	if opts.mode == ModeAPI {
+		required["API_ADDR"] = c.APIAddr
+	}
+
+	// A consent is kept under the tenant and notice version, without them every user would share one record
+	if c.ConsentRequired {
+		required["CONSENT_TENANT"] = c.ConsentTenant
+		required["CONSENT_VERSION"] = c.ConsentVersion
+	}
+
+	for key, value := range required {
+		if value == "" {
+			return fmt.Errorf("variável de ambiente obrigatória %s não está definida", key)
+		}
+	}
+
+	if !handler.InteractionMode(c.InteractionMode).IsValid() {
+		return fmt.Errorf("valor inválido para INTERACTION_MODE: %s (use instant ou demo)", c.InteractionMode)
+	}
+
+	// Optional subsystems are turned off instead, the process starts in safe mode without them
+	if c.BackupRestoreFile != "" && c.BackupPassphrase == "" {
+		c.degrade("BACKUP_RESTORE_FILE", "restauração do backup inicial", fmt.Errorf("BACKUP_RESTORE_FILE requer BACKUP_PASSPHRASE"))
+		c.BackupRestoreFile = ""
+	}
+
+	if c.ArtifactStore != "local" && c.ArtifactStore != "s3" {
+		c.degrade("ARTIFACT_STORE", "armazenamento externo de artefatos", fmt.Errorf("valor inválido: %s (use local ou s3)", c.ArtifactStore))
+		c.ArtifactStore = "local"
+	}
+
+	if c.LogoutAt != "" {
+		if _, err := scheduler.DailyAt(c.LogoutAt); err != nil {
+			c.degrade("LOGOUT_AT", "logout no fim do dia", err)
+			c.LogoutAt = ""
+		}
+	}
+
+	return nil
+}
+
+// Digest identifies the effective configuration with the first 12 hex characters of the SHA-256 of its JSON,
+// the secrets left out, so replicas running the same settings report the same digest
+func (c *Config) Digest() string {
+	redacted := *c
+	redacted.TelegramToken = ""
+	redacted.DatabaseDSN = ""
+	redacted.UNMPassword = ""
+	redacted.ErpWebhook.Secret = ""
+	redacted.BackupPassphrase = ""
+	redacted.Artifacts.SigningKey = ""
+	redacted.S3.AccessKey = ""
+	redacted.S3.SecretKey = ""
+
+	data, err := json.Marshal(redacted)
+	if err != nil {
+		return ""
+	}
+
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])[:12]
+}
+
+// degrade records an optional subsystem left off because its setting cannot be used
+func (c *Config) degrade(setting, feature string, err error) {
+	c.Degraded = append(c.Degraded, services.Degradation{Setting: setting, Feature: feature, Reason: err.Error()})
+}
+
+// getEnv retrieves environment variable with fallback to default value
+func getEnv(key, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
+
+// getEnvAsInt retrieves environment variable as integer with fallback
+func getEnvAsInt(key string, defaultValue int) int {
+	if value := os.Getenv(key); value != "" {
+		if intVal, err := strconv.Atoi(value); err == nil {
+			return intVal
+		}
+	}
+	return defaultValue
+}
+
+// getEnvAsBool retrieves environment variable as boolean with fallback
+func getEnvAsBool(key string, defaultValue bool) bool {
+	if value := os.Getenv(key); value != "" {
+		if boolVal, err := strconv.ParseBool(value); err == nil {
+			return boolVal
+		}
+	}
+	return defaultValue
+}
+
+// getEnvAsInt64Slice retrieves a comma separated environment variable as int64 list
+func getEnvAsInt64Slice(key string) []int64 {
+	var values []int64
+	for _, item := range strings.Split(os.Getenv(key), ",") {
+		if intVal, err := strconv.ParseInt(strings.TrimSpace(item), 10, 64); err == nil {
+			values = append(values, intVal)
+		}
+	}
+	return values
+}
+
+// getEnvAsStringSlice retrieves a comma separated environment variable as a trimmed string list
+func getEnvAsStringSlice(key string) []string {
+	var values []string
+	for _, item := range strings.Split(os.Getenv(key), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			values = append(values, item)
+		}
+	}
+	return values
+}
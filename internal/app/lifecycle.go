@@ -0,0 +1,65 @@
+package app
+
+import (
+	"context"
+
+	"provisioning-assistant/internal/domain"
+
+	"github.com/gookit/event"
+)
+
+// publishLifecycle fires a lifecycle phase on the event bus and waits for its listeners, which share a
+// context bounded by the drain timeout
+func (app *Application) publishLifecycle(phase domain.LifecyclePhase, reason string) {
+	ctx, cancel := context.WithTimeout(context.Background(), app.config.DrainTimeout)
+	defer cancel()
+
+	app.logger.WithFields(map[string]any{
+		"phase":  string(phase),
+		"reason": reason,
+	}).Debug("Fase do ciclo de vida da aplicação")
+
+	err, _ := app.eventManager.Fire("app.lifecycle", event.M{
+		"ctx": ctx,
+		"event": &domain.LifecycleEvent{
+			Phase:  phase,
+			Mode:   string(app.mode),
+			Reason: reason,
+			At:     app.services.Clock.Now(),
+		},
+	})
+	if err != nil {
+		app.logger.WithError(err).WithField("phase", string(phase)).Warn("Falha em ouvinte do ciclo de vida")
+	}
+}
+
+// registerLifecycleListeners drains the provisioning queue and the scheduler before the components stop.
+// The queue goes first so the jobs still waiting for a slot are refused instead of holding the updates
+// the chat channel waits for.
+func (app *Application) registerLifecycleListeners() {
+	app.onLifecycle(domain.LifecycleDraining, event.High, func(ctx context.Context) {
+		started := app.services.Clock.Now()
+		if err := app.services.Queue.Drain(ctx); err != nil {
+			running, _ := app.services.Queue.Jobs()
+			app.logger.WithField("running", len(running)).Warn("Encerrando com provisionamentos ainda em execução")
+			return
+		}
+		app.logger.WithField("duration", app.services.Clock.Since(started).String()).Info("Fila de provisionamento drenada")
+	})
+
+	app.onLifecycle(domain.LifecycleDraining, event.Normal, func(ctx context.Context) {
+		if err := app.scheduler.Drain(ctx); err != nil {
+			app.logger.Warn("Encerrando com tarefas agendadas ainda em execução")
+		}
+	})
+}
+
+// onLifecycle runs the action when the application enters the phase
+func (app *Application) onLifecycle(phase domain.LifecyclePhase, priority int, action func(ctx context.Context)) {
+	app.eventManager.On("app.lifecycle", event.ListenerFunc(func(e event.Event) error {
+		if lifecycle, ok := e.Get("event").(*domain.LifecycleEvent); ok && lifecycle.Phase == phase {
+			action(eventContext(e))
+		}
+		return nil
+	}), priority)
+}
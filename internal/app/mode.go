@@ -0,0 +1,38 @@
+package app
+
+import "slices"
+
+// Mode selects which components a process runs
+type Mode string
+
+const (
+	ModeAll    Mode = "all"
+	ModeBot    Mode = "bot"
+	ModeAPI    Mode = "api"
+	ModeWorker Mode = "worker"
+)
+
+// IsValid reports whether the mode is known
+func (m Mode) IsValid() bool {
+	return slices.Contains([]Mode{ModeAll, ModeBot, ModeAPI, ModeWorker}, m)
+}
+
+// RunsBot reports whether the process polls the chat channel and answers users
+func (m Mode) RunsBot() bool {
+	return m == ModeAll || m == ModeBot
+}
+
+// RunsAPI reports whether the process serves the HTTP API
+func (m Mode) RunsAPI() bool {
+	return m == ModeAll || m == ModeAPI
+}
+
+// RunsWorker reports whether the process runs the scheduled background jobs
+func (m Mode) RunsWorker() bool {
+	return m == ModeAll || m == ModeWorker
+}
+
+// IsSplit reports whether the components run as separate processes coordinating through the database
+func (m Mode) IsSplit() bool {
+	return m != ModeAll
+}
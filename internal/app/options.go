@@ -0,0 +1,91 @@
+package app
+
+import (
+	"context"
+
+	"provisioning-assistant/internal/clock"
+	"provisioning-assistant/internal/database"
+	"provisioning-assistant/internal/domain"
+	"provisioning-assistant/internal/services"
+	"provisioning-assistant/internal/unm"
+
+	"github.com/gookit/event"
+)
+
+// Channel is a chat front-end exchanging messages with the handlers through the event manager
+type Channel interface {
+	Start(ctx context.Context)
+}
+
+// ChannelFactory builds the chat channel once the event manager is available
+type ChannelFactory func(eventManager *event.Manager, logger domain.Logger) (Channel, error)
+
+// Option customizes how the application is wired
+type Option func(*options)
+
+type options struct {
+	mode         Mode
+	logger       domain.Logger
+	db           database.DB
+	channel      ChannelFactory
+	sessions     *services.SessionService
+	oltDriver    unm.Transporter
+	eventManager *event.Manager
+	clock        clock.Clock
+}
+
+// WithMode overrides the run mode read from the configuration
+func WithMode(mode Mode) Option {
+	return func(o *options) {
+		o.mode = mode
+	}
+}
+
+// WithLogger replaces the console logger built from the configuration
+func WithLogger(logger domain.Logger) Option {
+	return func(o *options) {
+		o.logger = logger
+	}
+}
+
+// WithDatabase replaces the ERP database connection, the caller keeps ownership of it
+func WithDatabase(db database.DB) Option {
+	return func(o *options) {
+		o.db = db
+	}
+}
+
+// WithChannel replaces the Telegram channel
+func WithChannel(factory ChannelFactory) Option {
+	return func(o *options) {
+		o.channel = factory
+	}
+}
+
+// WithSessionStore replaces the in-memory session store
+func WithSessionStore(sessions *services.SessionService) Option {
+	return func(o *options) {
+		o.sessions = sessions
+	}
+}
+
+// WithOltDriver replaces the TL1 transport used to reach the UNM
+func WithOltDriver(transporter unm.Transporter) Option {
+	return func(o *options) {
+		o.oltDriver = transporter
+	}
+}
+
+// WithEventManager replaces the event manager shared by the channel and the handlers
+func WithEventManager(eventManager *event.Manager) Option {
+	return func(o *options) {
+		o.eventManager = eventManager
+	}
+}
+
+// WithClock replaces the wall clock driving timeouts, expiries and schedules, letting tests fast-forward them
+func WithClock(clock clock.Clock) Option {
+	return func(o *options) {
+		o.clock = clock
+	}
+}
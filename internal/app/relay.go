@@ -0,0 +1,141 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"provisioning-assistant/internal/database"
+	"provisioning-assistant/internal/domain"
+
+	"github.com/gookit/event"
+)
+
+const (
+	// outboxChannel carries the messages produced by processes that do not talk to Telegram
+	outboxChannel = "provisioning_assistant_outbox"
+
+	// erpEventChannel carries the ERP webhook events received by processes without the bot sessions
+	erpEventChannel = "provisioning_assistant_erp_events"
+
+	relayRetryDelay = 5 * time.Second
+)
+
+// publishOutbound forwards outgoing messages to the broker so the bot process delivers them
+func publishOutbound(eventManager *event.Manager, broker *database.Broker, logger domain.Logger) {
+	eventManager.On("telegram.send.message", event.ListenerFunc(func(e event.Event) error {
+		response, ok := e.Get("response").(*domain.MessageResponse)
+		if !ok {
+			return fmt.Errorf("tipo de resposta de mensagem inválido")
+		}
+
+		payload, err := json.Marshal(response)
+		if err != nil {
+			return err
+		}
+
+		if err := broker.Publish(eventContext(e), outboxChannel, payload); err != nil {
+			logger.WithError(err).Error("Falha ao encaminhar mensagem ao processo do bot")
+			return err
+		}
+
+		return nil
+	}))
+
+	// Typing indicators only make sense while answering a user, so they are dropped
+	eventManager.On("telegram.send.typing", event.ListenerFunc(func(e event.Event) error {
+		return nil
+	}))
+}
+
+// relayOutbound delivers the messages published by other processes until the context is cancelled
+func relayOutbound(ctx context.Context, eventManager *event.Manager, broker *database.Broker, logger domain.Logger) error {
+	for {
+		err := broker.Listen(ctx, outboxChannel, func(payload []byte) {
+			var response domain.MessageResponse
+			if err := json.Unmarshal(payload, &response); err != nil {
+				logger.WithError(err).Warn("Mensagem encaminhada inválida")
+				return
+			}
+
+			err, _ := eventManager.Fire("telegram.send.message", event.M{
+				"ctx":      ctx,
+				"response": &response,
+			})
+
+			switch {
+			case errors.Is(err, domain.ErrChatUnavailable):
+				logger.WithField("chat_id", response.ChatID).Debug("Mensagem encaminhada descartada, chat indisponível")
+			case err != nil:
+				logger.WithError(err).Error("Falha ao entregar mensagem encaminhada")
+			}
+		})
+		if err != nil {
+			logger.WithError(err).Warn("Conexão com o broker perdida, reconectando")
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(relayRetryDelay):
+		}
+	}
+}
+
+// publishErpEvents forwards the events of the ERP webhook to the broker so the bot process updates its sessions
+func publishErpEvents(eventManager *event.Manager, broker *database.Broker, logger domain.Logger) {
+	eventManager.On("erp.event.received", event.ListenerFunc(func(e event.Event) error {
+		erpEvent, ok := e.Get("event").(*domain.ErpEvent)
+		if !ok {
+			return fmt.Errorf("tipo de evento do ERP inválido")
+		}
+
+		payload, err := json.Marshal(erpEvent)
+		if err != nil {
+			return err
+		}
+
+		if err := broker.Publish(eventContext(e), erpEventChannel, payload); err != nil {
+			logger.WithError(err).WithField("event_id", erpEvent.ID).Error("Falha ao encaminhar evento do ERP ao processo do bot")
+			return err
+		}
+
+		return nil
+	}))
+}
+
+// relayErpEvents fires the ERP webhook events published by other processes until the context is cancelled
+func relayErpEvents(ctx context.Context, eventManager *event.Manager, broker *database.Broker, logger domain.Logger) error {
+	for {
+		err := broker.Listen(ctx, erpEventChannel, func(payload []byte) {
+			var erpEvent domain.ErpEvent
+			if err := json.Unmarshal(payload, &erpEvent); err != nil {
+				logger.WithError(err).Warn("Evento do ERP encaminhado inválido")
+				return
+			}
+
+			if err, _ := eventManager.Fire("erp.event.received", event.M{"ctx": ctx, "event": &erpEvent}); err != nil {
+				logger.WithError(err).WithField("event_id", erpEvent.ID).Error("Falha ao processar evento do ERP encaminhado")
+			}
+		})
+		if err != nil {
+			logger.WithError(err).Warn("Conexão com o broker perdida, reconectando")
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(relayRetryDelay):
+		}
+	}
+}
+
+// eventContext extracts the request context carried by an event
+func eventContext(e event.Event) context.Context {
+	if ctx, ok := e.Get("ctx").(context.Context); ok && ctx != nil {
+		return ctx
+	}
+	return context.Background()
+}
@@ -0,0 +1,78 @@
+package buildinfo
+
+import (
+	"runtime/debug"
+	"sync"
+	"sync/atomic"
+)
+
+// Release metadata stamped by the release build, e.g.
+//
+//	go build -ldflags "-X provisioning-assistant/internal/buildinfo.Version=v1.8.0 \
+//	  -X provisioning-assistant/internal/buildinfo.Commit=$(git rev-parse HEAD)"
+//
+// The values identify the build, nothing signs them.
+var (
+	Version = "dev"
+	Commit  = ""
+)
+
+// Info identifies the build handling provisioning, Modified telling it came from a tree with
+// uncommitted changes. The configuration digest is left out of the JSON served to the probes.
+type Info struct {
+	Version      string `json:"version"`
+	Commit       string `json:"commit,omitempty"`
+	ConfigDigest string `json:"-"`
+	GoVersion    string `json:"go_version,omitempty"`
+	Modified     bool   `json:"modified,omitempty"`
+}
+
+// configDigest identifies the configuration the process loaded, set once it starts
+var configDigest atomic.Value
+
+// SetConfigDigest records the digest of the configuration the process loaded
+func SetConfigDigest(digest string) {
+	configDigest.Store(digest)
+}
+
+var read = sync.OnceValue(func() Info {
+	info := Info{
+		Version: Version,
+		Commit:  Commit,
+	}
+
+	build, ok := debug.ReadBuildInfo()
+	if !ok {
+		return info
+	}
+	info.GoVersion = build.GoVersion
+
+	// Builds without the stamp still carry the commit recorded by the go tool
+	for _, setting := range build.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			if info.Commit == "" {
+				info.Commit = setting.Value
+			}
+		case "vcs.modified":
+			info.Modified = setting.Value == "true"
+		}
+	}
+
+	return info
+})
+
+// Read returns the metadata of the running build with the digest of its configuration
+func Read() Info {
+	info := read()
+	info.ConfigDigest, _ = configDigest.Load().(string)
+	return info
+}
+
+// ShortCommit returns the first 12 characters of the commit, enough to find it in the history
+func (i Info) ShortCommit() string {
+	if len(i.Commit) > 12 {
+		return i.Commit[:12]
+	}
+	return i.Commit
+}
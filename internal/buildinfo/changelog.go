@@ -0,0 +1,76 @@
+package buildinfo
+
+import (
+	_ "embed"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Release lists the changes of a version the technicians notice, the changelog file keeps one per
+// release with user-visible changes, newest first
+type Release struct {
+	Version string   `json:"version"`
+	Changes []string `json:"changes"`
+}
+
+//go:embed changelog.json
+var changelogFile []byte
+
+var changelog = sync.OnceValue(func() []Release {
+	var releases []Release
+	if err := json.Unmarshal(changelogFile, &releases); err != nil {
+		panic("buildinfo: changelog.json inválido: " + err.Error())
+	}
+	return releases
+})
+
+// Changelog returns the releases of the embedded changelog, newest first
+func Changelog() []Release {
+	return changelog()
+}
+
+// CompareVersions orders two "vMAJOR.MINOR.PATCH" versions, ok is false when either one is not a release
+// version such as "dev"
+func CompareVersions(a, b string) (result int, ok bool) {
+	left, ok := parseVersion(a)
+	if !ok {
+		return 0, false
+	}
+
+	right, ok := parseVersion(b)
+	if !ok {
+		return 0, false
+	}
+
+	for i := range left {
+		if left[i] != right[i] {
+			if left[i] < right[i] {
+				return -1, true
+			}
+			return 1, true
+		}
+	}
+	return 0, true
+}
+
+// parseVersion reads the numbers of a release version, pre-release suffixes are ignored
+func parseVersion(version string) ([3]int, bool) {
+	var numbers [3]int
+
+	core, _, _ := strings.Cut(strings.TrimPrefix(version, "v"), "-")
+	parts := strings.Split(core, ".")
+	if len(parts) != len(numbers) {
+		return numbers, false
+	}
+
+	for i, part := range parts {
+		number, err := strconv.Atoi(part)
+		if err != nil || number < 0 {
+			return numbers, false
+		}
+		numbers[i] = number
+	}
+	return numbers, true
+}
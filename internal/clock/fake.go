@@ -0,0 +1,85 @@
+package clock
+
+import (
+	"slices"
+	"sync"
+	"time"
+)
+
+// Fake is a clock that only moves when told to, waking the waiters whose deadline is reached
+type Fake struct {
+	mu      sync.Mutex
+	now     time.Time
+	waiters []waiter
+}
+
+type waiter struct {
+	deadline time.Time
+	ch       chan time.Time
+}
+
+// NewFake creates a fake clock stopped at the given time
+func NewFake(now time.Time) *Fake {
+	return &Fake{now: now}
+}
+
+// Now returns the current fake time
+func (f *Fake) Now() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.now
+}
+
+// Since returns the fake time elapsed since t
+func (f *Fake) Since(t time.Time) time.Duration {
+	return f.Now().Sub(t)
+}
+
+// After returns a channel that receives the fake time once the clock is advanced past d
+func (f *Fake) After(d time.Duration) <-chan time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	ch := make(chan time.Time, 1)
+	if d <= 0 {
+		ch <- f.now
+		return ch
+	}
+
+	f.waiters = append(f.waiters, waiter{deadline: f.now.Add(d), ch: ch})
+	return ch
+}
+
+// Advance moves the clock forward, waking the waiters in deadline order
+func (f *Fake) Advance(d time.Duration) {
+	f.Set(f.Now().Add(d))
+}
+
+// Set moves the clock to the given time, waking the waiters whose deadline is reached
+func (f *Fake) Set(now time.Time) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.now = now
+
+	slices.SortStableFunc(f.waiters, func(a, b waiter) int {
+		return a.deadline.Compare(b.deadline)
+	})
+
+	pending := f.waiters[:0]
+	for _, w := range f.waiters {
+		if w.deadline.After(now) {
+			pending = append(pending, w)
+			continue
+		}
+		w.ch <- w.deadline
+	}
+	f.waiters = pending
+}
+
+// Waiters returns how many waits are still pending, letting tests sync with goroutines before advancing
+func (f *Fake) Waiters() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.waiters)
+}
@@ -0,0 +1,103 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/jackc/pgx/v5"
+)
+
+var ErrLockConnectionLost = errors.New("conexão do lock de liderança perdida")
+
+// AdvisoryLock elects leaders through PostgreSQL session advisory locks.
+// It keeps a dedicated connection so locks are released as soon as the
+// replica holding them dies.
+type AdvisoryLock struct {
+	dsn  string
+	conn *pgx.Conn
+	mu   sync.Mutex
+}
+
+func NewAdvisoryLock(dsn string) *AdvisoryLock {
+	return &AdvisoryLock{dsn: dsn}
+}
+
+func (l *AdvisoryLock) TryAcquire(ctx context.Context, name string) (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if err := l.ensureConnection(ctx); err != nil {
+		return false, err
+	}
+
+	var acquired bool
+	if err := l.conn.QueryRow(ctx, "SELECT pg_try_advisory_lock(hashtext($1))", name).Scan(&acquired); err != nil {
+		l.reset()
+		return false, err
+	}
+
+	return acquired, nil
+}
+
+func (l *AdvisoryLock) Check(ctx context.Context) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.conn == nil {
+		return ErrLockConnectionLost
+	}
+
+	if err := l.conn.Ping(ctx); err != nil {
+		l.reset()
+		return errors.Join(ErrLockConnectionLost, err)
+	}
+
+	return nil
+}
+
+func (l *AdvisoryLock) Release(ctx context.Context, name string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.conn == nil {
+		return nil
+	}
+
+	_, err := l.conn.Exec(ctx, "SELECT pg_advisory_unlock(hashtext($1))", name)
+	return err
+}
+
+func (l *AdvisoryLock) Close(ctx context.Context) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.conn == nil {
+		return nil
+	}
+
+	err := l.conn.Close(ctx)
+	l.conn = nil
+	return err
+}
+
+func (l *AdvisoryLock) ensureConnection(ctx context.Context) error {
+	if l.conn != nil && !l.conn.IsClosed() {
+		return nil
+	}
+
+	conn, err := pgx.Connect(ctx, l.dsn)
+	if err != nil {
+		return err
+	}
+
+	l.conn = conn
+	return nil
+}
+
+func (l *AdvisoryLock) reset() {
+	if l.conn != nil {
+		_ = l.conn.Close(context.Background())
+		l.conn = nil
+	}
+}
@@ -0,0 +1,81 @@
+package database
+
+import (
+	"context"
+	"sync"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Broker relays payloads between processes through PostgreSQL LISTEN/NOTIFY.
+// Publishing shares one connection while every listener keeps its own, since
+// a connection waiting for notifications cannot run other statements.
+type Broker struct {
+	dsn  string
+	conn *pgx.Conn
+	mu   sync.Mutex
+}
+
+func NewBroker(dsn string) *Broker {
+	return &Broker{dsn: dsn}
+}
+
+// Publish notifies the listeners of a channel, payloads are limited to 8000 bytes by PostgreSQL
+func (b *Broker) Publish(ctx context.Context, channel string, payload []byte) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.conn == nil || b.conn.IsClosed() {
+		conn, err := pgx.Connect(ctx, b.dsn)
+		if err != nil {
+			return err
+		}
+		b.conn = conn
+	}
+
+	if _, err := b.conn.Exec(ctx, "SELECT pg_notify($1, $2)", channel, string(payload)); err != nil {
+		_ = b.conn.Close(context.Background())
+		b.conn = nil
+		return err
+	}
+
+	return nil
+}
+
+// Listen delivers the notifications of a channel until the context is cancelled or the connection fails
+func (b *Broker) Listen(ctx context.Context, channel string, handle func(payload []byte)) error {
+	conn, err := pgx.Connect(ctx, b.dsn)
+	if err != nil {
+		return err
+	}
+	defer conn.Close(context.Background())
+
+	if _, err := conn.Exec(ctx, "LISTEN "+pgx.Identifier{channel}.Sanitize()); err != nil {
+		return err
+	}
+
+	for {
+		notification, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+
+		handle([]byte(notification.Payload))
+	}
+}
+
+func (b *Broker) Close(ctx context.Context) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.conn == nil {
+		return nil
+	}
+
+	err := b.conn.Close(ctx)
+	b.conn = nil
+	return err
+}
@@ -0,0 +1,112 @@
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// ErrKeyNotFound is returned when a key is absent from its bucket
+var ErrKeyNotFound = errors.New("chave não encontrada")
+
+// KV stores JSON values by bucket and key, in the embedded file of a single process or in the database
+// shared by the processes of a split deployment
+type KV interface {
+	Get(ctx context.Context, bucket, key string, target any) error
+	Put(ctx context.Context, bucket, key string, value any) error
+	Delete(ctx context.Context, bucket, key string) error
+	ForEach(ctx context.Context, bucket string, fn func(key string, data []byte) error) error
+	Close() error
+}
+
+// KVStore is an embedded key-value store kept in a single bbolt file, used for the
+// assistant's own state in deployments without a database of their own.
+// Values are stored as JSON, one bucket per kind of record.
+type KVStore struct {
+	db *bolt.DB
+}
+
+// OpenKVStore opens the store file, creating it and its directory when missing
+func OpenKVStore(path string) (*KVStore, error) {
+	if dir := filepath.Dir(path); dir != "" {
+		if err := os.MkdirAll(dir, 0o700); err != nil {
+			return nil, err
+		}
+	}
+
+	// A second process holding the file lock fails fast instead of blocking startup
+	db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, err
+	}
+
+	return &KVStore{db: db}, nil
+}
+
+// Get decodes the value of a key into target
+func (s *KVStore) Get(_ context.Context, bucket, key string, target any) error {
+	return s.db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(bucket))
+		if b == nil {
+			return ErrKeyNotFound
+		}
+
+		data := b.Get([]byte(key))
+		if data == nil {
+			return ErrKeyNotFound
+		}
+
+		return json.Unmarshal(data, target)
+	})
+}
+
+// Put stores the value of a key, creating the bucket when needed
+func (s *KVStore) Put(_ context.Context, bucket, key string, value any) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	return s.db.Update(func(tx *bolt.Tx) error {
+		b, err := tx.CreateBucketIfNotExists([]byte(bucket))
+		if err != nil {
+			return err
+		}
+		return b.Put([]byte(key), data)
+	})
+}
+
+// Delete removes a key, missing keys are ignored
+func (s *KVStore) Delete(_ context.Context, bucket, key string) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(bucket))
+		if b == nil {
+			return nil
+		}
+		return b.Delete([]byte(key))
+	})
+}
+
+// ForEach calls fn with the raw JSON of every key in the bucket, in key order
+func (s *KVStore) ForEach(_ context.Context, bucket string, fn func(key string, data []byte) error) error {
+	return s.db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(bucket))
+		if b == nil {
+			return nil
+		}
+
+		return b.ForEach(func(k, v []byte) error {
+			return fn(string(k), v)
+		})
+	})
+}
+
+// Close releases the store file
+func (s *KVStore) Close() error {
+	return s.db.Close()
+}
@@ -2,12 +2,44 @@ package database
 
 import (
 	"context"
-	"fmt"
+	"errors"
+	"io"
+	"net"
+	"strings"
 
 	"github.com/georgysavva/scany/v2/pgxscan"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 )
 
+var ErrNotFound = errors.New("not found")
+
+// IsTransient reports whether a query failed for the connection or a timeout, which another attempt may
+// not hit. Missing rows, constraint violations and other errors of the statement itself fail again.
+func IsTransient(err error) bool {
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+	if pgconn.Timeout(err) || pgconn.SafeToRetry(err) {
+		return true
+	}
+
+	var connectErr *pgconn.ConnectError
+	var netErr net.Error
+	if errors.As(err, &connectErr) || errors.As(err, &netErr) {
+		return true
+	}
+
+	// Class 08 is a connection exception, 57P0x a server shutting down or starting and 57014 a statement
+	// cancelled by its timeout
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return strings.HasPrefix(pgErr.Code, "08") || strings.HasPrefix(pgErr.Code, "57P0") || pgErr.Code == "57014"
+	}
+
+	return false
+}
+
 type Row interface {
 	Scan(dest ...any) error
 }
@@ -15,6 +47,7 @@ type Row interface {
 type DB interface {
 	QueryRowStruct(ctx context.Context, dest any, sql string, args ...any) error
 	QueryStruct(ctx context.Context, dest any, sql string, args ...any) error
+	Ping(ctx context.Context) error
 	Close(ctx context.Context) error
 }
 
@@ -35,6 +68,10 @@ func (db *PostgresDB) Close(ctx context.Context) error {
 	return db.conn.Close(ctx)
 }
 
+func (db *PostgresDB) Ping(ctx context.Context) error {
+	return db.conn.Ping(ctx)
+}
+
 func (db *PostgresDB) QueryRowStruct(ctx context.Context, dest any, sql string, args ...any) error {
 	rows, err := db.conn.Query(ctx, sql, args...)
 	if err != nil {
@@ -43,7 +80,7 @@ func (db *PostgresDB) QueryRowStruct(ctx context.Context, dest any, sql string,
 	defer rows.Close()
 
 	if !rows.Next() {
-		return fmt.Errorf("not found")
+		return ErrNotFound
 	}
 
 	return pgxscan.ScanRow(dest, rows)
@@ -0,0 +1,158 @@
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// sharedStoreTable keeps the values of the shared store, created on first use
+const sharedStoreTable = `CREATE TABLE IF NOT EXISTS provisioning_assistant_kv (
+	bucket TEXT NOT NULL,
+	key TEXT NOT NULL,
+	value JSONB NOT NULL,
+	PRIMARY KEY (bucket, key)
+)`
+
+// SharedStore is the key-value store of the split processes, kept in a table of the database they already
+// coordinate through so a record written by one process is read by the others.
+// Statements share one connection, reopened after a failure like the broker's.
+type SharedStore struct {
+	dsn  string
+	conn *pgx.Conn
+	mu   sync.Mutex
+}
+
+// OpenSharedStore connects to the database and creates the table of the store when missing
+func OpenSharedStore(ctx context.Context, dsn string) (*SharedStore, error) {
+	s := &SharedStore{dsn: dsn}
+
+	err := s.exec(ctx, func(conn *pgx.Conn) error {
+		_, err := conn.Exec(ctx, sharedStoreTable)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// Get decodes the value of a key into target
+func (s *SharedStore) Get(ctx context.Context, bucket, key string, target any) error {
+	var data []byte
+
+	err := s.exec(ctx, func(conn *pgx.Conn) error {
+		return conn.QueryRow(ctx,
+			"SELECT value::text FROM provisioning_assistant_kv WHERE bucket = $1 AND key = $2",
+			bucket, key,
+		).Scan(&data)
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrKeyNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(data, target)
+}
+
+// Put stores the value of a key
+func (s *SharedStore) Put(ctx context.Context, bucket, key string, value any) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	return s.exec(ctx, func(conn *pgx.Conn) error {
+		_, err := conn.Exec(ctx,
+			`INSERT INTO provisioning_assistant_kv (bucket, key, value) VALUES ($1, $2, $3::jsonb)
+			ON CONFLICT (bucket, key) DO UPDATE SET value = EXCLUDED.value`,
+			bucket, key, string(data),
+		)
+		return err
+	})
+}
+
+// Delete removes a key, missing keys are ignored
+func (s *SharedStore) Delete(ctx context.Context, bucket, key string) error {
+	return s.exec(ctx, func(conn *pgx.Conn) error {
+		_, err := conn.Exec(ctx, "DELETE FROM provisioning_assistant_kv WHERE bucket = $1 AND key = $2", bucket, key)
+		return err
+	})
+}
+
+// ForEach calls fn with the raw JSON of every key in the bucket, in key order. The rows are read before fn
+// runs, so fn may use the store.
+func (s *SharedStore) ForEach(ctx context.Context, bucket string, fn func(key string, data []byte) error) error {
+	type row struct {
+		key  string
+		data []byte
+	}
+	var rows []row
+
+	err := s.exec(ctx, func(conn *pgx.Conn) error {
+		rows = nil
+		result, err := conn.Query(ctx, "SELECT key, value::text FROM provisioning_assistant_kv WHERE bucket = $1 ORDER BY key", bucket)
+		if err != nil {
+			return err
+		}
+
+		var r row
+		_, err = pgx.ForEachRow(result, []any{&r.key, &r.data}, func() error {
+			rows = append(rows, row{key: r.key, data: append([]byte(nil), r.data...)})
+			return nil
+		})
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, r := range rows {
+		if err := fn(r.key, r.data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close releases the connection of the store
+func (s *SharedStore) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.conn == nil {
+		return nil
+	}
+
+	err := s.conn.Close(context.Background())
+	s.conn = nil
+	return err
+}
+
+// exec runs a statement on the shared connection, opening it when needed and dropping it when the statement
+// fails for a reason other than a missing row
+func (s *SharedStore) exec(ctx context.Context, statement func(conn *pgx.Conn) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.conn == nil || s.conn.IsClosed() {
+		conn, err := pgx.Connect(ctx, s.dsn)
+		if err != nil {
+			return err
+		}
+		s.conn = conn
+	}
+
+	err := statement(s.conn)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		_ = s.conn.Close(context.Background())
+		s.conn = nil
+	}
+	return err
+}
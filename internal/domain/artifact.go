@@ -0,0 +1,28 @@
+package domain
+
+import (
+	"errors"
+	"path"
+	"time"
+)
+
+var ErrArtifactNotFound = errors.New("artefato não encontrado")
+
+// Artifact describes a generated file (report, backup, capture, chart) kept in the artifact store
+type Artifact struct {
+	Key         string     `json:"key"`
+	ContentType string     `json:"content_type"`
+	Size        int64      `json:"size"`
+	CreatedAt   time.Time  `json:"created_at"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+}
+
+// Name returns the file name of the artifact, without its category
+func (a *Artifact) Name() string {
+	return path.Base(a.Key)
+}
+
+// IsExpired reports whether the artifact outlived its retention
+func (a *Artifact) IsExpired(now time.Time) bool {
+	return a.ExpiresAt != nil && now.After(*a.ExpiresAt)
+}
@@ -0,0 +1,84 @@
+package domain
+
+import (
+	"provisioning-assistant/internal/validation"
+	"time"
+)
+
+// AuditRecord stores the outcome of a provisioning job
+type AuditRecord struct {
+	ID                string               `json:"id"`
+	UserID            int64                `json:"user_id"`
+	ChatID            int64                `json:"chat_id"`
+	TechnicianTaxID   string               `json:"technician_tax_id"`
+	TechnicianName    string               `json:"technician_name"`
+	TechnicianProfile *TelegramProfile     `json:"technician_profile,omitempty"`
+	SessionID         string               `json:"session_id,omitempty"`
+	JobID             string               `json:"job_id,omitempty"`
+	Protocol          string               `json:"protocol"`
+	Contract          string               `json:"contract"`
+	ClientName        string               `json:"client_name"`
+	Serial            string               `json:"serial"`
+	OltIP             string               `json:"olt_ip"`
+	Slot              string               `json:"slot"`
+	Port              string               `json:"port"`
+	RxPower           string               `json:"rx_power,omitempty"`
+	SignalLevel       SignalLevel          `json:"signal_level,omitempty"`
+	WanServices       []WanServiceStatus   `json:"wan_services,omitempty"`
+	Commands          []Tl1Command         `json:"tl1_commands,omitempty"`
+	Steps             []StepTiming         `json:"steps,omitempty"`
+	Manual            bool                 `json:"manual"`
+	Reconfigured      bool                 `json:"reconfigured,omitempty"`
+	Success           bool                 `json:"success"`
+	Error             string               `json:"error,omitempty"`
+	Problems          []validation.Problem `json:"problems,omitempty"`
+	OverrideBy        string               `json:"override_by,omitempty"`
+	ReopenedFrom      string               `json:"reopened_from,omitempty"`
+	ReplacedSerial    string               `json:"replaced_serial,omitempty"`
+	Attachments       []AuditAttachment    `json:"attachments"`
+	SpeedTest         *SpeedTest           `json:"speed_test,omitempty"`
+	Troubleshooting   *Troubleshooting     `json:"troubleshooting,omitempty"`
+	Previous          *OnuSnapshot         `json:"previous,omitempty"`
+	RestoredAt        *time.Time           `json:"restored_at,omitempty"`
+	RestoredBy        string               `json:"restored_by,omitempty"`
+	RemovedAt         *time.Time           `json:"removed_at,omitempty"`
+	RemovedBy         string               `json:"removed_by,omitempty"`
+	CreatedAt         time.Time            `json:"created_at"`
+	UpdatedAt         time.Time            `json:"updated_at"`
+}
+
+// AuditAttachment references a proof-of-installation file sent by the technician
+type AuditAttachment struct {
+	FileID    string    `json:"file_id"`
+	Caption   string    `json:"caption,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Tl1Command is a TL1 command sent for a job, kept untagged with its passwords redacted, next to the
+// CTAG it was sent with and the error it got
+type Tl1Command struct {
+	Command string    `json:"command"`
+	Ctag    string    `json:"ctag"`
+	Error   string    `json:"error,omitempty"`
+	SentAt  time.Time `json:"sent_at"`
+}
+
+// SpeedTest is the customer speed test reported by the technician after the installation, next to
+// the contracted speed it was compared with
+type SpeedTest struct {
+	DownloadMbps         float64   `json:"download_mbps"`
+	UploadMbps           float64   `json:"upload_mbps"`
+	ExpectedDownloadMbps int       `json:"expected_download_mbps,omitempty"`
+	ExpectedUploadMbps   int       `json:"expected_upload_mbps,omitempty"`
+	Underperforming      bool      `json:"underperforming"`
+	ReportedAt           time.Time `json:"reported_at"`
+}
+
+// Troubleshooting is the guided diagnosis of an ONU installed without signal or with a very low reception,
+// the answers given by the technician in order and the cause found at the end
+type Troubleshooting struct {
+	Answers    []string   `json:"answers"`
+	Outcome    string     `json:"outcome,omitempty"`
+	StartedAt  time.Time  `json:"started_at"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+}
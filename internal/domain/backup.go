@@ -0,0 +1,32 @@
+package domain
+
+import "time"
+
+// BackupVersion identifies the layout of the exported bot data
+const BackupVersion = 1
+
+// Backup holds every table owned by the bot, used to migrate data between environments.
+// State keeps the values of the migrated state namespaces, keyed by namespace and key.
+type Backup struct {
+	Version   int                          `json:"version"`
+	CreatedAt time.Time                    `json:"created_at"`
+	Bindings  []*Binding                   `json:"bindings"`
+	Audits    []*AuditRecord               `json:"audits"`
+	Tokens    []*BackupToken               `json:"tokens"`
+	State     map[string]map[string]string `json:"state,omitempty"`
+}
+
+// StateEntries counts the state values in the backup
+func (b *Backup) StateEntries() int {
+	var entries int
+	for _, values := range b.State {
+		entries += len(values)
+	}
+	return entries
+}
+
+// BackupToken carries a service-account token together with its secret hash
+type BackupToken struct {
+	*ServiceAccountToken
+	TokenHash string `json:"token_hash"`
+}
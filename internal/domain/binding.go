@@ -0,0 +1,78 @@
+package domain
+
+import (
+	"slices"
+	"time"
+)
+
+// Binding links a Telegram account to a technician and keeps their consent
+type Binding struct {
+	UserID      int64            `json:"user_id"`
+	ChatID      int64            `json:"chat_id"`
+	TaxID       string           `json:"tax_id,omitempty"`
+	Name        string           `json:"name,omitempty"`
+	Consents    []Consent        `json:"consents,omitempty"`
+	Profile     *TelegramProfile `json:"profile,omitempty"`
+	BlockedAt   *time.Time       `json:"blocked_at,omitempty"`
+	SeenVersion string           `json:"seen_version,omitempty"`
+	Preferences Preferences      `json:"preferences"`
+	CreatedAt   time.Time        `json:"created_at"`
+	UpdatedAt   time.Time        `json:"updated_at"`
+}
+
+// Consent records when a user accepted a version of the privacy notice of a tenant
+type Consent struct {
+	Tenant  string    `json:"tenant"`
+	Version string    `json:"version"`
+	At      time.Time `json:"at"`
+}
+
+// HasConsent reports whether the binding holds consent for the given notice version of the tenant
+func (b *Binding) HasConsent(tenant, version string) bool {
+	return slices.ContainsFunc(b.Consents, func(consent Consent) bool {
+		return consent.Tenant == tenant && consent.Version == version
+	})
+}
+
+// IsBlocked reports whether the user blocked the bot and deliveries to the chat are suspended
+func (b *Binding) IsBlocked() bool {
+	return b.BlockedAt != nil
+}
+
+// Preferences are the choices of a technician about the messages the bot sends them, the zero value is the
+// historical behaviour
+type Preferences struct {
+	Compact      bool   `json:"compact,omitempty"`
+	SignalAlerts bool   `json:"signal_alerts,omitempty"`
+	Language     string `json:"language,omitempty"`
+}
+
+// TelegramProfile holds the public Telegram identity of a user, the phone only when shared
+type TelegramProfile struct {
+	Username  string `json:"username,omitempty"`
+	FirstName string `json:"first_name,omitempty"`
+	LastName  string `json:"last_name,omitempty"`
+	Phone     string `json:"phone,omitempty"`
+}
+
+// IsEmpty reports whether no profile field is known
+func (p TelegramProfile) IsEmpty() bool {
+	return p == TelegramProfile{}
+}
+
+// Merge returns the profile updated with the non-empty fields of other
+func (p TelegramProfile) Merge(other TelegramProfile) TelegramProfile {
+	if other.Username != "" {
+		p.Username = other.Username
+	}
+	if other.FirstName != "" {
+		p.FirstName = other.FirstName
+	}
+	if other.LastName != "" {
+		p.LastName = other.LastName
+	}
+	if other.Phone != "" {
+		p.Phone = other.Phone
+	}
+	return p
+}
@@ -0,0 +1,21 @@
+package domain
+
+import "time"
+
+// ConsoleCommand stores a raw TL1 command sent through the super-admin console
+type ConsoleCommand struct {
+	ID         string `json:"id"`
+	UserID     int64  `json:"user_id"`
+	UserName   string `json:"user_name"`
+	TaxID      string `json:"tax_id"`
+	Endpoint   string `json:"endpoint"`
+	Command    string `json:"command"`
+	Radius     string `json:"radius"`
+	ApprovalID string `json:"approval_id,omitempty"`
+	Response   string `json:"response,omitempty"`
+	// TranscriptKey is the artifact holding the full response when it was too long to keep in the record
+	TranscriptKey string        `json:"transcript_key,omitempty"`
+	Error         string        `json:"error,omitempty"`
+	Duration      time.Duration `json:"duration"`
+	CreatedAt     time.Time     `json:"created_at"`
+}
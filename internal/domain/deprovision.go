@@ -0,0 +1,49 @@
+package domain
+
+import "time"
+
+// DeprovisionOutcome tells what a batch did with the ONU of a cancelled contract
+type DeprovisionOutcome string
+
+const (
+	DeprovisionPending DeprovisionOutcome = "pending"
+	DeprovisionRemoved DeprovisionOutcome = "removed"
+	DeprovisionAbsent  DeprovisionOutcome = "absent"
+	DeprovisionReused  DeprovisionOutcome = "reused"
+	DeprovisionUnknown DeprovisionOutcome = "unknown"
+	DeprovisionFailed  DeprovisionOutcome = "failed"
+)
+
+// DeprovisionItem is the ONU of a cancelled contract matched on its OLT
+type DeprovisionItem struct {
+	Contract    string             `json:"contract"`
+	ClientName  string             `json:"client_name"`
+	CancelledAt time.Time          `json:"cancelled_at"`
+	Serial      string             `json:"serial"`
+	OltIP       string             `json:"olt_ip"`
+	Slot        string             `json:"slot"`
+	Port        string             `json:"port"`
+	AuditID     string             `json:"audit_id,omitempty"`
+	Outcome     DeprovisionOutcome `json:"outcome"`
+	Err         string             `json:"error,omitempty"`
+}
+
+// DeprovisionReport sums up a batch over the contracts cancelled since a moment, the items still pending
+// when it was only planned or ran out of room
+type DeprovisionReport struct {
+	Since      time.Time          `json:"since"`
+	StartedAt  time.Time          `json:"started_at"`
+	FinishedAt time.Time          `json:"finished_at"`
+	Items      []*DeprovisionItem `json:"items"`
+}
+
+// Count returns how many items ended with the outcome
+func (r *DeprovisionReport) Count(outcome DeprovisionOutcome) int {
+	count := 0
+	for _, item := range r.Items {
+		if item.Outcome == outcome {
+			count++
+		}
+	}
+	return count
+}
@@ -0,0 +1,15 @@
+package dto
+
+import "time"
+
+// CancelledContract is a contract cancelled in the ERP with the ONU its authentication points to
+type CancelledContract struct {
+	ContractID          uint64    `db:"contract_id"`
+	ContractDescription string    `db:"contract_description"`
+	ClientName          string    `db:"client_name"`
+	CancelledAt         time.Time `db:"cancelled_at"`
+	OltIP               string    `db:"connection_olt_ip"`
+	OltSlot             string    `db:"connection_olt_slot"`
+	OltPort             string    `db:"connection_olt_port"`
+	Serial              string    `db:"connection_equipment_serial_number"`
+}
@@ -1,8 +1,11 @@
 package dto
 
+import "strings"
+
 type ConnectionInfo struct {
 	AssignmentErpID                 uint64 `db:"assignment_erp_id"`
 	AssignmentTitle                 string `db:"assignment_title"`
+	AssignmentStatus                string `db:"assignment_status"`
 	ConnectionOltIP                 string `db:"connection_olt_ip"`
 	ConnectionOltPort               string `db:"connection_olt_port"`
 	ConnectionOltSlot               string `db:"connection_olt_slot"`
@@ -13,6 +16,54 @@ type ConnectionInfo struct {
 	ConnectionClientPPPoEUsername   string `db:"connection_client_pppoe_username"`
 	ConnectionClientPPPoEPassword   string `db:"connection_client_pppoe_password"`
 	ConnectionClientVlan            string `db:"connection_client_vlan"`
+	AuthenticationID                uint64 `db:"authentication_id" json:",omitempty"`
+	ContractID                      uint64 `db:"contract_id" json:",omitempty"`
 	ContractDescription             string `db:"contract_description"`
+	ContractPlanID                  uint64 `db:"contract_plan_id"`
+	ContractPlanName                string `db:"contract_plan_name"`
+	ContractVIP                     bool   `db:"contract_vip"`
 	ClientName                      string `db:"client_name"`
+
+	// ExtraWanServices lists the other WAN connections of the contract, such as IPTV and VoIP VLANs
+	ExtraWanServices []WanService `db:"-" json:",omitempty"`
+
+	// Contact is the customer phone and installation address, shown so the technician can tell
+	// the dispatch is for the house they are at
+	Contact *ContactInfo `db:"-" json:",omitempty"`
+}
+
+// WanService is an additional WAN connection of a contract, bridged when it has no PPPoE username
+type WanService struct {
+	ServiceName   string `db:"service_name"`
+	Vlan          string `db:"vlan"`
+	PPPoEUsername string `db:"pppoe_username"`
+	PPPoEPassword string `db:"pppoe_password"`
+}
+
+// ContactInfo is the customer phone and the address registered for the contract installation
+type ContactInfo struct {
+	Phone        string `db:"phone"`
+	Street       string `db:"street"`
+	Number       string `db:"number"`
+	Complement   string `db:"complement"`
+	Neighborhood string `db:"neighborhood"`
+	City         string `db:"city"`
+}
+
+// Address joins the filled address fields in the usual order, e.g. "Rua A, 10, Casa 2 - Centro, Cidade"
+func (c *ContactInfo) Address() string {
+	street := joinFilled(", ", c.Street, c.Number, c.Complement)
+	locality := joinFilled(", ", c.Neighborhood, c.City)
+	return joinFilled(" - ", street, locality)
+}
+
+// joinFilled joins the non-blank values with the separator
+func joinFilled(separator string, values ...string) string {
+	filled := make([]string, 0, len(values))
+	for _, value := range values {
+		if value = strings.TrimSpace(value); value != "" {
+			filled = append(filled, value)
+		}
+	}
+	return strings.Join(filled, separator)
 }
@@ -0,0 +1,13 @@
+package dto
+
+import "time"
+
+// WorkOrder is a protocol assigned to a technician in the field-service schedule of the ERP
+type WorkOrder struct {
+	AssignmentErpID uint64    `db:"assignment_erp_id"`
+	Protocol        string    `db:"protocol"`
+	AssignmentTitle string    `db:"assignment_title"`
+	TechnicianTaxID string    `db:"technician_tax_id"`
+	ClientName      string    `db:"client_name"`
+	AssignedAt      time.Time `db:"assigned_at"`
+}
@@ -0,0 +1,27 @@
+package domain
+
+import "time"
+
+// ErpEventType identifies what happened to a protocol in the ERP
+type ErpEventType string
+
+const (
+	ErpEventProtocolCreated   ErpEventType = "protocol.created"
+	ErpEventProtocolCancelled ErpEventType = "protocol.cancelled"
+	ErpEventProtocolCorrected ErpEventType = "protocol.corrected"
+)
+
+// ErpEventTypes lists the events the ERP webhook accepts
+var ErpEventTypes = []ErpEventType{ErpEventProtocolCreated, ErpEventProtocolCancelled, ErpEventProtocolCorrected}
+
+// ErpEvent is a change to a protocol pushed by the ERP through its webhook
+type ErpEvent struct {
+	ID              string       `json:"id"`
+	Type            ErpEventType `json:"type"`
+	Protocol        string       `json:"protocol"`
+	TechnicianTaxID string       `json:"technician_cpf,omitempty"`
+	AssignmentTitle string       `json:"assignment_title,omitempty"`
+	ClientName      string       `json:"client_name,omitempty"`
+	Reason          string       `json:"reason,omitempty"`
+	OccurredAt      time.Time    `json:"occurred_at"`
+}
@@ -0,0 +1,31 @@
+package domain
+
+import "time"
+
+const (
+	FeedbackMinRating = 1
+	FeedbackMaxRating = 5
+)
+
+// Feedback is a technician rating of the bot after a job. Anonymous feedback keeps the job it
+// refers to but not who sent it.
+type Feedback struct {
+	ID        string    `json:"id"`
+	JobID     string    `json:"job_id,omitempty"`
+	Protocol  string    `json:"protocol,omitempty"`
+	UserID    int64     `json:"user_id,omitempty"`
+	UserName  string    `json:"user_name,omitempty"`
+	Anonymous bool      `json:"anonymous"`
+	Rating    int       `json:"rating"`
+	Comment   string    `json:"comment,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// FeedbackSummary aggregates the feedback received in a period
+type FeedbackSummary struct {
+	Since    time.Time
+	Count    int
+	Average  float64
+	Ratings  [FeedbackMaxRating + 1]int
+	Comments []*Feedback
+}
@@ -0,0 +1,50 @@
+package domain
+
+import "time"
+
+// StateTransition is a move of a session from one state to another
+type StateTransition struct {
+	From SessionState
+	To   SessionState
+}
+
+// TransitionCount counts how many times sessions made a transition
+type TransitionCount struct {
+	StateTransition
+	Count uint64
+}
+
+// StateMetrics aggregates how sessions went through a state. Dwell is measured on leaving the
+// state, sessions that expired in it count as abandoned and add no dwell.
+type StateMetrics struct {
+	State         SessionState
+	Entries       uint64
+	Exits         uint64
+	Abandoned     uint64
+	InvalidInputs uint64
+	DwellTotal    time.Duration
+	DwellMax      time.Duration
+}
+
+// AverageDwell returns the mean time spent in the state before leaving it
+func (m *StateMetrics) AverageDwell() time.Duration {
+	if m.Exits == 0 {
+		return 0
+	}
+	return m.DwellTotal / time.Duration(m.Exits)
+}
+
+// InvalidRate returns the invalid inputs per entry into the state
+func (m *StateMetrics) InvalidRate() float64 {
+	if m.Entries == 0 {
+		return 0
+	}
+	return float64(m.InvalidInputs) / float64(m.Entries)
+}
+
+// FlowSnapshot is a point-in-time copy of the conversation flow metrics
+type FlowSnapshot struct {
+	Since       time.Time
+	States      []StateMetrics
+	Transitions []TransitionCount
+}
@@ -0,0 +1,28 @@
+package domain
+
+import (
+	"strings"
+	"time"
+)
+
+// IdempotencyKey identifies a provisioning run by the ERP protocol and the ONU serial
+func IdempotencyKey(protocol, serial string) string {
+	return strings.TrimSpace(protocol) + "|" + strings.ToUpper(strings.TrimSpace(serial))
+}
+
+// IdempotencyRecord is written before a provisioning run starts and completed with its outcome,
+// so a repeated run of the same protocol and serial can reuse the previous result
+type IdempotencyRecord struct {
+	Key         string              `json:"key"`
+	JobID       string              `json:"job_id"`
+	UserName    string              `json:"user_name"`
+	AuditID     string              `json:"audit_id,omitempty"`
+	Result      *ProvisioningResult `json:"result,omitempty"`
+	StartedAt   time.Time           `json:"started_at"`
+	CompletedAt *time.Time          `json:"completed_at,omitempty"`
+}
+
+// IsCompleted reports whether the run finished successfully
+func (r *IdempotencyRecord) IsCompleted() bool {
+	return r.CompletedAt != nil
+}
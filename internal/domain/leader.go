@@ -0,0 +1,10 @@
+package domain
+
+import "context"
+
+// LeaderLock is a distributed lock used to elect a single runner for background jobs
+type LeaderLock interface {
+	TryAcquire(ctx context.Context, name string) (bool, error)
+	Check(ctx context.Context) error
+	Release(ctx context.Context, name string) error
+}
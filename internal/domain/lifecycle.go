@@ -0,0 +1,22 @@
+package domain
+
+import "time"
+
+// LifecyclePhase is a step of the application startup or shutdown
+type LifecyclePhase string
+
+const (
+	LifecycleStarting LifecyclePhase = "starting"
+	LifecycleReady    LifecyclePhase = "ready"
+	LifecycleDraining LifecyclePhase = "draining"
+	LifecycleStopped  LifecyclePhase = "stopped"
+)
+
+// LifecycleEvent announces a phase of the application. The listeners of a phase run before the next one
+// begins, so the components are only cancelled once every draining listener returned.
+type LifecycleEvent struct {
+	Phase  LifecyclePhase
+	Mode   string
+	Reason string
+	At     time.Time
+}
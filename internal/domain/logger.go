@@ -46,3 +46,12 @@ type Observability interface {
 	// With context
 	WithContext(ctx context.Context) Observability
 }
+
+// Benchmark logs a step duration through the observability logger when available
+func Benchmark(logger Logger, name string, duration time.Duration) {
+	if observability, ok := logger.(Observability); ok {
+		observability.Benchmark(name, duration)
+		return
+	}
+	logger.WithField("duration", duration.String()).Debugf("Benchmark: %s", name)
+}
@@ -0,0 +1,28 @@
+package domain
+
+import "time"
+
+// MaintenanceWindow is a time-boxed period in which an OLT may not answer, provisioning on it warns
+// the technician and the nightly jobs leave it alone
+type MaintenanceWindow struct {
+	Olt       string    `json:"olt"`
+	Start     time.Time `json:"start"`
+	End       time.Time `json:"end"`
+	Reason    string    `json:"reason,omitempty"`
+	CreatedBy string    `json:"created_by,omitempty"`
+}
+
+// ID identifies the window, an acknowledgement given for a window does not carry over to the next one
+func (w *MaintenanceWindow) ID() string {
+	return w.Olt + "@" + w.Start.UTC().Format("20060102T1504")
+}
+
+// Covers reports whether the window is in progress at the given time
+func (w *MaintenanceWindow) Covers(now time.Time) bool {
+	return !now.Before(w.Start) && now.Before(w.End)
+}
+
+// IsOver reports whether the window already ended
+func (w *MaintenanceWindow) IsOver(now time.Time) bool {
+	return !now.Before(w.End)
+}
@@ -0,0 +1,33 @@
+package domain
+
+import (
+	"slices"
+	"time"
+)
+
+// CriticalNotice is a notification that must be acknowledged by its recipients
+type CriticalNotice struct {
+	ID          string     `json:"id"`
+	Text        string     `json:"text"`
+	ChatIDs     []int64    `json:"chat_ids"`
+	SentAt      time.Time  `json:"sent_at"`
+	Deadline    time.Time  `json:"deadline"`
+	AckedBy     int64      `json:"acked_by,omitempty"`
+	AckedAt     *time.Time `json:"acked_at,omitempty"`
+	EscalatedAt *time.Time `json:"escalated_at,omitempty"`
+}
+
+// IsAcknowledged reports whether someone acted on the notice
+func (n *CriticalNotice) IsAcknowledged() bool {
+	return n.AckedAt != nil
+}
+
+// IsOverdue reports whether the notice expired without acknowledgement or escalation
+func (n *CriticalNotice) IsOverdue(now time.Time) bool {
+	return !n.IsAcknowledged() && n.EscalatedAt == nil && now.After(n.Deadline)
+}
+
+// IsRecipient reports whether the chat received the notice
+func (n *CriticalNotice) IsRecipient(chatID int64) bool {
+	return slices.Contains(n.ChatIDs, chatID)
+}
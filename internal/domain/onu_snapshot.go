@@ -0,0 +1,25 @@
+package domain
+
+import "time"
+
+// OnuSnapshot keeps the OLT-side configuration of an ONU captured before it was replaced,
+// enough to put it back exactly as it was
+type OnuSnapshot struct {
+	OltIP       string               `json:"olt_ip"`
+	Slot        uint                 `json:"slot"`
+	Port        uint                 `json:"port"`
+	Serial      string               `json:"serial"`
+	Name        string               `json:"name"`
+	Model       string               `json:"model"`
+	WanServices []WanServiceSnapshot `json:"wan_services"`
+	CapturedAt  time.Time            `json:"captured_at"`
+}
+
+// WanServiceSnapshot is a WAN service bound to an ONU port or SSID. The password is kept in memory for the
+// restoration of an aborted job only, a snapshot read back from the audit trail gets it from the ERP again.
+type WanServiceSnapshot struct {
+	Port          string `json:"port"`
+	Vlan          string `json:"vlan"`
+	PPPoEUser     string `json:"pppoe_user"`
+	PPPoEPassword string `json:"-"`
+}
@@ -0,0 +1,16 @@
+package domain
+
+import "time"
+
+// OnuState is the last known state of an ONU, as read from the OLT or left by the bot's own provisioning.
+// Fingerprint identifies the configuration the bot applied, empty when the ONU was not provisioned since.
+type OnuState struct {
+	Serial      string
+	OltIP       string
+	Slot        string
+	Port        string
+	Online      bool
+	Signal      *OnuSignalInfo
+	Fingerprint string
+	CheckedAt   time.Time
+}
@@ -0,0 +1,34 @@
+package domain
+
+import "time"
+
+// OperationStatus tracks the approval flow of a destructive operation
+type OperationStatus string
+
+const (
+	OperationPendingPhrase   OperationStatus = "pending_phrase"
+	OperationPendingApproval OperationStatus = "pending_approval"
+	OperationApproved        OperationStatus = "approved"
+	OperationRejected        OperationStatus = "rejected"
+)
+
+// DestructiveOperation is an OLT command affecting more than one ONU awaiting confirmation and approval
+type DestructiveOperation struct {
+	ID            string
+	Description   string
+	Command       string
+	RequestedBy   int64
+	RequesterName string
+	ChatID        int64
+	Phrase        string
+	Status        OperationStatus
+	DecidedBy     int64
+	DeciderName   string
+	CreatedAt     time.Time
+	ExpiresAt     time.Time
+}
+
+// IsExpired reports whether the operation can no longer be confirmed or approved
+func (o *DestructiveOperation) IsExpired(now time.Time) bool {
+	return now.After(o.ExpiresAt)
+}
@@ -0,0 +1,20 @@
+package domain
+
+import "time"
+
+// OrphanOnu is an ONU still registered on its OLT whose contract now points to another serial in the ERP,
+// usually left behind by a swap that failed halfway
+type OrphanOnu struct {
+	Serial        string     `json:"serial"`
+	CurrentSerial string     `json:"current_serial"`
+	Contract      string     `json:"contract"`
+	Protocol      string     `json:"protocol"`
+	ClientName    string     `json:"client_name"`
+	OltIP         string     `json:"olt_ip"`
+	Slot          string     `json:"slot"`
+	Port          string     `json:"port"`
+	AuditID       string     `json:"audit_id"`
+	DetectedAt    time.Time  `json:"detected_at"`
+	IgnoredAt     *time.Time `json:"ignored_at,omitempty"`
+	IgnoredBy     string     `json:"ignored_by,omitempty"`
+}
@@ -0,0 +1,23 @@
+package domain
+
+// ProtocolIssue explains why a protocol should not be provisioned without an override
+type ProtocolIssue string
+
+const (
+	ProtocolIssueNone        ProtocolIssue = ""
+	ProtocolIssueClosed      ProtocolIssue = "closed"
+	ProtocolIssueCancelled   ProtocolIssue = "cancelled"
+	ProtocolIssueProvisioned ProtocolIssue = "provisioned"
+)
+
+// ProtocolCheck is the outcome of the pre-check done before asking for confirmation
+type ProtocolCheck struct {
+	Issue         ProtocolIssue
+	Status        string
+	PreviousAudit *AuditRecord
+}
+
+// RequiresOverride reports whether a supervisor must authorize the provisioning
+func (c ProtocolCheck) RequiresOverride() bool {
+	return c.Issue != ProtocolIssueNone
+}
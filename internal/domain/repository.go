@@ -2,9 +2,70 @@ package domain
 
 import (
 	"context"
+	"errors"
+	"io"
 	"provisioning-assistant/internal/domain/dto"
+	"time"
 )
 
 type ErpRepository interface {
 	GetConnInfoByProtocol(ctx context.Context, protocol string) (*dto.ConnectionInfo, error)
+	GetConnInfoByPPPoEUsername(ctx context.Context, username string) (*dto.ConnectionInfo, error)
+}
+
+// ErrErpSchemaDrift reports ERP columns missing or changed from what the connection queries expect
+var ErrErpSchemaDrift = errors.New("esquema do ERP divergente")
+
+// ErpSchemaChecker is implemented by ERP repositories able to validate the schema they query
+type ErpSchemaChecker interface {
+	CheckSchema(ctx context.Context) error
+}
+
+// ErpPinger is implemented by ERP repositories backed by a server that may go away
+type ErpPinger interface {
+	Ping(ctx context.Context) error
+}
+
+// ErpWorkOrderLister is implemented by ERP repositories able to list the protocols assigned to technicians
+type ErpWorkOrderLister interface {
+	ListWorkOrders(ctx context.Context, since time.Time) ([]*dto.WorkOrder, error)
+}
+
+// ErpCancellationLister is implemented by ERP repositories able to list the contracts cancelled over a period
+type ErpCancellationLister interface {
+	ListCancelledContracts(ctx context.Context, since time.Time) ([]*dto.CancelledContract, error)
+}
+
+type AuditRepository interface {
+	Save(ctx context.Context, record *AuditRecord) error
+	FindByID(ctx context.Context, id string) (*AuditRecord, error)
+	List(ctx context.Context) ([]*AuditRecord, error)
+	Delete(ctx context.Context, id string) error
+}
+
+type TokenRepository interface {
+	Save(ctx context.Context, token *ServiceAccountToken) error
+	FindByID(ctx context.Context, id string) (*ServiceAccountToken, error)
+	FindByHash(ctx context.Context, hash string) (*ServiceAccountToken, error)
+	List(ctx context.Context) ([]*ServiceAccountToken, error)
+}
+
+type BindingRepository interface {
+	Save(ctx context.Context, binding *Binding) error
+	FindByUserID(ctx context.Context, userID int64) (*Binding, error)
+	List(ctx context.Context) ([]*Binding, error)
+}
+
+type StateRepository interface {
+	Get(ctx context.Context, namespace, key string) (string, error)
+	Set(ctx context.Context, namespace, key, value string) error
+	List(ctx context.Context, namespace string) (map[string]string, error)
+	Delete(ctx context.Context, namespace, key string) error
+}
+
+type ArtifactStore interface {
+	Put(ctx context.Context, artifact *Artifact, content io.Reader) error
+	Open(ctx context.Context, key string) (io.ReadCloser, *Artifact, error)
+	List(ctx context.Context, prefix string) ([]*Artifact, error)
+	Delete(ctx context.Context, key string) error
 }
@@ -0,0 +1,44 @@
+package domain
+
+import (
+	"slices"
+	"time"
+)
+
+// Scope defines a permission granted to a service-account token
+type Scope string
+
+const (
+	ScopeReadOnu        Scope = "read:onu"
+	ScopeWriteProvision Scope = "write:provision"
+	ScopeReadReports    Scope = "read:reports"
+)
+
+// AllScopes lists every scope a token may be granted
+var AllScopes = []Scope{ScopeReadOnu, ScopeWriteProvision, ScopeReadReports}
+
+// ServiceAccountToken grants least-privilege API access to an internal consumer
+type ServiceAccountToken struct {
+	ID        string     `json:"id"`
+	Name      string     `json:"name"`
+	Scopes    []Scope    `json:"scopes"`
+	TokenHash string     `json:"-"`
+	CreatedBy int64      `json:"created_by"`
+	CreatedAt time.Time  `json:"created_at"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty"`
+}
+
+// HasScope reports whether the token grants the given scope
+func (t *ServiceAccountToken) HasScope(scope Scope) bool {
+	return slices.Contains(t.Scopes, scope)
+}
+
+// IsRevoked reports whether the token has been revoked
+func (t *ServiceAccountToken) IsRevoked() bool {
+	return t.RevokedAt != nil
+}
+
+// IsValid reports whether the scope is known
+func (s Scope) IsValid() bool {
+	return slices.Contains(AllScopes, s)
+}
@@ -0,0 +1,39 @@
+package domain
+
+import (
+	"strconv"
+	"strings"
+)
+
+// SignalLevel classifies an optical reception power against the thresholds of its network segment
+type SignalLevel string
+
+const (
+	SignalUnknown  SignalLevel = ""
+	SignalOK       SignalLevel = "ok"
+	SignalWarning  SignalLevel = "warning"
+	SignalCritical SignalLevel = "critical"
+)
+
+// SignalThreshold holds the reception power, in dBm, below which a signal is a warning or critical
+type SignalThreshold struct {
+	Warn     float64 `json:"warn"`
+	Critical float64 `json:"critical"`
+}
+
+// Classify rates a reception power reading as reported by the OLT, e.g. "-19.50"
+func (t SignalThreshold) Classify(rxPower string) SignalLevel {
+	value, err := strconv.ParseFloat(strings.TrimSpace(rxPower), 64)
+	if err != nil {
+		return SignalUnknown
+	}
+
+	switch {
+	case value < t.Critical:
+		return SignalCritical
+	case value < t.Warn:
+		return SignalWarning
+	default:
+		return SignalOK
+	}
+}
@@ -0,0 +1,69 @@
+package domain
+
+import (
+	"strings"
+	"time"
+)
+
+// TemplateCatalogVersion identifies the layout of an exported template catalog
+const TemplateCatalogVersion = 1
+
+// ProvisioningTemplate maps a contract plan to its ONU provisioning parameters
+type ProvisioningTemplate struct {
+	Name              string              `json:"name"`
+	PlanIDs           []uint64            `json:"plan_ids,omitempty"`
+	PlanNameContains  string              `json:"plan_name_contains,omitempty"`
+	Default           bool                `json:"default,omitempty"`
+	Vlan              string              `json:"vlan,omitempty"`
+	UpstreamProfile   string              `json:"upstream_profile,omitempty"`
+	DownstreamProfile string              `json:"downstream_profile,omitempty"`
+	DBAProfile        string              `json:"dba_profile,omitempty"`
+	DownloadMbps      int                 `json:"download_mbps,omitempty"`
+	UploadMbps        int                 `json:"upload_mbps,omitempty"`
+	WanPorts          []string            `json:"wan_ports,omitempty"`
+	ServicePorts      map[string][]string `json:"service_ports,omitempty"`
+	MulticastVlan     string              `json:"multicast_vlan,omitempty"`
+	MulticastPorts    []string            `json:"multicast_ports,omitempty"`
+	WifiEnabled       *bool               `json:"wifi_enabled,omitempty"`
+	SuccessSections   []string            `json:"success_sections,omitempty"`
+}
+
+// Matches reports whether the template applies to the given contract plan
+func (t *ProvisioningTemplate) Matches(planID uint64, planName string) bool {
+	for _, id := range t.PlanIDs {
+		if id != 0 && id == planID {
+			return true
+		}
+	}
+
+	if t.PlanNameContains == "" || planName == "" {
+		return false
+	}
+
+	return strings.Contains(strings.ToLower(planName), strings.ToLower(t.PlanNameContains))
+}
+
+// TemplateCatalog holds the provisioning templates, with the WAN profile of every plan, as moved
+// between environments
+type TemplateCatalog struct {
+	Version    int                    `json:"version"`
+	ExportedAt time.Time              `json:"exported_at"`
+	Templates  []ProvisioningTemplate `json:"templates"`
+}
+
+// TemplateChangeKind tells how an imported template differs from the one in use
+type TemplateChangeKind string
+
+const (
+	TemplateAdded   TemplateChangeKind = "added"
+	TemplateRemoved TemplateChangeKind = "removed"
+	TemplateChanged TemplateChangeKind = "changed"
+)
+
+// TemplateChange describes a template added, removed or changed by an import, Fields naming the
+// JSON keys that differ
+type TemplateChange struct {
+	Name   string
+	Kind   TemplateChangeKind
+	Fields []string
+}
@@ -0,0 +1,29 @@
+package domain
+
+import "context"
+
+// Tl1Operation classifies what a TL1 operation does to the OLT, each role is granted a set of them
+type Tl1Operation string
+
+const (
+	Tl1OperationQuery     Tl1Operation = "query"
+	Tl1OperationProvision Tl1Operation = "provision"
+	Tl1OperationDelete    Tl1Operation = "delete"
+	Tl1OperationConsole   Tl1Operation = "console"
+)
+
+// Tl1Operations lists every operation class, in ascending order of risk
+var Tl1Operations = []Tl1Operation{Tl1OperationQuery, Tl1OperationProvision, Tl1OperationDelete, Tl1OperationConsole}
+
+type roleKey struct{}
+
+// WithRole marks the context with the role of the user the operations run for
+func WithRole(ctx context.Context, role Role) context.Context {
+	return context.WithValue(ctx, roleKey{}, role)
+}
+
+// RoleFrom returns the role carried by the context, empty for system operations
+func RoleFrom(ctx context.Context) Role {
+	role, _ := ctx.Value(roleKey{}).(Role)
+	return role
+}
@@ -0,0 +1,16 @@
+package domain
+
+import "context"
+
+type trainingKey struct{}
+
+// WithTraining marks the context as belonging to a training session, routed to the sandbox backends
+func WithTraining(ctx context.Context) context.Context {
+	return context.WithValue(ctx, trainingKey{}, true)
+}
+
+// IsTraining reports whether the context belongs to a training session
+func IsTraining(ctx context.Context) bool {
+	training, _ := ctx.Value(trainingKey{}).(bool)
+	return training
+}
@@ -2,59 +2,179 @@
 package domain
 
 import (
+	"errors"
 	"provisioning-assistant/internal/domain/dto"
+	"slices"
 	"time"
 )
 
+// ErrChatUnavailable is returned for deliveries to a chat that blocked the bot or no longer exists
+var ErrChatUnavailable = errors.New("chat indisponível para o bot")
+
 // Events
 type MessageEvent struct {
+	UserID       int64
+	ChatID       int64
+	Message      string
+	PhotoFileID  string
+	PhotoCaption string
+	Location     *Location
+	Profile      TelegramProfile
+}
+
+// Location is a point shared by the user
+type Location struct {
+	Latitude  float64
+	Longitude float64
+}
+
+// IsContact reports whether the message only carries a shared phone number
+func (e *MessageEvent) IsContact() bool {
+	return e.Message == "" && e.PhotoFileID == "" && e.Location == nil && e.Profile.Phone != ""
+}
+
+// IsLocation reports whether the message carries a shared location
+func (e *MessageEvent) IsLocation() bool {
+	return e.Location != nil
+}
+
+// ErrorEvent is an unhandled failure, such as a recovered panic, sent to the error channel
+type ErrorEvent struct {
+	Source string
+	Err    error
+	Stack  string
+}
+
+type CallbackEvent struct {
 	UserID  int64
 	ChatID  int64
-	Message string
+	Data    string
+	Profile TelegramProfile
 }
 
-type CallbackEvent struct {
-	UserID int64
-	ChatID int64
-	Data   string
+type InlineQueryEvent struct {
+	QueryID string
+	UserID  int64
+	Query   string
 }
 
 // Responses
+type InlineAnswer struct {
+	QueryID   string
+	Results   []InlineResult
+	CacheTime time.Duration
+}
+
+type InlineResult struct {
+	ID          string
+	Title       string
+	Description string
+	Text        string
+}
+
 type MessageResponse struct {
 	ChatID   int64
 	Text     string
 	Keyboard *Keyboard
 }
 
+// Document is a file sent to a chat, such as an exported report
+type Document struct {
+	ChatID  int64
+	Name    string
+	Content []byte
+	Caption string
+}
+
 type Keyboard struct {
 	Inline  bool
 	Buttons [][]Button
 }
 
+// ButtonKind is what a button does when pressed, the zero value sends its callback data
+type ButtonKind string
+
+const (
+	ButtonCallback        ButtonKind = ""
+	ButtonURL             ButtonKind = "url"
+	ButtonWebApp          ButtonKind = "web_app"
+	ButtonRequestContact  ButtonKind = "request_contact"
+	ButtonRequestLocation ButtonKind = "request_location"
+)
+
+// RepliesOnly reports whether the button only works on reply keyboards, as the requests of the user's
+// contact and location
+func (k ButtonKind) RepliesOnly() bool {
+	return k == ButtonRequestContact || k == ButtonRequestLocation
+}
+
 type Button struct {
 	Text string
+	Kind ButtonKind
 	Data string
+	URL  string
 }
 
 // Session states
 type SessionState string
 
 const (
-	StateIdle             SessionState = "idle"
-	StateWaitingCPF       SessionState = "waiting_cpf"
-	StateMainMenu         SessionState = "main_menu"
-	StateServiceSelection SessionState = "service_selection"
-	StateWaitingProtocol  SessionState = "waiting_protocol"
-	StateConfirmData      SessionState = "confirm_data"
-	StateProvisioning     SessionState = "provisioning"
-	StateMaintenanceMenu  SessionState = "maintenance_menu"
-	StateWaitingOldSerial SessionState = "waiting_old_serial"
-	StateAddressChange    SessionState = "address_change"
-	StateWaitingOLT       SessionState = "waiting_olt"
-	StateWaitingSlot      SessionState = "waiting_slot"
-	StateWaitingPort      SessionState = "waiting_port"
+	StateIdle                   SessionState = "idle"
+	StateWaitingChallenge       SessionState = "waiting_challenge"
+	StateWaitingConsent         SessionState = "waiting_consent"
+	StateWaitingCPF             SessionState = "waiting_cpf"
+	StateMainMenu               SessionState = "main_menu"
+	StateServiceSelection       SessionState = "service_selection"
+	StateWaitingProtocol        SessionState = "waiting_protocol"
+	StateWaitingPPPoESearch     SessionState = "waiting_pppoe_search"
+	StateConfirmData            SessionState = "confirm_data"
+	StateProvisioning           SessionState = "provisioning"
+	StateMaintenanceMenu        SessionState = "maintenance_menu"
+	StateWaitingOldSerial       SessionState = "waiting_old_serial"
+	StateAddressChange          SessionState = "address_change"
+	StateWaitingOLT             SessionState = "waiting_olt"
+	StateWaitingSlot            SessionState = "waiting_slot"
+	StateWaitingPort            SessionState = "waiting_port"
+	StateWaitingPhotos          SessionState = "waiting_photos"
+	StateWaitingSpeedTest       SessionState = "waiting_speed_test"
+	StateWaitingSerial          SessionState = "waiting_serial"
+	StateWaitingVlan            SessionState = "waiting_vlan"
+	StateWaitingPPPoEUser       SessionState = "waiting_pppoe_user"
+	StateWaitingPPPoEPass       SessionState = "waiting_pppoe_pass"
+	StateWaitingOperationPhrase SessionState = "waiting_operation_phrase"
+	StateWaitingOverride        SessionState = "waiting_override"
+	StateTl1Console             SessionState = "tl1_console"
+	StateWaitingFeedbackRating  SessionState = "waiting_feedback_rating"
+	StateWaitingFeedbackComment SessionState = "waiting_feedback_comment"
+	StateReopenMenu             SessionState = "reopen_menu"
+	StateWaitingReopenSerial    SessionState = "waiting_reopen_serial"
+	StateConfirmRollback        SessionState = "confirm_rollback"
 )
 
+// User roles
+type Role string
+
+const (
+	RoleTechnician Role = "technician"
+	RoleSupervisor Role = "supervisor"
+	RoleAdmin      Role = "admin"
+
+	// RoleSystem is the role of the scheduled jobs that change the OLTs unattended, it ranks below every
+	// user role so no command accepts it
+	RoleSystem Role = "system"
+)
+
+var roleRanks = map[Role]int{
+	RoleTechnician: 1,
+	RoleSupervisor: 2,
+	RoleAdmin:      3,
+}
+
+// Includes reports whether the role grants at least the required role privileges
+func (r Role) Includes(required Role) bool {
+	return roleRanks[r] >= roleRanks[required]
+}
+
 // Service types
 type ServiceType string
 
@@ -73,28 +193,69 @@ const (
 
 // Session
 type Session struct {
+	ID              string
 	UserID          int64
 	ChatID          int64
 	State           SessionState
 	UserTaxID       string
 	UserName        string
+	UserRole        Role
 	ServiceType     ServiceType
 	MaintenanceType MaintenanceType
+	Manual          bool
 	Protocol        string
 	ConnectionInfo  *dto.ConnectionInfo
+	ErpFetchTime    time.Duration
+	ProtocolCheck   ProtocolCheck
+	OverrideBy      string
+	Profile         TelegramProfile
+	JobID           string
+	ConsoleEndpoint string
 	OldSerialNumber string
 	OLT             string
 	Slot            string
 	Port            string
+	AnsweredSteps   []SessionState
+	AuditID         string
+	ReopenedFrom    string
+	ReplacedSerial  string
+	RollbackAudit   string
+	Feedback        *Feedback
+	InvalidAttempts int
+	StateEnteredAt  time.Time
+	NudgedAt        time.Time
+	ConfirmAskedAt  time.Time
+	MaintenanceAck  string
 	CreatedAt       time.Time
 	UpdatedAt       time.Time
 }
 
+// Clone returns a deep copy of the session
+func (s *Session) Clone() *Session {
+	clone := *s
+	if s.ConnectionInfo != nil {
+		connInfo := *s.ConnectionInfo
+		connInfo.ExtraWanServices = slices.Clone(connInfo.ExtraWanServices)
+		if connInfo.Contact != nil {
+			contact := *connInfo.Contact
+			connInfo.Contact = &contact
+		}
+		clone.ConnectionInfo = &connInfo
+	}
+	clone.AnsweredSteps = slices.Clone(s.AnsweredSteps)
+	if s.Feedback != nil {
+		feedback := *s.Feedback
+		clone.Feedback = &feedback
+	}
+	return &clone
+}
+
 // User
 type User struct {
 	ID        int64
 	CPF       string
 	Name      string
+	Role      Role
 	IsValid   bool
 	CreatedAt time.Time
 }
@@ -111,8 +272,90 @@ type Equipment struct {
 	UpdatedAt    time.Time
 }
 
-// ONU Signal Info
+// ONU Signal Info, rated against the thresholds of the OLT it was read on
 type OnuSignalInfo struct {
-	TxPower string
-	RxPower string
+	TxPower     string
+	RxPower     string
+	Voltage     string
+	Temperature string
+	Level       SignalLevel
+	Threshold   *SignalThreshold
+}
+
+// StepErpFetch is the step reading the request from the ERP, the only one not spent on the OLT
+const StepErpFetch = "erp_fetch"
+
+// StepTiming records how long a provisioning step took
+type StepTiming struct {
+	Name     string        `json:"name"`
+	Duration time.Duration `json:"duration"`
+}
+
+// ProvisioningResult carries the signal read after provisioning, the time spent in each step,
+// the plan template applied and the configuration the ONU had before, if any. Overwrote tells the
+// job replaced a registration found on the target PON, the one Restored puts back after a failure,
+// Discarded that an aborted run removed the ONU it left half configured, Reconfigured that an ONU
+// already registered for the contract only had its services reapplied.
+type ProvisioningResult struct {
+	Signal       *OnuSignalInfo
+	Steps        []StepTiming
+	WanServices  []WanServiceStatus
+	Template     *ProvisioningTemplate
+	Previous     *OnuSnapshot
+	Commands     []Tl1Command
+	Overwrote    bool
+	Restored     bool
+	Discarded    bool
+	Reconfigured bool
+
+	// RolledBack names the steps under a percentage rollout sent back to the old path by this job
+	RolledBack []string
+}
+
+// WanServiceStatus tells how the configuration of one WAN service went, the primary one being
+// the PPPoE data service and the multicast one the IPTV VLAN joined through IGMP
+type WanServiceStatus struct {
+	Name      string `json:"name,omitempty"`
+	Vlan      string `json:"vlan"`
+	Primary   bool   `json:"primary,omitempty"`
+	Multicast bool   `json:"multicast,omitempty"`
+	Err       string `json:"error,omitempty"`
+}
+
+// WanServicesFailed reports whether any extra WAN service could not be configured
+func (r *ProvisioningResult) WanServicesFailed() bool {
+	return slices.ContainsFunc(r.WanServices, func(status WanServiceStatus) bool {
+		return status.Err != ""
+	})
+}
+
+// Total returns the time spent across every step
+func (r *ProvisioningResult) Total() time.Duration {
+	var total time.Duration
+	for _, step := range r.Steps {
+		total += step.Duration
+	}
+	return total
+}
+
+// StepFields returns the step durations keyed by step name for structured logs
+func (r *ProvisioningResult) StepFields() map[string]any {
+	fields := make(map[string]any, len(r.Steps))
+	for _, step := range r.Steps {
+		fields[step.Name] = step.Duration.String()
+	}
+	return fields
+}
+
+// LastJob keeps the context of the last successful provisioning of a user
+type LastJob struct {
+	UserID     int64     `json:"user_id"`
+	JobID      string    `json:"job_id"`
+	Protocol   string    `json:"protocol,omitempty"`
+	Contract   string    `json:"contract,omitempty"`
+	Serial     string    `json:"serial"`
+	OltIP      string    `json:"olt_ip"`
+	Slot       string    `json:"slot"`
+	Port       string    `json:"port"`
+	FinishedAt time.Time `json:"finished_at"`
 }
@@ -0,0 +1,21 @@
+package domain
+
+// WifiScanReport summarizes the Wi-Fi networks around an ONU, per band
+type WifiScanReport struct {
+	Networks int
+	Bands    []WifiBandReport
+}
+
+// WifiBandReport holds the channel occupancy of a band and the least crowded channel
+type WifiBandReport struct {
+	Band        string
+	Channels    []WifiChannelUsage
+	Recommended int
+}
+
+// WifiChannelUsage counts the networks on a channel and the strongest signal among them
+type WifiChannelUsage struct {
+	Channel   int
+	Networks  int
+	Strongest int
+}
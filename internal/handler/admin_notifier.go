@@ -0,0 +1,196 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"provisioning-assistant/internal/domain"
+	"provisioning-assistant/internal/services"
+	"slices"
+	"sync"
+)
+
+// AdminNotifier delivers operational alerts to the configured admin chats
+type AdminNotifier struct {
+	mu                sync.RWMutex
+	chatIDs           []int64
+	escalationChatIDs []int64
+	ackService        *services.AckService
+	keyboards         *KeyboardCatalog
+	messenger         *Messenger
+	logger            domain.Logger
+}
+
+// NewAdminNotifier creates a new admin alert notifier
+func NewAdminNotifier(chatIDs, escalationChatIDs []int64, ackService *services.AckService, keyboards *KeyboardCatalog, messenger *Messenger, logger domain.Logger) *AdminNotifier {
+	return &AdminNotifier{
+		chatIDs:           chatIDs,
+		escalationChatIDs: escalationChatIDs,
+		ackService:        ackService,
+		keyboards:         keyboards,
+		messenger:         messenger,
+		logger:            logger,
+	}
+}
+
+// Notify sends an alert message to every admin chat
+func (n *AdminNotifier) Notify(ctx context.Context, text string) {
+	chatIDs := n.adminChats()
+	if len(chatIDs) == 0 {
+		n.logger.WithField("alert", text).Warn("Alerta sem chats de administração configurados")
+		return
+	}
+
+	for _, chatID := range chatIDs {
+		if err := n.messenger.SendMessage(ctx, chatID, text); err != nil {
+			n.logger.WithError(err).WithField("chat_id", chatID).Error("Falha ao enviar alerta para administrador")
+		}
+	}
+}
+
+// NotifyWithKeyboard sends an alert with action buttons to every admin chat
+func (n *AdminNotifier) NotifyWithKeyboard(ctx context.Context, text string, keyboard *domain.Keyboard) {
+	chatIDs := n.adminChats()
+	if len(chatIDs) == 0 {
+		n.logger.WithField("alert", text).Warn("Alerta sem chats de administração configurados")
+		return
+	}
+
+	for _, chatID := range chatIDs {
+		if err := n.messenger.SendMessageWithKeyboard(ctx, chatID, text, keyboard); err != nil {
+			n.logger.WithError(err).WithField("chat_id", chatID).Error("Falha ao enviar alerta para administrador")
+		}
+	}
+}
+
+// NotifyCritical sends an alert that must be acknowledged, escalating it when nobody acts in time
+func (n *AdminNotifier) NotifyCritical(ctx context.Context, text string) {
+	chatIDs := n.adminChats()
+	if len(chatIDs) == 0 {
+		n.logger.WithField("alert", text).Warn("Alerta crítico sem chats de administração configurados")
+		return
+	}
+
+	notice := n.ackService.Track(ctx, text, chatIDs)
+	n.sendNotice(ctx, notice, chatIDs, text)
+}
+
+// NotifyEscalated sends a critical alert to the admin and escalation chats at once, skipping the acknowledgement wait
+func (n *AdminNotifier) NotifyEscalated(ctx context.Context, text string) {
+	chatIDs := n.adminChats()
+	for _, chatID := range n.escalationChats() {
+		if !slices.Contains(chatIDs, chatID) {
+			chatIDs = append(chatIDs, chatID)
+		}
+	}
+
+	if len(chatIDs) == 0 {
+		n.logger.WithField("alert", text).Warn("Alerta crítico sem chats de administração configurados")
+		return
+	}
+
+	notice := n.ackService.TrackEscalated(ctx, text, chatIDs)
+	n.sendNotice(ctx, notice, chatIDs, text)
+}
+
+// EscalateOverdue forwards unacknowledged critical alerts to the escalation contacts
+func (n *AdminNotifier) EscalateOverdue(ctx context.Context) error {
+	escalationChatIDs := n.escalationChats()
+	notices := n.ackService.Escalate(ctx, escalationChatIDs)
+
+	for _, notice := range notices {
+		log := n.logger.WithField("notice_id", notice.ID)
+
+		if len(escalationChatIDs) == 0 {
+			log.Warn("Alerta crítico sem confirmação e sem contatos de escalonamento configurados")
+			continue
+		}
+
+		log.Warn("Alerta crítico sem confirmação, escalonando")
+		text := fmt.Sprintf(MSG_ALERT_ESCALATED, int(notice.Deadline.Sub(notice.SentAt).Minutes()), notice.Text)
+		n.sendNotice(ctx, notice, escalationChatIDs, text)
+	}
+
+	return nil
+}
+
+// HandleAckOption records the acknowledgement of a critical alert
+func (n *AdminNotifier) HandleAckOption(ctx context.Context, callback *domain.CallbackEvent, noticeID string) error {
+	notice, acked, err := n.ackService.Acknowledge(ctx, noticeID, callback.ChatID, callback.UserID)
+	if err != nil {
+		if errors.Is(err, services.ErrNoticeNotRecipient) {
+			n.logger.WithFields(map[string]any{
+				"notice_id": noticeID,
+				"chat_id":   callback.ChatID,
+			}).Warn("Confirmação de alerta recebida de chat não destinatário")
+		}
+		return n.messenger.SendMessage(ctx, callback.ChatID, MSG_ALERT_ACK_UNKNOWN)
+	}
+
+	if !acked {
+		return n.messenger.SendMessage(ctx, callback.ChatID, fmt.Sprintf(MSG_ALERT_ACK_ALREADY, notice.AckedBy))
+	}
+
+	n.logger.WithFields(map[string]any{
+		"notice_id": notice.ID,
+		"user_id":   callback.UserID,
+	}).Info("Alerta crítico confirmado")
+
+	for _, chatID := range notice.ChatIDs {
+		if err := n.messenger.SendMessage(ctx, chatID, fmt.Sprintf(MSG_ALERT_ACKED, callback.UserID)); err != nil {
+			n.logger.WithError(err).WithField("chat_id", chatID).Error("Falha ao informar confirmação de alerta")
+		}
+	}
+
+	return nil
+}
+
+// sendNotice sends a critical notice with its acknowledgement button
+func (n *AdminNotifier) sendNotice(ctx context.Context, notice domain.CriticalNotice, chatIDs []int64, text string) {
+	keyboard := n.keyboards.Build(KeyboardAlertAck, WithTarget(notice.ID))
+
+	for _, chatID := range chatIDs {
+		if err := n.messenger.SendMessageWithKeyboard(ctx, chatID, text, keyboard); err != nil {
+			n.logger.WithError(err).WithField("chat_id", chatID).Error("Falha ao enviar alerta crítico")
+		}
+	}
+}
+
+// ReplaceChat points the admin and escalation lists at the supergroup a group migrated to
+func (n *AdminNotifier) ReplaceChat(from, to int64) bool {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	replaced := false
+	for _, chatIDs := range [][]int64{n.chatIDs, n.escalationChatIDs} {
+		for i, chatID := range chatIDs {
+			if chatID == from {
+				chatIDs[i] = to
+				replaced = true
+			}
+		}
+	}
+
+	return replaced
+}
+
+// IsAdminChat reports whether the chat receives admin alerts or escalations
+func (n *AdminNotifier) IsAdminChat(chatID int64) bool {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	return slices.Contains(n.chatIDs, chatID) || slices.Contains(n.escalationChatIDs, chatID)
+}
+
+// adminChats returns a copy of the admin chat list
+func (n *AdminNotifier) adminChats() []int64 {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	return slices.Clone(n.chatIDs)
+}
+
+// escalationChats returns a copy of the escalation chat list
+func (n *AdminNotifier) escalationChats() []int64 {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	return slices.Clone(n.escalationChatIDs)
+}
@@ -0,0 +1,79 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"provisioning-assistant/internal/domain"
+	"provisioning-assistant/internal/services"
+)
+
+const DefaultMaxInvalidAttempts = 5
+
+// InputPolicy defines how many invalid answers a step accepts, who to contact for help and how the text of
+// the steps differing from the full normalization is cleaned
+type InputPolicy struct {
+	MaxInvalidAttempts int
+	SupportContact     string
+	Normalization      map[domain.SessionState]Normalization
+}
+
+// AttemptGuard counts invalid answers per step and ends the session gracefully after too many
+type AttemptGuard struct {
+	policy         InputPolicy
+	sessionService *services.SessionService
+	messenger      *Messenger
+	logger         domain.Logger
+}
+
+// NewAttemptGuard creates a new invalid input guard, a zero limit falls back to the default
+func NewAttemptGuard(policy InputPolicy, sessionService *services.SessionService, messenger *Messenger, logger domain.Logger) *AttemptGuard {
+	if policy.MaxInvalidAttempts <= 0 {
+		policy.MaxInvalidAttempts = DefaultMaxInvalidAttempts
+	}
+
+	return &AttemptGuard{
+		policy:         policy,
+		sessionService: sessionService,
+		messenger:      messenger,
+		logger:         logger,
+	}
+}
+
+// Reject registers an invalid answer, warning on the last attempt and ending the session when the limit is reached
+func (g *AttemptGuard) Reject(ctx context.Context, session *domain.Session, message string) error {
+	updateSession(g.sessionService, session, func(s *domain.Session) {
+		s.InvalidAttempts++
+	})
+
+	remaining := g.policy.MaxInvalidAttempts - session.InvalidAttempts
+	if remaining > 0 {
+		if remaining == 1 {
+			message += MSG_LAST_ATTEMPT_HINT
+		}
+		return g.messenger.SendMessage(ctx, session.ChatID, message)
+	}
+
+	g.logger.WithFields(map[string]any{
+		"user_id":  session.UserID,
+		"state":    session.State,
+		"attempts": session.InvalidAttempts,
+	}).Warn("Sessão encerrada por excesso de respostas inválidas")
+
+	updateSession(g.sessionService, session, func(s *domain.Session) {
+		s.State = domain.StateIdle
+		s.Manual = false
+		s.Protocol = ""
+		s.ConnectionInfo = nil
+		s.ErpFetchTime = 0
+		s.AuditID = ""
+		s.ReopenedFrom = ""
+		s.ReplacedSerial = ""
+	})
+
+	text := MSG_TOO_MANY_ATTEMPTS
+	if g.policy.SupportContact != "" {
+		text += fmt.Sprintf(MSG_SUPPORT_CONTACT, g.policy.SupportContact)
+	}
+
+	return g.messenger.SendMessage(ctx, session.ChatID, text)
+}
@@ -1,68 +1,179 @@
 package handler
 
 import (
+	"context"
 	"fmt"
 	"provisioning-assistant/internal/domain"
 	"provisioning-assistant/internal/services"
+	"provisioning-assistant/internal/validation"
+	"strconv"
 	"strings"
-	"time"
 )
 
 type AuthenticationHandler struct {
-	userService    *services.UserService
-	sessionService *services.SessionService
-	messenger      *Messenger
-	logger         domain.Logger
+	userService        *services.UserService
+	sessionService     *services.SessionService
+	bindingService     *services.BindingService
+	accessGuardService *services.AccessGuardService
+	challengeHandler   *ChallengeHandler
+	attemptGuard       *AttemptGuard
+	adminNotifier      *AdminNotifier
+	menuHandler        *MenuHandler
+	messenger          *Messenger
+	pacer              *Pacer
+	logger             domain.Logger
 }
 
 // NewAuthenticationHandler creates a new authentication handler instance
 func NewAuthenticationHandler(
 	userService *services.UserService,
 	sessionService *services.SessionService,
+	bindingService *services.BindingService,
+	accessGuardService *services.AccessGuardService,
+	challengeHandler *ChallengeHandler,
+	attemptGuard *AttemptGuard,
+	adminNotifier *AdminNotifier,
+	menuHandler *MenuHandler,
 	messenger *Messenger,
+	pacer *Pacer,
 	logger domain.Logger,
 ) *AuthenticationHandler {
 	return &AuthenticationHandler{
-		userService:    userService,
-		sessionService: sessionService,
-		messenger:      messenger,
-		logger:         logger,
+		userService:        userService,
+		sessionService:     sessionService,
+		bindingService:     bindingService,
+		accessGuardService: accessGuardService,
+		challengeHandler:   challengeHandler,
+		attemptGuard:       attemptGuard,
+		adminNotifier:      adminNotifier,
+		menuHandler:        menuHandler,
+		messenger:          messenger,
+		pacer:              pacer,
+		logger:             logger,
 	}
 }
 
 // HandleCPFInput processes CPF input for user authentication
-func (h *AuthenticationHandler) HandleCPFInput(session *domain.Session, msg *domain.MessageEvent) error {
+func (h *AuthenticationHandler) HandleCPFInput(ctx context.Context, session *domain.Session, msg *domain.MessageEvent) error {
 	taxID := h.sanitizeTaxID(msg.Message)
 
-	if !h.isValidCPFFormat(taxID) {
-		return h.messenger.SendMessage(msg.ChatID, MSG_CPF_INVALID)
+	if validation.TaxID(taxID) != nil {
+		return h.attemptGuard.Reject(ctx, session, MSG_CPF_INVALID)
 	}
 
-	h.messenger.SendTypingIndicator(msg.ChatID)
+	h.messenger.SendTypingIndicator(ctx, msg.ChatID)
 
-	time.Sleep(TIMEOUT_CPF_VALIDATION)
+	h.pacer.Pause(ctx, TIMEOUT_CPF_VALIDATION)
 
-	if err := h.authenticateUser(session, taxID); err != nil {
+	if err := h.authenticateUser(ctx, session, taxID); err != nil {
 		h.logger.WithError(err).WithField("taxID", taxID).Debug("Falha na autenticação do CPF")
-		session.State = domain.StateWaitingCPF
-		h.sessionService.UpdateSession(session)
-		return h.messenger.SendMessage(msg.ChatID, MSG_CPF_UNAUTHORIZED)
+
+		result := h.accessGuardService.RecordFailure(session.UserID, taxID)
+		if result.SuspiciousCPF {
+			h.alertCPFGuessing(ctx, taxID, result.Accounts)
+		}
+
+		if result.Banned {
+			h.logger.WithField("user_id", session.UserID).Warn("Usuário bloqueado temporariamente por falhas de autenticação")
+			return h.challengeHandler.SendBanNotice(ctx, session, result.BannedUntil)
+		}
+
+		updateSession(h.sessionService, session, func(s *domain.Session) {
+			s.State = domain.StateWaitingCPF
+		})
+		return h.attemptGuard.Reject(ctx, session, MSG_CPF_UNAUTHORIZED)
+	}
+
+	h.accessGuardService.RecordSuccess(session.UserID)
+
+	return h.menuHandler.SendMainMenu(ctx, session)
+}
+
+// ResumeBinding signs in a technician whose account is still bound to an authorized CPF, reporting false
+// when the binding was dropped at the end of the workday or the CPF lost its access
+func (h *AuthenticationHandler) ResumeBinding(ctx context.Context, session *domain.Session) bool {
+	binding := h.bindingService.Get(ctx, session.UserID)
+	if binding == nil || binding.TaxID == "" {
+		return false
+	}
+
+	if err := h.authenticateUser(ctx, session, binding.TaxID); err != nil {
+		h.logger.WithError(err).WithField("user_id", session.UserID).Debug("Vínculo do usuário não autoriza mais o acesso")
+		return false
 	}
 
-	return h.sendMainMenu(session)
+	return true
+}
+
+// alertCPFGuessing warns admins that a CPF is being tried from several accounts
+func (h *AuthenticationHandler) alertCPFGuessing(ctx context.Context, taxID string, accounts []int64) {
+	ids := make([]string, len(accounts))
+	for i, account := range accounts {
+		ids[i] = strconv.FormatInt(account, 10)
+	}
+
+	h.logger.WithFields(map[string]any{
+		"tax_id":   maskTaxID(taxID),
+		"accounts": accounts,
+	}).Warn("CPF testado por múltiplas contas")
+
+	message := fmt.Sprintf(MSG_ALERT_CPF_GUESSING, maskTaxID(taxID), len(accounts), strings.Join(ids, ", "))
+	h.adminNotifier.NotifyCritical(ctx, message)
+}
+
+// RegisterCommands registers the authentication administration commands
+func (h *AuthenticationHandler) RegisterCommands(commands *CommandHandler) {
+	commands.Register("/unlock", domain.RoleAdmin, h.handleUnlockCommand)
+}
+
+// handleUnlockCommand lifts the lockout of a Telegram account
+func (h *AuthenticationHandler) handleUnlockCommand(ctx context.Context, session *domain.Session, args []string) error {
+	if len(args) != 1 {
+		return h.messenger.SendMessage(ctx, session.ChatID, MSG_UNLOCK_USAGE)
+	}
+
+	userID, err := strconv.ParseInt(args[0], 10, 64)
+	if err != nil {
+		return h.messenger.SendMessage(ctx, session.ChatID, MSG_UNLOCK_USAGE)
+	}
+
+	if !h.accessGuardService.Unlock(userID) {
+		return h.messenger.SendMessage(ctx, session.ChatID, fmt.Sprintf(MSG_UNLOCK_NOT_FOUND, userID))
+	}
+
+	h.logger.WithFields(map[string]any{
+		"user_id":     userID,
+		"unlocked_by": session.UserID,
+	}).Info("Bloqueio de acesso removido")
+
+	return h.messenger.SendMessage(ctx, session.ChatID, fmt.Sprintf(MSG_UNLOCK_DONE, userID))
+}
+
+// maskTaxID hides the middle digits of a CPF for alerts and logs
+func maskTaxID(taxID string) string {
+	if len(taxID) != 11 {
+		return "***"
+	}
+	return taxID[:3] + ".***.***-" + taxID[9:]
 }
 
 // authenticateUser validates CPF and updates session with user information
-func (h *AuthenticationHandler) authenticateUser(session *domain.Session, taxID string) error {
+func (h *AuthenticationHandler) authenticateUser(ctx context.Context, session *domain.Session, taxID string) error {
 	user := h.userService.ValidateTaxID(taxID)
 	if user == nil {
 		return fmt.Errorf("usuário com tax id %s não autorizado", taxID)
 	}
 
-	session.UserTaxID = taxID
-	session.UserName = user.Name
-	session.State = domain.StateMainMenu
-	h.sessionService.UpdateSession(session)
+	updateSession(h.sessionService, session, func(s *domain.Session) {
+		s.UserTaxID = taxID
+		s.UserName = user.Name
+		s.UserRole = user.Role
+		s.State = domain.StateMainMenu
+	})
+
+	if err := h.bindingService.Bind(ctx, session.UserID, session.ChatID, taxID, user.Name); err != nil {
+		h.logger.WithError(err).WithField("user_id", session.UserID).Warn("Falha ao vincular usuário autenticado")
+	}
 
 	h.logger.WithField("tax_id", taxID).
 		WithField("username", user.Name).
@@ -72,20 +183,6 @@ func (h *AuthenticationHandler) authenticateUser(session *domain.Session, taxID
 	return nil
 }
 
-// sendMainMenu sends the main menu after successful authentication
-func (h *AuthenticationHandler) sendMainMenu(session *domain.Session) error {
-	keyboard := &domain.Keyboard{
-		Inline: true,
-		Buttons: [][]domain.Button{
-			{{Text: MSG_MENU_PROVISION, Data: "main_menu:provision"}},
-			{{Text: MSG_MENU_EXIT, Data: "main_menu:exit"}},
-		},
-	}
-
-	message := fmt.Sprintf(MSG_USER_GREETING, session.UserName)
-	return h.messenger.SendMessageWithKeyboard(session.ChatID, message, keyboard)
-}
-
 // sanitizeTaxID removes formatting characters from tax id string
 func (h *AuthenticationHandler) sanitizeTaxID(taxID string) string {
 	taxID = strings.ReplaceAll(taxID, ".", "")
@@ -94,19 +191,36 @@ func (h *AuthenticationHandler) sanitizeTaxID(taxID string) string {
 	return taxID
 }
 
-// isValidCPFFormat checks if CPF has exactly 11 digits
-func (h *AuthenticationHandler) isValidCPFFormat(taxID string) bool {
-	return len(taxID) == 11
+// EndOfDayLogout signs every technician out at the end of the workday so shared devices don't keep
+// provisioning access overnight. Sessions with a provisioning running are left to finish.
+func (h *AuthenticationHandler) EndOfDayLogout(ctx context.Context) error {
+	sessions := h.sessionService.ExpireAuthenticated(domain.StateProvisioning)
+	for _, session := range sessions {
+		if err := h.messenger.SendMessage(ctx, session.ChatID, MSG_SESSION_END_OF_DAY); err != nil {
+			h.logger.WithError(err).WithField("user_id", session.UserID).Warn("Falha ao avisar encerramento da sessão")
+		}
+	}
+
+	unbound, err := h.bindingService.UnbindAll(ctx)
+
+	h.logger.WithFields(map[string]any{
+		"sessions": len(sessions),
+		"bindings": unbound,
+	}).Info("Sessões encerradas no fim do expediente")
+
+	return err
 }
 
 // Logout clears the user session and returns to idle state
-func (h *AuthenticationHandler) Logout(session *domain.Session) error {
-	session.State = domain.StateIdle
-	session.UserTaxID = ""
-	session.UserName = ""
-	h.sessionService.UpdateSession(session)
+func (h *AuthenticationHandler) Logout(ctx context.Context, session *domain.Session) error {
+	updateSession(h.sessionService, session, func(s *domain.Session) {
+		s.State = domain.StateIdle
+		s.UserTaxID = ""
+		s.UserName = ""
+		s.UserRole = ""
+	})
 
 	h.logger.WithField("chat_id", session.ChatID).Info("Usuário desconectado")
 
-	return h.messenger.SendMessage(session.ChatID, MSG_EXIT_MESSAGE)
+	return h.messenger.SendMessage(ctx, session.ChatID, MSG_EXIT_MESSAGE)
 }
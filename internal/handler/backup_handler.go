@@ -0,0 +1,94 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"provisioning-assistant/internal/domain"
+	"provisioning-assistant/internal/locale"
+	"provisioning-assistant/internal/services"
+)
+
+type BackupHandler struct {
+	backupService   *services.BackupService
+	artifactService *services.ArtifactService
+	formatter       *locale.Formatter
+	messenger       *Messenger
+	logger          domain.Logger
+}
+
+// NewBackupHandler creates a new backup command handler
+func NewBackupHandler(
+	backupService *services.BackupService,
+	artifactService *services.ArtifactService,
+	formatter *locale.Formatter,
+	messenger *Messenger,
+	logger domain.Logger,
+) *BackupHandler {
+	return &BackupHandler{
+		backupService:   backupService,
+		artifactService: artifactService,
+		formatter:       formatter,
+		messenger:       messenger,
+		logger:          logger,
+	}
+}
+
+// RegisterCommands registers the backup administration commands
+func (h *BackupHandler) RegisterCommands(commands *CommandHandler) {
+	commands.Register("/backup", domain.RoleAdmin, h.handleBackupCommand)
+	commands.Register("/restaurar", domain.RoleAdmin, h.handleRestoreCommand)
+}
+
+// handleBackupCommand exports the bot tables to an encrypted archive
+func (h *BackupHandler) handleBackupCommand(ctx context.Context, session *domain.Session, args []string) error {
+	if len(args) != 0 {
+		return h.messenger.SendMessage(ctx, session.ChatID, MSG_BACKUP_USAGE)
+	}
+
+	artifact, backup, err := h.backupService.Export(ctx)
+	if err != nil {
+		h.logger.WithError(err).Error("Falha ao exportar backup")
+		return h.messenger.SendMessage(ctx, session.ChatID, fmt.Sprintf(MSG_BACKUP_FAILED, err))
+	}
+
+	message := fmt.Sprintf(MSG_BACKUP_CREATED, artifact.Name(), len(backup.Bindings), len(backup.Audits), len(backup.Tokens), backup.StateEntries())
+
+	if h.artifactService.CanLink() {
+		link, expiresAt, err := h.artifactService.Link(artifact)
+		if err != nil {
+			h.logger.WithError(err).WithField("key", artifact.Key).Warn("Falha ao gerar link do backup")
+		} else {
+			message += fmt.Sprintf(MSG_BACKUP_LINK, link, h.formatter.DateTime(expiresAt))
+		}
+	}
+
+	return h.messenger.SendMessage(ctx, session.ChatID, message)
+}
+
+// handleRestoreCommand imports a previously exported archive
+func (h *BackupHandler) handleRestoreCommand(ctx context.Context, session *domain.Session, args []string) error {
+	if len(args) != 1 {
+		return h.messenger.SendMessage(ctx, session.ChatID, MSG_BACKUP_USAGE)
+	}
+
+	h.logger.WithFields(map[string]any{
+		"user_id": session.UserID,
+		"file":    args[0],
+	}).Warn("Restauração de backup solicitada")
+
+	backup, err := h.backupService.Restore(ctx, args[0])
+	if err != nil {
+		h.logger.WithError(err).Error("Falha ao restaurar backup")
+		return h.messenger.SendMessage(ctx, session.ChatID, fmt.Sprintf(MSG_BACKUP_FAILED, err))
+	}
+
+	message := fmt.Sprintf(
+		MSG_BACKUP_RESTORED,
+		h.formatter.DateTime(backup.CreatedAt),
+		len(backup.Bindings),
+		len(backup.Audits),
+		len(backup.Tokens),
+		backup.StateEntries(),
+	)
+	return h.messenger.SendMessage(ctx, session.ChatID, message)
+}
@@ -0,0 +1,81 @@
+package handler
+
+import (
+	"context"
+	"provisioning-assistant/internal/domain"
+	"slices"
+	"strings"
+)
+
+// ActionFunc handles a keyboard callback with the argument that follows the action name
+type ActionFunc func(ctx context.Context, session *domain.Session, arg string) error
+
+// ActionArgs describes the argument accepted by a callback action
+type ActionArgs struct {
+	// Rest keeps the colons of the argument, for values such as IPv6 addresses
+	Rest bool
+	// Values lists the accepted arguments, empty accepts any non-empty argument
+	Values []string
+}
+
+type action struct {
+	args    ActionArgs
+	handler ActionFunc
+}
+
+type CallbackHandler struct {
+	actions   map[string]action
+	messenger *Messenger
+	logger    domain.Logger
+}
+
+// NewCallbackHandler creates a new keyboard callback action registry
+func NewCallbackHandler(messenger *Messenger, logger domain.Logger) *CallbackHandler {
+	return &CallbackHandler{
+		actions:   make(map[string]action),
+		messenger: messenger,
+		logger:    logger,
+	}
+}
+
+// Register adds an action handled for callback data in the form "name:argument"
+func (h *CallbackHandler) Register(name string, args ActionArgs, handler ActionFunc) {
+	h.actions[name] = action{
+		args:    args,
+		handler: handler,
+	}
+}
+
+// Handle validates the callback data against the registered action and dispatches it, unknown or malformed
+// data is logged and answered with a notice instead of being ignored
+func (h *CallbackHandler) Handle(ctx context.Context, session *domain.Session, data string) error {
+	name, arg, _ := strings.Cut(data, ":")
+
+	act, exists := h.actions[name]
+	if !exists {
+		h.logger.WithFields(map[string]any{
+			"data":    data,
+			"user_id": session.UserID,
+		}).Warn("Callback com ação desconhecida")
+		return h.messenger.SendMessage(ctx, session.ChatID, MSG_CALLBACK_INVALID)
+	}
+
+	if !act.args.accepts(arg) {
+		h.logger.WithFields(map[string]any{
+			"action":  name,
+			"data":    data,
+			"user_id": session.UserID,
+		}).Warn("Callback com argumento inválido")
+		return h.messenger.SendMessage(ctx, session.ChatID, MSG_CALLBACK_INVALID)
+	}
+
+	return act.handler(ctx, session, arg)
+}
+
+// accepts reports whether the argument matches the schema of the action
+func (a ActionArgs) accepts(arg string) bool {
+	if arg == "" || (!a.Rest && strings.Contains(arg, ":")) {
+		return false
+	}
+	return len(a.Values) == 0 || slices.Contains(a.Values, arg)
+}
@@ -0,0 +1,61 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"provisioning-assistant/internal/locale"
+	"provisioning-assistant/internal/services"
+	"strings"
+)
+
+// capacityDigestPons bounds the PONs listed in the monthly digest, the closest to exhaustion first
+const capacityDigestPons = 10
+
+type CapacityHandler struct {
+	ponCapacityService *services.PonCapacityService
+	adminNotifier      *AdminNotifier
+	formatter          *locale.Formatter
+}
+
+// NewCapacityHandler creates a new handler reporting the PON capacity trends
+func NewCapacityHandler(ponCapacityService *services.PonCapacityService, adminNotifier *AdminNotifier, formatter *locale.Formatter) *CapacityHandler {
+	return &CapacityHandler{
+		ponCapacityService: ponCapacityService,
+		adminNotifier:      adminNotifier,
+		formatter:          formatter,
+	}
+}
+
+// SendCapacityDigest sends the occupancy growth of the PONs closest to exhaustion to the admin chats
+func (h *CapacityHandler) SendCapacityDigest(ctx context.Context) error {
+	report, err := h.ponCapacityService.Report(ctx)
+	if err != nil {
+		return err
+	}
+
+	if len(report.Pons) == 0 {
+		h.adminNotifier.Notify(ctx, MSG_CAPACITY_DIGEST_EMPTY)
+		return nil
+	}
+
+	var builder strings.Builder
+	builder.WriteString(fmt.Sprintf(MSG_CAPACITY_DIGEST, len(report.Pons), report.PonSize))
+
+	for _, pon := range report.Pons[:min(len(report.Pons), capacityDigestPons)] {
+		exhaustion := MSG_CAPACITY_NO_EXHAUSTION
+		if pon.ExhaustsAt != nil {
+			exhaustion = h.formatter.Date(*pon.ExhaustsAt)
+		}
+
+		builder.WriteString(fmt.Sprintf(
+			MSG_CAPACITY_DIGEST_PON,
+			pon.OltIP, pon.Slot, pon.Port,
+			pon.Onus, pon.Size, h.formatter.Percent(pon.Utilization),
+			h.formatter.Decimal(pon.GrowthPerMonth, 1),
+			exhaustion,
+		))
+	}
+
+	h.adminNotifier.Notify(ctx, builder.String())
+	return nil
+}
@@ -0,0 +1,105 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"provisioning-assistant/internal/domain"
+	"provisioning-assistant/internal/locale"
+	"provisioning-assistant/internal/services"
+	"strconv"
+	"time"
+)
+
+type ChallengeHandler struct {
+	challengeService   *services.ChallengeService
+	accessGuardService *services.AccessGuardService
+	sessionService     *services.SessionService
+	consentHandler     *ConsentHandler
+	formatter          *locale.Formatter
+	keyboards          *KeyboardCatalog
+	messenger          *Messenger
+	logger             domain.Logger
+}
+
+// NewChallengeHandler creates a new anti-bot challenge handler instance
+func NewChallengeHandler(
+	challengeService *services.ChallengeService,
+	accessGuardService *services.AccessGuardService,
+	sessionService *services.SessionService,
+	consentHandler *ConsentHandler,
+	formatter *locale.Formatter,
+	keyboards *KeyboardCatalog,
+	messenger *Messenger,
+	logger domain.Logger,
+) *ChallengeHandler {
+	return &ChallengeHandler{
+		challengeService:   challengeService,
+		accessGuardService: accessGuardService,
+		sessionService:     sessionService,
+		consentHandler:     consentHandler,
+		formatter:          formatter,
+		keyboards:          keyboards,
+		messenger:          messenger,
+		logger:             logger,
+	}
+}
+
+// IsRequired reports whether the session user must solve a challenge first
+func (h *ChallengeHandler) IsRequired(session *domain.Session) bool {
+	return h.challengeService.IsRequired(session.UserID)
+}
+
+// SendChallenge issues a new challenge and waits for the answer
+func (h *ChallengeHandler) SendChallenge(ctx context.Context, session *domain.Session) error {
+	challenge := h.challengeService.NewChallenge(session.UserID)
+
+	updateSession(h.sessionService, session, func(s *domain.Session) {
+		s.State = domain.StateWaitingChallenge
+	})
+
+	options := make([]string, 0, len(challenge.Options))
+	for _, option := range challenge.Options {
+		options = append(options, strconv.Itoa(option))
+	}
+
+	keyboard := h.keyboards.Build(KeyboardCaptcha, WithChoices(options...))
+
+	message := fmt.Sprintf(MSG_CHALLENGE, challenge.Question)
+	return h.messenger.SendMessageWithKeyboard(ctx, session.ChatID, message, keyboard)
+}
+
+// HandleTextInput reminds the user to answer using the keyboard
+func (h *ChallengeHandler) HandleTextInput(ctx context.Context, session *domain.Session, msg *domain.MessageEvent) error {
+	return h.messenger.SendMessage(ctx, msg.ChatID, MSG_CHALLENGE_PENDING)
+}
+
+// HandleAnswer verifies a challenge answer and proceeds to consent and CPF entry
+func (h *ChallengeHandler) HandleAnswer(ctx context.Context, session *domain.Session, answer string) error {
+	if session.State != domain.StateWaitingChallenge {
+		return nil
+	}
+
+	value, err := strconv.Atoi(answer)
+	if err == nil && h.challengeService.Verify(session.UserID, value) {
+		return h.consentHandler.RequestCPF(ctx, session)
+	}
+
+	h.logger.WithField("user_id", session.UserID).Warn("Desafio anti-robô respondido incorretamente")
+
+	if result := h.accessGuardService.RecordFailure(session.UserID, ""); result.Banned {
+		return h.SendBanNotice(ctx, session, result.BannedUntil)
+	}
+
+	_ = h.messenger.SendMessage(ctx, session.ChatID, MSG_CHALLENGE_WRONG)
+	return h.SendChallenge(ctx, session)
+}
+
+// SendBanNotice resets the session and informs the user about the temporary ban
+func (h *ChallengeHandler) SendBanNotice(ctx context.Context, session *domain.Session, until time.Time) error {
+	updateSession(h.sessionService, session, func(s *domain.Session) {
+		s.State = domain.StateIdle
+	})
+
+	message := fmt.Sprintf(MSG_TEMPORARILY_BANNED, h.formatter.Time(until))
+	return h.messenger.SendMessage(ctx, session.ChatID, message)
+}
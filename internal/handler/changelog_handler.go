@@ -0,0 +1,46 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"provisioning-assistant/internal/domain"
+	"provisioning-assistant/internal/services"
+	"strings"
+)
+
+type ChangelogHandler struct {
+	changelogService *services.ChangelogService
+	messenger        *Messenger
+}
+
+// NewChangelogHandler creates a new "what changed" notice handler
+func NewChangelogHandler(changelogService *services.ChangelogService, messenger *Messenger) *ChangelogHandler {
+	return &ChangelogHandler{
+		changelogService: changelogService,
+		messenger:        messenger,
+	}
+}
+
+// NotifyChanges tells an authenticated technician once what changed since the version last seen, before
+// the interaction goes on
+func (h *ChangelogHandler) NotifyChanges(ctx context.Context, session *domain.Session) {
+	if session.UserTaxID == "" {
+		return
+	}
+
+	releases := h.changelogService.TakeUnseen(ctx, session.UserID, session.ChatID)
+	if len(releases) == 0 {
+		return
+	}
+
+	var builder strings.Builder
+	builder.WriteString(MSG_CHANGELOG_HEADER)
+	for _, release := range releases {
+		builder.WriteString(fmt.Sprintf(MSG_CHANGELOG_RELEASE, release.Version))
+		for _, change := range release.Changes {
+			builder.WriteString(fmt.Sprintf(MSG_CHANGELOG_ITEM, change))
+		}
+	}
+
+	_ = h.messenger.SendMessage(ctx, session.ChatID, strings.TrimRight(builder.String(), "\n"))
+}
@@ -0,0 +1,65 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"provisioning-assistant/internal/domain"
+	"provisioning-assistant/internal/services"
+)
+
+// ChatStatusHandler keeps bindings and admin chats in sync with chats the bot can no longer reach
+type ChatStatusHandler struct {
+	bindingService *services.BindingService
+	adminNotifier  *AdminNotifier
+	logger         domain.Logger
+}
+
+// NewChatStatusHandler creates a new chat status handler instance
+func NewChatStatusHandler(bindingService *services.BindingService, adminNotifier *AdminNotifier, logger domain.Logger) *ChatStatusHandler {
+	return &ChatStatusHandler{
+		bindingService: bindingService,
+		adminNotifier:  adminNotifier,
+		logger:         logger,
+	}
+}
+
+// HandleBlocked flags the bindings of a chat that blocked the bot and warns the admins when it was one of theirs
+func (h *ChatStatusHandler) HandleBlocked(ctx context.Context, chatID int64) error {
+	log := h.logger.WithField("chat_id", chatID)
+
+	updated, err := h.bindingService.MarkChatBlocked(ctx, chatID)
+	if err != nil {
+		log.WithError(err).Error("Falha ao marcar vínculos do chat bloqueado")
+	} else if updated > 0 {
+		log.WithField("bindings", updated).Info("Vínculos marcados como bloqueados")
+	}
+
+	if h.adminNotifier.IsAdminChat(chatID) {
+		// The blocked chat itself is skipped by the adapter, only the remaining ones get the warning
+		h.adminNotifier.Notify(ctx, fmt.Sprintf(MSG_ALERT_ADMIN_CHAT_BLOCKED, chatID))
+	}
+
+	return nil
+}
+
+// HandleMigrated moves bindings and admin chats from a group to the supergroup it became
+func (h *ChatStatusHandler) HandleMigrated(ctx context.Context, from, to int64) error {
+	log := h.logger.WithFields(map[string]any{
+		"from_chat_id": from,
+		"to_chat_id":   to,
+	})
+
+	updated, err := h.bindingService.MigrateChat(ctx, from, to)
+	if err != nil {
+		log.WithError(err).Error("Falha ao migrar vínculos do grupo")
+	} else if updated > 0 {
+		log.WithField("bindings", updated).Info("Vínculos migrados para o supergrupo")
+	}
+
+	if h.adminNotifier.ReplaceChat(from, to) {
+		log.Warn("Chat de administração migrado, atualize ADMIN_CHAT_IDS na configuração")
+		h.adminNotifier.Notify(ctx, fmt.Sprintf(MSG_ALERT_ADMIN_CHAT_MIGRATED, from, to))
+	}
+
+	return nil
+}
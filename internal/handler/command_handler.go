@@ -0,0 +1,90 @@
+package handler
+
+import (
+	"context"
+	"provisioning-assistant/internal/domain"
+	"strings"
+)
+
+// CommandFunc handles a slash command with its whitespace separated arguments
+type CommandFunc func(ctx context.Context, session *domain.Session, args []string) error
+
+type command struct {
+	role    domain.Role
+	handler CommandFunc
+}
+
+type CommandHandler struct {
+	commands  map[string]command
+	messenger *Messenger
+	logger    domain.Logger
+}
+
+// NewCommandHandler creates a new slash command registry
+func NewCommandHandler(messenger *Messenger, logger domain.Logger) *CommandHandler {
+	return &CommandHandler{
+		commands:  make(map[string]command),
+		messenger: messenger,
+		logger:    logger,
+	}
+}
+
+// Register adds a command restricted to users with at least the given role
+func (h *CommandHandler) Register(name string, role domain.Role, handler CommandFunc) {
+	h.commands[strings.ToLower(name)] = command{
+		role:    role,
+		handler: handler,
+	}
+}
+
+// IsCommand reports whether the text invokes a registered command
+func (h *CommandHandler) IsCommand(text string) bool {
+	name, _ := h.parse(text)
+	_, exists := h.commands[name]
+	return exists
+}
+
+// Handle authorizes and dispatches a registered command
+func (h *CommandHandler) Handle(ctx context.Context, session *domain.Session, msg *domain.MessageEvent) error {
+	name, args := h.parse(msg.Message)
+
+	cmd, exists := h.commands[name]
+	if !exists {
+		return nil
+	}
+
+	if session.UserTaxID == "" {
+		return h.messenger.SendMessage(ctx, msg.ChatID, MSG_COMMAND_AUTH_REQUIRED)
+	}
+
+	if !session.UserRole.Includes(cmd.role) {
+		h.logger.WithFields(map[string]any{
+			"command": name,
+			"user_id": session.UserID,
+			"role":    session.UserRole,
+		}).Warn("Comando negado por falta de permissão")
+		return h.messenger.SendMessage(ctx, msg.ChatID, MSG_COMMAND_NOT_ALLOWED)
+	}
+
+	return cmd.handler(ctx, session, args)
+}
+
+// parse splits a command message into its normalized name and arguments.
+// Tappable links such as /auditoria_01jnzmgkm0 are read as /auditoria 01jnzmgkm0.
+func (h *CommandHandler) parse(text string) (string, []string) {
+	fields := strings.Fields(text)
+	if len(fields) == 0 || !strings.HasPrefix(fields[0], "/") {
+		return "", nil
+	}
+
+	name, _, _ := strings.Cut(strings.ToLower(fields[0]), "@")
+	if _, exists := h.commands[name]; !exists {
+		if base, arg, found := strings.Cut(name, "_"); found && arg != "" {
+			if _, exists := h.commands[base]; exists {
+				return base, append([]string{arg}, fields[1:]...)
+			}
+		}
+	}
+
+	return name, fields[1:]
+}
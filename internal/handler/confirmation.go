@@ -0,0 +1,116 @@
+package handler
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"provisioning-assistant/internal/clock"
+	"provisioning-assistant/internal/domain"
+	"provisioning-assistant/internal/services"
+	"strings"
+	"time"
+)
+
+// ConfirmationTimeout is how long a summary may wait for an answer, a later answer gets the data shown again
+// since the technician may no longer be in front of the same equipment
+const ConfirmationTimeout = 15 * time.Minute
+
+// Answers carried by the confirmation buttons
+const (
+	ConfirmationYes  = "yes"
+	ConfirmationNo   = "no"
+	ConfirmationEdit = "edit"
+)
+
+// ConfirmationField is one line of a confirmation summary, an optional field is left out when empty
+type ConfirmationField struct {
+	Label    string
+	Value    string
+	Optional bool
+}
+
+// Confirmation is the summary a flow asks the technician to confirm before touching the OLT. Flows other
+// than the provisioning wait in a state of their own and answer with their own keyboard, its buttons
+// carrying the target.
+type Confirmation struct {
+	Notice   string
+	Title    string
+	Fields   []ConfirmationField
+	Question string
+	State    domain.SessionState
+	Keyboard string
+	Target   string
+}
+
+// Summary renders the notice, the title, one line per field and the question
+func (c Confirmation) Summary() string {
+	var builder strings.Builder
+	builder.WriteString(c.Notice)
+	builder.WriteString(c.Title)
+	builder.WriteString("\n\n")
+
+	for _, field := range c.Fields {
+		if field.Optional && field.Value == "" {
+			continue
+		}
+		fmt.Fprintf(&builder, MSG_CONFIRM_FIELD, field.Label, field.Value)
+	}
+
+	builder.WriteString("\n")
+	builder.WriteString(c.Question)
+	return builder.String()
+}
+
+// Confirmer sends the confirmation summaries and tells whether an answer still applies to them,
+// every flow asking for a yes or no before acting goes through it
+type Confirmer struct {
+	sessionService *services.SessionService
+	clock          clock.Clock
+	keyboards      *KeyboardCatalog
+	messenger      *Messenger
+}
+
+// NewConfirmer creates a new confirmation component instance
+func NewConfirmer(
+	sessionService *services.SessionService,
+	clock clock.Clock,
+	keyboards *KeyboardCatalog,
+	messenger *Messenger,
+) *Confirmer {
+	return &Confirmer{
+		sessionService: sessionService,
+		clock:          clock,
+		keyboards:      keyboards,
+		messenger:      messenger,
+	}
+}
+
+// Ask moves the session to the confirmation step and sends the summary with the answer buttons
+func (c *Confirmer) Ask(ctx context.Context, session *domain.Session, confirmation Confirmation) error {
+	updateSession(c.sessionService, session, func(s *domain.Session) {
+		s.State = cmp.Or(confirmation.State, domain.StateConfirmData)
+		s.ConfirmAskedAt = c.clock.Now()
+	})
+
+	keyboard := c.keyboards.Build(cmp.Or(confirmation.Keyboard, KeyboardConfirm), WithTarget(confirmation.Target))
+	return c.messenger.SendMessageWithKeyboard(ctx, session.ChatID, confirmation.Summary(), keyboard)
+}
+
+// IsPending reports whether the session is still waiting for an answer to the provisioning summary, buttons
+// of older summaries are ignored
+func (c *Confirmer) IsPending(session *domain.Session) bool {
+	return c.Awaits(session, domain.StateConfirmData)
+}
+
+// Awaits reports whether the session is still waiting for an answer to a summary asked in the given state
+func (c *Confirmer) Awaits(session *domain.Session, state domain.SessionState) bool {
+	return session.State == state
+}
+
+// IsExpired reports whether the summary went unanswered for longer than the timeout
+func (c *Confirmer) IsExpired(session *domain.Session) bool {
+	if session.ConfirmAskedAt.IsZero() {
+		return false
+	}
+	return c.clock.Since(session.ConfirmAskedAt) > ConfirmationTimeout
+}
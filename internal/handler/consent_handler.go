@@ -0,0 +1,154 @@
+package handler
+
+import (
+	"context"
+	"provisioning-assistant/internal/domain"
+	"provisioning-assistant/internal/services"
+)
+
+// ConsentPolicy configures the privacy notice shown before CPF collection, a consent only counts for the
+// tenant and notice version it was given to
+type ConsentPolicy struct {
+	Required bool
+	Tenant   string
+	Version  string
+	Notice   string
+}
+
+type ConsentHandler struct {
+	policy         ConsentPolicy
+	bindingService *services.BindingService
+	sessionService *services.SessionService
+	keyboards      *KeyboardCatalog
+	messenger      *Messenger
+	logger         domain.Logger
+}
+
+// NewConsentHandler creates a new privacy consent handler instance
+func NewConsentHandler(
+	policy ConsentPolicy,
+	bindingService *services.BindingService,
+	sessionService *services.SessionService,
+	keyboards *KeyboardCatalog,
+	messenger *Messenger,
+	logger domain.Logger,
+) *ConsentHandler {
+	if policy.Notice == "" {
+		policy.Notice = MSG_PRIVACY_NOTICE
+	}
+
+	return &ConsentHandler{
+		policy:         policy,
+		bindingService: bindingService,
+		sessionService: sessionService,
+		keyboards:      keyboards,
+		messenger:      messenger,
+		logger:         logger,
+	}
+}
+
+// RegisterCommands registers the profile commands
+func (h *ConsentHandler) RegisterCommands(commands *CommandHandler) {
+	commands.Register("/telefone", domain.RoleTechnician, h.handlePhoneCommand)
+}
+
+// CaptureProfile keeps the Telegram profile in the session and binding once consent is held
+func (h *ConsentHandler) CaptureProfile(ctx context.Context, session *domain.Session, profile domain.TelegramProfile) bool {
+	if profile.IsEmpty() {
+		return false
+	}
+
+	if h.policy.Required && !h.bindingService.HasConsent(ctx, session.UserID, h.policy.Tenant, h.policy.Version) {
+		return false
+	}
+
+	merged := session.Profile.Merge(profile)
+	if merged == session.Profile {
+		return true
+	}
+
+	updateSession(h.sessionService, session, func(s *domain.Session) {
+		s.Profile = merged
+	})
+
+	if err := h.bindingService.UpdateProfile(ctx, session.UserID, session.ChatID, profile); err != nil {
+		h.logger.WithError(err).WithField("user_id", session.UserID).Warn("Falha ao salvar perfil do Telegram")
+	}
+
+	return true
+}
+
+// HandleContact confirms a phone shared through the contact button
+func (h *ConsentHandler) HandleContact(ctx context.Context, session *domain.Session, msg *domain.MessageEvent) error {
+	if !h.CaptureProfile(ctx, session, msg.Profile) {
+		return h.messenger.SendMessage(ctx, session.ChatID, MSG_PHONE_CONSENT_REQUIRED)
+	}
+
+	h.logger.WithField("user_id", session.UserID).Info("Telefone do técnico registrado")
+	return h.messenger.SendMessage(ctx, session.ChatID, MSG_PHONE_SAVED)
+}
+
+// handlePhoneCommand offers a button that shares the technician's own phone number
+func (h *ConsentHandler) handlePhoneCommand(ctx context.Context, session *domain.Session, args []string) error {
+	return h.messenger.SendMessageWithKeyboard(ctx, session.ChatID, MSG_PHONE_REQUEST, h.keyboards.Build(KeyboardPhoneShare))
+}
+
+// RequestCPF asks for consent when still missing, otherwise prompts for the CPF
+func (h *ConsentHandler) RequestCPF(ctx context.Context, session *domain.Session) error {
+	if h.IsPending(ctx, session) {
+		return h.sendNotice(ctx, session)
+	}
+
+	updateSession(h.sessionService, session, func(s *domain.Session) {
+		s.State = domain.StateWaitingCPF
+	})
+
+	return h.messenger.SendMessage(ctx, session.ChatID, MSG_WELCOME)
+}
+
+// IsPending reports whether the user still has to accept the current privacy notice
+func (h *ConsentHandler) IsPending(ctx context.Context, session *domain.Session) bool {
+	return h.policy.Required && !h.bindingService.HasConsent(ctx, session.UserID, h.policy.Tenant, h.policy.Version)
+}
+
+// HandleTextInput shows the privacy notice again while consent is pending
+func (h *ConsentHandler) HandleTextInput(ctx context.Context, session *domain.Session, msg *domain.MessageEvent) error {
+	return h.sendNotice(ctx, session)
+}
+
+// HandleConsentOption records the user's answer to the privacy notice
+func (h *ConsentHandler) HandleConsentOption(ctx context.Context, session *domain.Session, option string) error {
+	if session.State != domain.StateWaitingConsent {
+		return nil
+	}
+
+	if option != "accept" {
+		updateSession(h.sessionService, session, func(s *domain.Session) {
+			s.State = domain.StateIdle
+		})
+
+		h.logger.WithField("user_id", session.UserID).Info("Termo de privacidade recusado")
+		return h.messenger.SendMessage(ctx, session.ChatID, MSG_CONSENT_DECLINED)
+	}
+
+	if err := h.bindingService.RecordConsent(ctx, session.UserID, session.ChatID, h.policy.Tenant, h.policy.Version); err != nil {
+		return h.messenger.SendMessage(ctx, session.ChatID, MSG_CONSENT_FAILED)
+	}
+
+	h.logger.WithFields(map[string]any{
+		"user_id": session.UserID,
+		"tenant":  h.policy.Tenant,
+		"version": h.policy.Version,
+	}).Info("Consentimento de privacidade registrado")
+
+	return h.RequestCPF(ctx, session)
+}
+
+// sendNotice sends the privacy notice with accept and decline buttons
+func (h *ConsentHandler) sendNotice(ctx context.Context, session *domain.Session) error {
+	updateSession(h.sessionService, session, func(s *domain.Session) {
+		s.State = domain.StateWaitingConsent
+	})
+
+	return h.messenger.SendMessageWithKeyboard(ctx, session.ChatID, h.policy.Notice, h.keyboards.Build(KeyboardConsent))
+}
@@ -0,0 +1,54 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"provisioning-assistant/internal/services"
+)
+
+type CredentialHandler struct {
+	credentialService *services.CredentialCheckService
+	adminNotifier     *AdminNotifier
+}
+
+// NewCredentialHandler creates a new UNM credential check handler
+func NewCredentialHandler(credentialService *services.CredentialCheckService, adminNotifier *AdminNotifier) *CredentialHandler {
+	return &CredentialHandler{
+		credentialService: credentialService,
+		adminNotifier:     adminNotifier,
+	}
+}
+
+// CheckUnmCredentials logs into every UNM endpoint and alerts the admins about failures and expiring passwords
+func (h *CredentialHandler) CheckUnmCredentials(ctx context.Context) error {
+	var errs []error
+
+	for _, result := range h.credentialService.Check(ctx) {
+		switch {
+		case result.Err != nil:
+			h.adminNotifier.NotifyCritical(ctx, fmt.Sprintf(MSG_ALERT_CREDENTIALS_FAILED, result.Endpoint, credentialFailureReason(result), result.Err))
+			errs = append(errs, fmt.Errorf("%s: %w", result.Endpoint, result.Err))
+
+		case result.Warning && result.ExpiresInDays == 0:
+			h.adminNotifier.NotifyCritical(ctx, fmt.Sprintf(MSG_ALERT_CREDENTIALS_EXPIRE_TODAY, result.Endpoint))
+
+		case result.Warning:
+			h.adminNotifier.Notify(ctx, fmt.Sprintf(MSG_ALERT_CREDENTIALS_EXPIRING, result.Endpoint, result.ExpiresInDays))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// credentialFailureReason describes the failure in terms the admin can act on
+func credentialFailureReason(result services.CredentialCheck) string {
+	switch {
+	case result.Status != nil && result.Status.Locked:
+		return MSG_CREDENTIALS_LOCKED
+	case result.Status != nil && result.Status.Expired:
+		return MSG_CREDENTIALS_EXPIRED
+	default:
+		return MSG_CREDENTIALS_REJECTED
+	}
+}
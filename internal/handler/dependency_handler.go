@@ -0,0 +1,54 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"provisioning-assistant/internal/clock"
+	"provisioning-assistant/internal/locale"
+	"provisioning-assistant/internal/services"
+	"slices"
+	"strings"
+)
+
+type DependencyHandler struct {
+	watchdogService *services.DependencyWatchdogService
+	adminNotifier   *AdminNotifier
+	clock           clock.Clock
+	formatter       *locale.Formatter
+}
+
+// NewDependencyHandler creates a new dependency outage handler
+func NewDependencyHandler(
+	watchdogService *services.DependencyWatchdogService,
+	adminNotifier *AdminNotifier,
+	clock clock.Clock,
+	formatter *locale.Formatter,
+) *DependencyHandler {
+	return &DependencyHandler{
+		watchdogService: watchdogService,
+		adminNotifier:   adminNotifier,
+		clock:           clock,
+		formatter:       formatter,
+	}
+}
+
+// WatchDependencies probes the core dependencies and tells the admins when the bot enters or leaves an outage
+func (h *DependencyHandler) WatchDependencies(ctx context.Context) error {
+	switch h.watchdogService.Check(ctx) {
+	case services.DependencyOutage:
+		status := h.watchdogService.Status()
+
+		var failing strings.Builder
+		for _, name := range slices.Sorted(maps.Keys(status.Failing)) {
+			failing.WriteString(fmt.Sprintf(MSG_ALERT_DEPENDENCY_ITEM, name, status.Failing[name]))
+		}
+		h.adminNotifier.NotifyCritical(ctx, fmt.Sprintf(MSG_ALERT_DEPENDENCY_OUTAGE, failing.String()))
+
+	case services.DependencyRecovered:
+		downFor := h.clock.Since(h.watchdogService.Status().Since)
+		h.adminNotifier.Notify(ctx, fmt.Sprintf(MSG_ALERT_DEPENDENCY_RECOVERED, h.formatter.Duration(downFor)))
+	}
+
+	return nil
+}
@@ -0,0 +1,234 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"provisioning-assistant/internal/clock"
+	"provisioning-assistant/internal/domain"
+	"provisioning-assistant/internal/locale"
+	"provisioning-assistant/internal/services"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// deprovisionListedItems bounds the ONUs listed before the batch is confirmed
+	deprovisionListedItems = 10
+
+	// deprovisionMaxDays bounds how far back a supervisor may look for cancelled contracts
+	deprovisionMaxDays = 90
+)
+
+type DeprovisionHandler struct {
+	deprovisionService *services.DeprovisionService
+	adminNotifier      *AdminNotifier
+	clock              clock.Clock
+	formatter          *locale.Formatter
+	keyboards          *KeyboardCatalog
+	messenger          *Messenger
+	logger             domain.Logger
+}
+
+// NewDeprovisionHandler creates a new handler removing the ONUs of the contracts cancelled in the ERP
+func NewDeprovisionHandler(
+	deprovisionService *services.DeprovisionService,
+	adminNotifier *AdminNotifier,
+	clock clock.Clock,
+	formatter *locale.Formatter,
+	keyboards *KeyboardCatalog,
+	messenger *Messenger,
+	logger domain.Logger,
+) *DeprovisionHandler {
+	return &DeprovisionHandler{
+		deprovisionService: deprovisionService,
+		adminNotifier:      adminNotifier,
+		clock:              clock,
+		formatter:          formatter,
+		keyboards:          keyboards,
+		messenger:          messenger,
+		logger:             logger,
+	}
+}
+
+// RegisterCommands registers the cancelled contracts command
+func (h *DeprovisionHandler) RegisterCommands(commands *CommandHandler) {
+	commands.Register("/cancelados", domain.RoleSupervisor, h.handleCancelledCommand)
+}
+
+// handleCancelledCommand lists the ONUs of the contracts cancelled over the last days still on the OLTs,
+// asking before removing them
+func (h *DeprovisionHandler) handleCancelledCommand(ctx context.Context, session *domain.Session, args []string) error {
+	days, ok := h.parseDays(args)
+	if !ok {
+		return h.messenger.SendMessage(ctx, session.ChatID, fmt.Sprintf(MSG_DEPROVISION_USAGE, h.defaultDays()))
+	}
+
+	h.messenger.SendTypingIndicator(ctx, session.ChatID)
+
+	report, err := h.deprovisionService.Plan(ctx, h.since(days))
+	if err != nil {
+		h.logger.WithError(err).Error("Falha ao planejar remoção das ONUs de contratos cancelados")
+		return h.messenger.SendMessage(ctx, session.ChatID, fmt.Sprintf(MSG_DEPROVISION_FAILED, err))
+	}
+
+	pending := report.Count(domain.DeprovisionPending)
+
+	var builder strings.Builder
+	builder.WriteString(fmt.Sprintf(
+		MSG_DEPROVISION_PLANNED,
+		h.formatter.Date(report.Since),
+		len(report.Items),
+		pending,
+		report.Count(domain.DeprovisionAbsent),
+		report.Count(domain.DeprovisionReused),
+		report.Count(domain.DeprovisionUnknown),
+	))
+
+	if pending == 0 {
+		builder.WriteString(MSG_DEPROVISION_NOTHING)
+		return h.messenger.SendMessage(ctx, session.ChatID, builder.String())
+	}
+
+	builder.WriteString("\n")
+	listed := 0
+	for _, item := range report.Items {
+		if item.Outcome != domain.DeprovisionPending {
+			continue
+		}
+		if listed == deprovisionListedItems {
+			builder.WriteString(fmt.Sprintf(MSG_DEPROVISION_MORE, pending-listed))
+			break
+		}
+		builder.WriteString(fmt.Sprintf(MSG_DEPROVISION_ITEM, item.Contract, item.Serial, item.ClientName, item.OltIP, item.Slot, item.Port))
+		listed++
+	}
+
+	policy := h.deprovisionService.Policy()
+	builder.WriteString(fmt.Sprintf(MSG_DEPROVISION_CONFIRM, policy.MaxBatch, h.formatter.Duration(policy.Interval)))
+
+	keyboard := h.keyboards.Build(KeyboardDeprovision, WithTarget(strconv.Itoa(days)))
+	return h.messenger.SendMessageWithKeyboard(ctx, session.ChatID, builder.String(), keyboard)
+}
+
+// HandleRunOption plans the batch again, the OLTs may have changed since it was listed, and removes the
+// ONUs still registered
+func (h *DeprovisionHandler) HandleRunOption(ctx context.Context, session *domain.Session, arg string) error {
+	if session.UserTaxID == "" || !session.UserRole.Includes(domain.RoleSupervisor) {
+		return h.messenger.SendMessage(ctx, session.ChatID, MSG_COMMAND_NOT_ALLOWED)
+	}
+
+	days, ok := h.parseDays([]string{arg})
+	if !ok {
+		return h.messenger.SendMessage(ctx, session.ChatID, fmt.Sprintf(MSG_DEPROVISION_USAGE, h.defaultDays()))
+	}
+
+	h.messenger.SendTypingIndicator(ctx, session.ChatID)
+	_ = h.messenger.SendMessage(ctx, session.ChatID, MSG_DEPROVISION_RUNNING)
+
+	report, err := h.deprovisionService.Plan(ctx, h.since(days))
+	if err != nil {
+		h.logger.WithError(err).Error("Falha ao planejar remoção das ONUs de contratos cancelados")
+		return h.messenger.SendMessage(ctx, session.ChatID, fmt.Sprintf(MSG_DEPROVISION_FAILED, err))
+	}
+
+	report = h.deprovisionService.Run(ctx, report, session.UserName)
+
+	h.logger.WithFields(map[string]any{
+		"user_id": session.UserID,
+		"removed": report.Count(domain.DeprovisionRemoved),
+		"failed":  report.Count(domain.DeprovisionFailed),
+	}).Info("ONUs de contratos cancelados removidas em lote")
+
+	h.notify(ctx, report, session.UserName)
+
+	return h.messenger.SendMessage(ctx, session.ChatID, h.formatReport(report))
+}
+
+// HandleCancelOption dismisses a listed batch without removing anything
+func (h *DeprovisionHandler) HandleCancelOption(ctx context.Context, session *domain.Session, _ string) error {
+	return h.messenger.SendMessage(ctx, session.ChatID, MSG_DEPROVISION_ABORTED)
+}
+
+// DeprovisionCancelled removes the ONUs of the contracts cancelled over the lookback, reporting the batch
+// to the admin chats when anything was removed or failed. The removals run with the system role, no user
+// being behind them.
+func (h *DeprovisionHandler) DeprovisionCancelled(ctx context.Context) error {
+	ctx = domain.WithRole(ctx, domain.RoleSystem)
+
+	report, err := h.deprovisionService.Plan(ctx, h.clock.Now().Add(-h.deprovisionService.Policy().Lookback))
+	if err != nil {
+		return err
+	}
+
+	report = h.deprovisionService.Run(ctx, report, MSG_DEPROVISION_SCHEDULED)
+	if report.Count(domain.DeprovisionRemoved)+report.Count(domain.DeprovisionFailed) == 0 {
+		return nil
+	}
+
+	h.notify(ctx, report, MSG_DEPROVISION_SCHEDULED)
+	h.adminNotifier.Notify(ctx, h.formatReport(report))
+	return nil
+}
+
+// notify tells the admin chats how many ONUs a batch removed
+func (h *DeprovisionHandler) notify(ctx context.Context, report *domain.DeprovisionReport, removedBy string) {
+	h.adminNotifier.Notify(ctx, fmt.Sprintf(
+		MSG_ALERT_DEPROVISION,
+		removedBy,
+		report.Count(domain.DeprovisionRemoved),
+		report.Count(domain.DeprovisionFailed),
+		report.Count(domain.DeprovisionPending),
+	))
+}
+
+// formatReport summarizes a batch, listing its failures
+func (h *DeprovisionHandler) formatReport(report *domain.DeprovisionReport) string {
+	var builder strings.Builder
+	builder.WriteString(fmt.Sprintf(
+		MSG_DEPROVISION_REPORT,
+		report.Count(domain.DeprovisionRemoved),
+		report.Count(domain.DeprovisionFailed),
+		report.Count(domain.DeprovisionPending),
+		report.Count(domain.DeprovisionReused),
+		report.Count(domain.DeprovisionUnknown),
+	))
+
+	for _, item := range report.Items {
+		if item.Outcome == domain.DeprovisionFailed {
+			builder.WriteString(fmt.Sprintf(MSG_DEPROVISION_REPORT_FAILURE, item.Contract, item.Serial, item.Err))
+		}
+	}
+
+	if report.Count(domain.DeprovisionPending) > 0 {
+		builder.WriteString(MSG_DEPROVISION_REPORT_PENDING)
+	}
+
+	return builder.String()
+}
+
+// parseDays reads the optional number of days looked back, the lookback of the policy when absent
+func (h *DeprovisionHandler) parseDays(args []string) (int, bool) {
+	if len(args) == 0 {
+		return h.defaultDays(), true
+	}
+	if len(args) > 1 {
+		return 0, false
+	}
+
+	days, err := strconv.Atoi(args[0])
+	if err != nil || days < 1 || days > deprovisionMaxDays {
+		return 0, false
+	}
+	return days, true
+}
+
+// defaultDays is the lookback of the policy in whole days
+func (h *DeprovisionHandler) defaultDays() int {
+	return max(1, int(h.deprovisionService.Policy().Lookback/(24*time.Hour)))
+}
+
+// since is the moment the given number of days ago
+func (h *DeprovisionHandler) since(days int) time.Time {
+	return h.clock.Now().AddDate(0, 0, -days)
+}
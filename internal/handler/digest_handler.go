@@ -0,0 +1,66 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"provisioning-assistant/internal/clock"
+	"provisioning-assistant/internal/domain"
+	"provisioning-assistant/internal/services"
+	"time"
+)
+
+const AuditDigestPeriod = 24 * time.Hour
+
+type DigestHandler struct {
+	auditService  *services.AuditService
+	adminNotifier *AdminNotifier
+	clock         clock.Clock
+}
+
+// NewDigestHandler creates a new periodic digest handler
+func NewDigestHandler(auditService *services.AuditService, adminNotifier *AdminNotifier, clock clock.Clock) *DigestHandler {
+	return &DigestHandler{
+		auditService:  auditService,
+		adminNotifier: adminNotifier,
+		clock:         clock,
+	}
+}
+
+// SendAuditDigest sends the provisioning summary of the last day to the admin chats
+func (h *DigestHandler) SendAuditDigest(ctx context.Context) error {
+	records, err := h.auditService.ListRecords(ctx)
+	if err != nil {
+		return fmt.Errorf("falha ao listar registros de auditoria: %w", err)
+	}
+
+	since := h.clock.Now().Add(-AuditDigestPeriod)
+	var succeeded, failed, manual, withoutPhotos, weakSignal, criticalSignal int
+
+	for _, record := range records {
+		if record.CreatedAt.Before(since) {
+			continue
+		}
+
+		if !record.Success {
+			failed++
+			continue
+		}
+
+		succeeded++
+		if record.Manual {
+			manual++
+		}
+		if len(record.Attachments) == 0 {
+			withoutPhotos++
+		}
+		switch record.SignalLevel {
+		case domain.SignalWarning:
+			weakSignal++
+		case domain.SignalCritical:
+			criticalSignal++
+		}
+	}
+
+	h.adminNotifier.Notify(ctx, fmt.Sprintf(MSG_AUDIT_DIGEST, succeeded, failed, manual, withoutPhotos, weakSignal, criticalSignal))
+	return nil
+}
@@ -0,0 +1,139 @@
+package handler
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"provisioning-assistant/internal/domain"
+	"provisioning-assistant/internal/services"
+	"slices"
+)
+
+// erpPendingStates are the steps where a protocol is loaded but nothing was sent to the OLT yet
+var erpPendingStates = []domain.SessionState{domain.StateConfirmData, domain.StateWaitingOverride}
+
+type ErpEventHandler struct {
+	sessionService      *services.SessionService
+	erpService          *services.ErpService
+	workOrderHandler    *WorkOrderHandler
+	provisioningHandler *ProvisioningHandler
+	menuHandler         *MenuHandler
+	messenger           *Messenger
+	logger              domain.Logger
+}
+
+// NewErpEventHandler creates a new handler applying the protocol changes pushed by the ERP to the sessions
+func NewErpEventHandler(
+	sessionService *services.SessionService,
+	erpService *services.ErpService,
+	workOrderHandler *WorkOrderHandler,
+	provisioningHandler *ProvisioningHandler,
+	menuHandler *MenuHandler,
+	messenger *Messenger,
+	logger domain.Logger,
+) *ErpEventHandler {
+	return &ErpEventHandler{
+		sessionService:      sessionService,
+		erpService:          erpService,
+		workOrderHandler:    workOrderHandler,
+		provisioningHandler: provisioningHandler,
+		menuHandler:         menuHandler,
+		messenger:           messenger,
+		logger:              logger,
+	}
+}
+
+// HandleErpEvent greets the technician of a created protocol, and drops or reloads a cancelled or corrected
+// protocol in the sessions still reviewing it, telling their technicians
+func (h *ErpEventHandler) HandleErpEvent(ctx context.Context, event *domain.ErpEvent) {
+	if event.Type == domain.ErpEventProtocolCreated {
+		h.workOrderHandler.GreetFromErp(ctx, event)
+		return
+	}
+
+	for _, session := range h.sessionService.WithProtocol(event.Protocol) {
+		log := h.logger.WithFields(map[string]any{
+			"event_id": event.ID,
+			"protocol": event.Protocol,
+			"user_id":  session.UserID,
+		})
+
+		var err error
+		switch event.Type {
+		case domain.ErpEventProtocolCancelled:
+			err = h.cancel(ctx, session, event)
+		case domain.ErpEventProtocolCorrected:
+			err = h.reload(ctx, session, event)
+		}
+		if err != nil {
+			log.WithError(err).Warn("Falha ao aplicar evento do ERP à sessão")
+		}
+	}
+}
+
+// cancel returns a session reviewing a cancelled protocol to the main menu, a job already running is only
+// pointed out, the technician decides what to do with the ONU
+func (h *ErpEventHandler) cancel(ctx context.Context, session *domain.Session, event *domain.ErpEvent) error {
+	reason := cmp.Or(event.Reason, MSG_ERP_EVENT_NO_REASON)
+
+	if session.State == domain.StateProvisioning {
+		return h.messenger.SendMessage(ctx, session.ChatID, fmt.Sprintf(MSG_ERP_PROTOCOL_CANCELLED_RUNNING, event.Protocol, reason))
+	}
+
+	cancelled := false
+	updateSession(h.sessionService, session, func(s *domain.Session) {
+		if s.Protocol != event.Protocol || !slices.Contains(erpPendingStates, s.State) {
+			return
+		}
+		s.State = domain.StateMainMenu
+		s.Protocol = ""
+		s.ConnectionInfo = nil
+		s.ProtocolCheck = domain.ProtocolCheck{}
+		s.OverrideBy = ""
+		cancelled = true
+	})
+	if !cancelled {
+		return nil
+	}
+
+	if err := h.messenger.SendMessage(ctx, session.ChatID, fmt.Sprintf(MSG_ERP_PROTOCOL_CANCELLED, event.Protocol, reason)); err != nil {
+		return err
+	}
+	return h.menuHandler.SendMainMenu(ctx, session)
+}
+
+// reload fetches a corrected protocol again for a session reviewing it and sends the summary again
+func (h *ErpEventHandler) reload(ctx context.Context, session *domain.Session, event *domain.ErpEvent) error {
+	if !slices.Contains(erpPendingStates, session.State) {
+		return nil
+	}
+
+	fetchCtx, cancel := context.WithTimeout(ctx, TIMEOUT_ERP_FETCH)
+	defer cancel()
+
+	connInfo, err := h.erpService.GetConnectionInfo(fetchCtx, event.Protocol)
+	if err != nil {
+		_ = h.messenger.SendMessage(ctx, session.ChatID, fmt.Sprintf(MSG_ERP_PROTOCOL_CORRECTED_FAILED, event.Protocol))
+		return err
+	}
+
+	reloaded := false
+	updateSession(h.sessionService, session, func(s *domain.Session) {
+		if s.Protocol != event.Protocol || !slices.Contains(erpPendingStates, s.State) {
+			return
+		}
+		s.ConnectionInfo = connInfo
+		reloaded = true
+	})
+	if !reloaded {
+		return nil
+	}
+
+	if err := h.messenger.SendMessage(ctx, session.ChatID, fmt.Sprintf(MSG_ERP_PROTOCOL_CORRECTED, event.Protocol)); err != nil {
+		return err
+	}
+	if session.State == domain.StateWaitingOverride {
+		return nil
+	}
+	return h.provisioningHandler.sendConfirmationRequest(ctx, session)
+}
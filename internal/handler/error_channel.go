@@ -0,0 +1,63 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"provisioning-assistant/internal/domain"
+	"provisioning-assistant/internal/services"
+	"slices"
+)
+
+// maxErrorStack keeps the reported stack within a Telegram message
+const maxErrorStack = 2000
+
+// ErrorChannel forwards unhandled failures to the error chats, so production issues show up
+// without digging through the logs
+type ErrorChannel struct {
+	chatIDs   []int64
+	throttle  *services.ErrorThrottle
+	messenger *Messenger
+	logger    domain.Logger
+}
+
+// NewErrorChannel creates a new error channel, without chats the failures are only logged
+func NewErrorChannel(chatIDs []int64, throttle *services.ErrorThrottle, messenger *Messenger, logger domain.Logger) *ErrorChannel {
+	return &ErrorChannel{
+		chatIDs:   slices.Clone(chatIDs),
+		throttle:  throttle,
+		messenger: messenger,
+		logger:    logger,
+	}
+}
+
+// Report sends a failure to the error chats, repeats within the dedupe window are counted and
+// mentioned in the next report instead
+func (c *ErrorChannel) Report(ctx context.Context, report *domain.ErrorEvent) {
+	if len(c.chatIDs) == 0 || report.Err == nil {
+		return
+	}
+
+	repeats, admitted := c.throttle.Admit(report.Source, report.Err.Error())
+	if !admitted {
+		return
+	}
+
+	text := fmt.Sprintf(MSG_ERROR_REPORT, report.Source, report.Err)
+	if repeats > 0 {
+		text += fmt.Sprintf(MSG_ERROR_REPEATS, repeats)
+	}
+	if report.Stack != "" {
+		stack := report.Stack
+		if len(stack) > maxErrorStack {
+			stack = stack[:maxErrorStack] + "…"
+		}
+		text += fmt.Sprintf(MSG_ERROR_STACK, stack)
+	}
+
+	// The failure may be Telegram itself, a report that can't be delivered is only logged
+	for _, chatID := range c.chatIDs {
+		if err := c.messenger.Deliver(ctx, chatID, text); err != nil {
+			c.logger.WithError(err).WithField("chat_id", chatID).Warn("Falha ao enviar erro para o canal de erros")
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"provisioning-assistant/internal/clock"
+	"provisioning-assistant/internal/domain"
+	"provisioning-assistant/internal/locale"
+	"provisioning-assistant/internal/services"
+)
+
+type ExportHandler struct {
+	exportService   *services.ExportService
+	artifactService *services.ArtifactService
+	clock           clock.Clock
+	formatter       *locale.Formatter
+	messenger       *Messenger
+	logger          domain.Logger
+}
+
+// NewExportHandler creates a new CSV export command handler
+func NewExportHandler(
+	exportService *services.ExportService,
+	artifactService *services.ArtifactService,
+	clock clock.Clock,
+	formatter *locale.Formatter,
+	messenger *Messenger,
+	logger domain.Logger,
+) *ExportHandler {
+	return &ExportHandler{
+		exportService:   exportService,
+		artifactService: artifactService,
+		clock:           clock,
+		formatter:       formatter,
+		messenger:       messenger,
+		logger:          logger,
+	}
+}
+
+// RegisterCommands registers the CSV export command
+func (h *ExportHandler) RegisterCommands(commands *CommandHandler) {
+	commands.Register("/exportar", domain.RoleSupervisor, h.handleExportCommand)
+}
+
+// handleExportCommand writes the provisionings, failures and signal statistics of a period as CSV files
+// and sends them as documents
+func (h *ExportHandler) handleExportCommand(ctx context.Context, session *domain.Session, args []string) error {
+	if len(args) != 1 {
+		return h.messenger.SendMessage(ctx, session.ChatID, MSG_EXPORT_USAGE)
+	}
+
+	period, err := services.ParseExportPeriod(args[0], h.clock.Now().In(h.formatter.Location()))
+	if err != nil {
+		return h.messenger.SendMessage(ctx, session.ChatID, MSG_EXPORT_USAGE)
+	}
+
+	h.messenger.SendTypingIndicator(ctx, session.ChatID)
+
+	export, err := h.exportService.Export(ctx, period)
+	if err != nil {
+		h.logger.WithError(err).Error("Falha ao exportar auditorias em CSV")
+		return h.messenger.SendMessage(ctx, session.ChatID, fmt.Sprintf(MSG_EXPORT_FAILED, err))
+	}
+
+	for _, artifact := range export.Artifacts {
+		if err := h.sendArtifact(ctx, session, artifact); err != nil {
+			if errors.Is(err, domain.ErrChatUnavailable) {
+				return nil
+			}
+			h.logger.WithError(err).WithField("key", artifact.Key).Error("Falha ao enviar exportação CSV")
+			return h.messenger.SendMessage(ctx, session.ChatID, fmt.Sprintf(MSG_EXPORT_SEND_FAILED, artifact.Name()))
+		}
+	}
+
+	return h.messenger.SendMessage(ctx, session.ChatID, fmt.Sprintf(
+		MSG_EXPORT_DONE,
+		h.formatter.DateTime(period.Since),
+		h.formatter.DateTime(period.Until),
+		export.Provisionings,
+		export.Failures,
+	))
+}
+
+// sendArtifact reads an exported file back from the artifact store and sends it to the chat
+func (h *ExportHandler) sendArtifact(ctx context.Context, session *domain.Session, artifact *domain.Artifact) error {
+	reader, _, err := h.artifactService.Open(ctx, artifact.Key)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	content, err := io.ReadAll(reader)
+	if err != nil {
+		return err
+	}
+
+	return h.messenger.SendDocument(ctx, &domain.Document{
+		ChatID:  session.ChatID,
+		Name:    artifact.Name(),
+		Content: content,
+	})
+}
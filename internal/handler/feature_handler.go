@@ -0,0 +1,130 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"provisioning-assistant/internal/domain"
+	"provisioning-assistant/internal/locale"
+	"provisioning-assistant/internal/services"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+type FeatureHandler struct {
+	featureService *services.FeatureService
+	formatter      *locale.Formatter
+	messenger      *Messenger
+}
+
+// NewFeatureHandler creates a new feature flag command handler
+func NewFeatureHandler(featureService *services.FeatureService, formatter *locale.Formatter, messenger *Messenger) *FeatureHandler {
+	return &FeatureHandler{
+		featureService: featureService,
+		formatter:      formatter,
+		messenger:      messenger,
+	}
+}
+
+// RegisterCommands registers the feature flag administration commands
+func (h *FeatureHandler) RegisterCommands(commands *CommandHandler) {
+	commands.Register("/recurso", domain.RoleAdmin, h.handleFeatureCommand)
+}
+
+// handleFeatureCommand lists the feature flags, turns one on or off with "<nome> on|off" or sets the rollout
+// of a step with "<etapa> <percentual>% [<ip da OLT>]"
+func (h *FeatureHandler) handleFeatureCommand(ctx context.Context, session *domain.Session, args []string) error {
+	if len(args) == 0 {
+		return h.list(ctx, session)
+	}
+
+	if len(args) >= 2 && strings.HasSuffix(args[1], "%") {
+		return h.setRollout(ctx, session, args)
+	}
+
+	if len(args) != 2 {
+		return h.messenger.SendMessage(ctx, session.ChatID, MSG_FEATURE_USAGE)
+	}
+
+	var enabled bool
+	switch strings.ToLower(args[1]) {
+	case "on":
+		enabled = true
+	case "off":
+		enabled = false
+	default:
+		return h.messenger.SendMessage(ctx, session.ChatID, MSG_FEATURE_USAGE)
+	}
+
+	if err := h.featureService.Set(ctx, args[0], enabled); err != nil {
+		return h.messenger.SendMessage(ctx, session.ChatID, fmt.Sprintf(MSG_FEATURE_FAILED, err))
+	}
+
+	return h.messenger.SendMessage(ctx, session.ChatID, fmt.Sprintf(MSG_FEATURE_UPDATED, strings.ToLower(args[0]), h.formatState(enabled)))
+}
+
+// setRollout sets the share of jobs taking the new path of a step, on a single OLT when one is given
+func (h *FeatureHandler) setRollout(ctx context.Context, session *domain.Session, args []string) error {
+	if len(args) > 3 {
+		return h.messenger.SendMessage(ctx, session.ChatID, MSG_FEATURE_USAGE)
+	}
+
+	percent, err := strconv.Atoi(strings.TrimSuffix(args[1], "%"))
+	if err != nil {
+		return h.messenger.SendMessage(ctx, session.ChatID, MSG_FEATURE_USAGE)
+	}
+
+	var olt, scope string
+	if len(args) == 3 {
+		olt = args[2]
+		scope = fmt.Sprintf(MSG_ROLLOUT_UPDATED_OLT, olt)
+	}
+
+	if err := h.featureService.SetRollout(ctx, args[0], olt, percent); err != nil {
+		return h.messenger.SendMessage(ctx, session.ChatID, fmt.Sprintf(MSG_ROLLOUT_FAILED, err))
+	}
+
+	return h.messenger.SendMessage(ctx, session.ChatID, fmt.Sprintf(MSG_ROLLOUT_UPDATED, strings.ToLower(args[0]), percent, scope))
+}
+
+// list sends the state of every known feature flag, followed by the rollout of the pipeline steps
+func (h *FeatureHandler) list(ctx context.Context, session *domain.Session) error {
+	var builder strings.Builder
+
+	names, states := h.featureService.List(ctx)
+	if len(names) == 0 {
+		builder.WriteString(MSG_FEATURE_LIST_EMPTY + "\n")
+	} else {
+		builder.WriteString(MSG_FEATURE_LIST_HEADER)
+		for _, name := range names {
+			builder.WriteString(fmt.Sprintf(MSG_FEATURE_LIST_ITEM, name, h.formatState(states[name])))
+		}
+	}
+
+	policy := h.featureService.RolloutPolicy()
+	builder.WriteString(MSG_ROLLOUT_LIST_HEADER)
+	for _, rollout := range h.featureService.Rollouts(ctx) {
+		if rollout.RolledBack {
+			builder.WriteString(fmt.Sprintf(MSG_ROLLOUT_ROLLED_BACK, rollout.Name))
+			continue
+		}
+
+		var olts strings.Builder
+		for _, olt := range slices.Sorted(maps.Keys(rollout.Olts)) {
+			olts.WriteString(fmt.Sprintf(MSG_ROLLOUT_OLT, olt, rollout.Olts[olt]))
+		}
+		builder.WriteString(fmt.Sprintf(MSG_ROLLOUT_LIST_ITEM, rollout.Name, rollout.Percent, olts.String()))
+		builder.WriteString(fmt.Sprintf(MSG_ROLLOUT_ERROR_RATE, h.formatter.Percent(rollout.ErrorRate), rollout.Samples, h.formatter.Percent(policy.MaxErrorRate)))
+	}
+
+	return h.messenger.SendMessage(ctx, session.ChatID, builder.String())
+}
+
+// formatState describes a feature state
+func (h *FeatureHandler) formatState(enabled bool) string {
+	if enabled {
+		return MSG_FEATURE_ON
+	}
+	return MSG_FEATURE_OFF
+}
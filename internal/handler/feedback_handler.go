@@ -0,0 +1,264 @@
+package handler
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"provisioning-assistant/internal/clock"
+	"provisioning-assistant/internal/domain"
+	"provisioning-assistant/internal/locale"
+	"provisioning-assistant/internal/naming"
+	"provisioning-assistant/internal/services"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	FeedbackDigestPeriod = 7 * 24 * time.Hour
+
+	// feedbackDigestComments caps the comments quoted in the weekly summary
+	feedbackDigestComments = 10
+)
+
+type FeedbackHandler struct {
+	feedbackService *services.FeedbackService
+	sessionService  *services.SessionService
+	lastJobService  *services.LastJobService
+	adminNotifier   *AdminNotifier
+	ownerChatIDs    []int64
+	clock           clock.Clock
+	formatter       *locale.Formatter
+	keyboards       *KeyboardCatalog
+	messenger       *Messenger
+	logger          domain.Logger
+}
+
+// NewFeedbackHandler creates a new feedback handler, feedback goes to the product owner chats
+// or to the admins when none is configured
+func NewFeedbackHandler(
+	feedbackService *services.FeedbackService,
+	sessionService *services.SessionService,
+	lastJobService *services.LastJobService,
+	adminNotifier *AdminNotifier,
+	ownerChatIDs []int64,
+	clock clock.Clock,
+	formatter *locale.Formatter,
+	keyboards *KeyboardCatalog,
+	messenger *Messenger,
+	logger domain.Logger,
+) *FeedbackHandler {
+	return &FeedbackHandler{
+		feedbackService: feedbackService,
+		sessionService:  sessionService,
+		lastJobService:  lastJobService,
+		adminNotifier:   adminNotifier,
+		ownerChatIDs:    ownerChatIDs,
+		clock:           clock,
+		formatter:       formatter,
+		keyboards:       keyboards,
+		messenger:       messenger,
+		logger:          logger,
+	}
+}
+
+// RegisterCommands registers the feedback command
+func (h *FeedbackHandler) RegisterCommands(commands *CommandHandler) {
+	commands.Register("/feedback", domain.RoleTechnician, h.handleFeedbackCommand)
+}
+
+// handleFeedbackCommand starts the rating of the bot, about the last job when there is a recent one
+func (h *FeedbackHandler) handleFeedbackCommand(ctx context.Context, session *domain.Session, args []string) error {
+	anonymous := false
+	switch {
+	case len(args) == 1 && strings.EqualFold(naming.Transliterate(args[0]), "anonimo"):
+		anonymous = true
+	case len(args) != 0:
+		return h.messenger.SendMessage(ctx, session.ChatID, MSG_FEEDBACK_USAGE)
+	}
+
+	if session.State != domain.StateIdle && session.State != domain.StateMainMenu {
+		return h.messenger.SendMessage(ctx, session.ChatID, MSG_FEEDBACK_BUSY)
+	}
+
+	feedback := &domain.Feedback{
+		UserID:    session.UserID,
+		UserName:  session.UserName,
+		Anonymous: anonymous,
+	}
+
+	if job := h.lastJobService.Get(ctx, session.UserID); job != nil {
+		feedback.JobID = job.JobID
+		feedback.Protocol = job.Protocol
+	}
+
+	updateSession(h.sessionService, session, func(s *domain.Session) {
+		s.State = domain.StateWaitingFeedbackRating
+		s.Feedback = feedback
+	})
+
+	about := ""
+	if feedback.Protocol != "" {
+		about = fmt.Sprintf(MSG_FEEDBACK_JOB, feedback.Protocol)
+	}
+
+	message := fmt.Sprintf(MSG_FEEDBACK_RATING, about)
+	if anonymous {
+		message += MSG_FEEDBACK_ANONYMOUS
+	}
+
+	return h.messenger.SendMessageWithKeyboard(ctx, session.ChatID, message, h.ratingKeyboard())
+}
+
+// HandleFeedbackOption processes the rating, skip and cancel buttons
+func (h *FeedbackHandler) HandleFeedbackOption(ctx context.Context, session *domain.Session, option string) error {
+	if session.Feedback == nil {
+		return nil
+	}
+
+	switch {
+	case option == "cancel":
+		h.reset(session)
+		return h.messenger.SendMessage(ctx, session.ChatID, MSG_FEEDBACK_CANCELLED)
+
+	case option == "skip" && session.State == domain.StateWaitingFeedbackComment:
+		return h.submit(ctx, session, "")
+
+	case session.State == domain.StateWaitingFeedbackRating:
+		rating, err := strconv.Atoi(option)
+		if err != nil || rating < domain.FeedbackMinRating || rating > domain.FeedbackMaxRating {
+			return nil
+		}
+
+		updateSession(h.sessionService, session, func(s *domain.Session) {
+			s.State = domain.StateWaitingFeedbackComment
+			s.Feedback.Rating = rating
+		})
+
+		return h.messenger.SendMessageWithKeyboard(ctx, session.ChatID, MSG_FEEDBACK_COMMENT, h.commentKeyboard())
+	}
+
+	return nil
+}
+
+// HandleTextInput takes a typed rating or the free-text comment
+func (h *FeedbackHandler) HandleTextInput(ctx context.Context, session *domain.Session, msg *domain.MessageEvent) error {
+	if session.Feedback == nil {
+		h.reset(session)
+		return nil
+	}
+
+	if session.State == domain.StateWaitingFeedbackRating {
+		return h.HandleFeedbackOption(ctx, session, strings.TrimSpace(msg.Message))
+	}
+
+	return h.submit(ctx, session, msg.Message)
+}
+
+// SendFeedbackDigest sends the weekly feedback summary to the product owners
+func (h *FeedbackHandler) SendFeedbackDigest(ctx context.Context) error {
+	since := h.clock.Now().Add(-FeedbackDigestPeriod)
+
+	summary, err := h.feedbackService.Summarize(ctx, since)
+	if err != nil {
+		return err
+	}
+
+	if summary.Count == 0 {
+		h.notify(ctx, fmt.Sprintf(MSG_FEEDBACK_DIGEST_EMPTY, h.formatter.Date(since)))
+		return nil
+	}
+
+	ratings := make([]string, 0, domain.FeedbackMaxRating)
+	for rating := domain.FeedbackMaxRating; rating >= domain.FeedbackMinRating; rating-- {
+		ratings = append(ratings, fmt.Sprintf("%d⭐ %d", rating, summary.Ratings[rating]))
+	}
+
+	var builder strings.Builder
+	builder.WriteString(fmt.Sprintf(MSG_FEEDBACK_DIGEST, h.formatter.Date(since), summary.Count, h.formatter.Decimal(summary.Average, 1), strings.Join(ratings, " · ")))
+
+	if len(summary.Comments) > 0 {
+		builder.WriteString(MSG_FEEDBACK_DIGEST_COMMENTS)
+		for _, feedback := range summary.Comments[:min(len(summary.Comments), feedbackDigestComments)] {
+			builder.WriteString(fmt.Sprintf(MSG_FEEDBACK_DIGEST_COMMENT, strings.Repeat("⭐", feedback.Rating), feedback.Comment))
+		}
+	}
+
+	h.notify(ctx, builder.String())
+	return nil
+}
+
+// submit stores the feedback, forwards it to the product owners and closes the flow
+func (h *FeedbackHandler) submit(ctx context.Context, session *domain.Session, comment string) error {
+	feedback := *session.Feedback
+	feedback.Comment = comment
+	h.reset(session)
+
+	if err := h.feedbackService.Submit(ctx, &feedback); err != nil {
+		h.logger.WithError(err).WithField("user_id", session.UserID).Error("Falha ao registrar feedback")
+		return h.messenger.SendMessage(ctx, session.ChatID, fmt.Sprintf(MSG_FEEDBACK_FAILED, err))
+	}
+
+	h.notify(ctx, h.formatFeedback(&feedback))
+
+	return h.messenger.SendMessage(ctx, session.ChatID, MSG_FEEDBACK_THANKS)
+}
+
+// formatFeedback describes a single feedback for the product owners
+func (h *FeedbackHandler) formatFeedback(feedback *domain.Feedback) string {
+	technician := MSG_FEEDBACK_ANONYMOUS_NAME
+	if !feedback.Anonymous {
+		technician = feedback.UserName
+	}
+
+	message := fmt.Sprintf(
+		MSG_FEEDBACK_RECEIVED,
+		strings.Repeat("⭐", feedback.Rating),
+		technician,
+		cmp.Or(feedback.JobID, MSG_FEEDBACK_NONE),
+		cmp.Or(feedback.Protocol, MSG_FEEDBACK_NONE),
+	)
+
+	if feedback.Comment != "" {
+		message += fmt.Sprintf(MSG_FEEDBACK_RECEIVED_COMMENT, feedback.Comment)
+	}
+
+	return message
+}
+
+// notify delivers a message to the product owner chats, falling back to the admins
+func (h *FeedbackHandler) notify(ctx context.Context, message string) {
+	if len(h.ownerChatIDs) == 0 {
+		h.adminNotifier.Notify(ctx, message)
+		return
+	}
+
+	for _, chatID := range h.ownerChatIDs {
+		if err := h.messenger.SendMessage(ctx, chatID, message); err != nil {
+			h.logger.WithError(err).WithField("chat_id", chatID).Warn("Falha ao enviar feedback aos responsáveis pelo produto")
+		}
+	}
+}
+
+// reset leaves the feedback flow
+func (h *FeedbackHandler) reset(session *domain.Session) {
+	updateSession(h.sessionService, session, func(s *domain.Session) {
+		s.State = domain.StateIdle
+		s.Feedback = nil
+	})
+}
+
+// ratingKeyboard builds the one to five rating keyboard
+func (h *FeedbackHandler) ratingKeyboard() *domain.Keyboard {
+	ratings := make([]string, 0, domain.FeedbackMaxRating)
+	for rating := domain.FeedbackMinRating; rating <= domain.FeedbackMaxRating; rating++ {
+		ratings = append(ratings, strconv.Itoa(rating))
+	}
+
+	return h.keyboards.Build(KeyboardFeedbackRating, WithChoices(ratings...))
+}
+
+// commentKeyboard builds the keyboard offered while waiting for the comment
+func (h *FeedbackHandler) commentKeyboard() *domain.Keyboard {
+	return h.keyboards.Build(KeyboardFeedbackComment)
+}
@@ -0,0 +1,537 @@
+package handler_test
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"provisioning-assistant/internal/buildinfo"
+	"provisioning-assistant/internal/clock"
+	"provisioning-assistant/internal/database"
+	"provisioning-assistant/internal/domain"
+	"provisioning-assistant/internal/domain/dto"
+	"provisioning-assistant/internal/handler"
+	"provisioning-assistant/internal/ids"
+	"provisioning-assistant/internal/locale"
+	"provisioning-assistant/internal/logger"
+	"provisioning-assistant/internal/naming"
+	"provisioning-assistant/internal/repository"
+	"provisioning-assistant/internal/scheduler"
+	"provisioning-assistant/internal/services"
+	"provisioning-assistant/internal/unm"
+
+	"github.com/gookit/event"
+)
+
+const (
+	goldenDir    = "testdata/golden"
+	goldenUserID = 1001
+)
+
+// goldenEpoch is where the fake clock of every conversation starts
+var goldenEpoch = time.Date(2025, time.March, 10, 9, 0, 0, 0, time.UTC)
+
+var update = flag.Bool("update", false, "rewrite the expected responses of the golden conversations")
+
+// goldenConversation is a scripted conversation replayed against the handler layer
+type goldenConversation struct {
+	Description string                         `json:"description"`
+	Setup       goldenSetup                    `json:"setup"`
+	Erp         map[string]*dto.ConnectionInfo `json:"erp,omitempty"`
+	WorkOrders  []*dto.WorkOrder               `json:"work_orders,omitempty"`
+	Cancelled   []*dto.CancelledContract       `json:"cancelled_contracts,omitempty"`
+	Steps       []goldenStep                   `json:"steps"`
+}
+
+type goldenSetup struct {
+	ConsentRequired    bool     `json:"consent_required"`
+	Captcha            bool     `json:"captcha"`
+	MaxInvalidAttempts int      `json:"max_invalid_attempts,omitempty"`
+	SupportContact     string   `json:"support_contact,omitempty"`
+	Training           bool     `json:"training,omitempty"`
+	SuccessSections    []string `json:"success_sections,omitempty"`
+	SpeedTest          bool     `json:"speed_test,omitempty"`
+	DependenciesDown   bool     `json:"dependencies_down,omitempty"`
+
+	// AdminChat makes the test user's chat receive the admin alerts
+	AdminChat bool `json:"admin_chat,omitempty"`
+
+	// OltOnus lists the serials the fake UNM reports on every PON
+	OltOnus []string `json:"olt_onus,omitempty"`
+
+	// Degraded lists the subsystems the safe mode left off
+	Degraded []services.Degradation `json:"degraded,omitempty"`
+
+	Tl1Permissions services.Tl1PermissionPolicy `json:"tl1_permissions,omitempty"`
+
+	// Release is the version the bot runs, announced from the changelog when set
+	Release   string              `json:"release,omitempty"`
+	Changelog []buildinfo.Release `json:"changelog,omitempty"`
+
+	Templates        []domain.ProvisioningTemplate   `json:"templates,omitempty"`
+	SignalThresholds *services.SignalThresholdPolicy `json:"signal_thresholds,omitempty"`
+}
+
+type goldenStep struct {
+	Advance  string           `json:"advance,omitempty"`
+	Send     string           `json:"send,omitempty"`
+	Callback string           `json:"callback,omitempty"`
+	Job      string           `json:"job,omitempty"`
+	State    string           `json:"state"`
+	Expect   []goldenResponse `json:"expect"`
+}
+
+type goldenResponse struct {
+	Text     string     `json:"text"`
+	Buttons  [][]string `json:"buttons,omitempty"`
+	Document string     `json:"document,omitempty"`
+}
+
+// TestGoldenConversations replays every conversation under testdata/golden
+func TestGoldenConversations(t *testing.T) {
+	files, err := filepath.Glob(filepath.Join(goldenDir, "*.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(files) == 0 {
+		t.Fatal("nenhuma conversa golden encontrada")
+	}
+
+	for _, file := range files {
+		t.Run(strings.TrimSuffix(filepath.Base(file), ".json"), func(t *testing.T) {
+			runGolden(t, file)
+		})
+	}
+}
+
+// runGolden replays a conversation file and compares or rewrites its expectations
+func runGolden(t *testing.T, file string) {
+	content, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var conversation goldenConversation
+	if err := json.Unmarshal(content, &conversation); err != nil {
+		t.Fatalf("conversa golden inválida: %v", err)
+	}
+
+	h := newHarness(t, conversation)
+
+	for i := range conversation.Steps {
+		step := &conversation.Steps[i]
+		responses := h.play(t, step)
+		state := h.state()
+
+		if *update {
+			step.Expect = responses
+			step.State = state
+			continue
+		}
+
+		if state != step.State {
+			t.Errorf("passo %d (%s): estado esperado %q, obtido %q", i+1, step.label(), step.State, state)
+		}
+
+		compareResponses(t, i+1, step, responses)
+	}
+
+	if *update {
+		output, err := json.MarshalIndent(conversation, "", "  ")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(file, append(output, '\n'), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// compareResponses checks the bot replies of a step against the golden expectations
+func compareResponses(t *testing.T, index int, step *goldenStep, responses []goldenResponse) {
+	t.Helper()
+
+	if len(responses) != len(step.Expect) {
+		t.Errorf("passo %d (%s): esperadas %d respostas, obtidas %d: %q", index, step.label(), len(step.Expect), len(responses), responses)
+		return
+	}
+
+	for i, expected := range step.Expect {
+		got := responses[i]
+		if got.Text != expected.Text {
+			t.Errorf("passo %d (%s) resposta %d:\nesperado: %q\nobtido:   %q", index, step.label(), i+1, expected.Text, got.Text)
+		}
+
+		gotButtons, _ := json.Marshal(got.Buttons)
+		expectedButtons, _ := json.Marshal(expected.Buttons)
+		if string(gotButtons) != string(expectedButtons) {
+			t.Errorf("passo %d (%s) resposta %d: teclado esperado %s, obtido %s", index, step.label(), i+1, expectedButtons, gotButtons)
+		}
+	}
+}
+
+// label describes the input of a step in failure messages
+func (s *goldenStep) label() string {
+	if s.Job != "" {
+		return "job " + s.Job
+	}
+	if s.Callback != "" {
+		return "callback " + s.Callback
+	}
+	return "mensagem " + s.Send
+}
+
+// harness wires the handler layer with in-memory services and fake UNM/ERP backends
+type harness struct {
+	eventManager *event.Manager
+	sessions     *services.SessionService
+	clock        *clock.Fake
+	jobs         map[string]func(ctx context.Context) error
+
+	mu        sync.Mutex
+	responses []goldenResponse
+}
+
+// newHarness builds a message handler for the conversation setup
+func newHarness(t *testing.T, conversation goldenConversation) *harness {
+	t.Helper()
+
+	zlog, err := logger.New(&logger.Config{Level: "disabled"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	log := &logger.ZLogXAdapter{ZLogX: zlog}
+
+	eventManager := event.NewManager("golden")
+	fakeClock := clock.NewFake(goldenEpoch)
+	sessions := services.NewSessionService(fakeClock)
+	unmClient := unm.New("user", "pass", &fakeTransporter{onus: conversation.Setup.OltOnus}, log)
+	leader := services.NewLeaderService(nil, log)
+	formatter, err := locale.NewFormatter("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	namingPolicy, err := naming.NewPolicy("", 0, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Seeded entropy keeps the audit IDs shown in the conversations stable
+	idGenerator := ids.NewGenerator(fakeClock, rand.NewChaCha8([32]byte{}))
+	auditRepository := repository.NewAuditRepository(idGenerator)
+	tokenRepository := repository.NewTokenRepository()
+	bindingRepository := repository.NewBindingRepository()
+	bindingService := services.NewBindingService(bindingRepository, log)
+	trainingService := services.NewTrainingService(repository.NewStateRepository(), log)
+	if conversation.Setup.Training {
+		if err := trainingService.Set(context.Background(), goldenUserID, true); err != nil {
+			t.Fatal(err)
+		}
+	}
+	auditService := services.NewAuditService(auditRepository, repository.NewAuditRepository(idGenerator), fakeClock, log)
+	artifactService := services.NewArtifactService(repository.NewLocalArtifactStore(t.TempDir()), services.ArtifactPolicy{}, fakeClock, log)
+	archiveService := services.NewArchiveService(auditRepository, artifactService, 0, log)
+	templateService := services.NewPlanTemplateService(conversation.Setup.Templates, repository.NewStateRepository(), log)
+	var thresholdPolicy services.SignalThresholdPolicy
+	if conversation.Setup.SignalThresholds != nil {
+		thresholdPolicy = *conversation.Setup.SignalThresholds
+	}
+	signalThresholds := services.NewSignalThresholdService(thresholdPolicy)
+
+	permissions := services.NewTl1PermissionService(conversation.Setup.Tl1Permissions, log)
+	queue := services.NewProvisioningQueue(0)
+	loadShedder := services.NewLoadShedder(queue, services.LoadShedPolicy{}, fakeClock, log)
+	featureService := services.NewFeatureService(repository.NewStateRepository(), nil, services.RolloutPolicy{}, fakeClock, log)
+	provisioningService := services.NewProvisioningService(unmClient, unm.New("user", "pass", unm.NewSimulator(), log), templateService, signalThresholds, namingPolicy, unm.CommandBudget{}, permissions, loadShedder, featureService, fakeClock, log)
+	erpService := services.NewErpService(&fakeErpRepository{connections: conversation.Erp, workOrders: conversation.WorkOrders, cancelled: conversation.Cancelled}, nil, services.ErpRetryPolicy{}, fakeClock, log)
+	circuitService := services.NewProvisioningCircuitService(services.CircuitPolicy{}, fakeClock)
+	probeService := services.NewUnmProbeService(nil, circuitService, services.UnmProbePolicy{}, fakeClock, log)
+
+	// The watchdog probes answer for the fake dependencies, down when the conversation starts an outage
+	probe := func(context.Context) error {
+		if conversation.Setup.DependenciesDown {
+			return errors.New("conexão recusada")
+		}
+		return nil
+	}
+	maintenanceService := services.NewMaintenanceService(repository.NewStateRepository(), fakeClock, log)
+	watchdogService := services.NewDependencyWatchdogService(map[string]services.DependencyProbe{"unm": probe, "erp": probe}, fakeClock, log)
+
+	var adminChatIDs []int64
+	if conversation.Setup.AdminChat {
+		adminChatIDs = []int64{goldenUserID}
+	}
+
+	messageHandler := handler.NewMessageHandler(
+		eventManager,
+		provisioningService,
+		services.NewUserService(),
+		sessions,
+		erpService,
+		services.NewProtocolCheckService(services.ProtocolStatusPolicy{}, auditService, log),
+		auditService,
+		services.NewTokenService(tokenRepository, log),
+		services.NewChallengeService(conversation.Setup.Captcha, fakeClock),
+		services.NewAccessGuardService(fakeClock),
+		services.NewLastJobService(repository.NewStateRepository(), fakeClock, log),
+		bindingService,
+		services.NewAckService(repository.NewStateRepository(), 0, fakeClock, log),
+		circuitService,
+		services.NewOperationService(0, fakeClock),
+		services.NewBackupService(auditRepository, bindingRepository, tokenRepository, repository.NewStateRepository(), templateService, artifactService, "", log),
+		archiveService,
+		featureService,
+		trainingService,
+		services.NewTl1ConsoleService(map[string]*unm.UNMClient{"simulador": unm.New("user", "pass", unm.NewSimulator(), log)}, []int64{goldenUserID}, nil, permissions, services.NewTl1CaptureService(artifactService, log), repository.NewStateRepository(), log),
+		services.NewCredentialCheckService(nil, services.CredentialPolicy{}, log),
+		queue,
+		artifactService,
+		services.NewIdempotencyService(repository.NewStateRepository(), services.DefaultIdempotencyWindow, fakeClock, log),
+		templateService,
+		services.NewTemplateCatalogService(templateService, artifactService, log),
+		services.NewFeedbackService(repository.NewStateRepository(), log),
+		services.NewAlertService(provisioningService, erpService, circuitService, queue, probeService),
+		maintenanceService,
+		services.NewOltSuggestionService(auditService, signalThresholds, fakeClock, log),
+		services.NewOnuHistoryService(auditService, archiveService, log),
+		watchdogService,
+		services.NewChangelogService(conversation.Setup.Release, conversation.Setup.Changelog, bindingService, log),
+		services.NewSafeModeService(conversation.Setup.Degraded),
+		services.NewExportService(auditService, artifactService, log),
+		services.NewWorkOrderService(erpService, bindingService, repository.NewStateRepository(), services.WorkOrderPolicy{Enabled: true}, fakeClock, log),
+		services.NewPonCapacityService(auditService, provisioningService, maintenanceService, repository.NewStateRepository(), 0, fakeClock, log),
+		services.NewOrphanOnuService(auditService, erpService, provisioningService, repository.NewStateRepository(), fakeClock, log),
+		services.NewDeprovisionService(erpService, auditService, provisioningService, services.DeprovisionPolicy{}, fakeClock, log),
+		probeService,
+		loadShedder,
+		scheduler.New(leader, formatter.Location(), fakeClock, log),
+		handler.ConsentPolicy{Required: conversation.Setup.ConsentRequired, Tenant: "golden", Version: "1"},
+		handler.InputPolicy{MaxInvalidAttempts: conversation.Setup.MaxInvalidAttempts, SupportContact: conversation.Setup.SupportContact},
+		handler.SuccessMessagePolicy{Sections: conversation.Setup.SuccessSections},
+		handler.NudgePolicy{After: handler.DefaultNudgeDelay},
+		handler.SpeedTestPolicy{Enabled: conversation.Setup.SpeedTest},
+		handler.GreetingPolicy{Returning: true, QuickActions: handler.DefaultQuickActions},
+		handler.DefaultKeyboardCatalog(),
+		adminChatIDs,
+		nil,
+		nil,
+		nil,
+		handler.InteractionInstant,
+		fakeClock,
+		formatter,
+		log,
+	)
+	messageHandler.RegisterEventListeners()
+
+	h := &harness{
+		eventManager: eventManager,
+		sessions:     sessions,
+		clock:        fakeClock,
+		jobs: map[string]func(ctx context.Context) error{
+			"stalled_session_nudge":    messageHandler.NudgeStalledSessions,
+			"dependency_watchdog":      messageHandler.WatchDependencies,
+			"work_order_greeting":      messageHandler.GreetAssignedTechnicians,
+			"cancelled_deprovisioning": messageHandler.DeprovisionCancelled,
+		},
+	}
+
+	eventManager.On("telegram.send.message", event.ListenerFunc(func(e event.Event) error {
+		response, ok := e.Get("response").(*domain.MessageResponse)
+		if !ok {
+			t.Fatalf("resposta inválida: %T", e.Get("response"))
+		}
+		h.record(response)
+		return nil
+	}))
+	eventManager.On("telegram.send.typing", event.ListenerFunc(func(e event.Event) error {
+		return nil
+	}))
+	eventManager.On("telegram.send.document", event.ListenerFunc(func(e event.Event) error {
+		document, ok := e.Get("document").(*domain.Document)
+		if !ok {
+			t.Fatalf("documento inválido: %T", e.Get("document"))
+		}
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		h.responses = append(h.responses, goldenResponse{Text: document.Caption, Document: document.Name})
+		return nil
+	}))
+
+	return h
+}
+
+// play moves the clock forward when the step asks to, sends the step input or runs the step job
+// and returns the bot replies
+func (h *harness) play(t *testing.T, step *goldenStep) []goldenResponse {
+	t.Helper()
+
+	if step.Advance != "" {
+		elapsed, err := time.ParseDuration(step.Advance)
+		if err != nil {
+			t.Fatalf("%s: avanço do relógio inválido: %v", step.label(), err)
+		}
+		h.clock.Advance(elapsed)
+	}
+
+	h.mu.Lock()
+	h.responses = []goldenResponse{}
+	h.mu.Unlock()
+
+	var err error
+	switch {
+	case step.Job != "":
+		job, exists := h.jobs[step.Job]
+		if !exists {
+			t.Fatalf("%s: job desconhecido", step.label())
+		}
+		err = job(context.Background())
+	case step.Callback != "":
+		err, _ = h.eventManager.Fire("telegram.callback.received", event.M{
+			"ctx": context.Background(),
+			"event": &domain.CallbackEvent{
+				UserID: goldenUserID,
+				ChatID: goldenUserID,
+				Data:   step.Callback,
+			},
+		})
+	default:
+		err, _ = h.eventManager.Fire("telegram.message.received", event.M{
+			"ctx": context.Background(),
+			"event": &domain.MessageEvent{
+				UserID:  goldenUserID,
+				ChatID:  goldenUserID,
+				Message: step.Send,
+			},
+		})
+	}
+
+	if err != nil {
+		t.Fatalf("%s: %v", step.label(), err)
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.responses
+}
+
+// record stores a reply sent by the bot
+func (h *harness) record(response *domain.MessageResponse) {
+	reply := goldenResponse{Text: response.Text}
+
+	if response.Keyboard != nil {
+		for _, row := range response.Keyboard.Buttons {
+			var data []string
+			for _, button := range row {
+				data = append(data, cmp.Or(button.Data, button.URL))
+			}
+			reply.Buttons = append(reply.Buttons, data)
+		}
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.responses = append(h.responses, reply)
+}
+
+// state returns the current session state of the test user
+func (h *harness) state() string {
+	session := h.sessions.GetSession(goldenUserID)
+	if session == nil {
+		return ""
+	}
+	return string(session.State)
+}
+
+// fakeErpRepository serves connection information from the conversation file
+type fakeErpRepository struct {
+	connections map[string]*dto.ConnectionInfo
+	workOrders  []*dto.WorkOrder
+	cancelled   []*dto.CancelledContract
+}
+
+func (r *fakeErpRepository) GetConnInfoByProtocol(ctx context.Context, protocol string) (*dto.ConnectionInfo, error) {
+	connInfo, exists := r.connections[protocol]
+	if !exists {
+		return nil, database.ErrNotFound
+	}
+
+	clone := *connInfo
+	return &clone, nil
+}
+
+func (r *fakeErpRepository) GetConnInfoByPPPoEUsername(ctx context.Context, username string) (*dto.ConnectionInfo, error) {
+	for _, connInfo := range r.connections {
+		if strings.EqualFold(connInfo.ConnectionClientPPPoEUsername, username) {
+			clone := *connInfo
+			return &clone, nil
+		}
+	}
+
+	return nil, database.ErrNotFound
+}
+
+func (r *fakeErpRepository) ListWorkOrders(ctx context.Context, since time.Time) ([]*dto.WorkOrder, error) {
+	var workOrders []*dto.WorkOrder
+	for _, workOrder := range r.workOrders {
+		if !workOrder.AssignedAt.Before(since) {
+			workOrders = append(workOrders, workOrder)
+		}
+	}
+	return workOrders, nil
+}
+
+func (r *fakeErpRepository) ListCancelledContracts(ctx context.Context, since time.Time) ([]*dto.CancelledContract, error) {
+	var contracts []*dto.CancelledContract
+	for _, contract := range r.cancelled {
+		if !contract.CancelledAt.Before(since) {
+			contracts = append(contracts, contract)
+		}
+	}
+	return contracts, nil
+}
+
+// fakeTransporter accepts every TL1 command with an empty successful response, the optical listing of a
+// PON reporting the configured ONUs
+type fakeTransporter struct {
+	connected bool
+	onus      []string
+}
+
+func (t *fakeTransporter) Close() error {
+	t.connected = false
+	return nil
+}
+
+func (t *fakeTransporter) Reconnect() error {
+	t.connected = true
+	return nil
+}
+
+func (t *fakeTransporter) IsConnected() bool {
+	return t.connected
+}
+
+func (t *fakeTransporter) GetAddress() string {
+	return "unm.test:3337"
+}
+
+func (t *fakeTransporter) Send(ctx context.Context, cmd string) (string, error) {
+	if !strings.HasPrefix(cmd, "LST-OMDDM") || len(t.onus) == 0 {
+		return "", nil
+	}
+
+	lines := []string{"ONUID\tRxPower\tRxPowerR\tTxPower\tTxPowerR"}
+	for _, serial := range t.onus {
+		lines = append(lines, serial+"\t-19.50\tnormal\t2.30\tnormal")
+	}
+	return strings.Join(append(lines, ";"), "\r\n"), nil
+}
@@ -0,0 +1,178 @@
+package handler
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"provisioning-assistant/internal/clock"
+	"provisioning-assistant/internal/domain"
+	"provisioning-assistant/internal/locale"
+	"provisioning-assistant/internal/services"
+	"slices"
+	"time"
+)
+
+const (
+	// DefaultQuickActions is how many of their most used operations a returning technician gets as shortcuts
+	DefaultQuickActions = 2
+
+	// greetingUsageWindow is how far back the activations of a technician are counted to pick their shortcuts
+	greetingUsageWindow = 30 * 24 * time.Hour
+)
+
+// GreetingPolicy configures the greeting of /start. A returning technician, whose account is still bound
+// to their CPF, is signed in with a summary of their day instead of being asked for the CPF again.
+type GreetingPolicy struct {
+	Returning    bool
+	QuickActions int
+}
+
+type GreetingHandler struct {
+	policy         GreetingPolicy
+	auditService   *services.AuditService
+	authHandler    *AuthenticationHandler
+	consentHandler *ConsentHandler
+	menuHandler    *MenuHandler
+	manualHandler  *ManualProvisioningHandler
+	signalHandler  *SignalHandler
+	clock          clock.Clock
+	formatter      *locale.Formatter
+	keyboards      *KeyboardCatalog
+	messenger      *Messenger
+	logger         domain.Logger
+}
+
+// NewGreetingHandler creates a new handler greeting the technicians coming back to the bot
+func NewGreetingHandler(
+	policy GreetingPolicy,
+	auditService *services.AuditService,
+	authHandler *AuthenticationHandler,
+	consentHandler *ConsentHandler,
+	menuHandler *MenuHandler,
+	manualHandler *ManualProvisioningHandler,
+	signalHandler *SignalHandler,
+	clock clock.Clock,
+	formatter *locale.Formatter,
+	keyboards *KeyboardCatalog,
+	messenger *Messenger,
+	logger domain.Logger,
+) *GreetingHandler {
+	return &GreetingHandler{
+		policy:         policy,
+		auditService:   auditService,
+		authHandler:    authHandler,
+		consentHandler: consentHandler,
+		menuHandler:    menuHandler,
+		manualHandler:  manualHandler,
+		signalHandler:  signalHandler,
+		clock:          clock,
+		formatter:      formatter,
+		keyboards:      keyboards,
+		messenger:      messenger,
+		logger:         logger,
+	}
+}
+
+// GreetReturning signs a returning technician in and greets them with their activations of the day and
+// shortcuts to their most used operations, reporting false when the user must go through the CPF prompt
+func (h *GreetingHandler) GreetReturning(ctx context.Context, session *domain.Session) (bool, error) {
+	if !h.policy.Returning || h.consentHandler.IsPending(ctx, session) {
+		return false, nil
+	}
+
+	if !h.authHandler.ResumeBinding(ctx, session) {
+		return false, nil
+	}
+
+	now := h.clock.Now().In(h.formatter.Location())
+	records, err := h.activations(ctx, session.UserID, now.Add(-greetingUsageWindow))
+	if err != nil {
+		h.logger.WithError(err).WithField("user_id", session.UserID).Warn("Falha ao resumir ativações do técnico")
+	}
+
+	summary := MSG_RETURNING_TODAY_UNKNOWN
+	if err == nil {
+		midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+		summary = h.summary(records, midnight)
+	}
+
+	message := h.menuHandler.Banners(ctx) + fmt.Sprintf(MSG_RETURNING_GREETING, session.UserName, summary)
+	keyboard := h.keyboards.Build(KeyboardQuickActions, WithButtons(h.quickActions(ctx, session, records)...))
+
+	return true, h.messenger.SendMessageWithKeyboard(ctx, session.ChatID, message, keyboard)
+}
+
+// activations returns the audit records of the user created since the given time, oldest first
+func (h *GreetingHandler) activations(ctx context.Context, userID int64, since time.Time) ([]*domain.AuditRecord, error) {
+	records, err := h.auditService.ListRecords(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var own []*domain.AuditRecord
+	for _, record := range records {
+		if record.UserID == userID && !record.CreatedAt.Before(since) {
+			own = append(own, record)
+		}
+	}
+
+	slices.SortFunc(own, func(a, b *domain.AuditRecord) int {
+		return a.CreatedAt.Compare(b.CreatedAt)
+	})
+	return own, nil
+}
+
+// summary describes the activations since midnight and the outcome of the latest one
+func (h *GreetingHandler) summary(records []*domain.AuditRecord, midnight time.Time) string {
+	index := slices.IndexFunc(records, func(record *domain.AuditRecord) bool { return !record.CreatedAt.Before(midnight) })
+	if index < 0 {
+		return MSG_RETURNING_TODAY_NONE
+	}
+
+	today := records[index:]
+	last := today[len(today)-1]
+
+	outcome := MSG_RETURNING_FAILED
+	if last.Success {
+		outcome = MSG_RETURNING_OK
+	}
+
+	if len(today) == 1 {
+		return fmt.Sprintf(MSG_RETURNING_TODAY_ONE, h.formatter.Time(last.CreatedAt), outcome)
+	}
+	return fmt.Sprintf(MSG_RETURNING_TODAY, len(today), h.formatter.Time(last.CreatedAt), outcome)
+}
+
+// quickActions picks the operations the technician used the most over the window, provisioning by protocol
+// when they have none yet, and the signal re-check while their last job can still be checked
+func (h *GreetingHandler) quickActions(ctx context.Context, session *domain.Session, records []*domain.AuditRecord) []string {
+	usage := make(map[string]int)
+	for _, record := range records {
+		if record.Manual {
+			usage[ButtonManual]++
+		} else {
+			usage[ButtonQuickProvision]++
+		}
+	}
+
+	var actions []string
+	for _, action := range []string{ButtonQuickProvision, ButtonManual} {
+		if usage[action] == 0 || (action == ButtonManual && !h.manualHandler.IsAllowed(session)) {
+			continue
+		}
+		actions = append(actions, action)
+	}
+	slices.SortStableFunc(actions, func(a, b string) int {
+		return cmp.Compare(usage[b], usage[a])
+	})
+
+	actions = actions[:min(len(actions), max(h.policy.QuickActions, 0))]
+	if len(actions) == 0 && h.policy.QuickActions > 0 {
+		actions = append(actions, ButtonQuickProvision)
+	}
+
+	if h.signalHandler.HasRecheck(ctx, session) {
+		actions = append(actions, ButtonRecheckSignal)
+	}
+	return actions
+}
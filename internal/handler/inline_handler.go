@@ -0,0 +1,176 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"provisioning-assistant/internal/domain"
+	"provisioning-assistant/internal/locale"
+	"provisioning-assistant/internal/services"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var inlineSerialPattern = regexp.MustCompile(`^[A-Za-z0-9]{8,16}$`)
+
+type InlineHandler struct {
+	userService         *services.UserService
+	bindingService      *services.BindingService
+	erpService          *services.ErpService
+	auditService        *services.AuditService
+	provisioningService *services.ProvisioningService
+	formatter           *locale.Formatter
+	messenger           *Messenger
+	logger              domain.Logger
+}
+
+// NewInlineHandler creates a new inline query quick action handler
+func NewInlineHandler(
+	userService *services.UserService,
+	bindingService *services.BindingService,
+	erpService *services.ErpService,
+	auditService *services.AuditService,
+	provisioningService *services.ProvisioningService,
+	formatter *locale.Formatter,
+	messenger *Messenger,
+	logger domain.Logger,
+) *InlineHandler {
+	return &InlineHandler{
+		userService:         userService,
+		bindingService:      bindingService,
+		erpService:          erpService,
+		auditService:        auditService,
+		provisioningService: provisioningService,
+		formatter:           formatter,
+		messenger:           messenger,
+		logger:              logger,
+	}
+}
+
+// HandleInlineQuery answers an inline query with a signal snapshot of the requested ONU
+func (h *InlineHandler) HandleInlineQuery(ctx context.Context, query *domain.InlineQueryEvent) error {
+	user := h.authorize(ctx, query.UserID)
+	if user == nil {
+		return h.answerNotice(ctx, query, MSG_INLINE_UNAUTHORIZED_TITLE, MSG_INLINE_UNAUTHORIZED)
+	}
+
+	term := strings.TrimSpace(query.Query)
+
+	queryCtx, cancel := context.WithTimeout(ctx, TIMEOUT_INLINE_QUERY)
+	defer cancel()
+
+	var job *domain.LastJob
+	switch {
+	case h.isProtocol(term):
+		record, _ := h.auditService.FindLatestByProtocol(queryCtx, term)
+		if !h.allowed(user, query.UserID, record) {
+			return h.answerNotice(ctx, query, MSG_INLINE_UNAUTHORIZED_TITLE, MSG_INLINE_NOT_ALLOWED)
+		}
+		job = h.lookupProtocol(queryCtx, term)
+	case inlineSerialPattern.MatchString(term):
+		record, err := h.auditService.FindLatestBySerial(queryCtx, term)
+		if err == nil {
+			if !h.allowed(user, query.UserID, record) {
+				return h.answerNotice(ctx, query, MSG_INLINE_UNAUTHORIZED_TITLE, MSG_INLINE_NOT_ALLOWED)
+			}
+			job = h.jobFromAudit(record)
+		}
+	default:
+		return h.answerNotice(ctx, query, MSG_INLINE_USAGE_TITLE, MSG_INLINE_USAGE)
+	}
+
+	if job == nil {
+		return h.answerNotice(ctx, query, MSG_INLINE_USAGE_TITLE, fmt.Sprintf(MSG_INLINE_NOT_FOUND, term))
+	}
+
+	h.logger.WithFields(map[string]any{
+		"user_id": query.UserID,
+		"serial":  job.Serial,
+	}).Info("Consulta rápida de sinal via inline")
+
+	text := fmt.Sprintf(MSG_INLINE_OFFLINE, job.Serial)
+	state, err := h.provisioningService.OnuState(queryCtx, job)
+	switch {
+	case err != nil:
+		h.logger.WithError(err).WithField("serial", job.Serial).Warn("Falha na consulta rápida de sinal")
+	case state.Online:
+		text = fmt.Sprintf(MSG_RECHECK_HEADER, job.Serial, job.Contract) + formatSignalInfo(h.formatter, state.Signal)
+	}
+
+	return h.messenger.AnswerInlineQuery(ctx, query.QueryID, []domain.InlineResult{{
+		ID:          job.Serial,
+		Title:       fmt.Sprintf(MSG_INLINE_SIGNAL_TITLE, job.Serial),
+		Description: fmt.Sprintf(MSG_INLINE_SIGNAL_DESCRIPTION, job.Contract, job.OltIP, job.Slot, job.Port),
+		Text:        text,
+	}})
+}
+
+// authorize resolves the bound user of a Telegram account, requiring at least the technician role
+func (h *InlineHandler) authorize(ctx context.Context, userID int64) *domain.User {
+	binding := h.bindingService.Get(ctx, userID)
+	if binding == nil || binding.TaxID == "" {
+		return nil
+	}
+
+	user := h.userService.ValidateTaxID(binding.TaxID)
+	if user == nil || !user.Role.Includes(domain.RoleTechnician) {
+		return nil
+	}
+
+	return user
+}
+
+// allowed reports whether the user may read the ONU of an audit record, only its own provisionings unless a
+// supervisor. A protocol the bot never provisioned belongs to no technician.
+func (h *InlineHandler) allowed(user *domain.User, userID int64, record *domain.AuditRecord) bool {
+	if user.Role.Includes(domain.RoleSupervisor) {
+		return true
+	}
+	return record != nil && record.UserID == userID
+}
+
+// lookupProtocol fetches the ONU location of an ERP protocol
+func (h *InlineHandler) lookupProtocol(ctx context.Context, protocol string) *domain.LastJob {
+	connInfo, err := h.erpService.GetConnectionInfo(ctx, protocol)
+	if err != nil {
+		return nil
+	}
+
+	return &domain.LastJob{
+		Protocol: protocol,
+		Contract: connInfo.ContractDescription,
+		Serial:   connInfo.ConnectionEquipmentSerialNumber,
+		OltIP:    connInfo.ConnectionOltIP,
+		Slot:     connInfo.ConnectionOltSlot,
+		Port:     connInfo.ConnectionOltPort,
+	}
+}
+
+// jobFromAudit builds the ONU location from a provisioning audit record
+func (h *InlineHandler) jobFromAudit(record *domain.AuditRecord) *domain.LastJob {
+	return &domain.LastJob{
+		UserID:   record.UserID,
+		Protocol: record.Protocol,
+		Contract: record.Contract,
+		Serial:   record.Serial,
+		OltIP:    record.OltIP,
+		Slot:     record.Slot,
+		Port:     record.Port,
+	}
+}
+
+// isProtocol reports whether the term is a numeric protocol
+func (h *InlineHandler) isProtocol(term string) bool {
+	_, err := strconv.ParseInt(term, 10, 64)
+	return err == nil
+}
+
+// answerNotice answers the inline query with a single informative result
+func (h *InlineHandler) answerNotice(ctx context.Context, query *domain.InlineQueryEvent, title, text string) error {
+	return h.messenger.AnswerInlineQuery(ctx, query.QueryID, []domain.InlineResult{{
+		ID:          "notice",
+		Title:       title,
+		Description: text,
+		Text:        text,
+	}})
+}
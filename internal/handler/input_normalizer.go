@@ -0,0 +1,160 @@
+package handler
+
+import (
+	"fmt"
+	"maps"
+	"provisioning-assistant/internal/domain"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// Normalization is how much of the text typed by the user is cleaned before the steps read it
+type Normalization string
+
+const (
+	// NormalizeFull folds compatibility forms, smart quotes and dashes to ASCII and strips invisible
+	// characters and emojis, so validators and TL1 commands only see what the user meant to type
+	NormalizeFull Normalization = "full"
+
+	// NormalizeKeepEmoji cleans the text like NormalizeFull but keeps the emojis of free text answers
+	NormalizeKeepEmoji Normalization = "keep_emoji"
+
+	// NormalizeTrim only strips invisible characters and surrounding spaces, for secrets typed as they are
+	NormalizeTrim Normalization = "trim"
+
+	// NormalizeOff passes the text untouched
+	NormalizeOff Normalization = "off"
+)
+
+// DefaultNormalizationOverrides are the steps cleaned other than by NormalizeFull
+var DefaultNormalizationOverrides = map[domain.SessionState]Normalization{
+	domain.StateWaitingPPPoEPass:       NormalizeTrim,
+	domain.StateWaitingFeedbackComment: NormalizeKeepEmoji,
+	domain.StateWaitingPhotos:          NormalizeKeepEmoji,
+}
+
+// punctuationFolds maps the typographic punctuation inserted by phone keyboards to its ASCII form
+var punctuationFolds = strings.NewReplacer(
+	"‘", "'", "’", "'", "‚", "'", "‛", "'", "′", "'",
+	"“", "\"", "”", "\"", "„", "\"", "‟", "\"", "″", "\"",
+	"«", "\"", "»", "\"",
+	"‐", "-", "‑", "-", "‒", "-", "–", "-", "—", "-", "―", "-", "−", "-",
+)
+
+// IsValid reports whether the normalization is a known one
+func (n Normalization) IsValid() bool {
+	switch n {
+	case NormalizeFull, NormalizeKeepEmoji, NormalizeTrim, NormalizeOff:
+		return true
+	}
+	return false
+}
+
+// ParseNormalizationOverrides reads per-step normalizations written as "<estado>=<normalização>"
+func ParseNormalizationOverrides(values []string) (map[domain.SessionState]Normalization, error) {
+	overrides := make(map[domain.SessionState]Normalization, len(values))
+	for _, value := range values {
+		state, mode, found := strings.Cut(value, "=")
+		normalization := Normalization(strings.ToLower(strings.TrimSpace(mode)))
+		if !found || strings.TrimSpace(state) == "" || !normalization.IsValid() {
+			return nil, fmt.Errorf("normalização inválida: %s (use <estado>=full|keep_emoji|trim|off)", value)
+		}
+		overrides[domain.SessionState(strings.TrimSpace(state))] = normalization
+	}
+	return overrides, nil
+}
+
+// InputNormalizer cleans every message before the conversation steps read it, the way set for the step
+// the session is in
+type InputNormalizer struct {
+	overrides map[domain.SessionState]Normalization
+	logger    domain.Logger
+}
+
+// NewInputNormalizer creates a normalizer, the overrides given taking precedence over the defaults
+func NewInputNormalizer(overrides map[domain.SessionState]Normalization, logger domain.Logger) *InputNormalizer {
+	merged := maps.Clone(DefaultNormalizationOverrides)
+	maps.Copy(merged, overrides)
+
+	return &InputNormalizer{
+		overrides: merged,
+		logger:    logger,
+	}
+}
+
+// Normalize cleans the text and the photo caption of a message for the state of the session
+func (n *InputNormalizer) Normalize(state domain.SessionState, msg *domain.MessageEvent) {
+	normalization, exists := n.overrides[state]
+	if !exists {
+		normalization = NormalizeFull
+	}
+
+	message := NormalizeText(msg.Message, normalization)
+	if message != msg.Message {
+		n.logger.WithFields(map[string]any{
+			"user_id":       msg.UserID,
+			"state":         state,
+			"normalization": normalization,
+		}).Debug("Entrada do usuário normalizada")
+	}
+
+	msg.Message = message
+	msg.PhotoCaption = NormalizeText(msg.PhotoCaption, normalization)
+}
+
+// NormalizeText cleans a text the given way, keeping its line breaks for the pasted ERP records
+func NormalizeText(text string, normalization Normalization) string {
+	switch normalization {
+	case NormalizeOff:
+		return text
+	case NormalizeTrim:
+		return strings.TrimSpace(strings.Map(dropInvisible(false), text))
+	}
+
+	keepEmoji := normalization == NormalizeKeepEmoji
+
+	text = punctuationFolds.Replace(norm.NFKC.String(text))
+	text = strings.Map(func(r rune) rune {
+		if isEmoji(r) && !keepEmoji {
+			return -1
+		}
+		return dropInvisible(keepEmoji)(r)
+	}, text)
+
+	return strings.TrimSpace(text)
+}
+
+// dropInvisible strips the format characters and turns the unicode spaces into plain ones, the joiner of
+// composed emojis kept when they are
+func dropInvisible(keepJoiner bool) func(rune) rune {
+	return func(r rune) rune {
+		switch {
+		case r == '\n' || r == '\r' || r == '\t':
+			return r
+		case r == '\u200d' && keepJoiner:
+			return r
+		case unicode.Is(unicode.Cf, r):
+			return -1
+		case unicode.IsSpace(r):
+			return ' '
+		}
+		return r
+	}
+}
+
+// isEmoji reports whether the rune belongs to the emoji blocks, the modifiers and selectors composing them included
+func isEmoji(r rune) bool {
+	switch {
+	case r >= 0x1F000 && r <= 0x1FAFF, // pictographs, emoticons, flags and skin tones
+		r >= 0x2600 && r <= 0x27BF,   // miscellaneous symbols and dingbats
+		r >= 0x2B00 && r <= 0x2BFF,   // stars and arrows such as ⭐
+		r >= 0x2300 && r <= 0x23FF,   // technical symbols such as ⌚ and ⏰
+		r >= 0xFE00 && r <= 0xFE0F,   // variation selectors
+		r >= 0xE0020 && r <= 0xE007F, // tags of subdivision flags
+		r == 0x20E3:                  // keycap
+		return true
+	}
+	return false
+}
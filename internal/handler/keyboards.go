@@ -0,0 +1,427 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"provisioning-assistant/internal/domain"
+	"slices"
+	"strings"
+)
+
+// Keyboard layouts sent by the handlers
+const (
+	KeyboardMainMenu        = "main_menu"
+	KeyboardConfirm         = "confirm"
+	KeyboardOverride        = "override"
+	KeyboardOperation       = "operation"
+	KeyboardAlertAck        = "alert_ack"
+	KeyboardConsent         = "consent"
+	KeyboardPhoneShare      = "phone_share"
+	KeyboardCaptcha         = "captcha"
+	KeyboardPPPoESearch     = "pppoe_search"
+	KeyboardPhotos          = "photos"
+	KeyboardFeedbackRating  = "feedback_rating"
+	KeyboardFeedbackComment = "feedback_comment"
+	KeyboardTl1Endpoints    = "tl1_endpoints"
+	KeyboardRecheck         = "recheck"
+	KeyboardReopen          = "reopen"
+	KeyboardMaintenance     = "maintenance"
+	KeyboardDuplicate       = "duplicate"
+	KeyboardPreferences     = "preferences"
+	KeyboardOlts            = "olts"
+	KeyboardSpeedTest       = "speed_test"
+	KeyboardManualStep      = "manual_step"
+	KeyboardWorkOrder       = "work_order"
+	KeyboardOrphanOnu       = "orphan_onu"
+	KeyboardDeprovision     = "deprovision"
+	KeyboardRollback        = "rollback"
+	KeyboardQuickActions    = "quick_actions"
+
+	KeyboardTroubleshootStart     = "troubleshoot_start"
+	KeyboardTroubleshootConnector = "troubleshoot_connector"
+	KeyboardTroubleshootCto       = "troubleshoot_cto"
+	KeyboardTroubleshootMeter     = "troubleshoot_meter"
+)
+
+// Buttons available to the keyboard layouts
+const (
+	ButtonProvision        = "provision"
+	ButtonManual           = "manual"
+	ButtonSearch           = "search"
+	ButtonExit             = "exit"
+	ButtonBackToMenu       = "back_to_menu"
+	ButtonSearchAgain      = "search_again"
+	ButtonConfirmYes       = "confirm_yes"
+	ButtonConfirmNo        = "confirm_no"
+	ButtonConfirmEdit      = "confirm_edit"
+	ButtonOverrideApprove  = "override_approve"
+	ButtonOverrideReject   = "override_reject"
+	ButtonOperationApprove = "operation_approve"
+	ButtonOperationReject  = "operation_reject"
+	ButtonAlertAck         = "alert_ack"
+	ButtonConsentAccept    = "consent_accept"
+	ButtonConsentDecline   = "consent_decline"
+	ButtonPhoneShare       = "phone_share"
+	ButtonCaptchaOption    = "captcha_option"
+	ButtonPhotosDone       = "photos_done"
+	ButtonFeedbackRating   = "feedback_rating"
+	ButtonFeedbackSkip     = "feedback_skip"
+	ButtonFeedbackCancel   = "feedback_cancel"
+	ButtonTl1Endpoint      = "tl1_endpoint"
+	ButtonRecheckSignal    = "recheck_signal"
+	ButtonWifiScan         = "wifi_scan"
+	ButtonReopenSignal     = "reopen_signal"
+	ButtonReopenReconfig   = "reopen_reconfigure"
+	ButtonReopenSwap       = "reopen_swap"
+	ButtonReopenCancel     = "reopen_cancel"
+	ButtonMaintenanceAck   = "maintenance_ack"
+	ButtonMaintenanceStop  = "maintenance_cancel"
+	ButtonDuplicateReplace = "duplicate_replace"
+	ButtonDuplicateCancel  = "duplicate_cancel"
+	ButtonPrefsCompact     = "prefs_compact"
+	ButtonPrefsAlerts      = "prefs_alerts"
+	ButtonPrefsLanguage    = "prefs_language"
+	ButtonPrefsDone        = "prefs_done"
+	ButtonOltOption        = "olt_option"
+	ButtonSpeedTestSkip    = "speed_test_skip"
+	ButtonManualUndo       = "manual_undo"
+	ButtonWorkOrderStart   = "work_order_start"
+	ButtonOrphanRemove     = "orphan_remove"
+	ButtonOrphanIgnore     = "orphan_ignore"
+	ButtonDeprovisionRun   = "deprovision_run"
+	ButtonDeprovisionStop  = "deprovision_cancel"
+	ButtonRollbackRun      = "rollback_run"
+	ButtonRollbackStop     = "rollback_cancel"
+	ButtonQuickProvision   = "quick_provision"
+
+	ButtonTroubleshootStart           = "troubleshoot_start"
+	ButtonTroubleshootConnectorFixed  = "troubleshoot_connector_fixed"
+	ButtonTroubleshootConnectorFailed = "troubleshoot_connector_failed"
+	ButtonTroubleshootCtoFixed        = "troubleshoot_cto_fixed"
+	ButtonTroubleshootCtoFailed       = "troubleshoot_cto_failed"
+	ButtonTroubleshootMeterDrop       = "troubleshoot_meter_drop"
+	ButtonTroubleshootMeterCto        = "troubleshoot_meter_cto"
+	ButtonTroubleshootMeterNone       = "troubleshoot_meter_none"
+)
+
+// keyboardButton is the behaviour of a button, kept out of the catalog since the callbacks are routed on it.
+// A %s in the data takes the target of the keyboard, or the value of each choice.
+// A stacked choice puts each value on a row of its own, for labels too long to share one.
+// Link and web app buttons open the URL given to the keyboard and are left out without one.
+type keyboardButton struct {
+	data     string
+	kind     domain.ButtonKind
+	optional bool
+	choice   bool
+	stacked  bool
+}
+
+var keyboardButtons = map[string]keyboardButton{
+	ButtonProvision:        {data: "main_menu:provision"},
+	ButtonManual:           {data: "main_menu:manual", optional: true},
+	ButtonSearch:           {data: "main_menu:search"},
+	ButtonExit:             {data: "main_menu:exit"},
+	ButtonBackToMenu:       {data: "main_menu:menu"},
+	ButtonSearchAgain:      {data: "main_menu:search"},
+	ButtonConfirmYes:       {data: "confirm:yes"},
+	ButtonConfirmNo:        {data: "confirm:no"},
+	ButtonConfirmEdit:      {data: "confirm:edit"},
+	ButtonOverrideApprove:  {data: "override_approve:%s"},
+	ButtonOverrideReject:   {data: "override_reject:%s"},
+	ButtonOperationApprove: {data: "op_approve:%s"},
+	ButtonOperationReject:  {data: "op_reject:%s"},
+	ButtonAlertAck:         {data: "ack:%s"},
+	ButtonConsentAccept:    {data: "consent:accept"},
+	ButtonConsentDecline:   {data: "consent:decline"},
+	ButtonPhoneShare:       {kind: domain.ButtonRequestContact},
+	ButtonCaptchaOption:    {data: "captcha:%s", choice: true},
+	ButtonPhotosDone:       {data: "photos:done"},
+	ButtonFeedbackRating:   {data: "feedback:%s", choice: true},
+	ButtonFeedbackSkip:     {data: "feedback:skip"},
+	ButtonFeedbackCancel:   {data: "feedback:cancel"},
+	ButtonTl1Endpoint:      {data: "tl1_endpoint:%s", choice: true},
+	ButtonRecheckSignal:    {data: "recheck:signal", optional: true},
+	ButtonWifiScan:         {data: "recheck:wifi", optional: true},
+	ButtonReopenSignal:     {data: "reopen:signal"},
+	ButtonReopenReconfig:   {data: "reopen:reconfigure"},
+	ButtonReopenSwap:       {data: "reopen:swap"},
+	ButtonReopenCancel:     {data: "reopen:cancel"},
+	ButtonMaintenanceAck:   {data: "maintenance:ack"},
+	ButtonMaintenanceStop:  {data: "maintenance:cancel"},
+	ButtonDuplicateReplace: {data: "duplicate:replace"},
+	ButtonDuplicateCancel:  {data: "duplicate:cancel"},
+	ButtonPrefsCompact:     {data: "prefs:compact"},
+	ButtonPrefsAlerts:      {data: "prefs:alerts"},
+	ButtonPrefsLanguage:    {data: "prefs:language"},
+	ButtonPrefsDone:        {data: "prefs:done"},
+	ButtonOltOption:        {data: "olt:%s", choice: true, stacked: true},
+	ButtonSpeedTestSkip:    {data: "speedtest:skip"},
+	ButtonManualUndo:       {data: "manual:undo", optional: true},
+	ButtonWorkOrderStart:   {data: "work_order:%s"},
+	ButtonOrphanRemove:     {data: "orphan_remove:%s"},
+	ButtonOrphanIgnore:     {data: "orphan_ignore:%s"},
+	ButtonDeprovisionRun:   {data: "deprovision_run:%s"},
+	ButtonDeprovisionStop:  {data: "deprovision_cancel:%s"},
+	ButtonRollbackRun:      {data: "rollback_run:%s"},
+	ButtonRollbackStop:     {data: "rollback_cancel:%s"},
+	ButtonQuickProvision:   {data: "main_menu:provision", optional: true},
+
+	ButtonTroubleshootStart:           {data: "troubleshoot:start:%s"},
+	ButtonTroubleshootConnectorFixed:  {data: "troubleshoot:connector_fixed:%s"},
+	ButtonTroubleshootConnectorFailed: {data: "troubleshoot:connector_failed:%s"},
+	ButtonTroubleshootCtoFixed:        {data: "troubleshoot:cto_fixed:%s"},
+	ButtonTroubleshootCtoFailed:       {data: "troubleshoot:cto_failed:%s"},
+	ButtonTroubleshootMeterDrop:       {data: "troubleshoot:meter_drop:%s"},
+	ButtonTroubleshootMeterCto:        {data: "troubleshoot:meter_cto:%s"},
+	ButtonTroubleshootMeterNone:       {data: "troubleshoot:meter_none:%s"},
+}
+
+// KeyboardCatalog holds the wording and the arrangement of the keyboards. Each layout lists rows of
+// button identifiers, a choice button expands into one button per value of its row.
+type KeyboardCatalog struct {
+	Labels  map[string]string     `json:"labels,omitempty"`
+	Layouts map[string][][]string `json:"layouts,omitempty"`
+}
+
+// DefaultKeyboardCatalog returns the built-in pt-BR keyboards
+func DefaultKeyboardCatalog() *KeyboardCatalog {
+	return &KeyboardCatalog{
+		Labels: map[string]string{
+			ButtonProvision:        MSG_MENU_PROVISION,
+			ButtonManual:           MSG_MENU_MANUAL,
+			ButtonSearch:           MSG_MENU_SEARCH,
+			ButtonExit:             MSG_MENU_EXIT,
+			ButtonBackToMenu:       MSG_BACK_TO_MENU,
+			ButtonSearchAgain:      MSG_PPPOE_SEARCH_AGAIN,
+			ButtonConfirmYes:       MSG_CONFIRM_YES,
+			ButtonConfirmNo:        MSG_CONFIRM_NO,
+			ButtonConfirmEdit:      MSG_CONFIRM_EDIT,
+			ButtonOverrideApprove:  MSG_PROTOCOL_OVERRIDE_APPROVE,
+			ButtonOverrideReject:   MSG_PROTOCOL_OVERRIDE_REJECT,
+			ButtonOperationApprove: MSG_OPERATION_APPROVE,
+			ButtonOperationReject:  MSG_OPERATION_REJECT,
+			ButtonAlertAck:         MSG_ALERT_ACK_BUTTON,
+			ButtonConsentAccept:    MSG_CONSENT_ACCEPT,
+			ButtonConsentDecline:   MSG_CONSENT_DECLINE,
+			ButtonPhoneShare:       MSG_PHONE_SHARE,
+			ButtonCaptchaOption:    "%s",
+			ButtonPhotosDone:       MSG_PHOTOS_DONE,
+			ButtonFeedbackRating:   "%s⭐",
+			ButtonFeedbackSkip:     MSG_FEEDBACK_SKIP,
+			ButtonFeedbackCancel:   MSG_FEEDBACK_CANCEL,
+			ButtonTl1Endpoint:      "%s",
+			ButtonRecheckSignal:    MSG_RECHECK_SIGNAL,
+			ButtonWifiScan:         MSG_WIFI_SCAN,
+			ButtonReopenSignal:     MSG_REOPEN_SIGNAL,
+			ButtonReopenReconfig:   MSG_REOPEN_RECONFIGURE,
+			ButtonReopenSwap:       MSG_REOPEN_SWAP,
+			ButtonReopenCancel:     MSG_REOPEN_CANCEL,
+			ButtonMaintenanceAck:   MSG_MAINTENANCE_ACK,
+			ButtonMaintenanceStop:  MSG_MAINTENANCE_CANCEL,
+			ButtonDuplicateReplace: MSG_DUPLICATE_REPLACE,
+			ButtonDuplicateCancel:  MSG_DUPLICATE_CANCEL,
+			ButtonPrefsCompact:     MSG_PREFS_TOGGLE_COMPACT,
+			ButtonPrefsAlerts:      MSG_PREFS_TOGGLE_ALERTS,
+			ButtonPrefsLanguage:    MSG_PREFS_TOGGLE_LANGUAGE,
+			ButtonPrefsDone:        MSG_PREFS_DONE,
+			ButtonOltOption:        MSG_MANUAL_OLT_OPTION,
+			ButtonSpeedTestSkip:    MSG_SPEED_TEST_SKIP,
+			ButtonManualUndo:       MSG_MANUAL_UNDO,
+			ButtonWorkOrderStart:   MSG_WORK_ORDER_START,
+			ButtonOrphanRemove:     MSG_ORPHAN_REMOVE,
+			ButtonOrphanIgnore:     MSG_ORPHAN_IGNORE,
+			ButtonDeprovisionRun:   MSG_DEPROVISION_RUN,
+			ButtonDeprovisionStop:  MSG_DEPROVISION_CANCEL,
+			ButtonRollbackRun:      MSG_ROLLBACK_RUN,
+			ButtonRollbackStop:     MSG_ROLLBACK_CANCEL,
+			ButtonQuickProvision:   MSG_MENU_PROVISION,
+
+			ButtonTroubleshootStart:           MSG_TROUBLESHOOT_START,
+			ButtonTroubleshootConnectorFixed:  MSG_TROUBLESHOOT_SOLVED,
+			ButtonTroubleshootConnectorFailed: MSG_TROUBLESHOOT_NOT_SOLVED,
+			ButtonTroubleshootCtoFixed:        MSG_TROUBLESHOOT_SOLVED,
+			ButtonTroubleshootCtoFailed:       MSG_TROUBLESHOOT_NOT_SOLVED,
+			ButtonTroubleshootMeterDrop:       MSG_TROUBLESHOOT_METER_DROP,
+			ButtonTroubleshootMeterCto:        MSG_TROUBLESHOOT_METER_CTO,
+			ButtonTroubleshootMeterNone:       MSG_TROUBLESHOOT_METER_NONE,
+		},
+		Layouts: map[string][][]string{
+			KeyboardMainMenu:        {{ButtonProvision}, {ButtonManual}, {ButtonSearch}, {ButtonRecheckSignal}, {ButtonExit}},
+			KeyboardConfirm:         {{ButtonConfirmYes, ButtonConfirmNo}, {ButtonConfirmEdit}},
+			KeyboardOverride:        {{ButtonOverrideApprove, ButtonOverrideReject}},
+			KeyboardOperation:       {{ButtonOperationApprove, ButtonOperationReject}},
+			KeyboardAlertAck:        {{ButtonAlertAck}},
+			KeyboardConsent:         {{ButtonConsentAccept, ButtonConsentDecline}},
+			KeyboardPhoneShare:      {{ButtonPhoneShare}},
+			KeyboardCaptcha:         {{ButtonCaptchaOption}},
+			KeyboardPPPoESearch:     {{ButtonSearchAgain}, {ButtonBackToMenu}},
+			KeyboardPhotos:          {{ButtonPhotosDone}},
+			KeyboardFeedbackRating:  {{ButtonFeedbackRating}, {ButtonFeedbackCancel}},
+			KeyboardFeedbackComment: {{ButtonFeedbackSkip}, {ButtonFeedbackCancel}},
+			KeyboardTl1Endpoints:    {{ButtonTl1Endpoint}},
+			KeyboardRecheck:         {{ButtonRecheckSignal}, {ButtonWifiScan}},
+			KeyboardReopen:          {{ButtonReopenSignal}, {ButtonReopenReconfig}, {ButtonReopenSwap}, {ButtonReopenCancel}},
+			KeyboardMaintenance:     {{ButtonMaintenanceAck, ButtonMaintenanceStop}},
+			KeyboardDuplicate:       {{ButtonDuplicateReplace}, {ButtonDuplicateCancel}},
+			KeyboardPreferences:     {{ButtonPrefsCompact}, {ButtonPrefsAlerts}, {ButtonPrefsLanguage}, {ButtonPrefsDone}},
+			KeyboardOlts:            {{ButtonOltOption}, {ButtonManualUndo}},
+			KeyboardSpeedTest:       {{ButtonSpeedTestSkip}},
+			KeyboardManualStep:      {{ButtonManualUndo}},
+			KeyboardWorkOrder:       {{ButtonWorkOrderStart}},
+			KeyboardOrphanOnu:       {{ButtonOrphanRemove, ButtonOrphanIgnore}},
+			KeyboardDeprovision:     {{ButtonDeprovisionRun, ButtonDeprovisionStop}},
+			KeyboardRollback:        {{ButtonRollbackRun, ButtonRollbackStop}},
+			KeyboardQuickActions:    {{ButtonQuickProvision}, {ButtonManual}, {ButtonRecheckSignal}, {ButtonBackToMenu}},
+
+			KeyboardTroubleshootStart:     {{ButtonTroubleshootStart}},
+			KeyboardTroubleshootConnector: {{ButtonTroubleshootConnectorFixed, ButtonTroubleshootConnectorFailed}},
+			KeyboardTroubleshootCto:       {{ButtonTroubleshootCtoFixed, ButtonTroubleshootCtoFailed}},
+			KeyboardTroubleshootMeter:     {{ButtonTroubleshootMeterDrop}, {ButtonTroubleshootMeterCto}, {ButtonTroubleshootMeterNone}},
+		},
+	}
+}
+
+// LoadKeyboardCatalog reads the labels and layouts of a deployment from a JSON file,
+// entries left out of the file keep the built-in ones
+func LoadKeyboardCatalog(path string) (*KeyboardCatalog, error) {
+	catalog := DefaultKeyboardCatalog()
+	if path == "" {
+		return catalog, nil
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("falha ao ler arquivo de teclados: %w", err)
+	}
+
+	var overrides KeyboardCatalog
+	if err := json.Unmarshal(content, &overrides); err != nil {
+		return nil, fmt.Errorf("falha ao interpretar arquivo de teclados: %w", err)
+	}
+
+	maps.Copy(catalog.Labels, overrides.Labels)
+	maps.Copy(catalog.Layouts, overrides.Layouts)
+
+	if err := catalog.Validate(); err != nil {
+		return nil, err
+	}
+
+	return catalog, nil
+}
+
+// Validate rejects unknown identifiers and layouts that drop buttons, so a flow can't lose its way forward
+func (c *KeyboardCatalog) Validate() error {
+	for id, label := range c.Labels {
+		button, exists := keyboardButtons[id]
+		if !exists {
+			return fmt.Errorf("botão desconhecido no catálogo de teclados: %s", id)
+		}
+		if button.choice && (strings.Count(label, "%s") != 1 || strings.Count(label, "%") != 1) {
+			return fmt.Errorf("rótulo inválido para o botão %s: %q", id, label)
+		}
+	}
+
+	defaults := DefaultKeyboardCatalog()
+	for id, rows := range c.Layouts {
+		builtin, exists := defaults.Layouts[id]
+		if !exists {
+			return fmt.Errorf("teclado desconhecido no catálogo de teclados: %s", id)
+		}
+
+		buttons := slices.Sorted(slices.Values(slices.Concat(rows...)))
+		if !slices.Equal(buttons, slices.Sorted(slices.Values(slices.Concat(builtin...)))) {
+			return fmt.Errorf("o teclado %s deve conter os botões %s", id, strings.Join(slices.Concat(builtin...), ", "))
+		}
+	}
+
+	return nil
+}
+
+// KeyboardOption adjusts a keyboard built from the catalog
+type KeyboardOption func(*keyboardParams)
+
+type keyboardParams struct {
+	target  string
+	url     string
+	choices []string
+	shown   map[string]bool
+}
+
+// WithTarget fills the buttons acting on a record, such as the approval of an operation
+func WithTarget(target string) KeyboardOption {
+	return func(p *keyboardParams) {
+		p.target = target
+	}
+}
+
+// WithURL sets the address opened by the link and web app buttons
+func WithURL(url string) KeyboardOption {
+	return func(p *keyboardParams) {
+		p.url = url
+	}
+}
+
+// WithChoices sets the values offered by the choice buttons
+func WithChoices(choices ...string) KeyboardOption {
+	return func(p *keyboardParams) {
+		p.choices = choices
+	}
+}
+
+// WithButtons shows optional buttons of the layout
+func WithButtons(ids ...string) KeyboardOption {
+	return func(p *keyboardParams) {
+		for _, id := range ids {
+			p.shown[id] = true
+		}
+	}
+}
+
+// Build renders a layout of the catalog, rows left without buttons are dropped
+func (c *KeyboardCatalog) Build(layout string, options ...KeyboardOption) *domain.Keyboard {
+	params := &keyboardParams{shown: make(map[string]bool)}
+	for _, option := range options {
+		option(params)
+	}
+
+	keyboard := &domain.Keyboard{Inline: true}
+	for _, ids := range c.Layouts[layout] {
+		var row []domain.Button
+		for _, id := range ids {
+			button := keyboardButtons[id]
+			label := c.Labels[id]
+
+			switch {
+			case button.optional && !params.shown[id]:
+			case button.stacked:
+				for _, choice := range params.choices {
+					keyboard.Buttons = append(keyboard.Buttons, []domain.Button{{Text: fmt.Sprintf(label, choice), Data: fmt.Sprintf(button.data, choice)}})
+				}
+			case button.choice:
+				for _, choice := range params.choices {
+					row = append(row, domain.Button{Text: fmt.Sprintf(label, choice), Data: fmt.Sprintf(button.data, choice)})
+				}
+			case button.kind.RepliesOnly():
+				// Contact and location requests only work on reply keyboards
+				keyboard.Inline = false
+				row = append(row, domain.Button{Text: label, Kind: button.kind})
+			case button.kind == domain.ButtonURL || button.kind == domain.ButtonWebApp:
+				if params.url != "" {
+					row = append(row, domain.Button{Text: label, Kind: button.kind, URL: params.url})
+				}
+			case strings.Contains(button.data, "%s"):
+				row = append(row, domain.Button{Text: label, Data: fmt.Sprintf(button.data, params.target)})
+			default:
+				row = append(row, domain.Button{Text: label, Data: button.data})
+			}
+		}
+
+		if len(row) > 0 {
+			keyboard.Buttons = append(keyboard.Buttons, row)
+		}
+	}
+
+	return keyboard
+}
@@ -0,0 +1,132 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"provisioning-assistant/internal/clock"
+	"provisioning-assistant/internal/domain"
+	"provisioning-assistant/internal/locale"
+	"provisioning-assistant/internal/services"
+	"strings"
+	"time"
+)
+
+type MaintenanceHandler struct {
+	maintenanceService *services.MaintenanceService
+	clock              clock.Clock
+	formatter          *locale.Formatter
+	messenger          *Messenger
+}
+
+// NewMaintenanceHandler creates a new OLT maintenance window command handler
+func NewMaintenanceHandler(
+	maintenanceService *services.MaintenanceService,
+	clock clock.Clock,
+	formatter *locale.Formatter,
+	messenger *Messenger,
+) *MaintenanceHandler {
+	return &MaintenanceHandler{
+		maintenanceService: maintenanceService,
+		clock:              clock,
+		formatter:          formatter,
+		messenger:          messenger,
+	}
+}
+
+// RegisterCommands registers the maintenance window commands
+func (h *MaintenanceHandler) RegisterCommands(commands *CommandHandler) {
+	commands.Register("/manutencao", domain.RoleSupervisor, h.handleMaintenanceCommand)
+}
+
+// handleMaintenanceCommand lists the windows, schedules one for an OLT or ends it early
+func (h *MaintenanceHandler) handleMaintenanceCommand(ctx context.Context, session *domain.Session, args []string) error {
+	switch {
+	case len(args) == 0:
+		return h.list(ctx, session)
+	case len(args) == 2 && strings.EqualFold(args[0], "encerrar"):
+		return h.finish(ctx, session, args[1])
+	case len(args) >= 3:
+		return h.schedule(ctx, session, args[0], args[1], args[2], strings.Join(args[3:], " "))
+	default:
+		return h.messenger.SendMessage(ctx, session.ChatID, MSG_MAINTENANCE_USAGE)
+	}
+}
+
+// list sends the windows in progress or still to come
+func (h *MaintenanceHandler) list(ctx context.Context, session *domain.Session) error {
+	windows := h.maintenanceService.Windows(ctx)
+	if len(windows) == 0 {
+		return h.messenger.SendMessage(ctx, session.ChatID, MSG_MAINTENANCE_LIST_EMPTY)
+	}
+
+	now := h.clock.Now()
+
+	var builder strings.Builder
+	builder.WriteString(MSG_MAINTENANCE_LIST_HEADER)
+	for _, window := range windows {
+		active := ""
+		if window.Covers(now) {
+			active = MSG_MAINTENANCE_LIST_ACTIVE
+		}
+		builder.WriteString(fmt.Sprintf(MSG_MAINTENANCE_LIST_ITEM, window.Olt, h.formatter.DateTime(window.Start), h.formatter.Time(window.End), active))
+	}
+
+	return h.messenger.SendMessage(ctx, session.ChatID, builder.String())
+}
+
+// schedule stores a window starting now or at the next occurrence of a time of day
+func (h *MaintenanceHandler) schedule(ctx context.Context, session *domain.Session, olt, start, duration, reason string) error {
+	startAt, ok := h.parseStart(start)
+	if !ok {
+		return h.messenger.SendMessage(ctx, session.ChatID, MSG_MAINTENANCE_INVALID_START)
+	}
+
+	length, err := time.ParseDuration(duration)
+	if err != nil || length <= 0 {
+		return h.messenger.SendMessage(ctx, session.ChatID, MSG_MAINTENANCE_INVALID_DURATION)
+	}
+
+	window := domain.MaintenanceWindow{
+		Olt:       olt,
+		Start:     startAt,
+		End:       startAt.Add(length),
+		Reason:    reason,
+		CreatedBy: session.UserName,
+	}
+
+	if err := h.maintenanceService.Schedule(ctx, window); err != nil {
+		return h.messenger.SendMessage(ctx, session.ChatID, fmt.Sprintf(MSG_MAINTENANCE_FAILED, err))
+	}
+
+	message := fmt.Sprintf(MSG_MAINTENANCE_SCHEDULED, olt, h.formatter.DateTime(window.Start), h.formatter.DateTime(window.End))
+	return h.messenger.SendMessage(ctx, session.ChatID, message)
+}
+
+// finish ends the window of an OLT
+func (h *MaintenanceHandler) finish(ctx context.Context, session *domain.Session, olt string) error {
+	if err := h.maintenanceService.Finish(ctx, olt); err != nil {
+		return h.messenger.SendMessage(ctx, session.ChatID, fmt.Sprintf(MSG_MAINTENANCE_FAILED, err))
+	}
+
+	return h.messenger.SendMessage(ctx, session.ChatID, fmt.Sprintf(MSG_MAINTENANCE_FINISHED, olt))
+}
+
+// parseStart reads "agora" or a HH:MM time in the configured timezone, a time already past today is taken as tomorrow
+func (h *MaintenanceHandler) parseStart(value string) (time.Time, bool) {
+	now := h.clock.Now()
+	if strings.EqualFold(value, "agora") {
+		return now, true
+	}
+
+	clockTime, err := time.Parse(locale.TimeLayout, value)
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	local := now.In(h.formatter.Location())
+	start := time.Date(local.Year(), local.Month(), local.Day(), clockTime.Hour(), clockTime.Minute(), 0, 0, local.Location())
+	if start.Before(now) {
+		start = start.AddDate(0, 0, 1)
+	}
+	return start, true
+}
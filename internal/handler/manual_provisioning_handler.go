@@ -0,0 +1,415 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"provisioning-assistant/internal/domain"
+	"provisioning-assistant/internal/domain/dto"
+	"provisioning-assistant/internal/paste"
+	"provisioning-assistant/internal/pon"
+	"provisioning-assistant/internal/services"
+	"provisioning-assistant/internal/validation"
+	"strconv"
+	"strings"
+)
+
+type ManualProvisioningHandler struct {
+	sessionService *services.SessionService
+	oltSuggestions *services.OltSuggestionService
+	attemptGuard   *AttemptGuard
+	confirmer      *Confirmer
+	keyboards      *KeyboardCatalog
+	messenger      *Messenger
+	logger         domain.Logger
+}
+
+// NewManualProvisioningHandler creates a new manual provisioning wizard handler instance
+func NewManualProvisioningHandler(
+	sessionService *services.SessionService,
+	oltSuggestions *services.OltSuggestionService,
+	attemptGuard *AttemptGuard,
+	confirmer *Confirmer,
+	keyboards *KeyboardCatalog,
+	messenger *Messenger,
+	logger domain.Logger,
+) *ManualProvisioningHandler {
+	return &ManualProvisioningHandler{
+		sessionService: sessionService,
+		oltSuggestions: oltSuggestions,
+		attemptGuard:   attemptGuard,
+		confirmer:      confirmer,
+		keyboards:      keyboards,
+		messenger:      messenger,
+		logger:         logger,
+	}
+}
+
+// IsAllowed checks if the session role may use the manual wizard
+func (h *ManualProvisioningHandler) IsAllowed(session *domain.Session) bool {
+	return session.UserRole.Includes(domain.RoleSupervisor)
+}
+
+// Start begins the manual provisioning wizard without ERP data
+func (h *ManualProvisioningHandler) Start(ctx context.Context, session *domain.Session) error {
+	updateSession(h.sessionService, session, func(s *domain.Session) {
+		s.Manual = true
+		s.Protocol = ""
+		s.ConnectionInfo = &dto.ConnectionInfo{}
+		s.ErpFetchTime = 0
+		s.ReopenedFrom = ""
+		s.ReplacedSerial = ""
+		s.AnsweredSteps = nil
+		s.State = domain.StateWaitingSerial
+	})
+
+	h.logger.WithField("chat_id", session.ChatID).Info("Provisionamento manual iniciado")
+
+	return h.messenger.SendMessage(ctx, session.ChatID, MSG_MANUAL_REQUEST_SERIAL)
+}
+
+// Prefill starts the wizard with the values recognized in a pasted text, asking only for the
+// missing ones or going straight to the confirmation when nothing is missing
+func (h *ManualProvisioningHandler) Prefill(ctx context.Context, session *domain.Session, fields paste.Fields) error {
+	updateSession(h.sessionService, session, func(s *domain.Session) {
+		if !s.Manual || s.ConnectionInfo == nil {
+			s.Protocol = ""
+			s.ConnectionInfo = &dto.ConnectionInfo{}
+			s.ErpFetchTime = 0
+			s.ReopenedFrom = ""
+			s.ReplacedSerial = ""
+		}
+		s.Manual = true
+		s.AnsweredSteps = nil
+
+		paste.Merge(s.ConnectionInfo, &fields.Info)
+		if s.ConnectionInfo.ClientName == "" {
+			s.ConnectionInfo.ClientName = s.ConnectionInfo.ConnectionClientPPPoEUsername
+		}
+		s.OLT = s.ConnectionInfo.ConnectionOltIP
+		s.Slot = s.ConnectionInfo.ConnectionOltSlot
+		s.Port = s.ConnectionInfo.ConnectionOltPort
+	})
+
+	h.logger.WithFields(map[string]any{
+		"chat_id": session.ChatID,
+		"fields":  fields.Count(),
+	}).Info("Provisionamento manual preenchido a partir de texto colado")
+
+	if err := h.messenger.SendMessage(ctx, session.ChatID, formatPastedFields(fields)); err != nil {
+		return err
+	}
+
+	next, prompt := h.nextStep(session.ConnectionInfo)
+	if next == domain.StateConfirmData {
+		return h.sendConfirmationRequest(ctx, session)
+	}
+
+	updateSession(h.sessionService, session, func(s *domain.Session) {
+		s.State = next
+	})
+	return h.prompt(ctx, session, next, prompt)
+}
+
+// nextStep returns the first wizard step still unanswered and its prompt
+func (h *ManualProvisioningHandler) nextStep(connInfo *dto.ConnectionInfo) (domain.SessionState, string) {
+	switch {
+	case connInfo.ConnectionEquipmentSerialNumber == "":
+		return domain.StateWaitingSerial, MSG_MANUAL_REQUEST_SERIAL
+	case connInfo.ConnectionOltIP == "":
+		return domain.StateWaitingOLT, MSG_MANUAL_REQUEST_OLT
+	case connInfo.ConnectionOltSlot == "":
+		return domain.StateWaitingSlot, MSG_MANUAL_REQUEST_SLOT
+	case connInfo.ConnectionOltPort == "":
+		return domain.StateWaitingPort, MSG_MANUAL_REQUEST_PORT
+	case connInfo.ConnectionClientVlan == "":
+		return domain.StateWaitingVlan, MSG_MANUAL_REQUEST_VLAN
+	case connInfo.ConnectionClientPPPoEUsername == "":
+		return domain.StateWaitingPPPoEUser, MSG_MANUAL_REQUEST_PPPOE
+	case connInfo.ConnectionClientPPPoEPassword == "":
+		return domain.StateWaitingPPPoEPass, MSG_MANUAL_REQUEST_PASSWORD
+	default:
+		return domain.StateConfirmData, ""
+	}
+}
+
+// HandleInput processes the answer for the current wizard step
+func (h *ManualProvisioningHandler) HandleInput(ctx context.Context, session *domain.Session, msg *domain.MessageEvent) error {
+	if session.ConnectionInfo == nil {
+		return h.Start(ctx, session)
+	}
+
+	input := strings.TrimSpace(msg.Message)
+
+	// A pasted ERP screen answers several steps at once
+	if fields := paste.Parse(msg.Message); fields.Count() >= paste.MinFields {
+		return h.Prefill(ctx, session, fields)
+	}
+
+	switch session.State {
+	case domain.StateWaitingSerial:
+		if validation.Serial(input) != nil {
+			return h.attemptGuard.Reject(ctx, session, MSG_MANUAL_SERIAL_INVALID)
+		}
+		return h.advance(ctx, session, domain.StateWaitingOLT, MSG_MANUAL_REQUEST_OLT, func(s *domain.Session) {
+			s.ConnectionInfo.ConnectionEquipmentSerialNumber = strings.ToUpper(input)
+		})
+
+	case domain.StateWaitingOLT:
+		return h.selectOlt(ctx, session, input)
+
+	case domain.StateWaitingSlot:
+		// "1/4" or "S1/P4" answers both questions at once
+		if location, err := pon.ParseSlotPort(input, ""); err == nil {
+			return h.advance(ctx, session, domain.StateWaitingVlan, MSG_MANUAL_REQUEST_VLAN, func(s *domain.Session) {
+				s.ConnectionInfo.ConnectionOltSlot = strconv.FormatUint(uint64(location.Slot), 10)
+				s.ConnectionInfo.ConnectionOltPort = strconv.FormatUint(uint64(location.Port), 10)
+				s.Slot = s.ConnectionInfo.ConnectionOltSlot
+				s.Port = s.ConnectionInfo.ConnectionOltPort
+			})
+		}
+
+		slot, err := pon.ParseSlot(input)
+		if err != nil {
+			return h.attemptGuard.Reject(ctx, session, fmt.Sprintf(MSG_MANUAL_SLOT_INVALID, pon.MaxSlot))
+		}
+		return h.advance(ctx, session, domain.StateWaitingPort, MSG_MANUAL_REQUEST_PORT, func(s *domain.Session) {
+			s.ConnectionInfo.ConnectionOltSlot = strconv.FormatUint(uint64(slot), 10)
+			s.Slot = s.ConnectionInfo.ConnectionOltSlot
+		})
+
+	case domain.StateWaitingPort:
+		port, err := pon.ParsePort(input)
+		if err != nil {
+			return h.attemptGuard.Reject(ctx, session, fmt.Sprintf(MSG_MANUAL_PORT_INVALID, pon.MaxPort))
+		}
+		return h.advance(ctx, session, domain.StateWaitingVlan, MSG_MANUAL_REQUEST_VLAN, func(s *domain.Session) {
+			s.ConnectionInfo.ConnectionOltPort = strconv.FormatUint(uint64(port), 10)
+			s.Port = s.ConnectionInfo.ConnectionOltPort
+		})
+
+	case domain.StateWaitingVlan:
+		if validation.Vlan(input) != nil {
+			return h.attemptGuard.Reject(ctx, session, MSG_MANUAL_VLAN_INVALID)
+		}
+		return h.advance(ctx, session, domain.StateWaitingPPPoEUser, MSG_MANUAL_REQUEST_PPPOE, func(s *domain.Session) {
+			s.ConnectionInfo.ConnectionClientVlan = input
+		})
+
+	case domain.StateWaitingPPPoEUser:
+		if validation.PPPoEUser(input) != nil {
+			return h.attemptGuard.Reject(ctx, session, MSG_MANUAL_PPPOE_INVALID)
+		}
+		return h.advance(ctx, session, domain.StateWaitingPPPoEPass, MSG_MANUAL_REQUEST_PASSWORD, func(s *domain.Session) {
+			s.ConnectionInfo.ConnectionClientPPPoEUsername = input
+			s.ConnectionInfo.ClientName = input
+		})
+
+	case domain.StateWaitingPPPoEPass:
+		if validation.PPPoEPassword(input) != nil {
+			return h.attemptGuard.Reject(ctx, session, MSG_MANUAL_PASSWORD_INVALID)
+		}
+		updateSession(h.sessionService, session, func(s *domain.Session) {
+			s.ConnectionInfo.ConnectionClientPPPoEPassword = input
+		})
+		return h.sendConfirmationRequest(ctx, session)
+	}
+
+	return nil
+}
+
+// HandleOltOption answers the OLT step with an OLT picked on the suggestions keyboard
+func (h *ManualProvisioningHandler) HandleOltOption(ctx context.Context, session *domain.Session, olt string) error {
+	if session.State != domain.StateWaitingOLT || session.ConnectionInfo == nil {
+		return nil
+	}
+	return h.selectOlt(ctx, session, olt)
+}
+
+// HandleStepOption processes the keyboard of a wizard step, "undo" clears the last answered field and
+// asks for it again, keeping the answers given before it
+func (h *ManualProvisioningHandler) HandleStepOption(ctx context.Context, session *domain.Session, option string) error {
+	if option != "undo" || !isManualStep(session.State) || session.ConnectionInfo == nil || len(session.AnsweredSteps) == 0 {
+		return nil
+	}
+
+	step := session.AnsweredSteps[len(session.AnsweredSteps)-1]
+	label := manualStepLabel(step)
+	updateSession(h.sessionService, session, func(s *domain.Session) {
+		s.AnsweredSteps = s.AnsweredSteps[:len(s.AnsweredSteps)-1]
+		clearManualStep(s, step)
+		s.State = step
+	})
+
+	if err := h.messenger.SendMessage(ctx, session.ChatID, fmt.Sprintf(MSG_MANUAL_UNDONE, label)); err != nil {
+		return err
+	}
+	return h.prompt(ctx, session, step, manualStepPrompt(step))
+}
+
+// selectOlt stores the OLT of the wizard and asks for the slot
+func (h *ManualProvisioningHandler) selectOlt(ctx context.Context, session *domain.Session, olt string) error {
+	if validation.OltIP(olt) != nil {
+		return h.attemptGuard.Reject(ctx, session, MSG_MANUAL_OLT_INVALID)
+	}
+	return h.advance(ctx, session, domain.StateWaitingSlot, MSG_MANUAL_REQUEST_SLOT, func(s *domain.Session) {
+		s.ConnectionInfo.ConnectionOltIP = olt
+		s.OLT = olt
+	})
+}
+
+// advance stores the step answer and prompts for the next wizard step
+func (h *ManualProvisioningHandler) advance(
+	ctx context.Context,
+	session *domain.Session,
+	next domain.SessionState,
+	prompt string,
+	apply func(s *domain.Session),
+) error {
+	updateSession(h.sessionService, session, func(s *domain.Session) {
+		apply(s)
+		s.AnsweredSteps = append(s.AnsweredSteps, s.State)
+		s.State = next
+	})
+
+	return h.prompt(ctx, session, next, prompt)
+}
+
+// prompt asks for a wizard step, offering to correct the last answer when there is one
+func (h *ManualProvisioningHandler) prompt(ctx context.Context, session *domain.Session, step domain.SessionState, prompt string) error {
+	var options []KeyboardOption
+	if len(session.AnsweredSteps) > 0 {
+		options = append(options, WithButtons(ButtonManualUndo))
+	}
+
+	// The OLTs of the technician's recent jobs and region spare typing an IP from a large inventory
+	if step == domain.StateWaitingOLT {
+		if olts := h.oltSuggestions.Suggest(ctx, session.UserID); len(olts) > 0 {
+			options = append(options, WithChoices(olts...))
+			return h.messenger.SendMessageWithKeyboard(ctx, session.ChatID, MSG_MANUAL_OLT_SUGGESTED, h.keyboards.Build(KeyboardOlts, options...))
+		}
+	}
+
+	if len(options) == 0 {
+		return h.messenger.SendMessage(ctx, session.ChatID, prompt)
+	}
+	return h.messenger.SendMessageWithKeyboard(ctx, session.ChatID, prompt, h.keyboards.Build(KeyboardManualStep, options...))
+}
+
+// sendConfirmationRequest sends the collected manual data for confirmation
+func (h *ManualProvisioningHandler) sendConfirmationRequest(ctx context.Context, session *domain.Session) error {
+	connInfo := session.ConnectionInfo
+	return h.confirmer.Ask(ctx, session, Confirmation{
+		Title: MSG_MANUAL_CONFIRM_TITLE,
+		Fields: []ConfirmationField{
+			{Label: MSG_CONFIRM_SERIAL, Value: connInfo.ConnectionEquipmentSerialNumber},
+			{Label: MSG_CONFIRM_OLT, Value: connInfo.ConnectionOltIP},
+			{Label: MSG_CONFIRM_SLOT, Value: connInfo.ConnectionOltSlot},
+			{Label: MSG_CONFIRM_PON_PORT, Value: connInfo.ConnectionOltPort},
+			{Label: MSG_CONFIRM_VLAN, Value: connInfo.ConnectionClientVlan},
+			{Label: MSG_CONFIRM_PPPOE_USER, Value: connInfo.ConnectionClientPPPoEUsername},
+		},
+		Question: MSG_MANUAL_CONFIRM_QUESTION,
+	})
+}
+
+// isManualStep reports whether the state collects a field of the manual wizard
+func isManualStep(state domain.SessionState) bool {
+	return manualStepPrompt(state) != ""
+}
+
+// manualStepPrompt returns the question of a wizard step
+func manualStepPrompt(step domain.SessionState) string {
+	switch step {
+	case domain.StateWaitingSerial:
+		return MSG_MANUAL_REQUEST_SERIAL
+	case domain.StateWaitingOLT:
+		return MSG_MANUAL_REQUEST_OLT
+	case domain.StateWaitingSlot:
+		return MSG_MANUAL_REQUEST_SLOT
+	case domain.StateWaitingPort:
+		return MSG_MANUAL_REQUEST_PORT
+	case domain.StateWaitingVlan:
+		return MSG_MANUAL_REQUEST_VLAN
+	case domain.StateWaitingPPPoEUser:
+		return MSG_MANUAL_REQUEST_PPPOE
+	case domain.StateWaitingPPPoEPass:
+		return MSG_MANUAL_REQUEST_PASSWORD
+	default:
+		return ""
+	}
+}
+
+// manualStepLabel names the field answered on a wizard step
+func manualStepLabel(step domain.SessionState) string {
+	switch step {
+	case domain.StateWaitingSerial:
+		return MSG_MANUAL_FIELD_SERIAL
+	case domain.StateWaitingOLT:
+		return MSG_MANUAL_FIELD_OLT
+	case domain.StateWaitingSlot:
+		return MSG_MANUAL_FIELD_SLOT
+	case domain.StateWaitingPort:
+		return MSG_MANUAL_FIELD_PORT
+	case domain.StateWaitingVlan:
+		return MSG_MANUAL_FIELD_VLAN
+	default:
+		return MSG_MANUAL_FIELD_PPPOE_USER
+	}
+}
+
+// clearManualStep forgets the answer of a wizard step, a slot answered as "slot/porta" takes the port with it
+func clearManualStep(s *domain.Session, step domain.SessionState) {
+	connInfo := s.ConnectionInfo
+	switch step {
+	case domain.StateWaitingSerial:
+		connInfo.ConnectionEquipmentSerialNumber = ""
+	case domain.StateWaitingOLT:
+		connInfo.ConnectionOltIP = ""
+		s.OLT = ""
+	case domain.StateWaitingSlot:
+		connInfo.ConnectionOltSlot = ""
+		connInfo.ConnectionOltPort = ""
+		s.Slot = ""
+		s.Port = ""
+	case domain.StateWaitingPort:
+		connInfo.ConnectionOltPort = ""
+		s.Port = ""
+	case domain.StateWaitingVlan:
+		connInfo.ConnectionClientVlan = ""
+	case domain.StateWaitingPPPoEUser:
+		connInfo.ConnectionClientPPPoEUsername = ""
+		connInfo.ClientName = ""
+	}
+}
+
+// formatPastedFields lists the values recognized in a pasted text, hiding the PPPoE password
+func formatPastedFields(fields paste.Fields) string {
+	info := fields.Info
+
+	var builder strings.Builder
+	builder.WriteString(MSG_PASTE_RECOGNIZED)
+
+	for _, line := range []struct {
+		field  paste.Field
+		format string
+		value  string
+	}{
+		{paste.FieldProtocol, MSG_PASTE_PROTOCOL, fields.Protocol},
+		{paste.FieldSerial, MSG_PASTE_SERIAL, info.ConnectionEquipmentSerialNumber},
+		{paste.FieldOlt, MSG_PASTE_OLT, info.ConnectionOltIP},
+		{paste.FieldSlot, MSG_PASTE_SLOT, info.ConnectionOltSlot},
+		{paste.FieldPort, MSG_PASTE_PORT, info.ConnectionOltPort},
+		{paste.FieldVlan, MSG_PASTE_VLAN, info.ConnectionClientVlan},
+		{paste.FieldPPPoEUser, MSG_PASTE_PPPOE_USER, info.ConnectionClientPPPoEUsername},
+		{paste.FieldPPPoEPassword, MSG_PASTE_PPPOE_PASSWORD, MSG_PASTE_HIDDEN},
+		{paste.FieldSplitter, MSG_PASTE_SPLITTER, info.ConnectionClientSplitterName},
+		{paste.FieldSplitterPort, MSG_PASTE_SPLITTER_PORT, info.ConnectionClientSplitterPort},
+	} {
+		if !fields.Has(line.field) {
+			continue
+		}
+		fmt.Fprintf(&builder, line.format, line.value)
+	}
+
+	return builder.String()
+}
@@ -1,77 +1,160 @@
 package handler
 
 import (
+	"context"
 	"fmt"
 	"provisioning-assistant/internal/domain"
 	"provisioning-assistant/internal/services"
 )
 
 type MenuHandler struct {
-	sessionService *services.SessionService
-	messenger      *Messenger
+	sessionService  *services.SessionService
+	erpService      *services.ErpService
+	circuitService  *services.ProvisioningCircuitService
+	watchdogService *services.DependencyWatchdogService
+	manualHandler   *ManualProvisioningHandler
+	searchHandler   *SearchHandler
+	signalHandler   *SignalHandler
+	keyboards       *KeyboardCatalog
+	messenger       *Messenger
 }
 
 // NewMenuHandler creates a new menu handler instance
 func NewMenuHandler(
 	sessionService *services.SessionService,
+	erpService *services.ErpService,
+	circuitService *services.ProvisioningCircuitService,
+	watchdogService *services.DependencyWatchdogService,
+	manualHandler *ManualProvisioningHandler,
+	searchHandler *SearchHandler,
+	signalHandler *SignalHandler,
+	keyboards *KeyboardCatalog,
 	messenger *Messenger,
 ) *MenuHandler {
 	return &MenuHandler{
-		sessionService: sessionService,
-		messenger:      messenger,
+		sessionService:  sessionService,
+		erpService:      erpService,
+		circuitService:  circuitService,
+		watchdogService: watchdogService,
+		manualHandler:   manualHandler,
+		searchHandler:   searchHandler,
+		signalHandler:   signalHandler,
+		keyboards:       keyboards,
+		messenger:       messenger,
 	}
 }
 
 // HandleMainMenuOption processes main menu selection and routes to appropriate handler
-func (h *MenuHandler) HandleMainMenuOption(session *domain.Session, option string) error {
+func (h *MenuHandler) HandleMainMenuOption(ctx context.Context, session *domain.Session, option string) error {
+	if h.IsOutage(ctx) && (option == "provision" || option == "manual" || option == "search") {
+		return h.messenger.SendMessage(ctx, session.ChatID, MSG_DEPENDENCY_OUTAGE)
+	}
+
 	switch option {
 	case "provision":
-		return h.handleProvisionOption(session)
+		return h.handleProvisionOption(ctx, session)
+	case "manual":
+		return h.handleManualOption(ctx, session)
+	case "search":
+		return h.searchHandler.Start(ctx, session)
 	case "exit":
-		return h.handleExitOption(session)
+		return h.handleExitOption(ctx, session)
 	default:
-		return h.sendMainMenu(session)
+		return h.SendMainMenu(ctx, session)
 	}
 }
 
 // handleProvisionOption handles equipment provisioning menu selection
-func (h *MenuHandler) handleProvisionOption(session *domain.Session) error {
-	session.State = domain.StateWaitingProtocol
-	h.sessionService.UpdateSession(session)
-	return h.messenger.SendMessage(session.ChatID, MSG_REQUEST_PROTOCOL)
+func (h *MenuHandler) handleProvisionOption(ctx context.Context, session *domain.Session) error {
+	if h.IsProvisioningSuspended(ctx, session) {
+		return h.messenger.SendMessage(ctx, session.ChatID, MSG_AUTO_PROVISIONING_SUSPENDED)
+	}
+
+	updateSession(h.sessionService, session, func(s *domain.Session) {
+		s.State = domain.StateWaitingProtocol
+	})
+	return h.messenger.SendMessage(ctx, session.ChatID, MSG_REQUEST_PROTOCOL)
+}
+
+// IsProvisioningSuspended reports whether the open circuit keeps the user from provisioning by protocol
+func (h *MenuHandler) IsProvisioningSuspended(ctx context.Context, session *domain.Session) bool {
+	return h.circuitService.IsOpen() && !h.canUseManualProvisioning(session) && !domain.IsTraining(ctx)
+}
+
+// handleManualOption starts the manual provisioning wizard for allowed roles
+func (h *MenuHandler) handleManualOption(ctx context.Context, session *domain.Session) error {
+	if !h.canUseManualProvisioning(session) {
+		return h.messenger.SendMessage(ctx, session.ChatID, MSG_MANUAL_NOT_ALLOWED)
+	}
+	return h.manualHandler.Start(ctx, session)
 }
 
 // handleExitOption handles exit menu selection and resets session
-func (h *MenuHandler) handleExitOption(session *domain.Session) error {
-	session.State = domain.StateIdle
-	h.sessionService.UpdateSession(session)
-	return h.messenger.SendMessage(session.ChatID, MSG_EXIT_MESSAGE)
+func (h *MenuHandler) handleExitOption(ctx context.Context, session *domain.Session) error {
+	updateSession(h.sessionService, session, func(s *domain.Session) {
+		s.State = domain.StateIdle
+	})
+	return h.messenger.SendMessage(ctx, session.ChatID, MSG_EXIT_MESSAGE)
+}
+
+// SendMainMenu sends the main menu, warning about ERP degradation and suspended provisioning when needed
+func (h *MenuHandler) SendMainMenu(ctx context.Context, session *domain.Session) error {
+	degraded := h.erpService.IsDegraded()
+	suspended := h.circuitService.IsTripped() && !domain.IsTraining(ctx)
+
+	var shown []string
+	if (degraded || suspended) && h.canUseManualProvisioning(session) {
+		shown = append(shown, ButtonManual)
+	}
+	if h.signalHandler.HasRecheck(ctx, session) {
+		shown = append(shown, ButtonRecheckSignal)
+	}
+
+	message := h.Banners(ctx) + fmt.Sprintf(MSG_USER_GREETING, session.UserName)
+	return h.messenger.SendMessageWithKeyboard(ctx, session.ChatID, message, h.keyboards.Build(KeyboardMainMenu, WithButtons(shown...)))
 }
 
-// sendMainMenu sends the main menu with inline keyboard buttons
-func (h *MenuHandler) sendMainMenu(session *domain.Session) error {
-	keyboard := &domain.Keyboard{
-		Inline: true,
-		Buttons: [][]domain.Button{
-			{{Text: MSG_MENU_PROVISION, Data: "main_menu:provision"}},
-			{{Text: MSG_MENU_EXIT, Data: "main_menu:exit"}},
-		},
+// Banners returns the warnings about training, suspended provisioning, ERP degradation and outages
+// shown above the menus
+func (h *MenuHandler) Banners(ctx context.Context) string {
+	var banners string
+	if domain.IsTraining(ctx) {
+		banners += MSG_TRAINING_BANNER
+	}
+	if h.circuitService.IsTripped() && !domain.IsTraining(ctx) {
+		banners += MSG_CIRCUIT_OPEN_BANNER
+	}
+	if h.erpService.IsDegraded() {
+		banners += MSG_ERP_DEGRADED_BANNER
 	}
+	if h.IsOutage(ctx) {
+		banners += MSG_DEPENDENCY_OUTAGE_BANNER
+	}
+	return banners
+}
 
-	message := fmt.Sprintf(MSG_USER_GREETING, session.UserName)
-	return h.messenger.SendMessageWithKeyboard(session.ChatID, message, keyboard)
+// IsOutage reports whether the UNM and the ERP are both down, training sessions run on the simulator and go on
+func (h *MenuHandler) IsOutage(ctx context.Context) bool {
+	return h.watchdogService.IsOutage() && !domain.IsTraining(ctx)
+}
+
+// canUseManualProvisioning checks if the session role may use the manual wizard
+func (h *MenuHandler) canUseManualProvisioning(session *domain.Session) bool {
+	return h.manualHandler.IsAllowed(session)
 }
 
 // SendContextualMenu sends appropriate menu based on current session state
-func (h *MenuHandler) SendContextualMenu(session *domain.Session) error {
+func (h *MenuHandler) SendContextualMenu(ctx context.Context, session *domain.Session) error {
 	switch session.State {
 	case domain.StateMainMenu:
-		return h.sendMainMenu(session)
+		return h.SendMainMenu(ctx, session)
 	case domain.StateWaitingProtocol:
-		return h.messenger.SendMessage(session.ChatID, MSG_REQUEST_PROTOCOL)
+		return h.messenger.SendMessage(ctx, session.ChatID, MSG_REQUEST_PROTOCOL)
+	case domain.StateWaitingPPPoESearch:
+		return h.messenger.SendMessage(ctx, session.ChatID, MSG_REQUEST_PPPOE_SEARCH)
 	case domain.StateWaitingCPF:
-		return h.messenger.SendMessage(session.ChatID, MSG_WELCOME)
+		return h.messenger.SendMessage(ctx, session.ChatID, MSG_WELCOME)
 	default:
-		return h.sendMainMenu(session)
+		return h.SendMainMenu(ctx, session)
 	}
-}
\ No newline at end of file
+}
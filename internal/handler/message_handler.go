@@ -1,9 +1,15 @@
 package handler
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"provisioning-assistant/internal/clock"
 	"provisioning-assistant/internal/domain"
+	"provisioning-assistant/internal/locale"
+	"provisioning-assistant/internal/scheduler"
 	"provisioning-assistant/internal/services"
+	"provisioning-assistant/internal/unm"
 	"strings"
 
 	"github.com/gookit/event"
@@ -15,11 +21,46 @@ type MessageHandler struct {
 	userService         *services.UserService
 	sessionService      *services.SessionService
 	erpService          *services.ErpService
+	auditService        *services.AuditService
+	tokenService        *services.TokenService
+	accessGuardService  *services.AccessGuardService
+	trainingService     *services.TrainingService
+	normalizer          *InputNormalizer
 	logger              domain.Logger
 
 	authHandler         *AuthenticationHandler
+	challengeHandler    *ChallengeHandler
+	consentHandler      *ConsentHandler
+	greetingHandler     *GreetingHandler
 	provisioningHandler *ProvisioningHandler
 	menuHandler         *MenuHandler
+	manualHandler       *ManualProvisioningHandler
+	searchHandler       *SearchHandler
+	operationGuard      *OperationGuard
+	photoHandler        *PhotoHandler
+	speedTestHandler    *SpeedTestHandler
+	signalHandler       *SignalHandler
+	commandHandler      *CommandHandler
+	callbackHandler     *CallbackHandler
+	digestHandler       *DigestHandler
+	capacityHandler     *CapacityHandler
+	orphanHandler       *OrphanHandler
+	deprovisionHandler  *DeprovisionHandler
+	probeHandler        *ProbeHandler
+	credentialHandler   *CredentialHandler
+	dependencyHandler   *DependencyHandler
+	changelogHandler    *ChangelogHandler
+	inlineHandler       *InlineHandler
+	consoleHandler      *Tl1ConsoleHandler
+	chatStatusHandler   *ChatStatusHandler
+	feedbackHandler     *FeedbackHandler
+	reopenHandler       *ReopenHandler
+	nudgeHandler        *NudgeHandler
+	preferencesHandler  *PreferencesHandler
+	workOrderHandler    *WorkOrderHandler
+	erpEventHandler     *ErpEventHandler
+	errorChannel        *ErrorChannel
+	adminNotifier       *AdminNotifier
 	messenger           *Messenger
 }
 
@@ -30,9 +71,146 @@ func NewMessageHandler(
 	userService *services.UserService,
 	sessionService *services.SessionService,
 	erpService *services.ErpService,
+	protocolCheckService *services.ProtocolCheckService,
+	auditService *services.AuditService,
+	tokenService *services.TokenService,
+	challengeService *services.ChallengeService,
+	accessGuardService *services.AccessGuardService,
+	lastJobService *services.LastJobService,
+	bindingService *services.BindingService,
+	ackService *services.AckService,
+	circuitService *services.ProvisioningCircuitService,
+	operationService *services.OperationService,
+	backupService *services.BackupService,
+	archiveService *services.ArchiveService,
+	featureService *services.FeatureService,
+	trainingService *services.TrainingService,
+	consoleService *services.Tl1ConsoleService,
+	credentialService *services.CredentialCheckService,
+	queue *services.ProvisioningQueue,
+	artifactService *services.ArtifactService,
+	idempotencyService *services.IdempotencyService,
+	templateService *services.PlanTemplateService,
+	catalogService *services.TemplateCatalogService,
+	feedbackService *services.FeedbackService,
+	alertService *services.AlertService,
+	maintenanceService *services.MaintenanceService,
+	oltSuggestionService *services.OltSuggestionService,
+	onuHistoryService *services.OnuHistoryService,
+	watchdogService *services.DependencyWatchdogService,
+	changelogService *services.ChangelogService,
+	safeModeService *services.SafeModeService,
+	exportService *services.ExportService,
+	workOrderService *services.WorkOrderService,
+	ponCapacityService *services.PonCapacityService,
+	orphanOnuService *services.OrphanOnuService,
+	deprovisionService *services.DeprovisionService,
+	probeService *services.UnmProbeService,
+	loadShedder *services.LoadShedder,
+	scheduler *scheduler.Scheduler,
+	consentPolicy ConsentPolicy,
+	inputPolicy InputPolicy,
+	successPolicy SuccessMessagePolicy,
+	nudgePolicy NudgePolicy,
+	speedTestPolicy SpeedTestPolicy,
+	greetingPolicy GreetingPolicy,
+	keyboards *KeyboardCatalog,
+	adminChatIDs []int64,
+	escalationChatIDs []int64,
+	feedbackChatIDs []int64,
+	errorChatIDs []int64,
+	mode InteractionMode,
+	clock clock.Clock,
+	formatter *locale.Formatter,
 	logger domain.Logger,
 ) *MessageHandler {
 	messenger := NewMessenger(eventManager)
+	attemptGuard := NewAttemptGuard(inputPolicy, sessionService, messenger, logger)
+	confirmer := NewConfirmer(sessionService, clock, keyboards, messenger)
+	manualHandler := NewManualProvisioningHandler(sessionService, oltSuggestionService, attemptGuard, confirmer, keyboards, messenger, logger)
+	adminNotifier := NewAdminNotifier(adminChatIDs, escalationChatIDs, ackService, keyboards, messenger, logger)
+	troubleshootHandler := NewTroubleshootHandler(auditService, adminNotifier, formatter, keyboards, messenger, logger)
+	signalHandler := NewSignalHandler(provisioningService, lastJobService, troubleshootHandler, formatter, keyboards, messenger, logger)
+	searchHandler := NewSearchHandler(sessionService, erpService, provisioningService, attemptGuard, formatter, keyboards, messenger, logger)
+	menuHandler := NewMenuHandler(sessionService, erpService, circuitService, watchdogService, manualHandler, searchHandler, signalHandler, keyboards, messenger)
+	commandHandler := NewCommandHandler(messenger, logger)
+	consentHandler := NewConsentHandler(consentPolicy, bindingService, sessionService, keyboards, messenger, logger)
+	challengeHandler := NewChallengeHandler(challengeService, accessGuardService, sessionService, consentHandler, formatter, keyboards, messenger, logger)
+	speedTestHandler := NewSpeedTestHandler(speedTestPolicy, auditService, sessionService, templateService, adminNotifier, formatter, keyboards, messenger, logger)
+	photoHandler := NewPhotoHandler(auditService, sessionService, speedTestHandler, keyboards, messenger, logger)
+	operationGuard := NewOperationGuard(operationService, sessionService, attemptGuard, adminNotifier, keyboards, messenger, logger)
+	authHandler := NewAuthenticationHandler(userService, sessionService, bindingService, accessGuardService, challengeHandler, attemptGuard, adminNotifier, menuHandler, messenger, NewPacer(mode, clock), logger)
+
+	NewTokenHandler(tokenService, messenger).RegisterCommands(commandHandler)
+	authHandler.RegisterCommands(commandHandler)
+	NewStatusHandler(provisioningService, erpService, circuitService, alertService, safeModeService, loadShedder, probeService, scheduler, formatter, messenger).RegisterCommands(commandHandler)
+	NewBackupHandler(backupService, artifactService, formatter, messenger, logger).RegisterCommands(commandHandler)
+	NewStorageHandler(artifactService, formatter, messenger, logger).RegisterCommands(commandHandler)
+	NewReportHandler(auditService, archiveService, sessionService.Metrics(), loadShedder, clock, formatter, messenger, logger).RegisterCommands(commandHandler)
+	NewQueueHandler(queue, clock, formatter, messenger).RegisterCommands(commandHandler)
+	rollbackHandler := NewRollbackHandler(auditService, erpService, provisioningService, sessionService, adminNotifier, confirmer, formatter, messenger, logger)
+	rollbackHandler.RegisterCommands(commandHandler)
+	NewFeatureHandler(featureService, formatter, messenger).RegisterCommands(commandHandler)
+	NewTemplateHandler(catalogService, templateService, formatter, messenger, logger).RegisterCommands(commandHandler)
+	NewTrainingHandler(trainingService, messenger).RegisterCommands(commandHandler)
+	NewMaintenanceHandler(maintenanceService, clock, formatter, messenger).RegisterCommands(commandHandler)
+	NewOnuHistoryHandler(onuHistoryService, loadShedder, formatter, messenger, logger).RegisterCommands(commandHandler)
+	NewOpticalBudgetHandler(provisioningService, lastJobService, formatter, messenger, logger).RegisterCommands(commandHandler)
+	NewExportHandler(exportService, artifactService, clock, formatter, messenger, logger).RegisterCommands(commandHandler)
+	preferencesHandler := NewPreferencesHandler(bindingService, keyboards, messenger, logger)
+	preferencesHandler.RegisterCommands(commandHandler)
+	consoleHandler := NewTl1ConsoleHandler(consoleService, sessionService, operationGuard, attemptGuard, adminNotifier, keyboards, messenger, logger)
+	consoleHandler.RegisterCommands(commandHandler)
+	feedbackHandler := NewFeedbackHandler(feedbackService, sessionService, lastJobService, adminNotifier, feedbackChatIDs, clock, formatter, keyboards, messenger, logger)
+	feedbackHandler.RegisterCommands(commandHandler)
+	searchHandler.RegisterCommands(commandHandler)
+	consentHandler.RegisterCommands(commandHandler)
+	provisioningHandler := NewProvisioningHandler(provisioningService, erpService, protocolCheckService, sessionService, auditService, lastJobService, circuitService, queue, idempotencyService, maintenanceService, bindingService, adminNotifier, attemptGuard, photoHandler, signalHandler, manualHandler, confirmer, successPolicy, clock, formatter, keyboards, messenger, eventManager, logger)
+	reopenHandler := NewReopenHandler(auditService, sessionService, lastJobService, provisioningHandler, signalHandler, menuHandler, attemptGuard, clock, formatter, keyboards, messenger, logger)
+	reopenHandler.RegisterCommands(commandHandler)
+	nudgeHandler := NewNudgeHandler(nudgePolicy, sessionService, menuHandler, messenger, logger)
+	nudgeHandler.RegisterCommands(commandHandler)
+	workOrderHandler := NewWorkOrderHandler(workOrderService, sessionService, menuHandler, provisioningHandler, keyboards, messenger, logger)
+	orphanHandler := NewOrphanHandler(orphanOnuService, adminNotifier, keyboards, messenger, logger)
+	deprovisionHandler := NewDeprovisionHandler(deprovisionService, adminNotifier, clock, formatter, keyboards, messenger, logger)
+	deprovisionHandler.RegisterCommands(commandHandler)
+
+	callbackHandler := NewCallbackHandler(messenger, logger)
+	callbackHandler.Register("main_menu", ActionArgs{}, menuHandler.HandleMainMenuOption)
+	callbackHandler.Register("confirm", ActionArgs{Values: []string{ConfirmationYes, ConfirmationNo, ConfirmationEdit}}, provisioningHandler.HandleConfirmation)
+	callbackHandler.Register("consent", ActionArgs{Values: []string{"accept", "decline"}}, consentHandler.HandleConsentOption)
+	callbackHandler.Register("captcha", ActionArgs{}, challengeHandler.HandleAnswer)
+	callbackHandler.Register("recheck", ActionArgs{Values: []string{"signal", "wifi"}}, signalHandler.HandleRecheckOption)
+	callbackHandler.Register("photos", ActionArgs{Values: []string{"done"}}, photoHandler.HandlePhotoOption)
+	callbackHandler.Register("speedtest", ActionArgs{Values: []string{"skip"}}, speedTestHandler.HandleOption)
+	callbackHandler.Register("op_approve", ActionArgs{}, func(ctx context.Context, session *domain.Session, arg string) error {
+		return operationGuard.HandleDecision(ctx, session, true, arg)
+	})
+	callbackHandler.Register("op_reject", ActionArgs{}, func(ctx context.Context, session *domain.Session, arg string) error {
+		return operationGuard.HandleDecision(ctx, session, false, arg)
+	})
+	callbackHandler.Register("override_approve", ActionArgs{}, func(ctx context.Context, session *domain.Session, arg string) error {
+		return provisioningHandler.HandleOverrideDecision(ctx, session, true, arg)
+	})
+	callbackHandler.Register("override_reject", ActionArgs{}, func(ctx context.Context, session *domain.Session, arg string) error {
+		return provisioningHandler.HandleOverrideDecision(ctx, session, false, arg)
+	})
+	callbackHandler.Register("tl1_endpoint", ActionArgs{}, consoleHandler.HandleEndpointOption)
+	callbackHandler.Register("feedback", ActionArgs{}, feedbackHandler.HandleFeedbackOption)
+	callbackHandler.Register("reopen", ActionArgs{Values: []string{"signal", "reconfigure", "swap", "cancel"}}, reopenHandler.HandleReopenOption)
+	callbackHandler.Register("olt", ActionArgs{Rest: true}, manualHandler.HandleOltOption)
+	callbackHandler.Register("manual", ActionArgs{Values: []string{"undo"}}, manualHandler.HandleStepOption)
+	callbackHandler.Register("maintenance", ActionArgs{Values: []string{"ack", "cancel"}}, provisioningHandler.HandleMaintenanceOption)
+	callbackHandler.Register("duplicate", ActionArgs{Values: []string{"replace", "cancel"}}, provisioningHandler.HandleDuplicateOption)
+	callbackHandler.Register("prefs", ActionArgs{Values: []string{"compact", "alerts", "language", "done"}}, preferencesHandler.HandlePreferenceOption)
+	callbackHandler.Register("work_order", ActionArgs{}, workOrderHandler.HandleStartOption)
+	callbackHandler.Register("orphan_remove", ActionArgs{}, orphanHandler.HandleRemoveOption)
+	callbackHandler.Register("orphan_ignore", ActionArgs{}, orphanHandler.HandleIgnoreOption)
+	callbackHandler.Register("deprovision_run", ActionArgs{}, deprovisionHandler.HandleRunOption)
+	callbackHandler.Register("deprovision_cancel", ActionArgs{}, deprovisionHandler.HandleCancelOption)
+	callbackHandler.Register("rollback_run", ActionArgs{}, rollbackHandler.HandleRunOption)
+	callbackHandler.Register("rollback_cancel", ActionArgs{}, rollbackHandler.HandleCancelOption)
+	callbackHandler.Register("troubleshoot", ActionArgs{Rest: true}, troubleshootHandler.HandleOption)
 
 	return &MessageHandler{
 		eventManager:        eventManager,
@@ -40,22 +218,57 @@ func NewMessageHandler(
 		userService:         userService,
 		sessionService:      sessionService,
 		erpService:          erpService,
+		auditService:        auditService,
+		tokenService:        tokenService,
+		accessGuardService:  accessGuardService,
+		trainingService:     trainingService,
+		normalizer:          NewInputNormalizer(inputPolicy.Normalization, logger),
 		logger:              logger,
-		authHandler:         NewAuthenticationHandler(userService, sessionService, messenger, logger),
-		provisioningHandler: NewProvisioningHandler(provisioningService, erpService, sessionService, messenger, eventManager, logger),
-		menuHandler:         NewMenuHandler(sessionService, messenger),
+		authHandler:         authHandler,
+		challengeHandler:    challengeHandler,
+		consentHandler:      consentHandler,
+		greetingHandler:     NewGreetingHandler(greetingPolicy, auditService, authHandler, consentHandler, menuHandler, manualHandler, signalHandler, clock, formatter, keyboards, messenger, logger),
+		provisioningHandler: provisioningHandler,
+		menuHandler:         menuHandler,
+		manualHandler:       manualHandler,
+		searchHandler:       searchHandler,
+		operationGuard:      operationGuard,
+		photoHandler:        photoHandler,
+		speedTestHandler:    speedTestHandler,
+		signalHandler:       signalHandler,
+		commandHandler:      commandHandler,
+		callbackHandler:     callbackHandler,
+		digestHandler:       NewDigestHandler(auditService, adminNotifier, clock),
+		capacityHandler:     NewCapacityHandler(ponCapacityService, adminNotifier, formatter),
+		orphanHandler:       orphanHandler,
+		deprovisionHandler:  deprovisionHandler,
+		probeHandler:        NewProbeHandler(probeService, circuitService, adminNotifier, formatter),
+		credentialHandler:   NewCredentialHandler(credentialService, adminNotifier),
+		dependencyHandler:   NewDependencyHandler(watchdogService, adminNotifier, clock, formatter),
+		changelogHandler:    NewChangelogHandler(changelogService, messenger),
+		inlineHandler:       NewInlineHandler(userService, bindingService, erpService, auditService, provisioningService, formatter, messenger, logger),
+		consoleHandler:      consoleHandler,
+		chatStatusHandler:   NewChatStatusHandler(bindingService, adminNotifier, logger),
+		feedbackHandler:     feedbackHandler,
+		reopenHandler:       reopenHandler,
+		nudgeHandler:        nudgeHandler,
+		preferencesHandler:  preferencesHandler,
+		workOrderHandler:    workOrderHandler,
+		erpEventHandler:     NewErpEventHandler(sessionService, erpService, workOrderHandler, provisioningHandler, menuHandler, messenger, logger),
+		errorChannel:        NewErrorChannel(errorChatIDs, services.NewErrorThrottle(clock), messenger, logger),
+		adminNotifier:       adminNotifier,
 		messenger:           messenger,
 	}
 }
 
-// RegisterEventListeners registers event listeners for messages and callbacks
+// RegisterEventListeners registers event listeners for messages, callbacks and inline queries
 func (h *MessageHandler) RegisterEventListeners() {
 	h.eventManager.On("telegram.message.received", event.ListenerFunc(func(e event.Event) error {
 		msgEvent, ok := e.Get("event").(*domain.MessageEvent)
 		if !ok {
 			return fmt.Errorf("tipo de evento de mensagem inválido")
 		}
-		return h.handleMessage(msgEvent)
+		return h.handleMessage(eventContext(e), msgEvent)
 	}))
 
 	h.eventManager.On("telegram.callback.received", event.ListenerFunc(func(e event.Event) error {
@@ -63,57 +276,264 @@ func (h *MessageHandler) RegisterEventListeners() {
 		if !ok {
 			return fmt.Errorf("tipo de evento de callback inválido")
 		}
-		return h.handleCallback(callbackEvent)
+		return h.handleCallback(eventContext(e), callbackEvent)
+	}))
+
+	h.eventManager.On("telegram.inline.received", event.ListenerFunc(func(e event.Event) error {
+		queryEvent, ok := e.Get("event").(*domain.InlineQueryEvent)
+		if !ok {
+			return fmt.Errorf("tipo de evento de consulta inline inválido")
+		}
+		return h.inlineHandler.HandleInlineQuery(eventContext(e), queryEvent)
+	}))
+
+	h.eventManager.On("telegram.chat.blocked", event.ListenerFunc(func(e event.Event) error {
+		chatID, ok := e.Get("chatID").(int64)
+		if !ok {
+			return fmt.Errorf("tipo de chatID inválido")
+		}
+		return h.chatStatusHandler.HandleBlocked(eventContext(e), chatID)
+	}))
+
+	h.eventManager.On("telegram.chat.migrated", event.ListenerFunc(func(e event.Event) error {
+		from, fromOk := e.Get("from").(int64)
+		to, toOk := e.Get("to").(int64)
+		if !fromOk || !toOk {
+			return fmt.Errorf("tipo de chat migrado inválido")
+		}
+		return h.chatStatusHandler.HandleMigrated(eventContext(e), from, to)
 	}))
+
+	h.eventManager.On("app.error", event.ListenerFunc(func(e event.Event) error {
+		report, ok := e.Get("event").(*domain.ErrorEvent)
+		if !ok {
+			return fmt.Errorf("tipo de evento de erro inválido")
+		}
+		h.errorChannel.Report(eventContext(e), report)
+		return nil
+	}))
+
+	h.eventManager.On("erp.event.received", event.ListenerFunc(func(e event.Event) error {
+		erpEvent, ok := e.Get("event").(*domain.ErpEvent)
+		if !ok {
+			return fmt.Errorf("tipo de evento do ERP inválido")
+		}
+		h.erpEventHandler.HandleErpEvent(eventContext(e), erpEvent)
+		return nil
+	}))
+
+	h.eventManager.On("unm.watchdog.fired", event.ListenerFunc(func(e event.Event) error {
+		fired, ok := e.Get("event").(*unm.WatchdogEvent)
+		if !ok {
+			return fmt.Errorf("tipo de evento do watchdog inválido")
+		}
+		h.notifyWatchdog(eventContext(e), fired)
+		return nil
+	}))
+}
+
+// notifyWatchdog tells the admins a wedged TL1 connection was torn down
+func (h *MessageHandler) notifyWatchdog(ctx context.Context, fired *unm.WatchdogEvent) {
+	outcome := MSG_ALERT_TL1_RECONNECTED
+	if !fired.Reconnected {
+		outcome = fmt.Sprintf(MSG_ALERT_TL1_RECONNECT_FAIL, fired.Err)
+	}
+	h.adminNotifier.Notify(ctx, fmt.Sprintf(MSG_ALERT_TL1_WATCHDOG, fired.Endpoint, fired.Timeouts, outcome))
+}
+
+// SendAuditDigest sends the daily provisioning summary to the admin chats
+func (h *MessageHandler) SendAuditDigest(ctx context.Context) error {
+	return h.digestHandler.SendAuditDigest(ctx)
+}
+
+// SendFeedbackDigest sends the weekly technician feedback summary to the product owners
+func (h *MessageHandler) SendFeedbackDigest(ctx context.Context) error {
+	return h.feedbackHandler.SendFeedbackDigest(ctx)
+}
+
+// SendCapacityDigest sends the monthly PON capacity trends to the admin chats
+func (h *MessageHandler) SendCapacityDigest(ctx context.Context) error {
+	return h.capacityHandler.SendCapacityDigest(ctx)
+}
+
+// DetectOrphanOnus reports the ONUs left on the OLTs by failed swaps to the supervisors
+func (h *MessageHandler) DetectOrphanOnus(ctx context.Context) error {
+	return h.orphanHandler.DetectOrphanOnus(ctx)
+}
+
+// DeprovisionCancelled removes the ONUs of the contracts recently cancelled in the ERP from the OLTs
+func (h *MessageHandler) DeprovisionCancelled(ctx context.Context) error {
+	return h.deprovisionHandler.DeprovisionCancelled(ctx)
+}
+
+// ProbeUnm issues the synthetic TL1 probe to the UNM endpoints, alerting the admins on outages
+func (h *MessageHandler) ProbeUnm(ctx context.Context) error {
+	return h.probeHandler.ProbeUnm(ctx)
+}
+
+// CheckUnmCredentials verifies the UNM account and warns the admins before it stops working
+func (h *MessageHandler) CheckUnmCredentials(ctx context.Context) error {
+	return h.credentialHandler.CheckUnmCredentials(ctx)
+}
+
+// WatchDependencies probes the UNM and the ERP, turning users away with an outage notice while both are down
+func (h *MessageHandler) WatchDependencies(ctx context.Context) error {
+	return h.dependencyHandler.WatchDependencies(ctx)
+}
+
+// EndOfDayLogout signs every technician out and detaches their Telegram accounts
+func (h *MessageHandler) EndOfDayLogout(ctx context.Context) error {
+	return h.authHandler.EndOfDayLogout(ctx)
+}
+
+// EscalateOverdueAlerts forwards unacknowledged critical alerts to the escalation contacts
+func (h *MessageHandler) EscalateOverdueAlerts(ctx context.Context) error {
+	return h.adminNotifier.EscalateOverdue(ctx)
+}
+
+// NudgeStalledSessions reminds technicians silent in an input step of what it is waiting for
+func (h *MessageHandler) NudgeStalledSessions(ctx context.Context) error {
+	return h.nudgeHandler.NudgeStalled(ctx)
+}
+
+// GreetAssignedTechnicians tells the technicians with a binding about the protocols the ERP assigned to them
+func (h *MessageHandler) GreetAssignedTechnicians(ctx context.Context) error {
+	return h.workOrderHandler.GreetAssignedTechnicians(ctx)
 }
 
 // handleMessage routes messages based on current session state
-func (h *MessageHandler) handleMessage(msg *domain.MessageEvent) error {
+func (h *MessageHandler) handleMessage(ctx context.Context, msg *domain.MessageEvent) error {
 	session := h.getOrCreateSession(msg.UserID, msg.ChatID)
+	ctx = h.userContext(ctx, session)
+
+	if banned, until := h.accessGuardService.IsBanned(msg.UserID); banned {
+		return h.challengeHandler.SendBanNotice(ctx, session, until)
+	}
+
+	if msg.IsContact() {
+		return h.consentHandler.HandleContact(ctx, session, msg)
+	}
+
+	// Smart quotes, invisible characters and emojis would break the validators and the TL1 commands
+	h.normalizer.Normalize(session.State, msg)
+
+	// No step takes a location, a shared one must not be read as an empty answer
+	if msg.IsLocation() {
+		return nil
+	}
+
+	h.changelogHandler.NotifyChanges(ctx, session)
+
+	h.consentHandler.CaptureProfile(ctx, session, msg.Profile)
+
+	if h.commandHandler.IsCommand(msg.Message) {
+		return h.commandHandler.Handle(ctx, session, msg)
+	}
 
 	switch session.State {
 	case domain.StateIdle:
-		return h.handleStart(session, msg)
+		return h.handleStart(ctx, session, msg)
+	case domain.StateWaitingChallenge:
+		return h.challengeHandler.HandleTextInput(ctx, session, msg)
+	case domain.StateWaitingConsent:
+		return h.consentHandler.HandleTextInput(ctx, session, msg)
 	case domain.StateWaitingCPF:
-		return h.authHandler.HandleCPFInput(session, msg)
+		return h.authHandler.HandleCPFInput(ctx, session, msg)
 	case domain.StateWaitingProtocol:
-		return h.provisioningHandler.HandleProtocolInput(session, msg)
+		return h.provisioningHandler.HandleProtocolInput(ctx, session, msg)
+	case domain.StateWaitingPPPoESearch:
+		return h.searchHandler.HandlePPPoEInput(ctx, session, msg)
+	case domain.StateWaitingOperationPhrase:
+		return h.operationGuard.HandlePhraseInput(ctx, session, msg)
+	case domain.StateWaitingOverride:
+		return h.provisioningHandler.HandleOverrideWaiting(ctx, session, msg)
+	case domain.StateTl1Console:
+		return h.consoleHandler.HandleCommandInput(ctx, session, msg)
+	case domain.StateWaitingSerial,
+		domain.StateWaitingOLT,
+		domain.StateWaitingSlot,
+		domain.StateWaitingPort,
+		domain.StateWaitingVlan,
+		domain.StateWaitingPPPoEUser,
+		domain.StateWaitingPPPoEPass:
+		return h.manualHandler.HandleInput(ctx, session, msg)
+	case domain.StateWaitingPhotos:
+		return h.photoHandler.HandlePhotoInput(ctx, session, msg)
+	case domain.StateWaitingSpeedTest:
+		return h.speedTestHandler.HandleInput(ctx, session, msg)
+	case domain.StateWaitingFeedbackRating, domain.StateWaitingFeedbackComment:
+		return h.feedbackHandler.HandleTextInput(ctx, session, msg)
+	case domain.StateReopenMenu, domain.StateWaitingReopenSerial:
+		return h.reopenHandler.HandleTextInput(ctx, session, msg)
 	default:
-		return h.handleStart(session, msg)
+		return h.handleStart(ctx, session, msg)
 	}
 }
 
 // handleCallback routes callback queries based on action type
-func (h *MessageHandler) handleCallback(callback *domain.CallbackEvent) error {
+func (h *MessageHandler) handleCallback(ctx context.Context, callback *domain.CallbackEvent) error {
+	// Alert acknowledgements come from admin chats and do not depend on a session
+	if noticeID, found := strings.CutPrefix(callback.Data, "ack:"); found {
+		return h.adminNotifier.HandleAckOption(ctx, callback, noticeID)
+	}
+
 	session := h.sessionService.GetSession(callback.UserID)
 	if session == nil {
 		_ = h.sessionService.CreateSession(callback.UserID, callback.ChatID)
-		return h.messenger.SendMessage(callback.ChatID, MSG_SESSION_EXPIRED)
+		return h.messenger.SendMessage(ctx, callback.ChatID, MSG_SESSION_EXPIRED)
 	}
 
-	parts := strings.Split(callback.Data, ":")
-	if len(parts) == 0 {
-		return nil
+	ctx = h.userContext(ctx, session)
+
+	if banned, until := h.accessGuardService.IsBanned(callback.UserID); banned {
+		return h.challengeHandler.SendBanNotice(ctx, session, until)
 	}
 
-	action := parts[0]
+	h.consentHandler.CaptureProfile(ctx, session, callback.Profile)
+	h.changelogHandler.NotifyChanges(ctx, session)
 
-	switch action {
-	case "main_menu":
-		return h.menuHandler.HandleMainMenuOption(session, parts[1])
-	case "confirm":
-		return h.provisioningHandler.HandleConfirmation(session, parts[1])
-	default:
-		return nil
+	return h.callbackHandler.Handle(ctx, session, callback.Data)
+}
+
+// handleStart initiates the conversation flow through challenge, consent and CPF entry, greets a returning
+// technician still bound to their CPF, or answers with the outage notice while no flow can succeed
+func (h *MessageHandler) handleStart(ctx context.Context, session *domain.Session, msg *domain.MessageEvent) error {
+	if h.menuHandler.IsOutage(ctx) {
+		return h.messenger.SendMessage(ctx, session.ChatID, MSG_DEPENDENCY_OUTAGE)
 	}
+
+	if h.challengeHandler.IsRequired(session) {
+		return h.challengeHandler.SendChallenge(ctx, session)
+	}
+
+	if greeted, err := h.greetingHandler.GreetReturning(ctx, session); greeted {
+		return err
+	}
+
+	return h.consentHandler.RequestCPF(ctx, session)
 }
 
-// handleStart initiates the conversation flow and sets waiting for CPF state
-func (h *MessageHandler) handleStart(session *domain.Session, msg *domain.MessageEvent) error {
-	session.State = domain.StateWaitingCPF
-	h.sessionService.UpdateSession(session)
+// userContext tags the TL1 commands with the user and its role and routes training users to the sandbox
+func (h *MessageHandler) userContext(ctx context.Context, session *domain.Session) context.Context {
+	ctx = unm.WithOrigin(ctx, unm.Origin{UserID: session.UserID})
+	ctx = domain.WithRole(ctx, session.UserRole)
+	if h.trainingService.IsEnabled(ctx, session.UserID) {
+		ctx = domain.WithTraining(ctx)
+	}
+	return ctx
+}
 
-	return h.messenger.SendMessage(msg.ChatID, MSG_WELCOME)
+// updateSession applies changes through the session service and refreshes the caller's copy
+func updateSession(sessionService *services.SessionService, session *domain.Session, fn func(s *domain.Session)) {
+	updated, err := sessionService.UpdateFn(session.UserID, fn)
+	if errors.Is(err, services.ErrSessionNotFound) {
+		sessionService.Restore(session)
+		updated, err = sessionService.UpdateFn(session.UserID, fn)
+	}
+
+	if err == nil {
+		*session = *updated
+	}
 }
 
 // getOrCreateSession retrieves existing session or creates a new one if needed
@@ -14,62 +14,963 @@ const (
 	MSG_CPF_UNAUTHORIZED = "❌ CPF não autorizado.\n" +
 		"Por favor, verifique o número e tente novamente:"
 
+	// Invalid input limit messages
+	MSG_LAST_ATTEMPT_HINT = "\n\n⚠️ Última tentativa antes de encerrar o atendimento."
+	MSG_TOO_MANY_ATTEMPTS = "🚪 Muitas respostas inválidas seguidas, o atendimento foi encerrado.\n\n" +
+		"Confira os dados da ordem de serviço e digite /start para recomeçar."
+	MSG_SUPPORT_CONTACT = "\n\n📞 Precisa de ajuda? Fale com %s."
+
 	MSG_USER_GREETING = "✅ Olá, %s!\n\nO que você deseja fazer?"
 
+	// Privacy consent messages
+	MSG_PRIVACY_NOTICE = "🔒 Aviso de privacidade\n\n" +
+		"Para identificar você, este assistente coleta seu CPF, seu ID, nome e usuário do Telegram " +
+		"e, se você compartilhar, seu telefone. Os dados são usados exclusivamente para autorizar o " +
+		"acesso, registrar os provisionamentos realizados e permitir que o suporte entre em contato " +
+		"com você, conforme a Lei Geral de Proteção de Dados (LGPD).\n\n" +
+		"Você concorda com o tratamento desses dados?"
+	MSG_CONSENT_ACCEPT   = "✅ Aceito"
+	MSG_CONSENT_DECLINE  = "❌ Não aceito"
+	MSG_CONSENT_DECLINED = "Sem o seu consentimento não é possível continuar. Digite /start se mudar de ideia."
+	MSG_CONSENT_FAILED   = "❌ Não foi possível registrar seu consentimento. Tente novamente."
+
+	// Technician contact messages
+	MSG_PHONE_REQUEST          = "📞 Toque no botão abaixo para compartilhar seu telefone com o suporte."
+	MSG_PHONE_SHARE            = "📞 Compartilhar telefone"
+	MSG_PHONE_SAVED            = "✅ Telefone registrado. O suporte poderá entrar em contato com você."
+	MSG_PHONE_CONSENT_REQUIRED = "🔒 Aceite o aviso de privacidade antes de compartilhar seu telefone. Digite /start para continuar."
+
+	// Anti-bot challenge messages
+	MSG_CHALLENGE          = "🤖 Antes de continuar, confirme que você não é um robô.\n\nQuanto é %s?"
+	MSG_CHALLENGE_WRONG    = "❌ Resposta incorreta. Tente novamente."
+	MSG_CHALLENGE_PENDING  = "👆 Toque na resposta correta abaixo para continuar."
+	MSG_TEMPORARILY_BANNED = "⛔ Muitas tentativas de acesso sem sucesso.\n" +
+		"Tente novamente após %s."
+
+	// Access lockout messages
+	MSG_ALERT_CPF_GUESSING = "🚨 Alerta de segurança\n\n" +
+		"O CPF %s recebeu tentativas de acesso malsucedidas de %d contas diferentes.\n" +
+		"IDs do Telegram: %s"
+	MSG_ALERT_ACK_BUTTON   = "✅ Ciente"
+	MSG_ALERT_ACKED        = "✅ Alerta confirmado pelo usuário %d."
+	MSG_ALERT_ACK_ALREADY  = "ℹ️ Este alerta já foi confirmado pelo usuário %d."
+	MSG_ALERT_ACK_UNKNOWN  = "ℹ️ Alerta não encontrado ou expirado."
+	MSG_ALERT_ESCALATED    = "⏫ Alerta sem confirmação há %d minutos\n\n%s"
+	MSG_ALERT_CIRCUIT_OPEN = "🚨 Provisionamento automático suspenso\n\n" +
+		"A taxa de sucesso recente caiu para %s, indicando problema sistêmico na OLT ou no ERP.\n" +
+		"O bot passou para o modo de escalonamento manual."
+	MSG_ALERT_VIP_STARTED         = "⭐ Provisionamento VIP iniciado\n\n📄 Contrato: %s\n👤 Cliente: %s\n👷 Técnico: %s"
+	MSG_ALERT_VIP_FINISHED        = "⭐ Provisionamento VIP concluído\n\n📄 Contrato: %s\n👤 Cliente: %s\n👷 Técnico: %s"
+	MSG_ALERT_VIP_FAILED          = "🚨 Falha no provisionamento VIP\n\n📄 Contrato: %s\n👤 Cliente: %s\n👷 Técnico: %s\n\nErro: %v"
+	MSG_ALERT_SIGNAL_CRITICAL     = "🚨 ONU provisionada com sinal crítico\n\n📄 Contrato: %s\n📟 Serial: %s\n🏢 OLT: %s\n➡️ Recepção: %s (limite %s)\n👷 Técnico: %s"
+	MSG_ALERT_TL1_WATCHDOG        = "🔌 Conexão TL1 com %s travada\n\n%d comandos seguidos ficaram sem resposta e a conexão foi derrubada. %s"
+	MSG_ALERT_TL1_RECONNECTED     = "A reconexão foi bem-sucedida."
+	MSG_ALERT_TL1_RECONNECT_FAIL  = "A reconexão falhou (%v), uma nova tentativa será feita no próximo comando."
+	MSG_ALERT_CIRCUIT_CLOSED      = "✅ Provisionamento automático restabelecido após novas ativações bem-sucedidas."
+	MSG_ALERT_ADMIN_CHAT_BLOCKED  = "⚠️ O chat de administração %d bloqueou ou removeu o bot e deixou de receber alertas."
+	MSG_ALERT_ADMIN_CHAT_MIGRATED = "ℹ️ O grupo de administração %d foi migrado para o supergrupo %d. Os alertas seguem para o novo chat, atualize a configuração."
+	MSG_ALERT_CREDENTIALS_FAILED  = "🔑 Credenciais do UNM rejeitadas\n\n" +
+		"O login de verificação em %s falhou: %s.\n%v\n\n" +
+		"Os provisionamentos vão falhar até a conta do UNM ser corrigida."
+	MSG_ALERT_CREDENTIALS_EXPIRING     = "🔑 A senha da conta do UNM em %s expira em %d dia(s). Troque-a e atualize UNM_PASSWORD para evitar falhas nos provisionamentos."
+	MSG_ALERT_CREDENTIALS_EXPIRE_TODAY = "🔑 A senha da conta do UNM em %s expira hoje. Troque-a e atualize UNM_PASSWORD antes que os provisionamentos comecem a falhar."
+	MSG_CREDENTIALS_LOCKED             = "conta bloqueada"
+	MSG_CREDENTIALS_EXPIRED            = "senha expirada"
+	MSG_CREDENTIALS_REJECTED           = "login recusado ou UNM inacessível"
+	MSG_ALERT_RESTORE_FAILED           = "🚨 Troca de ONU abortada sem restauração\n\n" +
+		"A configuração anterior da ONU %s não pôde ser restaurada na OLT. Use /reverter com o registro %s."
+	MSG_ALERT_COMMAND_BUDGET = "🚨 Provisionamento abortado por excesso de comandos TL1\n\n" +
+		"ONU %s na OLT %s (plano %s, template %s)\n%v\n\n" +
+		"Revise o perfil de WAN do template antes de novos provisionamentos. Registro: %s"
+	MSG_ALERT_STEP_TIMEOUT = "🚨 Configuração parcial deixada na OLT\n\n" +
+		"ONU %s na OLT %s\n%v\n\n" +
+		"Uma etapa TL1 excedeu o tempo limite e a ONU não pôde ser removida. Remova-a antes de um novo provisionamento. Registro: %s"
+	MSG_ALERT_DEPENDENCY_OUTAGE = "🚨 UNM e ERP fora do ar\n\n" +
+		"Todas as dependências falharam nas últimas verificações e os fluxos foram suspensos:\n%s\n" +
+		"Os técnicos recebem um aviso de indisponibilidade até o restabelecimento."
+	MSG_ALERT_DEPENDENCY_ITEM      = "• %s: %s\n"
+	MSG_ALERT_ROLLOUT_ROLLED_BACK  = "↩️ Lançamento gradual revertido\n\nA etapa %s passou da taxa de erro tolerada e voltou ao caminho antigo em todas as OLTs. Use /recurso %s <percentual>%% para retomá-la."
+	MSG_ALERT_DEPENDENCY_RECOVERED = "✅ Dependências restabelecidas após %s, os fluxos foram retomados."
+	MSG_ALERT_PROBE_DOWN           = "🚨 UNM %s sem resposta\n\n" +
+		"A sonda TL1 falhou %d vez(es) seguidas: %v\n" +
+		"Os provisionamentos nesse endpoint devem falhar até ele voltar a responder."
+	MSG_ALERT_PROBE_RECOVERED = "✅ UNM %s voltou a responder à sonda TL1 após %s."
+	MSG_UNLOCK_USAGE          = "🔓 Uso: /unlock <id do Telegram>"
+	MSG_UNLOCK_DONE           = "🔓 Acesso do usuário %d desbloqueado."
+	MSG_UNLOCK_NOT_FOUND      = "ℹ️ O usuário %d não possui bloqueio ativo."
+
 	// Session messages
-	MSG_SESSION_EXPIRED = "Sessão expirada. Por favor, digite /start para começar novamente."
+	MSG_SESSION_EXPIRED    = "Sessão expirada. Por favor, digite /start para começar novamente."
+	MSG_SESSION_END_OF_DAY = "🌙 Fim do expediente: sua sessão foi encerrada por segurança.\n\nDigite /start para entrar novamente."
+	MSG_CALLBACK_INVALID   = "⚠️ Este botão não é mais válido. Use as opções da última mensagem ou digite /start."
+
+	// Nudge messages
+	MSG_NUDGE               = "👋 Ainda está aí? %s\n\nOu digite /cancelar para voltar ao menu principal."
+	MSG_NUDGE_PROTOCOL      = "Responda com o número do protocolo da solicitação."
+	MSG_NUDGE_PPPOE_SEARCH  = "Responda com o usuário PPPoE que deseja buscar."
+	MSG_NUDGE_CONFIRM_DATA  = "Confirme os dados do cliente pelos botões da mensagem anterior."
+	MSG_NUDGE_SERIAL        = "Responda com o serial da ONU."
+	MSG_NUDGE_OLT           = "Responda com o IP da OLT."
+	MSG_NUDGE_SLOT          = "Responda com o slot da OLT."
+	MSG_NUDGE_PORT          = "Responda com a porta PON."
+	MSG_NUDGE_VLAN          = "Responda com a VLAN do cliente."
+	MSG_NUDGE_PPPOE_USER    = "Responda com o usuário PPPoE do cliente."
+	MSG_NUDGE_PPPOE_PASS    = "Responda com a senha PPPoE do cliente."
+	MSG_NUDGE_PHOTOS        = "Envie as fotos da instalação ou toque em Concluir."
+	MSG_NUDGE_SPEED_TEST    = "Envie o resultado do teste de velocidade ou toque em Pular teste."
+	MSG_NUDGE_REOPEN_MENU   = "Escolha o que fazer com o provisionamento reaberto."
+	MSG_NUDGE_REOPEN_SERIAL = "Responda com o serial da nova ONU."
+	MSG_CANCEL_DONE         = "↩️ Etapa cancelada."
+	MSG_CANCEL_PROVISIONING = "⏳ O provisionamento já está em andamento e não pode ser cancelado, aguarde o resultado."
+	MSG_CANCEL_OPERATION    = "⚠️ Para desistir da operação digite \"cancelar\"."
 
 	// Menu messages
 	MSG_MENU_PROVISION = "🔧 Provisionar Equipamento"
+	MSG_MENU_MANUAL    = "🛠️ Provisionamento Manual"
+	MSG_MENU_SEARCH    = "🔎 Buscar ONU por PPPoE"
 	MSG_MENU_EXIT      = "❌ Sair"
+	MSG_BACK_TO_MENU   = "🏠 Menu principal"
 	MSG_EXIT_MESSAGE   = "👋 Obrigado por usar nosso sistema. Até logo!"
 
+	MSG_DEPENDENCY_OUTAGE = "🚧 O UNM e o ERP estão fora do ar no momento, nenhum provisionamento ou consulta pode ser concluído.\n" +
+		"O assistente volta a funcionar sozinho assim que os sistemas forem restabelecidos."
+	MSG_DEPENDENCY_OUTAGE_BANNER    = "🚧 O UNM e o ERP estão fora do ar, provisionamentos e consultas estão suspensos até o restabelecimento.\n\n"
+	MSG_CIRCUIT_OPEN_BANNER         = "🚧 O provisionamento automático está suspenso por falhas recorrentes. Encaminhe as ativações ao NOC.\n\n"
+	MSG_AUTO_PROVISIONING_SUSPENDED = "🚧 O provisionamento automático está temporariamente suspenso devido a falhas recorrentes na OLT ou no ERP.\n" +
+		"Encaminhe esta ativação ao NOC para escalonamento manual."
+	MSG_ERP_DEGRADED_BANNER = "⚠️ As consultas ao ERP estão instáveis no momento e a busca por protocolo pode falhar.\n\n"
+
+	// Manual provisioning messages
+	MSG_MANUAL_NOT_ALLOWED      = "⛔ Você não tem permissão para realizar o provisionamento manual."
+	MSG_MANUAL_REQUEST_SERIAL   = "🛠️ Provisionamento manual\n\n📟 Informe o serial da ONU:"
+	MSG_MANUAL_SERIAL_INVALID   = "❌ Serial inválido. Informe de 8 a 16 caracteres alfanuméricos:"
+	MSG_MANUAL_REQUEST_OLT      = "🖥️ Informe o IP da OLT:"
+	MSG_MANUAL_OLT_INVALID      = "❌ IP da OLT inválido. Informe um endereço IP válido:"
+	MSG_MANUAL_OLT_SUGGESTED    = "🖥️ Escolha a OLT abaixo ou informe o IP:"
+	MSG_MANUAL_OLT_OPTION       = "🖥️ %s"
+	MSG_MANUAL_REQUEST_SLOT     = "🔢 Informe o slot da OLT (ou slot/porta, ex.: 1/4):"
+	MSG_MANUAL_SLOT_INVALID     = "❌ Slot inválido. Informe um número entre 1 e %d, ex.: 1 ou S1:"
+	MSG_MANUAL_REQUEST_PORT     = "🔌 Informe a porta PON:"
+	MSG_MANUAL_PORT_INVALID     = "❌ Porta inválida. Informe um número entre 1 e %d, ex.: 4 ou P4:"
+	MSG_MANUAL_REQUEST_VLAN     = "🏷️ Informe a VLAN do cliente:"
+	MSG_MANUAL_VLAN_INVALID     = "❌ VLAN inválida. Informe um número entre 1 e 4094:"
+	MSG_MANUAL_REQUEST_PPPOE    = "👤 Informe o usuário PPPoE do cliente:"
+	MSG_MANUAL_PPPOE_INVALID    = "❌ Usuário PPPoE inválido. Não utilize espaços:"
+	MSG_MANUAL_REQUEST_PASSWORD = "🔑 Informe a senha PPPoE do cliente:"
+	MSG_MANUAL_PASSWORD_INVALID = "❌ Senha PPPoE inválida. Não utilize espaços:"
+
+	MSG_MANUAL_UNDO             = "↩️ Corrigir último campo"
+	MSG_MANUAL_UNDONE           = "↩️ Valor de %s descartado, informe novamente."
+	MSG_MANUAL_FIELD_SERIAL     = "serial"
+	MSG_MANUAL_FIELD_OLT        = "OLT"
+	MSG_MANUAL_FIELD_SLOT       = "slot"
+	MSG_MANUAL_FIELD_PORT       = "porta PON"
+	MSG_MANUAL_FIELD_VLAN       = "VLAN"
+	MSG_MANUAL_FIELD_PPPOE_USER = "usuário PPPoE"
+	MSG_MANUAL_CONFIRM_TITLE    = "📋 Confirme os dados do provisionamento manual:"
+	MSG_MANUAL_CONFIRM_QUESTION = "Você confirma os dados?"
+
 	// Protocol messages
 	MSG_REQUEST_PROTOCOL   = "📄 Por favor, informe o número do protocolo da solicitação:"
 	MSG_PROTOCOL_INVALID   = "❌ Protocolo inválido. Por favor, digite apenas números:"
 	MSG_SEARCHING_INFO     = "🔍 Buscando informações da solicitação..."
+	MSG_ERP_SLOW           = "🐢 ERP lento no momento, aguarde. A consulta pode levar cerca de %d segundos..."
 	MSG_PROTOCOL_NOT_FOUND = "❌ Não foi possível encontrar a solicitação.\n" +
 		"Verifique o número do protocolo e tente novamente:"
 
-	// Confirmation messages
-	MSG_CONFIRM_DATA = "📋 Confirme os dados da solicitação:\n\n" +
+	// Pasted text messages
+	MSG_PASTE_RECOGNIZED       = "📋 Identifiquei no texto colado:\n\n"
+	MSG_PASTE_PROTOCOL         = "📄 Protocolo: %s\n"
+	MSG_PASTE_SERIAL           = "📟 Serial ONU: %s\n"
+	MSG_PASTE_OLT              = "🖥️ OLT: %s\n"
+	MSG_PASTE_SLOT             = "🔢 Slot: %s\n"
+	MSG_PASTE_PORT             = "🔌 Porta PON: %s\n"
+	MSG_PASTE_VLAN             = "🏷️ VLAN: %s\n"
+	MSG_PASTE_PPPOE_USER       = "👤 Usuário PPPoE: %s\n"
+	MSG_PASTE_PPPOE_PASSWORD   = "🔑 Senha PPPoE: %s\n"
+	MSG_PASTE_SPLITTER         = "🔲 CTO: %s\n"
+	MSG_PASTE_SPLITTER_PORT    = "🔌 Porta CTO: %s\n"
+	MSG_PASTE_HIDDEN           = "******"
+	MSG_PASTE_PROTOCOL_MISSING = "❓ Não encontrei o número do protocolo no texto colado. Por favor, informe o protocolo da solicitação:"
+	MSG_PASTE_SERIAL_MISMATCH  = "⚠️ O serial colado (%s) difere do cadastrado no ERP (%s). Será considerado o serial do ERP."
+
+	// PPPoE search messages
+	MSG_REQUEST_PPPOE_SEARCH   = "🔎 Informe o usuário PPPoE do cliente:"
+	MSG_PPPOE_SEARCH_USAGE     = "🔎 Uso: /pppoe <usuário PPPoE>"
+	MSG_PPPOE_SEARCH_INVALID   = "❌ Usuário PPPoE inválido. Informe o login sem espaços:"
+	MSG_PPPOE_SEARCHING        = "🔍 Buscando conexão do cliente..."
+	MSG_PPPOE_SEARCH_NOT_FOUND = "❌ Nenhuma conexão encontrada para este usuário PPPoE.\n" +
+		"Verifique o login e tente novamente:"
+	MSG_PPPOE_SEARCH_RESULT = "🔎 Conexão localizada:\n\n" +
+		"👤 Usuário PPPoE: %s\n" +
+		"🙍 Cliente: %s\n" +
 		"📄 Contrato: %s\n" +
-		"📝 Solicitação: %s\n" +
+		"📦 Plano: %s\n" +
 		"📟 Serial ONU: %s\n" +
-		"🔲 CTO: %s\n" +
-		"🔌 Porta CTO: %s\n\n" +
-		"Você confirma os dados da solicitação?"
+		"🌐 OLT: %s\n" +
+		"🔢 Slot/PON: %s/%s\n\n"
+	MSG_PPPOE_SEARCH_ONLINE  = "🟢 Status: online\n"
+	MSG_PPPOE_SEARCH_OFFLINE = "🔴 Status: sem resposta da ONU (offline ou inacessível)"
+	MSG_PPPOE_SEARCH_AGAIN   = "🔎 Nova busca"
+
+	// Confirmation messages
+	MSG_CONFIRM_TITLE    = "📋 Confirme os dados da solicitação:"
+	MSG_CONFIRM_QUESTION = "Você confirma os dados da solicitação?"
+	MSG_CONFIRM_FIELD    = "%s: %s\n"
+
+	MSG_CONFIRM_CONTRACT      = "📄 Contrato"
+	MSG_CONFIRM_ADDRESS       = "📍 Endereço"
+	MSG_CONFIRM_PHONE         = "📞 Telefone"
+	MSG_CONFIRM_PLAN          = "📦 Plano"
+	MSG_CONFIRM_ASSIGNMENT    = "📝 Solicitação"
+	MSG_CONFIRM_SERIAL        = "📟 Serial ONU"
+	MSG_CONFIRM_SPLITTER      = "🔲 CTO"
+	MSG_CONFIRM_SPLITTER_PORT = "🔌 Porta CTO"
+	MSG_CONFIRM_EXTRA_WAN     = "📺 Serviços adicionais"
+	MSG_CONFIRM_OLT           = "🖥️ OLT"
+	MSG_CONFIRM_SLOT          = "🔢 Slot"
+	MSG_CONFIRM_PON_PORT      = "🔌 Porta PON"
+	MSG_CONFIRM_VLAN          = "🏷️ VLAN"
+	MSG_CONFIRM_PPPOE_USER    = "👤 Usuário PPPoE"
+
+	MSG_CONFIRM_YES  = "✅ Sim"
+	MSG_CONFIRM_NO   = "❌ Não"
+	MSG_CONFIRM_EDIT = "✏️ Corrigir"
 
-	MSG_CONFIRM_YES = "✅ Sim"
-	MSG_CONFIRM_NO  = "❌ Não"
+	MSG_CONFIRM_EXPIRED = "⌛ A confirmação expirou. Confira os dados novamente antes de prosseguir."
 
 	MSG_CONFIRMATION_DENIED = "❌ Infelizmente não é possível continuar por aqui.\n\n" +
 		"Por favor, entre em contato com o gerenciamento de campo para atualização das informações " +
 		"ou provisionamento manual do equipamento."
 
+	// Protocol pre-check messages
+	MSG_PROTOCOL_WARNING_CLOSED      = "⚠️ A solicitação está encerrada no ERP (status: %s).\n"
+	MSG_PROTOCOL_WARNING_CANCELLED   = "⚠️ A solicitação foi cancelada no ERP (status: %s).\n"
+	MSG_PROTOCOL_WARNING_PROVISIONED = "⚠️ Este protocolo já foi provisionado em %s por %s (/auditoria_%s).\n"
+	MSG_PROTOCOL_OVERRIDE_SELF       = "Como supervisor, ao confirmar você autoriza o provisionamento mesmo assim.\n\n"
+	MSG_PROTOCOL_OVERRIDE_GRANTED    = "✅ Provisionamento liberado por %s.\n\n"
+	MSG_PROTOCOL_OVERRIDE_REQUESTED  = "%s\n🔒 É necessária a liberação de um supervisor. " +
+		"A solicitação foi enviada e você será avisado aqui assim que houver uma decisão."
+	MSG_PROTOCOL_OVERRIDE_WAITING = "⏳ Aguardando a liberação de um supervisor para o protocolo %s."
+	MSG_PROTOCOL_OVERRIDE_REQUEST = "🔒 Liberação de protocolo solicitada\n\n" +
+		"👷 Técnico: %s\n" +
+		"📄 Protocolo: %s\n" +
+		"📄 Contrato: %s\n\n" +
+		"%s"
+	MSG_PROTOCOL_OVERRIDE_APPROVE     = "✅ Liberar"
+	MSG_PROTOCOL_OVERRIDE_REJECT      = "❌ Recusar"
+	MSG_PROTOCOL_OVERRIDE_REJECTED    = "❌ %s recusou o provisionamento do protocolo %s."
+	MSG_PROTOCOL_OVERRIDE_DECIDED     = "👍 Decisão registrada para o protocolo %s."
+	MSG_PROTOCOL_OVERRIDE_EXPIRED     = "⌛ Esta solicitação de liberação não está mais pendente."
+	MSG_PROTOCOL_OVERRIDE_SELF_DENIED = "❌ Você não pode liberar a sua própria solicitação."
+
 	// Provisioning messages
-	MSG_PROVISIONING_START = "⏳ Aguarde enquanto estamos provisionando o equipamento..."
+	MSG_PROVISIONING_START     = "⏳ Aguarde enquanto estamos provisionando o equipamento..."
+	MSG_PROVISIONING_IN_FLIGHT = "⏳ Este protocolo já está sendo provisionado com o mesmo serial neste momento. " +
+		"Aguarde a conclusão antes de tentar novamente."
+	MSG_PROVISIONING_REPLAYED = "♻️ Este protocolo já foi provisionado com o mesmo serial em %s por %s (%s).\n" +
+		"Os comandos não foram reenviados à OLT, segue o resultado anterior."
+	MSG_QUEUE_WAITING  = "🕒 Há %d provisionamento(s) na sua frente. Assim que chegar a sua vez o provisionamento começa automaticamente."
+	MSG_QUEUE_TIMEOUT  = "⌛ A fila de provisionamento está muito longa no momento. Tente novamente em alguns minutos."
+	MSG_QUEUE_DRAINING = "🔄 O assistente está reiniciando e não aceita novos provisionamentos agora. Tente novamente em alguns minutos."
+	MSG_VIP_BADGE      = "⭐ Contrato VIP, provisionamento com prioridade.\n\n"
+
+	// Provisioning queue messages
+	MSG_QUEUE_EMPTY          = "📭 Nenhum provisionamento em andamento ou na fila."
+	MSG_QUEUE_HEADER         = "🕒 Fila de provisionamento\n\n⚙️ Em execução: %d de %d vaga(s)\n⏳ Aguardando: %d\n"
+	MSG_QUEUE_RUNNING        = "\n⚙️ Em execução\n"
+	MSG_QUEUE_WAITING_HEADER = "\n⏳ Aguardando\n"
+	MSG_QUEUE_ITEM           = "• %sprotocolo %s - %s (%s) há %s\n"
+	MSG_QUEUE_VIP            = "⭐ "
 
 	MSG_PROVISIONING_FAILED = "❌ Falha no provisionamento.\n\nErro: %v\n\n" +
 		"Por favor, tente novamente ou entre em contato com o suporte."
+	MSG_PROVISIONING_PREVIOUS_RESTORED = "\n\n♻️ A configuração anterior da ONU foi restaurada na OLT."
+	MSG_PROVISIONING_PREVIOUS_LOST     = "\n\n⚠️ Não foi possível restaurar a configuração anterior da ONU. A equipe de operações foi avisada."
+	MSG_PROVISIONING_PARTIAL_REMOVED   = "\n\n♻️ A configuração parcial da ONU foi removida da OLT."
+	MSG_PROVISIONING_PARTIAL_LEFT      = "\n\n⚠️ A configuração parcial da ONU não pôde ser removida da OLT. A equipe de operações foi avisada."
 
 	MSG_PROVISIONING_SUCCESS = "✅ Equipamento provisionado com sucesso!\n\n" +
 		"📄 Contrato: %s\n" +
 		"📟 Serial: %s\n" +
 		"📶 Status: ONLINE\n"
 
+	MSG_PROVISIONING_RECONFIGURED = "♻️ ONU já autorizada para este contrato, apenas os serviços foram reaplicados\n"
+
 	MSG_SIGNAL_INFO = "📡 Informações:\n" +
-		"➡️ Pot. de recepção (dBm): %s dBm\n" +
-		"⬅️ Pot. de transmissão (-dBm): %s dBm\n" +
-		"🔋 Voltagem: %s V\n" +
-		"🌡️ Temperatura: %s ºC\n"
+		"➡️ Pot. de recepção: %s\n" +
+		"⬅️ Pot. de transmissão: %s\n" +
+		"🔋 Voltagem: %s\n" +
+		"🌡️ Temperatura: %s\n"
+	MSG_SIGNAL_WARNING  = "⚠️ Recepção abaixo do recomendado para esta OLT (alerta abaixo de %s)\n"
+	MSG_SIGNAL_CRITICAL = "🚨 Recepção crítica para esta OLT (limite de %s), verifique conectores e fusões\n"
+
+	MSG_PROVISIONING_ELAPSED = "⏱️ Tempo total: %s s\n"
+
+	MSG_SUCCESS_CLIENT      = "👤 Cliente: %s\n"
+	MSG_SUCCESS_SPLITTER    = "🔀 CTO: %s, porta %s\n"
+	MSG_SUCCESS_CREDENTIALS = "🔐 PPPoE:\n" +
+		"👤 Usuário: %s\n" +
+		"🔑 Senha: %s\n" +
+		"🏷️ VLAN: %s\n"
 
 	MSG_EQUIPMENT_READY = "\nO equipamento está pronto para uso!"
+
+	MSG_WAN_SERVICE_ITEM      = "%s (VLAN %s)"
+	MSG_WAN_SERVICES_HEADER   = "🌐 Serviços WAN:\n"
+	MSG_WAN_SERVICE_OK        = "✅ %s (VLAN %s)\n"
+	MSG_WAN_SERVICE_FAILED    = "❌ %s (VLAN %s): %s\n"
+	MSG_WAN_SERVICE_PRIMARY   = "Internet"
+	MSG_WAN_SERVICE_MULTICAST = "IPTV multicast"
+	MSG_WAN_SERVICE_UNNAMED   = "Serviço adicional"
+	MSG_WAN_SERVICES_REVIEW   = "⚠️ Nem todos os serviços WAN foram configurados, acione o NOC para concluir os que falharam.\n"
+
+	MSG_SUCCESS_COMPACT         = "✅ Contrato %s provisionado, ONU %s online\n"
+	MSG_SUCCESS_COMPACT_SIGNAL  = "📡 Recepção: %s\n"
+	MSG_SIGNAL_ALERT_TECHNICIAN = "📶 Alerta de sinal da sua instalação\n\n📄 Contrato: %s\n📟 Serial: %s\n➡️ Recepção: %s\n"
+
+	// Success message in English, for the technicians who chose it with /config
+	MSG_EN_PROVISIONING_SUCCESS = "✅ Equipment provisioned successfully!\n\n" +
+		"📄 Contract: %s\n" +
+		"📟 Serial: %s\n" +
+		"📶 Status: ONLINE\n"
+	MSG_EN_PROVISIONING_RECONFIGURED = "♻️ ONU already authorized for this contract, only the services were applied again\n"
+	MSG_EN_SIGNAL_INFO               = "📡 Readings:\n" +
+		"➡️ Rx power: %s\n" +
+		"⬅️ Tx power: %s\n" +
+		"🔋 Voltage: %s\n" +
+		"🌡️ Temperature: %s\n"
+	MSG_EN_SIGNAL_WARNING       = "⚠️ Reception below the recommended level for this OLT (warning below %s)\n"
+	MSG_EN_SIGNAL_CRITICAL      = "🚨 Critical reception for this OLT (limit %s), check the connectors and splices\n"
+	MSG_EN_PROVISIONING_ELAPSED = "⏱️ Total time: %s s\n"
+	MSG_EN_SUCCESS_CLIENT       = "👤 Customer: %s\n"
+	MSG_EN_SUCCESS_SPLITTER     = "🔀 Splitter box: %s, port %s\n"
+	MSG_EN_SUCCESS_CREDENTIALS  = "🔐 PPPoE:\n" +
+		"👤 User: %s\n" +
+		"🔑 Password: %s\n" +
+		"🏷️ VLAN: %s\n"
+	MSG_EN_EQUIPMENT_READY         = "\nThe equipment is ready to use!"
+	MSG_EN_WAN_SERVICES_HEADER     = "🌐 WAN services:\n"
+	MSG_EN_WAN_SERVICE_UNNAMED     = "Additional service"
+	MSG_EN_WAN_SERVICES_REVIEW     = "⚠️ Not every WAN service was configured, ask the NOC to finish the failed ones.\n"
+	MSG_EN_SUCCESS_COMPACT         = "✅ Contract %s provisioned, ONU %s online\n"
+	MSG_EN_SUCCESS_COMPACT_SIGNAL  = "📡 Reception: %s\n"
+	MSG_EN_SIGNAL_ALERT_TECHNICIAN = "📶 Signal alert for your install\n\n📄 Contract: %s\n📟 Serial: %s\n➡️ Reception: %s\n"
+
+	// Signal re-check messages
+	MSG_RECHECK_SIGNAL  = "📶 Verificar sinal novamente"
+	MSG_RECHECK_HEADER  = "📶 Sinal atual da ONU %s (contrato %s):\n\n"
+	MSG_RECHECK_FAILED  = "❌ Não foi possível consultar o sinal da ONU agora. Tente novamente em instantes."
+	MSG_RECHECK_EXPIRED = "⌛ A verificação rápida não está mais disponível para o último provisionamento."
+
+	// Wi-Fi scan messages
+	MSG_WIFI_SCAN             = "📡 Redes Wi-Fi vizinhas"
+	MSG_WIFI_SCAN_HEADER      = "📡 Redes Wi-Fi vistas pela ONU %s: %d\n"
+	MSG_WIFI_SCAN_BAND        = "\n📶 %s\n"
+	MSG_WIFI_SCAN_BAND_EMPTY  = "• Nenhuma rede encontrada\n"
+	MSG_WIFI_SCAN_CHANNEL     = "• Canal %d: %d rede(s), mais forte %d dBm\n"
+	MSG_WIFI_SCAN_RECOMMENDED = "👉 Canal sugerido: %d\n"
+	MSG_WIFI_SCAN_UNSUPPORTED = "ℹ️ Este modelo de ONU não permite consultar as redes Wi-Fi vizinhas."
+	MSG_LOAD_SHED_DEFERRED    = "🚦 O UNM está sobrecarregado e as consultas não essenciais estão pausadas para não atrasar as ativações. Tente novamente em alguns minutos."
+	MSG_WIFI_SCAN_FAILED      = "❌ Não foi possível consultar as redes Wi-Fi vizinhas agora. Tente novamente em instantes."
+
+	// Inline query messages
+	MSG_INLINE_UNAUTHORIZED_TITLE = "🔒 Acesso não autorizado"
+	MSG_INLINE_UNAUTHORIZED       = "Identifique-se com o bot em uma conversa privada antes de usar consultas rápidas."
+	MSG_INLINE_USAGE_TITLE        = "ℹ️ Consulta rápida"
+	MSG_INLINE_USAGE              = "Digite um protocolo ou o serial da ONU para ver o sinal atual."
+	MSG_INLINE_NOT_ALLOWED        = "Você não tem permissão para consultar este equipamento."
+	MSG_INLINE_NOT_FOUND          = "Nenhum equipamento encontrado para %s."
+	MSG_INLINE_SIGNAL_TITLE       = "📶 ONU %s"
+	MSG_INLINE_SIGNAL_DESCRIPTION = "Contrato %s | OLT %s %s/%s"
+	MSG_INLINE_OFFLINE            = "⚠️ A ONU %s não respondeu à consulta de sinal (offline ou inacessível)."
+
+	// Command messages
+	MSG_COMMAND_AUTH_REQUIRED = "🔒 Identifique-se com seu CPF antes de usar este comando. Digite /start."
+	MSG_COMMAND_NOT_ALLOWED   = "⛔ Você não tem permissão para usar este comando."
+
+	// Feature flag messages
+	MSG_FEATURE_USAGE       = "🧩 Uso: /recurso [<nome> on|off] ou /recurso <etapa> <percentual>% [<ip da OLT>]"
+	MSG_FEATURE_LIST_HEADER = "🧩 Recursos:\n\n"
+	MSG_FEATURE_LIST_ITEM   = "• %s: %s\n"
+	MSG_FEATURE_LIST_EMPTY  = "🧩 Nenhum recurso configurado."
+	MSG_FEATURE_UPDATED     = "✅ Recurso %s %s."
+	MSG_FEATURE_FAILED      = "❌ Não foi possível atualizar o recurso: %v"
+	MSG_FEATURE_ON          = "ativado"
+	MSG_FEATURE_OFF         = "desativado"
+
+	// Rollout messages
+	MSG_ROLLOUT_LIST_HEADER = "\n🚦 Lançamentos graduais:\n\n"
+	MSG_ROLLOUT_LIST_ITEM   = "• %s: %d%%%s\n"
+	MSG_ROLLOUT_OLT         = ", OLT %s: %d%%"
+	MSG_ROLLOUT_ERROR_RATE  = "   Erros: %s em %d job(s), reverte acima de %s\n"
+	MSG_ROLLOUT_ROLLED_BACK = "• %s: ↩️ revertido ao caminho antigo\n"
+	MSG_ROLLOUT_UPDATED     = "✅ Etapa %s liberada para %d%% dos jobs%s."
+	MSG_ROLLOUT_UPDATED_OLT = " na OLT %s"
+	MSG_ROLLOUT_FAILED      = "❌ Não foi possível atualizar o lançamento gradual: %v"
+
+	// Training sandbox messages
+	MSG_TRAINING_BANNER      = "🎓 Modo treinamento: os comandos vão para o simulador do UNM e nenhuma OLT é alterada.\n\n"
+	MSG_TRAINING_USAGE       = "🎓 Uso: /treinamento [<id do Telegram> on|off]"
+	MSG_TRAINING_ENABLED     = "🎓 Usuário %d no modo treinamento."
+	MSG_TRAINING_DISABLED    = "✅ Usuário %d de volta ao modo real."
+	MSG_TRAINING_FAILED      = "❌ Não foi possível atualizar o modo treinamento: %v"
+	MSG_TRAINING_LIST_HEADER = "🎓 Usuários em treinamento:\n\n"
+	MSG_TRAINING_LIST_ITEM   = "• %d\n"
+	MSG_TRAINING_LIST_EMPTY  = "🎓 Nenhum usuário em treinamento."
+
+	// Raw TL1 console messages
+	MSG_TL1_USAGE            = "🖥️ Uso: /tl1 [<endpoint>]"
+	MSG_TL1_CHOOSE_ENDPOINT  = "🖥️ Console TL1\n\nEscolha o endpoint do UNM:"
+	MSG_TL1_UNKNOWN_ENDPOINT = "❌ Endpoint %q desconhecido. Disponíveis: %s"
+	MSG_TL1_OPENED           = "🖥️ Console TL1 aberto em %s\n\n" +
+		"Envie um comando por mensagem, ex.: LST-ONU::OLTID=10.0.0.1,PONID=NA-NA-1-1:CTAG::;\n" +
+		"Verbos permitidos: %s\n" +
+		"Todos os comandos são auditados. Digite \"sair\" para encerrar."
+	MSG_TL1_EXIT_WORD    = "sair"
+	MSG_TL1_CLOSED       = "✅ Console TL1 encerrado."
+	MSG_TL1_INVALID      = "❌ %v\n\nVerbos permitidos: %s\nEnvie outro comando ou \"sair\":"
+	MSG_TL1_DESCRIPTION  = "Comando TL1 pelo console (%s)"
+	MSG_TL1_RESPONSE     = "📟 Resposta de %s:\n\n%s"
+	MSG_TL1_FAILED       = "❌ Falha no comando TL1: %v"
+	MSG_TL1_TRUNCATED    = "\n\n✂️ Resposta truncada."
+	MSG_TL1_TRANSCRIPT   = "\n📎 Resposta completa guardada em %s."
+	MSG_TL1_ADMIN_NOTICE = "🖥️ Console TL1\n\n%s (%d) executou em %s:\n%s"
+
+	// Service-account token messages
+	MSG_TOKEN_USAGE = "🔑 Uso:\n" +
+		"/token criar <nome> <escopos separados por vírgula>\n" +
+		"/token listar\n" +
+		"/token revogar <id>\n\n" +
+		"Escopos: read:onu, write:provision, read:reports"
+	MSG_TOKEN_CREATED = "🔑 Token criado!\n\n" +
+		"🆔 ID: %s\n" +
+		"📛 Nome: %s\n" +
+		"🔐 Escopos: %s\n\n" +
+		"%s\n\n" +
+		"⚠️ Guarde o token agora, ele não será exibido novamente."
+	MSG_TOKEN_LIST_EMPTY     = "Nenhum token emitido."
+	MSG_TOKEN_LIST_HEADER    = "🔑 Tokens de conta de serviço:\n\n"
+	MSG_TOKEN_LIST_ITEM      = "🆔 %s | %s | %s | %s\n"
+	MSG_TOKEN_STATUS_ACTIVE  = "ativo"
+	MSG_TOKEN_STATUS_REVOKED = "revogado"
+	MSG_TOKEN_REVOKED        = "✅ Token %s revogado."
+	MSG_TOKEN_FAILED         = "❌ Falha na operação do token: %v"
+
+	// Destructive operation messages
+	MSG_OPERATION_CANCEL_WORD    = "cancelar"
+	MSG_OPERATION_CONFIRM_PHRASE = "⚠️ Operação com impacto em múltiplas ONUs\n\n" +
+		"📝 %s\n" +
+		"💻 %s\n\n" +
+		"Para continuar, digite exatamente:\n%s\n\n" +
+		"Depois da confirmação, um supervisor precisa aprovar a execução. Digite \"cancelar\" para desistir."
+	MSG_OPERATION_PHRASE_MISMATCH   = "❌ Frase incorreta. Digite exatamente \"%s\" ou \"cancelar\":"
+	MSG_OPERATION_CANCELLED         = "✅ Operação cancelada."
+	MSG_OPERATION_EXPIRED           = "⌛ A operação expirou ou não está mais disponível."
+	MSG_OPERATION_AWAITING_APPROVAL = "⏳ Confirmação registrada. Aguardando aprovação de um supervisor."
+	MSG_OPERATION_APPROVAL_REQUEST  = "🛑 Aprovação necessária\n\n" +
+		"👤 Solicitante: %s\n" +
+		"📝 %s\n" +
+		"💻 %s\n\n" +
+		"Esta operação afeta mais de uma ONU."
+	MSG_OPERATION_APPROVE         = "✅ Aprovar"
+	MSG_OPERATION_REJECT          = "⛔ Rejeitar"
+	MSG_OPERATION_DECISION_FAILED = "❌ Não foi possível registrar a decisão: %v"
+	MSG_OPERATION_DECIDED         = "✅ Decisão registrada para a operação %s."
+	MSG_OPERATION_REJECTED        = "⛔ A operação \"%s\" foi rejeitada por %s."
+	MSG_OPERATION_DONE            = "✅ Operação \"%s\" executada (aprovada por %s)."
+	MSG_OPERATION_FAILED          = "❌ Falha ao executar a operação \"%s\": %v"
+
+	// Backup messages
+	MSG_BACKUP_USAGE = "💾 Uso:\n" +
+		"/backup - exporta vínculos, auditoria, tokens e o estado do bot para um arquivo criptografado\n" +
+		"/restaurar <arquivo> - importa um backup exportado anteriormente"
+	MSG_BACKUP_CREATED = "💾 Backup criado!\n\n" +
+		"📄 Arquivo: %s\n" +
+		"👤 Vínculos: %d\n" +
+		"📋 Registros de auditoria: %d\n" +
+		"🔑 Tokens: %d\n" +
+		"⚙️ Estado: %d"
+	MSG_BACKUP_RESTORED = "✅ Backup de %s restaurado!\n\n" +
+		"👤 Vínculos: %d\n" +
+		"📋 Registros de auditoria: %d\n" +
+		"🔑 Tokens: %d\n" +
+		"⚙️ Estado: %d"
+	MSG_BACKUP_FAILED = "❌ Falha na operação de backup: %v"
+	MSG_BACKUP_LINK   = "\n\n🔗 Download: %s\n⌛ Link válido até %s"
+
+	// Artifact storage messages
+	MSG_STORAGE_HEADER  = "🗄️ Armazenamento de artefatos\n"
+	MSG_STORAGE_ITEM    = "\n• %s: %d arquivo(s), %s\n   Retenção: %s\n"
+	MSG_STORAGE_QUOTA   = "%s de %s (%s da cota)"
+	MSG_STORAGE_OLDEST  = "   Mais antigo: %s\n"
+	MSG_STORAGE_TOTAL   = "\nTotal: %d arquivo(s), %s"
+	MSG_STORAGE_DAYS    = "%d dia(s)"
+	MSG_STORAGE_FOREVER = "indefinida"
+	MSG_STORAGE_EMPTY   = "\nNenhum artefato armazenado."
+	MSG_STORAGE_FAILED  = "❌ Não foi possível ler o armazenamento de artefatos: %v"
+
+	// Provisioning template catalog messages
+	MSG_TEMPLATES_USAGE = "📐 Uso:\n" +
+		"/templates - lista os templates de provisionamento em uso\n" +
+		"/templates exportar - exporta os templates para um arquivo JSON\n" +
+		"/templates importar <arquivo> - compara um arquivo exportado com os templates em uso\n" +
+		"/templates importar <arquivo> aplicar - substitui os templates em uso pelos do arquivo"
+	MSG_TEMPLATES_LIST_HEADER = "📐 Templates de provisionamento:\n\n"
+	MSG_TEMPLATES_LIST_ITEM   = "• %s%s: VLAN %s, WAN %s%s\n"
+	MSG_TEMPLATES_MULTICAST   = ", multicast VLAN %s"
+	MSG_TEMPLATES_LIST_EMPTY  = "📐 Nenhum template de provisionamento configurado."
+	MSG_TEMPLATES_DEFAULT     = " (padrão)"
+	MSG_TEMPLATES_UNSET       = "-"
+	MSG_TEMPLATES_EXPORTED    = "📐 Templates exportados!\n\n" +
+		"📄 Arquivo: %s\n" +
+		"📋 Templates: %d"
+	MSG_TEMPLATES_DIFF_HEADER = "📐 Simulação da importação de %s (exportado em %s):\n\n"
+	MSG_TEMPLATES_DIFF_ADDED  = "➕ %s\n"
+	MSG_TEMPLATES_DIFF_REMOVE = "➖ %s\n"
+	MSG_TEMPLATES_DIFF_CHANGE = "✏️ %s: %s\n"
+	MSG_TEMPLATES_DIFF_APPLY  = "\nPara aplicar, envie /templates importar %s aplicar"
+	MSG_TEMPLATES_UNCHANGED   = "📐 O arquivo %s é igual aos templates em uso."
+	MSG_TEMPLATES_IMPORTED    = "✅ Templates de %s importados! %d alterações aplicadas."
+	MSG_TEMPLATES_FAILED      = "❌ Falha na operação de templates: %v"
+
+	// Status and scheduled job messages
+	MSG_STATUS_HEADER         = "📊 Status do assistente\n\n"
+	MSG_STATUS_SAFE_MODE      = "🛟 Modo de segurança: recursos desativados por configuração inválida\n"
+	MSG_STATUS_SAFE_MODE_ITEM = "• %s (%s): %s\n"
+	MSG_STATUS_ERP_OK         = "🗄️ ERP: normal\n"
+	MSG_STATUS_ERP_DEGRADED   = "🗄️ ERP: degradado\n"
+	MSG_STATUS_ERP_SLOW       = "🗄️ ERP: lento\n"
+	MSG_STATUS_ERP_LATENCY    = "⏱️ Latência do ERP: mediana %s, p95 %s (timeout atual %s)\n"
+	MSG_STATUS_CIRCUIT_OK     = "⚙️ Provisionamento automático: ativo (%s de sucesso)\n"
+	MSG_STATUS_CIRCUIT_OPEN   = "⚙️ Provisionamento automático: suspenso (%s de sucesso)\n"
+	MSG_STATUS_TL1_WATCHDOG   = "🔌 Reconexões TL1 forçadas por travamento: %d\n"
+	MSG_STATUS_LOAD_OK        = "🚦 Carga do UNM: normal (p95 TL1 %s, %d na fila)\n"
+	MSG_STATUS_LOAD_SHEDDING  = "🚦 Carga do UNM: saturado desde %s, operações não críticas adiadas\n   Motivo: %s\n"
+	MSG_STATUS_LOAD_DISABLED  = "🚦 Adiamento por carga do UNM: desativado\n"
+	MSG_STATUS_LOAD_DEFERRED  = "   Adiadas: %s\n"
+	MSG_STATUS_LOAD_DECISION  = "   • %s %s: %s\n"
+	MSG_STATUS_PROBE_UP       = "📡 Sonda TL1 %s: respondendo (%s das últimas %d, p95 %s)\n"
+	MSG_STATUS_PROBE_DOWN     = "📡 Sonda TL1 %s: sem resposta desde %s (%s das últimas %d)\n   Erro: %s\n"
+	MSG_STATUS_JOBS_HEADER    = "\n⏰ Tarefas agendadas:\n"
+	MSG_STATUS_JOBS_EMPTY     = "Nenhuma tarefa agendada."
+	MSG_STATUS_JOB_ITEM       = "\n• %s (%s) %s\n" +
+		"   Última execução: %s\n" +
+		"   Próxima execução: %s\n"
+	MSG_STATUS_JOB_ERROR    = "   ⚠️ Último erro: %s\n"
+	MSG_STATUS_JOB_ENABLED  = "✅"
+	MSG_STATUS_JOB_DISABLED = "⏸️"
+	MSG_STATUS_JOB_RUNNING  = "em execução"
+	MSG_STATUS_JOB_NEVER    = "nunca"
+	MSG_STATUS_JOB_OTHER    = "em outra réplica"
+	MSG_STATUS_JOB_WORKER   = "no processo worker"
+
+	MSG_ALERTS_HEADER       = "🔔 Alertas avaliados nesta réplica\n"
+	MSG_ALERTS_ITEM         = "\n%s %s\n   %s\n   Condição: %s (valor atual %s)\n"
+	MSG_ALERTS_OK           = "✅"
+	MSG_ALERTS_FIRING       = "🔥"
+	MSG_ALERTS_NONE_FIRING  = "\nNenhum alerta disparando agora."
+	MSG_ALERTS_FIRING_COUNT = "\n%d alerta(s) disparando agora, o Prometheus só notifica após a duração de cada regra."
+
+	MSG_ERROR_REPORT  = "🐞 Erro não tratado em %s\n\n%v\n"
+	MSG_ERROR_REPEATS = "\n🔁 Repetido %d vez(es) desde o último aviso.\n"
+	MSG_ERROR_STACK   = "\n%s"
+
+	MSG_VERSION = "🏷️ Versão em execução\n\n" +
+		"Versão: %s\n" +
+		"Commit: %s\n" +
+		"Configuração: %s\n"
+	MSG_VERSION_MODIFIED = "\n⚠️ Build gerado com alterações não commitadas."
+	MSG_VERSION_UNSET    = "não informado"
+
+	// Changelog messages
+	MSG_CHANGELOG_HEADER  = "🆕 O que mudou no assistente\n"
+	MSG_CHANGELOG_RELEASE = "\nVersão %s\n"
+	MSG_CHANGELOG_ITEM    = "• %s\n"
+
+	MSG_AUDIT_DIGEST = "📋 Resumo das últimas 24h\n\n" +
+		"✅ Provisionamentos com sucesso: %d\n" +
+		"❌ Provisionamentos com falha: %d\n" +
+		"🛠️ Provisionamentos manuais: %d\n" +
+		"📷 Registros sem fotos: %d\n" +
+		"⚠️ Ativações com sinal abaixo do recomendado: %d\n" +
+		"🚨 Ativações com sinal crítico: %d"
+
+	// Conversation flow report messages
+	MSG_FLOW_HEADER      = "🧭 Etapas do atendimento desde %s\n\n"
+	MSG_FLOW_EMPTY       = "🧭 Nenhuma etapa registrada desde %s."
+	MSG_FLOW_STATE       = "• %s: %d entradas, tempo médio %s (máx. %s), %s entradas inválidas por entrada, %d abandonos\n"
+	MSG_FLOW_TRANSITIONS = "\n🔀 Transições mais frequentes:\n"
+	MSG_FLOW_TRANSITION  = "• %s → %s: %d\n"
+
+	// OLT health report messages
+	MSG_OLT_HEALTH_HEADER    = "🩺 Saúde das OLTs desde %s\nTempo mediano da rede: %s por provisionamento\n"
+	MSG_OLT_HEALTH_EMPTY     = "🩺 Nenhum provisionamento registrado desde %s."
+	MSG_OLT_HEALTH_FAILED    = "❌ Não foi possível montar a saúde das OLTs: %v"
+	MSG_OLT_HEALTH_OLT       = "\n🏢 OLT %s%s\n• %d provisionamento(s), %d com falha (%s)\n• Tempo mediano %s, p95 %s\n• Comandos TL1 com erro: %d de %d\n"
+	MSG_OLT_HEALTH_SLOW      = " 🐢 lenta"
+	MSG_OLT_HEALTH_ERRORS    = " ⚠️ falhas frequentes"
+	MSG_OLT_HEALTH_STEP      = "  ◦ %s: %s (p95 %s)\n"
+	MSG_OLT_HEALTH_TRUNCATED = "\n… e mais %d OLT(s), consulte a API de relatórios."
+
+	// Daily activation report messages
+	MSG_TODAY_HEADER        = "📅 Ativações de hoje (%s): %d\n"
+	MSG_TODAY_EMPTY         = "📅 Nenhuma ativação registrada hoje (%s)."
+	MSG_TODAY_OLT           = "\n🏢 OLT %s (%d)\n"
+	MSG_TODAY_OLT_CONTINUED = "🏢 OLT %s (continuação)\n"
+	MSG_TODAY_ITEM          = "• %s %s - %s (%s) /auditoria_%s\n"
+	MSG_TODAY_NO_OLT        = "sem OLT"
+	MSG_TODAY_FAILED        = "❌ Não foi possível consultar as ativações de hoje: %v"
+	MSG_AUDIT_USAGE         = "📋 Uso: /auditoria <id>"
+	MSG_AUDIT_NOT_FOUND     = "❌ Registro de auditoria %s não encontrado."
+	MSG_AUDIT_DETAIL        = "📋 Registro de auditoria %s\n\n" +
+		"🕒 Data: %s\n" +
+		"👷 Técnico: %s%s\n" +
+		"📄 Protocolo: %s\n" +
+		"📄 Contrato: %s\n" +
+		"👤 Cliente: %s\n" +
+		"📟 Serial: %s\n" +
+		"🏢 OLT: %s (slot %s, porta %s)\n" +
+		"🛠️ Manual: %s\n" +
+		"📊 Resultado: %s\n" +
+		"🏷️ Job TL1: %s\n" +
+		"📷 Fotos: %d"
+	MSG_AUDIT_SUCCESS           = "sucesso"
+	MSG_AUDIT_FAILURE           = "falha (%s)"
+	MSG_AUDIT_YES               = "sim"
+	MSG_AUDIT_NO                = "não"
+	MSG_AUDIT_CONTACT           = " (%s)"
+	MSG_AUDIT_NO_JOB            = "-"
+	MSG_AUDIT_PREVIOUS          = "\n♻️ Configuração anterior: %s (%s), %d serviço(s) WAN"
+	MSG_AUDIT_RESTORED          = "\n↩️ Revertido por %s em %s"
+	MSG_AUDIT_REMOVED           = "\n🧹 ONU órfã removida por %s em %s"
+	MSG_AUDIT_TROUBLESHOOT      = "\n🔎 Diagnóstico de sinal: %s"
+	MSG_AUDIT_TROUBLESHOOT_OPEN = "em andamento"
+
+	// Swap rollback messages
+	MSG_ROLLBACK_USAGE       = "↩️ Uso: /reverter <id do registro de auditoria>"
+	MSG_ROLLBACK_NO_SNAPSHOT = "ℹ️ O registro %s não possui configuração anterior da ONU para restaurar."
+	MSG_ROLLBACK_ALREADY     = "ℹ️ O registro %s já foi revertido por %s em %s."
+	MSG_ROLLBACK_RUNNING     = "⏳ Restaurando a configuração anterior da ONU %s..."
+	MSG_ROLLBACK_DONE        = "↩️ Configuração anterior da ONU %s restaurada: %s, %d serviço(s) WAN."
+	MSG_ROLLBACK_FAILED      = "❌ Não foi possível restaurar a configuração anterior: %v"
+	MSG_ROLLBACK_TITLE       = "↩️ Reverter a troca do registro %s:"
+	MSG_ROLLBACK_SERIAL      = "ONU restaurada"
+	MSG_ROLLBACK_NAME        = "Nome"
+	MSG_ROLLBACK_LOCATION    = "OLT"
+	MSG_ROLLBACK_WAN         = "Serviços WAN"
+	MSG_ROLLBACK_QUESTION    = "⚠️ A ONU será removida e autorizada de novo na OLT com a configuração acima, derrubando o cliente durante a restauração. Confirma?"
+	MSG_ROLLBACK_RUN         = "↩️ Reverter"
+	MSG_ROLLBACK_CANCEL      = "✖️ Cancelar"
+	MSG_ROLLBACK_ABORTED     = "✖️ Reversão cancelada, nada foi alterado na OLT."
+	MSG_ALERT_ROLLBACK       = "↩️ %s reverteu a troca do registro %s, ONU %s restaurada na OLT %s."
+
+	// ONU TL1 history messages
+	MSG_ONU_HISTORY_USAGE       = "📜 Uso: /historico-onu <serial> [simular]"
+	MSG_ONU_HISTORY_FAILED      = "❌ Não foi possível montar o histórico: %v"
+	MSG_ONU_HISTORY_EMPTY       = "📜 Nenhum comando TL1 registrado para a ONU %s."
+	MSG_ONU_HISTORY_HEADER      = "📜 Histórico TL1 da ONU %s: %d comando(s)\n"
+	MSG_ONU_HISTORY_TRUNCATED   = "✂️ Exibindo os %d mais recentes.\n"
+	MSG_ONU_HISTORY_RECORD      = "\n🗂️ Registro %s · %s · %s\n"
+	MSG_ONU_HISTORY_ITEM        = "✅ %s\n"
+	MSG_ONU_HISTORY_FAILED_ITEM = "❌ %s\n   %s\n"
+	MSG_ONU_HISTORY_NONE        = "-"
+	MSG_ONU_REPLAY_HEADER       = "🧪 Simulação da ONU %s, nada foi enviado à OLT\n\n" +
+		"OLT: %s\nPON: %s\nNome: %s\nModelo: %s\n" +
+		"Banda: %s up / %s down\nPerfil DBA: %s\nWAN: %s\nPortas LAN: %s\nPortas IPTV: %s\n"
+	MSG_ONU_REPLAY_COMMANDS     = "\n📟 Comandos que recriam a configuração (senhas ocultas):\n"
+	MSG_ONU_REPLAY_CUT          = "✂️ Lista truncada."
+	MSG_ONU_REPLAY_UNREGISTERED = "🧪 Pelo histórico, a ONU %s não está autorizada em nenhuma OLT."
+
+	// Optical budget messages
+	MSG_BUDGET_USAGE = "📐 Uso: /atenuacao <TX da OLT em dBm> <distância em km> [divisores]\n" +
+		"Ex.: /atenuacao 3,5 2,4 1:8 1:16"
+	MSG_BUDGET_INVALID = "❌ Valor inválido: %v\n\n"
+	MSG_BUDGET_RESULT  = "📐 Orçamento óptico\n\n" +
+		"⬅️ TX da OLT: %s\n" +
+		"🧵 Fibra (%s km): %s dB\n" +
+		"🔀 Divisores (%s): %s dB\n" +
+		"🔌 Conectores: %s dB\n" +
+		"📉 Perda total: %s dB\n\n" +
+		"🎯 RX esperado na ONU: %s\n"
+	MSG_BUDGET_NO_SPLITTERS  = "nenhum"
+	MSG_BUDGET_NO_JOB        = "\nℹ️ Após provisionar uma ONU, repita o cálculo para compará-lo com o sinal medido."
+	MSG_BUDGET_SIGNAL_FAILED = "\n⚠️ Não foi possível ler o sinal da ONU %s para comparar."
+	MSG_BUDGET_MEASURED      = "\n📡 RX medido na ONU %s: %s (diferença de %s dB)\n"
+	MSG_BUDGET_SUSPICIOUS    = "⚠️ Recepção bem abaixo do esperado, verifique as emendas e os conectores do trajeto."
+	MSG_BUDGET_CONSISTENT    = "✅ Recepção compatível com o trajeto informado."
+
+	// Proof-of-installation messages
+	MSG_REQUEST_PHOTOS = "📷 Envie as fotos da instalação (CTO, leitura do power meter, ONU instalada).\n" +
+		"Você pode adicionar uma legenda em cada foto. Ao terminar, toque em Concluir."
+	MSG_PHOTO_RECEIVED  = "📎 Foto %d de %d anexada ao registro."
+	MSG_PHOTO_EXPECTED  = "📷 Envie uma foto ou toque em Concluir para finalizar."
+	MSG_PHOTOS_FINISHED = "✅ Registro finalizado com %d foto(s) anexada(s). Obrigado!\n\n" + MSG_FEEDBACK_INVITE
+	MSG_PHOTOS_DONE     = "✅ Concluir"
+	MSG_FEEDBACK_INVITE = "💬 Como foi o atendimento do assistente? Envie /feedback para avaliar."
+
+	// Speed test survey messages
+	MSG_REQUEST_SPEED_TEST = "📎 %d foto(s) anexada(s) ao registro.\n\n" +
+		"🚀 Rode um teste de velocidade no aparelho do cliente e envie download e upload em Mbps (ex.: 480 230).%s"
+	MSG_SPEED_TEST_CONTRACTED      = "\n📄 Plano contratado: %s."
+	MSG_SPEED_TEST_DOWNLOAD        = "%d Mbps"
+	MSG_SPEED_TEST_DOWNLOAD_UPLOAD = "%d/%d Mbps"
+	MSG_SPEED_TEST_MEASURED        = "%s/%s Mbps"
+	MSG_SPEED_TEST_INVALID         = "⚠️ Não entendi o resultado. Envie download e upload em Mbps, por exemplo: 480 230."
+	MSG_SPEED_TEST_RECORDED        = "🚀 Teste de velocidade registrado: %s."
+	MSG_SPEED_TEST_LOW             = "⚠️ Velocidade abaixo do contratado: %s medidos para %s contratados (mínimo de %d%%).\n" +
+		"Confira o cabeamento, o Wi-Fi e o aparelho usado no teste. A instalação foi sinalizada para a equipe."
+	MSG_SPEED_TEST_SKIP      = "⏭️ Pular teste"
+	MSG_SPEED_TEST_FINISHED  = "✅ Registro finalizado. Obrigado!\n\n" + MSG_FEEDBACK_INVITE
+	MSG_ALERT_SPEED_TEST_LOW = "🐢 Instalação abaixo do contratado\n" +
+		"Técnico: %s\nCliente: %s\nProtocolo: %s\nMedido: %s\nContratado: %s\nRegistro: %s"
+
+	// Feedback messages
+	MSG_FEEDBACK_USAGE     = "💬 Uso: /feedback [anonimo]"
+	MSG_FEEDBACK_BUSY      = "⏳ Conclua o atendimento em andamento antes de enviar seu feedback."
+	MSG_FEEDBACK_RATING    = "💬 De 1 a 5, como foi o atendimento do assistente%s?"
+	MSG_FEEDBACK_JOB       = " no protocolo %s"
+	MSG_FEEDBACK_ANONYMOUS = "\n🕶️ Seu nome não será registrado."
+	MSG_FEEDBACK_COMMENT   = "✍️ Quer deixar um comentário? Escreva sua mensagem ou toque em Enviar sem comentário."
+	MSG_FEEDBACK_SKIP      = "➡️ Enviar sem comentário"
+	MSG_FEEDBACK_CANCEL    = "❌ Cancelar"
+	MSG_FEEDBACK_CANCELLED = "💬 Feedback cancelado."
+	MSG_FEEDBACK_THANKS    = "🙏 Obrigado! Seu feedback foi enviado à equipe do produto."
+	MSG_FEEDBACK_FAILED    = "❌ Não foi possível registrar seu feedback: %v"
+	MSG_FEEDBACK_RECEIVED  = "💬 Novo feedback: %s\n\n" +
+		"👤 Técnico: %s\n" +
+		"🔖 Job: %s\n" +
+		"📋 Protocolo: %s"
+	MSG_FEEDBACK_RECEIVED_COMMENT = "\n\n📝 %s"
+	MSG_FEEDBACK_ANONYMOUS_NAME   = "anônimo"
+	MSG_FEEDBACK_NONE             = "-"
+	MSG_FEEDBACK_DIGEST           = "💬 Feedback da semana (desde %s)\n\n" +
+		"📨 Avaliações: %d\n" +
+		"⭐ Média: %s\n" +
+		"📊 Notas: %s"
+	MSG_FEEDBACK_DIGEST_EMPTY    = "💬 Nenhum feedback recebido na semana (desde %s)."
+	MSG_FEEDBACK_DIGEST_COMMENTS = "\n\n📝 Comentários recentes:\n"
+	MSG_FEEDBACK_DIGEST_COMMENT  = "• %s %s\n"
+
+	// PON capacity messages
+	MSG_CAPACITY_DIGEST = "📈 Capacidade das PONs (%d monitoradas, %d ONUs por PON)\n\n" +
+		"PONs mais próximas do esgotamento:\n"
+	MSG_CAPACITY_DIGEST_PON = "\n🔌 %s %s/%s\n" +
+		"   Ocupação: %d/%d (%s)\n" +
+		"   Crescimento: %s ONUs/mês\n" +
+		"   Esgotamento previsto: %s\n"
+	MSG_CAPACITY_DIGEST_EMPTY  = "📈 Nenhuma ocupação de PON registrada ainda."
+	MSG_CAPACITY_NO_EXHAUSTION = "sem previsão"
+
+	// Protocol reopening messages
+	MSG_REOPEN_USAGE     = "♻️ Uso: /reabrir <protocolo>"
+	MSG_REOPEN_BUSY      = "⏳ Conclua o atendimento em andamento antes de reabrir um protocolo."
+	MSG_REOPEN_NOT_FOUND = "❌ Nenhum provisionamento concluído encontrado para o protocolo %s."
+	MSG_REOPEN_SUMMARY   = "♻️ Reabrindo o protocolo %s (/auditoria_%s)\n\n" +
+		"🕒 Provisionado em: %s\n" +
+		"👷 Técnico: %s\n" +
+		"📄 Contrato: %s\n" +
+		"👤 Cliente: %s\n" +
+		"📟 Serial: %s\n" +
+		"🏢 OLT: %s (slot %s, porta %s)\n" +
+		"📶 Sinal na ativação: %s\n\n" +
+		"O que deseja fazer?"
+	MSG_REOPEN_NO_SIGNAL        = "-"
+	MSG_REOPEN_SIGNAL           = "📶 Verificar sinal"
+	MSG_REOPEN_RECONFIGURE      = "🔄 Reconfigurar WAN"
+	MSG_REOPEN_SWAP             = "🔁 Trocar ONU"
+	MSG_REOPEN_CANCEL           = "❌ Cancelar"
+	MSG_REOPEN_REQUEST_SERIAL   = "📟 Informe o serial da nova ONU (ex.: ZTEG1A2B3C4D)."
+	MSG_REOPEN_SERIAL_INVALID   = "❌ Serial inválido. Informe o serial impresso na etiqueta da nova ONU."
+	MSG_REOPEN_CONFIRM          = "♻️ Reabertura do provisionamento /auditoria_%s.\n"
+	MSG_REOPEN_CONFIRM_SWAP     = "🔁 A ONU %s será substituída e removida da OLT após o sucesso.\n"
+	MSG_REOPEN_REPLACED_REMOVED = "\n\n🗑️ A ONU substituída %s foi removida da OLT."
+	MSG_REOPEN_REPLACED_LEFT    = "\n\n⚠️ Não foi possível remover a ONU substituída %s da OLT, peça a remoção ao NOC."
+
+	// Maintenance window messages
+	MSG_MAINTENANCE_USAGE = "🚧 Uso:\n" +
+		"/manutencao - lista as janelas de manutenção\n" +
+		"/manutencao <IP da OLT> <agora|HH:MM> <duração, ex.: 2h> [motivo] - agenda uma janela\n" +
+		"/manutencao encerrar <IP da OLT> - encerra a janela da OLT"
+	MSG_MAINTENANCE_LIST_HEADER      = "🚧 Janelas de manutenção:\n\n"
+	MSG_MAINTENANCE_LIST_ITEM        = "• OLT %s: %s até %s%s\n"
+	MSG_MAINTENANCE_LIST_ACTIVE      = " (em andamento)"
+	MSG_MAINTENANCE_LIST_EMPTY       = "🚧 Nenhuma janela de manutenção agendada."
+	MSG_MAINTENANCE_REASON           = "\n📝 Motivo: %s"
+	MSG_MAINTENANCE_SCHEDULED        = "🚧 Janela de manutenção da OLT %s agendada de %s até %s."
+	MSG_MAINTENANCE_FINISHED         = "✅ Janela de manutenção da OLT %s encerrada."
+	MSG_MAINTENANCE_FAILED           = "❌ Não foi possível atualizar a janela de manutenção: %v"
+	MSG_MAINTENANCE_INVALID_START    = "❌ Início inválido. Use agora ou o horário no formato HH:MM."
+	MSG_MAINTENANCE_INVALID_DURATION = "❌ Duração inválida. Use por exemplo 90m ou 2h."
+	MSG_MAINTENANCE_WARNING          = "🚧 A OLT %s está em janela de manutenção até %s.%s\n\n" +
+		"O provisionamento pode falhar durante a manutenção. Deseja prosseguir mesmo assim?"
+	MSG_MAINTENANCE_ACK       = "⚠️ Prosseguir"
+	MSG_MAINTENANCE_CANCEL    = "❌ Cancelar"
+	MSG_MAINTENANCE_CANCELLED = "🚧 Provisionamento cancelado. Tente novamente após o fim da manutenção da OLT."
+
+	// Duplicate activation messages
+	MSG_DUPLICATE_WARNING = "⚠️ O contrato %s já tem a ONU %s ativa e online (OLT %s, slot %s, porta %s).\n\n" +
+		"Um contrato não recebe uma segunda ONU. Para instalar a ONU %s no lugar dela, escolha Substituição: " +
+		"a ONU atual será removida da OLT após o sucesso."
+	MSG_DUPLICATE_REPLACE   = "🔁 Substituição"
+	MSG_DUPLICATE_CANCEL    = "❌ Cancelar"
+	MSG_DUPLICATE_CANCELLED = "❌ Provisionamento cancelado, a ONU atual do contrato foi mantida."
+
+	// CSV export messages
+	MSG_EXPORT_USAGE = "📤 Uso: /exportar <período>\n" +
+		"Períodos: hoje, ontem, semana, mes ou um número de dias como 15d."
+	MSG_EXPORT_DONE        = "📤 Exportação de %s a %s: %d provisionamento(s), %d falha(s)."
+	MSG_EXPORT_FAILED      = "❌ Falha ao exportar: %v"
+	MSG_EXPORT_SEND_FAILED = "❌ Não foi possível enviar o arquivo %s. A exportação continua no armazenamento de artefatos."
+
+	// User preference messages
+	MSG_PREFS_HEADER = "⚙️ Suas preferências\n\n" +
+		"📝 Mensagens de resultado: %s\n" +
+		"📶 Alertas de sinal das suas instalações: %s\n" +
+		"🌐 Idioma dos resultados: %s\n\n" +
+		"Toque em uma opção para alterá-la."
+	MSG_PREFS_COMPACT         = "resumidas"
+	MSG_PREFS_VERBOSE         = "detalhadas"
+	MSG_PREFS_ON              = "ligados"
+	MSG_PREFS_OFF             = "desligados"
+	MSG_PREFS_TOGGLE_COMPACT  = "📝 Resumidas / detalhadas"
+	MSG_PREFS_TOGGLE_ALERTS   = "📶 Ligar / desligar alertas"
+	MSG_PREFS_TOGGLE_LANGUAGE = "🌐 Trocar idioma"
+	MSG_PREFS_DONE            = "✅ Concluir"
+	MSG_PREFS_SAVED           = "✅ Preferências salvas."
+	MSG_PREFS_FAILED          = "❌ Não foi possível salvar suas preferências agora. Tente novamente em instantes."
+
+	// Work order greeting messages
+	MSG_WORK_ORDER_ASSIGNED = "📋 Nova ordem de serviço atribuída a você\n\n" +
+		"📄 Protocolo: %s\n" +
+		"🛠️ Serviço: %s\n" +
+		"👤 Cliente: %s\n\n" +
+		"Quando chegar ao local, toque no botão abaixo para iniciar o provisionamento."
+	MSG_WORK_ORDER_START = "▶️ Iniciar provisionamento"
+	MSG_WORK_ORDER_LOGIN = "🔐 Digite /start e entre com seu CPF, depois toque no botão da ordem de serviço novamente."
+	MSG_WORK_ORDER_BUSY  = "⏳ Conclua o atendimento em andamento ou digite /cancelar antes de iniciar esta ordem de serviço."
+
+	// ERP webhook messages
+	MSG_ERP_PROTOCOL_CANCELLED = "🚫 O protocolo %s foi cancelado no ERP e o atendimento foi encerrado, nada foi enviado à OLT.\n\n" +
+		"📝 Motivo: %s"
+	MSG_ERP_PROTOCOL_CANCELLED_RUNNING = "🚫 O protocolo %s foi cancelado no ERP enquanto o provisionamento estava em andamento.\n\n" +
+		"📝 Motivo: %s\n\n" +
+		"Confirme com o suporte se a ONU deve ser mantida."
+	MSG_ERP_PROTOCOL_CORRECTED        = "✏️ Os dados do protocolo %s foram corrigidos no ERP. Confira as informações atualizadas antes de continuar."
+	MSG_ERP_PROTOCOL_CORRECTED_FAILED = "✏️ Os dados do protocolo %s foram corrigidos no ERP, mas não foi possível recarregá-los. Digite /cancelar e informe o protocolo novamente."
+	MSG_ERP_EVENT_NO_REASON           = "não informado"
+
+	// Orphan ONU cleanup messages
+	MSG_ORPHAN_DETECTED = "🧹 ONU órfã encontrada na OLT\n\n" +
+		"🔢 Serial: %s\n" +
+		"📄 Contrato: %s (%s)\n" +
+		"🔁 Serial atual no ERP: %s\n" +
+		"🖥️ OLT %s, slot %s, porta %s\n" +
+		"📋 Provisionada em /auditoria_%s\n\n" +
+		"A ONU continua registrada na OLT, mas o contrato aponta para outro equipamento. Remova-a para liberar a posição na PON."
+	MSG_ORPHAN_REMOVE    = "🧹 Remover da OLT"
+	MSG_ORPHAN_IGNORE    = "🙈 Ignorar"
+	MSG_ORPHAN_RUNNING   = "⏳ Conferindo o ERP e a OLT e removendo a ONU %s..."
+	MSG_ORPHAN_REMOVED   = "✅ ONU %s removida da OLT %s. A remoção ficou registrada em /auditoria_%s."
+	MSG_ORPHAN_RESOLVED  = "ℹ️ A ONU %s não está mais órfã (o contrato voltou a apontar para ela ou ela já saiu da OLT). Nada foi removido."
+	MSG_ORPHAN_IGNORED   = "🙈 ONU %s mantida na OLT e não será mais reportada."
+	MSG_ORPHAN_NOT_FOUND = "ℹ️ Esta ONU órfã já foi tratada."
+	MSG_ORPHAN_FAILED    = "❌ Não foi possível remover a ONU órfã: %v"
+	MSG_ALERT_ORPHAN     = "🧹 %s removeu a ONU órfã %s da OLT %s (contrato %s)."
+
+	// Cancelled contract deprovisioning messages
+	MSG_DEPROVISION_USAGE   = "🧹 Uso: /cancelados [dias]\n\nLista os contratos cancelados no ERP nos últimos dias (padrão %d) cujas ONUs ainda estão nas OLTs e permite removê-las em lote."
+	MSG_DEPROVISION_PLANNED = "🧹 Contratos cancelados desde %s\n\n" +
+		"📋 %d ONU(s) de contratos cancelados\n" +
+		"🔌 %d ainda na OLT\n" +
+		"✅ %d já fora da OLT\n" +
+		"♻️ %d reaproveitada(s) em outro contrato\n" +
+		"❓ %d sem PON conhecida ou sem leitura\n"
+	MSG_DEPROVISION_ITEM    = "• %s %s (%s), OLT %s %s/%s\n"
+	MSG_DEPROVISION_MORE    = "• ... e mais %d\n"
+	MSG_DEPROVISION_CONFIRM = "\nAté %d remoção(ões) por lote, uma a cada %s. Remover as ONUs ainda na OLT?"
+	MSG_DEPROVISION_NOTHING = "\nNenhuma ONU a remover."
+	MSG_DEPROVISION_RUN     = "🧹 Remover em lote"
+	MSG_DEPROVISION_CANCEL  = "✖️ Cancelar"
+	MSG_DEPROVISION_RUNNING = "⏳ Conferindo as OLTs e removendo as ONUs dos contratos cancelados..."
+	MSG_DEPROVISION_ABORTED = "✖️ Nenhuma ONU foi removida."
+	MSG_DEPROVISION_FAILED  = "❌ Não foi possível listar os contratos cancelados: %v"
+	MSG_DEPROVISION_REPORT  = "🧹 Lote de contratos cancelados concluído\n\n" +
+		"✅ Removidas: %d\n" +
+		"❌ Falhas: %d\n" +
+		"⏸️ Pendentes: %d\n" +
+		"♻️ Reaproveitadas: %d\n" +
+		"❓ Sem PON conhecida ou sem leitura: %d\n"
+	MSG_DEPROVISION_REPORT_FAILURE = "• %s %s: %s\n"
+	MSG_DEPROVISION_REPORT_PENDING = "\nAs pendentes ficam para o próximo lote, o UNM estava ocupado ou o limite do lote foi atingido."
+	MSG_ALERT_DEPROVISION          = "🧹 Contratos cancelados (%s): %d ONU(s) removida(s), %d falha(s), %d pendente(s)."
+	MSG_DEPROVISION_SCHEDULED      = "agendamento"
+
+	// Signal troubleshooting messages
+	MSG_TROUBLESHOOT_OFFER_LOS = "📵 A ONU não respondeu à leitura de sinal, o que costuma indicar perda de sinal óptico (LOS).\n\n" +
+		"Vamos verificar juntos o caminho da fibra?"
+	MSG_TROUBLESHOOT_OFFER_LOW = "📉 A recepção da ONU está muito baixa (%s).\n\n" +
+		"Vamos verificar juntos o caminho da fibra?"
+	MSG_TROUBLESHOOT_START      = "🔎 Iniciar diagnóstico"
+	MSG_TROUBLESHOOT_SOLVED     = "✅ Resolveu"
+	MSG_TROUBLESHOOT_NOT_SOLVED = "❌ Não resolveu"
+	MSG_TROUBLESHOOT_CONNECTOR  = "1️⃣ Conector da ONU\n\n" +
+		"Desconecte o conector SC/APC da ONU, limpe a ponta com caneta de limpeza ou álcool isopropílico, confira se não há dobras no drop perto da ONU e reconecte até ouvir o clique.\n\n" +
+		"O LED PON parou de piscar e o LOS apagou?"
+	MSG_TROUBLESHOOT_CTO = "2️⃣ Porta da CTO\n\n" +
+		"Na CTO, confira se o drop está na porta indicada no protocolo, limpe o conector e o adaptador da porta e, se houver porta livre, teste em outra.\n\n" +
+		"O sinal voltou?"
+	MSG_TROUBLESHOOT_METER = "3️⃣ Medição com power meter (1490 nm)\n\n" +
+		"Meça o sinal na porta da CTO e depois na ponta do drop, junto à ONU. O que você encontrou?"
+	MSG_TROUBLESHOOT_METER_DROP = "📏 CTO boa, drop com perda"
+	MSG_TROUBLESHOOT_METER_CTO  = "📉 Porta da CTO com sinal baixo"
+	MSG_TROUBLESHOOT_METER_NONE = "⛔ Sem luz na CTO"
+	MSG_TROUBLESHOOT_FIXED      = "✅ Causa registrada: %s.\n\nFaça uma nova leitura de sinal para confirmar a recepção."
+	MSG_TROUBLESHOOT_DROP       = "🔧 Causa registrada: %s.\n\nRefaça a conectorização ou substitua o cabo drop e faça uma nova leitura de sinal."
+	MSG_TROUBLESHOOT_ESCALATED  = "📣 Causa registrada: %s.\n\nO problema está na rede de distribuição e foi encaminhado à equipe de rede. Deixe a ONU instalada e aguarde o retorno."
+	MSG_TROUBLESHOOT_ALREADY    = "ℹ️ Este diagnóstico já foi concluído: %s."
+	MSG_ALERT_TROUBLESHOOT      = "📣 Diagnóstico de sinal encaminhado à rede: %s\n\n" +
+		"📄 Contrato: %s\n" +
+		"📟 Serial: %s\n" +
+		"🏢 OLT %s, slot %s, porta %s\n" +
+		"👷 Técnico: %s\n" +
+		"📋 /auditoria_%s"
+
+	MSG_TROUBLESHOOT_OUTCOME_CONNECTOR    = "conector da ONU"
+	MSG_TROUBLESHOOT_OUTCOME_CTO_PORT     = "porta da CTO"
+	MSG_TROUBLESHOOT_OUTCOME_DROP         = "cabo drop"
+	MSG_TROUBLESHOOT_OUTCOME_CTO_LOW      = "sinal baixo na CTO"
+	MSG_TROUBLESHOOT_OUTCOME_CTO_NO_LIGHT = "sem luz na CTO"
+
+	// Returning technician messages
+	MSG_RETURNING_GREETING      = "👋 Bem-vindo de volta, %s — %s.\n\nO que você deseja fazer?"
+	MSG_RETURNING_TODAY         = "%d ativações hoje, última às %s (%s)"
+	MSG_RETURNING_TODAY_ONE     = "1 ativação hoje, às %s (%s)"
+	MSG_RETURNING_TODAY_NONE    = "nenhuma ativação hoje"
+	MSG_RETURNING_TODAY_UNKNOWN = "resumo do dia indisponível"
+	MSG_RETURNING_OK            = "OK"
+	MSG_RETURNING_FAILED        = "falha"
 )
 
-// Timeout constants
+// Proof-of-installation limits
+const (
+	MAX_INSTALLATION_PHOTOS = 10
+)
+
+// INLINE_CACHE_TIME is how long Telegram may serve an inline answer again, kept short since it carries a
+// live signal reading
+const INLINE_CACHE_TIME = 5 * time.Second
+
+// MAX_MESSAGE_LENGTH keeps long reports below the Telegram limit of 4096 characters per message
+const MAX_MESSAGE_LENGTH = 4000
+
+// Timeout constants, TIMEOUT_CPF_VALIDATION is only applied in demo mode and
+// TIMEOUT_ERP_FETCH bounds all ERP attempts, each one has an adaptive timeout
 const (
 	TIMEOUT_CPF_VALIDATION = 2 * time.Second
-	TIMEOUT_ERP_FETCH      = 30 * time.Second
+	TIMEOUT_ERP_FETCH      = 90 * time.Second
 	TIMEOUT_PROVISIONING   = 60 * time.Second
+	TIMEOUT_SIGNAL_CHECK   = 20 * time.Second
+	TIMEOUT_INLINE_QUERY   = 8 * time.Second
+	TIMEOUT_QUEUE_WAIT     = 5 * time.Minute
 )
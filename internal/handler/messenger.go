@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"context"
 	"provisioning-assistant/internal/domain"
 
 	"github.com/gookit/event"
@@ -19,21 +20,31 @@ func NewMessenger(eventManager *event.Manager) *Messenger {
 }
 
 // SendMessage sends a text message to a chat
-func (m *Messenger) SendMessage(chatID int64, text string) error {
+func (m *Messenger) SendMessage(ctx context.Context, chatID int64, text string) error {
 	response := &domain.MessageResponse{
 		ChatID: chatID,
 		Text:   text,
 	}
 
 	m.eventManager.MustFire("telegram.send.message", event.M{
+		"ctx":      ctx,
 		"response": response,
 	})
 
 	return nil
 }
 
+// Deliver sends a text message and returns the delivery failure instead of panicking on it
+func (m *Messenger) Deliver(ctx context.Context, chatID int64, text string) error {
+	err, _ := m.eventManager.Fire("telegram.send.message", event.M{
+		"ctx":      ctx,
+		"response": &domain.MessageResponse{ChatID: chatID, Text: text},
+	})
+	return err
+}
+
 // SendMessageWithKeyboard sends a message with an inline keyboard
-func (m *Messenger) SendMessageWithKeyboard(chatID int64, text string, keyboard *domain.Keyboard) error {
+func (m *Messenger) SendMessageWithKeyboard(ctx context.Context, chatID int64, text string, keyboard *domain.Keyboard) error {
 	response := &domain.MessageResponse{
 		ChatID:   chatID,
 		Text:     text,
@@ -41,28 +52,43 @@ func (m *Messenger) SendMessageWithKeyboard(chatID int64, text string, keyboard
 	}
 
 	m.eventManager.MustFire("telegram.send.message", event.M{
+		"ctx":      ctx,
 		"response": response,
 	})
 
 	return nil
 }
 
+// AnswerInlineQuery sends the results of an inline query
+func (m *Messenger) AnswerInlineQuery(ctx context.Context, queryID string, results []domain.InlineResult) error {
+	m.eventManager.MustFire("telegram.answer.inline", event.M{
+		"ctx": ctx,
+		"answer": &domain.InlineAnswer{
+			QueryID:   queryID,
+			Results:   results,
+			CacheTime: INLINE_CACHE_TIME,
+		},
+	})
+
+	return nil
+}
+
 // SendTypingIndicator sends a typing action to show bot is processing
-func (m *Messenger) SendTypingIndicator(chatID int64) {
+func (m *Messenger) SendTypingIndicator(ctx context.Context, chatID int64) {
 	m.eventManager.MustFire("telegram.send.typing", event.M{
+		"ctx":    ctx,
 		"chatID": chatID,
 	})
 }
 
-// SendDocument sends a document/file to a chat
-// func (m *Messenger) SendDocument(chatID int64, document *domain.Document) error {
-// 	m.eventManager.MustFire("telegram.send.document", event.M{
-// 		"chatID":   chatID,
-// 		"document": document,
-// 	})
-
-// 	return nil
-// }
+// SendDocument sends a file to a chat and returns the delivery failure, a large file may be refused
+func (m *Messenger) SendDocument(ctx context.Context, document *domain.Document) error {
+	err, _ := m.eventManager.Fire("telegram.send.document", event.M{
+		"ctx":      ctx,
+		"document": document,
+	})
+	return err
+}
 
 // EditMessage edits an existing message
 // func (m *Messenger) EditMessage(chatID int64, messageID int, text string, keyboard *domain.Keyboard) error {
@@ -81,8 +107,9 @@ func (m *Messenger) SendTypingIndicator(chatID int64) {
 // }
 
 // DeleteMessage deletes a message
-func (m *Messenger) DeleteMessage(chatID int64, messageID int) error {
+func (m *Messenger) DeleteMessage(ctx context.Context, chatID int64, messageID int) error {
 	m.eventManager.MustFire("telegram.delete.message", event.M{
+		"ctx":       ctx,
 		"chatID":    chatID,
 		"messageID": messageID,
 	})
@@ -91,8 +118,9 @@ func (m *Messenger) DeleteMessage(chatID int64, messageID int) error {
 }
 
 // AnswerCallbackQuery sends a response to a callback query
-func (m *Messenger) AnswerCallbackQuery(callbackID string, text string, showAlert bool) error {
+func (m *Messenger) AnswerCallbackQuery(ctx context.Context, callbackID string, text string, showAlert bool) error {
 	m.eventManager.MustFire("telegram.answer.callback", event.M{
+		"ctx":        ctx,
 		"callbackID": callbackID,
 		"text":       text,
 		"showAlert":  showAlert,
@@ -100,3 +128,11 @@ func (m *Messenger) AnswerCallbackQuery(callbackID string, text string, showAler
 
 	return nil
 }
+
+// eventContext extracts the propagated context from an event, defaulting to background
+func eventContext(e event.Event) context.Context {
+	if ctx, ok := e.Get("ctx").(context.Context); ok && ctx != nil {
+		return ctx
+	}
+	return context.Background()
+}
@@ -0,0 +1,184 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"provisioning-assistant/internal/domain"
+	"provisioning-assistant/internal/services"
+	"time"
+)
+
+const DefaultNudgeDelay = 10 * time.Minute
+
+// nudgeHints tells what each input step is waiting for, only these states are nudged by default
+var nudgeHints = map[domain.SessionState]string{
+	domain.StateWaitingProtocol:     MSG_NUDGE_PROTOCOL,
+	domain.StateWaitingPPPoESearch:  MSG_NUDGE_PPPOE_SEARCH,
+	domain.StateConfirmData:         MSG_NUDGE_CONFIRM_DATA,
+	domain.StateWaitingSerial:       MSG_NUDGE_SERIAL,
+	domain.StateWaitingOLT:          MSG_NUDGE_OLT,
+	domain.StateWaitingSlot:         MSG_NUDGE_SLOT,
+	domain.StateWaitingPort:         MSG_NUDGE_PORT,
+	domain.StateWaitingVlan:         MSG_NUDGE_VLAN,
+	domain.StateWaitingPPPoEUser:    MSG_NUDGE_PPPOE_USER,
+	domain.StateWaitingPPPoEPass:    MSG_NUDGE_PPPOE_PASS,
+	domain.StateWaitingPhotos:       MSG_NUDGE_PHOTOS,
+	domain.StateWaitingSpeedTest:    MSG_NUDGE_SPEED_TEST,
+	domain.StateReopenMenu:          MSG_NUDGE_REOPEN_MENU,
+	domain.StateWaitingReopenSerial: MSG_NUDGE_REOPEN_SERIAL,
+}
+
+// NudgeRule overrides the delay or the hint of the nudge sent in one state, or turns it off
+type NudgeRule struct {
+	AfterMinutes int    `json:"after_minutes"`
+	Hint         string `json:"hint"`
+	Disabled     bool   `json:"disabled"`
+}
+
+// NudgePolicy defines after how long a silent session is nudged, a zero delay disables the nudges
+type NudgePolicy struct {
+	After  time.Duration
+	States map[domain.SessionState]NudgeRule
+}
+
+// LoadNudgePolicy reads the per state nudge rules from a JSON file keyed by session state
+func LoadNudgePolicy(path string, after time.Duration) (NudgePolicy, error) {
+	policy := NudgePolicy{After: after}
+	if path == "" {
+		return policy, policy.Validate()
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return policy, fmt.Errorf("falha ao ler arquivo de lembretes: %w", err)
+	}
+
+	if err := json.Unmarshal(content, &policy.States); err != nil {
+		return policy, fmt.Errorf("falha ao interpretar arquivo de lembretes: %w", err)
+	}
+
+	return policy, policy.Validate()
+}
+
+// Validate ensures every nudge happens before the session expires and has a hint to send
+func (p NudgePolicy) Validate() error {
+	if p.After >= services.SessionTTL {
+		return fmt.Errorf("o lembrete padrão (%s) precisa ocorrer antes da expiração da sessão (%s)", p.After, services.SessionTTL)
+	}
+
+	for state, rule := range p.States {
+		if delay := time.Duration(rule.AfterMinutes) * time.Minute; delay >= services.SessionTTL {
+			return fmt.Errorf("o lembrete de %s (%s) precisa ocorrer antes da expiração da sessão (%s)", state, delay, services.SessionTTL)
+		}
+		if _, exists := nudgeHints[state]; !exists && !rule.Disabled && rule.Hint == "" {
+			return fmt.Errorf("o lembrete de %s precisa de uma dica, o estado não possui uma padrão", state)
+		}
+	}
+
+	return nil
+}
+
+// delay returns how long a session may stay silent in the state, zero when it is never nudged
+func (p NudgePolicy) delay(state domain.SessionState) time.Duration {
+	rule := p.States[state]
+	if p.After <= 0 || rule.Disabled || p.hint(state) == "" {
+		return 0
+	}
+
+	if rule.AfterMinutes > 0 {
+		return time.Duration(rule.AfterMinutes) * time.Minute
+	}
+	return p.After
+}
+
+// hint returns what the nudge asks for in the state
+func (p NudgePolicy) hint(state domain.SessionState) string {
+	if rule := p.States[state]; rule.Hint != "" {
+		return rule.Hint
+	}
+	return nudgeHints[state]
+}
+
+// NudgeHandler reminds technicians of the step a flow is waiting on and lets them leave it
+type NudgeHandler struct {
+	policy         NudgePolicy
+	sessionService *services.SessionService
+	menuHandler    *MenuHandler
+	messenger      *Messenger
+	logger         domain.Logger
+}
+
+// NewNudgeHandler creates a new stalled session handler
+func NewNudgeHandler(
+	policy NudgePolicy,
+	sessionService *services.SessionService,
+	menuHandler *MenuHandler,
+	messenger *Messenger,
+	logger domain.Logger,
+) *NudgeHandler {
+	return &NudgeHandler{
+		policy:         policy,
+		sessionService: sessionService,
+		menuHandler:    menuHandler,
+		messenger:      messenger,
+		logger:         logger,
+	}
+}
+
+// RegisterCommands registers the command that abandons the current step
+func (h *NudgeHandler) RegisterCommands(commands *CommandHandler) {
+	commands.Register("/cancelar", domain.RoleTechnician, h.handleCancelCommand)
+}
+
+// IsEnabled reports whether stalled sessions are nudged
+func (h *NudgeHandler) IsEnabled() bool {
+	return h.policy.After > 0
+}
+
+// NudgeStalled sends a reminder to every session silent in an input step for longer than its delay
+func (h *NudgeHandler) NudgeStalled(ctx context.Context) error {
+	sessions := h.sessionService.Stalled(h.policy.delay)
+	for _, session := range sessions {
+		message := fmt.Sprintf(MSG_NUDGE, h.policy.hint(session.State))
+		if err := h.messenger.SendMessage(ctx, session.ChatID, message); err != nil {
+			h.logger.WithError(err).WithField("user_id", session.UserID).Warn("Falha ao enviar lembrete da etapa")
+		}
+	}
+
+	if len(sessions) > 0 {
+		h.logger.WithField("sessions", len(sessions)).Debug("Lembretes enviados a sessões paradas")
+	}
+
+	return nil
+}
+
+// handleCancelCommand abandons the current step and returns to the main menu
+func (h *NudgeHandler) handleCancelCommand(ctx context.Context, session *domain.Session, args []string) error {
+	switch session.State {
+	case domain.StateProvisioning:
+		return h.messenger.SendMessage(ctx, session.ChatID, MSG_CANCEL_PROVISIONING)
+	case domain.StateWaitingOperationPhrase:
+		return h.messenger.SendMessage(ctx, session.ChatID, MSG_CANCEL_OPERATION)
+	case domain.StateMainMenu:
+		return h.menuHandler.SendMainMenu(ctx, session)
+	}
+
+	previous := session.State
+	updateSession(h.sessionService, session, func(s *domain.Session) {
+		s.State = domain.StateMainMenu
+		s.ConsoleEndpoint = ""
+		s.Feedback = nil
+	})
+
+	h.logger.WithFields(map[string]any{
+		"user_id": session.UserID,
+		"state":   previous,
+	}).Info("Etapa cancelada pelo técnico")
+
+	if err := h.messenger.SendMessage(ctx, session.ChatID, MSG_CANCEL_DONE); err != nil {
+		return err
+	}
+	return h.menuHandler.SendMainMenu(ctx, session)
+}
@@ -0,0 +1,156 @@
+package handler
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"provisioning-assistant/internal/domain"
+	"provisioning-assistant/internal/locale"
+	"provisioning-assistant/internal/services"
+	"strings"
+)
+
+type OnuHistoryHandler struct {
+	historyService *services.OnuHistoryService
+	loadShedder    *services.LoadShedder
+	formatter      *locale.Formatter
+	messenger      *Messenger
+	logger         domain.Logger
+}
+
+// NewOnuHistoryHandler creates a new ONU TL1 history command handler
+func NewOnuHistoryHandler(
+	historyService *services.OnuHistoryService,
+	loadShedder *services.LoadShedder,
+	formatter *locale.Formatter,
+	messenger *Messenger,
+	logger domain.Logger,
+) *OnuHistoryHandler {
+	return &OnuHistoryHandler{
+		historyService: historyService,
+		loadShedder:    loadShedder,
+		formatter:      formatter,
+		messenger:      messenger,
+		logger:         logger,
+	}
+}
+
+// RegisterCommands registers the ONU history command
+func (h *OnuHistoryHandler) RegisterCommands(commands *CommandHandler) {
+	commands.Register("/historico-onu", domain.RoleSupervisor, h.handleHistoryCommand)
+}
+
+// handleHistoryCommand lists the TL1 commands sent for a serial or, with "simular", the configuration they rebuild
+func (h *OnuHistoryHandler) handleHistoryCommand(ctx context.Context, session *domain.Session, args []string) error {
+	if !h.loadShedder.Allow(services.ShedOnuHistory) {
+		return h.messenger.SendMessage(ctx, session.ChatID, MSG_LOAD_SHED_DEFERRED)
+	}
+
+	switch {
+	case len(args) == 1:
+		return h.sendHistory(ctx, session, strings.ToUpper(args[0]))
+	case len(args) == 2 && strings.EqualFold(args[1], "simular"):
+		return h.sendReplay(ctx, session, strings.ToUpper(args[0]))
+	default:
+		return h.messenger.SendMessage(ctx, session.ChatID, MSG_ONU_HISTORY_USAGE)
+	}
+}
+
+// sendHistory sends the most recent commands of the serial that fit in one message, oldest first and
+// grouped by the audit record they were sent for
+func (h *OnuHistoryHandler) sendHistory(ctx context.Context, session *domain.Session, serial string) error {
+	history, err := h.historyService.History(ctx, serial)
+	if err != nil {
+		return h.messenger.SendMessage(ctx, session.ChatID, fmt.Sprintf(MSG_ONU_HISTORY_FAILED, err))
+	}
+
+	if len(history) == 0 {
+		return h.messenger.SendMessage(ctx, session.ChatID, fmt.Sprintf(MSG_ONU_HISTORY_EMPTY, serial))
+	}
+
+	header := fmt.Sprintf(MSG_ONU_HISTORY_HEADER, serial, len(history))
+	budget := MAX_MESSAGE_LENGTH - len(header) - len(MSG_ONU_HISTORY_TRUNCATED)
+
+	// The newest commands are kept when the history does not fit, each group header counted with them
+	shown := 0
+	for i := len(history) - 1; i >= 0; i-- {
+		cost := len(h.historyLine(history[i]))
+		if i == 0 || history[i-1].AuditID != history[i].AuditID {
+			cost += len(h.recordLine(history[i]))
+		}
+		if cost > budget {
+			break
+		}
+		budget -= cost
+		shown++
+	}
+
+	var builder strings.Builder
+	builder.WriteString(header)
+	if shown < len(history) {
+		builder.WriteString(fmt.Sprintf(MSG_ONU_HISTORY_TRUNCATED, shown))
+	}
+
+	visible := history[len(history)-shown:]
+	for i, entry := range visible {
+		if i == 0 || visible[i-1].AuditID != entry.AuditID {
+			builder.WriteString(h.recordLine(entry))
+		}
+		builder.WriteString(h.historyLine(entry))
+	}
+
+	return h.messenger.SendMessage(ctx, session.ChatID, builder.String())
+}
+
+// recordLine renders the header of the commands of one audit record
+func (h *OnuHistoryHandler) recordLine(entry services.OnuCommand) string {
+	return fmt.Sprintf(MSG_ONU_HISTORY_RECORD, entry.AuditID, h.formatter.DateTime(entry.AuditAt), entry.Technician)
+}
+
+// historyLine renders one command and how it went
+func (h *OnuHistoryHandler) historyLine(entry services.OnuCommand) string {
+	if entry.Error != "" {
+		return fmt.Sprintf(MSG_ONU_HISTORY_FAILED_ITEM, entry.Command, entry.Error)
+	}
+	return fmt.Sprintf(MSG_ONU_HISTORY_ITEM, entry.Command)
+}
+
+// sendReplay sends the configuration rebuilt from the history and the commands that would recreate it
+func (h *OnuHistoryHandler) sendReplay(ctx context.Context, session *domain.Session, serial string) error {
+	onu, err := h.historyService.Replay(ctx, serial)
+	if err != nil {
+		return h.messenger.SendMessage(ctx, session.ChatID, fmt.Sprintf(MSG_ONU_HISTORY_FAILED, err))
+	}
+
+	if !onu.Registered {
+		return h.messenger.SendMessage(ctx, session.ChatID, fmt.Sprintf(MSG_ONU_REPLAY_UNREGISTERED, serial))
+	}
+
+	var builder strings.Builder
+	builder.WriteString(fmt.Sprintf(
+		MSG_ONU_REPLAY_HEADER,
+		serial,
+		onu.OltIP,
+		onu.PonID,
+		onu.Name,
+		onu.Model,
+		cmp.Or(onu.UpBandwidth, MSG_ONU_HISTORY_NONE),
+		cmp.Or(onu.DownBandwidth, MSG_ONU_HISTORY_NONE),
+		cmp.Or(onu.DBAProfile, MSG_ONU_HISTORY_NONE),
+		cmp.Or(strings.Join(onu.WanServices, ", "), MSG_ONU_HISTORY_NONE),
+		cmp.Or(strings.Join(onu.LanPorts, ", "), MSG_ONU_HISTORY_NONE),
+		cmp.Or(strings.Join(onu.IptvPorts, ", "), MSG_ONU_HISTORY_NONE),
+	))
+
+	builder.WriteString(MSG_ONU_REPLAY_COMMANDS)
+	for _, command := range onu.Commands {
+		line := command + "\n"
+		if builder.Len()+len(line)+len(MSG_ONU_REPLAY_CUT) > MAX_MESSAGE_LENGTH {
+			builder.WriteString(MSG_ONU_REPLAY_CUT)
+			break
+		}
+		builder.WriteString(line)
+	}
+
+	return h.messenger.SendMessage(ctx, session.ChatID, builder.String())
+}
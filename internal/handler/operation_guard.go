@@ -0,0 +1,192 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"provisioning-assistant/internal/domain"
+	"provisioning-assistant/internal/services"
+	"provisioning-assistant/internal/unm"
+	"strings"
+	"sync"
+)
+
+// OperationFunc executes an OLT operation once it is cleared by the guard
+type OperationFunc func(ctx context.Context) error
+
+// OperationGuard requires a typed phrase and supervisor approval before running commands affecting more than one ONU
+type OperationGuard struct {
+	operationService *services.OperationService
+	sessionService   *services.SessionService
+	attemptGuard     *AttemptGuard
+	adminNotifier    *AdminNotifier
+	keyboards        *KeyboardCatalog
+	messenger        *Messenger
+	logger           domain.Logger
+
+	runs map[string]OperationFunc
+	mu   sync.Mutex
+}
+
+// NewOperationGuard creates a new destructive operation guard
+func NewOperationGuard(
+	operationService *services.OperationService,
+	sessionService *services.SessionService,
+	attemptGuard *AttemptGuard,
+	adminNotifier *AdminNotifier,
+	keyboards *KeyboardCatalog,
+	messenger *Messenger,
+	logger domain.Logger,
+) *OperationGuard {
+	return &OperationGuard{
+		operationService: operationService,
+		sessionService:   sessionService,
+		attemptGuard:     attemptGuard,
+		adminNotifier:    adminNotifier,
+		keyboards:        keyboards,
+		messenger:        messenger,
+		logger:           logger,
+		runs:             make(map[string]OperationFunc),
+	}
+}
+
+// Run executes the operation right away when it affects at most one ONU, otherwise starts the approval flow
+func (g *OperationGuard) Run(ctx context.Context, session *domain.Session, description, command string, run OperationFunc) error {
+	radius := unm.ClassifyCommand(command)
+	if radius != unm.BlastRadiusMultiONU {
+		return run(ctx)
+	}
+
+	operation := g.operationService.Submit(domain.DestructiveOperation{
+		Description:   description,
+		Command:       command,
+		RequestedBy:   session.UserID,
+		RequesterName: session.UserName,
+		ChatID:        session.ChatID,
+	})
+
+	// The approved run goes through the TL1 permissions of the requester, not those of the approver
+	requesterRole := domain.RoleFrom(ctx)
+
+	g.mu.Lock()
+	g.runs[operation.ID] = func(ctx context.Context) error {
+		return run(domain.WithRole(ctx, requesterRole))
+	}
+	g.mu.Unlock()
+
+	g.logger.WithFields(map[string]any{
+		"operation_id": operation.ID,
+		"user_id":      session.UserID,
+		"command":      command,
+		"radius":       radius.String(),
+	}).Warn("Operação de múltiplas ONUs solicitada")
+
+	updateSession(g.sessionService, session, func(s *domain.Session) {
+		s.State = domain.StateWaitingOperationPhrase
+	})
+
+	message := fmt.Sprintf(MSG_OPERATION_CONFIRM_PHRASE, description, command, operation.Phrase)
+	return g.messenger.SendMessage(ctx, session.ChatID, message)
+}
+
+// HandlePhraseInput validates the typed confirmation and forwards the operation to the supervisors
+func (g *OperationGuard) HandlePhraseInput(ctx context.Context, session *domain.Session, msg *domain.MessageEvent) error {
+	pending, exists := g.operationService.PendingPhrase(session.UserID)
+	if !exists {
+		updateSession(g.sessionService, session, func(s *domain.Session) {
+			s.State = domain.StateMainMenu
+		})
+		return g.messenger.SendMessage(ctx, session.ChatID, MSG_OPERATION_EXPIRED)
+	}
+
+	if strings.EqualFold(strings.TrimSpace(msg.Message), MSG_OPERATION_CANCEL_WORD) {
+		g.forget(pending.ID)
+		updateSession(g.sessionService, session, func(s *domain.Session) {
+			s.State = domain.StateMainMenu
+		})
+		return g.messenger.SendMessage(ctx, session.ChatID, MSG_OPERATION_CANCELLED)
+	}
+
+	operation, err := g.operationService.ConfirmPhrase(pending.ID, session.UserID, msg.Message)
+	if err != nil {
+		return g.attemptGuard.Reject(ctx, session, fmt.Sprintf(MSG_OPERATION_PHRASE_MISMATCH, pending.Phrase))
+	}
+
+	updateSession(g.sessionService, session, func(s *domain.Session) {
+		s.State = domain.StateMainMenu
+	})
+
+	keyboard := g.keyboards.Build(KeyboardOperation, WithTarget(operation.ID))
+
+	text := fmt.Sprintf(MSG_OPERATION_APPROVAL_REQUEST, operation.RequesterName, operation.Description, operation.Command)
+	g.adminNotifier.NotifyWithKeyboard(ctx, text, keyboard)
+
+	return g.messenger.SendMessage(ctx, session.ChatID, MSG_OPERATION_AWAITING_APPROVAL)
+}
+
+// HandleDecision processes a supervisor approval or refusal
+func (g *OperationGuard) HandleDecision(ctx context.Context, session *domain.Session, approve bool, operationID string) error {
+	if session.UserTaxID == "" || !session.UserRole.Includes(domain.RoleSupervisor) {
+		return g.messenger.SendMessage(ctx, session.ChatID, MSG_COMMAND_NOT_ALLOWED)
+	}
+
+	if !approve {
+		operation, err := g.operationService.Reject(operationID, session.UserID, session.UserName)
+		if err != nil {
+			return g.messenger.SendMessage(ctx, session.ChatID, fmt.Sprintf(MSG_OPERATION_DECISION_FAILED, err))
+		}
+
+		g.forget(operation.ID)
+		g.logger.WithFields(map[string]any{
+			"operation_id": operation.ID,
+			"rejected_by":  session.UserID,
+		}).Info("Operação de múltiplas ONUs rejeitada")
+
+		_ = g.messenger.SendMessage(ctx, operation.ChatID, fmt.Sprintf(MSG_OPERATION_REJECTED, operation.Description, session.UserName))
+		return g.messenger.SendMessage(ctx, session.ChatID, fmt.Sprintf(MSG_OPERATION_DECIDED, operation.ID))
+	}
+
+	operation, err := g.operationService.Approve(operationID, session.UserID, session.UserName)
+	if err != nil {
+		if errors.Is(err, services.ErrOperationSelfApproval) {
+			g.logger.WithField("operation_id", operationID).Warn("Tentativa de autoaprovação de operação")
+		}
+		return g.messenger.SendMessage(ctx, session.ChatID, fmt.Sprintf(MSG_OPERATION_DECISION_FAILED, err))
+	}
+
+	run := g.forget(operation.ID)
+	if run == nil {
+		return g.messenger.SendMessage(ctx, session.ChatID, MSG_OPERATION_EXPIRED)
+	}
+
+	g.logger.WithFields(map[string]any{
+		"operation_id": operation.ID,
+		"approved_by":  session.UserID,
+		"requested_by": operation.RequestedBy,
+		"command":      operation.Command,
+	}).Warn("Operação de múltiplas ONUs aprovada")
+
+	g.messenger.SendTypingIndicator(ctx, session.ChatID)
+
+	runCtx, cancel := context.WithTimeout(unm.WithApproval(ctx, operation.ID), TIMEOUT_PROVISIONING)
+	defer cancel()
+
+	result := fmt.Sprintf(MSG_OPERATION_DONE, operation.Description, session.UserName)
+	if err := run(runCtx); err != nil {
+		g.logger.WithError(err).WithField("operation_id", operation.ID).Error("Falha na operação de múltiplas ONUs")
+		result = fmt.Sprintf(MSG_OPERATION_FAILED, operation.Description, err)
+	}
+
+	_ = g.messenger.SendMessage(ctx, operation.ChatID, result)
+	return g.messenger.SendMessage(ctx, session.ChatID, result)
+}
+
+// forget removes and returns the pending execution of an operation
+func (g *OperationGuard) forget(operationID string) OperationFunc {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	run := g.runs[operationID]
+	delete(g.runs, operationID)
+	return run
+}
@@ -0,0 +1,114 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"provisioning-assistant/internal/domain"
+	"provisioning-assistant/internal/locale"
+	"provisioning-assistant/internal/pon"
+	"provisioning-assistant/internal/services"
+	"strconv"
+	"strings"
+)
+
+type OpticalBudgetHandler struct {
+	provisioningService *services.ProvisioningService
+	lastJobService      *services.LastJobService
+	formatter           *locale.Formatter
+	messenger           *Messenger
+	logger              domain.Logger
+}
+
+// NewOpticalBudgetHandler creates a new optical budget calculator handler
+func NewOpticalBudgetHandler(
+	provisioningService *services.ProvisioningService,
+	lastJobService *services.LastJobService,
+	formatter *locale.Formatter,
+	messenger *Messenger,
+	logger domain.Logger,
+) *OpticalBudgetHandler {
+	return &OpticalBudgetHandler{
+		provisioningService: provisioningService,
+		lastJobService:      lastJobService,
+		formatter:           formatter,
+		messenger:           messenger,
+		logger:              logger,
+	}
+}
+
+// RegisterCommands registers the optical budget command
+func (h *OpticalBudgetHandler) RegisterCommands(commands *CommandHandler) {
+	commands.Register("/atenuacao", domain.RoleTechnician, h.handleBudgetCommand)
+}
+
+// handleBudgetCommand estimates the reception power of a path and, when the user provisioned an ONU
+// recently, compares it with the reception measured on that ONU
+func (h *OpticalBudgetHandler) handleBudgetCommand(ctx context.Context, session *domain.Session, args []string) error {
+	if len(args) == 0 {
+		return h.messenger.SendMessage(ctx, session.ChatID, MSG_BUDGET_USAGE)
+	}
+
+	budget, err := pon.ParseBudget(args)
+	if err != nil {
+		return h.messenger.SendMessage(ctx, session.ChatID, fmt.Sprintf(MSG_BUDGET_INVALID, err)+MSG_BUDGET_USAGE)
+	}
+
+	splitters := MSG_BUDGET_NO_SPLITTERS
+	if len(budget.Splitters) > 0 {
+		ratios := make([]string, len(budget.Splitters))
+		for i, splitter := range budget.Splitters {
+			ratios[i] = "1:" + strconv.Itoa(splitter)
+		}
+		splitters = strings.Join(ratios, " + ")
+	}
+
+	message := fmt.Sprintf(
+		MSG_BUDGET_RESULT,
+		formatDbm(h.formatter, budget.TxPower),
+		h.formatter.Decimal(budget.Distance, 1),
+		h.formatter.Decimal(budget.FiberLoss(), 2),
+		splitters,
+		h.formatter.Decimal(budget.SplitterLoss(), 2),
+		h.formatter.Decimal(budget.ConnectorLoss(), 2),
+		h.formatter.Decimal(budget.Loss(), 2),
+		formatDbm(h.formatter, budget.ExpectedRx()),
+	)
+
+	return h.messenger.SendMessage(ctx, session.ChatID, message+h.compareMeasured(ctx, session, budget))
+}
+
+// compareMeasured reads the reception of the last ONU provisioned by the user and tells whether it fits
+// the estimate or points to a bad splice
+func (h *OpticalBudgetHandler) compareMeasured(ctx context.Context, session *domain.Session, budget pon.Budget) string {
+	job := h.lastJobService.Get(ctx, session.UserID)
+	if job == nil {
+		return MSG_BUDGET_NO_JOB
+	}
+
+	h.messenger.SendTypingIndicator(ctx, session.ChatID)
+
+	signalCtx, cancel := context.WithTimeout(ctx, TIMEOUT_SIGNAL_CHECK)
+	defer cancel()
+
+	signalInfo, err := h.provisioningService.CheckSignal(signalCtx, job)
+	if err != nil {
+		h.logger.WithError(err).WithField("serial", job.Serial).Error("Falha ao verificar sinal da ONU para o orçamento óptico")
+		return fmt.Sprintf(MSG_BUDGET_SIGNAL_FAILED, job.Serial)
+	}
+
+	measured, err := strconv.ParseFloat(strings.TrimSpace(signalInfo.RxPower), 64)
+	if err != nil {
+		return fmt.Sprintf(MSG_BUDGET_SIGNAL_FAILED, job.Serial)
+	}
+
+	message := fmt.Sprintf(
+		MSG_BUDGET_MEASURED,
+		job.Serial,
+		formatDbm(h.formatter, measured),
+		h.formatter.Decimal(measured-budget.ExpectedRx(), 2),
+	)
+	if budget.Suspicious(measured) {
+		return message + MSG_BUDGET_SUSPICIOUS
+	}
+	return message + MSG_BUDGET_CONSISTENT
+}
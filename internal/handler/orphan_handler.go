@@ -0,0 +1,117 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"provisioning-assistant/internal/domain"
+	"provisioning-assistant/internal/services"
+)
+
+type OrphanHandler struct {
+	orphanService *services.OrphanOnuService
+	adminNotifier *AdminNotifier
+	keyboards     *KeyboardCatalog
+	messenger     *Messenger
+	logger        domain.Logger
+}
+
+// NewOrphanHandler creates a new handler reporting the ONUs left behind by failed swaps to the supervisors
+func NewOrphanHandler(
+	orphanService *services.OrphanOnuService,
+	adminNotifier *AdminNotifier,
+	keyboards *KeyboardCatalog,
+	messenger *Messenger,
+	logger domain.Logger,
+) *OrphanHandler {
+	return &OrphanHandler{
+		orphanService: orphanService,
+		adminNotifier: adminNotifier,
+		keyboards:     keyboards,
+		messenger:     messenger,
+		logger:        logger,
+	}
+}
+
+// DetectOrphanOnus reports each newly found orphan ONU to the admin chats with buttons to remove or keep it
+func (h *OrphanHandler) DetectOrphanOnus(ctx context.Context) error {
+	orphans, err := h.orphanService.Detect(ctx)
+
+	for _, orphan := range orphans {
+		message := fmt.Sprintf(
+			MSG_ORPHAN_DETECTED,
+			orphan.Serial,
+			orphan.Contract,
+			orphan.ClientName,
+			orphan.CurrentSerial,
+			orphan.OltIP,
+			orphan.Slot,
+			orphan.Port,
+			orphan.AuditID,
+		)
+		h.adminNotifier.NotifyWithKeyboard(ctx, message, h.keyboards.Build(KeyboardOrphanOnu, WithTarget(orphan.Serial)))
+	}
+
+	return err
+}
+
+// HandleRemoveOption removes an orphan ONU from its OLT once the ERP and the OLT confirm it is still orphan
+func (h *OrphanHandler) HandleRemoveOption(ctx context.Context, session *domain.Session, serial string) error {
+	if session.UserTaxID == "" || !session.UserRole.Includes(domain.RoleSupervisor) {
+		return h.messenger.SendMessage(ctx, session.ChatID, MSG_COMMAND_NOT_ALLOWED)
+	}
+
+	if orphan, err := h.orphanService.Get(ctx, serial); err != nil || orphan.IgnoredAt != nil {
+		return h.messenger.SendMessage(ctx, session.ChatID, MSG_ORPHAN_NOT_FOUND)
+	}
+
+	h.messenger.SendTypingIndicator(ctx, session.ChatID)
+	_ = h.messenger.SendMessage(ctx, session.ChatID, fmt.Sprintf(MSG_ORPHAN_RUNNING, serial))
+
+	removeCtx, cancel := context.WithTimeout(ctx, TIMEOUT_PROVISIONING)
+	defer cancel()
+
+	orphan, err := h.orphanService.Remove(removeCtx, serial, session.UserName)
+	switch {
+	case errors.Is(err, services.ErrOrphanOnuNotFound):
+		return h.messenger.SendMessage(ctx, session.ChatID, MSG_ORPHAN_NOT_FOUND)
+	case errors.Is(err, services.ErrOrphanOnuResolved):
+		return h.messenger.SendMessage(ctx, session.ChatID, fmt.Sprintf(MSG_ORPHAN_RESOLVED, orphan.Serial))
+	case err != nil:
+		h.logger.WithError(err).WithField("serial", serial).Error("Falha ao remover ONU órfã")
+		return h.messenger.SendMessage(ctx, session.ChatID, fmt.Sprintf(MSG_ORPHAN_FAILED, err))
+	}
+
+	h.logger.WithFields(map[string]any{
+		"serial":   orphan.Serial,
+		"audit_id": orphan.AuditID,
+		"user_id":  session.UserID,
+	}).Info("ONU órfã removida")
+
+	h.adminNotifier.Notify(ctx, fmt.Sprintf(MSG_ALERT_ORPHAN, session.UserName, orphan.Serial, orphan.OltIP, orphan.Contract))
+
+	return h.messenger.SendMessage(ctx, session.ChatID, fmt.Sprintf(MSG_ORPHAN_REMOVED, orphan.Serial, orphan.OltIP, orphan.AuditID))
+}
+
+// HandleIgnoreOption keeps an orphan ONU on its OLT and stops reporting it
+func (h *OrphanHandler) HandleIgnoreOption(ctx context.Context, session *domain.Session, serial string) error {
+	if session.UserTaxID == "" || !session.UserRole.Includes(domain.RoleSupervisor) {
+		return h.messenger.SendMessage(ctx, session.ChatID, MSG_COMMAND_NOT_ALLOWED)
+	}
+
+	orphan, err := h.orphanService.Ignore(ctx, serial, session.UserName)
+	if errors.Is(err, services.ErrOrphanOnuNotFound) {
+		return h.messenger.SendMessage(ctx, session.ChatID, MSG_ORPHAN_NOT_FOUND)
+	}
+	if err != nil {
+		h.logger.WithError(err).WithField("serial", serial).Error("Falha ao ignorar ONU órfã")
+		return h.messenger.SendMessage(ctx, session.ChatID, fmt.Sprintf(MSG_ORPHAN_FAILED, err))
+	}
+
+	h.logger.WithFields(map[string]any{
+		"serial":  orphan.Serial,
+		"user_id": session.UserID,
+	}).Info("ONU órfã ignorada")
+
+	return h.messenger.SendMessage(ctx, session.ChatID, fmt.Sprintf(MSG_ORPHAN_IGNORED, orphan.Serial))
+}
@@ -0,0 +1,40 @@
+package handler
+
+import (
+	"context"
+	"provisioning-assistant/internal/clock"
+	"time"
+)
+
+// InteractionMode defines whether artificial delays are applied to conversations
+type InteractionMode string
+
+const (
+	InteractionInstant InteractionMode = "instant"
+	InteractionDemo    InteractionMode = "demo"
+)
+
+// Pacer applies simulated waits only when running in demo mode
+type Pacer struct {
+	mode  InteractionMode
+	clock clock.Clock
+}
+
+// NewPacer creates a new pacer for the given interaction mode, waiting on the given clock
+func NewPacer(mode InteractionMode, clock clock.Clock) *Pacer {
+	return &Pacer{mode: mode, clock: clock}
+}
+
+// Pause sleeps for the given duration in demo mode and returns immediately otherwise
+func (p *Pacer) Pause(ctx context.Context, duration time.Duration) {
+	if p.mode != InteractionDemo {
+		return
+	}
+
+	clock.Sleep(ctx, p.clock, duration)
+}
+
+// IsValid reports whether the interaction mode is supported
+func (m InteractionMode) IsValid() bool {
+	return m == InteractionInstant || m == InteractionDemo
+}
@@ -0,0 +1,100 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"provisioning-assistant/internal/domain"
+	"provisioning-assistant/internal/services"
+)
+
+type PhotoHandler struct {
+	auditService   *services.AuditService
+	sessionService *services.SessionService
+	speedTest      *SpeedTestHandler
+	keyboards      *KeyboardCatalog
+	messenger      *Messenger
+	logger         domain.Logger
+}
+
+// NewPhotoHandler creates a new proof-of-installation photo handler instance
+func NewPhotoHandler(
+	auditService *services.AuditService,
+	sessionService *services.SessionService,
+	speedTest *SpeedTestHandler,
+	keyboards *KeyboardCatalog,
+	messenger *Messenger,
+	logger domain.Logger,
+) *PhotoHandler {
+	return &PhotoHandler{
+		auditService:   auditService,
+		sessionService: sessionService,
+		speedTest:      speedTest,
+		keyboards:      keyboards,
+		messenger:      messenger,
+		logger:         logger,
+	}
+}
+
+// RequestPhotos moves the session to the photo step of the given audit record
+func (h *PhotoHandler) RequestPhotos(ctx context.Context, session *domain.Session, auditID string) error {
+	updateSession(h.sessionService, session, func(s *domain.Session) {
+		s.AuditID = auditID
+		s.State = domain.StateWaitingPhotos
+	})
+
+	return h.messenger.SendMessageWithKeyboard(ctx, session.ChatID, MSG_REQUEST_PHOTOS, h.doneKeyboard())
+}
+
+// HandlePhotoInput attaches a received photo to the session audit record
+func (h *PhotoHandler) HandlePhotoInput(ctx context.Context, session *domain.Session, msg *domain.MessageEvent) error {
+	if msg.PhotoFileID == "" {
+		return h.messenger.SendMessageWithKeyboard(ctx, msg.ChatID, MSG_PHOTO_EXPECTED, h.doneKeyboard())
+	}
+
+	record, err := h.auditService.AttachPhoto(ctx, session.AuditID, msg.PhotoFileID, msg.PhotoCaption)
+	if err != nil {
+		h.logger.WithError(err).WithField("audit_id", session.AuditID).Error("Falha ao anexar foto da instalação")
+		return h.finish(ctx, session, 0)
+	}
+
+	count := len(record.Attachments)
+	if count >= MAX_INSTALLATION_PHOTOS {
+		return h.finish(ctx, session, count)
+	}
+
+	message := fmt.Sprintf(MSG_PHOTO_RECEIVED, count, MAX_INSTALLATION_PHOTOS)
+	return h.messenger.SendMessageWithKeyboard(ctx, msg.ChatID, message, h.doneKeyboard())
+}
+
+// HandlePhotoOption processes the photo step keyboard selection
+func (h *PhotoHandler) HandlePhotoOption(ctx context.Context, session *domain.Session, option string) error {
+	if session.State != domain.StateWaitingPhotos || option != "done" {
+		return nil
+	}
+
+	count := 0
+	if record, err := h.auditService.GetRecord(ctx, session.AuditID); err == nil {
+		count = len(record.Attachments)
+	}
+
+	return h.finish(ctx, session, count)
+}
+
+// finish closes the photo step, handing over to the speed test survey when enabled or resetting the session
+func (h *PhotoHandler) finish(ctx context.Context, session *domain.Session, count int) error {
+	if h.speedTest.IsEnabled() {
+		return h.speedTest.Request(ctx, session, count)
+	}
+
+	updateSession(h.sessionService, session, func(s *domain.Session) {
+		s.State = domain.StateIdle
+		s.AuditID = ""
+	})
+
+	return h.messenger.SendMessage(ctx, session.ChatID, fmt.Sprintf(MSG_PHOTOS_FINISHED, count))
+}
+
+// doneKeyboard builds the keyboard used to finish the photo step
+func (h *PhotoHandler) doneKeyboard() *domain.Keyboard {
+	return h.keyboards.Build(KeyboardPhotos)
+}
@@ -0,0 +1,94 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"provisioning-assistant/internal/domain"
+	"provisioning-assistant/internal/locale"
+	"provisioning-assistant/internal/services"
+)
+
+// languageNames are the languages as shown on the preferences screen
+var languageNames = map[string]string{
+	locale.LanguagePortuguese: "Português",
+	locale.LanguageEnglish:    "English",
+}
+
+type PreferencesHandler struct {
+	bindingService *services.BindingService
+	keyboards      *KeyboardCatalog
+	messenger      *Messenger
+	logger         domain.Logger
+}
+
+// NewPreferencesHandler creates a new user preferences handler
+func NewPreferencesHandler(
+	bindingService *services.BindingService,
+	keyboards *KeyboardCatalog,
+	messenger *Messenger,
+	logger domain.Logger,
+) *PreferencesHandler {
+	return &PreferencesHandler{
+		bindingService: bindingService,
+		keyboards:      keyboards,
+		messenger:      messenger,
+		logger:         logger,
+	}
+}
+
+// RegisterCommands registers the preferences command
+func (h *PreferencesHandler) RegisterCommands(commands *CommandHandler) {
+	commands.Register("/config", domain.RoleTechnician, h.handleConfigCommand)
+}
+
+// handleConfigCommand shows the preferences of the user with the buttons that change them
+func (h *PreferencesHandler) handleConfigCommand(ctx context.Context, session *domain.Session, args []string) error {
+	return h.sendPreferences(ctx, session, h.bindingService.Preferences(ctx, session.UserID))
+}
+
+// HandlePreferenceOption toggles the chosen preference and shows the screen again
+func (h *PreferencesHandler) HandlePreferenceOption(ctx context.Context, session *domain.Session, option string) error {
+	var apply func(*domain.Preferences)
+
+	switch option {
+	case "compact":
+		apply = func(p *domain.Preferences) { p.Compact = !p.Compact }
+	case "alerts":
+		apply = func(p *domain.Preferences) { p.SignalAlerts = !p.SignalAlerts }
+	case "language":
+		apply = func(p *domain.Preferences) { p.Language = locale.NextLanguage(p.Language) }
+	case "done":
+		return h.messenger.SendMessage(ctx, session.ChatID, MSG_PREFS_SAVED)
+	default:
+		return nil
+	}
+
+	preferences, err := h.bindingService.UpdatePreferences(ctx, session.UserID, session.ChatID, apply)
+	if err != nil {
+		h.logger.WithError(err).WithField("user_id", session.UserID).Error("Falha ao salvar preferências do usuário")
+		return h.messenger.SendMessage(ctx, session.ChatID, MSG_PREFS_FAILED)
+	}
+
+	return h.sendPreferences(ctx, session, preferences)
+}
+
+// sendPreferences renders the current preferences with the toggle buttons
+func (h *PreferencesHandler) sendPreferences(ctx context.Context, session *domain.Session, preferences domain.Preferences) error {
+	format := MSG_PREFS_VERBOSE
+	if preferences.Compact {
+		format = MSG_PREFS_COMPACT
+	}
+
+	alerts := MSG_PREFS_OFF
+	if preferences.SignalAlerts {
+		alerts = MSG_PREFS_ON
+	}
+
+	language, known := languageNames[preferences.Language]
+	if !known {
+		language = languageNames[locale.LanguagePortuguese]
+	}
+
+	message := fmt.Sprintf(MSG_PREFS_HEADER, format, alerts, language)
+	return h.messenger.SendMessageWithKeyboard(ctx, session.ChatID, message, h.keyboards.Build(KeyboardPreferences))
+}
@@ -0,0 +1,49 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"provisioning-assistant/internal/locale"
+	"provisioning-assistant/internal/services"
+)
+
+type ProbeHandler struct {
+	probeService   *services.UnmProbeService
+	circuitService *services.ProvisioningCircuitService
+	adminNotifier  *AdminNotifier
+	formatter      *locale.Formatter
+}
+
+// NewProbeHandler creates a new handler alerting on the UNM endpoints found down by the synthetic probe
+func NewProbeHandler(
+	probeService *services.UnmProbeService,
+	circuitService *services.ProvisioningCircuitService,
+	adminNotifier *AdminNotifier,
+	formatter *locale.Formatter,
+) *ProbeHandler {
+	return &ProbeHandler{
+		probeService:   probeService,
+		circuitService: circuitService,
+		adminNotifier:  adminNotifier,
+		formatter:      formatter,
+	}
+}
+
+// ProbeUnm probes every UNM endpoint and tells the admins when one stops or resumes answering, or when the
+// failed probes suspend automatic provisioning
+func (h *ProbeHandler) ProbeUnm(ctx context.Context) error {
+	for _, result := range h.probeService.Probe(ctx) {
+		switch result.Transition {
+		case services.ProbeDown:
+			h.adminNotifier.NotifyCritical(ctx, fmt.Sprintf(MSG_ALERT_PROBE_DOWN, result.Endpoint, result.Failures, result.Err))
+		case services.ProbeRecovered:
+			h.adminNotifier.Notify(ctx, fmt.Sprintf(MSG_ALERT_PROBE_RECOVERED, result.Endpoint, h.formatter.Duration(result.DownFor)))
+		}
+
+		if result.Circuit == services.CircuitTripped {
+			h.adminNotifier.NotifyCritical(ctx, fmt.Sprintf(MSG_ALERT_CIRCUIT_OPEN, h.formatter.Percent(h.circuitService.SuccessRate())))
+		}
+	}
+
+	return nil
+}
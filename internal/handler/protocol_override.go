@@ -0,0 +1,104 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"provisioning-assistant/internal/domain"
+	"strconv"
+)
+
+// protocolWarning describes why the protocol needs an override
+func (h *ProvisioningHandler) protocolWarning(check domain.ProtocolCheck) string {
+	switch check.Issue {
+	case domain.ProtocolIssueCancelled:
+		return fmt.Sprintf(MSG_PROTOCOL_WARNING_CANCELLED, check.Status)
+	case domain.ProtocolIssueClosed:
+		return fmt.Sprintf(MSG_PROTOCOL_WARNING_CLOSED, check.Status)
+	case domain.ProtocolIssueProvisioned:
+		previous := check.PreviousAudit
+		return fmt.Sprintf(
+			MSG_PROTOCOL_WARNING_PROVISIONED,
+			h.formatter.DateTime(previous.CreatedAt),
+			previous.TechnicianName,
+			previous.ID,
+		)
+	default:
+		return ""
+	}
+}
+
+// requestOverride asks the supervisors to authorize a protocol flagged by the pre-check
+func (h *ProvisioningHandler) requestOverride(ctx context.Context, session *domain.Session) error {
+	userID := strconv.FormatInt(session.UserID, 10)
+	warning := h.protocolWarning(session.ProtocolCheck)
+
+	keyboard := h.keyboards.Build(KeyboardOverride, WithTarget(userID))
+
+	h.adminNotifier.NotifyWithKeyboard(ctx, fmt.Sprintf(
+		MSG_PROTOCOL_OVERRIDE_REQUEST,
+		session.UserName,
+		session.Protocol,
+		session.ConnectionInfo.ContractDescription,
+		warning,
+	), keyboard)
+
+	return h.messenger.SendMessage(ctx, session.ChatID, fmt.Sprintf(MSG_PROTOCOL_OVERRIDE_REQUESTED, warning))
+}
+
+// HandleOverrideWaiting answers messages sent while the technician waits for a supervisor
+func (h *ProvisioningHandler) HandleOverrideWaiting(ctx context.Context, session *domain.Session, msg *domain.MessageEvent) error {
+	return h.messenger.SendMessage(ctx, session.ChatID, fmt.Sprintf(MSG_PROTOCOL_OVERRIDE_WAITING, session.Protocol))
+}
+
+// HandleOverrideDecision applies a supervisor decision to the technician waiting for it
+func (h *ProvisioningHandler) HandleOverrideDecision(ctx context.Context, session *domain.Session, approve bool, target string) error {
+	if session.UserTaxID == "" || !session.UserRole.Includes(domain.RoleSupervisor) {
+		return h.messenger.SendMessage(ctx, session.ChatID, MSG_COMMAND_NOT_ALLOWED)
+	}
+
+	userID, err := strconv.ParseInt(target, 10, 64)
+	if err != nil {
+		return h.messenger.SendMessage(ctx, session.ChatID, MSG_PROTOCOL_OVERRIDE_EXPIRED)
+	}
+
+	technician := h.sessionService.GetSession(userID)
+	if technician == nil || technician.State != domain.StateWaitingOverride {
+		return h.messenger.SendMessage(ctx, session.ChatID, MSG_PROTOCOL_OVERRIDE_EXPIRED)
+	}
+
+	if technician.UserID == session.UserID {
+		return h.messenger.SendMessage(ctx, session.ChatID, MSG_PROTOCOL_OVERRIDE_SELF_DENIED)
+	}
+
+	h.logger.WithFields(map[string]any{
+		"protocol":      technician.Protocol,
+		"issue":         technician.ProtocolCheck.Issue,
+		"technician_id": technician.UserID,
+		"supervisor_id": session.UserID,
+		"approved":      approve,
+	}).Warn("Decisão de liberação de protocolo registrada")
+
+	protocol := technician.Protocol
+
+	if !approve {
+		updateSession(h.sessionService, technician, func(s *domain.Session) {
+			s.State = domain.StateIdle
+			s.ConnectionInfo = nil
+			s.ProtocolCheck = domain.ProtocolCheck{}
+		})
+
+		_ = h.messenger.SendMessage(ctx, technician.ChatID, fmt.Sprintf(MSG_PROTOCOL_OVERRIDE_REJECTED, session.UserName, protocol))
+		return h.messenger.SendMessage(ctx, session.ChatID, fmt.Sprintf(MSG_PROTOCOL_OVERRIDE_DECIDED, protocol))
+	}
+
+	updateSession(h.sessionService, technician, func(s *domain.Session) {
+		s.OverrideBy = session.UserName
+		s.State = domain.StateConfirmData
+	})
+
+	if err := h.sendConfirmationRequest(ctx, technician); err != nil {
+		return err
+	}
+
+	return h.messenger.SendMessage(ctx, session.ChatID, fmt.Sprintf(MSG_PROTOCOL_OVERRIDE_DECIDED, protocol))
+}
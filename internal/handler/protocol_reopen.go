@@ -0,0 +1,114 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"provisioning-assistant/internal/domain"
+	"strings"
+)
+
+// ReopenProtocol fetches a finished protocol from the ERP again to provision it once more, linked to its
+// previous audit record. A serial swaps the ONU, the replaced one is removed once the new one is up.
+func (h *ProvisioningHandler) ReopenProtocol(ctx context.Context, session *domain.Session, record *domain.AuditRecord, serial string) error {
+	started := h.clock.Now()
+	connectionInfo, err := h.fetchConnectionInfo(ctx, session.ChatID, record.Protocol)
+	fetchTime := h.clock.Since(started)
+
+	if err != nil {
+		h.logger.WithError(err).WithField("protocol", record.Protocol).Error("Falha ao buscar informações de conexão")
+		return h.messenger.SendMessage(ctx, session.ChatID, MSG_PROTOCOL_NOT_FOUND)
+	}
+
+	replaced := ""
+	if serial != "" {
+		connectionInfo.ConnectionEquipmentSerialNumber = serial
+		if !strings.EqualFold(serial, record.Serial) {
+			replaced = record.Serial
+		}
+	}
+
+	// Reopening is the decision to work on a finished protocol again, only a cancellation still needs a supervisor
+	check := h.protocolCheck.Check(ctx, record.Protocol, connectionInfo)
+	if check.Issue == domain.ProtocolIssueClosed || check.Issue == domain.ProtocolIssueProvisioned {
+		check.Issue = domain.ProtocolIssueNone
+	}
+
+	h.updateSessionWithConnectionInfo(session, record.Protocol, connectionInfo, fetchTime, check)
+	updateSession(h.sessionService, session, func(s *domain.Session) {
+		s.ReopenedFrom = record.ID
+		s.ReplacedSerial = replaced
+	})
+
+	h.logger.WithFields(map[string]any{
+		"protocol": record.Protocol,
+		"audit_id": record.ID,
+		"replaced": replaced,
+	}).Info("Protocolo reaberto")
+
+	if session.State == domain.StateWaitingOverride {
+		return h.requestOverride(ctx, session)
+	}
+
+	return h.sendConfirmationRequest(ctx, session)
+}
+
+// reopenNotice describes the reopened protocol on the confirmation request
+func reopenNotice(session *domain.Session) string {
+	if session.ReopenedFrom == "" {
+		return ""
+	}
+
+	notice := fmt.Sprintf(MSG_REOPEN_CONFIRM, session.ReopenedFrom)
+	if session.ReplacedSerial != "" {
+		notice += fmt.Sprintf(MSG_REOPEN_CONFIRM_SWAP, session.ReplacedSerial)
+	}
+	return notice + "\n"
+}
+
+// removeReplacedOnu deletes the ONU swapped out by a reopened protocol, returning the note for the technician
+func (h *ProvisioningHandler) removeReplacedOnu(ctx context.Context, session *domain.Session) string {
+	previous, err := h.auditService.GetRecord(ctx, session.ReopenedFrom)
+	if err != nil {
+		h.logger.WithError(err).WithField("audit_id", session.ReopenedFrom).Warn("Registro da ONU substituída não encontrado")
+		return fmt.Sprintf(MSG_REOPEN_REPLACED_LEFT, session.ReplacedSerial)
+	}
+
+	err = h.provisioningService.RemoveReplacedOnu(ctx, &domain.LastJob{
+		Serial: previous.Serial,
+		OltIP:  previous.OltIP,
+		Slot:   previous.Slot,
+		Port:   previous.Port,
+	})
+	if err != nil {
+		h.logger.WithError(err).WithField("serial", previous.Serial).Error("Falha ao remover ONU substituída")
+		return fmt.Sprintf(MSG_REOPEN_REPLACED_LEFT, previous.Serial)
+	}
+
+	return fmt.Sprintf(MSG_REOPEN_REPLACED_REMOVED, previous.Serial)
+}
+
+// replacedRegistration returns where the contract's ONU was registered before this job, from the audit record
+// the protocol was reopened from or else the latest one of the contract, nil when the bot never provisioned it
+func (h *ProvisioningHandler) replacedRegistration(ctx context.Context, session *domain.Session) *domain.LastJob {
+	var record *domain.AuditRecord
+	var err error
+
+	switch {
+	case session.ReopenedFrom != "":
+		record, err = h.auditService.GetRecord(ctx, session.ReopenedFrom)
+	case session.ConnectionInfo != nil && session.ConnectionInfo.ContractDescription != "":
+		record, err = h.auditService.FindLatestByContract(ctx, session.ConnectionInfo.ContractDescription)
+	default:
+		return nil
+	}
+	if err != nil {
+		return nil
+	}
+
+	return &domain.LastJob{
+		Serial: record.Serial,
+		OltIP:  record.OltIP,
+		Slot:   record.Slot,
+		Port:   record.Port,
+	}
+}
@@ -0,0 +1,112 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"provisioning-assistant/internal/domain"
+	"strings"
+)
+
+// contractOnu returns the audit record of the ONU last activated for the contract when it is not the one
+// being provisioned, nil when there is none or the provisioning already replaces it
+func (h *ProvisioningHandler) contractOnu(ctx context.Context, session *domain.Session) *domain.AuditRecord {
+	connInfo := session.ConnectionInfo
+	if connInfo == nil || connInfo.ContractDescription == "" || session.ReplacedSerial != "" {
+		return nil
+	}
+
+	record, err := h.auditService.FindLatestByContract(ctx, connInfo.ContractDescription)
+	if err != nil || strings.EqualFold(record.Serial, connInfo.ConnectionEquipmentSerialNumber) {
+		return nil
+	}
+	return record
+}
+
+// activeDuplicate returns the audit record of another ONU of the contract still online on its OLT, which the
+// provisioning would leave next to a second ONU for the same contract
+func (h *ProvisioningHandler) activeDuplicate(ctx context.Context, session *domain.Session) *domain.AuditRecord {
+	record := h.contractOnu(ctx, session)
+	if record == nil {
+		return nil
+	}
+
+	// An ONU of the PON read while the confirmation was on screen needs no query of its own
+	signals, _ := h.provisioningService.PrefetchedPon(ctx, record.OltIP, record.Slot, record.Port)
+	if signal := signals[strings.ToUpper(record.Serial)]; signal != nil && signal.RxPower != "" {
+		return record
+	}
+
+	h.messenger.SendTypingIndicator(ctx, session.ChatID)
+
+	signalCtx, cancel := context.WithTimeout(ctx, TIMEOUT_SIGNAL_CHECK)
+	defer cancel()
+
+	// An ONU the OLT cannot read is offline or gone, the new one does not duplicate it
+	signalInfo, err := h.provisioningService.CheckSignal(signalCtx, &domain.LastJob{
+		Serial: record.Serial,
+		OltIP:  record.OltIP,
+		Slot:   record.Slot,
+		Port:   record.Port,
+	})
+	if err != nil || signalInfo.RxPower == "" {
+		return nil
+	}
+
+	return record
+}
+
+// requestReplacement warns that the contract already has an ONU online, provisioning only goes on as its
+// replacement
+func (h *ProvisioningHandler) requestReplacement(ctx context.Context, session *domain.Session, record *domain.AuditRecord) error {
+	h.logger.WithFields(map[string]any{
+		"protocol": session.Protocol,
+		"contract": record.Contract,
+		"active":   record.Serial,
+		"serial":   session.ConnectionInfo.ConnectionEquipmentSerialNumber,
+	}).Warn("Contrato já possui ONU ativa com outro serial")
+
+	message := fmt.Sprintf(
+		MSG_DUPLICATE_WARNING,
+		record.Contract,
+		record.Serial,
+		record.OltIP,
+		record.Slot,
+		record.Port,
+		session.ConnectionInfo.ConnectionEquipmentSerialNumber,
+	)
+	return h.messenger.SendMessageWithKeyboard(ctx, session.ChatID, message, h.keyboards.Build(KeyboardDuplicate))
+}
+
+// HandleDuplicateOption turns the provisioning into the swap of the ONU the contract already has, the
+// replaced one being removed once the new one is up, or gives up on it
+func (h *ProvisioningHandler) HandleDuplicateOption(ctx context.Context, session *domain.Session, option string) error {
+	if !h.confirmer.IsPending(session) {
+		return nil
+	}
+
+	if h.confirmer.IsExpired(session) {
+		return h.resendConfirmation(ctx, session)
+	}
+
+	if option != "replace" {
+		updateSession(h.sessionService, session, func(s *domain.Session) {
+			s.State = domain.StateIdle
+		})
+		return h.messenger.SendMessage(ctx, session.ChatID, MSG_DUPLICATE_CANCELLED)
+	}
+
+	if record := h.contractOnu(ctx, session); record != nil {
+		updateSession(h.sessionService, session, func(s *domain.Session) {
+			s.ReopenedFrom = record.ID
+			s.ReplacedSerial = record.Serial
+		})
+
+		h.logger.WithFields(map[string]any{
+			"protocol": session.Protocol,
+			"audit_id": record.ID,
+			"replaced": record.Serial,
+		}).Info("Provisionamento convertido em substituição da ONU do contrato")
+	}
+
+	return h.startConfirmed(ctx, session)
+}
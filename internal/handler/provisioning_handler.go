@@ -1,13 +1,22 @@
 package handler
 
 import (
+	"cmp"
 	"context"
+	"errors"
 	"fmt"
+	"provisioning-assistant/internal/clock"
 	"provisioning-assistant/internal/domain"
 	"provisioning-assistant/internal/domain/dto"
+	"provisioning-assistant/internal/ids"
+	"provisioning-assistant/internal/locale"
+	"provisioning-assistant/internal/paste"
 	"provisioning-assistant/internal/services"
+	"provisioning-assistant/internal/unm"
+	"provisioning-assistant/internal/validation"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gookit/event"
 )
@@ -15,7 +24,25 @@ import (
 type ProvisioningHandler struct {
 	provisioningService *services.ProvisioningService
 	erpService          *services.ErpService
+	protocolCheck       *services.ProtocolCheckService
 	sessionService      *services.SessionService
+	auditService        *services.AuditService
+	lastJobService      *services.LastJobService
+	circuitService      *services.ProvisioningCircuitService
+	queue               *services.ProvisioningQueue
+	idempotencyService  *services.IdempotencyService
+	maintenanceService  *services.MaintenanceService
+	bindingService      *services.BindingService
+	adminNotifier       *AdminNotifier
+	attemptGuard        *AttemptGuard
+	photoHandler        *PhotoHandler
+	signalHandler       *SignalHandler
+	manualHandler       *ManualProvisioningHandler
+	confirmer           *Confirmer
+	successPolicy       SuccessMessagePolicy
+	clock               clock.Clock
+	formatter           *locale.Formatter
+	keyboards           *KeyboardCatalog
 	messenger           *Messenger
 	eventManager        *event.Manager
 	logger              domain.Logger
@@ -25,7 +52,25 @@ type ProvisioningHandler struct {
 func NewProvisioningHandler(
 	provisioningService *services.ProvisioningService,
 	erpService *services.ErpService,
+	protocolCheck *services.ProtocolCheckService,
 	sessionService *services.SessionService,
+	auditService *services.AuditService,
+	lastJobService *services.LastJobService,
+	circuitService *services.ProvisioningCircuitService,
+	queue *services.ProvisioningQueue,
+	idempotencyService *services.IdempotencyService,
+	maintenanceService *services.MaintenanceService,
+	bindingService *services.BindingService,
+	adminNotifier *AdminNotifier,
+	attemptGuard *AttemptGuard,
+	photoHandler *PhotoHandler,
+	signalHandler *SignalHandler,
+	manualHandler *ManualProvisioningHandler,
+	confirmer *Confirmer,
+	successPolicy SuccessMessagePolicy,
+	clock clock.Clock,
+	formatter *locale.Formatter,
+	keyboards *KeyboardCatalog,
 	messenger *Messenger,
 	eventManager *event.Manager,
 	logger domain.Logger,
@@ -33,7 +78,25 @@ func NewProvisioningHandler(
 	return &ProvisioningHandler{
 		provisioningService: provisioningService,
 		erpService:          erpService,
+		protocolCheck:       protocolCheck,
 		sessionService:      sessionService,
+		auditService:        auditService,
+		lastJobService:      lastJobService,
+		circuitService:      circuitService,
+		queue:               queue,
+		idempotencyService:  idempotencyService,
+		maintenanceService:  maintenanceService,
+		bindingService:      bindingService,
+		adminNotifier:       adminNotifier,
+		attemptGuard:        attemptGuard,
+		photoHandler:        photoHandler,
+		signalHandler:       signalHandler,
+		manualHandler:       manualHandler,
+		confirmer:           confirmer,
+		successPolicy:       successPolicy,
+		clock:               clock,
+		formatter:           formatter,
+		keyboards:           keyboards,
 		messenger:           messenger,
 		eventManager:        eventManager,
 		logger:              logger,
@@ -41,162 +104,664 @@ func NewProvisioningHandler(
 }
 
 // HandleProtocolInput processes protocol number input from user
-func (h *ProvisioningHandler) HandleProtocolInput(session *domain.Session, msg *domain.MessageEvent) error {
+func (h *ProvisioningHandler) HandleProtocolInput(ctx context.Context, session *domain.Session, msg *domain.MessageEvent) error {
 	protocol := strings.TrimSpace(msg.Message)
 
+	// Anything but a number may be a pasted ERP screen carrying the protocol and other fields
+	var pasted paste.Fields
 	if _, err := strconv.ParseInt(protocol, 10, 64); err != nil {
-		return h.messenger.SendMessage(msg.ChatID, MSG_PROTOCOL_INVALID)
+		pasted = paste.Parse(msg.Message)
+
+		switch {
+		case pasted.Protocol != "":
+			protocol = pasted.Protocol
+			_ = h.messenger.SendMessage(ctx, msg.ChatID, formatPastedFields(pasted))
+		case pasted.Count() >= paste.MinFields && h.manualHandler.IsAllowed(session):
+			return h.manualHandler.Prefill(ctx, session, pasted)
+		case pasted.Count() > 0:
+			return h.attemptGuard.Reject(ctx, session, MSG_PASTE_PROTOCOL_MISSING)
+		default:
+			return h.attemptGuard.Reject(ctx, session, MSG_PROTOCOL_INVALID)
+		}
 	}
 
-	connectionInfo, err := h.fetchConnectionInfo(msg.ChatID, protocol)
+	started := h.clock.Now()
+	connectionInfo, err := h.fetchConnectionInfo(ctx, msg.ChatID, protocol)
+	fetchTime := h.clock.Since(started)
+	domain.Benchmark(h.logger, "erp_fetch", fetchTime)
+
 	if err != nil {
 		h.logger.WithError(err).WithField("protocol", protocol).Error("Falha ao buscar informações de conexão")
-		return h.messenger.SendMessage(msg.ChatID, MSG_PROTOCOL_NOT_FOUND)
+		return h.attemptGuard.Reject(ctx, session, MSG_PROTOCOL_NOT_FOUND)
 	}
 
-	h.updateSessionWithConnectionInfo(session, protocol, connectionInfo)
+	h.mergePastedFields(ctx, msg.ChatID, connectionInfo, pasted)
+
+	check := h.protocolCheck.Check(ctx, protocol, connectionInfo)
+	h.updateSessionWithConnectionInfo(session, protocol, connectionInfo, fetchTime, check)
+
+	if session.State == domain.StateWaitingOverride {
+		return h.requestOverride(ctx, session)
+	}
 
-	return h.sendConfirmationRequest(session)
+	return h.sendConfirmationRequest(ctx, session)
 }
 
 // fetchConnectionInfo retrieves connection information from ERP system
-func (h *ProvisioningHandler) fetchConnectionInfo(chatID int64, protocol string) (*dto.ConnectionInfo, error) {
-	h.messenger.SendTypingIndicator(chatID)
-	_ = h.messenger.SendMessage(chatID, MSG_SEARCHING_INFO)
+func (h *ProvisioningHandler) fetchConnectionInfo(ctx context.Context, chatID int64, protocol string) (*dto.ConnectionInfo, error) {
+	h.messenger.SendTypingIndicator(ctx, chatID)
+	_ = h.messenger.SendMessage(ctx, chatID, erpWaitMessage(h.erpService, MSG_SEARCHING_INFO))
 
-	ctx, cancel := context.WithTimeout(context.Background(), TIMEOUT_ERP_FETCH)
+	fetchCtx, cancel := context.WithTimeout(ctx, TIMEOUT_ERP_FETCH)
 	defer cancel()
 
-	return h.erpService.GetConnectionInfo(ctx, protocol)
+	return h.erpService.GetConnectionInfo(fetchCtx, protocol)
 }
 
-// updateSessionWithConnectionInfo updates session with connection data and state
+// mergePastedFields completes the ERP data with the pasted values, the ERP stays authoritative
+// and a diverging serial is pointed out to the technician
+func (h *ProvisioningHandler) mergePastedFields(ctx context.Context, chatID int64, connectionInfo *dto.ConnectionInfo, pasted paste.Fields) {
+	if pasted.Count() == 0 {
+		return
+	}
+
+	erpSerial := connectionInfo.ConnectionEquipmentSerialNumber
+	pastedSerial := pasted.Info.ConnectionEquipmentSerialNumber
+	if pastedSerial != "" && erpSerial != "" && !strings.EqualFold(pastedSerial, erpSerial) {
+		_ = h.messenger.SendMessage(ctx, chatID, fmt.Sprintf(MSG_PASTE_SERIAL_MISMATCH, pastedSerial, erpSerial))
+	}
+
+	paste.Merge(connectionInfo, &pasted.Info)
+}
+
+// erpWaitMessage warns that the ERP is slow instead of the usual wait message when lookups are lagging
+func erpWaitMessage(erpService *services.ErpService, message string) string {
+	if !erpService.IsSlow() {
+		return message
+	}
+
+	_, p95 := erpService.Latency()
+	return fmt.Sprintf(MSG_ERP_SLOW, int(p95.Round(time.Second).Seconds()))
+}
+
+// updateSessionWithConnectionInfo updates session with connection data and state, protocols needing an
+// override wait for a supervisor unless the user is one, in which case the confirmation is the override
 func (h *ProvisioningHandler) updateSessionWithConnectionInfo(
 	session *domain.Session,
 	protocol string,
 	connectionInfo *dto.ConnectionInfo,
+	fetchTime time.Duration,
+	check domain.ProtocolCheck,
 ) {
-	session.Protocol = protocol
-	session.ConnectionInfo = connectionInfo
-	session.State = domain.StateConfirmData
-	h.sessionService.UpdateSession(session)
+	updateSession(h.sessionService, session, func(s *domain.Session) {
+		s.Manual = false
+		s.Protocol = protocol
+		s.ConnectionInfo = connectionInfo
+		s.ErpFetchTime = fetchTime
+		s.ProtocolCheck = check
+		s.OverrideBy = ""
+		s.ReopenedFrom = ""
+		s.ReplacedSerial = ""
+		s.State = domain.StateConfirmData
+
+		if check.RequiresOverride() {
+			if s.UserRole.Includes(domain.RoleSupervisor) {
+				s.OverrideBy = s.UserName
+			} else {
+				s.State = domain.StateWaitingOverride
+			}
+		}
+	})
 }
 
 // sendConfirmationRequest sends confirmation message with connection details
-func (h *ProvisioningHandler) sendConfirmationRequest(session *domain.Session) error {
-	keyboard := &domain.Keyboard{
-		Inline: true,
-		Buttons: [][]domain.Button{
-			{
-				{Text: MSG_CONFIRM_YES, Data: "confirm:yes"},
-				{Text: MSG_CONFIRM_NO, Data: "confirm:no"},
-			},
+func (h *ProvisioningHandler) sendConfirmationRequest(ctx context.Context, session *domain.Session) error {
+	notice := ""
+	if session.ConnectionInfo.ContractVIP {
+		notice = MSG_VIP_BADGE
+	}
+	if session.ProtocolCheck.RequiresOverride() {
+		notice = h.protocolWarning(session.ProtocolCheck)
+		if session.OverrideBy == session.UserName {
+			notice += MSG_PROTOCOL_OVERRIDE_SELF
+		} else {
+			notice += fmt.Sprintf(MSG_PROTOCOL_OVERRIDE_GRANTED, session.OverrideBy)
+		}
+	}
+
+	connInfo := session.ConnectionInfo
+	contact := cmp.Or(connInfo.Contact, &dto.ContactInfo{})
+
+	// The PON is read while the technician reviews the data, the provisioning then starts on a warm session
+	h.provisioningService.PrefetchPon(ctx, connInfo)
+
+	// The address and phone catch a wrong dispatch before the OLT is touched
+	return h.confirmer.Ask(ctx, session, Confirmation{
+		Notice: notice + reopenNotice(session),
+		Title:  MSG_CONFIRM_TITLE,
+		Fields: []ConfirmationField{
+			{Label: MSG_CONFIRM_CONTRACT, Value: connInfo.ContractDescription},
+			{Label: MSG_CONFIRM_ADDRESS, Value: contact.Address(), Optional: true},
+			{Label: MSG_CONFIRM_PHONE, Value: contact.Phone, Optional: true},
+			{Label: MSG_CONFIRM_PLAN, Value: connInfo.ContractPlanName},
+			{Label: MSG_CONFIRM_ASSIGNMENT, Value: connInfo.AssignmentTitle},
+			{Label: MSG_CONFIRM_SERIAL, Value: connInfo.ConnectionEquipmentSerialNumber},
+			{Label: MSG_CONFIRM_SPLITTER, Value: connInfo.ConnectionClientSplitterName},
+			{Label: MSG_CONFIRM_SPLITTER_PORT, Value: connInfo.ConnectionClientSplitterPort},
+			{Label: MSG_CONFIRM_EXTRA_WAN, Value: extraWanSummary(connInfo.ExtraWanServices), Optional: true},
 		},
+		Question: MSG_CONFIRM_QUESTION,
+	})
+}
+
+// HandleConfirmation processes user confirmation response for provisioning, an expired summary
+// is sent again so the technician reviews the data before anything runs
+func (h *ProvisioningHandler) HandleConfirmation(ctx context.Context, session *domain.Session, answer string) error {
+	if !h.confirmer.IsPending(session) {
+		return nil
 	}
 
-	message := fmt.Sprintf(
-		MSG_CONFIRM_DATA,
-		session.ConnectionInfo.ContractDescription,
-		session.ConnectionInfo.AssignmentTitle,
-		session.ConnectionInfo.ConnectionEquipmentSerialNumber,
-		session.ConnectionInfo.ConnectionClientSplitterName,
-		session.ConnectionInfo.ConnectionClientSplitterPort,
-	)
+	if h.confirmer.IsExpired(session) {
+		return h.resendConfirmation(ctx, session)
+	}
+
+	switch answer {
+	case ConfirmationYes:
+		if record := h.activeDuplicate(ctx, session); record != nil {
+			return h.requestReplacement(ctx, session, record)
+		}
+		return h.startConfirmed(ctx, session)
+	case ConfirmationEdit:
+		return h.handleConfirmationEdit(ctx, session)
+	default:
+		return h.handleConfirmationDenied(ctx, session)
+	}
+}
 
-	return h.messenger.SendMessageWithKeyboard(session.ChatID, message, keyboard)
+// startConfirmed runs the confirmed provisioning, unless the OLT is in a maintenance window the technician
+// did not acknowledge yet
+func (h *ProvisioningHandler) startConfirmed(ctx context.Context, session *domain.Session) error {
+	if window, active := h.maintenanceService.Active(ctx, session.ConnectionInfo.ConnectionOltIP); active && session.MaintenanceAck != window.ID() {
+		return h.requestMaintenanceAck(ctx, session, window)
+	}
+	return h.executeProvisioning(ctx, session)
 }
 
-// HandleConfirmation processes user confirmation response for provisioning
-func (h *ProvisioningHandler) HandleConfirmation(session *domain.Session, confirm string) error {
-	if confirm != "yes" {
-		if err := h.handleConfirmationDenied(session); err != nil {
-			return err
-		}
+// resendConfirmation shows the data again when the summary went unanswered for too long
+func (h *ProvisioningHandler) resendConfirmation(ctx context.Context, session *domain.Session) error {
+	_ = h.messenger.SendMessage(ctx, session.ChatID, MSG_CONFIRM_EXPIRED)
+	if session.Manual {
+		return h.manualHandler.sendConfirmationRequest(ctx, session)
+	}
+	return h.sendConfirmationRequest(ctx, session)
+}
+
+// handleConfirmationEdit goes back to where the data came from, the wizard for manual
+// provisioning or the protocol entry otherwise
+func (h *ProvisioningHandler) handleConfirmationEdit(ctx context.Context, session *domain.Session) error {
+	if session.Manual {
+		return h.manualHandler.Start(ctx, session)
 	}
 
-	return h.executeProvisioning(session)
+	updateSession(h.sessionService, session, func(s *domain.Session) {
+		s.Protocol = ""
+		s.ConnectionInfo = nil
+		s.ProtocolCheck = domain.ProtocolCheck{}
+		s.OverrideBy = ""
+		s.ReopenedFrom = ""
+		s.ReplacedSerial = ""
+		s.State = domain.StateWaitingProtocol
+	})
+
+	return h.messenger.SendMessage(ctx, session.ChatID, MSG_REQUEST_PROTOCOL)
 }
 
 // handleConfirmationDenied handles when user denies the confirmation
-func (h *ProvisioningHandler) handleConfirmationDenied(session *domain.Session) error {
-	session.State = domain.StateIdle
-	h.sessionService.UpdateSession(session)
+func (h *ProvisioningHandler) handleConfirmationDenied(ctx context.Context, session *domain.Session) error {
+	updateSession(h.sessionService, session, func(s *domain.Session) {
+		s.State = domain.StateIdle
+	})
 
-	return h.messenger.SendMessage(session.ChatID, MSG_CONFIRMATION_DENIED)
+	return h.messenger.SendMessage(ctx, session.ChatID, MSG_CONFIRMATION_DENIED)
 }
 
 // executeProvisioning performs the complete equipment provisioning process
-func (h *ProvisioningHandler) executeProvisioning(session *domain.Session) error {
-	h.messenger.SendTypingIndicator(session.ChatID)
-	_ = h.messenger.SendMessage(session.ChatID, MSG_PROVISIONING_START)
+func (h *ProvisioningHandler) executeProvisioning(ctx context.Context, session *domain.Session) error {
+	release, err := h.waitForSlot(ctx, session)
+	if err != nil {
+		updateSession(h.sessionService, session, func(s *domain.Session) {
+			s.State = domain.StateIdle
+		})
 
-	ctx, cancel := context.WithTimeout(context.Background(), TIMEOUT_PROVISIONING)
+		if errors.Is(err, services.ErrQueueDraining) {
+			h.logger.WithField("protocol", session.Protocol).Warn("Provisionamento recusado, aplicação encerrando")
+			return h.messenger.SendMessage(ctx, session.ChatID, MSG_QUEUE_DRAINING)
+		}
+
+		h.logger.WithError(err).WithField("protocol", session.Protocol).Warn("Tempo de espera na fila de provisionamento esgotado")
+		return h.messenger.SendMessage(ctx, session.ChatID, MSG_QUEUE_TIMEOUT)
+	}
+	defer release()
+
+	// The job ID goes into the CTAG of every TL1 command and into the audit record
+	jobID := ids.New()
+	updateSession(h.sessionService, session, func(s *domain.Session) {
+		s.JobID = jobID
+	})
+
+	// The run is recorded before touching the OLT, a repeated protocol and serial reuses the previous result
+	if key, ok := h.idempotencyKey(ctx, session); ok {
+		previous, err := h.idempotencyService.Begin(ctx, key, jobID, session.UserName)
+		switch {
+		case errors.Is(err, services.ErrProvisioningInFlight):
+			updateSession(h.sessionService, session, func(s *domain.Session) {
+				s.State = domain.StateIdle
+			})
+			return h.messenger.SendMessage(ctx, session.ChatID, MSG_PROVISIONING_IN_FLIGHT)
+		case err != nil:
+			h.logger.WithError(err).WithField("key", key).Warn("Falha ao registrar idempotência do provisionamento")
+		case previous != nil:
+			return h.replayProvisioning(ctx, session, previous)
+		}
+	}
+
+	h.messenger.SendTypingIndicator(ctx, session.ChatID)
+	_ = h.messenger.SendMessage(ctx, session.ChatID, MSG_PROVISIONING_START)
+
+	provisionCtx, cancel := context.WithTimeout(unm.WithOrigin(ctx, unm.Origin{UserID: session.UserID, JobID: jobID}), TIMEOUT_PROVISIONING)
 	defer cancel()
 
-	signalInfo, err := h.provisioningService.ProvisionEquipment(ctx, session.ConnectionInfo)
+	vip := h.isVIP(ctx, session)
+	if vip {
+		h.adminNotifier.Notify(ctx, fmt.Sprintf(MSG_ALERT_VIP_STARTED, session.ConnectionInfo.ContractDescription, session.ConnectionInfo.ClientName, session.UserName))
+	}
+
+	result, err := h.provisioningService.ProvisionEquipment(provisionCtx, session.ConnectionInfo, h.replacedRegistration(ctx, session))
+	h.recordOutcome(ctx, err)
+	h.alertRolledBack(ctx, result)
+
 	if err != nil {
-		return h.handleProvisioningError(session, err)
+		if vip {
+			h.adminNotifier.NotifyEscalated(ctx, fmt.Sprintf(MSG_ALERT_VIP_FAILED, session.ConnectionInfo.ContractDescription, session.ConnectionInfo.ClientName, session.UserName, err))
+		}
+		if key, ok := h.idempotencyKey(ctx, session); ok {
+			h.idempotencyService.Release(ctx, key)
+		}
+		return h.handleProvisioningError(ctx, session, result, err)
+	}
+
+	if vip {
+		h.adminNotifier.Notify(ctx, fmt.Sprintf(MSG_ALERT_VIP_FINISHED, session.ConnectionInfo.ContractDescription, session.ConnectionInfo.ClientName, session.UserName))
+	}
+
+	if session.ErpFetchTime > 0 {
+		erpStep := domain.StepTiming{Name: domain.StepErpFetch, Duration: session.ErpFetchTime}
+		result.Steps = append([]domain.StepTiming{erpStep}, result.Steps...)
+	}
+
+	return h.handleProvisioningSuccess(ctx, session, result)
+}
+
+// idempotencyKey returns the key guarding the run against repeats, manual and simulated runs are not guarded
+func (h *ProvisioningHandler) idempotencyKey(ctx context.Context, session *domain.Session) (string, bool) {
+	if session.Manual || session.Protocol == "" || domain.IsTraining(ctx) {
+		return "", false
+	}
+	return domain.IdempotencyKey(session.Protocol, session.ConnectionInfo.ConnectionEquipmentSerialNumber), true
+}
+
+// replayProvisioning answers a repeated run with the result of the one already completed,
+// without sending the TL1 sequence again
+func (h *ProvisioningHandler) replayProvisioning(ctx context.Context, session *domain.Session, previous *domain.IdempotencyRecord) error {
+	h.logger.WithFields(map[string]any{
+		"protocol":     session.Protocol,
+		"session_id":   session.ID,
+		"job_id":       session.JobID,
+		"previous_job": previous.JobID,
+		"audit_id":     previous.AuditID,
+	}).Info("Provisionamento repetido, reaproveitando o resultado anterior")
+
+	h.saveLastJob(ctx, session)
+	updateSession(h.sessionService, session, func(s *domain.Session) {
+		s.State = domain.StateIdle
+	})
+
+	reference := "-"
+	if previous.AuditID != "" {
+		reference = "/auditoria_" + previous.AuditID
+	}
+
+	message := fmt.Sprintf(MSG_PROVISIONING_REPLAYED, h.formatter.DateTime(*previous.CompletedAt), previous.UserName, reference)
+	if previous.Result != nil {
+		preferences := h.bindingService.Preferences(ctx, session.UserID)
+		message += "\n\n" + h.buildSuccessMessage(preferences, session.ConnectionInfo, previous.Result)
+	}
+
+	return h.messenger.SendMessageWithKeyboard(ctx, session.ChatID, message, h.signalHandler.RecheckKeyboard(ctx))
+}
+
+// waitForSlot queues the job for a provisioning slot, telling the user its position while others run first.
+// Training runs never reach an OLT and skip the queue.
+func (h *ProvisioningHandler) waitForSlot(ctx context.Context, session *domain.Session) (func(), error) {
+	if domain.IsTraining(ctx) {
+		return func() {}, nil
+	}
+
+	ticket := h.queue.Enqueue(services.QueuedJob{
+		UserID:   session.UserID,
+		UserName: session.UserName,
+		Protocol: session.Protocol,
+		Contract: session.ConnectionInfo.ContractDescription,
+		VIP:      session.ConnectionInfo.ContractVIP,
+	})
+
+	if position := ticket.Position(); position > 0 {
+		_ = h.messenger.SendMessage(ctx, session.ChatID, fmt.Sprintf(MSG_QUEUE_WAITING, position))
+	}
+
+	waitCtx, cancel := context.WithTimeout(ctx, TIMEOUT_QUEUE_WAIT)
+	defer cancel()
+
+	if err := ticket.Wait(waitCtx); err != nil {
+		return nil, err
 	}
 
-	return h.handleProvisioningSuccess(session, signalInfo)
+	return ticket.Done, nil
+}
+
+// isVIP reports whether supervisors follow the job, simulated runs are never announced
+func (h *ProvisioningHandler) isVIP(ctx context.Context, session *domain.Session) bool {
+	return session.ConnectionInfo.ContractVIP && !domain.IsTraining(ctx)
 }
 
-// handleProvisioningError handles provisioning failure and resets session
-func (h *ProvisioningHandler) handleProvisioningError(session *domain.Session, err error) error {
-	h.logger.WithError(err).WithField("protocol", session.Protocol).Error("Falha no provisionamento")
+// recordOutcome feeds the success-rate circuit and alerts operations on state changes, only the failures of
+// the UNM count against it
+func (h *ProvisioningHandler) recordOutcome(ctx context.Context, err error) {
+	// Simulated runs say nothing about the health of the OLTs
+	if domain.IsTraining(ctx) {
+		return
+	}
+	if err != nil && !services.IsUnmFailure(err) {
+		return
+	}
 
-	session.State = domain.StateIdle
-	h.sessionService.UpdateSession(session)
+	switch h.circuitService.Record(err == nil) {
+	case services.CircuitTripped:
+		rate := h.circuitService.SuccessRate()
+		h.logger.WithField("success_rate", rate*100).Warn("Provisionamento automático suspenso por baixa taxa de sucesso")
+		h.adminNotifier.NotifyCritical(ctx, fmt.Sprintf(MSG_ALERT_CIRCUIT_OPEN, h.formatter.Percent(rate)))
+	case services.CircuitRecovered:
+		h.logger.Info("Provisionamento automático restabelecido")
+		h.adminNotifier.Notify(ctx, MSG_ALERT_CIRCUIT_CLOSED)
+	}
+}
+
+// alertRolledBack warns operations of the steps the job sent back to the old path for their error rate
+func (h *ProvisioningHandler) alertRolledBack(ctx context.Context, result *domain.ProvisioningResult) {
+	if result == nil {
+		return
+	}
+	for _, step := range result.RolledBack {
+		h.adminNotifier.NotifyCritical(ctx, fmt.Sprintf(MSG_ALERT_ROLLOUT_ROLLED_BACK, step, step))
+	}
+}
+
+// handleProvisioningError handles provisioning failure and resets session, telling whether the previous
+// configuration of the ONU was put back
+func (h *ProvisioningHandler) handleProvisioningError(
+	ctx context.Context,
+	session *domain.Session,
+	result *domain.ProvisioningResult,
+	err error,
+) error {
+	h.logger.WithError(err).WithFields(map[string]any{
+		"protocol":   session.Protocol,
+		"session_id": session.ID,
+		"job_id":     session.JobID,
+	}).Error("Falha no provisionamento")
+
+	record, _ := h.recordAudit(ctx, session, result, err)
+
+	updateSession(h.sessionService, session, func(s *domain.Session) {
+		s.State = domain.StateIdle
+	})
 
 	message := fmt.Sprintf(MSG_PROVISIONING_FAILED, err)
-	return h.messenger.SendMessage(session.ChatID, message)
+	if result != nil && result.Overwrote {
+		if result.Restored {
+			message += MSG_PROVISIONING_PREVIOUS_RESTORED
+		} else {
+			message += MSG_PROVISIONING_PREVIOUS_LOST
+			h.adminNotifier.NotifyCritical(ctx, fmt.Sprintf(MSG_ALERT_RESTORE_FAILED, session.ConnectionInfo.ConnectionEquipmentSerialNumber, auditReference(record)))
+		}
+	}
+
+	budgetExceeded := errors.Is(err, unm.ErrCommandBudgetExceeded)
+	if (budgetExceeded || errors.Is(err, unm.ErrStepTimeout)) && result != nil && !result.Overwrote {
+		if result.Discarded {
+			message += MSG_PROVISIONING_PARTIAL_REMOVED
+		} else {
+			message += MSG_PROVISIONING_PARTIAL_LEFT
+			if !budgetExceeded {
+				connInfo := session.ConnectionInfo
+				h.adminNotifier.NotifyCritical(ctx, fmt.Sprintf(MSG_ALERT_STEP_TIMEOUT, connInfo.ConnectionEquipmentSerialNumber, connInfo.ConnectionOltIP, err, auditReference(record)))
+			}
+		}
+	}
+	if budgetExceeded {
+		h.alertCommandBudget(ctx, session, result, record, err)
+	}
+
+	return h.messenger.SendMessage(ctx, session.ChatID, message)
+}
+
+// alertCommandBudget warns operations that a job was aborted for sending too many TL1 commands or running too long
+func (h *ProvisioningHandler) alertCommandBudget(
+	ctx context.Context,
+	session *domain.Session,
+	result *domain.ProvisioningResult,
+	record *domain.AuditRecord,
+	err error,
+) {
+	connInfo := session.ConnectionInfo
+	template := "-"
+	if result != nil && result.Template != nil {
+		template = result.Template.Name
+	}
+
+	h.adminNotifier.NotifyCritical(ctx, fmt.Sprintf(
+		MSG_ALERT_COMMAND_BUDGET,
+		connInfo.ConnectionEquipmentSerialNumber,
+		connInfo.ConnectionOltIP,
+		cmp.Or(connInfo.ContractPlanName, "-"),
+		template,
+		err,
+		auditReference(record),
+	))
+}
+
+// auditReference names an audit record in alerts, even when it could not be stored
+func auditReference(record *domain.AuditRecord) string {
+	if record == nil {
+		return "-"
+	}
+	return "/auditoria_" + record.ID
 }
 
 // handleProvisioningSuccess handles successful provisioning and builds response
 func (h *ProvisioningHandler) handleProvisioningSuccess(
+	ctx context.Context,
 	session *domain.Session,
-	signalInfo *domain.OnuSignalInfo,
+	result *domain.ProvisioningResult,
 ) error {
-	session.State = domain.StateIdle
-	h.sessionService.UpdateSession(session)
-
-	message := h.buildSuccessMessage(session.ConnectionInfo, signalInfo)
+	preferences := h.bindingService.Preferences(ctx, session.UserID)
+	message := h.buildSuccessMessage(preferences, session.ConnectionInfo, result)
+	if session.ReplacedSerial != "" {
+		message += h.removeReplacedOnu(ctx, session)
+	}
 
 	h.logger.WithFields(map[string]any{
-		"protocol": session.Protocol,
-		"contract": session.ConnectionInfo.ContractDescription,
-		"serial":   session.ConnectionInfo.ConnectionEquipmentSerialNumber,
+		"protocol":   session.Protocol,
+		"session_id": session.ID,
+		"job_id":     session.JobID,
+		"contract":   session.ConnectionInfo.ContractDescription,
+		"serial":     session.ConnectionInfo.ConnectionEquipmentSerialNumber,
+		"steps":      result.StepFields(),
+		"total":      result.Total().String(),
 	}).Info("Provisionamento concluído com sucesso")
 
-	return h.messenger.SendMessage(session.ChatID, message)
+	h.saveLastJob(ctx, session)
+	h.alertCriticalSignal(ctx, session, result)
+	h.alertTechnicianSignal(ctx, session, result, preferences)
+	keyboard := h.signalHandler.RecheckKeyboard(ctx)
+
+	record, err := h.recordAudit(ctx, session, result, nil)
+	if key, ok := h.idempotencyKey(ctx, session); ok {
+		auditID := ""
+		if record != nil {
+			auditID = record.ID
+		}
+		h.idempotencyService.Complete(ctx, key, auditID, result)
+	}
+
+	if err != nil {
+		updateSession(h.sessionService, session, func(s *domain.Session) {
+			s.State = domain.StateIdle
+		})
+		return h.messenger.SendMessageWithKeyboard(ctx, session.ChatID, message, keyboard)
+	}
+
+	if err := h.messenger.SendMessageWithKeyboard(ctx, session.ChatID, message, keyboard); err != nil {
+		return err
+	}
+	h.signalHandler.OfferTroubleshooting(ctx, session.ChatID, record.ID, result.Signal)
+
+	return h.photoHandler.RequestPhotos(ctx, session, record.ID)
 }
 
-// buildSuccessMessage creates the success message with equipment and signal details
-func (h *ProvisioningHandler) buildSuccessMessage(
-	connectionInfo *dto.ConnectionInfo,
-	signalInfo *domain.OnuSignalInfo,
-) string {
+// alertCriticalSignal tells the admins an ONU went live with a reception below the critical threshold
+// of its OLT, usually a dirty connector or a bad splice the technician can still fix on site
+func (h *ProvisioningHandler) alertCriticalSignal(ctx context.Context, session *domain.Session, result *domain.ProvisioningResult) {
+	signal := result.Signal
+	if signal == nil || signal.Level != domain.SignalCritical || signal.Threshold == nil {
+		return
+	}
+
+	connInfo := session.ConnectionInfo
+	h.adminNotifier.Notify(ctx, fmt.Sprintf(
+		MSG_ALERT_SIGNAL_CRITICAL,
+		connInfo.ContractDescription,
+		connInfo.ConnectionEquipmentSerialNumber,
+		connInfo.ConnectionOltIP,
+		h.formatter.Measurement(signal.RxPower, 2, "dBm"),
+		formatDbm(h.formatter, signal.Threshold.Critical),
+		session.UserName,
+	))
+}
+
+// alertTechnicianSignal tells a technician who opted into signal alerts that their install went live with a
+// reception below the thresholds of the OLT, apart from the success message so it is not missed
+func (h *ProvisioningHandler) alertTechnicianSignal(
+	ctx context.Context,
+	session *domain.Session,
+	result *domain.ProvisioningResult,
+	preferences domain.Preferences,
+) {
+	signal := result.Signal
+	if !preferences.SignalAlerts || signal == nil || signal.Threshold == nil {
+		return
+	}
+	if signal.Level != domain.SignalWarning && signal.Level != domain.SignalCritical {
+		return
+	}
+
+	text := textFor(preferences.Language)
 	message := fmt.Sprintf(
-		MSG_PROVISIONING_SUCCESS,
-		connectionInfo.ContractDescription,
-		connectionInfo.ConnectionEquipmentSerialNumber,
+		text.SignalAlert,
+		session.ConnectionInfo.ContractDescription,
+		session.ConnectionInfo.ConnectionEquipmentSerialNumber,
+		h.formatter.Measurement(signal.RxPower, 2, "dBm"),
 	)
+	_ = h.messenger.SendMessage(ctx, session.ChatID, message+signalLevelNote(text, h.formatter, signal))
+}
+
+// saveLastJob keeps the provisioned ONU context for quick signal re-checks
+func (h *ProvisioningHandler) saveLastJob(ctx context.Context, session *domain.Session) {
+	connInfo := session.ConnectionInfo
+	h.lastJobService.Save(ctx, domain.LastJob{
+		UserID:     session.UserID,
+		JobID:      session.JobID,
+		Protocol:   session.Protocol,
+		Contract:   connInfo.ContractDescription,
+		Serial:     connInfo.ConnectionEquipmentSerialNumber,
+		OltIP:      connInfo.ConnectionOltIP,
+		Slot:       connInfo.ConnectionOltSlot,
+		Port:       connInfo.ConnectionOltPort,
+		FinishedAt: h.clock.Now(),
+	})
+}
+
+// recordAudit stores the provisioning outcome in the audit trail, with the configuration the ONU had before
+func (h *ProvisioningHandler) recordAudit(
+	ctx context.Context,
+	session *domain.Session,
+	result *domain.ProvisioningResult,
+	provisioningErr error,
+) (*domain.AuditRecord, error) {
+	record := &domain.AuditRecord{
+		UserID:          session.UserID,
+		ChatID:          session.ChatID,
+		TechnicianTaxID: session.UserTaxID,
+		TechnicianName:  session.UserName,
+		SessionID:       session.ID,
+		JobID:           session.JobID,
+		Protocol:        session.Protocol,
+		Manual:          session.Manual,
+		Success:         provisioningErr == nil,
+		OverrideBy:      session.OverrideBy,
+		ReopenedFrom:    session.ReopenedFrom,
+		ReplacedSerial:  session.ReplacedSerial,
+	}
+
+	if provisioningErr != nil {
+		record.Error = provisioningErr.Error()
+		record.Problems = validation.From(provisioningErr)
+	}
 
-	if signalInfo != nil && h.hasSignalData(signalInfo) {
-		message += fmt.Sprintf(
-			MSG_SIGNAL_INFO,
-			"1.94",
-			"-23.01",
-			"3.28",
-			"56.17",
-		)
+	if result != nil {
+		record.Previous = result.Previous
+		record.WanServices = result.WanServices
+		record.Commands = result.Commands
+		record.Steps = result.Steps
+		record.Reconfigured = result.Reconfigured
+		if result.Signal != nil {
+			record.RxPower = result.Signal.RxPower
+			record.SignalLevel = result.Signal.Level
+		}
 	}
 
-	message += MSG_EQUIPMENT_READY
-	return message
+	if !session.Profile.IsEmpty() {
+		profile := session.Profile
+		record.TechnicianProfile = &profile
+	}
+
+	if connInfo := session.ConnectionInfo; connInfo != nil {
+		record.Contract = connInfo.ContractDescription
+		record.ClientName = connInfo.ClientName
+		record.Serial = connInfo.ConnectionEquipmentSerialNumber
+		record.OltIP = connInfo.ConnectionOltIP
+		record.Slot = connInfo.ConnectionOltSlot
+		record.Port = connInfo.ConnectionOltPort
+	}
+
+	return h.auditService.Record(ctx, record)
 }
 
-// hasSignalData checks if signal information contains valid data
-func (h *ProvisioningHandler) hasSignalData(signalInfo *domain.OnuSignalInfo) bool {
-	return signalInfo.TxPower != "" && signalInfo.RxPower != ""
+// buildSuccessMessage creates the success message from the configured sections and the user preferences
+func (h *ProvisioningHandler) buildSuccessMessage(
+	preferences domain.Preferences,
+	connectionInfo *dto.ConnectionInfo,
+	result *domain.ProvisioningResult,
+) string {
+	return renderSuccessMessage(h.successPolicy, preferences, h.formatter, connectionInfo, result)
 }
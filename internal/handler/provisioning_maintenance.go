@@ -0,0 +1,51 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"provisioning-assistant/internal/domain"
+)
+
+// requestMaintenanceAck warns that the OLT is in a maintenance window, provisioning only goes on
+// once the technician acknowledges it
+func (h *ProvisioningHandler) requestMaintenanceAck(ctx context.Context, session *domain.Session, window *domain.MaintenanceWindow) error {
+	reason := ""
+	if window.Reason != "" {
+		reason = fmt.Sprintf(MSG_MAINTENANCE_REASON, window.Reason)
+	}
+
+	message := fmt.Sprintf(MSG_MAINTENANCE_WARNING, window.Olt, h.formatter.Time(window.End), reason)
+	return h.messenger.SendMessageWithKeyboard(ctx, session.ChatID, message, h.keyboards.Build(KeyboardMaintenance))
+}
+
+// HandleMaintenanceOption provisions despite the maintenance window or gives up on it
+func (h *ProvisioningHandler) HandleMaintenanceOption(ctx context.Context, session *domain.Session, option string) error {
+	if !h.confirmer.IsPending(session) {
+		return nil
+	}
+
+	if h.confirmer.IsExpired(session) {
+		return h.resendConfirmation(ctx, session)
+	}
+
+	if option != "ack" {
+		updateSession(h.sessionService, session, func(s *domain.Session) {
+			s.State = domain.StateIdle
+		})
+		return h.messenger.SendMessage(ctx, session.ChatID, MSG_MAINTENANCE_CANCELLED)
+	}
+
+	if window, active := h.maintenanceService.Active(ctx, session.ConnectionInfo.ConnectionOltIP); active {
+		updateSession(h.sessionService, session, func(s *domain.Session) {
+			s.MaintenanceAck = window.ID()
+		})
+
+		h.logger.WithFields(map[string]any{
+			"protocol": session.Protocol,
+			"olt":      window.Olt,
+			"user":     session.UserName,
+		}).Warn("Provisionamento confirmado durante janela de manutenção")
+	}
+
+	return h.executeProvisioning(ctx, session)
+}
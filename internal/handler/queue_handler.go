@@ -0,0 +1,74 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"provisioning-assistant/internal/clock"
+	"provisioning-assistant/internal/domain"
+	"provisioning-assistant/internal/locale"
+	"provisioning-assistant/internal/services"
+	"strings"
+	"time"
+)
+
+type QueueHandler struct {
+	queue     *services.ProvisioningQueue
+	clock     clock.Clock
+	formatter *locale.Formatter
+	messenger *Messenger
+}
+
+// NewQueueHandler creates a new provisioning queue command handler
+func NewQueueHandler(queue *services.ProvisioningQueue, clock clock.Clock,
+	formatter *locale.Formatter, messenger *Messenger) *QueueHandler {
+	return &QueueHandler{
+		queue:     queue,
+		clock:     clock,
+		formatter: formatter,
+		messenger: messenger,
+	}
+}
+
+// RegisterCommands registers the provisioning queue commands
+func (h *QueueHandler) RegisterCommands(commands *CommandHandler) {
+	commands.Register("/fila", domain.RoleSupervisor, h.handleQueueCommand)
+}
+
+// handleQueueCommand lists the jobs being provisioned and the ones waiting for a slot
+func (h *QueueHandler) handleQueueCommand(ctx context.Context, session *domain.Session, args []string) error {
+	running, waiting := h.queue.Jobs()
+	if len(running) == 0 && len(waiting) == 0 {
+		return h.messenger.SendMessage(ctx, session.ChatID, MSG_QUEUE_EMPTY)
+	}
+
+	now := h.clock.Now()
+
+	var builder strings.Builder
+	builder.WriteString(fmt.Sprintf(MSG_QUEUE_HEADER, len(running), h.queue.Slots(), len(waiting)))
+
+	if len(running) > 0 {
+		builder.WriteString(MSG_QUEUE_RUNNING)
+		for _, job := range running {
+			builder.WriteString(h.formatJob(job, now.Sub(job.StartedAt)))
+		}
+	}
+
+	if len(waiting) > 0 {
+		builder.WriteString(MSG_QUEUE_WAITING_HEADER)
+		for _, job := range waiting {
+			builder.WriteString(h.formatJob(job, now.Sub(job.EnqueuedAt)))
+		}
+	}
+
+	return h.messenger.SendMessage(ctx, session.ChatID, builder.String())
+}
+
+// formatJob renders a queue line, VIP jobs are starred
+func (h *QueueHandler) formatJob(job services.QueuedJob, elapsed time.Duration) string {
+	priority := ""
+	if job.VIP {
+		priority = MSG_QUEUE_VIP
+	}
+
+	return fmt.Sprintf(MSG_QUEUE_ITEM, priority, job.Protocol, job.Contract, job.UserName, h.formatter.Duration(elapsed))
+}
@@ -0,0 +1,191 @@
+package handler
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"provisioning-assistant/internal/clock"
+	"provisioning-assistant/internal/domain"
+	"provisioning-assistant/internal/locale"
+	"provisioning-assistant/internal/services"
+	"provisioning-assistant/internal/validation"
+	"strings"
+)
+
+type ReopenHandler struct {
+	auditService        *services.AuditService
+	sessionService      *services.SessionService
+	lastJobService      *services.LastJobService
+	provisioningHandler *ProvisioningHandler
+	signalHandler       *SignalHandler
+	menuHandler         *MenuHandler
+	attemptGuard        *AttemptGuard
+	clock               clock.Clock
+	formatter           *locale.Formatter
+	keyboards           *KeyboardCatalog
+	messenger           *Messenger
+	logger              domain.Logger
+}
+
+// NewReopenHandler creates a new handler for reopening finished protocols
+func NewReopenHandler(
+	auditService *services.AuditService,
+	sessionService *services.SessionService,
+	lastJobService *services.LastJobService,
+	provisioningHandler *ProvisioningHandler,
+	signalHandler *SignalHandler,
+	menuHandler *MenuHandler,
+	attemptGuard *AttemptGuard,
+	clock clock.Clock,
+	formatter *locale.Formatter,
+	keyboards *KeyboardCatalog,
+	messenger *Messenger,
+	logger domain.Logger,
+) *ReopenHandler {
+	return &ReopenHandler{
+		auditService:        auditService,
+		sessionService:      sessionService,
+		lastJobService:      lastJobService,
+		provisioningHandler: provisioningHandler,
+		signalHandler:       signalHandler,
+		menuHandler:         menuHandler,
+		attemptGuard:        attemptGuard,
+		clock:               clock,
+		formatter:           formatter,
+		keyboards:           keyboards,
+		messenger:           messenger,
+		logger:              logger,
+	}
+}
+
+// RegisterCommands registers the protocol reopening command
+func (h *ReopenHandler) RegisterCommands(commands *CommandHandler) {
+	commands.Register("/reabrir", domain.RoleTechnician, h.handleReopenCommand)
+}
+
+// handleReopenCommand loads the last successful audit record of a protocol and offers the follow-up actions
+func (h *ReopenHandler) handleReopenCommand(ctx context.Context, session *domain.Session, args []string) error {
+	if len(args) != 1 {
+		return h.messenger.SendMessage(ctx, session.ChatID, MSG_REOPEN_USAGE)
+	}
+
+	if session.State != domain.StateIdle && session.State != domain.StateMainMenu {
+		return h.messenger.SendMessage(ctx, session.ChatID, MSG_REOPEN_BUSY)
+	}
+
+	record, err := h.auditService.FindLatestByProtocol(ctx, args[0])
+	if err != nil {
+		return h.messenger.SendMessage(ctx, session.ChatID, fmt.Sprintf(MSG_REOPEN_NOT_FOUND, args[0]))
+	}
+
+	updateSession(h.sessionService, session, func(s *domain.Session) {
+		s.State = domain.StateReopenMenu
+		s.ReopenedFrom = record.ID
+		s.ReplacedSerial = ""
+	})
+
+	h.logger.WithFields(map[string]any{
+		"user_id":  session.UserID,
+		"protocol": record.Protocol,
+		"audit_id": record.ID,
+	}).Info("Reabertura de protocolo iniciada")
+
+	return h.sendSummary(ctx, session, record)
+}
+
+// sendSummary shows what was done on the previous job along with the reopening actions
+func (h *ReopenHandler) sendSummary(ctx context.Context, session *domain.Session, record *domain.AuditRecord) error {
+	message := fmt.Sprintf(
+		MSG_REOPEN_SUMMARY,
+		record.Protocol,
+		record.ID,
+		h.formatter.DateTime(record.CreatedAt),
+		record.TechnicianName,
+		record.Contract,
+		record.ClientName,
+		record.Serial,
+		record.OltIP,
+		record.Slot,
+		record.Port,
+		cmp.Or(record.RxPower, MSG_REOPEN_NO_SIGNAL),
+	)
+
+	return h.messenger.SendMessageWithKeyboard(ctx, session.ChatID, message, h.keyboards.Build(KeyboardReopen))
+}
+
+// HandleReopenOption runs the action picked on the reopening summary
+func (h *ReopenHandler) HandleReopenOption(ctx context.Context, session *domain.Session, option string) error {
+	if session.State != domain.StateReopenMenu {
+		return nil
+	}
+
+	if option == "cancel" {
+		h.reset(session)
+		return h.menuHandler.SendMainMenu(ctx, session)
+	}
+
+	record, err := h.auditService.GetRecord(ctx, session.ReopenedFrom)
+	if err != nil {
+		h.logger.WithError(err).WithField("audit_id", session.ReopenedFrom).Error("Registro de auditoria da reabertura não encontrado")
+		h.reset(session)
+		return h.messenger.SendMessage(ctx, session.ChatID, fmt.Sprintf(MSG_AUDIT_NOT_FOUND, session.ReopenedFrom))
+	}
+
+	switch option {
+	case "signal":
+		// The reopened ONU becomes the last job so the re-check buttons keep pointing at it
+		h.lastJobService.Save(ctx, domain.LastJob{
+			UserID:     session.UserID,
+			JobID:      record.JobID,
+			Protocol:   record.Protocol,
+			Contract:   record.Contract,
+			Serial:     record.Serial,
+			OltIP:      record.OltIP,
+			Slot:       record.Slot,
+			Port:       record.Port,
+			FinishedAt: h.clock.Now(),
+		})
+		h.reset(session)
+		return h.signalHandler.HandleRecheckOption(ctx, session, "signal")
+
+	case "reconfigure":
+		return h.provisioningHandler.ReopenProtocol(ctx, session, record, "")
+
+	case "swap":
+		updateSession(h.sessionService, session, func(s *domain.Session) {
+			s.State = domain.StateWaitingReopenSerial
+		})
+		return h.messenger.SendMessage(ctx, session.ChatID, MSG_REOPEN_REQUEST_SERIAL)
+	}
+
+	return nil
+}
+
+// HandleTextInput takes the serial of the replacement ONU, repeating the summary on the action menu
+func (h *ReopenHandler) HandleTextInput(ctx context.Context, session *domain.Session, msg *domain.MessageEvent) error {
+	record, err := h.auditService.GetRecord(ctx, session.ReopenedFrom)
+	if err != nil {
+		h.reset(session)
+		return h.messenger.SendMessage(ctx, session.ChatID, fmt.Sprintf(MSG_AUDIT_NOT_FOUND, session.ReopenedFrom))
+	}
+
+	if session.State == domain.StateReopenMenu {
+		return h.sendSummary(ctx, session, record)
+	}
+
+	serial := strings.ToUpper(strings.TrimSpace(msg.Message))
+	if validation.Serial(serial) != nil {
+		return h.attemptGuard.Reject(ctx, session, MSG_REOPEN_SERIAL_INVALID)
+	}
+
+	return h.provisioningHandler.ReopenProtocol(ctx, session, record, serial)
+}
+
+// reset leaves the reopening flow back on the main menu
+func (h *ReopenHandler) reset(session *domain.Session) {
+	updateSession(h.sessionService, session, func(s *domain.Session) {
+		s.State = domain.StateMainMenu
+		s.ReopenedFrom = ""
+		s.ReplacedSerial = ""
+	})
+}
@@ -0,0 +1,350 @@
+package handler
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"provisioning-assistant/internal/clock"
+	"provisioning-assistant/internal/domain"
+	"provisioning-assistant/internal/locale"
+	"provisioning-assistant/internal/services"
+	"provisioning-assistant/internal/validation"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	// flowReportTransitions caps the transitions listed by /fluxo
+	flowReportTransitions = 10
+
+	// oltHealthSteps caps the slowest steps listed for each OLT by /saude-olts
+	oltHealthSteps = 3
+)
+
+type ReportHandler struct {
+	auditService   *services.AuditService
+	archiveService *services.ArchiveService
+	flowMetrics    *services.FlowMetrics
+	loadShedder    *services.LoadShedder
+	clock          clock.Clock
+	formatter      *locale.Formatter
+	messenger      *Messenger
+	logger         domain.Logger
+}
+
+// NewReportHandler creates a new activation report command handler
+func NewReportHandler(
+	auditService *services.AuditService,
+	archiveService *services.ArchiveService,
+	flowMetrics *services.FlowMetrics,
+	loadShedder *services.LoadShedder,
+	clock clock.Clock,
+	formatter *locale.Formatter,
+	messenger *Messenger,
+	logger domain.Logger,
+) *ReportHandler {
+	return &ReportHandler{
+		auditService:   auditService,
+		archiveService: archiveService,
+		flowMetrics:    flowMetrics,
+		loadShedder:    loadShedder,
+		clock:          clock,
+		formatter:      formatter,
+		messenger:      messenger,
+		logger:         logger,
+	}
+}
+
+// RegisterCommands registers the activation report commands
+func (h *ReportHandler) RegisterCommands(commands *CommandHandler) {
+	commands.Register("/hoje", domain.RoleSupervisor, h.handleTodayCommand)
+	commands.Register("/auditoria", domain.RoleSupervisor, h.handleAuditCommand)
+	commands.Register("/fluxo", domain.RoleSupervisor, h.handleFlowCommand)
+	commands.Register("/saude-olts", domain.RoleSupervisor, h.handleOltHealthCommand)
+}
+
+// handleOltHealthCommand shows the TL1 latency and failures of each OLT over the last week, the OLTs
+// flagged as slow or error-prone first
+func (h *ReportHandler) handleOltHealthCommand(ctx context.Context, session *domain.Session, args []string) error {
+	if !h.loadShedder.Allow(services.ShedOltHealth) {
+		return h.messenger.SendMessage(ctx, session.ChatID, MSG_LOAD_SHED_DEFERRED)
+	}
+
+	report, err := h.auditService.OltHealth(ctx)
+	if err != nil {
+		h.logger.WithError(err).Error("Falha ao montar relatório de saúde das OLTs")
+		return h.messenger.SendMessage(ctx, session.ChatID, fmt.Sprintf(MSG_OLT_HEALTH_FAILED, err))
+	}
+
+	since := h.formatter.DateTime(report.Since)
+	if len(report.Olts) == 0 {
+		return h.messenger.SendMessage(ctx, session.ChatID, fmt.Sprintf(MSG_OLT_HEALTH_EMPTY, since))
+	}
+
+	var builder strings.Builder
+	builder.WriteString(fmt.Sprintf(MSG_OLT_HEALTH_HEADER, since, h.formatter.Duration(report.Median)))
+
+	for i, olt := range report.Olts {
+		section := h.oltHealthSection(olt)
+		truncated := fmt.Sprintf(MSG_OLT_HEALTH_TRUNCATED, len(report.Olts)-i)
+		if builder.Len()+len(section)+len(truncated) > MAX_MESSAGE_LENGTH {
+			builder.WriteString(truncated)
+			break
+		}
+		builder.WriteString(section)
+	}
+
+	return h.messenger.SendMessage(ctx, session.ChatID, builder.String())
+}
+
+// oltHealthSection renders the summary of an OLT with its slowest steps
+func (h *ReportHandler) oltHealthSection(olt services.OltHealth) string {
+	flags := ""
+	if olt.Slow {
+		flags += MSG_OLT_HEALTH_SLOW
+	}
+	if olt.ErrorProne {
+		flags += MSG_OLT_HEALTH_ERRORS
+	}
+
+	var builder strings.Builder
+	builder.WriteString(fmt.Sprintf(
+		MSG_OLT_HEALTH_OLT,
+		olt.OltIP,
+		flags,
+		olt.Jobs,
+		olt.Failures,
+		h.formatter.Percent(olt.FailureRate()),
+		h.formatter.Duration(olt.Median),
+		h.formatter.Duration(olt.P95),
+		olt.FailedCommands,
+		olt.Commands,
+	))
+	for _, step := range olt.Steps[:min(len(olt.Steps), oltHealthSteps)] {
+		builder.WriteString(fmt.Sprintf(MSG_OLT_HEALTH_STEP, step.Step, h.formatter.Duration(step.Median), h.formatter.Duration(step.P95)))
+	}
+	return builder.String()
+}
+
+// handleFlowCommand shows how long sessions stay in each step and how often input is rejected there,
+// the slowest steps first
+func (h *ReportHandler) handleFlowCommand(ctx context.Context, session *domain.Session, args []string) error {
+	snapshot := h.flowMetrics.Snapshot()
+	since := h.formatter.DateTime(snapshot.Since)
+
+	states := slices.DeleteFunc(snapshot.States, func(metrics domain.StateMetrics) bool {
+		return metrics.State == domain.StateIdle
+	})
+	if len(states) == 0 {
+		return h.messenger.SendMessage(ctx, session.ChatID, fmt.Sprintf(MSG_FLOW_EMPTY, since))
+	}
+
+	slices.SortStableFunc(states, func(a, b domain.StateMetrics) int {
+		return cmp.Compare(b.AverageDwell(), a.AverageDwell())
+	})
+
+	var builder strings.Builder
+	builder.WriteString(fmt.Sprintf(MSG_FLOW_HEADER, since))
+	for _, metrics := range states {
+		builder.WriteString(fmt.Sprintf(
+			MSG_FLOW_STATE,
+			metrics.State,
+			metrics.Entries,
+			h.formatter.Duration(metrics.AverageDwell()),
+			h.formatter.Duration(metrics.DwellMax),
+			h.formatter.Decimal(metrics.InvalidRate(), 2),
+			metrics.Abandoned,
+		))
+	}
+
+	if len(snapshot.Transitions) > 0 {
+		builder.WriteString(MSG_FLOW_TRANSITIONS)
+		for _, transition := range snapshot.Transitions[:min(len(snapshot.Transitions), flowReportTransitions)] {
+			builder.WriteString(fmt.Sprintf(MSG_FLOW_TRANSITION, transition.From, transition.To, transition.Count))
+		}
+	}
+
+	return h.messenger.SendMessage(ctx, session.ChatID, builder.String())
+}
+
+// handleTodayCommand lists the successful activations of the current day grouped by OLT
+func (h *ReportHandler) handleTodayCommand(ctx context.Context, session *domain.Session, args []string) error {
+	now := h.clock.Now().In(h.formatter.Location())
+	midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	today := h.formatter.Date(now)
+
+	records, err := h.auditService.ListSince(ctx, midnight)
+	if err != nil {
+		h.logger.WithError(err).Error("Falha ao listar ativações do dia")
+		return h.messenger.SendMessage(ctx, session.ChatID, fmt.Sprintf(MSG_TODAY_FAILED, err))
+	}
+
+	byOlt := make(map[string][]*domain.AuditRecord)
+	var total int
+	for _, record := range records {
+		if !record.Success {
+			continue
+		}
+		byOlt[record.OltIP] = append(byOlt[record.OltIP], record)
+		total++
+	}
+
+	if total == 0 {
+		return h.messenger.SendMessage(ctx, session.ChatID, fmt.Sprintf(MSG_TODAY_EMPTY, today))
+	}
+
+	olts := make([]string, 0, len(byOlt))
+	for olt := range byOlt {
+		olts = append(olts, olt)
+	}
+	slices.Sort(olts)
+
+	var builder strings.Builder
+	builder.WriteString(fmt.Sprintf(MSG_TODAY_HEADER, today, total))
+
+	flush := func() error {
+		err := h.messenger.SendMessage(ctx, session.ChatID, builder.String())
+		builder.Reset()
+		return err
+	}
+
+	for _, olt := range olts {
+		activations := byOlt[olt]
+
+		name := olt
+		if name == "" {
+			name = MSG_TODAY_NO_OLT
+		}
+
+		heading := fmt.Sprintf(MSG_TODAY_OLT, name, len(activations))
+		size := len(heading)
+		items := make([]string, 0, len(activations))
+		for _, record := range activations {
+			item := fmt.Sprintf(
+				MSG_TODAY_ITEM,
+				h.formatter.Time(record.CreatedAt),
+				record.Serial,
+				record.Contract,
+				record.TechnicianName,
+				record.ID,
+			)
+			items = append(items, item)
+			size += len(item)
+		}
+
+		// Large days are split into several messages at OLT boundaries, an OLT too large for a message of
+		// its own at its items
+		if builder.Len() > 0 && builder.Len()+size > MAX_MESSAGE_LENGTH {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+
+		builder.WriteString(heading)
+		for _, item := range items {
+			if builder.Len()+len(item) > MAX_MESSAGE_LENGTH {
+				if err := flush(); err != nil {
+					return err
+				}
+				builder.WriteString(fmt.Sprintf(MSG_TODAY_OLT_CONTINUED, name))
+			}
+			builder.WriteString(item)
+		}
+	}
+
+	return flush()
+}
+
+// handleAuditCommand shows a single audit record, looking into the archive when it left the hot store
+func (h *ReportHandler) handleAuditCommand(ctx context.Context, session *domain.Session, args []string) error {
+	if len(args) != 1 {
+		return h.messenger.SendMessage(ctx, session.ChatID, MSG_AUDIT_USAGE)
+	}
+
+	record, err := h.auditService.GetRecord(ctx, args[0])
+	if err != nil {
+		record, err = h.archiveService.FindRecord(ctx, args[0])
+	}
+	if err != nil {
+		return h.messenger.SendMessage(ctx, session.ChatID, fmt.Sprintf(MSG_AUDIT_NOT_FOUND, args[0]))
+	}
+
+	result := MSG_AUDIT_SUCCESS
+	switch {
+	case record.Success:
+	case len(record.Problems) > 0:
+		result = fmt.Sprintf(MSG_AUDIT_FAILURE, validation.Problems(record.Problems).Error())
+	default:
+		result = fmt.Sprintf(MSG_AUDIT_FAILURE, record.Error)
+	}
+
+	manual := MSG_AUDIT_NO
+	if record.Manual {
+		manual = MSG_AUDIT_YES
+	}
+
+	job := record.JobID
+	if job == "" {
+		job = MSG_AUDIT_NO_JOB
+	}
+
+	message := fmt.Sprintf(
+		MSG_AUDIT_DETAIL,
+		record.ID,
+		h.formatter.DateTime(record.CreatedAt),
+		record.TechnicianName,
+		technicianContact(record.TechnicianProfile),
+		record.Protocol,
+		record.Contract,
+		record.ClientName,
+		record.Serial,
+		record.OltIP,
+		record.Slot,
+		record.Port,
+		manual,
+		result,
+		job,
+		len(record.Attachments),
+	)
+
+	if previous := record.Previous; previous != nil {
+		message += fmt.Sprintf(MSG_AUDIT_PREVIOUS, previous.Name, previous.Model, len(previous.WanServices))
+	}
+	if record.RestoredAt != nil {
+		message += fmt.Sprintf(MSG_AUDIT_RESTORED, record.RestoredBy, h.formatter.DateTime(*record.RestoredAt))
+	}
+	if record.RemovedAt != nil {
+		message += fmt.Sprintf(MSG_AUDIT_REMOVED, record.RemovedBy, h.formatter.DateTime(*record.RemovedAt))
+	}
+	if troubleshooting := record.Troubleshooting; troubleshooting != nil {
+		outcome := MSG_AUDIT_TROUBLESHOOT_OPEN
+		if troubleshooting.FinishedAt != nil {
+			outcome = troubleshootOutcomes[troubleshooting.Outcome]
+		}
+		message += fmt.Sprintf(MSG_AUDIT_TROUBLESHOOT, outcome)
+	}
+
+	return h.messenger.SendMessage(ctx, session.ChatID, message)
+}
+
+// technicianContact formats the Telegram username and phone kept in an audit record
+func technicianContact(profile *domain.TelegramProfile) string {
+	if profile == nil {
+		return ""
+	}
+
+	var contacts []string
+	if profile.Username != "" {
+		contacts = append(contacts, "@"+profile.Username)
+	}
+	if profile.Phone != "" {
+		contacts = append(contacts, profile.Phone)
+	}
+
+	if len(contacts) == 0 {
+		return ""
+	}
+
+	return fmt.Sprintf(MSG_AUDIT_CONTACT, strings.Join(contacts, ", "))
+}
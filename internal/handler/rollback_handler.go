@@ -0,0 +1,223 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"provisioning-assistant/internal/domain"
+	"provisioning-assistant/internal/locale"
+	"provisioning-assistant/internal/services"
+	"provisioning-assistant/internal/unm"
+	"slices"
+	"strconv"
+)
+
+type RollbackHandler struct {
+	auditService        *services.AuditService
+	erpService          *services.ErpService
+	provisioningService *services.ProvisioningService
+	sessionService      *services.SessionService
+	adminNotifier       *AdminNotifier
+	confirmer           *Confirmer
+	formatter           *locale.Formatter
+	messenger           *Messenger
+	logger              domain.Logger
+}
+
+// NewRollbackHandler creates a new handler for rolling back ONU swaps from their audit records
+func NewRollbackHandler(
+	auditService *services.AuditService,
+	erpService *services.ErpService,
+	provisioningService *services.ProvisioningService,
+	sessionService *services.SessionService,
+	adminNotifier *AdminNotifier,
+	confirmer *Confirmer,
+	formatter *locale.Formatter,
+	messenger *Messenger,
+	logger domain.Logger,
+) *RollbackHandler {
+	return &RollbackHandler{
+		auditService:        auditService,
+		erpService:          erpService,
+		provisioningService: provisioningService,
+		sessionService:      sessionService,
+		adminNotifier:       adminNotifier,
+		confirmer:           confirmer,
+		formatter:           formatter,
+		messenger:           messenger,
+		logger:              logger,
+	}
+}
+
+// RegisterCommands registers the swap rollback command
+func (h *RollbackHandler) RegisterCommands(commands *CommandHandler) {
+	commands.Register("/reverter", domain.RoleAdmin, h.handleRollbackCommand)
+}
+
+// handleRollbackCommand shows the ONU configuration captured before the provisioning of an audit record,
+// asking before putting it back
+func (h *RollbackHandler) handleRollbackCommand(ctx context.Context, session *domain.Session, args []string) error {
+	if len(args) != 1 {
+		return h.messenger.SendMessage(ctx, session.ChatID, MSG_ROLLBACK_USAGE)
+	}
+
+	record, ok := h.restorableRecord(ctx, session, args[0])
+	if !ok {
+		return nil
+	}
+
+	return h.ask(ctx, session, record)
+}
+
+// HandleRunOption puts back the configuration of the confirmed audit record, a button of an older or
+// expired summary only gets the summary shown again
+func (h *RollbackHandler) HandleRunOption(ctx context.Context, session *domain.Session, auditID string) error {
+	if !h.confirmer.Awaits(session, domain.StateConfirmRollback) || session.RollbackAudit != auditID {
+		return nil
+	}
+
+	record, ok := h.restorableRecord(ctx, session, auditID)
+	if !ok {
+		h.finish(session)
+		return nil
+	}
+
+	if h.confirmer.IsExpired(session) {
+		_ = h.messenger.SendMessage(ctx, session.ChatID, MSG_CONFIRM_EXPIRED)
+		return h.ask(ctx, session, record)
+	}
+
+	h.finish(session)
+	return h.restore(ctx, session, record)
+}
+
+// HandleCancelOption dismisses the pending rollback without touching the OLT
+func (h *RollbackHandler) HandleCancelOption(ctx context.Context, session *domain.Session, auditID string) error {
+	if !h.confirmer.Awaits(session, domain.StateConfirmRollback) || session.RollbackAudit != auditID {
+		return nil
+	}
+
+	h.finish(session)
+	return h.messenger.SendMessage(ctx, session.ChatID, MSG_ROLLBACK_ABORTED)
+}
+
+// restorableRecord loads an audit record with a configuration still to put back, telling the admin why not otherwise
+func (h *RollbackHandler) restorableRecord(ctx context.Context, session *domain.Session, auditID string) (*domain.AuditRecord, bool) {
+	record, err := h.auditService.GetRecord(ctx, auditID)
+	if err != nil {
+		_ = h.messenger.SendMessage(ctx, session.ChatID, fmt.Sprintf(MSG_AUDIT_NOT_FOUND, auditID))
+		return nil, false
+	}
+
+	if record.Previous == nil {
+		_ = h.messenger.SendMessage(ctx, session.ChatID, fmt.Sprintf(MSG_ROLLBACK_NO_SNAPSHOT, record.ID))
+		return nil, false
+	}
+
+	if record.RestoredAt != nil {
+		_ = h.messenger.SendMessage(ctx, session.ChatID, fmt.Sprintf(
+			MSG_ROLLBACK_ALREADY,
+			record.ID,
+			record.RestoredBy,
+			h.formatter.DateTime(*record.RestoredAt),
+		))
+		return nil, false
+	}
+
+	return record, true
+}
+
+// ask sends the summary of the configuration a rollback puts back through the confirmer
+func (h *RollbackHandler) ask(ctx context.Context, session *domain.Session, record *domain.AuditRecord) error {
+	updateSession(h.sessionService, session, func(s *domain.Session) {
+		s.RollbackAudit = record.ID
+	})
+
+	snapshot := record.Previous
+	return h.confirmer.Ask(ctx, session, Confirmation{
+		Title: fmt.Sprintf(MSG_ROLLBACK_TITLE, record.ID),
+		Fields: []ConfirmationField{
+			{Label: MSG_ROLLBACK_SERIAL, Value: snapshot.Serial},
+			{Label: MSG_ROLLBACK_NAME, Value: snapshot.Name, Optional: true},
+			{Label: MSG_ROLLBACK_LOCATION, Value: fmt.Sprintf("%s %d/%d", snapshot.OltIP, snapshot.Slot, snapshot.Port)},
+			{Label: MSG_ROLLBACK_WAN, Value: strconv.Itoa(len(snapshot.WanServices))},
+		},
+		Question: MSG_ROLLBACK_QUESTION,
+		State:    domain.StateConfirmRollback,
+		Keyboard: KeyboardRollback,
+		Target:   record.ID,
+	})
+}
+
+// finish leaves the confirmation step
+func (h *RollbackHandler) finish(session *domain.Session) {
+	updateSession(h.sessionService, session, func(s *domain.Session) {
+		s.State = domain.StateIdle
+		s.RollbackAudit = ""
+	})
+}
+
+// restore puts back the ONU configuration captured before the provisioning of an audit record
+func (h *RollbackHandler) restore(ctx context.Context, session *domain.Session, record *domain.AuditRecord) error {
+	h.messenger.SendTypingIndicator(ctx, session.ChatID)
+	_ = h.messenger.SendMessage(ctx, session.ChatID, fmt.Sprintf(MSG_ROLLBACK_RUNNING, record.Previous.Serial))
+
+	snapshot, err := h.withPasswords(ctx, record.Previous)
+	if err != nil {
+		h.logger.WithError(err).WithField("audit_id", record.ID).Error("Falha ao obter senhas PPPoE para reverter troca de ONU")
+		return h.messenger.SendMessage(ctx, session.ChatID, fmt.Sprintf(MSG_ROLLBACK_FAILED, err))
+	}
+
+	restoreCtx, cancel := context.WithTimeout(unm.WithOrigin(ctx, unm.Origin{UserID: session.UserID, JobID: record.JobID}), TIMEOUT_PROVISIONING)
+	defer cancel()
+
+	restoreCtx, commandLog := unm.WithCommandLog(restoreCtx)
+	err = h.provisioningService.RestoreSnapshot(restoreCtx, snapshot)
+	if logErr := h.auditService.AppendCommands(ctx, record.ID, commandLog.Commands()); logErr != nil {
+		h.logger.WithError(logErr).WithField("audit_id", record.ID).Warn("Falha ao registrar comandos da reversão")
+	}
+
+	if err != nil {
+		h.logger.WithError(err).WithField("audit_id", record.ID).Error("Falha ao reverter troca de ONU")
+		return h.messenger.SendMessage(ctx, session.ChatID, fmt.Sprintf(MSG_ROLLBACK_FAILED, err))
+	}
+
+	if _, err := h.auditService.MarkRestored(ctx, record.ID, session.UserName); err != nil {
+		h.logger.WithError(err).WithField("audit_id", record.ID).Error("Falha ao registrar reversão no registro de auditoria")
+	}
+
+	h.logger.WithFields(map[string]any{
+		"audit_id": record.ID,
+		"serial":   snapshot.Serial,
+		"user_id":  session.UserID,
+	}).Info("Troca de ONU revertida")
+
+	h.adminNotifier.Notify(ctx, fmt.Sprintf(MSG_ALERT_ROLLBACK, session.UserName, record.ID, snapshot.Serial, snapshot.OltIP))
+
+	return h.messenger.SendMessage(ctx, session.ChatID, fmt.Sprintf(
+		MSG_ROLLBACK_DONE,
+		snapshot.Serial,
+		snapshot.Name,
+		len(snapshot.WanServices),
+	))
+}
+
+// withPasswords copies a snapshot read from the audit trail, which keeps no password, with the PPPoE
+// passwords the ERP holds for its WAN services
+func (h *RollbackHandler) withPasswords(ctx context.Context, snapshot *domain.OnuSnapshot) (*domain.OnuSnapshot, error) {
+	filled := *snapshot
+	filled.WanServices = slices.Clone(snapshot.WanServices)
+
+	for i, service := range filled.WanServices {
+		if service.PPPoEUser == "" || service.PPPoEPassword != "" {
+			continue
+		}
+
+		password, err := h.erpService.PPPoEPassword(ctx, service.PPPoEUser)
+		if err != nil {
+			return nil, err
+		}
+		filled.WanServices[i].PPPoEPassword = password
+	}
+
+	return &filled, nil
+}
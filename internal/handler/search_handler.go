@@ -0,0 +1,152 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"provisioning-assistant/internal/domain"
+	"provisioning-assistant/internal/domain/dto"
+	"provisioning-assistant/internal/locale"
+	"provisioning-assistant/internal/services"
+	"regexp"
+	"strings"
+)
+
+var pppoeUsernamePattern = regexp.MustCompile(`^[A-Za-z0-9._@\-]{1,64}$`)
+
+type SearchHandler struct {
+	sessionService      *services.SessionService
+	erpService          *services.ErpService
+	provisioningService *services.ProvisioningService
+	attemptGuard        *AttemptGuard
+	formatter           *locale.Formatter
+	keyboards           *KeyboardCatalog
+	messenger           *Messenger
+	logger              domain.Logger
+}
+
+// NewSearchHandler creates a new ONU search handler instance
+func NewSearchHandler(
+	sessionService *services.SessionService,
+	erpService *services.ErpService,
+	provisioningService *services.ProvisioningService,
+	attemptGuard *AttemptGuard,
+	formatter *locale.Formatter,
+	keyboards *KeyboardCatalog,
+	messenger *Messenger,
+	logger domain.Logger,
+) *SearchHandler {
+	return &SearchHandler{
+		sessionService:      sessionService,
+		erpService:          erpService,
+		provisioningService: provisioningService,
+		attemptGuard:        attemptGuard,
+		formatter:           formatter,
+		keyboards:           keyboards,
+		messenger:           messenger,
+		logger:              logger,
+	}
+}
+
+// RegisterCommands registers the ONU search commands
+func (h *SearchHandler) RegisterCommands(commands *CommandHandler) {
+	commands.Register("/pppoe", domain.RoleTechnician, h.handlePPPoECommand)
+}
+
+// Start asks for the PPPoE username to search
+func (h *SearchHandler) Start(ctx context.Context, session *domain.Session) error {
+	updateSession(h.sessionService, session, func(s *domain.Session) {
+		s.State = domain.StateWaitingPPPoESearch
+	})
+	return h.messenger.SendMessage(ctx, session.ChatID, MSG_REQUEST_PPPOE_SEARCH)
+}
+
+// HandlePPPoEInput processes the PPPoE username typed during the search flow
+func (h *SearchHandler) HandlePPPoEInput(ctx context.Context, session *domain.Session, msg *domain.MessageEvent) error {
+	username := strings.TrimSpace(msg.Message)
+	if !pppoeUsernamePattern.MatchString(username) {
+		return h.attemptGuard.Reject(ctx, session, MSG_PPPOE_SEARCH_INVALID)
+	}
+
+	connInfo, err := h.lookup(ctx, session.ChatID, username)
+	if err != nil {
+		return h.attemptGuard.Reject(ctx, session, MSG_PPPOE_SEARCH_NOT_FOUND)
+	}
+
+	updateSession(h.sessionService, session, func(s *domain.Session) {
+		s.State = domain.StateMainMenu
+	})
+
+	return h.sendResult(ctx, session, connInfo)
+}
+
+// handlePPPoECommand runs a search from "/pppoe <usuario>" without changing the conversation state
+func (h *SearchHandler) handlePPPoECommand(ctx context.Context, session *domain.Session, args []string) error {
+	if len(args) != 1 || !pppoeUsernamePattern.MatchString(args[0]) {
+		return h.messenger.SendMessage(ctx, session.ChatID, MSG_PPPOE_SEARCH_USAGE)
+	}
+
+	connInfo, err := h.lookup(ctx, session.ChatID, args[0])
+	if err != nil {
+		return h.messenger.SendMessage(ctx, session.ChatID, MSG_PPPOE_SEARCH_NOT_FOUND)
+	}
+
+	return h.sendResult(ctx, session, connInfo)
+}
+
+// lookup fetches the connection bound to a PPPoE username from the ERP
+func (h *SearchHandler) lookup(ctx context.Context, chatID int64, username string) (*dto.ConnectionInfo, error) {
+	h.messenger.SendTypingIndicator(ctx, chatID)
+	_ = h.messenger.SendMessage(ctx, chatID, erpWaitMessage(h.erpService, MSG_PPPOE_SEARCHING))
+
+	fetchCtx, cancel := context.WithTimeout(ctx, TIMEOUT_ERP_FETCH)
+	defer cancel()
+
+	connInfo, err := h.erpService.GetConnectionInfoByPPPoE(fetchCtx, username)
+	if err != nil {
+		h.logger.WithError(err).WithField("pppoe_user", username).Warn("Usuário PPPoE não localizado")
+		return nil, err
+	}
+
+	return connInfo, nil
+}
+
+// sendResult shows where the ONU is installed together with its current status and signal
+func (h *SearchHandler) sendResult(ctx context.Context, session *domain.Session, connInfo *dto.ConnectionInfo) error {
+	message := fmt.Sprintf(
+		MSG_PPPOE_SEARCH_RESULT,
+		connInfo.ConnectionClientPPPoEUsername,
+		connInfo.ClientName,
+		connInfo.ContractDescription,
+		connInfo.ContractPlanName,
+		connInfo.ConnectionEquipmentSerialNumber,
+		connInfo.ConnectionOltIP,
+		connInfo.ConnectionOltSlot,
+		connInfo.ConnectionOltPort,
+	)
+
+	h.messenger.SendTypingIndicator(ctx, session.ChatID)
+
+	signalCtx, cancel := context.WithTimeout(ctx, TIMEOUT_SIGNAL_CHECK)
+	defer cancel()
+
+	state, err := h.provisioningService.OnuState(signalCtx, &domain.LastJob{
+		UserID:   session.UserID,
+		Contract: connInfo.ContractDescription,
+		Serial:   connInfo.ConnectionEquipmentSerialNumber,
+		OltIP:    connInfo.ConnectionOltIP,
+		Slot:     connInfo.ConnectionOltSlot,
+		Port:     connInfo.ConnectionOltPort,
+	})
+	if err != nil || !state.Online {
+		if err != nil {
+			h.logger.WithError(err).WithField("serial", connInfo.ConnectionEquipmentSerialNumber).Warn("Falha ao consultar sinal da ONU localizada")
+		}
+		message += MSG_PPPOE_SEARCH_OFFLINE
+	} else {
+		message += MSG_PPPOE_SEARCH_ONLINE + formatSignalInfo(h.formatter, state.Signal)
+	}
+
+	keyboard := h.keyboards.Build(KeyboardPPPoESearch)
+
+	return h.messenger.SendMessageWithKeyboard(ctx, session.ChatID, message, keyboard)
+}
@@ -0,0 +1,187 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"provisioning-assistant/internal/domain"
+	"provisioning-assistant/internal/locale"
+	"provisioning-assistant/internal/services"
+	"provisioning-assistant/internal/unm"
+	"strings"
+)
+
+type SignalHandler struct {
+	provisioningService *services.ProvisioningService
+	lastJobService      *services.LastJobService
+	troubleshootHandler *TroubleshootHandler
+	formatter           *locale.Formatter
+	keyboards           *KeyboardCatalog
+	messenger           *Messenger
+	logger              domain.Logger
+}
+
+// NewSignalHandler creates a new signal re-check handler instance
+func NewSignalHandler(
+	provisioningService *services.ProvisioningService,
+	lastJobService *services.LastJobService,
+	troubleshootHandler *TroubleshootHandler,
+	formatter *locale.Formatter,
+	keyboards *KeyboardCatalog,
+	messenger *Messenger,
+	logger domain.Logger,
+) *SignalHandler {
+	return &SignalHandler{
+		provisioningService: provisioningService,
+		lastJobService:      lastJobService,
+		troubleshootHandler: troubleshootHandler,
+		formatter:           formatter,
+		keyboards:           keyboards,
+		messenger:           messenger,
+		logger:              logger,
+	}
+}
+
+// HandleRecheckOption re-runs the signal query or the Wi-Fi scan for the user's last provisioned ONU
+func (h *SignalHandler) HandleRecheckOption(ctx context.Context, session *domain.Session, option string) error {
+	job := h.lastJobService.Get(ctx, session.UserID)
+	if job == nil {
+		return h.messenger.SendMessage(ctx, session.ChatID, MSG_RECHECK_EXPIRED)
+	}
+
+	if option == "wifi" {
+		return h.handleWifiScan(ctx, session, job)
+	}
+
+	h.messenger.SendTypingIndicator(ctx, session.ChatID)
+
+	signalCtx, cancel := context.WithTimeout(ctx, TIMEOUT_SIGNAL_CHECK)
+	defer cancel()
+
+	signalInfo, err := h.provisioningService.CheckSignal(signalCtx, job)
+	if err != nil {
+		h.logger.WithError(err).WithField("serial", job.Serial).Error("Falha ao verificar sinal da ONU")
+		if err := h.messenger.SendMessageWithKeyboard(ctx, session.ChatID, MSG_RECHECK_FAILED, h.RecheckKeyboard(ctx)); err != nil {
+			return err
+		}
+		if signalCtx.Err() == nil {
+			h.troubleshootHandler.OfferForSerial(ctx, session.ChatID, job.Serial, nil)
+		}
+		return nil
+	}
+
+	message := fmt.Sprintf(MSG_RECHECK_HEADER, job.Serial, job.Contract) + formatSignalInfo(h.formatter, signalInfo)
+	if err := h.messenger.SendMessageWithKeyboard(ctx, session.ChatID, message, h.RecheckKeyboard(ctx)); err != nil {
+		return err
+	}
+	if NeedsTroubleshooting(signalInfo) {
+		h.troubleshootHandler.OfferForSerial(ctx, session.ChatID, job.Serial, signalInfo)
+	}
+	return nil
+}
+
+// OfferTroubleshooting proposes the guided diagnosis of a just provisioned ONU without signal
+func (h *SignalHandler) OfferTroubleshooting(ctx context.Context, chatID int64, auditID string, signal *domain.OnuSignalInfo) {
+	if NeedsTroubleshooting(signal) {
+		h.troubleshootHandler.Offer(ctx, chatID, auditID, signal)
+	}
+}
+
+// handleWifiScan shows the Wi-Fi networks around the ONU and the suggested channel of each band
+func (h *SignalHandler) handleWifiScan(ctx context.Context, session *domain.Session, job *domain.LastJob) error {
+	h.messenger.SendTypingIndicator(ctx, session.ChatID)
+
+	scanCtx, cancel := context.WithTimeout(ctx, TIMEOUT_SIGNAL_CHECK)
+	defer cancel()
+
+	report, err := h.provisioningService.ScanWifi(scanCtx, job)
+	if errors.Is(err, unm.ErrWifiScanUnsupported) {
+		return h.messenger.SendMessage(ctx, session.ChatID, MSG_WIFI_SCAN_UNSUPPORTED)
+	}
+	if errors.Is(err, services.ErrLoadShed) {
+		return h.messenger.SendMessageWithKeyboard(ctx, session.ChatID, MSG_LOAD_SHED_DEFERRED, h.RecheckKeyboard(ctx))
+	}
+	if err != nil {
+		h.logger.WithError(err).WithField("serial", job.Serial).Error("Falha ao consultar redes Wi-Fi vizinhas")
+		return h.messenger.SendMessageWithKeyboard(ctx, session.ChatID, MSG_WIFI_SCAN_FAILED, h.RecheckKeyboard(ctx))
+	}
+
+	return h.messenger.SendMessageWithKeyboard(ctx, session.ChatID, formatWifiScan(job, report), h.RecheckKeyboard(ctx))
+}
+
+// HasRecheck reports whether the user has a recent job available for re-check
+func (h *SignalHandler) HasRecheck(ctx context.Context, session *domain.Session) bool {
+	return h.lastJobService.Get(ctx, session.UserID) != nil
+}
+
+// RecheckKeyboard builds the re-check keyboard, with the Wi-Fi scan when the UNM supports it
+func (h *SignalHandler) RecheckKeyboard(ctx context.Context) *domain.Keyboard {
+	shown := []string{ButtonRecheckSignal}
+	if h.provisioningService.SupportsWifiScan(ctx) {
+		shown = append(shown, ButtonWifiScan)
+	}
+
+	return h.keyboards.Build(KeyboardRecheck, WithButtons(shown...))
+}
+
+// formatSignalInfo renders the optical readings of an ONU, warning when the reception is below the
+// thresholds of its OLT
+func formatSignalInfo(formatter *locale.Formatter, signalInfo *domain.OnuSignalInfo) string {
+	return formatSignalText(successTexts[locale.LanguagePortuguese], formatter, signalInfo)
+}
+
+// formatSignalText renders the signal readings in the wording of a language
+func formatSignalText(text successText, formatter *locale.Formatter, signalInfo *domain.OnuSignalInfo) string {
+	message := fmt.Sprintf(
+		text.SignalInfo,
+		formatter.Measurement(signalInfo.RxPower, 2, "dBm"),
+		formatter.Measurement(signalInfo.TxPower, 2, "dBm"),
+		formatter.Measurement(signalInfo.Voltage, 2, "V"),
+		formatter.Measurement(signalInfo.Temperature, 1, "ºC"),
+	)
+
+	return message + signalLevelNote(text, formatter, signalInfo)
+}
+
+// signalLevelNote warns about a reception below the thresholds of the OLT, empty when it is fine
+func signalLevelNote(text successText, formatter *locale.Formatter, signalInfo *domain.OnuSignalInfo) string {
+	threshold := signalInfo.Threshold
+	if threshold == nil {
+		return ""
+	}
+
+	switch signalInfo.Level {
+	case domain.SignalWarning:
+		return fmt.Sprintf(text.SignalWarning, formatDbm(formatter, threshold.Warn))
+	case domain.SignalCritical:
+		return fmt.Sprintf(text.SignalCritical, formatDbm(formatter, threshold.Critical))
+	default:
+		return ""
+	}
+}
+
+// formatDbm renders a power level in dBm
+func formatDbm(formatter *locale.Formatter, value float64) string {
+	return formatter.Decimal(value, 2) + " dBm"
+}
+
+// formatWifiScan renders the channel occupancy of each band and the suggested channels
+func formatWifiScan(job *domain.LastJob, report *domain.WifiScanReport) string {
+	var builder strings.Builder
+	builder.WriteString(fmt.Sprintf(MSG_WIFI_SCAN_HEADER, job.Serial, report.Networks))
+
+	for _, band := range report.Bands {
+		builder.WriteString(fmt.Sprintf(MSG_WIFI_SCAN_BAND, band.Band))
+
+		if len(band.Channels) == 0 {
+			builder.WriteString(MSG_WIFI_SCAN_BAND_EMPTY)
+		}
+		for _, channel := range band.Channels {
+			builder.WriteString(fmt.Sprintf(MSG_WIFI_SCAN_CHANNEL, channel.Channel, channel.Networks, channel.Strongest))
+		}
+
+		builder.WriteString(fmt.Sprintf(MSG_WIFI_SCAN_RECOMMENDED, band.Recommended))
+	}
+
+	return strings.TrimRight(builder.String(), "\n")
+}
@@ -0,0 +1,181 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"provisioning-assistant/internal/domain"
+	"provisioning-assistant/internal/locale"
+	"provisioning-assistant/internal/services"
+	"strconv"
+	"strings"
+)
+
+const DefaultSpeedTestMinPercent = 80
+
+// SpeedTestPolicy turns on the speed test survey after the installation photos, an install measuring
+// below MinPercent of the contracted speed is flagged
+type SpeedTestPolicy struct {
+	Enabled    bool
+	MinPercent int
+}
+
+type SpeedTestHandler struct {
+	policy          SpeedTestPolicy
+	auditService    *services.AuditService
+	sessionService  *services.SessionService
+	templateService *services.PlanTemplateService
+	adminNotifier   *AdminNotifier
+	formatter       *locale.Formatter
+	keyboards       *KeyboardCatalog
+	messenger       *Messenger
+	logger          domain.Logger
+}
+
+// NewSpeedTestHandler creates a new post-installation speed test survey handler
+func NewSpeedTestHandler(
+	policy SpeedTestPolicy,
+	auditService *services.AuditService,
+	sessionService *services.SessionService,
+	templateService *services.PlanTemplateService,
+	adminNotifier *AdminNotifier,
+	formatter *locale.Formatter,
+	keyboards *KeyboardCatalog,
+	messenger *Messenger,
+	logger domain.Logger,
+) *SpeedTestHandler {
+	if policy.MinPercent <= 0 {
+		policy.MinPercent = DefaultSpeedTestMinPercent
+	}
+
+	return &SpeedTestHandler{
+		policy:          policy,
+		auditService:    auditService,
+		sessionService:  sessionService,
+		templateService: templateService,
+		adminNotifier:   adminNotifier,
+		formatter:       formatter,
+		keyboards:       keyboards,
+		messenger:       messenger,
+		logger:          logger,
+	}
+}
+
+// IsEnabled reports whether the technicians are asked for a speed test
+func (h *SpeedTestHandler) IsEnabled() bool {
+	return h.policy.Enabled
+}
+
+// Request moves the session to the speed test step of its audit record
+func (h *SpeedTestHandler) Request(ctx context.Context, session *domain.Session, photos int) error {
+	updateSession(h.sessionService, session, func(s *domain.Session) {
+		s.State = domain.StateWaitingSpeedTest
+	})
+
+	contracted := ""
+	if download, upload := h.contractedSpeed(session); download > 0 {
+		contracted = fmt.Sprintf(MSG_SPEED_TEST_CONTRACTED, h.speedLabel(download, upload))
+	}
+
+	message := fmt.Sprintf(MSG_REQUEST_SPEED_TEST, photos, contracted)
+	return h.messenger.SendMessageWithKeyboard(ctx, session.ChatID, message, h.keyboards.Build(KeyboardSpeedTest))
+}
+
+// HandleInput records the reported download and upload and compares them to the contracted plan
+func (h *SpeedTestHandler) HandleInput(ctx context.Context, session *domain.Session, msg *domain.MessageEvent) error {
+	download, upload, ok := parseSpeedTest(msg.Message)
+	if !ok {
+		return h.messenger.SendMessageWithKeyboard(ctx, msg.ChatID, MSG_SPEED_TEST_INVALID, h.keyboards.Build(KeyboardSpeedTest))
+	}
+
+	test := domain.SpeedTest{DownloadMbps: download, UploadMbps: upload}
+	test.ExpectedDownloadMbps, test.ExpectedUploadMbps = h.contractedSpeed(session)
+	test.Underperforming = h.isBelow(test.DownloadMbps, test.ExpectedDownloadMbps) || h.isBelow(test.UploadMbps, test.ExpectedUploadMbps)
+
+	record, err := h.auditService.RecordSpeedTest(ctx, session.AuditID, test)
+	if err != nil {
+		h.logger.WithError(err).WithField("audit_id", session.AuditID).Error("Falha ao registrar teste de velocidade")
+		return h.finish(ctx, session)
+	}
+
+	measured := fmt.Sprintf(MSG_SPEED_TEST_MEASURED, h.formatter.Decimal(download, 0), h.formatter.Decimal(upload, 0))
+	if !test.Underperforming {
+		if err := h.messenger.SendMessage(ctx, session.ChatID, fmt.Sprintf(MSG_SPEED_TEST_RECORDED, measured)); err != nil {
+			return err
+		}
+		return h.finish(ctx, session)
+	}
+
+	contracted := h.speedLabel(test.ExpectedDownloadMbps, test.ExpectedUploadMbps)
+	h.adminNotifier.Notify(ctx, fmt.Sprintf(MSG_ALERT_SPEED_TEST_LOW, record.TechnicianName, record.ClientName, record.Protocol, measured, contracted, record.ID))
+
+	if err := h.messenger.SendMessage(ctx, session.ChatID, fmt.Sprintf(MSG_SPEED_TEST_LOW, measured, contracted, h.policy.MinPercent)); err != nil {
+		return err
+	}
+	return h.finish(ctx, session)
+}
+
+// HandleOption processes the speed test step keyboard selection
+func (h *SpeedTestHandler) HandleOption(ctx context.Context, session *domain.Session, option string) error {
+	if session.State != domain.StateWaitingSpeedTest || option != "skip" {
+		return nil
+	}
+
+	return h.finish(ctx, session)
+}
+
+// finish closes the survey and resets the session
+func (h *SpeedTestHandler) finish(ctx context.Context, session *domain.Session) error {
+	updateSession(h.sessionService, session, func(s *domain.Session) {
+		s.State = domain.StateIdle
+		s.AuditID = ""
+	})
+
+	return h.messenger.SendMessage(ctx, session.ChatID, MSG_SPEED_TEST_FINISHED)
+}
+
+// contractedSpeed returns the speeds sold with the plan of the provisioned contract
+func (h *SpeedTestHandler) contractedSpeed(session *domain.Session) (int, int) {
+	if session.ConnectionInfo == nil {
+		return 0, 0
+	}
+	return h.templateService.ContractedSpeed(session.ConnectionInfo.ContractPlanID, session.ConnectionInfo.ContractPlanName)
+}
+
+// isBelow reports whether a measure falls under the minimum share of a known contracted speed
+func (h *SpeedTestHandler) isBelow(measured float64, expected int) bool {
+	return expected > 0 && measured*100 < float64(expected*h.policy.MinPercent)
+}
+
+// speedLabel renders a contracted speed, the upload is left out when unknown
+func (h *SpeedTestHandler) speedLabel(download, upload int) string {
+	if upload == 0 {
+		return fmt.Sprintf(MSG_SPEED_TEST_DOWNLOAD, download)
+	}
+	return fmt.Sprintf(MSG_SPEED_TEST_DOWNLOAD_UPLOAD, download, upload)
+}
+
+// parseSpeedTest reads "480 230", "480/230" or "480,5 230,1" as download and upload in Mbps
+func parseSpeedTest(text string) (download, upload float64, ok bool) {
+	fields := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return r == ' ' || r == '/' || r == ';' || r == '\n' || r == '\t'
+	})
+
+	values := make([]float64, 0, 2)
+	for _, field := range fields {
+		field = strings.TrimSuffix(strings.TrimSuffix(field, "mbps"), "mb")
+		if field == "" {
+			continue
+		}
+
+		value, err := strconv.ParseFloat(strings.ReplaceAll(field, ",", "."), 64)
+		if err != nil || value < 0 {
+			return 0, 0, false
+		}
+		values = append(values, value)
+	}
+
+	if len(values) != 2 {
+		return 0, 0, false
+	}
+	return values[0], values[1], true
+}
@@ -0,0 +1,257 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"provisioning-assistant/internal/buildinfo"
+	"provisioning-assistant/internal/domain"
+	"provisioning-assistant/internal/locale"
+	"provisioning-assistant/internal/scheduler"
+	"provisioning-assistant/internal/services"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type StatusHandler struct {
+	provisioningService *services.ProvisioningService
+	erpService          *services.ErpService
+	circuitService      *services.ProvisioningCircuitService
+	alertService        *services.AlertService
+	safeMode            *services.SafeModeService
+	loadShedder         *services.LoadShedder
+	probeService        *services.UnmProbeService
+	scheduler           *scheduler.Scheduler
+	formatter           *locale.Formatter
+	messenger           *Messenger
+}
+
+// NewStatusHandler creates a new operational status command handler
+func NewStatusHandler(
+	provisioningService *services.ProvisioningService,
+	erpService *services.ErpService,
+	circuitService *services.ProvisioningCircuitService,
+	alertService *services.AlertService,
+	safeMode *services.SafeModeService,
+	loadShedder *services.LoadShedder,
+	probeService *services.UnmProbeService,
+	scheduler *scheduler.Scheduler,
+	formatter *locale.Formatter,
+	messenger *Messenger,
+) *StatusHandler {
+	return &StatusHandler{
+		provisioningService: provisioningService,
+		erpService:          erpService,
+		circuitService:      circuitService,
+		alertService:        alertService,
+		safeMode:            safeMode,
+		loadShedder:         loadShedder,
+		probeService:        probeService,
+		scheduler:           scheduler,
+		formatter:           formatter,
+		messenger:           messenger,
+	}
+}
+
+// RegisterCommands registers the status commands
+func (h *StatusHandler) RegisterCommands(commands *CommandHandler) {
+	commands.Register("/status", domain.RoleSupervisor, h.handleStatusCommand)
+	commands.Register("/alerts", domain.RoleAdmin, h.handleAlertsCommand)
+	commands.Register("/version", domain.RoleAdmin, h.handleVersionCommand)
+}
+
+// handleStatusCommand sends the ERP health, the subsystems left off by the safe mode and the scheduled jobs state
+func (h *StatusHandler) handleStatusCommand(ctx context.Context, session *domain.Session, args []string) error {
+	var builder strings.Builder
+	builder.WriteString(MSG_STATUS_HEADER)
+
+	if h.safeMode.IsActive() {
+		builder.WriteString(MSG_STATUS_SAFE_MODE)
+		for _, degradation := range h.safeMode.Degradations() {
+			builder.WriteString(fmt.Sprintf(MSG_STATUS_SAFE_MODE_ITEM, degradation.Feature, degradation.Setting, degradation.Reason))
+		}
+		builder.WriteString("\n")
+	}
+
+	switch {
+	case h.erpService.IsDegraded():
+		builder.WriteString(MSG_STATUS_ERP_DEGRADED)
+	case h.erpService.IsSlow():
+		builder.WriteString(MSG_STATUS_ERP_SLOW)
+	default:
+		builder.WriteString(MSG_STATUS_ERP_OK)
+	}
+
+	p50, p95 := h.erpService.Latency()
+	builder.WriteString(fmt.Sprintf(
+		MSG_STATUS_ERP_LATENCY,
+		h.formatter.Duration(p50),
+		h.formatter.Duration(p95),
+		h.formatter.Duration(h.erpService.AttemptTimeout()),
+	))
+
+	rate := h.formatter.Percent(h.circuitService.SuccessRate())
+	if h.circuitService.IsTripped() {
+		builder.WriteString(fmt.Sprintf(MSG_STATUS_CIRCUIT_OPEN, rate))
+	} else {
+		builder.WriteString(fmt.Sprintf(MSG_STATUS_CIRCUIT_OK, rate))
+	}
+
+	if trips := h.provisioningService.WatchdogTrips(); trips > 0 {
+		builder.WriteString(fmt.Sprintf(MSG_STATUS_TL1_WATCHDOG, trips))
+	}
+
+	h.writeLoadShed(&builder)
+	h.writeProbes(&builder)
+
+	builder.WriteString(MSG_STATUS_JOBS_HEADER)
+
+	jobs := h.scheduler.Status()
+	if len(jobs) == 0 {
+		builder.WriteString(MSG_STATUS_JOBS_EMPTY)
+	}
+
+	for _, job := range jobs {
+		state := MSG_STATUS_JOB_DISABLED
+		if job.Enabled {
+			state = MSG_STATUS_JOB_ENABLED
+		}
+
+		lastRun := h.formatTime(job.LastRun)
+		if job.Running {
+			lastRun = MSG_STATUS_JOB_RUNNING
+		}
+
+		nextRun := h.formatTime(job.NextRun)
+		if job.Enabled && job.NextRun.IsZero() {
+			switch {
+			case job.Exclusive:
+				nextRun = MSG_STATUS_JOB_OTHER
+			case !job.Local:
+				nextRun = MSG_STATUS_JOB_WORKER
+			}
+		}
+
+		builder.WriteString(fmt.Sprintf(MSG_STATUS_JOB_ITEM, job.Name, job.Cron, state, lastRun, nextRun))
+		if job.LastError != "" {
+			builder.WriteString(fmt.Sprintf(MSG_STATUS_JOB_ERROR, job.LastError))
+		}
+	}
+
+	return h.messenger.SendMessage(ctx, session.ChatID, builder.String())
+}
+
+// writeLoadShed adds the saturation of the UNM with the operations deferred because of it
+func (h *StatusHandler) writeLoadShed(builder *strings.Builder) {
+	status := h.loadShedder.Status()
+
+	switch {
+	case !status.Enabled:
+		builder.WriteString(MSG_STATUS_LOAD_DISABLED)
+		return
+	case status.Shedding:
+		builder.WriteString(fmt.Sprintf(MSG_STATUS_LOAD_SHEDDING, h.formatter.DateTime(status.Since), status.Reason))
+	default:
+		builder.WriteString(fmt.Sprintf(MSG_STATUS_LOAD_OK, h.formatter.Duration(status.P95), status.Waiting))
+	}
+
+	if len(status.Deferred) == 0 {
+		return
+	}
+
+	var deferred []string
+	for _, operation := range slices.Sorted(maps.Keys(status.Deferred)) {
+		deferred = append(deferred, fmt.Sprintf("%s (%d)", operation, status.Deferred[operation]))
+	}
+	builder.WriteString(fmt.Sprintf(MSG_STATUS_LOAD_DEFERRED, strings.Join(deferred, ", ")))
+
+	for _, decision := range slices.Backward(status.Decisions) {
+		builder.WriteString(fmt.Sprintf(MSG_STATUS_LOAD_DECISION, h.formatter.DateTime(decision.At), decision.Operation, decision.Reason))
+	}
+}
+
+// writeProbes adds how each UNM endpoint answered the latest synthetic probes
+func (h *StatusHandler) writeProbes(builder *strings.Builder) {
+	if !h.probeService.IsEnabled() {
+		return
+	}
+
+	for _, status := range h.probeService.Status() {
+		if status.Probes == 0 {
+			continue
+		}
+
+		availability := h.formatter.Percent(status.Availability)
+		if status.Up {
+			builder.WriteString(fmt.Sprintf(MSG_STATUS_PROBE_UP, status.Endpoint, availability, status.Probes, h.formatter.Duration(status.P95)))
+		} else {
+			builder.WriteString(fmt.Sprintf(MSG_STATUS_PROBE_DOWN, status.Endpoint, h.formatter.DateTime(status.Since), availability, status.Probes, status.LastError))
+		}
+	}
+}
+
+// handleAlertsCommand sends which alert rules would fire with the current readings of this process
+func (h *StatusHandler) handleAlertsCommand(ctx context.Context, session *domain.Session, args []string) error {
+	var builder strings.Builder
+	builder.WriteString(MSG_ALERTS_HEADER)
+
+	firing := 0
+	for _, status := range h.alertService.Evaluate() {
+		icon := MSG_ALERTS_OK
+		if status.Firing {
+			icon = MSG_ALERTS_FIRING
+			firing++
+		}
+
+		builder.WriteString(fmt.Sprintf(
+			MSG_ALERTS_ITEM,
+			icon,
+			status.Rule.Name,
+			status.Rule.Summary,
+			status.Rule.Expr(),
+			strconv.FormatFloat(status.Value, 'g', 4, 64),
+		))
+	}
+
+	if firing == 0 {
+		builder.WriteString(MSG_ALERTS_NONE_FIRING)
+	} else {
+		builder.WriteString(fmt.Sprintf(MSG_ALERTS_FIRING_COUNT, firing))
+	}
+
+	return h.messenger.SendMessage(ctx, session.ChatID, builder.String())
+}
+
+// handleVersionCommand sends the build and configuration handling provisioning in this replica
+func (h *StatusHandler) handleVersionCommand(ctx context.Context, session *domain.Session, args []string) error {
+	info := buildinfo.Read()
+
+	message := fmt.Sprintf(MSG_VERSION,
+		info.Version,
+		valueOrUnset(info.ShortCommit()),
+		valueOrUnset(info.ConfigDigest),
+	)
+	if info.Modified {
+		message += MSG_VERSION_MODIFIED
+	}
+
+	return h.messenger.SendMessage(ctx, session.ChatID, message)
+}
+
+// valueOrUnset shows a placeholder for build metadata the build did not stamp
+func valueOrUnset(value string) string {
+	if value == "" {
+		return MSG_VERSION_UNSET
+	}
+	return value
+}
+
+// formatTime formats a job timestamp for display
+func (h *StatusHandler) formatTime(t time.Time) string {
+	if t.IsZero() {
+		return MSG_STATUS_JOB_NEVER
+	}
+	return h.formatter.DateTime(t)
+}
@@ -0,0 +1,79 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"provisioning-assistant/internal/domain"
+	"provisioning-assistant/internal/locale"
+	"provisioning-assistant/internal/services"
+	"strings"
+	"time"
+)
+
+type StorageHandler struct {
+	artifactService *services.ArtifactService
+	formatter       *locale.Formatter
+	messenger       *Messenger
+	logger          domain.Logger
+}
+
+// NewStorageHandler creates a new handler reporting what the artifact store holds
+func NewStorageHandler(artifactService *services.ArtifactService, formatter *locale.Formatter, messenger *Messenger, logger domain.Logger) *StorageHandler {
+	return &StorageHandler{
+		artifactService: artifactService,
+		formatter:       formatter,
+		messenger:       messenger,
+		logger:          logger,
+	}
+}
+
+// RegisterCommands registers the artifact storage command
+func (h *StorageHandler) RegisterCommands(commands *CommandHandler) {
+	commands.Register("/armazenamento", domain.RoleAdmin, h.handleStorageCommand)
+}
+
+// handleStorageCommand sends the files and space taken by each artifact category against its quota and retention
+func (h *StorageHandler) handleStorageCommand(ctx context.Context, session *domain.Session, args []string) error {
+	usage, err := h.artifactService.Usage(ctx)
+	if err != nil {
+		h.logger.WithError(err).Error("Falha ao ler o uso do armazenamento de artefatos")
+		return h.messenger.SendMessage(ctx, session.ChatID, fmt.Sprintf(MSG_STORAGE_FAILED, err))
+	}
+
+	var builder strings.Builder
+	builder.WriteString(MSG_STORAGE_HEADER)
+
+	var files int
+	var size int64
+	for _, category := range usage {
+		files += category.Files
+		size += category.Size
+
+		taken := h.formatter.Size(category.Size)
+		if category.Quota > 0 {
+			ratio := float64(category.Size) / float64(category.Quota)
+			taken = fmt.Sprintf(MSG_STORAGE_QUOTA, taken, h.formatter.Size(category.Quota), h.formatter.Percent(ratio))
+		}
+
+		builder.WriteString(fmt.Sprintf(MSG_STORAGE_ITEM, category.Category, category.Files, taken, h.formatRetention(category.Retention)))
+		if !category.Oldest.IsZero() {
+			builder.WriteString(fmt.Sprintf(MSG_STORAGE_OLDEST, h.formatter.DateTime(category.Oldest)))
+		}
+	}
+
+	if files == 0 {
+		builder.WriteString(MSG_STORAGE_EMPTY)
+	} else {
+		builder.WriteString(fmt.Sprintf(MSG_STORAGE_TOTAL, files, h.formatter.Size(size)))
+	}
+
+	return h.messenger.SendMessage(ctx, session.ChatID, builder.String())
+}
+
+// formatRetention writes a retention in days, files kept for good having none
+func (h *StorageHandler) formatRetention(retention time.Duration) string {
+	if retention <= 0 {
+		return MSG_STORAGE_FOREVER
+	}
+	return fmt.Sprintf(MSG_STORAGE_DAYS, int(retention/(24*time.Hour)))
+}
@@ -0,0 +1,281 @@
+package handler
+
+import (
+	"fmt"
+	"provisioning-assistant/internal/domain"
+	"provisioning-assistant/internal/domain/dto"
+	"provisioning-assistant/internal/locale"
+	"slices"
+	"strings"
+)
+
+// Sections of the closing message of a successful provisioning
+const (
+	SectionSummary     = "summary"
+	SectionClient      = "client"
+	SectionSignal      = "signal"
+	SectionCredentials = "credentials"
+	SectionElapsed     = "elapsed"
+	SectionNextSteps   = "next_steps"
+	SectionWanServices = "wan_services"
+)
+
+// DefaultSuccessSections keeps the historical layout of the success message, the WAN services
+// section only shows up for contracts with more than one
+var DefaultSuccessSections = []string{SectionSummary, SectionWanServices, SectionSignal, SectionElapsed, SectionNextSteps}
+
+// SuccessMessagePolicy selects and orders the sections of the success message.
+// Plan templates listing their own sections take precedence over the global order.
+type SuccessMessagePolicy struct {
+	Sections  []string
+	NextSteps string
+}
+
+// successText is the wording of the success message in one language
+type successText struct {
+	Summary        string
+	Reconfigured   string
+	SignalInfo     string
+	SignalWarning  string
+	SignalCritical string
+	Elapsed        string
+	Client         string
+	Splitter       string
+	Credentials    string
+	EquipmentReady string
+	WanHeader      string
+	WanOK          string
+	WanFailed      string
+	WanPrimary     string
+	WanMulticast   string
+	WanUnnamed     string
+	WanReview      string
+	Compact        string
+	CompactSignal  string
+	SignalAlert    string
+}
+
+var successTexts = map[string]successText{
+	locale.LanguagePortuguese: {
+		Summary:        MSG_PROVISIONING_SUCCESS,
+		Reconfigured:   MSG_PROVISIONING_RECONFIGURED,
+		SignalInfo:     MSG_SIGNAL_INFO,
+		SignalWarning:  MSG_SIGNAL_WARNING,
+		SignalCritical: MSG_SIGNAL_CRITICAL,
+		Elapsed:        MSG_PROVISIONING_ELAPSED,
+		Client:         MSG_SUCCESS_CLIENT,
+		Splitter:       MSG_SUCCESS_SPLITTER,
+		Credentials:    MSG_SUCCESS_CREDENTIALS,
+		EquipmentReady: MSG_EQUIPMENT_READY,
+		WanHeader:      MSG_WAN_SERVICES_HEADER,
+		WanOK:          MSG_WAN_SERVICE_OK,
+		WanFailed:      MSG_WAN_SERVICE_FAILED,
+		WanPrimary:     MSG_WAN_SERVICE_PRIMARY,
+		WanMulticast:   MSG_WAN_SERVICE_MULTICAST,
+		WanUnnamed:     MSG_WAN_SERVICE_UNNAMED,
+		WanReview:      MSG_WAN_SERVICES_REVIEW,
+		Compact:        MSG_SUCCESS_COMPACT,
+		CompactSignal:  MSG_SUCCESS_COMPACT_SIGNAL,
+		SignalAlert:    MSG_SIGNAL_ALERT_TECHNICIAN,
+	},
+	locale.LanguageEnglish: {
+		Summary:        MSG_EN_PROVISIONING_SUCCESS,
+		Reconfigured:   MSG_EN_PROVISIONING_RECONFIGURED,
+		SignalInfo:     MSG_EN_SIGNAL_INFO,
+		SignalWarning:  MSG_EN_SIGNAL_WARNING,
+		SignalCritical: MSG_EN_SIGNAL_CRITICAL,
+		Elapsed:        MSG_EN_PROVISIONING_ELAPSED,
+		Client:         MSG_EN_SUCCESS_CLIENT,
+		Splitter:       MSG_EN_SUCCESS_SPLITTER,
+		Credentials:    MSG_EN_SUCCESS_CREDENTIALS,
+		EquipmentReady: MSG_EN_EQUIPMENT_READY,
+		WanHeader:      MSG_EN_WAN_SERVICES_HEADER,
+		WanOK:          MSG_WAN_SERVICE_OK,
+		WanFailed:      MSG_WAN_SERVICE_FAILED,
+		WanPrimary:     MSG_WAN_SERVICE_PRIMARY,
+		WanMulticast:   MSG_WAN_SERVICE_MULTICAST,
+		WanUnnamed:     MSG_EN_WAN_SERVICE_UNNAMED,
+		WanReview:      MSG_EN_WAN_SERVICES_REVIEW,
+		Compact:        MSG_EN_SUCCESS_COMPACT,
+		CompactSignal:  MSG_EN_SUCCESS_COMPACT_SIGNAL,
+		SignalAlert:    MSG_EN_SIGNAL_ALERT_TECHNICIAN,
+	},
+}
+
+// textFor returns the wording of a language, Portuguese for unknown ones
+func textFor(language string) successText {
+	if text, exists := successTexts[language]; exists {
+		return text
+	}
+	return successTexts[locale.LanguagePortuguese]
+}
+
+// successSection renders one section, an empty result leaves it out
+type successSection func(text successText, formatter *locale.Formatter, connInfo *dto.ConnectionInfo, result *domain.ProvisioningResult, nextSteps string) string
+
+var successSections = map[string]successSection{
+	SectionSummary:     renderSummarySection,
+	SectionClient:      renderClientSection,
+	SectionSignal:      renderSignalSection,
+	SectionCredentials: renderCredentialsSection,
+	SectionElapsed:     renderElapsedSection,
+	SectionNextSteps:   renderNextStepsSection,
+	SectionWanServices: renderWanServicesSection,
+}
+
+// ValidateSuccessSections rejects unknown section names so typos surface at startup
+func ValidateSuccessSections(sections []string) error {
+	for _, section := range sections {
+		if _, exists := successSections[section]; !exists {
+			return fmt.Errorf("seção desconhecida na mensagem de sucesso: %s", section)
+		}
+	}
+	return nil
+}
+
+// renderSuccessMessage builds the success message from the sections of the policy or of the plan template,
+// in the language and layout the technician prefers
+func renderSuccessMessage(
+	policy SuccessMessagePolicy,
+	preferences domain.Preferences,
+	formatter *locale.Formatter,
+	connInfo *dto.ConnectionInfo,
+	result *domain.ProvisioningResult,
+) string {
+	text := textFor(preferences.Language)
+	if preferences.Compact {
+		return renderCompactSuccess(text, formatter, connInfo, result)
+	}
+
+	sections := policy.Sections
+	if result.Template != nil && len(result.Template.SuccessSections) > 0 {
+		sections = result.Template.SuccessSections
+	}
+	if len(sections) == 0 {
+		sections = DefaultSuccessSections
+	}
+
+	// A WAN service left unconfigured is always reported, even by layouts without the section
+	if result.WanServicesFailed() && !slices.Contains(sections, SectionWanServices) {
+		sections = append(slices.Clone(sections), SectionWanServices)
+	}
+
+	var builder strings.Builder
+	for _, name := range sections {
+		render, exists := successSections[name]
+		if !exists {
+			continue
+		}
+		builder.WriteString(render(text, formatter, connInfo, result, policy.NextSteps))
+	}
+
+	return strings.TrimRight(builder.String(), "\n")
+}
+
+// renderCompactSuccess sums the provisioning up in a few lines, failed WAN services are still listed
+func renderCompactSuccess(text successText, formatter *locale.Formatter, connInfo *dto.ConnectionInfo, result *domain.ProvisioningResult) string {
+	message := fmt.Sprintf(text.Compact, connInfo.ContractDescription, connInfo.ConnectionEquipmentSerialNumber)
+	if signal := result.Signal; signal != nil && signal.RxPower != "" {
+		message += fmt.Sprintf(text.CompactSignal, formatter.Measurement(signal.RxPower, 2, "dBm"))
+		message += signalLevelNote(text, formatter, signal)
+	}
+	if result.WanServicesFailed() {
+		message += renderWanServicesSection(text, formatter, connInfo, result, "")
+	}
+	return strings.TrimRight(message, "\n")
+}
+
+func renderSummarySection(text successText, formatter *locale.Formatter, connInfo *dto.ConnectionInfo, result *domain.ProvisioningResult, nextSteps string) string {
+	summary := fmt.Sprintf(text.Summary, connInfo.ContractDescription, connInfo.ConnectionEquipmentSerialNumber)
+	if result.Reconfigured {
+		summary += text.Reconfigured
+	}
+	return summary
+}
+
+func renderClientSection(text successText, formatter *locale.Formatter, connInfo *dto.ConnectionInfo, result *domain.ProvisioningResult, nextSteps string) string {
+	if connInfo.ClientName == "" {
+		return ""
+	}
+
+	section := fmt.Sprintf(text.Client, connInfo.ClientName)
+	if connInfo.ConnectionClientSplitterName != "" {
+		section += fmt.Sprintf(text.Splitter, connInfo.ConnectionClientSplitterName, connInfo.ConnectionClientSplitterPort)
+	}
+	return section
+}
+
+func renderSignalSection(text successText, formatter *locale.Formatter, connInfo *dto.ConnectionInfo, result *domain.ProvisioningResult, nextSteps string) string {
+	signal := result.Signal
+	if signal == nil || signal.TxPower == "" || signal.RxPower == "" {
+		return ""
+	}
+	return formatSignalText(text, formatter, signal)
+}
+
+func renderCredentialsSection(text successText, formatter *locale.Formatter, connInfo *dto.ConnectionInfo, result *domain.ProvisioningResult, nextSteps string) string {
+	if connInfo.ConnectionClientPPPoEUsername == "" {
+		return ""
+	}
+	return fmt.Sprintf(text.Credentials, connInfo.ConnectionClientPPPoEUsername, connInfo.ConnectionClientPPPoEPassword, connInfo.ConnectionClientVlan)
+}
+
+func renderWanServicesSection(text successText, formatter *locale.Formatter, connInfo *dto.ConnectionInfo, result *domain.ProvisioningResult, nextSteps string) string {
+	if len(result.WanServices) < 2 {
+		return ""
+	}
+
+	section := text.WanHeader
+	for _, service := range result.WanServices {
+		name := wanServiceName(text, service)
+		if service.Err != "" {
+			section += fmt.Sprintf(text.WanFailed, name, service.Vlan, service.Err)
+		} else {
+			section += fmt.Sprintf(text.WanOK, name, service.Vlan)
+		}
+	}
+
+	if result.WanServicesFailed() {
+		section += text.WanReview
+	}
+	return section
+}
+
+// extraWanSummary lists the extra WAN services of the contract for the confirmation message
+func extraWanSummary(services []dto.WanService) string {
+	if len(services) == 0 {
+		return ""
+	}
+
+	items := make([]string, 0, len(services))
+	for _, service := range services {
+		items = append(items, fmt.Sprintf(MSG_WAN_SERVICE_ITEM, wanServiceName(successTexts[locale.LanguagePortuguese], domain.WanServiceStatus{Name: service.ServiceName}), service.Vlan))
+	}
+	return strings.Join(items, ", ")
+}
+
+// wanServiceName names a WAN service for the technician, the ERP may leave extra ones untitled
+func wanServiceName(text successText, service domain.WanServiceStatus) string {
+	switch {
+	case service.Primary:
+		return text.WanPrimary
+	case service.Multicast:
+		return text.WanMulticast
+	case service.Name == "":
+		return text.WanUnnamed
+	default:
+		return service.Name
+	}
+}
+
+func renderElapsedSection(text successText, formatter *locale.Formatter, connInfo *dto.ConnectionInfo, result *domain.ProvisioningResult, nextSteps string) string {
+	// Seconds with one decimal keep the message readable for runs that take from a few seconds to minutes
+	return fmt.Sprintf(text.Elapsed, formatter.Decimal(result.Total().Seconds(), 1))
+}
+
+func renderNextStepsSection(text successText, formatter *locale.Formatter, connInfo *dto.ConnectionInfo, result *domain.ProvisioningResult, nextSteps string) string {
+	if nextSteps != "" {
+		return "\n" + nextSteps
+	}
+	return text.EquipmentReady
+}
@@ -0,0 +1,168 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"provisioning-assistant/internal/domain"
+	"provisioning-assistant/internal/locale"
+	"provisioning-assistant/internal/services"
+	"strings"
+)
+
+type TemplateHandler struct {
+	catalogService  *services.TemplateCatalogService
+	templateService *services.PlanTemplateService
+	formatter       *locale.Formatter
+	messenger       *Messenger
+	logger          domain.Logger
+}
+
+// NewTemplateHandler creates a new provisioning template catalog command handler
+func NewTemplateHandler(
+	catalogService *services.TemplateCatalogService,
+	templateService *services.PlanTemplateService,
+	formatter *locale.Formatter,
+	messenger *Messenger,
+	logger domain.Logger,
+) *TemplateHandler {
+	return &TemplateHandler{
+		catalogService:  catalogService,
+		templateService: templateService,
+		formatter:       formatter,
+		messenger:       messenger,
+		logger:          logger,
+	}
+}
+
+// RegisterCommands registers the template catalog administration commands
+func (h *TemplateHandler) RegisterCommands(commands *CommandHandler) {
+	commands.Register("/templates", domain.RoleAdmin, h.handleTemplatesCommand)
+}
+
+// handleTemplatesCommand lists, exports or imports the provisioning templates
+func (h *TemplateHandler) handleTemplatesCommand(ctx context.Context, session *domain.Session, args []string) error {
+	switch {
+	case len(args) == 0:
+		return h.list(ctx, session)
+	case len(args) == 1 && strings.EqualFold(args[0], "exportar"):
+		return h.export(ctx, session)
+	case len(args) == 2 && strings.EqualFold(args[0], "importar"):
+		return h.preview(ctx, session, args[1])
+	case len(args) == 3 && strings.EqualFold(args[0], "importar") && strings.EqualFold(args[2], "aplicar"):
+		return h.apply(ctx, session, args[1])
+	default:
+		return h.messenger.SendMessage(ctx, session.ChatID, MSG_TEMPLATES_USAGE)
+	}
+}
+
+// list sends the templates in use with their WAN settings
+func (h *TemplateHandler) list(ctx context.Context, session *domain.Session) error {
+	templates := h.templateService.Templates()
+	if len(templates) == 0 {
+		return h.messenger.SendMessage(ctx, session.ChatID, MSG_TEMPLATES_LIST_EMPTY)
+	}
+
+	var builder strings.Builder
+	builder.WriteString(MSG_TEMPLATES_LIST_HEADER)
+	for _, template := range templates {
+		marker := ""
+		if template.Default {
+			marker = MSG_TEMPLATES_DEFAULT
+		}
+
+		vlan := template.Vlan
+		if vlan == "" {
+			vlan = MSG_TEMPLATES_UNSET
+		}
+
+		wanPorts := strings.Join(template.WanPorts, ", ")
+		if wanPorts == "" {
+			wanPorts = MSG_TEMPLATES_UNSET
+		}
+
+		multicast := ""
+		if template.MulticastVlan != "" {
+			multicast = fmt.Sprintf(MSG_TEMPLATES_MULTICAST, template.MulticastVlan)
+		}
+
+		builder.WriteString(fmt.Sprintf(MSG_TEMPLATES_LIST_ITEM, template.Name, marker, vlan, wanPorts, multicast))
+	}
+
+	return h.messenger.SendMessage(ctx, session.ChatID, builder.String())
+}
+
+// export writes the templates in use to the artifact store
+func (h *TemplateHandler) export(ctx context.Context, session *domain.Session) error {
+	artifact, catalog, err := h.catalogService.Export(ctx)
+	if err != nil {
+		h.logger.WithError(err).Error("Falha ao exportar templates")
+		return h.messenger.SendMessage(ctx, session.ChatID, fmt.Sprintf(MSG_TEMPLATES_FAILED, err))
+	}
+
+	return h.messenger.SendMessage(ctx, session.ChatID, fmt.Sprintf(MSG_TEMPLATES_EXPORTED, artifact.Name(), len(catalog.Templates)))
+}
+
+// preview shows what importing a catalog would change, without changing anything
+func (h *TemplateHandler) preview(ctx context.Context, session *domain.Session, name string) error {
+	catalog, changes, err := h.load(ctx, name)
+	if err != nil {
+		return h.messenger.SendMessage(ctx, session.ChatID, fmt.Sprintf(MSG_TEMPLATES_FAILED, err))
+	}
+
+	if len(changes) == 0 {
+		return h.messenger.SendMessage(ctx, session.ChatID, fmt.Sprintf(MSG_TEMPLATES_UNCHANGED, name))
+	}
+
+	var builder strings.Builder
+	builder.WriteString(fmt.Sprintf(MSG_TEMPLATES_DIFF_HEADER, name, h.formatter.DateTime(catalog.ExportedAt)))
+	for _, change := range changes {
+		switch change.Kind {
+		case domain.TemplateAdded:
+			builder.WriteString(fmt.Sprintf(MSG_TEMPLATES_DIFF_ADDED, change.Name))
+		case domain.TemplateRemoved:
+			builder.WriteString(fmt.Sprintf(MSG_TEMPLATES_DIFF_REMOVE, change.Name))
+		case domain.TemplateChanged:
+			builder.WriteString(fmt.Sprintf(MSG_TEMPLATES_DIFF_CHANGE, change.Name, strings.Join(change.Fields, ", ")))
+		}
+	}
+	builder.WriteString(fmt.Sprintf(MSG_TEMPLATES_DIFF_APPLY, name))
+
+	return h.messenger.SendMessage(ctx, session.ChatID, builder.String())
+}
+
+// apply replaces the templates in use with the ones of a catalog
+func (h *TemplateHandler) apply(ctx context.Context, session *domain.Session, name string) error {
+	catalog, changes, err := h.load(ctx, name)
+	if err != nil {
+		return h.messenger.SendMessage(ctx, session.ChatID, fmt.Sprintf(MSG_TEMPLATES_FAILED, err))
+	}
+
+	h.logger.WithFields(map[string]any{
+		"user_id": session.UserID,
+		"file":    name,
+		"changes": len(changes),
+	}).Warn("Importação de templates solicitada")
+
+	if err := h.catalogService.Apply(ctx, catalog); err != nil {
+		h.logger.WithError(err).Error("Falha ao importar templates")
+		return h.messenger.SendMessage(ctx, session.ChatID, fmt.Sprintf(MSG_TEMPLATES_FAILED, err))
+	}
+
+	return h.messenger.SendMessage(ctx, session.ChatID, fmt.Sprintf(MSG_TEMPLATES_IMPORTED, name, len(changes)))
+}
+
+// load reads a catalog and checks the success sections, which only the handler knows
+func (h *TemplateHandler) load(ctx context.Context, name string) (*domain.TemplateCatalog, []domain.TemplateChange, error) {
+	catalog, changes, err := h.catalogService.Load(ctx, name)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for _, template := range catalog.Templates {
+		if err := ValidateSuccessSections(template.SuccessSections); err != nil {
+			return nil, nil, fmt.Errorf("template %s: %w", template.Name, err)
+		}
+	}
+
+	return catalog, changes, nil
+}
@@ -0,0 +1,191 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"provisioning-assistant/internal/domain"
+	"provisioning-assistant/internal/services"
+	"provisioning-assistant/internal/unm"
+	"strings"
+	"unicode/utf8"
+)
+
+// maxConsoleResponseLength keeps TL1 responses within a single Telegram message
+const maxConsoleResponseLength = 3500
+
+// Tl1ConsoleHandler lets super-admins send raw TL1 commands to a UNM endpoint for emergency troubleshooting
+type Tl1ConsoleHandler struct {
+	consoleService *services.Tl1ConsoleService
+	sessionService *services.SessionService
+	operationGuard *OperationGuard
+	attemptGuard   *AttemptGuard
+	adminNotifier  *AdminNotifier
+	keyboards      *KeyboardCatalog
+	messenger      *Messenger
+	logger         domain.Logger
+}
+
+// NewTl1ConsoleHandler creates a new raw TL1 console handler
+func NewTl1ConsoleHandler(
+	consoleService *services.Tl1ConsoleService,
+	sessionService *services.SessionService,
+	operationGuard *OperationGuard,
+	attemptGuard *AttemptGuard,
+	adminNotifier *AdminNotifier,
+	keyboards *KeyboardCatalog,
+	messenger *Messenger,
+	logger domain.Logger,
+) *Tl1ConsoleHandler {
+	return &Tl1ConsoleHandler{
+		consoleService: consoleService,
+		sessionService: sessionService,
+		operationGuard: operationGuard,
+		attemptGuard:   attemptGuard,
+		adminNotifier:  adminNotifier,
+		keyboards:      keyboards,
+		messenger:      messenger,
+		logger:         logger,
+	}
+}
+
+// RegisterCommands registers the console command, further restricted to the configured operators
+func (h *Tl1ConsoleHandler) RegisterCommands(commands *CommandHandler) {
+	commands.Register("/tl1", domain.RoleAdmin, h.handleConsoleCommand)
+}
+
+// handleConsoleCommand opens the console on the endpoint given as argument or asks which one to use
+func (h *Tl1ConsoleHandler) handleConsoleCommand(ctx context.Context, session *domain.Session, args []string) error {
+	if !h.authorize(ctx, session) {
+		return h.messenger.SendMessage(ctx, session.ChatID, MSG_COMMAND_NOT_ALLOWED)
+	}
+
+	if len(args) == 1 {
+		return h.open(ctx, session, strings.ToLower(args[0]))
+	}
+
+	if len(args) > 1 {
+		return h.messenger.SendMessage(ctx, session.ChatID, MSG_TL1_USAGE)
+	}
+
+	keyboard := h.keyboards.Build(KeyboardTl1Endpoints, WithChoices(h.consoleService.Endpoints()...))
+
+	return h.messenger.SendMessageWithKeyboard(ctx, session.ChatID, MSG_TL1_CHOOSE_ENDPOINT, keyboard)
+}
+
+// HandleEndpointOption opens the console on the endpoint picked from the keyboard
+func (h *Tl1ConsoleHandler) HandleEndpointOption(ctx context.Context, session *domain.Session, endpoint string) error {
+	if session.UserTaxID == "" || !session.UserRole.Includes(domain.RoleAdmin) || !h.authorize(ctx, session) {
+		return h.messenger.SendMessage(ctx, session.ChatID, MSG_COMMAND_NOT_ALLOWED)
+	}
+
+	return h.open(ctx, session, endpoint)
+}
+
+// HandleCommandInput validates and runs a raw TL1 command typed in the console
+func (h *Tl1ConsoleHandler) HandleCommandInput(ctx context.Context, session *domain.Session, msg *domain.MessageEvent) error {
+	input := strings.TrimSpace(msg.Message)
+
+	if strings.EqualFold(input, MSG_TL1_EXIT_WORD) {
+		updateSession(h.sessionService, session, func(s *domain.Session) {
+			s.State = domain.StateMainMenu
+			s.ConsoleEndpoint = ""
+		})
+		return h.messenger.SendMessage(ctx, session.ChatID, MSG_TL1_CLOSED)
+	}
+
+	// Operators may be removed from the configuration while the console is open
+	if !h.authorize(ctx, session) {
+		updateSession(h.sessionService, session, func(s *domain.Session) {
+			s.State = domain.StateMainMenu
+			s.ConsoleEndpoint = ""
+		})
+		return h.messenger.SendMessage(ctx, session.ChatID, MSG_COMMAND_NOT_ALLOWED)
+	}
+
+	command, err := h.consoleService.Validate(input)
+	if err != nil {
+		return h.attemptGuard.Reject(ctx, session, fmt.Sprintf(MSG_TL1_INVALID, err, strings.Join(h.consoleService.Verbs(), ", ")))
+	}
+
+	endpoint := session.ConsoleEndpoint
+	chatID := session.ChatID
+	operator := *session
+
+	description := fmt.Sprintf(MSG_TL1_DESCRIPTION, endpoint)
+	return h.operationGuard.Run(ctx, session, description, command, func(ctx context.Context) error {
+		return h.execute(ctx, &operator, chatID, endpoint, command)
+	})
+}
+
+// execute sends the command and answers with the formatted response
+func (h *Tl1ConsoleHandler) execute(ctx context.Context, operator *domain.Session, chatID int64, endpoint, command string) error {
+	h.messenger.SendTypingIndicator(ctx, chatID)
+
+	record, err := h.consoleService.Execute(ctx, operator, endpoint, command)
+	if record == nil {
+		return h.messenger.SendMessage(ctx, chatID, fmt.Sprintf(MSG_TL1_FAILED, err))
+	}
+
+	if unm.ClassifyCommand(command) != unm.BlastRadiusNone {
+		h.adminNotifier.Notify(ctx, fmt.Sprintf(MSG_TL1_ADMIN_NOTICE, operator.UserName, operator.UserID, endpoint, command))
+	}
+
+	message := fmt.Sprintf(MSG_TL1_RESPONSE, endpoint, formatConsoleResponse(record.Response))
+	if err != nil {
+		message = fmt.Sprintf(MSG_TL1_FAILED, err)
+		if record.Response != "" {
+			message += "\n\n" + formatConsoleResponse(record.Response)
+		}
+	}
+
+	if record.TranscriptKey != "" {
+		message += fmt.Sprintf(MSG_TL1_TRANSCRIPT, record.TranscriptKey)
+	}
+
+	return h.messenger.SendMessage(ctx, chatID, message)
+}
+
+// open switches the session into console mode on the endpoint
+func (h *Tl1ConsoleHandler) open(ctx context.Context, session *domain.Session, endpoint string) error {
+	if !h.consoleService.HasEndpoint(endpoint) {
+		return h.messenger.SendMessage(ctx, session.ChatID, fmt.Sprintf(MSG_TL1_UNKNOWN_ENDPOINT, endpoint, strings.Join(h.consoleService.Endpoints(), ", ")))
+	}
+
+	updateSession(h.sessionService, session, func(s *domain.Session) {
+		s.State = domain.StateTl1Console
+		s.ConsoleEndpoint = endpoint
+		s.InvalidAttempts = 0
+	})
+
+	h.logger.WithFields(map[string]any{
+		"user_id":  session.UserID,
+		"endpoint": endpoint,
+	}).Warn("Console TL1 aberto")
+
+	return h.messenger.SendMessage(ctx, session.ChatID, fmt.Sprintf(MSG_TL1_OPENED, endpoint, strings.Join(h.consoleService.Verbs(), ", ")))
+}
+
+// authorize checks the operator allowlist, logging denied attempts of admins outside it
+func (h *Tl1ConsoleHandler) authorize(ctx context.Context, session *domain.Session) bool {
+	if h.consoleService.IsOperator(session.UserID) {
+		return true
+	}
+
+	h.logger.WithField("user_id", session.UserID).Warn("Acesso ao console TL1 negado, usuário fora da lista de operadores")
+	return false
+}
+
+// formatConsoleResponse trims the TL1 response and cuts it to fit a message
+func formatConsoleResponse(response string) string {
+	response = strings.TrimSpace(strings.ReplaceAll(response, "\r", ""))
+	if len(response) <= maxConsoleResponseLength {
+		return response
+	}
+
+	cut := response[:maxConsoleResponseLength]
+	for !utf8.ValidString(cut) {
+		cut = cut[:len(cut)-1]
+	}
+
+	return cut + MSG_TL1_TRUNCATED
+}
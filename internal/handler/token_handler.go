@@ -0,0 +1,114 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"provisioning-assistant/internal/domain"
+	"provisioning-assistant/internal/services"
+	"strings"
+)
+
+type TokenHandler struct {
+	tokenService *services.TokenService
+	messenger    *Messenger
+}
+
+// NewTokenHandler creates a new service-account token command handler
+func NewTokenHandler(tokenService *services.TokenService, messenger *Messenger) *TokenHandler {
+	return &TokenHandler{
+		tokenService: tokenService,
+		messenger:    messenger,
+	}
+}
+
+// RegisterCommands registers the token administration commands
+func (h *TokenHandler) RegisterCommands(commands *CommandHandler) {
+	commands.Register("/token", domain.RoleAdmin, h.handleTokenCommand)
+}
+
+// handleTokenCommand routes /token subcommands
+func (h *TokenHandler) handleTokenCommand(ctx context.Context, session *domain.Session, args []string) error {
+	if len(args) == 0 {
+		return h.messenger.SendMessage(ctx, session.ChatID, MSG_TOKEN_USAGE)
+	}
+
+	switch strings.ToLower(args[0]) {
+	case "criar":
+		return h.issue(ctx, session, args[1:])
+	case "listar":
+		return h.list(ctx, session)
+	case "revogar":
+		return h.revoke(ctx, session, args[1:])
+	default:
+		return h.messenger.SendMessage(ctx, session.ChatID, MSG_TOKEN_USAGE)
+	}
+}
+
+// issue creates a new token from "<nome> <escopos>" arguments
+func (h *TokenHandler) issue(ctx context.Context, session *domain.Session, args []string) error {
+	if len(args) != 2 {
+		return h.messenger.SendMessage(ctx, session.ChatID, MSG_TOKEN_USAGE)
+	}
+
+	var scopes []domain.Scope
+	for _, scope := range strings.Split(args[1], ",") {
+		if scope = strings.TrimSpace(scope); scope != "" {
+			scopes = append(scopes, domain.Scope(scope))
+		}
+	}
+
+	token, plaintext, err := h.tokenService.Issue(ctx, args[0], scopes, session.UserID)
+	if err != nil {
+		return h.messenger.SendMessage(ctx, session.ChatID, fmt.Sprintf(MSG_TOKEN_FAILED, err))
+	}
+
+	message := fmt.Sprintf(MSG_TOKEN_CREATED, token.ID, token.Name, h.formatScopes(token.Scopes), plaintext)
+	return h.messenger.SendMessage(ctx, session.ChatID, message)
+}
+
+// list sends every issued token with its status
+func (h *TokenHandler) list(ctx context.Context, session *domain.Session) error {
+	tokens, err := h.tokenService.List(ctx)
+	if err != nil {
+		return h.messenger.SendMessage(ctx, session.ChatID, fmt.Sprintf(MSG_TOKEN_FAILED, err))
+	}
+
+	if len(tokens) == 0 {
+		return h.messenger.SendMessage(ctx, session.ChatID, MSG_TOKEN_LIST_EMPTY)
+	}
+
+	var builder strings.Builder
+	builder.WriteString(MSG_TOKEN_LIST_HEADER)
+
+	for _, token := range tokens {
+		status := MSG_TOKEN_STATUS_ACTIVE
+		if token.IsRevoked() {
+			status = MSG_TOKEN_STATUS_REVOKED
+		}
+		builder.WriteString(fmt.Sprintf(MSG_TOKEN_LIST_ITEM, token.ID, token.Name, h.formatScopes(token.Scopes), status))
+	}
+
+	return h.messenger.SendMessage(ctx, session.ChatID, builder.String())
+}
+
+// revoke disables the token with the given ID
+func (h *TokenHandler) revoke(ctx context.Context, session *domain.Session, args []string) error {
+	if len(args) != 1 {
+		return h.messenger.SendMessage(ctx, session.ChatID, MSG_TOKEN_USAGE)
+	}
+
+	if err := h.tokenService.Revoke(ctx, args[0]); err != nil {
+		return h.messenger.SendMessage(ctx, session.ChatID, fmt.Sprintf(MSG_TOKEN_FAILED, err))
+	}
+
+	return h.messenger.SendMessage(ctx, session.ChatID, fmt.Sprintf(MSG_TOKEN_REVOKED, args[0]))
+}
+
+// formatScopes joins scopes for display
+func (h *TokenHandler) formatScopes(scopes []domain.Scope) string {
+	values := make([]string, len(scopes))
+	for i, scope := range scopes {
+		values[i] = string(scope)
+	}
+	return strings.Join(values, ", ")
+}
@@ -0,0 +1,85 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"provisioning-assistant/internal/domain"
+	"provisioning-assistant/internal/services"
+	"strconv"
+	"strings"
+)
+
+type TrainingHandler struct {
+	trainingService *services.TrainingService
+	messenger       *Messenger
+}
+
+// NewTrainingHandler creates a new training sandbox command handler
+func NewTrainingHandler(trainingService *services.TrainingService, messenger *Messenger) *TrainingHandler {
+	return &TrainingHandler{
+		trainingService: trainingService,
+		messenger:       messenger,
+	}
+}
+
+// RegisterCommands registers the training sandbox administration commands
+func (h *TrainingHandler) RegisterCommands(commands *CommandHandler) {
+	commands.Register("/treinamento", domain.RoleAdmin, h.handleTrainingCommand)
+}
+
+// handleTrainingCommand lists the users in training or switches one with "<id do Telegram> on|off"
+func (h *TrainingHandler) handleTrainingCommand(ctx context.Context, session *domain.Session, args []string) error {
+	if len(args) == 0 {
+		return h.list(ctx, session)
+	}
+
+	if len(args) != 2 {
+		return h.messenger.SendMessage(ctx, session.ChatID, MSG_TRAINING_USAGE)
+	}
+
+	userID, err := strconv.ParseInt(args[0], 10, 64)
+	if err != nil {
+		return h.messenger.SendMessage(ctx, session.ChatID, MSG_TRAINING_USAGE)
+	}
+
+	var enabled bool
+	switch strings.ToLower(args[1]) {
+	case "on":
+		enabled = true
+	case "off":
+		enabled = false
+	default:
+		return h.messenger.SendMessage(ctx, session.ChatID, MSG_TRAINING_USAGE)
+	}
+
+	if err := h.trainingService.Set(ctx, userID, enabled); err != nil {
+		return h.messenger.SendMessage(ctx, session.ChatID, fmt.Sprintf(MSG_TRAINING_FAILED, err))
+	}
+
+	message := MSG_TRAINING_DISABLED
+	if enabled {
+		message = MSG_TRAINING_ENABLED
+	}
+
+	return h.messenger.SendMessage(ctx, session.ChatID, fmt.Sprintf(message, userID))
+}
+
+// list sends the users currently in the training sandbox
+func (h *TrainingHandler) list(ctx context.Context, session *domain.Session) error {
+	users, err := h.trainingService.List(ctx)
+	if err != nil {
+		return h.messenger.SendMessage(ctx, session.ChatID, fmt.Sprintf(MSG_TRAINING_FAILED, err))
+	}
+
+	if len(users) == 0 {
+		return h.messenger.SendMessage(ctx, session.ChatID, MSG_TRAINING_LIST_EMPTY)
+	}
+
+	var builder strings.Builder
+	builder.WriteString(MSG_TRAINING_LIST_HEADER)
+	for _, userID := range users {
+		builder.WriteString(fmt.Sprintf(MSG_TRAINING_LIST_ITEM, userID))
+	}
+
+	return h.messenger.SendMessage(ctx, session.ChatID, builder.String())
+}
@@ -0,0 +1,167 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"provisioning-assistant/internal/domain"
+	"provisioning-assistant/internal/locale"
+	"provisioning-assistant/internal/services"
+	"strings"
+)
+
+// Causes found by the guided diagnosis, kept in the audit records for the failure analysis
+const (
+	TroubleshootConnector    = "connector"
+	TroubleshootCtoPort      = "cto_port"
+	TroubleshootDropCable    = "drop_cable"
+	TroubleshootCtoLowSignal = "cto_low_signal"
+	TroubleshootCtoNoLight   = "cto_no_light"
+)
+
+// troubleshootStep is where an answer of the diagnosis leads, either the next question with its keyboard
+// or the cause found. Causes outside the reach of the technician are escalated to the admins.
+type troubleshootStep struct {
+	message  string
+	keyboard string
+	outcome  string
+	escalate bool
+}
+
+// troubleshootTree walks the technician from the customer end towards the network: the connector of the
+// ONU, the port of the CTO, then the power meter readings
+var troubleshootTree = map[string]troubleshootStep{
+	services.TroubleshootingStart: {message: MSG_TROUBLESHOOT_CONNECTOR, keyboard: KeyboardTroubleshootConnector},
+	"connector_fixed":             {message: MSG_TROUBLESHOOT_FIXED, outcome: TroubleshootConnector},
+	"connector_failed":            {message: MSG_TROUBLESHOOT_CTO, keyboard: KeyboardTroubleshootCto},
+	"cto_fixed":                   {message: MSG_TROUBLESHOOT_FIXED, outcome: TroubleshootCtoPort},
+	"cto_failed":                  {message: MSG_TROUBLESHOOT_METER, keyboard: KeyboardTroubleshootMeter},
+	"meter_drop":                  {message: MSG_TROUBLESHOOT_DROP, outcome: TroubleshootDropCable},
+	"meter_cto":                   {message: MSG_TROUBLESHOOT_ESCALATED, outcome: TroubleshootCtoLowSignal, escalate: true},
+	"meter_none":                  {message: MSG_TROUBLESHOOT_ESCALATED, outcome: TroubleshootCtoNoLight, escalate: true},
+}
+
+// troubleshootOutcomes names the causes in the messages and reports
+var troubleshootOutcomes = map[string]string{
+	TroubleshootConnector:    MSG_TROUBLESHOOT_OUTCOME_CONNECTOR,
+	TroubleshootCtoPort:      MSG_TROUBLESHOOT_OUTCOME_CTO_PORT,
+	TroubleshootDropCable:    MSG_TROUBLESHOOT_OUTCOME_DROP,
+	TroubleshootCtoLowSignal: MSG_TROUBLESHOOT_OUTCOME_CTO_LOW,
+	TroubleshootCtoNoLight:   MSG_TROUBLESHOOT_OUTCOME_CTO_NO_LIGHT,
+}
+
+type TroubleshootHandler struct {
+	auditService  *services.AuditService
+	adminNotifier *AdminNotifier
+	formatter     *locale.Formatter
+	keyboards     *KeyboardCatalog
+	messenger     *Messenger
+	logger        domain.Logger
+}
+
+// NewTroubleshootHandler creates a new handler guiding the technicians through ONUs without signal
+func NewTroubleshootHandler(
+	auditService *services.AuditService,
+	adminNotifier *AdminNotifier,
+	formatter *locale.Formatter,
+	keyboards *KeyboardCatalog,
+	messenger *Messenger,
+	logger domain.Logger,
+) *TroubleshootHandler {
+	return &TroubleshootHandler{
+		auditService:  auditService,
+		adminNotifier: adminNotifier,
+		formatter:     formatter,
+		keyboards:     keyboards,
+		messenger:     messenger,
+		logger:        logger,
+	}
+}
+
+// NeedsTroubleshooting reports whether a signal reading is below the critical threshold of its OLT
+func NeedsTroubleshooting(signal *domain.OnuSignalInfo) bool {
+	return signal != nil && signal.Level == domain.SignalCritical
+}
+
+// Offer proposes the guided diagnosis of the ONU of an audit record, a nil signal standing for an ONU that
+// did not answer the reading at all
+func (h *TroubleshootHandler) Offer(ctx context.Context, chatID int64, auditID string, signal *domain.OnuSignalInfo) {
+	message := MSG_TROUBLESHOOT_OFFER_LOS
+	if signal != nil {
+		message = fmt.Sprintf(MSG_TROUBLESHOOT_OFFER_LOW, h.formatter.Measurement(signal.RxPower, 2, "dBm"))
+	}
+
+	keyboard := h.keyboards.Build(KeyboardTroubleshootStart, WithTarget(auditID))
+	if err := h.messenger.SendMessageWithKeyboard(ctx, chatID, message, keyboard); err != nil {
+		h.logger.WithError(err).WithField("audit_id", auditID).Warn("Falha ao oferecer diagnóstico de sinal")
+	}
+}
+
+// OfferForSerial proposes the guided diagnosis of the latest provisioning of an ONU
+func (h *TroubleshootHandler) OfferForSerial(ctx context.Context, chatID int64, serial string, signal *domain.OnuSignalInfo) {
+	record, err := h.auditService.FindLatestBySerial(ctx, serial)
+	if err != nil {
+		h.logger.WithError(err).WithField("serial", serial).Debug("ONU sem registro de auditoria para diagnóstico")
+		return
+	}
+
+	h.Offer(ctx, chatID, record.ID, signal)
+}
+
+// HandleOption records an answer of the diagnosis, given as "<answer>:<audit id>", and asks the next
+// question or reports the cause found
+func (h *TroubleshootHandler) HandleOption(ctx context.Context, session *domain.Session, option string) error {
+	answer, auditID, _ := strings.Cut(option, ":")
+	step, exists := troubleshootTree[answer]
+	if !exists || auditID == "" {
+		return h.messenger.SendMessage(ctx, session.ChatID, MSG_CALLBACK_INVALID)
+	}
+
+	record, err := h.auditService.GetRecord(ctx, auditID)
+	if err != nil {
+		return h.messenger.SendMessage(ctx, session.ChatID, fmt.Sprintf(MSG_AUDIT_NOT_FOUND, auditID))
+	}
+
+	if record.UserID != session.UserID && !session.UserRole.Includes(domain.RoleSupervisor) {
+		return h.messenger.SendMessage(ctx, session.ChatID, MSG_COMMAND_NOT_ALLOWED)
+	}
+
+	if answer != services.TroubleshootingStart {
+		if record.Troubleshooting == nil {
+			return h.messenger.SendMessage(ctx, session.ChatID, MSG_CALLBACK_INVALID)
+		}
+		if record.Troubleshooting.FinishedAt != nil {
+			return h.messenger.SendMessage(ctx, session.ChatID, fmt.Sprintf(MSG_TROUBLESHOOT_ALREADY, troubleshootOutcomes[record.Troubleshooting.Outcome]))
+		}
+	}
+
+	if _, err := h.auditService.RecordTroubleshooting(ctx, auditID, answer, step.outcome); err != nil {
+		h.logger.WithError(err).WithField("audit_id", auditID).Error("Falha ao registrar diagnóstico de sinal")
+	}
+
+	if step.keyboard != "" {
+		return h.messenger.SendMessageWithKeyboard(ctx, session.ChatID, step.message, h.keyboards.Build(step.keyboard, WithTarget(auditID)))
+	}
+
+	outcome := troubleshootOutcomes[step.outcome]
+	h.logger.WithFields(map[string]any{
+		"audit_id": auditID,
+		"serial":   record.Serial,
+		"outcome":  step.outcome,
+	}).Info("Diagnóstico de sinal concluído")
+
+	if step.escalate {
+		h.adminNotifier.Notify(ctx, fmt.Sprintf(
+			MSG_ALERT_TROUBLESHOOT,
+			outcome,
+			record.Contract,
+			record.Serial,
+			record.OltIP,
+			record.Slot,
+			record.Port,
+			session.UserName,
+			record.ID,
+		))
+	}
+
+	return h.messenger.SendMessage(ctx, session.ChatID, fmt.Sprintf(step.message, outcome))
+}
@@ -0,0 +1,122 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"provisioning-assistant/internal/domain"
+	"provisioning-assistant/internal/domain/dto"
+	"provisioning-assistant/internal/services"
+)
+
+type WorkOrderHandler struct {
+	workOrderService    *services.WorkOrderService
+	sessionService      *services.SessionService
+	menuHandler         *MenuHandler
+	provisioningHandler *ProvisioningHandler
+	keyboards           *KeyboardCatalog
+	messenger           *Messenger
+	logger              domain.Logger
+}
+
+// NewWorkOrderHandler creates a new handler greeting the technicians assigned to a protocol
+func NewWorkOrderHandler(
+	workOrderService *services.WorkOrderService,
+	sessionService *services.SessionService,
+	menuHandler *MenuHandler,
+	provisioningHandler *ProvisioningHandler,
+	keyboards *KeyboardCatalog,
+	messenger *Messenger,
+	logger domain.Logger,
+) *WorkOrderHandler {
+	return &WorkOrderHandler{
+		workOrderService:    workOrderService,
+		sessionService:      sessionService,
+		menuHandler:         menuHandler,
+		provisioningHandler: provisioningHandler,
+		keyboards:           keyboards,
+		messenger:           messenger,
+		logger:              logger,
+	}
+}
+
+// GreetAssignedTechnicians messages each technician newly assigned to a protocol with a button that starts its
+// provisioning, a greeting that fails to send is tried again on the next run
+func (h *WorkOrderHandler) GreetAssignedTechnicians(ctx context.Context) error {
+	greetings, err := h.workOrderService.Pending(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, greeting := range greetings {
+		h.greet(ctx, greeting)
+	}
+
+	return nil
+}
+
+// GreetFromErp greets the technician of a protocol the ERP webhook reported as created
+func (h *WorkOrderHandler) GreetFromErp(ctx context.Context, event *domain.ErpEvent) {
+	greeting, ok := h.workOrderService.Greeting(ctx, &dto.WorkOrder{
+		Protocol:        event.Protocol,
+		AssignmentTitle: event.AssignmentTitle,
+		TechnicianTaxID: event.TechnicianTaxID,
+		ClientName:      event.ClientName,
+		AssignedAt:      event.OccurredAt,
+	})
+	if ok {
+		h.greet(ctx, greeting)
+	}
+}
+
+// greet sends a greeting and records it, one that fails to send is left for the next run
+func (h *WorkOrderHandler) greet(ctx context.Context, greeting services.WorkOrderGreeting) {
+	workOrder := greeting.WorkOrder
+	log := h.logger.WithFields(map[string]any{
+		"protocol": workOrder.Protocol,
+		"user_id":  greeting.Binding.UserID,
+	})
+
+	message := fmt.Sprintf(MSG_WORK_ORDER_ASSIGNED, workOrder.Protocol, workOrder.AssignmentTitle, workOrder.ClientName)
+	keyboard := h.keyboards.Build(KeyboardWorkOrder, WithTarget(workOrder.Protocol))
+
+	err := h.messenger.SendMessageWithKeyboard(ctx, greeting.Binding.ChatID, message, keyboard)
+	if err != nil && !errors.Is(err, domain.ErrChatUnavailable) {
+		log.WithError(err).Warn("Falha ao avisar técnico da ordem de serviço")
+		return
+	}
+
+	if err := h.workOrderService.MarkGreeted(ctx, workOrder); err != nil {
+		log.WithError(err).Error("Falha ao registrar aviso da ordem de serviço")
+	}
+}
+
+// HandleStartOption starts the provisioning of the protocol of a work order greeting, as if the technician had
+// typed it from the main menu
+func (h *WorkOrderHandler) HandleStartOption(ctx context.Context, session *domain.Session, protocol string) error {
+	if session.UserTaxID == "" {
+		return h.messenger.SendMessage(ctx, session.ChatID, MSG_WORK_ORDER_LOGIN)
+	}
+
+	if session.State != domain.StateMainMenu && session.State != domain.StateIdle {
+		return h.messenger.SendMessage(ctx, session.ChatID, MSG_WORK_ORDER_BUSY)
+	}
+
+	if h.menuHandler.IsOutage(ctx) {
+		return h.messenger.SendMessage(ctx, session.ChatID, MSG_DEPENDENCY_OUTAGE)
+	}
+
+	if h.menuHandler.IsProvisioningSuspended(ctx, session) {
+		return h.messenger.SendMessage(ctx, session.ChatID, MSG_AUTO_PROVISIONING_SUSPENDED)
+	}
+
+	updateSession(h.sessionService, session, func(s *domain.Session) {
+		s.State = domain.StateWaitingProtocol
+	})
+
+	return h.provisioningHandler.HandleProtocolInput(ctx, session, &domain.MessageEvent{
+		UserID:  session.UserID,
+		ChatID:  session.ChatID,
+		Message: protocol,
+	})
+}
@@ -0,0 +1,149 @@
+package ids
+
+import (
+	"crypto/rand"
+	"io"
+	"provisioning-assistant/internal/clock"
+	"strings"
+	"sync"
+	"time"
+)
+
+// alphabet is the Crockford base32 alphabet of the ULID text form
+const alphabet = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// Length is the size of an identifier in its text form
+const Length = 26
+
+// Generator issues ULIDs: 48 bits of milliseconds followed by 80 random bits, so identifiers sort in the
+// order they were created. Identifiers issued in the same millisecond increment the random part.
+type Generator struct {
+	clock   clock.Clock
+	entropy io.Reader
+
+	mu         sync.Mutex
+	lastMillis int64
+	last       [10]byte
+}
+
+// NewGenerator creates a generator reading time from the clock and randomness from the entropy source,
+// a nil source uses the cryptographic one
+func NewGenerator(clock clock.Clock, entropy io.Reader) *Generator {
+	if entropy == nil {
+		entropy = rand.Reader
+	}
+
+	return &Generator{
+		clock:   clock,
+		entropy: entropy,
+	}
+}
+
+var defaultGenerator = NewGenerator(clock.System, nil)
+
+// New issues an identifier from the wall clock
+func New() string {
+	return defaultGenerator.New()
+}
+
+// New issues an identifier for the current time of the generator clock
+func (g *Generator) New() string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	millis := g.clock.Now().UnixMilli()
+	if millis <= g.lastMillis {
+		// Same millisecond or a clock set back, the order holds by bumping the random part
+		millis = g.lastMillis
+		if !g.increment() {
+			millis++
+		}
+	} else if _, err := io.ReadFull(g.entropy, g.last[:]); err != nil {
+		// Without entropy the identifier still has to be unique within the process
+		g.increment()
+	}
+	g.lastMillis = millis
+
+	var id [16]byte
+	for i := range 6 {
+		id[i] = byte(g.lastMillis >> (40 - 8*i))
+	}
+	copy(id[6:], g.last[:])
+
+	return encode(id)
+}
+
+// increment adds one to the random part, false when it wrapped around
+func (g *Generator) increment() bool {
+	for i := len(g.last) - 1; i >= 0; i-- {
+		g.last[i]++
+		if g.last[i] != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// encode writes the 128 bits of an identifier as 26 base32 characters, the first one carrying 3 bits
+func encode(id [16]byte) string {
+	var builder strings.Builder
+	builder.Grow(Length)
+
+	for i := range Length {
+		// Bit offset of the character counted from the least significant end
+		shift := 5 * (Length - 1 - i)
+		builder.WriteByte(alphabet[bitsAt(id, shift)])
+	}
+	return builder.String()
+}
+
+// bitsAt reads the five bits of the identifier starting at the bit offset, counted from the least
+// significant end
+func bitsAt(id [16]byte, shift int) byte {
+	var value byte
+	for bit := range 5 {
+		position := shift + bit
+		if position >= 128 {
+			break
+		}
+		if id[15-position/8]>>(position%8)&1 == 1 {
+			value |= 1 << bit
+		}
+	}
+	return value
+}
+
+// Time returns when an identifier was issued, false when it is not a ULID such as the numeric
+// identifiers of records created before them
+func Time(id string) (time.Time, bool) {
+	if !IsValid(id) {
+		return time.Time{}, false
+	}
+
+	var millis int64
+	for _, char := range strings.ToUpper(id[:10]) {
+		millis = millis<<5 | int64(strings.IndexRune(alphabet, char))
+	}
+	return time.UnixMilli(millis), true
+}
+
+// IsValid reports whether the text is a ULID, letters in any case
+func IsValid(id string) bool {
+	if len(id) != Length || id[0] > '7' {
+		return false
+	}
+	for _, char := range strings.ToUpper(id) {
+		if !strings.ContainsRune(alphabet, char) {
+			return false
+		}
+	}
+	return true
+}
+
+// Normalize writes an identifier typed or clicked in any case as issued, other identifiers are kept
+func Normalize(id string) string {
+	if IsValid(id) {
+		return strings.ToUpper(id)
+	}
+	return id
+}
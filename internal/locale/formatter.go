@@ -0,0 +1,140 @@
+package locale
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	// Embedded so the configured timezone resolves on hosts without zoneinfo
+	_ "time/tzdata"
+)
+
+const (
+	DefaultTimezone = "America/Sao_Paulo"
+
+	DateLayout     = "02/01/2006"
+	TimeLayout     = "15:04"
+	DateTimeLayout = "02/01/2006 15:04"
+
+	DecimalSeparator  = ","
+	ThousandSeparator = "."
+)
+
+// Formatter renders user-facing numbers, durations and timestamps in pt-BR using the configured timezone
+type Formatter struct {
+	location *time.Location
+}
+
+// NewFormatter creates a formatter for the given IANA timezone, empty means the default timezone
+func NewFormatter(timezone string) (*Formatter, error) {
+	if timezone == "" {
+		timezone = DefaultTimezone
+	}
+
+	location, err := time.LoadLocation(timezone)
+	if err != nil {
+		return nil, fmt.Errorf("fuso horário inválido %q: %w", timezone, err)
+	}
+
+	return &Formatter{location: location}, nil
+}
+
+// Location returns the configured timezone
+func (f *Formatter) Location() *time.Location {
+	return f.location
+}
+
+// DateTime formats a timestamp as dd/mm/yyyy HH:MM in the configured timezone
+func (f *Formatter) DateTime(t time.Time) string {
+	return t.In(f.location).Format(DateTimeLayout)
+}
+
+// Date formats a timestamp as dd/mm/yyyy in the configured timezone
+func (f *Formatter) Date(t time.Time) string {
+	return t.In(f.location).Format(DateLayout)
+}
+
+// Time formats a timestamp as HH:MM in the configured timezone
+func (f *Formatter) Time(t time.Time) string {
+	return t.In(f.location).Format(TimeLayout)
+}
+
+// Decimal formats a number with comma decimals and dot thousand separators
+func (f *Formatter) Decimal(value float64, places int) string {
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		return "-"
+	}
+
+	raw := strconv.FormatFloat(math.Abs(value), 'f', places, 64)
+	integer, fraction, _ := strings.Cut(raw, ".")
+
+	var builder strings.Builder
+	if value < 0 && strings.Trim(raw, "0.") != "" {
+		builder.WriteString("-")
+	}
+
+	for i, digit := range integer {
+		if i > 0 && (len(integer)-i)%3 == 0 {
+			builder.WriteString(ThousandSeparator)
+		}
+		builder.WriteRune(digit)
+	}
+
+	if fraction != "" {
+		builder.WriteString(DecimalSeparator)
+		builder.WriteString(fraction)
+	}
+
+	return builder.String()
+}
+
+// Integer formats a whole number with dot thousand separators
+func (f *Formatter) Integer(value int) string {
+	return f.Decimal(float64(value), 0)
+}
+
+// Percent formats a 0-1 ratio as a percentage without decimals
+func (f *Formatter) Percent(ratio float64) string {
+	return f.Decimal(ratio*100, 0) + "%"
+}
+
+// Measurement formats a raw numeric reading with its unit, keeping the raw text when it is not a number
+func (f *Formatter) Measurement(raw string, places int, unit string) string {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return "-"
+	}
+
+	value, err := strconv.ParseFloat(strings.ReplaceAll(raw, ",", "."), 64)
+	if err != nil {
+		return raw
+	}
+
+	return f.Decimal(value, places) + " " + unit
+}
+
+// Duration formats a duration in milliseconds below one second and in seconds with one decimal above
+func (f *Formatter) Duration(d time.Duration) string {
+	if d < time.Second {
+		return f.Integer(int(d.Milliseconds())) + " ms"
+	}
+	return f.Decimal(d.Seconds(), 1) + " s"
+}
+
+// Size formats a byte count in the largest unit below it, with one decimal above the bytes
+func (f *Formatter) Size(bytes int64) string {
+	units := []string{"KB", "MB", "GB", "TB"}
+	if bytes < 1024 {
+		return f.Integer(int(bytes)) + " B"
+	}
+
+	value := float64(bytes) / 1024
+	unit := 0
+	for value >= 1024 && unit < len(units)-1 {
+		value /= 1024
+		unit++
+	}
+	return f.Decimal(value, 1) + " " + units[unit]
+}
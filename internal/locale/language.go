@@ -0,0 +1,21 @@
+package locale
+
+import "slices"
+
+// Languages a technician can choose for the result messages, Portuguese is the default
+const (
+	LanguagePortuguese = "pt-BR"
+	LanguageEnglish    = "en"
+)
+
+// Languages lists the supported languages in the order they are offered
+var Languages = []string{LanguagePortuguese, LanguageEnglish}
+
+// NextLanguage returns the language offered after the given one, an unknown language goes back to the first
+func NextLanguage(language string) string {
+	if language == "" {
+		language = LanguagePortuguese
+	}
+	index := slices.Index(Languages, language)
+	return Languages[(index+1)%len(Languages)]
+}
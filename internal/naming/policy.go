@@ -0,0 +1,133 @@
+package naming
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+const (
+	// DefaultTemplate keeps the historical "CTO | PORTA - CLIENTE" layout of the ONU NAME field
+	DefaultTemplate = "{cto} | {porta} - {cliente}"
+
+	// DefaultMaxLength is the longest NAME accepted by the UNM
+	DefaultMaxLength = 64
+)
+
+var (
+	placeholderPattern = regexp.MustCompile(`\{([a-z]+)\}`)
+	spacePattern       = regexp.MustCompile(`\s+`)
+
+	// TL1 uses these characters as field and parameter delimiters
+	reservedReplacer = strings.NewReplacer(
+		",", " ",
+		";", " ",
+		":", " ",
+		"=", " ",
+		"\"", "",
+		"'", "",
+	)
+)
+
+// Fields holds the values available to a naming template
+type Fields struct {
+	Splitter     string
+	SplitterPort string
+	Contract     string
+	Client       string
+	Serial       string
+	Plan         string
+}
+
+// values maps each template placeholder to its field
+func (f Fields) values() map[string]string {
+	return map[string]string{
+		"cto":      f.Splitter,
+		"porta":    f.SplitterPort,
+		"contrato": f.Contract,
+		"cliente":  f.Client,
+		"serial":   f.Serial,
+		"plano":    f.Plan,
+	}
+}
+
+// Policy builds ONU names from a template, transliterating ERP text to the ASCII accepted by TL1
+type Policy struct {
+	template  string
+	maxLength int
+	uppercase bool
+}
+
+// NewPolicy creates a naming policy, empty template or non-positive length fall back to the defaults
+func NewPolicy(template string, maxLength int, uppercase bool) (*Policy, error) {
+	if template == "" {
+		template = DefaultTemplate
+	}
+
+	if maxLength <= 0 {
+		maxLength = DefaultMaxLength
+	}
+
+	known := Fields{}.values()
+	for _, match := range placeholderPattern.FindAllStringSubmatch(template, -1) {
+		if _, exists := known[match[1]]; !exists {
+			return nil, fmt.Errorf("campo desconhecido no modelo de nome: {%s}", match[1])
+		}
+	}
+
+	return &Policy{template: template, maxLength: maxLength, uppercase: uppercase}, nil
+}
+
+// MarshalJSON describes the policy, so it counts in the digest of the configuration
+func (p *Policy) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]any{
+		"template":   p.template,
+		"max_length": p.maxLength,
+		"uppercase":  p.uppercase,
+	})
+}
+
+// Name renders the template with the given fields
+func (p *Policy) Name(fields Fields) string {
+	values := fields.values()
+
+	name := placeholderPattern.ReplaceAllStringFunc(p.template, func(placeholder string) string {
+		return Transliterate(values[placeholder[1:len(placeholder)-1]])
+	})
+
+	name = reservedReplacer.Replace(Transliterate(name))
+	name = strings.TrimSpace(spacePattern.ReplaceAllString(name, " "))
+
+	if p.uppercase {
+		name = strings.ToUpper(name)
+	}
+
+	if len(name) > p.maxLength {
+		name = strings.TrimSpace(name[:p.maxLength])
+	}
+
+	return name
+}
+
+// Transliterate removes accents and drops the characters outside printable ASCII
+func Transliterate(value string) string {
+	t := transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+
+	result, _, err := transform.String(t, value)
+	if err != nil {
+		result = value
+	}
+
+	return strings.Map(func(r rune) rune {
+		if r < ' ' || r > '~' {
+			return -1
+		}
+		return r
+	}, result)
+}
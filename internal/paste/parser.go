@@ -0,0 +1,280 @@
+package paste
+
+import (
+	"provisioning-assistant/internal/domain/dto"
+	"provisioning-assistant/internal/naming"
+	"provisioning-assistant/internal/pon"
+	"provisioning-assistant/internal/validation"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Field identifies a value recognized in pasted text
+type Field string
+
+const (
+	FieldProtocol      Field = "protocol"
+	FieldSerial        Field = "serial"
+	FieldOlt           Field = "olt"
+	FieldSlot          Field = "slot"
+	FieldPort          Field = "port"
+	FieldVlan          Field = "vlan"
+	FieldPPPoEUser     Field = "pppoe_user"
+	FieldPPPoEPassword Field = "pppoe_password"
+	FieldSplitter      Field = "splitter"
+	FieldSplitterPort  Field = "splitter_port"
+
+	// fieldSlotPort is a label carrying both the slot and the port, e.g. "Slot/PON: 1/4"
+	fieldSlotPort Field = "slot_port"
+)
+
+// MinFields is how many recognized values tell a pasted screen apart from a single answer
+const MinFields = 2
+
+var (
+	// Cells of a screen copied from the ERP grid are separated by tabs or pipes
+	cellSeparator = regexp.MustCompile(`[\t|]+`)
+
+	// "Label: value", "Label = value" or "Label - value"
+	labeledValue = regexp.MustCompile(`^\s*([^:=]{1,40}?)\s*(?::|=|\s-\s)\s*(.+?)\s*$`)
+
+	labelCleaner = regexp.MustCompile(`[^a-z0-9/]+`)
+
+	// GPON serials are four vendor letters followed by eight hex digits, e.g. FHTT1A2B3C4D
+	gponSerial = regexp.MustCompile(`\b[A-Z]{4}[0-9A-Fa-f]{8}\b`)
+	digits     = regexp.MustCompile(`\d+`)
+)
+
+// labels maps the normalized labels used by the ERP screens and by technicians to their field
+var labels = map[string]Field{
+	"protocolo":                  FieldProtocol,
+	"n protocolo":                FieldProtocol,
+	"no protocolo":               FieldProtocol,
+	"numero do protocolo":        FieldProtocol,
+	"protocolo de atendimento":   FieldProtocol,
+	"serial":                     FieldSerial,
+	"sn":                         FieldSerial,
+	"serial onu":                 FieldSerial,
+	"serial da onu":              FieldSerial,
+	"serial do equipamento":      FieldSerial,
+	"n de serie":                 FieldSerial,
+	"numero de serie":            FieldSerial,
+	"gpon sn":                    FieldSerial,
+	"olt":                        FieldOlt,
+	"ip olt":                     FieldOlt,
+	"ip da olt":                  FieldOlt,
+	"olt ip":                     FieldOlt,
+	"slot":                       FieldSlot,
+	"slot olt":                   FieldSlot,
+	"slot da olt":                FieldSlot,
+	"pon":                        FieldPort,
+	"porta pon":                  FieldPort,
+	"porta olt":                  FieldPort,
+	"porta da olt":               FieldPort,
+	"slot/porta":                 fieldSlotPort,
+	"slot/pon":                   fieldSlotPort,
+	"slot/porta pon":             fieldSlotPort,
+	"vlan":                       FieldVlan,
+	"vlan cliente":               FieldVlan,
+	"vlan do cliente":            FieldVlan,
+	"pppoe":                      FieldPPPoEUser,
+	"login":                      FieldPPPoEUser,
+	"login pppoe":                FieldPPPoEUser,
+	"usuario":                    FieldPPPoEUser,
+	"usuario pppoe":              FieldPPPoEUser,
+	"senha":                      FieldPPPoEPassword,
+	"senha pppoe":                FieldPPPoEPassword,
+	"cto":                        FieldSplitter,
+	"caixa":                      FieldSplitter,
+	"splitter":                   FieldSplitter,
+	"caixa de atendimento":       FieldSplitter,
+	"porta cto":                  FieldSplitterPort,
+	"porta da cto":               FieldSplitterPort,
+	"porta caixa":                FieldSplitterPort,
+	"porta da caixa":             FieldSplitterPort,
+	"porta splitter":             FieldSplitterPort,
+	"porta do splitter":          FieldSplitterPort,
+	"porta de atendimento":       FieldSplitterPort,
+	"porta da caixa atendimento": FieldSplitterPort,
+}
+
+// Fields holds the values recognized in a pasted text, in the shape of the ERP connection data
+type Fields struct {
+	Protocol string
+	Info     dto.ConnectionInfo
+	Found    []Field
+}
+
+// Count returns how many values were recognized
+func (f *Fields) Count() int {
+	return len(f.Found)
+}
+
+// Has reports whether a value was recognized for the field
+func (f *Fields) Has(field Field) bool {
+	for _, found := range f.Found {
+		if found == field {
+			return true
+		}
+	}
+	return false
+}
+
+// Parse extracts the recognizable fields from free-form text such as a copied ERP screen. Labeled
+// values are read first, then a GPON serial without a label is picked up when none was labeled.
+// Values that do not validate are left out, so they are asked again.
+func Parse(text string) Fields {
+	var fields Fields
+
+	for _, line := range strings.Split(text, "\n") {
+		for _, cell := range cellSeparator.Split(line, -1) {
+			match := labeledValue.FindStringSubmatch(cell)
+			if match == nil {
+				continue
+			}
+
+			field, known := labels[normalizeLabel(match[1])]
+			if !known {
+				continue
+			}
+
+			fields.set(field, match[2])
+		}
+	}
+
+	if !fields.Has(FieldSerial) {
+		if serial := gponSerial.FindString(text); serial != "" {
+			fields.set(FieldSerial, serial)
+		}
+	}
+
+	return fields
+}
+
+// Merge fills the blank fields of the connection data with the recognized values, keeping
+// whatever is already there
+func Merge(dst, src *dto.ConnectionInfo) {
+	for _, pair := range []struct {
+		dst *string
+		src string
+	}{
+		{&dst.ConnectionEquipmentSerialNumber, src.ConnectionEquipmentSerialNumber},
+		{&dst.ConnectionOltIP, src.ConnectionOltIP},
+		{&dst.ConnectionOltSlot, src.ConnectionOltSlot},
+		{&dst.ConnectionOltPort, src.ConnectionOltPort},
+		{&dst.ConnectionClientVlan, src.ConnectionClientVlan},
+		{&dst.ConnectionClientPPPoEUsername, src.ConnectionClientPPPoEUsername},
+		{&dst.ConnectionClientPPPoEPassword, src.ConnectionClientPPPoEPassword},
+		{&dst.ConnectionClientSplitterName, src.ConnectionClientSplitterName},
+		{&dst.ConnectionClientSplitterPort, src.ConnectionClientSplitterPort},
+	} {
+		if *pair.dst == "" {
+			*pair.dst = pair.src
+		}
+	}
+}
+
+// set validates and stores a value, the first occurrence of a field wins
+func (f *Fields) set(field Field, value string) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return
+	}
+
+	if field == fieldSlotPort {
+		location, err := pon.ParseSlotPort(value, "")
+		if err != nil || f.Has(FieldSlot) || f.Has(FieldPort) {
+			return
+		}
+		f.Info.ConnectionOltSlot = strconv.FormatUint(uint64(location.Slot), 10)
+		f.Info.ConnectionOltPort = strconv.FormatUint(uint64(location.Port), 10)
+		f.Found = append(f.Found, FieldSlot, FieldPort)
+		return
+	}
+
+	if f.Has(field) {
+		return
+	}
+
+	switch field {
+	case FieldProtocol:
+		number := digits.FindString(value)
+		if number == "" {
+			return
+		}
+		f.Protocol = number
+
+	case FieldSerial:
+		serial := strings.ToUpper(strings.ReplaceAll(value, ":", ""))
+		if validation.Serial(serial) != nil {
+			return
+		}
+		f.Info.ConnectionEquipmentSerialNumber = serial
+
+	case FieldOlt:
+		if validation.OltIP(value) != nil {
+			return
+		}
+		f.Info.ConnectionOltIP = value
+
+	case FieldSlot:
+		// Some screens show "Slot: 1/4", which carries the port too
+		if location, err := pon.ParseSlotPort(value, ""); err == nil && !f.Has(FieldPort) {
+			f.set(fieldSlotPort, location.String())
+			return
+		}
+		slot, err := pon.ParseSlot(value)
+		if err != nil {
+			return
+		}
+		f.Info.ConnectionOltSlot = strconv.FormatUint(uint64(slot), 10)
+
+	case FieldPort:
+		port, err := pon.ParsePort(value)
+		if err != nil {
+			return
+		}
+		f.Info.ConnectionOltPort = strconv.FormatUint(uint64(port), 10)
+
+	case FieldVlan:
+		if validation.Vlan(value) != nil {
+			return
+		}
+		f.Info.ConnectionClientVlan = value
+
+	case FieldPPPoEUser:
+		if validation.PPPoEUser(value) != nil {
+			return
+		}
+		f.Info.ConnectionClientPPPoEUsername = value
+
+	case FieldPPPoEPassword:
+		if validation.PPPoEPassword(value) != nil {
+			return
+		}
+		f.Info.ConnectionClientPPPoEPassword = value
+
+	case FieldSplitter:
+		f.Info.ConnectionClientSplitterName = value
+
+	case FieldSplitterPort:
+		if strings.TrimLeft(value, "0123456789") != "" {
+			return
+		}
+		f.Info.ConnectionClientSplitterPort = value
+
+	default:
+		return
+	}
+
+	f.Found = append(f.Found, field)
+}
+
+// normalizeLabel lowercases a label and drops accents and punctuation, so "Nº de Série" reads "n de serie"
+func normalizeLabel(label string) string {
+	label = strings.ToLower(naming.Transliterate(label))
+	label = labelCleaner.ReplaceAllString(label, " ")
+	label = strings.ReplaceAll(label, " / ", "/")
+	return strings.Join(strings.Fields(label), " ")
+}
@@ -0,0 +1,152 @@
+package pon
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const (
+	// FiberLossPerKm is the attenuation of the fiber at the 1490 nm downstream wavelength, in dB/km
+	FiberLossPerKm = 0.35
+
+	// ConnectorLoss is the loss of each connector of the path, in dB
+	ConnectorLoss = 0.5
+
+	// Connectors is how many connectors a drop usually has, the OLT patch and the ONU
+	Connectors = 2
+
+	// MaxDistance bounds the fiber length accepted for a GPON drop, in km
+	MaxDistance = 60
+
+	// SpliceTolerance is how far, in dB, a measured reception may fall below the estimate before the path
+	// is suspected of a bad splice or a dirty connector
+	SpliceTolerance = 3.0
+)
+
+// splitterLosses holds the typical insertion loss, in dB, of the balanced splitters by their number of outputs
+var splitterLosses = map[int]float64{
+	2:  3.7,
+	4:  7.3,
+	8:  10.5,
+	16: 13.7,
+	32: 17.1,
+	64: 20.5,
+}
+
+// "1:8", "1x8", "1/8" or just "8"
+var splitterPattern = regexp.MustCompile(`(?i)^(?:1\s*[:x/]\s*)?(\d+)$`)
+
+// Budget is an optical path from the OLT to the ONU: the transmit power of the PON port, the fiber length
+// and the splitters the signal goes through
+type Budget struct {
+	TxPower   float64
+	Distance  float64
+	Splitters []int
+}
+
+// ParseBudget reads the transmit power in dBm, the distance in km and the splitters of the path, decimals
+// written with a dot or a comma, e.g. "3,5 2.4 1:8 1:16"
+func ParseBudget(args []string) (Budget, error) {
+	if len(args) < 2 {
+		return Budget{}, &FieldError{Field: "orçamento óptico", Reason: ErrEmpty}
+	}
+
+	txPower, err := parseDecimal("potência de transmissão", args[0])
+	if err != nil {
+		return Budget{}, err
+	}
+	if txPower < -10 || txPower > 10 {
+		return Budget{}, &FieldError{
+			Field:  "potência de transmissão",
+			Value:  args[0],
+			Reason: fmt.Errorf("%w, esperado entre -10 e 10 dBm", ErrOutOfRange),
+		}
+	}
+
+	distance, err := parseDecimal("distância", args[1])
+	if err != nil {
+		return Budget{}, err
+	}
+	if distance < 0 || distance > MaxDistance {
+		return Budget{}, &FieldError{
+			Field:  "distância",
+			Value:  args[1],
+			Reason: fmt.Errorf("%w, esperado entre 0 e %d km", ErrOutOfRange, MaxDistance),
+		}
+	}
+
+	budget := Budget{TxPower: txPower, Distance: distance}
+	for _, arg := range args[2:] {
+		splitter, err := ParseSplitter(arg)
+		if err != nil {
+			return Budget{}, err
+		}
+		budget.Splitters = append(budget.Splitters, splitter)
+	}
+
+	return budget, nil
+}
+
+// ParseSplitter reads a splitter ratio like "1:8", "1x16" or "32"
+func ParseSplitter(value string) (int, error) {
+	raw := strings.TrimSpace(value)
+	match := splitterPattern.FindStringSubmatch(raw)
+	if match == nil {
+		return 0, &FieldError{Field: "divisor", Value: raw, Reason: ErrNotNumeric}
+	}
+
+	outputs, _ := strconv.Atoi(match[1])
+	if _, known := splitterLosses[outputs]; !known {
+		return 0, &FieldError{Field: "divisor", Value: raw, Reason: fmt.Errorf("%w, use 1:2 a 1:64", ErrOutOfRange)}
+	}
+
+	return outputs, nil
+}
+
+// parseDecimal reads a number written with a dot or a comma
+func parseDecimal(field, value string) (float64, error) {
+	raw := strings.TrimSpace(value)
+	number, err := strconv.ParseFloat(strings.ReplaceAll(raw, ",", "."), 64)
+	if err != nil || math.IsNaN(number) || math.IsInf(number, 0) {
+		return 0, &FieldError{Field: field, Value: raw, Reason: ErrNotNumeric}
+	}
+	return number, nil
+}
+
+// FiberLoss is the attenuation of the fiber length
+func (b Budget) FiberLoss() float64 {
+	return b.Distance * FiberLossPerKm
+}
+
+// SplitterLoss is the insertion loss of every splitter of the path
+func (b Budget) SplitterLoss() float64 {
+	var loss float64
+	for _, splitter := range b.Splitters {
+		loss += splitterLosses[splitter]
+	}
+	return loss
+}
+
+// ConnectorLoss is the loss of the connectors at both ends of the drop
+func (b Budget) ConnectorLoss() float64 {
+	return Connectors * ConnectorLoss
+}
+
+// Loss is the total attenuation expected between the OLT and the ONU
+func (b Budget) Loss() float64 {
+	return b.FiberLoss() + b.SplitterLoss() + b.ConnectorLoss()
+}
+
+// ExpectedRx is the reception power the ONU should read, in dBm
+func (b Budget) ExpectedRx() float64 {
+	return b.TxPower - b.Loss()
+}
+
+// Suspicious reports whether a measured reception falls so far below the estimate that the path likely has
+// a bad splice or a dirty connector
+func (b Budget) Suspicious(measuredRx float64) bool {
+	return b.ExpectedRx()-measuredRx > SpliceTolerance
+}
@@ -0,0 +1,216 @@
+package pon
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const (
+	// MaxSlot and MaxPort bound the values accepted for a PON interface of the OLT chassis
+	MaxSlot = 32
+	MaxPort = 64
+)
+
+var (
+	// ErrEmpty, ErrNotNumeric, ErrOutOfRange and ErrConflict describe why a field was rejected
+	ErrEmpty      = errors.New("valor vazio")
+	ErrNotNumeric = errors.New("valor não numérico")
+	ErrOutOfRange = errors.New("valor fora do intervalo")
+	ErrConflict   = errors.New("valor diverge do informado no outro campo")
+
+	// Prefixes used by the ERP and by technicians, e.g. "S1", "SLOT 01", "P4", "PON-4", "porta 4"
+	slotPrefix = regexp.MustCompile(`(?i)^(slot|sl|s)[\s\-_.:#]*`)
+	portPrefix = regexp.MustCompile(`(?i)^(porta|port|pon|pt|p)[\s\-_.:#]*`)
+
+	// Separators between the fields of a combined value, e.g. "1/4", "S1-P4", "0/1/4"
+	combinedSeparator = regexp.MustCompile(`\s*[/\\|\-]\s*|\s+`)
+
+	// "S1P4" carries both fields without a separator
+	joinedPair = regexp.MustCompile(`(?i)^(s\d+)(p\d+)$`)
+)
+
+// Location is the slot and port of a PON interface
+type Location struct {
+	Slot uint
+	Port uint
+}
+
+// String formats the location the way the UNM shows it
+func (l Location) String() string {
+	return fmt.Sprintf("%d/%d", l.Slot, l.Port)
+}
+
+// FieldError identifies which field failed to parse and why
+type FieldError struct {
+	Field  string
+	Value  string
+	Reason error
+}
+
+func (e *FieldError) Error() string {
+	if e.Value == "" {
+		return fmt.Sprintf("%s: %v", e.Field, e.Reason)
+	}
+	return fmt.Sprintf("%s %q: %v", e.Field, e.Value, e.Reason)
+}
+
+func (e *FieldError) Unwrap() error {
+	return e.Reason
+}
+
+// ParseSlotPort reads the slot and port as stored by the ERP, tolerating prefixes, leading
+// zeros and the whole "slot/port" pair in either field
+func ParseSlotPort(slot, port string) (Location, error) {
+	slot = strings.TrimSpace(slot)
+	port = strings.TrimSpace(port)
+
+	// A combined value in one field, the other one empty or repeating it
+	for _, value := range []string{slot, port} {
+		if !isCombined(value) {
+			continue
+		}
+
+		location, err := parseCombined(value)
+		if err != nil {
+			return Location{}, err
+		}
+
+		if err := checkAgreement(location, slot, port, value); err != nil {
+			return Location{}, err
+		}
+
+		return location, nil
+	}
+
+	parsedSlot, err := ParseSlot(slot)
+	if err != nil {
+		return Location{}, err
+	}
+
+	parsedPort, err := ParsePort(port)
+	if err != nil {
+		return Location{}, err
+	}
+
+	return Location{Slot: parsedSlot, Port: parsedPort}, nil
+}
+
+// ParseSlot reads a single slot value like "1", "01", "S1" or "SLOT 01"
+func ParseSlot(value string) (uint, error) {
+	return parseField("slot", value, slotPrefix, MaxSlot)
+}
+
+// ParsePort reads a single port value like "4", "04", "P4" or "PON 4"
+func ParsePort(value string) (uint, error) {
+	return parseField("porta", value, portPrefix, MaxPort)
+}
+
+// parseField strips the field prefix and leading zeros and checks the value range
+func parseField(field, value string, prefix *regexp.Regexp, max uint) (uint, error) {
+	raw := strings.TrimSpace(value)
+	if raw == "" {
+		return 0, &FieldError{Field: field, Reason: ErrEmpty}
+	}
+
+	digits := prefix.ReplaceAllString(raw, "")
+	if digits == "" || strings.TrimLeft(digits, "0123456789") != "" {
+		return 0, &FieldError{Field: field, Value: raw, Reason: ErrNotNumeric}
+	}
+
+	number, err := strconv.ParseUint(digits, 10, 32)
+	if err != nil || number == 0 || uint(number) > max {
+		return 0, &FieldError{
+			Field:  field,
+			Value:  raw,
+			Reason: fmt.Errorf("%w, esperado entre 1 e %d", ErrOutOfRange, max),
+		}
+	}
+
+	return uint(number), nil
+}
+
+// isCombined reports whether the value holds more than one field, e.g. "1/4" or "S1 P4"
+func isCombined(value string) bool {
+	return len(splitCombined(value)) > 1
+}
+
+// splitCombined breaks a combined value into its fields, also splitting "S1P4"
+func splitCombined(value string) []string {
+	parts := combinedSeparator.Split(strings.TrimSpace(value), -1)
+
+	fields := make([]string, 0, len(parts))
+	for _, part := range parts {
+		if part == "" {
+			continue
+		}
+
+		if location := joinedPair.FindStringSubmatch(part); location != nil {
+			fields = append(fields, location[1], location[2])
+			continue
+		}
+
+		fields = append(fields, part)
+	}
+
+	// A prefix split from its number, e.g. "SLOT 1", is still a single field
+	if len(fields) == 2 && !strings.ContainsAny(fields[0], "0123456789") {
+		return []string{fields[0] + fields[1]}
+	}
+
+	return fields
+}
+
+// parseCombined reads "slot/port" or "frame/slot/port", the frame being ignored
+func parseCombined(value string) (Location, error) {
+	fields := splitCombined(value)
+	if len(fields) > 3 {
+		return Location{}, &FieldError{Field: "slot/porta", Value: value, Reason: ErrNotNumeric}
+	}
+
+	fields = fields[len(fields)-2:]
+
+	slot, err := ParseSlot(fields[0])
+	if err != nil {
+		return Location{}, err
+	}
+
+	port, err := ParsePort(fields[1])
+	if err != nil {
+		return Location{}, err
+	}
+
+	return Location{Slot: slot, Port: port}, nil
+}
+
+// checkAgreement makes sure the field not holding the combined value does not contradict it
+func checkAgreement(location Location, slot, port, combined string) error {
+	for _, other := range []struct {
+		field string
+		value string
+		parse func(string) (uint, error)
+		want  uint
+	}{
+		{"slot", slot, ParseSlot, location.Slot},
+		{"porta", port, ParsePort, location.Port},
+	} {
+		if other.value == "" || other.value == combined {
+			continue
+		}
+
+		if isCombined(other.value) {
+			if parsed, err := parseCombined(other.value); err != nil || parsed != location {
+				return &FieldError{Field: other.field, Value: other.value, Reason: ErrConflict}
+			}
+			continue
+		}
+
+		if parsed, err := other.parse(other.value); err != nil || parsed != other.want {
+			return &FieldError{Field: other.field, Value: other.value, Reason: ErrConflict}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,112 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"provisioning-assistant/internal/domain"
+	"provisioning-assistant/internal/ids"
+	"slices"
+	"strings"
+	"sync"
+)
+
+var ErrAuditNotFound = errors.New("registro de auditoria não encontrado")
+
+type AuditRepository struct {
+	records map[string]*domain.AuditRecord
+	order   []string
+	ids     *ids.Generator
+	mu      sync.RWMutex
+}
+
+// NewAuditRepository creates a new in-memory audit repository instance issuing ULIDs
+func NewAuditRepository(generator *ids.Generator) *AuditRepository {
+	return &AuditRepository{
+		records: make(map[string]*domain.AuditRecord),
+		ids:     generator,
+	}
+}
+
+// Save inserts or updates an audit record, assigning an ID when missing
+func (rpt *AuditRepository) Save(ctx context.Context, record *domain.AuditRecord) error {
+	if record == nil {
+		return errors.New("registro de auditoria não pode ser nulo")
+	}
+
+	rpt.mu.Lock()
+	defer rpt.mu.Unlock()
+
+	if record.ID == "" {
+		record.ID = rpt.ids.New()
+	}
+
+	_, exists := rpt.records[record.ID]
+	rpt.records[record.ID] = cloneAuditRecord(record)
+
+	// The order is kept by creation, restored and archived records fall back in place
+	if !exists {
+		position, _ := slices.BinarySearchFunc(rpt.order, record.ID, rpt.compare)
+		rpt.order = slices.Insert(rpt.order, position, record.ID)
+	}
+	return nil
+}
+
+// FindByID retrieves an audit record by its identifier
+func (rpt *AuditRepository) FindByID(ctx context.Context, id string) (*domain.AuditRecord, error) {
+	rpt.mu.RLock()
+	defer rpt.mu.RUnlock()
+
+	record, exists := rpt.records[ids.Normalize(id)]
+	if !exists {
+		return nil, ErrAuditNotFound
+	}
+
+	return cloneAuditRecord(record), nil
+}
+
+// List returns all audit records, oldest first
+func (rpt *AuditRepository) List(ctx context.Context) ([]*domain.AuditRecord, error) {
+	rpt.mu.RLock()
+	defer rpt.mu.RUnlock()
+
+	records := make([]*domain.AuditRecord, 0, len(rpt.order))
+	for _, id := range rpt.order {
+		records = append(records, cloneAuditRecord(rpt.records[id]))
+	}
+
+	return records, nil
+}
+
+// Delete removes an audit record, missing records are ignored
+func (rpt *AuditRepository) Delete(ctx context.Context, id string) error {
+	rpt.mu.Lock()
+	defer rpt.mu.Unlock()
+
+	if _, exists := rpt.records[id]; !exists {
+		return nil
+	}
+
+	delete(rpt.records, id)
+	rpt.order = slices.DeleteFunc(rpt.order, func(orderedID string) bool {
+		return orderedID == id
+	})
+
+	return nil
+}
+
+// compare orders two stored records by creation time, then by ID
+func (rpt *AuditRepository) compare(a, b string) int {
+	if byTime := rpt.records[a].CreatedAt.Compare(rpt.records[b].CreatedAt); byTime != 0 {
+		return byTime
+	}
+	return strings.Compare(a, b)
+}
+
+// cloneAuditRecord copies a record so callers never share internal state
+func cloneAuditRecord(record *domain.AuditRecord) *domain.AuditRecord {
+	clone := *record
+	clone.Attachments = slices.Clone(record.Attachments)
+	clone.Commands = slices.Clone(record.Commands)
+	clone.Steps = slices.Clone(record.Steps)
+	return &clone
+}
@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"provisioning-assistant/internal/domain"
+	"sync"
+)
+
+var ErrBindingNotFound = errors.New("vínculo de usuário não encontrado")
+
+type BindingRepository struct {
+	bindings map[int64]*domain.Binding
+	mu       sync.RWMutex
+}
+
+// NewBindingRepository creates a new in-memory user binding repository
+func NewBindingRepository() *BindingRepository {
+	return &BindingRepository{
+		bindings: make(map[int64]*domain.Binding),
+	}
+}
+
+// Save inserts or updates a user binding
+func (rpt *BindingRepository) Save(ctx context.Context, binding *domain.Binding) error {
+	if binding == nil || binding.UserID == 0 {
+		return errors.New("vínculo de usuário inválido")
+	}
+
+	rpt.mu.Lock()
+	defer rpt.mu.Unlock()
+
+	clone := *binding
+	rpt.bindings[binding.UserID] = &clone
+	return nil
+}
+
+// FindByUserID retrieves the binding of a Telegram user
+func (rpt *BindingRepository) FindByUserID(ctx context.Context, userID int64) (*domain.Binding, error) {
+	rpt.mu.RLock()
+	defer rpt.mu.RUnlock()
+
+	binding, exists := rpt.bindings[userID]
+	if !exists {
+		return nil, ErrBindingNotFound
+	}
+
+	clone := *binding
+	return &clone, nil
+}
+
+// List returns all user bindings
+func (rpt *BindingRepository) List(ctx context.Context) ([]*domain.Binding, error) {
+	rpt.mu.RLock()
+	defer rpt.mu.RUnlock()
+
+	bindings := make([]*domain.Binding, 0, len(rpt.bindings))
+	for _, binding := range rpt.bindings {
+		clone := *binding
+		bindings = append(bindings, &clone)
+	}
+
+	return bindings, nil
+}
@@ -3,14 +3,19 @@ package repository
 import (
 	"context"
 	"errors"
+	"fmt"
 	"provisioning-assistant/internal/database"
 	"provisioning-assistant/internal/domain/dto"
+	"time"
 )
 
+// getConnInfoQuery prefers the authentication with a PPPoE login, the others are extra WAN services
 const getConnInfoQuery = `
+SELECT conn.* FROM (
 SELECT DISTINCT
        a.id AS assignment_erp_id,
        a.title AS assignment_title,
+       COALESCE(ist.title, '') AS assignment_status,
        ai2.ip AS connection_olt_ip,
        as2.port_olt AS connection_olt_port,
        as2.slot_olt AS connection_olt_slot,
@@ -21,7 +26,12 @@ SELECT DISTINCT
        ac."user" AS connection_client_pppoe_username,
        ac."password" AS connection_client_pppoe_password,
        ac.vlan AS connection_client_vlan,
+       ac.id AS authentication_id,
+       c.id AS contract_id,
        c.description AS contract_description,
+       COALESCE(sp.id, 0) AS contract_plan_id,
+       COALESCE(sp.title, '') AS contract_plan_name,
+       COALESCE(c.vip, FALSE) AS contract_vip,
        p.name AS client_name
   FROM assignments AS a
  INNER JOIN assignment_incidents AS ai ON a.id = ai.assignment_id
@@ -33,7 +43,117 @@ SELECT DISTINCT
   LEFT JOIN authentication_ips AS ai3 ON ac.ip_authentication_id = ai3.id 
   LEFT JOIN authentication_splitter_ports AS asp ON ac.id = asp.authentication_contract_id
   LEFT JOIN authentication_splitters AS as2 ON asp.authentication_splitter_id = as2.id
- WHERE ai.protocol = $1;`
+  LEFT JOIN service_products AS sp ON ac.service_product_id = sp.id
+  LEFT JOIN incident_status AS ist ON ai.incident_status_id = ist.id
+ WHERE ai.protocol = $1
+) AS conn
+ ORDER BY COALESCE(conn.connection_client_pppoe_username, '') = '', conn.authentication_id;`
+
+const getConnInfoByPPPoEQuery = `
+SELECT 0::bigint AS assignment_erp_id,
+       '' AS assignment_title,
+       '' AS assignment_status,
+       ai2.ip AS connection_olt_ip,
+       as2.port_olt AS connection_olt_port,
+       as2.slot_olt AS connection_olt_slot,
+       ac.equipment_serial_number AS connection_equipment_serial_number,
+       ai3.ip AS connection_client_ip,
+       as2.title AS connection_client_splitter_name,
+       asp.port AS connection_client_splitter_port,
+       ac."user" AS connection_client_pppoe_username,
+       ac."password" AS connection_client_pppoe_password,
+       ac.vlan AS connection_client_vlan,
+       ac.id AS authentication_id,
+       c.id AS contract_id,
+       c.description AS contract_description,
+       COALESCE(sp.id, 0) AS contract_plan_id,
+       COALESCE(sp.title, '') AS contract_plan_name,
+       COALESCE(c.vip, FALSE) AS contract_vip,
+       p.name AS client_name
+  FROM authentication_contracts AS ac
+ INNER JOIN contracts AS c ON ac.contract_id = c.id
+ INNER JOIN people AS p ON p.id = c.client_id
+  LEFT JOIN authentication_access_points AS acp ON ac.authentication_access_point_id = acp.id
+  LEFT JOIN authentication_ips AS ai2 ON acp.authentication_ip_id = ai2.id
+  LEFT JOIN authentication_ips AS ai3 ON ac.ip_authentication_id = ai3.id
+  LEFT JOIN authentication_splitter_ports AS asp ON ac.id = asp.authentication_contract_id
+  LEFT JOIN authentication_splitters AS as2 ON asp.authentication_splitter_id = as2.id
+  LEFT JOIN service_products AS sp ON ac.service_product_id = sp.id
+ WHERE LOWER(ac."user") = LOWER($1)
+ ORDER BY ac.id DESC
+ LIMIT 1;`
+
+// getWanServicesQuery lists the other authentications of the contract, each one a WAN service of its own
+const getWanServicesQuery = `
+SELECT COALESCE(sp.title, '') AS service_name,
+       ac.vlan AS vlan,
+       COALESCE(ac."user", '') AS pppoe_username,
+       COALESCE(ac."password", '') AS pppoe_password
+  FROM authentication_contracts AS ac
+  LEFT JOIN service_products AS sp ON ac.service_product_id = sp.id
+ WHERE ac.contract_id = $1
+   AND ac.id <> $2
+   AND COALESCE(ac.vlan, '') <> ''
+ ORDER BY ac.id;`
+
+// getContactInfoQuery reads the customer phone and the address where the contract is installed
+const getContactInfoQuery = `
+SELECT COALESCE(NULLIF(p.cell_phone_1, ''), NULLIF(p.phone, ''), '') AS phone,
+       COALESCE(pa.street, '') AS street,
+       COALESCE(pa.number::text, '') AS number,
+       COALESCE(pa.address_complement, '') AS complement,
+       COALESCE(pa.neighborhood, '') AS neighborhood,
+       COALESCE(pa.city, '') AS city
+  FROM contracts AS c
+ INNER JOIN people AS p ON p.id = c.client_id
+  LEFT JOIN people_addresses AS pa ON pa.id = c.people_address_id
+ WHERE c.id = $1;`
+
+// listWorkOrdersQuery reads the protocols handed to a responsible technician since the given moment, the
+// technician identified by the digits of their CPF
+const listWorkOrdersQuery = `
+SELECT a.id AS assignment_erp_id,
+       ai.protocol::text AS protocol,
+       a.title AS assignment_title,
+       regexp_replace(COALESCE(r.tx_id, ''), '[^0-9]', '', 'g') AS technician_tax_id,
+       p.name AS client_name,
+       a.modified AS assigned_at
+  FROM assignments AS a
+ INNER JOIN assignment_incidents AS ai ON a.id = ai.assignment_id
+ INNER JOIN people AS p ON p.id = ai.client_id
+ INNER JOIN people AS r ON r.id = a.responsible_id
+ WHERE a.modified >= $1
+ ORDER BY a.modified;`
+
+// listCancelledContractsQuery reads the contracts cancelled since the given moment with the ONU and PON of
+// their authentications, one row per authentication carrying an equipment. An equipment bound to a contract
+// still active is left out, it was reused whoever provisioned it.
+const listCancelledContractsQuery = `
+SELECT c.id AS contract_id,
+       c.description AS contract_description,
+       p.name AS client_name,
+       c.cancellation_date AS cancelled_at,
+       COALESCE(ai2.ip::text, '') AS connection_olt_ip,
+       COALESCE(as2.slot_olt::text, '') AS connection_olt_slot,
+       COALESCE(as2.port_olt::text, '') AS connection_olt_port,
+       ac.equipment_serial_number AS connection_equipment_serial_number
+  FROM contracts AS c
+ INNER JOIN people AS p ON p.id = c.client_id
+ INNER JOIN authentication_contracts AS ac ON c.id = ac.contract_id
+  LEFT JOIN authentication_access_points AS acp ON ac.authentication_access_point_id = acp.id
+  LEFT JOIN authentication_ips AS ai2 ON acp.authentication_ip_id = ai2.id
+  LEFT JOIN authentication_splitter_ports AS asp ON ac.id = asp.authentication_contract_id
+  LEFT JOIN authentication_splitters AS as2 ON asp.authentication_splitter_id = as2.id
+ WHERE c.cancellation_date >= $1
+   AND COALESCE(ac.equipment_serial_number, '') <> ''
+   AND NOT EXISTS (
+       SELECT 1
+         FROM authentication_contracts AS other
+        INNER JOIN contracts AS oc ON oc.id = other.contract_id
+        WHERE UPPER(TRIM(other.equipment_serial_number)) = UPPER(TRIM(ac.equipment_serial_number))
+          AND other.contract_id <> c.id
+          AND oc.cancellation_date IS NULL)
+ ORDER BY c.cancellation_date, c.id, ac.id;`
 
 type ErpRepository struct {
 	db database.DB
@@ -50,6 +170,11 @@ func NewErpRepository(db database.DB) *ErpRepository {
 	}
 }
 
+// Ping checks the ERP database answers
+func (rpt *ErpRepository) Ping(ctx context.Context) error {
+	return rpt.db.Ping(ctx)
+}
+
 // GetConnInfoByProtocol retrieves connection information by protocol number
 func (rpt *ErpRepository) GetConnInfoByProtocol(ctx context.Context, protocol string) (*dto.ConnectionInfo, error) {
 	if protocol == "" {
@@ -61,5 +186,72 @@ func (rpt *ErpRepository) GetConnInfoByProtocol(ctx context.Context, protocol st
 		return nil, err
 	}
 
+	if err := rpt.loadWanServices(ctx, connInfo); err != nil {
+		return nil, err
+	}
+
+	if err := rpt.loadContactInfo(ctx, connInfo); err != nil {
+		return nil, err
+	}
+
+	return connInfo, nil
+}
+
+// GetConnInfoByPPPoEUsername retrieves connection information by the customer PPPoE username
+func (rpt *ErpRepository) GetConnInfoByPPPoEUsername(ctx context.Context, username string) (*dto.ConnectionInfo, error) {
+	if username == "" {
+		return nil, errors.New("usuário PPPoE inválido")
+	}
+
+	connInfo := &dto.ConnectionInfo{}
+	if err := rpt.db.QueryRowStruct(ctx, connInfo, getConnInfoByPPPoEQuery, username); err != nil {
+		return nil, err
+	}
+
+	if err := rpt.loadWanServices(ctx, connInfo); err != nil {
+		return nil, err
+	}
+
+	if err := rpt.loadContactInfo(ctx, connInfo); err != nil {
+		return nil, err
+	}
+
 	return connInfo, nil
 }
+
+// ListWorkOrders retrieves the protocols assigned to technicians since the given moment
+func (rpt *ErpRepository) ListWorkOrders(ctx context.Context, since time.Time) ([]*dto.WorkOrder, error) {
+	var workOrders []*dto.WorkOrder
+	if err := rpt.db.QueryStruct(ctx, &workOrders, listWorkOrdersQuery, since); err != nil {
+		return nil, fmt.Errorf("falha ao consultar ordens de serviço atribuídas: %w", err)
+	}
+	return workOrders, nil
+}
+
+// ListCancelledContracts retrieves the contracts cancelled since the given moment with their ONUs
+func (rpt *ErpRepository) ListCancelledContracts(ctx context.Context, since time.Time) ([]*dto.CancelledContract, error) {
+	var contracts []*dto.CancelledContract
+	if err := rpt.db.QueryStruct(ctx, &contracts, listCancelledContractsQuery, since); err != nil {
+		return nil, fmt.Errorf("falha ao consultar contratos cancelados: %w", err)
+	}
+	return contracts, nil
+}
+
+// loadWanServices fills the WAN services the contract has besides the authentication found
+func (rpt *ErpRepository) loadWanServices(ctx context.Context, connInfo *dto.ConnectionInfo) error {
+	if err := rpt.db.QueryStruct(ctx, &connInfo.ExtraWanServices, getWanServicesQuery, connInfo.ContractID, connInfo.AuthenticationID); err != nil {
+		return fmt.Errorf("falha ao consultar serviços WAN do contrato: %w", err)
+	}
+	return nil
+}
+
+// loadContactInfo fills the customer phone and installation address of the contract
+func (rpt *ErpRepository) loadContactInfo(ctx context.Context, connInfo *dto.ConnectionInfo) error {
+	contact := &dto.ContactInfo{}
+	if err := rpt.db.QueryRowStruct(ctx, contact, getContactInfoQuery, connInfo.ContractID); err != nil {
+		return fmt.Errorf("falha ao consultar contato do cliente: %w", err)
+	}
+
+	connInfo.Contact = contact
+	return nil
+}
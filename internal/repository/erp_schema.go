@@ -0,0 +1,148 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"provisioning-assistant/internal/domain"
+	"slices"
+	"strings"
+)
+
+const getErpSchemaQuery = `
+SELECT table_name, column_name, data_type
+  FROM information_schema.columns
+ WHERE table_schema = ANY(current_schemas(false))
+   AND table_name = ANY($1);`
+
+// Column types accepted by the scan of the connection queries, as named by information_schema
+var (
+	erpIntegerTypes = []string{"smallint", "integer", "bigint"}
+	erpTextTypes    = []string{"text", "character varying", "character"}
+	erpAddressTypes = []string{"text", "character varying", "inet"}
+	erpBooleanTypes = []string{"boolean"}
+)
+
+// erpSchema lists every column referenced by the connection queries with the types they may have
+var erpSchema = map[string]map[string][]string{
+	"assignments": {
+		"id":    erpIntegerTypes,
+		"title": erpTextTypes,
+	},
+	"assignment_incidents": {
+		"assignment_id":      erpIntegerTypes,
+		"client_id":          erpIntegerTypes,
+		"incident_status_id": erpIntegerTypes,
+		"protocol":           slices.Concat(erpTextTypes, erpIntegerTypes),
+	},
+	"contracts": {
+		"id":                erpIntegerTypes,
+		"client_id":         erpIntegerTypes,
+		"people_address_id": erpIntegerTypes,
+		"description":       erpTextTypes,
+		"vip":               erpBooleanTypes,
+	},
+	"people": {
+		"id":           erpIntegerTypes,
+		"name":         erpTextTypes,
+		"phone":        erpTextTypes,
+		"cell_phone_1": erpTextTypes,
+	},
+	"people_addresses": {
+		"id":                 erpIntegerTypes,
+		"street":             erpTextTypes,
+		"number":             slices.Concat(erpTextTypes, erpIntegerTypes),
+		"address_complement": erpTextTypes,
+		"neighborhood":       erpTextTypes,
+		"city":               erpTextTypes,
+	},
+	"authentication_contracts": {
+		"id":                             erpIntegerTypes,
+		"contract_id":                    erpIntegerTypes,
+		"authentication_access_point_id": erpIntegerTypes,
+		"ip_authentication_id":           erpIntegerTypes,
+		"service_product_id":             erpIntegerTypes,
+		"equipment_serial_number":        erpTextTypes,
+		"user":                           erpTextTypes,
+		"password":                       erpTextTypes,
+		"vlan":                           erpTextTypes,
+	},
+	"authentication_access_points": {
+		"id":                   erpIntegerTypes,
+		"authentication_ip_id": erpIntegerTypes,
+	},
+	"authentication_ips": {
+		"id": erpIntegerTypes,
+		"ip": erpAddressTypes,
+	},
+	"authentication_splitter_ports": {
+		"authentication_contract_id": erpIntegerTypes,
+		"authentication_splitter_id": erpIntegerTypes,
+		"port":                       erpTextTypes,
+	},
+	"authentication_splitters": {
+		"id":       erpIntegerTypes,
+		"title":    erpTextTypes,
+		"port_olt": erpTextTypes,
+		"slot_olt": erpTextTypes,
+	},
+	"service_products": {
+		"id":    erpIntegerTypes,
+		"title": erpTextTypes,
+	},
+	"incident_status": {
+		"id":    erpIntegerTypes,
+		"title": erpTextTypes,
+	},
+}
+
+type erpSchemaColumn struct {
+	Table  string `db:"table_name"`
+	Column string `db:"column_name"`
+	Type   string `db:"data_type"`
+}
+
+// CheckSchema compares the ERP columns against the ones the connection queries read,
+// reporting every missing table, missing column and unexpected type at once
+func (rpt *ErpRepository) CheckSchema(ctx context.Context) error {
+	tables := slices.Sorted(maps.Keys(erpSchema))
+
+	var columns []erpSchemaColumn
+	if err := rpt.db.QueryStruct(ctx, &columns, getErpSchemaQuery, tables); err != nil {
+		return fmt.Errorf("falha ao consultar esquema do ERP: %w", err)
+	}
+
+	found := make(map[string]map[string]string, len(tables))
+	for _, column := range columns {
+		if found[column.Table] == nil {
+			found[column.Table] = make(map[string]string)
+		}
+		found[column.Table][column.Column] = column.Type
+	}
+
+	var problems []string
+	for _, table := range tables {
+		existing, exists := found[table]
+		if !exists {
+			problems = append(problems, fmt.Sprintf("tabela %s ausente", table))
+			continue
+		}
+
+		for _, column := range slices.Sorted(maps.Keys(erpSchema[table])) {
+			accepted := erpSchema[table][column]
+			kind, exists := existing[column]
+			switch {
+			case !exists:
+				problems = append(problems, fmt.Sprintf("coluna %s.%s ausente", table, column))
+			case !slices.Contains(accepted, kind):
+				problems = append(problems, fmt.Sprintf("coluna %s.%s é %s, esperado %s", table, column, kind, strings.Join(accepted, " ou ")))
+			}
+		}
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("%w: %s", domain.ErrErpSchemaDrift, strings.Join(problems, "; "))
+	}
+
+	return nil
+}
@@ -0,0 +1,269 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"provisioning-assistant/internal/database"
+	"provisioning-assistant/internal/domain"
+	"provisioning-assistant/internal/ids"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+const (
+	bindingsBucket = "bindings"
+	auditsBucket   = "audits"
+	tokensBucket   = "tokens"
+	statePrefix    = "state:"
+)
+
+type KVBindingRepository struct {
+	store database.KV
+}
+
+// NewKVBindingRepository creates a user binding repository persisted in a key-value store
+func NewKVBindingRepository(store database.KV) *KVBindingRepository {
+	return &KVBindingRepository{store: store}
+}
+
+// Save inserts or updates a user binding
+func (rpt *KVBindingRepository) Save(ctx context.Context, binding *domain.Binding) error {
+	if binding == nil || binding.UserID == 0 {
+		return errors.New("vínculo de usuário inválido")
+	}
+
+	return rpt.store.Put(ctx, bindingsBucket, strconv.FormatInt(binding.UserID, 10), binding)
+}
+
+// FindByUserID retrieves the binding of a Telegram user
+func (rpt *KVBindingRepository) FindByUserID(ctx context.Context, userID int64) (*domain.Binding, error) {
+	var binding domain.Binding
+	if err := rpt.store.Get(ctx, bindingsBucket, strconv.FormatInt(userID, 10), &binding); err != nil {
+		if errors.Is(err, database.ErrKeyNotFound) {
+			return nil, ErrBindingNotFound
+		}
+		return nil, err
+	}
+
+	return &binding, nil
+}
+
+// List returns all user bindings
+func (rpt *KVBindingRepository) List(ctx context.Context) ([]*domain.Binding, error) {
+	var bindings []*domain.Binding
+
+	err := rpt.store.ForEach(ctx, bindingsBucket, func(key string, data []byte) error {
+		var binding domain.Binding
+		if err := json.Unmarshal(data, &binding); err != nil {
+			return err
+		}
+		bindings = append(bindings, &binding)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return bindings, nil
+}
+
+type KVStateRepository struct {
+	store database.KV
+}
+
+// NewKVStateRepository creates a named state repository persisted in a key-value store
+func NewKVStateRepository(store database.KV) *KVStateRepository {
+	return &KVStateRepository{store: store}
+}
+
+// Get retrieves the value of a key in the namespace
+func (rpt *KVStateRepository) Get(ctx context.Context, namespace, key string) (string, error) {
+	var value string
+	if err := rpt.store.Get(ctx, statePrefix+namespace, key, &value); err != nil {
+		if errors.Is(err, database.ErrKeyNotFound) {
+			return "", ErrStateNotFound
+		}
+		return "", err
+	}
+
+	return value, nil
+}
+
+// Set stores the value of a key in the namespace
+func (rpt *KVStateRepository) Set(ctx context.Context, namespace, key, value string) error {
+	return rpt.store.Put(ctx, statePrefix+namespace, key, value)
+}
+
+// List returns all values of the namespace
+func (rpt *KVStateRepository) List(ctx context.Context, namespace string) (map[string]string, error) {
+	values := make(map[string]string)
+
+	err := rpt.store.ForEach(ctx, statePrefix+namespace, func(key string, data []byte) error {
+		var value string
+		if err := json.Unmarshal(data, &value); err != nil {
+			return err
+		}
+		values[key] = value
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return values, nil
+}
+
+// Delete removes a key from the namespace, missing keys are ignored
+func (rpt *KVStateRepository) Delete(ctx context.Context, namespace, key string) error {
+	return rpt.store.Delete(ctx, statePrefix+namespace, key)
+}
+
+type KVAuditRepository struct {
+	store database.KV
+	ids   *ids.Generator
+}
+
+// NewKVAuditRepository creates an audit repository persisted in a key-value store, issuing ULIDs
+func NewKVAuditRepository(store database.KV, generator *ids.Generator) *KVAuditRepository {
+	return &KVAuditRepository{store: store, ids: generator}
+}
+
+// Save inserts or updates an audit record, assigning an ID when missing
+func (rpt *KVAuditRepository) Save(ctx context.Context, record *domain.AuditRecord) error {
+	if record == nil {
+		return errors.New("registro de auditoria não pode ser nulo")
+	}
+
+	if record.ID == "" {
+		record.ID = rpt.ids.New()
+	}
+
+	return rpt.store.Put(ctx, auditsBucket, record.ID, record)
+}
+
+// FindByID retrieves an audit record by its identifier
+func (rpt *KVAuditRepository) FindByID(ctx context.Context, id string) (*domain.AuditRecord, error) {
+	var record domain.AuditRecord
+	if err := rpt.store.Get(ctx, auditsBucket, ids.Normalize(id), &record); err != nil {
+		if errors.Is(err, database.ErrKeyNotFound) {
+			return nil, ErrAuditNotFound
+		}
+		return nil, err
+	}
+
+	return &record, nil
+}
+
+// List returns all audit records, oldest first
+func (rpt *KVAuditRepository) List(ctx context.Context) ([]*domain.AuditRecord, error) {
+	var records []*domain.AuditRecord
+
+	err := rpt.store.ForEach(ctx, auditsBucket, func(key string, data []byte) error {
+		var record domain.AuditRecord
+		if err := json.Unmarshal(data, &record); err != nil {
+			return err
+		}
+		records = append(records, &record)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// The keys follow the IDs, restored records keep the time they were created
+	slices.SortStableFunc(records, func(a, b *domain.AuditRecord) int {
+		if byTime := a.CreatedAt.Compare(b.CreatedAt); byTime != 0 {
+			return byTime
+		}
+		return strings.Compare(a.ID, b.ID)
+	})
+
+	return records, nil
+}
+
+// Delete removes an audit record, missing records are ignored
+func (rpt *KVAuditRepository) Delete(ctx context.Context, id string) error {
+	return rpt.store.Delete(ctx, auditsBucket, id)
+}
+
+type KVTokenRepository struct {
+	store database.KV
+}
+
+// NewKVTokenRepository creates a service-account token repository persisted in a key-value store
+func NewKVTokenRepository(store database.KV) *KVTokenRepository {
+	return &KVTokenRepository{store: store}
+}
+
+// Save inserts or updates a service-account token, the hash of its secret kept alongside like in a backup
+func (rpt *KVTokenRepository) Save(ctx context.Context, token *domain.ServiceAccountToken) error {
+	if token == nil || token.ID == "" {
+		return errors.New("token inválido")
+	}
+
+	return rpt.store.Put(ctx, tokensBucket, token.ID, &domain.BackupToken{ServiceAccountToken: token, TokenHash: token.TokenHash})
+}
+
+// FindByID retrieves a token by its identifier
+func (rpt *KVTokenRepository) FindByID(ctx context.Context, id string) (*domain.ServiceAccountToken, error) {
+	var stored domain.BackupToken
+	if err := rpt.store.Get(ctx, tokensBucket, id, &stored); err != nil {
+		if errors.Is(err, database.ErrKeyNotFound) {
+			return nil, ErrTokenNotFound
+		}
+		return nil, err
+	}
+
+	return storedToken(&stored), nil
+}
+
+// FindByHash retrieves a token by the hash of its secret
+func (rpt *KVTokenRepository) FindByHash(ctx context.Context, hash string) (*domain.ServiceAccountToken, error) {
+	tokens, err := rpt.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, token := range tokens {
+		if token.TokenHash == hash {
+			return token, nil
+		}
+	}
+
+	return nil, ErrTokenNotFound
+}
+
+// List returns all tokens in creation order
+func (rpt *KVTokenRepository) List(ctx context.Context) ([]*domain.ServiceAccountToken, error) {
+	var tokens []*domain.ServiceAccountToken
+
+	err := rpt.store.ForEach(ctx, tokensBucket, func(key string, data []byte) error {
+		var stored domain.BackupToken
+		if err := json.Unmarshal(data, &stored); err != nil {
+			return err
+		}
+		tokens = append(tokens, storedToken(&stored))
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	slices.SortStableFunc(tokens, func(a, b *domain.ServiceAccountToken) int {
+		return a.CreatedAt.Compare(b.CreatedAt)
+	})
+
+	return tokens, nil
+}
+
+// storedToken returns the token of a stored record with the hash of its secret
+func storedToken(stored *domain.BackupToken) *domain.ServiceAccountToken {
+	token := stored.ServiceAccountToken
+	if token == nil {
+		token = &domain.ServiceAccountToken{}
+	}
+	token.TokenHash = stored.TokenHash
+	return token
+}
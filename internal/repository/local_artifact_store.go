@@ -0,0 +1,217 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"provisioning-assistant/internal/domain"
+	"slices"
+	"strings"
+)
+
+const (
+	artifactMetaExtension = ".meta"
+	artifactTempExtension = ".tmp"
+)
+
+var ErrInvalidArtifactKey = errors.New("chave de artefato inválida")
+
+type LocalArtifactStore struct {
+	root string
+}
+
+// NewLocalArtifactStore creates an artifact store keeping each file under the root directory,
+// with its metadata in a sidecar file next to it
+func NewLocalArtifactStore(root string) *LocalArtifactStore {
+	return &LocalArtifactStore{root: root}
+}
+
+// Put writes the artifact content atomically, replacing any artifact with the same key
+func (s *LocalArtifactStore) Put(ctx context.Context, artifact *domain.Artifact, content io.Reader) error {
+	file, err := s.path(artifact.Key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(file), 0o700); err != nil {
+		return fmt.Errorf("falha ao criar diretório de artefatos: %w", err)
+	}
+
+	size, err := writeAtomic(file, func(w io.Writer) (int64, error) {
+		return io.Copy(w, content)
+	})
+	if err != nil {
+		return fmt.Errorf("falha ao gravar artefato %s: %w", artifact.Key, err)
+	}
+	artifact.Size = size
+
+	if _, err := writeAtomic(file+artifactMetaExtension, func(w io.Writer) (int64, error) {
+		return 0, json.NewEncoder(w).Encode(artifact)
+	}); err != nil {
+		return fmt.Errorf("falha ao gravar metadados do artefato %s: %w", artifact.Key, err)
+	}
+
+	return nil
+}
+
+// Open returns a reader for the artifact content and its metadata
+func (s *LocalArtifactStore) Open(ctx context.Context, key string) (io.ReadCloser, *domain.Artifact, error) {
+	file, err := s.path(key)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	artifact, err := s.stat(key, file)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	reader, err := os.Open(file)
+	if err != nil {
+		return nil, nil, fmt.Errorf("falha ao abrir artefato %s: %w", key, err)
+	}
+
+	return reader, artifact, nil
+}
+
+// List returns the artifacts whose key starts with the prefix, ordered by key
+func (s *LocalArtifactStore) List(ctx context.Context, prefix string) ([]*domain.Artifact, error) {
+	var artifacts []*domain.Artifact
+
+	err := filepath.WalkDir(s.root, func(file string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if entry.IsDir() || isArtifactSidecar(file) {
+			return nil
+		}
+
+		relative, err := filepath.Rel(s.root, file)
+		if err != nil {
+			return err
+		}
+
+		key := filepath.ToSlash(relative)
+		if !strings.HasPrefix(key, prefix) {
+			return nil
+		}
+
+		artifact, err := s.stat(key, file)
+		if err != nil {
+			return err
+		}
+		artifacts = append(artifacts, artifact)
+
+		return ctx.Err()
+	})
+	if err != nil {
+		return nil, fmt.Errorf("falha ao listar artefatos: %w", err)
+	}
+
+	slices.SortFunc(artifacts, func(a, b *domain.Artifact) int {
+		return strings.Compare(a.Key, b.Key)
+	})
+
+	return artifacts, nil
+}
+
+// Delete removes an artifact and its metadata
+func (s *LocalArtifactStore) Delete(ctx context.Context, key string) error {
+	file, err := s.path(key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.Remove(file); err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return domain.ErrArtifactNotFound
+		}
+		return fmt.Errorf("falha ao remover artefato %s: %w", key, err)
+	}
+
+	if err := os.Remove(file + artifactMetaExtension); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("falha ao remover metadados do artefato %s: %w", key, err)
+	}
+
+	return nil
+}
+
+// stat reads the metadata of an artifact, files without a sidecar are described from the file itself
+func (s *LocalArtifactStore) stat(key, file string) (*domain.Artifact, error) {
+	info, err := os.Stat(file)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, domain.ErrArtifactNotFound
+		}
+		return nil, err
+	}
+
+	artifact := &domain.Artifact{}
+	if meta, err := os.ReadFile(file + artifactMetaExtension); err == nil {
+		if err := json.Unmarshal(meta, artifact); err != nil {
+			return nil, fmt.Errorf("metadados do artefato %s corrompidos: %w", key, err)
+		}
+	}
+
+	artifact.Key = key
+	artifact.Size = info.Size()
+	if artifact.CreatedAt.IsZero() {
+		artifact.CreatedAt = info.ModTime()
+	}
+
+	return artifact, nil
+}
+
+// path resolves a key inside the root directory, refusing keys that could escape it
+func (s *LocalArtifactStore) path(key string) (string, error) {
+	if err := ValidateArtifactKey(key); err != nil {
+		return "", err
+	}
+	return filepath.Join(s.root, filepath.FromSlash(key)), nil
+}
+
+// ValidateArtifactKey checks a key is a clean relative "category/name" path
+func ValidateArtifactKey(key string) error {
+	if key == "" || path.IsAbs(key) || path.Clean(key) != key || strings.HasPrefix(key, "../") || key == ".." ||
+		strings.Contains(key, "\\") || isArtifactSidecar(key) {
+		return fmt.Errorf("%w: %q", ErrInvalidArtifactKey, key)
+	}
+	return nil
+}
+
+// isArtifactSidecar reports whether the file is metadata or an unfinished write rather than an artifact
+func isArtifactSidecar(file string) bool {
+	return strings.HasSuffix(file, artifactMetaExtension) || strings.HasSuffix(file, artifactTempExtension)
+}
+
+// writeAtomic writes through a temporary file renamed over the destination once complete
+func writeAtomic(file string, write func(w io.Writer) (int64, error)) (int64, error) {
+	temporary := file + artifactTempExtension
+
+	output, err := os.OpenFile(temporary, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o600)
+	if err != nil {
+		return 0, err
+	}
+
+	size, err := write(output)
+	err = errors.Join(err, output.Close())
+	if err == nil {
+		err = os.Rename(temporary, file)
+	}
+
+	if err != nil {
+		_ = os.Remove(temporary)
+		return 0, err
+	}
+
+	return size, nil
+}
@@ -0,0 +1,336 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"provisioning-assistant/internal/domain"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	s3Service          = "s3"
+	s3Algorithm        = "AWS4-HMAC-SHA256"
+	s3DateLayout       = "20060102"
+	s3TimeLayout       = "20060102T150405Z"
+	s3MetaCreatedAt    = "X-Amz-Meta-Created-At"
+	s3MetaExpiresAt    = "X-Amz-Meta-Expires-At"
+	s3RequestTimeout   = 60 * time.Second
+	s3MaxErrorBodySize = 4 * 1024
+)
+
+// S3Config points the artifact store at an S3-compatible bucket (AWS, MinIO, Ceph, R2...)
+type S3Config struct {
+	Endpoint  string
+	Region    string
+	Bucket    string
+	AccessKey string
+	SecretKey string
+}
+
+type S3ArtifactStore struct {
+	config S3Config
+	client *http.Client
+}
+
+// NewS3ArtifactStore creates an artifact store on an S3-compatible bucket, addressed path-style
+// so it works the same with AWS and self-hosted backends
+func NewS3ArtifactStore(config S3Config) (*S3ArtifactStore, error) {
+	if config.Endpoint == "" || config.Bucket == "" || config.AccessKey == "" || config.SecretKey == "" {
+		return nil, errors.New("armazenamento S3 requer endpoint, bucket e credenciais")
+	}
+	if config.Region == "" {
+		config.Region = "us-east-1"
+	}
+	config.Endpoint = strings.TrimRight(config.Endpoint, "/")
+
+	return &S3ArtifactStore{
+		config: config,
+		client: &http.Client{Timeout: s3RequestTimeout},
+	}, nil
+}
+
+// Put uploads the artifact, its creation and expiry travel as object metadata
+func (s *S3ArtifactStore) Put(ctx context.Context, artifact *domain.Artifact, content io.Reader) error {
+	if err := ValidateArtifactKey(artifact.Key); err != nil {
+		return err
+	}
+
+	body, err := io.ReadAll(content)
+	if err != nil {
+		return fmt.Errorf("falha ao ler conteúdo do artefato %s: %w", artifact.Key, err)
+	}
+	artifact.Size = int64(len(body))
+
+	header := http.Header{}
+	header.Set("Content-Type", artifact.ContentType)
+	header.Set(s3MetaCreatedAt, artifact.CreatedAt.UTC().Format(time.RFC3339))
+	if artifact.ExpiresAt != nil {
+		header.Set(s3MetaExpiresAt, artifact.ExpiresAt.UTC().Format(time.RFC3339))
+	}
+
+	response, err := s.do(ctx, http.MethodPut, artifact.Key, nil, header, body)
+	if err != nil {
+		return fmt.Errorf("falha ao enviar artefato %s: %w", artifact.Key, err)
+	}
+	response.Body.Close()
+
+	return nil
+}
+
+// Open downloads the artifact content, the caller closes the reader
+func (s *S3ArtifactStore) Open(ctx context.Context, key string) (io.ReadCloser, *domain.Artifact, error) {
+	if err := ValidateArtifactKey(key); err != nil {
+		return nil, nil, err
+	}
+
+	response, err := s.do(ctx, http.MethodGet, key, nil, nil, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return response.Body, s.artifactFromHeader(key, response), nil
+}
+
+// List returns the artifacts whose key starts with the prefix, following the listing pages
+func (s *S3ArtifactStore) List(ctx context.Context, prefix string) ([]*domain.Artifact, error) {
+	var artifacts []*domain.Artifact
+	token := ""
+
+	for {
+		query := url.Values{"list-type": {"2"}, "prefix": {prefix}}
+		if token != "" {
+			query.Set("continuation-token", token)
+		}
+
+		response, err := s.do(ctx, http.MethodGet, "", query, nil, nil)
+		if err != nil {
+			return nil, fmt.Errorf("falha ao listar artefatos: %w", err)
+		}
+
+		var page s3ListResult
+		err = xml.NewDecoder(response.Body).Decode(&page)
+		response.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("resposta de listagem do S3 inválida: %w", err)
+		}
+
+		for _, object := range page.Contents {
+			// Expiry lives in the object metadata, which the listing does not carry
+			artifact, err := s.head(ctx, object.Key)
+			if err != nil {
+				return nil, err
+			}
+			artifacts = append(artifacts, artifact)
+		}
+
+		if !page.IsTruncated || page.NextContinuationToken == "" {
+			break
+		}
+		token = page.NextContinuationToken
+	}
+
+	slices.SortFunc(artifacts, func(a, b *domain.Artifact) int {
+		return strings.Compare(a.Key, b.Key)
+	})
+
+	return artifacts, nil
+}
+
+// Delete removes an artifact from the bucket
+func (s *S3ArtifactStore) Delete(ctx context.Context, key string) error {
+	if err := ValidateArtifactKey(key); err != nil {
+		return err
+	}
+
+	response, err := s.do(ctx, http.MethodDelete, key, nil, nil, nil)
+	if err != nil {
+		return fmt.Errorf("falha ao remover artefato %s: %w", key, err)
+	}
+	response.Body.Close()
+
+	return nil
+}
+
+// head reads the metadata of a single object
+func (s *S3ArtifactStore) head(ctx context.Context, key string) (*domain.Artifact, error) {
+	response, err := s.do(ctx, http.MethodHead, key, nil, nil, nil)
+	if err != nil {
+		return nil, fmt.Errorf("falha ao consultar artefato %s: %w", key, err)
+	}
+	response.Body.Close()
+
+	return s.artifactFromHeader(key, response), nil
+}
+
+// artifactFromHeader rebuilds the artifact metadata from the object response headers
+func (s *S3ArtifactStore) artifactFromHeader(key string, response *http.Response) *domain.Artifact {
+	artifact := &domain.Artifact{
+		Key:         key,
+		ContentType: response.Header.Get("Content-Type"),
+		Size:        response.ContentLength,
+	}
+
+	if createdAt, err := time.Parse(time.RFC3339, response.Header.Get(s3MetaCreatedAt)); err == nil {
+		artifact.CreatedAt = createdAt
+	} else if modified, err := http.ParseTime(response.Header.Get("Last-Modified")); err == nil {
+		artifact.CreatedAt = modified
+	}
+
+	if expiresAt, err := time.Parse(time.RFC3339, response.Header.Get(s3MetaExpiresAt)); err == nil {
+		artifact.ExpiresAt = &expiresAt
+	}
+
+	return artifact
+}
+
+// do sends a signed request for an object (or the bucket when key is empty) and fails on non-2xx answers
+func (s *S3ArtifactStore) do(
+	ctx context.Context,
+	method, key string,
+	query url.Values,
+	header http.Header,
+	body []byte,
+) (*http.Response, error) {
+	target := s.config.Endpoint + "/" + s3EscapePath(s.config.Bucket)
+	if key != "" {
+		target += "/" + s3EscapePath(key)
+	}
+	if len(query) > 0 {
+		target += "?" + s3CanonicalQuery(query)
+	}
+
+	request, err := http.NewRequestWithContext(ctx, method, target, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	for name, values := range header {
+		request.Header[name] = values
+	}
+
+	s.sign(request, body, time.Now().UTC())
+
+	response, err := s.client.Do(request)
+	if err != nil {
+		return nil, err
+	}
+
+	if response.StatusCode == http.StatusNotFound {
+		response.Body.Close()
+		return nil, domain.ErrArtifactNotFound
+	}
+
+	if response.StatusCode/100 != 2 {
+		message, _ := io.ReadAll(io.LimitReader(response.Body, s3MaxErrorBodySize))
+		response.Body.Close()
+		return nil, fmt.Errorf("S3 respondeu %s: %s", response.Status, strings.TrimSpace(string(message)))
+	}
+
+	return response, nil
+}
+
+// sign adds an AWS Signature Version 4 authorization header to the request
+func (s *S3ArtifactStore) sign(request *http.Request, body []byte, now time.Time) {
+	payloadHash := sha256Hex(body)
+	amzDate := now.Format(s3TimeLayout)
+	scope := strings.Join([]string{now.Format(s3DateLayout), s.config.Region, s3Service, "aws4_request"}, "/")
+
+	request.Header.Set("X-Amz-Date", amzDate)
+	request.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	signedHeaders := "host;x-amz-content-sha256;x-amz-date"
+	canonicalRequest := strings.Join([]string{
+		request.Method,
+		request.URL.EscapedPath(),
+		request.URL.RawQuery,
+		"host:" + request.URL.Host,
+		"x-amz-content-sha256:" + payloadHash,
+		"x-amz-date:" + amzDate,
+		"",
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	stringToSign := strings.Join([]string{s3Algorithm, amzDate, scope, sha256Hex([]byte(canonicalRequest))}, "\n")
+
+	key := []byte("AWS4" + s.config.SecretKey)
+	for _, part := range []string{now.Format(s3DateLayout), s.config.Region, s3Service, "aws4_request"} {
+		key = hmacSHA256(key, part)
+	}
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	request.Header.Set("Authorization", fmt.Sprintf(
+		"%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s3Algorithm, s.config.AccessKey, scope, signedHeaders, signature,
+	))
+}
+
+type s3ListResult struct {
+	Contents []struct {
+		Key string `xml:"Key"`
+	} `xml:"Contents"`
+	IsTruncated           bool   `xml:"IsTruncated"`
+	NextContinuationToken string `xml:"NextContinuationToken"`
+}
+
+// s3EscapePath URI-encodes every path segment the way SigV4 expects, keeping the slashes
+func s3EscapePath(value string) string {
+	segments := strings.Split(value, "/")
+	for i, segment := range segments {
+		segments[i] = s3Escape(segment)
+	}
+	return strings.Join(segments, "/")
+}
+
+// s3CanonicalQuery encodes the query sorted by key as SigV4 expects
+func s3CanonicalQuery(query url.Values) string {
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+
+	var pairs []string
+	for _, key := range keys {
+		for _, value := range query[key] {
+			pairs = append(pairs, s3Escape(key)+"="+s3Escape(value))
+		}
+	}
+	return strings.Join(pairs, "&")
+}
+
+// s3Escape percent-encodes everything but the RFC 3986 unreserved characters
+func s3Escape(value string) string {
+	var builder strings.Builder
+	for _, b := range []byte(value) {
+		if ('A' <= b && b <= 'Z') || ('a' <= b && b <= 'z') || ('0' <= b && b <= '9') || strings.IndexByte("-_.~", b) >= 0 {
+			builder.WriteByte(b)
+			continue
+		}
+		fmt.Fprintf(&builder, "%%%02X", b)
+	}
+	return builder.String()
+}
+
+// sha256Hex returns the hex encoded SHA-256 of the data
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// hmacSHA256 signs the data with the key
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"provisioning-assistant/internal/database"
+	"provisioning-assistant/internal/domain/dto"
+	"strconv"
+)
+
+type SnapshotErpRepository struct {
+	byProtocol map[string]*dto.ConnectionInfo
+	byPPPoE    map[string]*dto.ConnectionInfo
+}
+
+// NewSnapshotErpRepository creates a read-only ERP repository over a fixed copy of connection records
+func NewSnapshotErpRepository(connections []*dto.ConnectionInfo) *SnapshotErpRepository {
+	rpt := &SnapshotErpRepository{
+		byProtocol: make(map[string]*dto.ConnectionInfo, len(connections)),
+		byPPPoE:    make(map[string]*dto.ConnectionInfo, len(connections)),
+	}
+
+	for _, connInfo := range connections {
+		rpt.byProtocol[strconv.FormatUint(connInfo.AssignmentErpID, 10)] = connInfo
+		if connInfo.ConnectionClientPPPoEUsername != "" {
+			rpt.byPPPoE[connInfo.ConnectionClientPPPoEUsername] = connInfo
+		}
+	}
+
+	return rpt
+}
+
+// LoadSnapshotErpRepository reads the connection records from a JSON array file
+func LoadSnapshotErpRepository(path string) (*SnapshotErpRepository, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("falha ao ler cópia dos dados do ERP: %w", err)
+	}
+
+	var connections []*dto.ConnectionInfo
+	if err := json.Unmarshal(data, &connections); err != nil {
+		return nil, fmt.Errorf("falha ao interpretar cópia dos dados do ERP: %w", err)
+	}
+
+	return NewSnapshotErpRepository(connections), nil
+}
+
+// GetConnInfoByProtocol retrieves connection information by protocol number
+func (rpt *SnapshotErpRepository) GetConnInfoByProtocol(ctx context.Context, protocol string) (*dto.ConnectionInfo, error) {
+	return rpt.find(rpt.byProtocol, protocol)
+}
+
+// GetConnInfoByPPPoEUsername retrieves connection information by the customer PPPoE username
+func (rpt *SnapshotErpRepository) GetConnInfoByPPPoEUsername(ctx context.Context, username string) (*dto.ConnectionInfo, error) {
+	return rpt.find(rpt.byPPPoE, username)
+}
+
+// find returns a copy of the record so callers never change the snapshot
+func (rpt *SnapshotErpRepository) find(index map[string]*dto.ConnectionInfo, key string) (*dto.ConnectionInfo, error) {
+	connInfo, exists := index[key]
+	if !exists {
+		return nil, database.ErrNotFound
+	}
+
+	clone := *connInfo
+	return &clone, nil
+}
@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"sync"
+)
+
+var ErrStateNotFound = errors.New("estado não encontrado")
+
+type StateRepository struct {
+	values map[string]map[string]string
+	mu     sync.RWMutex
+}
+
+// NewStateRepository creates a new in-memory repository for small pieces of named state
+func NewStateRepository() *StateRepository {
+	return &StateRepository{
+		values: make(map[string]map[string]string),
+	}
+}
+
+// Get retrieves the value of a key in the namespace
+func (rpt *StateRepository) Get(ctx context.Context, namespace, key string) (string, error) {
+	rpt.mu.RLock()
+	defer rpt.mu.RUnlock()
+
+	value, exists := rpt.values[namespace][key]
+	if !exists {
+		return "", ErrStateNotFound
+	}
+
+	return value, nil
+}
+
+// Set stores the value of a key in the namespace
+func (rpt *StateRepository) Set(ctx context.Context, namespace, key, value string) error {
+	rpt.mu.Lock()
+	defer rpt.mu.Unlock()
+
+	if rpt.values[namespace] == nil {
+		rpt.values[namespace] = make(map[string]string)
+	}
+
+	rpt.values[namespace][key] = value
+	return nil
+}
+
+// List returns all values of the namespace
+func (rpt *StateRepository) List(ctx context.Context, namespace string) (map[string]string, error) {
+	rpt.mu.RLock()
+	defer rpt.mu.RUnlock()
+
+	return maps.Clone(rpt.values[namespace]), nil
+}
+
+// Delete removes a key from the namespace, missing keys are ignored
+func (rpt *StateRepository) Delete(ctx context.Context, namespace, key string) error {
+	rpt.mu.Lock()
+	defer rpt.mu.Unlock()
+
+	delete(rpt.values[namespace], key)
+	return nil
+}
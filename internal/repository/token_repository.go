@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"provisioning-assistant/internal/domain"
+	"slices"
+	"sync"
+)
+
+var ErrTokenNotFound = errors.New("token não encontrado")
+
+type TokenRepository struct {
+	tokens map[string]*domain.ServiceAccountToken
+	order  []string
+	mu     sync.RWMutex
+}
+
+// NewTokenRepository creates a new in-memory service-account token repository
+func NewTokenRepository() *TokenRepository {
+	return &TokenRepository{
+		tokens: make(map[string]*domain.ServiceAccountToken),
+	}
+}
+
+// Save inserts or updates a service-account token
+func (rpt *TokenRepository) Save(ctx context.Context, token *domain.ServiceAccountToken) error {
+	if token == nil || token.ID == "" {
+		return errors.New("token inválido")
+	}
+
+	rpt.mu.Lock()
+	defer rpt.mu.Unlock()
+
+	if _, exists := rpt.tokens[token.ID]; !exists {
+		rpt.order = append(rpt.order, token.ID)
+	}
+
+	rpt.tokens[token.ID] = cloneToken(token)
+	return nil
+}
+
+// FindByID retrieves a token by its identifier
+func (rpt *TokenRepository) FindByID(ctx context.Context, id string) (*domain.ServiceAccountToken, error) {
+	rpt.mu.RLock()
+	defer rpt.mu.RUnlock()
+
+	token, exists := rpt.tokens[id]
+	if !exists {
+		return nil, ErrTokenNotFound
+	}
+
+	return cloneToken(token), nil
+}
+
+// FindByHash retrieves a token by the hash of its secret
+func (rpt *TokenRepository) FindByHash(ctx context.Context, hash string) (*domain.ServiceAccountToken, error) {
+	rpt.mu.RLock()
+	defer rpt.mu.RUnlock()
+
+	for _, token := range rpt.tokens {
+		if token.TokenHash == hash {
+			return cloneToken(token), nil
+		}
+	}
+
+	return nil, ErrTokenNotFound
+}
+
+// List returns all tokens in creation order
+func (rpt *TokenRepository) List(ctx context.Context) ([]*domain.ServiceAccountToken, error) {
+	rpt.mu.RLock()
+	defer rpt.mu.RUnlock()
+
+	tokens := make([]*domain.ServiceAccountToken, 0, len(rpt.order))
+	for _, id := range rpt.order {
+		tokens = append(tokens, cloneToken(rpt.tokens[id]))
+	}
+
+	return tokens, nil
+}
+
+// cloneToken copies a token so callers never share internal state
+func cloneToken(token *domain.ServiceAccountToken) *domain.ServiceAccountToken {
+	clone := *token
+	clone.Scopes = slices.Clone(token.Scopes)
+	return &clone
+}
@@ -0,0 +1,431 @@
+package scheduler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"provisioning-assistant/internal/clock"
+	"provisioning-assistant/internal/domain"
+	"provisioning-assistant/internal/services"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+// LeaderJobName identifies the leadership lock shared by every exclusive job
+const LeaderJobName = "scheduler"
+
+// DefaultJobTimeout bounds a run of a job without a timeout of its own, a hung run is cancelled instead of
+// skipping the next ones for good
+const DefaultJobTimeout = 30 * time.Minute
+
+var ErrUnknownJob = errors.New("tarefa agendada desconhecida")
+
+// JobFunc executes a scheduled job
+type JobFunc func(ctx context.Context) error
+
+// Job describes a scheduled job and its default schedule.
+// Local jobs act on state kept in the memory of the process serving chat users,
+// so they run alongside the bot instead of on dedicated workers.
+// A run is cancelled after Timeout, DefaultJobTimeout when zero.
+type Job struct {
+	Name      string
+	Cron      string
+	Enabled   bool
+	Jitter    time.Duration
+	Timeout   time.Duration
+	Exclusive bool
+	Local     bool
+	Run       JobFunc
+}
+
+// Scope selects which jobs a process schedules when the components run as separate processes
+type Scope int
+
+const (
+	ScopeAll Scope = iota
+	ScopeBot
+	ScopeWorker
+)
+
+// includes reports whether the scope covers the job
+func (s Scope) includes(job Job) bool {
+	switch s {
+	case ScopeBot:
+		return job.Local
+	case ScopeWorker:
+		return !job.Local
+	default:
+		return true
+	}
+}
+
+// JobConfig overrides the schedule of a registered job
+type JobConfig struct {
+	Cron    string `json:"cron,omitempty"`
+	Enabled *bool  `json:"enabled,omitempty"`
+	Jitter  string `json:"jitter,omitempty"`
+	Timeout string `json:"timeout,omitempty"`
+}
+
+// JobStatus exposes the run state of a scheduled job
+type JobStatus struct {
+	Name      string
+	Cron      string
+	Enabled   bool
+	Exclusive bool
+	Local     bool
+	Running   bool
+	LastRun   time.Time
+	NextRun   time.Time
+	LastError string
+}
+
+type entry struct {
+	job       Job
+	schedule  cron.Schedule
+	running   bool
+	lastRun   time.Time
+	nextRun   time.Time
+	lastError string
+}
+
+type Scheduler struct {
+	entries  map[string]*entry
+	leader   *services.LeaderService
+	location *time.Location
+	clock    clock.Clock
+	scope    Scope
+	logger   domain.Logger
+
+	// Once draining no job starts, active tracks the ones still running
+	draining bool
+	active   sync.WaitGroup
+
+	mu sync.RWMutex
+}
+
+// New creates a new job scheduler evaluating cron expressions in the given timezone, exclusive jobs run only on the elected leader
+func New(leader *services.LeaderService, location *time.Location, clock clock.Clock, logger domain.Logger) *Scheduler {
+	if location == nil {
+		location = time.Local
+	}
+
+	return &Scheduler{
+		entries:  make(map[string]*entry),
+		leader:   leader,
+		location: location,
+		clock:    clock,
+		logger:   logger,
+	}
+}
+
+// DailyAt returns the cron expression of a job running every day at a HH:MM local time
+func DailyAt(at string) (string, error) {
+	parsed, err := time.Parse("15:04", strings.TrimSpace(at))
+	if err != nil {
+		return "", fmt.Errorf("horário inválido %q (use HH:MM)", at)
+	}
+	return fmt.Sprintf("%d %d * * *", parsed.Minute(), parsed.Hour()), nil
+}
+
+// LoadConfig reads per-job schedule overrides from a JSON file
+func LoadConfig(path string) (map[string]JobConfig, error) {
+	if path == "" {
+		return nil, nil
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("falha ao ler arquivo de agendamentos: %w", err)
+	}
+
+	var configs map[string]JobConfig
+	if err := json.Unmarshal(content, &configs); err != nil {
+		return nil, fmt.Errorf("falha ao interpretar arquivo de agendamentos: %w", err)
+	}
+
+	return configs, nil
+}
+
+// Register adds a job with its default schedule
+func (s *Scheduler) Register(job Job) error {
+	schedule, err := cron.ParseStandard(job.Cron)
+	if err != nil {
+		return fmt.Errorf("expressão cron inválida para %s: %w", job.Name, err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.entries[job.Name] = &entry{job: job, schedule: schedule}
+	return nil
+}
+
+// Configure applies schedule overrides to the registered jobs
+func (s *Scheduler) Configure(configs map[string]JobConfig) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for name, config := range configs {
+		e, exists := s.entries[name]
+		if !exists {
+			return fmt.Errorf("%w: %s", ErrUnknownJob, name)
+		}
+
+		if config.Cron != "" {
+			schedule, err := cron.ParseStandard(config.Cron)
+			if err != nil {
+				return fmt.Errorf("expressão cron inválida para %s: %w", name, err)
+			}
+			e.job.Cron = config.Cron
+			e.schedule = schedule
+		}
+
+		if config.Enabled != nil {
+			e.job.Enabled = *config.Enabled
+		}
+
+		if config.Jitter != "" {
+			jitter, err := time.ParseDuration(config.Jitter)
+			if err != nil || jitter < 0 {
+				return fmt.Errorf("jitter inválido para %s: %s", name, config.Jitter)
+			}
+			e.job.Jitter = jitter
+		}
+
+		if config.Timeout != "" {
+			timeout, err := time.ParseDuration(config.Timeout)
+			if err != nil || timeout <= 0 {
+				return fmt.Errorf("tempo limite inválido para %s: %s", name, config.Timeout)
+			}
+			e.job.Timeout = timeout
+		}
+	}
+
+	return nil
+}
+
+// Start runs the scheduled jobs of the scope until the context is cancelled
+func (s *Scheduler) Start(ctx context.Context, scope Scope) {
+	s.mu.Lock()
+	s.scope = scope
+	s.mu.Unlock()
+
+	var wg sync.WaitGroup
+
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		s.loop(ctx, false)
+	}()
+	go func() {
+		defer wg.Done()
+		s.leader.RunExclusive(ctx, LeaderJobName, func(ctx context.Context) {
+			s.loop(ctx, true)
+		})
+	}()
+
+	wg.Wait()
+}
+
+// Drain stops starting jobs and blocks until the running ones return or the context ends
+func (s *Scheduler) Drain(ctx context.Context) error {
+	s.mu.Lock()
+	s.draining = true
+	s.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		s.active.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Status returns the state of every registered job ordered by name
+func (s *Scheduler) Status() []JobStatus {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	statuses := make([]JobStatus, 0, len(s.entries))
+	for _, e := range s.entries {
+		statuses = append(statuses, JobStatus{
+			Name:      e.job.Name,
+			Cron:      e.job.Cron,
+			Enabled:   e.job.Enabled,
+			Exclusive: e.job.Exclusive,
+			Local:     e.job.Local,
+			Running:   e.running,
+			LastRun:   e.lastRun,
+			NextRun:   e.nextRun,
+			LastError: e.lastError,
+		})
+	}
+
+	slices.SortFunc(statuses, func(a, b JobStatus) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+
+	return statuses
+}
+
+// loop waits for the next due job among the exclusive or shared ones and starts it, each job running on its
+// own so a slow one does not hold back the others of the group
+func (s *Scheduler) loop(ctx context.Context, exclusive bool) {
+	s.planAll(exclusive, s.clock.Now().In(s.location))
+	defer s.clearNextRuns(exclusive)
+
+	for {
+		next, due := s.nextDue(exclusive)
+		if due == nil {
+			<-ctx.Done()
+			return
+		}
+
+		if err := clock.Sleep(ctx, s.clock, next.Sub(s.clock.Now())); err != nil {
+			return
+		}
+
+		for _, e := range due {
+			if !s.start(ctx, e) {
+				return
+			}
+		}
+	}
+}
+
+// planAll computes the next run of every enabled job in the group
+func (s *Scheduler) planAll(exclusive bool, now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, e := range s.entries {
+		if s.owns(e, exclusive) && e.job.Enabled {
+			e.nextRun = s.plan(e, now)
+		}
+	}
+}
+
+// clearNextRuns hides the next run of jobs this replica no longer schedules
+func (s *Scheduler) clearNextRuns(exclusive bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, e := range s.entries {
+		if s.owns(e, exclusive) {
+			e.nextRun = time.Time{}
+		}
+	}
+}
+
+// nextDue returns the earliest planned time and the jobs due at it
+func (s *Scheduler) nextDue(exclusive bool) (time.Time, []*entry) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var next time.Time
+	var due []*entry
+
+	for _, e := range s.entries {
+		if !s.owns(e, exclusive) || !e.job.Enabled || e.nextRun.IsZero() {
+			continue
+		}
+
+		switch {
+		case next.IsZero() || e.nextRun.Before(next):
+			next = e.nextRun
+			due = []*entry{e}
+		case e.nextRun.Equal(next):
+			due = append(due, e)
+		}
+	}
+
+	return next, due
+}
+
+// owns reports whether this process schedules the job within the exclusive or shared group
+func (s *Scheduler) owns(e *entry, exclusive bool) bool {
+	return e.job.Exclusive == exclusive && s.scope.includes(e.job)
+}
+
+// start plans the next run of a due job and runs this one in the background, skipping it while the previous
+// run is still going. False when the scheduler is draining and the job was not started.
+func (s *Scheduler) start(ctx context.Context, e *entry) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.draining {
+		return false
+	}
+
+	e.nextRun = s.plan(e, s.clock.Now().In(s.location))
+
+	if e.running {
+		s.logger.WithField("job", e.job.Name).Warn("Tarefa agendada ainda em execução, execução ignorada")
+		return true
+	}
+
+	e.running = true
+	s.active.Add(1)
+	go s.run(ctx, e, e.job)
+	return true
+}
+
+// run executes a job within its timeout and records the outcome
+func (s *Scheduler) run(ctx context.Context, e *entry, job Job) {
+	defer s.active.Done()
+
+	timeout := job.Timeout
+	if timeout <= 0 {
+		timeout = DefaultJobTimeout
+	}
+
+	runCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	log := s.logger.WithField("job", job.Name)
+	log.Info("Executando tarefa agendada")
+
+	started := s.clock.Now()
+	err := job.Run(runCtx)
+	if err != nil && errors.Is(runCtx.Err(), context.DeadlineExceeded) {
+		err = fmt.Errorf("tarefa excedeu o tempo limite de %s: %w", timeout, err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e.running = false
+	e.lastRun = started
+	e.lastError = ""
+
+	if err != nil {
+		e.lastError = err.Error()
+		log.WithError(err).Error("Falha na tarefa agendada")
+		return
+	}
+
+	log.WithField("duration", s.clock.Since(started).String()).Info("Tarefa agendada concluída")
+}
+
+// plan computes the next run of a job after now, adding a random jitter
+func (s *Scheduler) plan(e *entry, now time.Time) time.Time {
+	next := e.schedule.Next(now)
+	if e.job.Jitter > 0 {
+		next = next.Add(rand.N(e.job.Jitter))
+	}
+	return next
+}
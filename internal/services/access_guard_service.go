@@ -0,0 +1,188 @@
+package services
+
+import (
+	"provisioning-assistant/internal/clock"
+	"slices"
+	"sync"
+	"time"
+)
+
+const (
+	MaxFailedAuthentications  = 5
+	FailedAuthWindow          = 30 * time.Minute
+	TemporaryBanDuration      = 15 * time.Minute
+	MaxBanDuration            = 24 * time.Hour
+	SuspiciousAccountsPerCPF  = 3
+	SuspiciousTaxIDTrackLimit = 10000
+	FailedAuthTrackLimit      = 10000
+
+	// LockoutCoolOff is how long after a ban ends its lockouts still double the next one
+	LockoutCoolOff = MaxBanDuration
+)
+
+type authFailures struct {
+	count       int
+	lockouts    int
+	firstFailAt time.Time
+	bannedUntil time.Time
+}
+
+// isExpired reports whether the failures fell out of the window and the last ban out of the cool-off, a new
+// failure then starts over without earlier lockouts
+func (f *authFailures) isExpired(now time.Time) bool {
+	return now.Sub(f.firstFailAt) > FailedAuthWindow && now.After(f.bannedUntil.Add(LockoutCoolOff))
+}
+
+type taxIDFailures struct {
+	accounts    []int64
+	firstFailAt time.Time
+	alerted     bool
+}
+
+// AuthFailureResult describes the consequences of a failed authentication
+type AuthFailureResult struct {
+	Banned        bool
+	BannedUntil   time.Time
+	SuspiciousCPF bool
+	Accounts      []int64
+}
+
+type AccessGuardService struct {
+	failures      map[int64]*authFailures
+	taxIDFailures map[string]*taxIDFailures
+	clock         clock.Clock
+	mu            sync.Mutex
+}
+
+// NewAccessGuardService creates a new failed authentication tracker
+func NewAccessGuardService(clock clock.Clock) *AccessGuardService {
+	return &AccessGuardService{
+		clock:         clock,
+		failures:      make(map[int64]*authFailures),
+		taxIDFailures: make(map[string]*taxIDFailures),
+	}
+}
+
+// IsBanned reports whether the Telegram user is temporarily banned and until when
+func (s *AccessGuardService) IsBanned(userID int64) (bool, time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, exists := s.failures[userID]
+	if !exists || s.clock.Now().After(entry.bannedUntil) {
+		return false, time.Time{}
+	}
+
+	return true, entry.bannedUntil
+}
+
+// RecordFailure registers a failed authentication, applying exponential lockouts
+// and flagging CPFs guessed from several Telegram accounts
+func (s *AccessGuardService) RecordFailure(userID int64, taxID string) AuthFailureResult {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.clock.Now()
+	result := s.trackTaxID(userID, taxID, now)
+
+	entry, exists := s.failures[userID]
+	if !exists || entry.isExpired(now) {
+		if !exists && len(s.failures) >= FailedAuthTrackLimit {
+			s.pruneFailures(now)
+		}
+		entry = &authFailures{firstFailAt: now}
+		s.failures[userID] = entry
+	} else if now.Sub(entry.firstFailAt) > FailedAuthWindow {
+		entry.count = 0
+		entry.firstFailAt = now
+	}
+
+	entry.count++
+	if entry.count < MaxFailedAuthentications {
+		return result
+	}
+
+	entry.lockouts++
+	entry.count = 0
+	entry.firstFailAt = now
+	entry.bannedUntil = now.Add(lockoutDuration(entry.lockouts))
+
+	result.Banned = true
+	result.BannedUntil = entry.bannedUntil
+	return result
+}
+
+// RecordSuccess clears the failure history of the user
+func (s *AccessGuardService) RecordSuccess(userID int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.failures, userID)
+}
+
+// Unlock lifts any lockout of the user, returning false when there was none
+func (s *AccessGuardService) Unlock(userID int64) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, exists := s.failures[userID]
+	delete(s.failures, userID)
+	return exists
+}
+
+// trackTaxID records which accounts failed with a CPF and detects guessing
+func (s *AccessGuardService) trackTaxID(userID int64, taxID string, now time.Time) AuthFailureResult {
+	if taxID == "" {
+		return AuthFailureResult{}
+	}
+
+	entry, exists := s.taxIDFailures[taxID]
+	if !exists || now.Sub(entry.firstFailAt) > FailedAuthWindow {
+		if !exists && len(s.taxIDFailures) >= SuspiciousTaxIDTrackLimit {
+			s.pruneTaxIDFailures(now)
+		}
+		entry = &taxIDFailures{firstFailAt: now}
+		s.taxIDFailures[taxID] = entry
+	}
+
+	if !slices.Contains(entry.accounts, userID) {
+		entry.accounts = append(entry.accounts, userID)
+	}
+
+	if entry.alerted || len(entry.accounts) < SuspiciousAccountsPerCPF {
+		return AuthFailureResult{}
+	}
+
+	entry.alerted = true
+	return AuthFailureResult{
+		SuspiciousCPF: true,
+		Accounts:      slices.Clone(entry.accounts),
+	}
+}
+
+// pruneFailures drops the failure history of users with nothing left to remember
+func (s *AccessGuardService) pruneFailures(now time.Time) {
+	for userID, entry := range s.failures {
+		if entry.isExpired(now) {
+			delete(s.failures, userID)
+		}
+	}
+}
+
+// pruneTaxIDFailures drops CPF tracking entries outside the failure window
+func (s *AccessGuardService) pruneTaxIDFailures(now time.Time) {
+	for taxID, entry := range s.taxIDFailures {
+		if now.Sub(entry.firstFailAt) > FailedAuthWindow {
+			delete(s.taxIDFailures, taxID)
+		}
+	}
+}
+
+// lockoutDuration doubles the ban for each consecutive lockout up to the maximum
+func lockoutDuration(lockouts int) time.Duration {
+	duration := TemporaryBanDuration
+	for i := 1; i < lockouts && duration < MaxBanDuration; i++ {
+		duration *= 2
+	}
+	return min(duration, MaxBanDuration)
+}
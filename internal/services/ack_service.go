@@ -0,0 +1,214 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"provisioning-assistant/internal/clock"
+	"provisioning-assistant/internal/domain"
+	"provisioning-assistant/internal/ids"
+	"slices"
+	"sync"
+	"time"
+)
+
+const ackNamespace = "critical_notices"
+
+const (
+	// Acknowledgement tracking constants
+	DefaultAckTimeout = 15 * time.Minute
+	AckRetention      = 24 * time.Hour
+)
+
+var (
+	ErrNoticeNotFound     = errors.New("notificação não encontrada")
+	ErrNoticeNotRecipient = errors.New("chat não é destinatário da notificação")
+)
+
+type AckService struct {
+	repository domain.StateRepository
+	timeout    time.Duration
+	clock      clock.Clock
+	logger     domain.Logger
+	mu         sync.Mutex
+}
+
+// NewAckService creates a new acknowledgement tracker with the given response deadline, the notices kept in
+// the state repository so every process sees the same acknowledgements
+func NewAckService(repository domain.StateRepository, timeout time.Duration, clock clock.Clock, logger domain.Logger) *AckService {
+	if timeout <= 0 {
+		timeout = DefaultAckTimeout
+	}
+
+	return &AckService{
+		repository: repository,
+		timeout:    timeout,
+		clock:      clock,
+		logger:     logger,
+	}
+}
+
+// Track registers a critical notice sent to the given chats, the notice is returned even when it could not
+// be stored so the alert still goes out
+func (s *AckService) Track(ctx context.Context, text string, chatIDs []int64) domain.CriticalNotice {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	notice := s.newNotice(text, chatIDs)
+	s.store(ctx, notice)
+	return notice
+}
+
+// TrackEscalated registers a critical notice that already went to the escalation chats
+func (s *AckService) TrackEscalated(ctx context.Context, text string, chatIDs []int64) domain.CriticalNotice {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	notice := s.newNotice(text, chatIDs)
+	notice.EscalatedAt = &notice.SentAt
+	s.store(ctx, notice)
+	return notice
+}
+
+// Acknowledge marks a notice as handled by a user of one of its recipient chats
+func (s *AckService) Acknowledge(ctx context.Context, id string, chatID, userID int64) (domain.CriticalNotice, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	notice, err := s.get(ctx, id)
+	if err != nil {
+		return domain.CriticalNotice{}, false, ErrNoticeNotFound
+	}
+
+	if !notice.IsRecipient(chatID) {
+		return domain.CriticalNotice{}, false, ErrNoticeNotRecipient
+	}
+
+	if notice.IsAcknowledged() {
+		return *notice, false, nil
+	}
+
+	now := s.clock.Now()
+	notice.AckedBy = userID
+	notice.AckedAt = &now
+
+	if err := s.save(ctx, *notice); err != nil {
+		return domain.CriticalNotice{}, false, err
+	}
+
+	return *notice, true, nil
+}
+
+// Escalate returns the overdue notices and adds the escalation chats to their recipients
+func (s *AckService) Escalate(ctx context.Context, escalationChatIDs []int64) []domain.CriticalNotice {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.clock.Now()
+	var overdue []domain.CriticalNotice
+
+	for _, notice := range s.list(ctx) {
+		if !notice.IsOverdue(now) {
+			continue
+		}
+
+		notice.EscalatedAt = &now
+		for _, chatID := range escalationChatIDs {
+			if !notice.IsRecipient(chatID) {
+				notice.ChatIDs = append(notice.ChatIDs, chatID)
+			}
+		}
+
+		// A notice not marked escalated would go out again on the next run, so it is skipped this time
+		if err := s.save(ctx, notice); err != nil {
+			s.logger.WithError(err).WithField("notice_id", notice.ID).Error("Falha ao registrar escalonamento de alerta crítico")
+			continue
+		}
+
+		overdue = append(overdue, notice)
+	}
+
+	slices.SortFunc(overdue, func(a, b domain.CriticalNotice) int {
+		return a.SentAt.Compare(b.SentAt)
+	})
+	return overdue
+}
+
+// newNotice builds a notice sent now to the given chats
+func (s *AckService) newNotice(text string, chatIDs []int64) domain.CriticalNotice {
+	now := s.clock.Now()
+	return domain.CriticalNotice{
+		ID:       ids.New(),
+		Text:     text,
+		ChatIDs:  slices.Clone(chatIDs),
+		SentAt:   now,
+		Deadline: now.Add(s.timeout),
+	}
+}
+
+// store saves a new notice after dropping the expired ones, a failure only logged
+func (s *AckService) store(ctx context.Context, notice domain.CriticalNotice) {
+	s.purge(ctx)
+
+	if err := s.save(ctx, notice); err != nil {
+		s.logger.WithError(err).WithField("notice_id", notice.ID).Error("Falha ao registrar alerta crítico, confirmação não será acompanhada")
+	}
+}
+
+// list reads the stored notices, skipping the unreadable ones
+func (s *AckService) list(ctx context.Context) []domain.CriticalNotice {
+	stored, err := s.repository.List(ctx, ackNamespace)
+	if err != nil {
+		s.logger.WithError(err).Warn("Falha ao listar alertas críticos")
+		return nil
+	}
+
+	notices := make([]domain.CriticalNotice, 0, len(stored))
+	for id, value := range stored {
+		var notice domain.CriticalNotice
+		if err := json.Unmarshal([]byte(value), &notice); err != nil {
+			s.logger.WithError(err).WithField("notice_id", id).Warn("Alerta crítico inválido ignorado")
+			continue
+		}
+		notices = append(notices, notice)
+	}
+	return notices
+}
+
+// get reads a stored notice
+func (s *AckService) get(ctx context.Context, id string) (*domain.CriticalNotice, error) {
+	value, err := s.repository.Get(ctx, ackNamespace, id)
+	if err != nil {
+		return nil, err
+	}
+
+	var notice domain.CriticalNotice
+	if err := json.Unmarshal([]byte(value), &notice); err != nil {
+		return nil, fmt.Errorf("falha ao interpretar alerta crítico: %w", err)
+	}
+	return &notice, nil
+}
+
+// save stores a notice under its ID
+func (s *AckService) save(ctx context.Context, notice domain.CriticalNotice) error {
+	value, err := json.Marshal(notice)
+	if err != nil {
+		return fmt.Errorf("falha ao serializar alerta crítico: %w", err)
+	}
+
+	return s.repository.Set(ctx, ackNamespace, notice.ID, string(value))
+}
+
+// purge drops notices older than the retention period
+func (s *AckService) purge(ctx context.Context) {
+	for _, notice := range s.list(ctx) {
+		if s.clock.Since(notice.SentAt) <= AckRetention {
+			continue
+		}
+
+		if err := s.repository.Delete(ctx, ackNamespace, notice.ID); err != nil {
+			s.logger.WithError(err).WithField("notice_id", notice.ID).Warn("Falha ao remover alerta crítico expirado")
+		}
+	}
+}
@@ -0,0 +1,216 @@
+package services
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MetricsPrefix namespaces the metrics exported by the application
+const MetricsPrefix = "provisioning_assistant_"
+
+// Operational gauges exported next to the flow metrics and watched by the alert rules
+const (
+	MetricUnmUp                    = "unm_up"
+	MetricProvisioningSuccessRatio = "provisioning_success_ratio"
+	MetricProvisioningQueueWaiting = "provisioning_queue_waiting"
+	MetricErpLatencyP95            = "erp_latency_p95_seconds"
+	MetricUnmProbeAvailability     = "unm_probe_availability_ratio"
+	MetricUnmProbeLatencyP95       = "unm_probe_latency_p95_seconds"
+)
+
+// AlertSeverity tells how urgently a firing alert must be handled
+type AlertSeverity string
+
+const (
+	AlertCritical AlertSeverity = "critical"
+	AlertWarning  AlertSeverity = "warning"
+)
+
+// Gauge is the current value of an operational metric
+type Gauge struct {
+	Name  string
+	Help  string
+	Value float64
+}
+
+// AlertRule fires while its metric stays beyond the threshold, below it when Below is set
+type AlertRule struct {
+	Name      string
+	Metric    string
+	Below     bool
+	Threshold float64
+	For       time.Duration
+	Severity  AlertSeverity
+	Summary   string
+}
+
+// AlertStatus is a rule evaluated against the current reading of its metric
+type AlertStatus struct {
+	Rule   AlertRule
+	Value  float64
+	Firing bool
+}
+
+// DefaultAlertRules is the canned set of alerts shipped for the exported gauges
+var DefaultAlertRules = []AlertRule{
+	{
+		Name:      "ProvisioningAssistantUnmDown",
+		Metric:    MetricUnmUp,
+		Below:     true,
+		Threshold: 1,
+		For:       2 * time.Minute,
+		Severity:  AlertCritical,
+		Summary:   "O UNM não responde aos comandos TL1",
+	},
+	{
+		Name:      "ProvisioningAssistantSuccessRateDrop",
+		Metric:    MetricProvisioningSuccessRatio,
+		Below:     true,
+		Threshold: 0.8,
+		For:       10 * time.Minute,
+		Severity:  AlertWarning,
+		Summary:   "A taxa de sucesso dos provisionamentos caiu abaixo de 80%",
+	},
+	{
+		Name:      "ProvisioningAssistantQueueBacklog",
+		Metric:    MetricProvisioningQueueWaiting,
+		Threshold: 5,
+		For:       5 * time.Minute,
+		Severity:  AlertWarning,
+		Summary:   "Provisionamentos acumulados aguardando vaga na fila",
+	},
+	{
+		Name:      "ProvisioningAssistantErpLatencyHigh",
+		Metric:    MetricErpLatencyP95,
+		Threshold: ErpSlowThreshold.Seconds(),
+		For:       10 * time.Minute,
+		Severity:  AlertWarning,
+		Summary:   "O p95 das consultas ao ERP está acima do limite de lentidão",
+	},
+	{
+		Name:      "ProvisioningAssistantUnmProbeFailing",
+		Metric:    MetricUnmProbeAvailability,
+		Below:     true,
+		Threshold: 0.9,
+		For:       5 * time.Minute,
+		Severity:  AlertWarning,
+		Summary:   "Mais de 10% das sondas TL1 recentes ficaram sem resposta do UNM",
+	},
+}
+
+// AlertService reads the operational gauges of this process and evaluates the alert rules against them
+type AlertService struct {
+	provisioningService *ProvisioningService
+	erpService          *ErpService
+	circuitService      *ProvisioningCircuitService
+	queue               *ProvisioningQueue
+	probeService        *UnmProbeService
+	rules               []AlertRule
+}
+
+// NewAlertService creates a new alert service evaluating the default rules
+func NewAlertService(
+	provisioningService *ProvisioningService,
+	erpService *ErpService,
+	circuitService *ProvisioningCircuitService,
+	queue *ProvisioningQueue,
+	probeService *UnmProbeService,
+) *AlertService {
+	return &AlertService{
+		provisioningService: provisioningService,
+		erpService:          erpService,
+		circuitService:      circuitService,
+		queue:               queue,
+		probeService:        probeService,
+		rules:               DefaultAlertRules,
+	}
+}
+
+// Gauges returns the current operational readings, they belong to the process that provisions
+func (s *AlertService) Gauges() []Gauge {
+	unmUp := 0.0
+	if s.provisioningService.UnmReachable() {
+		unmUp = 1
+	}
+
+	_, waiting := s.queue.Jobs()
+	_, p95 := s.erpService.Latency()
+	probeAvailability, probeP95 := s.probeService.Availability()
+
+	return []Gauge{
+		{Name: MetricUnmUp, Help: "Whether the UNM answered the last TL1 command", Value: unmUp},
+		{Name: MetricProvisioningSuccessRatio, Help: "Provisioning success ratio in the circuit breaker window", Value: s.circuitService.SuccessRate()},
+		{Name: MetricProvisioningQueueWaiting, Help: "Provisioning jobs waiting for a slot", Value: float64(len(waiting))},
+		{Name: MetricErpLatencyP95, Help: "Recent p95 latency of the ERP lookups", Value: p95.Seconds()},
+		{Name: MetricUnmProbeAvailability, Help: "Lowest ratio of synthetic TL1 probes answered by a UNM endpoint", Value: probeAvailability},
+		{Name: MetricUnmProbeLatencyP95, Help: "Highest p95 latency of the synthetic TL1 probes among the UNM endpoints", Value: probeP95.Seconds()},
+	}
+}
+
+// Evaluate returns every rule with the current value of its metric and whether it is firing now,
+// the For duration is left to Prometheus
+func (s *AlertService) Evaluate() []AlertStatus {
+	values := make(map[string]float64)
+	for _, gauge := range s.Gauges() {
+		values[gauge.Name] = gauge.Value
+	}
+
+	statuses := make([]AlertStatus, 0, len(s.rules))
+	for _, rule := range s.rules {
+		value := values[rule.Metric]
+		statuses = append(statuses, AlertStatus{
+			Rule:   rule,
+			Value:  value,
+			Firing: rule.Fires(value),
+		})
+	}
+
+	return statuses
+}
+
+// Fires reports whether the value crosses the rule threshold
+func (r AlertRule) Fires(value float64) bool {
+	if r.Below {
+		return value < r.Threshold
+	}
+	return value > r.Threshold
+}
+
+// Expr returns the PromQL condition of the rule
+func (r AlertRule) Expr() string {
+	operator := ">"
+	if r.Below {
+		operator = "<"
+	}
+	return fmt.Sprintf("%s%s %s %s", MetricsPrefix, r.Metric, operator, strconv.FormatFloat(r.Threshold, 'g', -1, 64))
+}
+
+// PrometheusRules renders the rules as a Prometheus alerting rules file
+func (s *AlertService) PrometheusRules() string {
+	var builder strings.Builder
+	builder.WriteString("groups:\n")
+	builder.WriteString("  - name: provisioning-assistant\n")
+	builder.WriteString("    rules:\n")
+
+	for _, rule := range s.rules {
+		fmt.Fprintf(&builder, "      - alert: %s\n", rule.Name)
+		fmt.Fprintf(&builder, "        expr: %s\n", rule.Expr())
+		fmt.Fprintf(&builder, "        for: %s\n", promDuration(rule.For))
+		builder.WriteString("        labels:\n")
+		fmt.Fprintf(&builder, "          severity: %s\n", rule.Severity)
+		builder.WriteString("        annotations:\n")
+		fmt.Fprintf(&builder, "          summary: %q\n", rule.Summary)
+	}
+
+	return builder.String()
+}
+
+// promDuration writes a duration in the Prometheus notation, e.g. 10m or 90s
+func promDuration(d time.Duration) string {
+	if d%time.Minute == 0 {
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	}
+	return fmt.Sprintf("%ds", int(d.Seconds()))
+}
@@ -0,0 +1,216 @@
+package services
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"provisioning-assistant/internal/domain"
+	"provisioning-assistant/internal/ids"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	ArchiveFilePrefix     = "audits-"
+	ArchiveFileExtension  = ".jsonl.gz"
+	ArchiveFileTimeLayout = "20060102-150405"
+	ArchiveArtifactPrefix = "archive/"
+)
+
+var ErrArchivedRecordNotFound = errors.New("registro de auditoria não encontrado no arquivo morto")
+
+type ArchiveService struct {
+	auditRepository domain.AuditRepository
+	artifacts       *ArtifactService
+	retention       time.Duration
+	logger          domain.Logger
+}
+
+// NewArchiveService creates a service that moves old audit records to compressed cold-storage files,
+// kept for good in the artifact store
+func NewArchiveService(auditRepository domain.AuditRepository, artifacts *ArtifactService, retention time.Duration, logger domain.Logger) *ArchiveService {
+	return &ArchiveService{
+		auditRepository: auditRepository,
+		artifacts:       artifacts,
+		retention:       retention,
+		logger:          logger,
+	}
+}
+
+// IsEnabled reports whether a retention period is configured
+func (s *ArchiveService) IsEnabled() bool {
+	return s.retention > 0
+}
+
+// Archive writes the records not updated within the retention period to a new archive file and
+// removes them from the hot store, returning how many records were moved
+func (s *ArchiveService) Archive(ctx context.Context) (int, error) {
+	if !s.IsEnabled() {
+		return 0, nil
+	}
+
+	records, err := s.auditRepository.List(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("falha ao listar registros de auditoria: %w", err)
+	}
+
+	cutoff := time.Now().Add(-s.retention)
+	records = slices.DeleteFunc(records, func(record *domain.AuditRecord) bool {
+		return !record.UpdatedAt.Before(cutoff)
+	})
+
+	if len(records) == 0 {
+		return 0, nil
+	}
+
+	key, err := s.write(ctx, records)
+	if err != nil {
+		return 0, err
+	}
+
+	// Records are only removed once the archive is safely stored
+	for _, record := range records {
+		if err := s.auditRepository.Delete(ctx, record.ID); err != nil {
+			return 0, fmt.Errorf("falha ao remover registro %s arquivado: %w", record.ID, err)
+		}
+	}
+
+	s.logger.WithFields(map[string]any{
+		"file":    key,
+		"records": len(records),
+	}).Info("Registros de auditoria arquivados")
+
+	return len(records), nil
+}
+
+// FindRecord looks up an archived audit record, scanning the newest archives first
+func (s *ArchiveService) FindRecord(ctx context.Context, id string) (*domain.AuditRecord, error) {
+	id = ids.Normalize(id)
+
+	var found *domain.AuditRecord
+	err := s.scan(ctx, fmt.Sprintf(`"id":%q`, id), func(record *domain.AuditRecord) bool {
+		if record.ID != id {
+			return true
+		}
+		found = record
+		return false
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if found == nil {
+		return nil, ErrArchivedRecordNotFound
+	}
+	return found, nil
+}
+
+// FindBySerial returns every archived audit record of an ONU serial, newest archives first
+func (s *ArchiveService) FindBySerial(ctx context.Context, serial string) ([]*domain.AuditRecord, error) {
+	var records []*domain.AuditRecord
+	err := s.scan(ctx, fmt.Sprintf(`"serial":%q`, serial), func(record *domain.AuditRecord) bool {
+		if strings.EqualFold(record.Serial, serial) {
+			records = append(records, record)
+		}
+		return true
+	})
+	return records, err
+}
+
+// scan hands the archived records whose line contains the needle to visit, newest archives first,
+// until visit returns false
+func (s *ArchiveService) scan(ctx context.Context, needle string, visit func(record *domain.AuditRecord) bool) error {
+	artifacts, err := s.artifacts.List(ctx, ArchiveArtifactPrefix+ArchiveFilePrefix)
+	if err != nil {
+		return err
+	}
+
+	// The timestamp in the name makes the lexical order chronological
+	slices.Reverse(artifacts)
+
+	for _, artifact := range artifacts {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if !strings.HasSuffix(artifact.Key, ArchiveFileExtension) {
+			continue
+		}
+
+		more, err := s.scanArtifact(ctx, artifact.Key, needle, visit)
+		if err != nil {
+			s.logger.WithError(err).WithField("file", artifact.Key).Warn("Falha ao ler arquivo morto de auditoria")
+			continue
+		}
+		if !more {
+			return nil
+		}
+	}
+
+	return nil
+}
+
+// write stores the records as gzip compressed JSON lines
+func (s *ArchiveService) write(ctx context.Context, records []*domain.AuditRecord) (string, error) {
+	var content bytes.Buffer
+	writer := gzip.NewWriter(&content)
+	encoder := json.NewEncoder(writer)
+
+	var err error
+	for _, record := range records {
+		if err = encoder.Encode(record); err != nil {
+			break
+		}
+	}
+
+	if err = errors.Join(err, writer.Close()); err != nil {
+		return "", fmt.Errorf("falha ao compactar arquivo morto: %w", err)
+	}
+
+	key := ArchiveArtifactPrefix + ArchiveFilePrefix + time.Now().Format(ArchiveFileTimeLayout) + ArchiveFileExtension
+	if _, err := s.artifacts.Save(ctx, key, "application/gzip", &content, 0); err != nil {
+		return "", fmt.Errorf("falha ao gravar arquivo morto: %w", err)
+	}
+
+	return key, nil
+}
+
+// scanArtifact hands the records of an archive matching the needle to visit, reporting whether to keep scanning
+func (s *ArchiveService) scanArtifact(ctx context.Context, key, needle string, visit func(record *domain.AuditRecord) bool) (bool, error) {
+	file, _, err := s.artifacts.Open(ctx, key)
+	if err != nil {
+		return true, err
+	}
+	defer file.Close()
+
+	reader, err := gzip.NewReader(file)
+	if err != nil {
+		return true, err
+	}
+	defer reader.Close()
+
+	needle = strings.ToLower(needle)
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if !strings.Contains(strings.ToLower(string(line)), needle) {
+			continue
+		}
+
+		var record domain.AuditRecord
+		if err := json.Unmarshal(line, &record); err != nil {
+			return true, err
+		}
+		if !visit(&record) {
+			return false, nil
+		}
+	}
+
+	return true, scanner.Err()
+}
@@ -0,0 +1,379 @@
+package services
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net/url"
+	"provisioning-assistant/internal/clock"
+	"provisioning-assistant/internal/domain"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultArtifactRetention = 30 * 24 * time.Hour
+	DefaultArtifactLinkTTL   = 24 * time.Hour
+	ArtifactRoutePrefix      = "/artifacts/"
+)
+
+// DefaultArtifactRetentions keeps the TL1 captures shorter than the reports, they only matter while the
+// problem they show is being investigated
+var DefaultArtifactRetentions = map[string]time.Duration{
+	Tl1DumpCategory:       7 * 24 * time.Hour,
+	Tl1TranscriptCategory: 90 * 24 * time.Hour,
+}
+
+// DefaultArtifactQuotas bounds the categories written without anyone asking for them
+var DefaultArtifactQuotas = map[string]int64{
+	Tl1DumpCategory:       50 << 20,
+	Tl1TranscriptCategory: 200 << 20,
+}
+
+var (
+	ErrArtifactLinkInvalid = errors.New("link de artefato inválido")
+	ErrArtifactLinkExpired = errors.New("link de artefato expirado")
+)
+
+// ArtifactPolicy configures how long generated files are kept and how they are shared. Retentions and
+// Quotas override the defaults per category, the first segment of the key.
+type ArtifactPolicy struct {
+	Retention  time.Duration
+	Retentions map[string]time.Duration
+	Quotas     map[string]int64
+	BaseURL    string
+	SigningKey string
+	LinkTTL    time.Duration
+}
+
+// ArtifactUsage is what a category takes in the store
+type ArtifactUsage struct {
+	Category  string
+	Files     int
+	Size      int64
+	Quota     int64
+	Retention time.Duration
+	Oldest    time.Time
+}
+
+type ArtifactService struct {
+	store  domain.ArtifactStore
+	policy ArtifactPolicy
+	clock  clock.Clock
+	logger domain.Logger
+}
+
+// NewArtifactService creates the service every file-producing feature stores its output through
+func NewArtifactService(store domain.ArtifactStore, policy ArtifactPolicy, clock clock.Clock, logger domain.Logger) *ArtifactService {
+	if policy.LinkTTL <= 0 {
+		policy.LinkTTL = DefaultArtifactLinkTTL
+	}
+	policy.BaseURL = strings.TrimRight(policy.BaseURL, "/")
+
+	retentions := maps.Clone(DefaultArtifactRetentions)
+	maps.Copy(retentions, policy.Retentions)
+	policy.Retentions = retentions
+
+	quotas := maps.Clone(DefaultArtifactQuotas)
+	maps.Copy(quotas, policy.Quotas)
+	policy.Quotas = quotas
+
+	return &ArtifactService{
+		store:  store,
+		policy: policy,
+		clock:  clock,
+		logger: logger,
+	}
+}
+
+// Retention returns the default time generated files are kept
+func (s *ArtifactService) Retention() time.Duration {
+	return s.policy.Retention
+}
+
+// RetentionFor returns how long the files of a category are kept, the default retention unless overridden
+func (s *ArtifactService) RetentionFor(category string) time.Duration {
+	if retention, exists := s.policy.Retentions[category]; exists {
+		return retention
+	}
+	return s.policy.Retention
+}
+
+// Save stores a generated file under "category/name", a zero keep period stores it for good. The retention
+// configured for the category replaces the keep period given, and the oldest files of the category are
+// removed when it goes over its quota.
+func (s *ArtifactService) Save(ctx context.Context, key, contentType string, content io.Reader, keep time.Duration) (*domain.Artifact, error) {
+	artifact := &domain.Artifact{
+		Key:         key,
+		ContentType: contentType,
+		CreatedAt:   s.clock.Now(),
+	}
+
+	if retention, exists := s.policy.Retentions[artifactCategory(key)]; exists && keep > 0 {
+		keep = retention
+	}
+
+	if keep > 0 {
+		expiresAt := artifact.CreatedAt.Add(keep)
+		artifact.ExpiresAt = &expiresAt
+	}
+
+	if err := s.store.Put(ctx, artifact, content); err != nil {
+		return nil, err
+	}
+
+	s.logger.WithFields(map[string]any{
+		"key":  artifact.Key,
+		"size": artifact.Size,
+	}).Debug("Artefato gravado")
+
+	if err := s.enforceQuota(ctx, artifact); err != nil {
+		s.logger.WithError(err).WithField("key", artifact.Key).Warn("Falha ao aplicar a cota da categoria do artefato")
+	}
+
+	return artifact, nil
+}
+
+// enforceQuota removes the oldest files of the category of a new artifact until it fits its quota, the
+// new artifact itself is kept
+func (s *ArtifactService) enforceQuota(ctx context.Context, saved *domain.Artifact) error {
+	category := artifactCategory(saved.Key)
+	quota := s.policy.Quotas[category]
+	if quota <= 0 {
+		return nil
+	}
+
+	artifacts, err := s.store.List(ctx, category+"/")
+	if err != nil {
+		return err
+	}
+
+	var total int64
+	for _, artifact := range artifacts {
+		total += artifact.Size
+	}
+	if total <= quota {
+		return nil
+	}
+
+	slices.SortFunc(artifacts, func(a, b *domain.Artifact) int {
+		return a.CreatedAt.Compare(b.CreatedAt)
+	})
+
+	var evicted int
+	for _, artifact := range artifacts {
+		if total <= quota {
+			break
+		}
+		if artifact.Key == saved.Key {
+			continue
+		}
+
+		if err := s.store.Delete(ctx, artifact.Key); err != nil && !errors.Is(err, domain.ErrArtifactNotFound) {
+			return fmt.Errorf("falha ao remover artefato %s acima da cota: %w", artifact.Key, err)
+		}
+		total -= artifact.Size
+		evicted++
+	}
+
+	s.logger.WithFields(map[string]any{
+		"category":  category,
+		"artifacts": evicted,
+		"quota":     quota,
+	}).Info("Artefatos mais antigos removidos pela cota da categoria")
+
+	return nil
+}
+
+// Usage returns the files and space taken by each category, the categories with a quota or a retention
+// of their own listed even when empty
+func (s *ArtifactService) Usage(ctx context.Context) ([]ArtifactUsage, error) {
+	artifacts, err := s.store.List(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+
+	usage := make(map[string]*ArtifactUsage)
+	categoryUsage := func(category string) *ArtifactUsage {
+		if usage[category] == nil {
+			usage[category] = &ArtifactUsage{
+				Category:  category,
+				Quota:     s.policy.Quotas[category],
+				Retention: s.RetentionFor(category),
+			}
+		}
+		return usage[category]
+	}
+
+	for category := range s.policy.Quotas {
+		categoryUsage(category)
+	}
+	for category := range s.policy.Retentions {
+		categoryUsage(category)
+	}
+
+	for _, artifact := range artifacts {
+		current := categoryUsage(artifactCategory(artifact.Key))
+		current.Files++
+		current.Size += artifact.Size
+		if current.Oldest.IsZero() || artifact.CreatedAt.Before(current.Oldest) {
+			current.Oldest = artifact.CreatedAt
+		}
+	}
+
+	result := make([]ArtifactUsage, 0, len(usage))
+	for _, category := range slices.Sorted(maps.Keys(usage)) {
+		result = append(result, *usage[category])
+	}
+	return result, nil
+}
+
+// Open returns the content of a stored file, the caller closes the reader
+func (s *ArtifactService) Open(ctx context.Context, key string) (io.ReadCloser, *domain.Artifact, error) {
+	return s.store.Open(ctx, key)
+}
+
+// List returns the stored files of a category, or of every category with an empty prefix
+func (s *ArtifactService) List(ctx context.Context, prefix string) ([]*domain.Artifact, error) {
+	return s.store.List(ctx, prefix)
+}
+
+// Purge removes the files past their retention, returning how many were deleted
+func (s *ArtifactService) Purge(ctx context.Context) (int, error) {
+	artifacts, err := s.store.List(ctx, "")
+	if err != nil {
+		return 0, err
+	}
+
+	now := s.clock.Now()
+	var purged int
+
+	for _, artifact := range artifacts {
+		if !artifact.IsExpired(now) {
+			continue
+		}
+
+		if err := s.store.Delete(ctx, artifact.Key); err != nil && !errors.Is(err, domain.ErrArtifactNotFound) {
+			return purged, fmt.Errorf("falha ao remover artefato expirado %s: %w", artifact.Key, err)
+		}
+		purged++
+	}
+
+	if purged > 0 {
+		s.logger.WithField("artifacts", purged).Info("Artefatos expirados removidos")
+	}
+
+	return purged, nil
+}
+
+// ParseArtifactRetentions reads per-category retentions written as "<categoria>=<dias>", zero keeping the
+// files of the category for good
+func ParseArtifactRetentions(values []string) (map[string]time.Duration, error) {
+	days, err := parseArtifactLimits(values)
+	if err != nil {
+		return nil, err
+	}
+
+	retentions := make(map[string]time.Duration, len(days))
+	for category, value := range days {
+		retentions[category] = time.Duration(value) * 24 * time.Hour
+	}
+	return retentions, nil
+}
+
+// ParseArtifactQuotas reads per-category quotas written as "<categoria>=<MB>", zero lifting the quota
+func ParseArtifactQuotas(values []string) (map[string]int64, error) {
+	megabytes, err := parseArtifactLimits(values)
+	if err != nil {
+		return nil, err
+	}
+
+	quotas := make(map[string]int64, len(megabytes))
+	for category, value := range megabytes {
+		quotas[category] = value << 20
+	}
+	return quotas, nil
+}
+
+// parseArtifactLimits reads "<categoria>=<número>" entries
+func parseArtifactLimits(values []string) (map[string]int64, error) {
+	limits := make(map[string]int64, len(values))
+	for _, value := range values {
+		category, raw, found := strings.Cut(value, "=")
+		category = strings.Trim(strings.TrimSpace(category), "/")
+		limit, err := strconv.ParseInt(strings.TrimSpace(raw), 10, 64)
+		if !found || category == "" || strings.Contains(category, "/") || err != nil || limit < 0 {
+			return nil, fmt.Errorf("limite de artefato inválido: %s (use <categoria>=<número>)", value)
+		}
+		limits[category] = limit
+	}
+	return limits, nil
+}
+
+// artifactCategory returns the category of a key, its first segment
+func artifactCategory(key string) string {
+	category, _, _ := strings.Cut(key, "/")
+	return category
+}
+
+// CanLink reports whether signed retrieval links can be issued
+func (s *ArtifactService) CanLink() bool {
+	return s.policy.BaseURL != "" && s.policy.SigningKey != ""
+}
+
+// Link issues a signed retrieval link for a stored file, valid for the configured time
+func (s *ArtifactService) Link(artifact *domain.Artifact) (string, time.Time, error) {
+	if !s.CanLink() {
+		return "", time.Time{}, errors.New("links de artefatos desabilitados: ARTIFACT_BASE_URL e ARTIFACT_SIGNING_KEY não definidas")
+	}
+
+	expiresAt := s.clock.Now().Add(s.policy.LinkTTL).Truncate(time.Second)
+	if artifact.ExpiresAt != nil && artifact.ExpiresAt.Before(expiresAt) {
+		expiresAt = artifact.ExpiresAt.Truncate(time.Second)
+	}
+
+	expires := strconv.FormatInt(expiresAt.Unix(), 10)
+	query := url.Values{
+		"expires":   {expires},
+		"signature": {s.signature(artifact.Key, expires)},
+	}
+
+	link := s.policy.BaseURL + ArtifactRoutePrefix + (&url.URL{Path: artifact.Key}).EscapedPath() + "?" + query.Encode()
+	return link, expiresAt, nil
+}
+
+// VerifyLink checks the signature and expiry of a retrieval link
+func (s *ArtifactService) VerifyLink(key, expires, signature string) error {
+	if s.policy.SigningKey == "" {
+		return ErrArtifactLinkInvalid
+	}
+
+	expected := s.signature(key, expires)
+	if !hmac.Equal([]byte(expected), []byte(signature)) {
+		return ErrArtifactLinkInvalid
+	}
+
+	unix, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil {
+		return ErrArtifactLinkInvalid
+	}
+	if s.clock.Now().After(time.Unix(unix, 0)) {
+		return ErrArtifactLinkExpired
+	}
+
+	return nil
+}
+
+// signature authenticates a key and expiry with the signing key
+func (s *ArtifactService) signature(key, expires string) string {
+	mac := hmac.New(sha256.New, []byte(s.policy.SigningKey))
+	mac.Write([]byte(key + "\n" + expires))
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,372 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"provisioning-assistant/internal/clock"
+	"provisioning-assistant/internal/domain"
+	"provisioning-assistant/internal/ids"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// AuditCacheTTL bounds how stale a cached period listing can be
+const AuditCacheTTL = time.Minute
+
+var ErrInvalidAuditCursor = errors.New("cursor de auditoria inválido")
+
+// TroubleshootingStart is the answer that opens the guided diagnosis of an audit record
+const TroubleshootingStart = "start"
+
+type AuditService struct {
+	repository        domain.AuditRepository
+	sandboxRepository domain.AuditRepository
+	clock             clock.Clock
+	logger            domain.Logger
+
+	mu    sync.Mutex
+	cache *auditPeriodCache
+}
+
+// auditPeriodCache keeps the last period listing so repeated reports skip the full scan
+type auditPeriodCache struct {
+	since     time.Time
+	records   []*domain.AuditRecord
+	expiresAt time.Time
+}
+
+// NewAuditService creates a new audit service instance.
+// Training sessions keep their records apart in the sandbox repository, reports only read the real ones.
+func NewAuditService(repository, sandboxRepository domain.AuditRepository, clock clock.Clock, logger domain.Logger) *AuditService {
+	return &AuditService{
+		repository:        repository,
+		sandboxRepository: sandboxRepository,
+		clock:             clock,
+		logger:            logger,
+	}
+}
+
+// Record persists a provisioning audit record and returns it with its ID
+func (s *AuditService) Record(ctx context.Context, record *domain.AuditRecord) (*domain.AuditRecord, error) {
+	now := s.clock.Now()
+	record.CreatedAt = now
+	record.UpdatedAt = now
+
+	if err := s.repositoryFor(ctx).Save(ctx, record); err != nil {
+		s.logger.WithError(err).WithField("protocol", record.Protocol).Error("Falha ao gravar registro de auditoria")
+		return nil, fmt.Errorf("falha ao gravar registro de auditoria: %w", err)
+	}
+
+	s.invalidateCache()
+
+	return record, nil
+}
+
+// AttachPhoto adds a proof-of-installation photo to an existing audit record
+func (s *AuditService) AttachPhoto(ctx context.Context, auditID, fileID, caption string) (*domain.AuditRecord, error) {
+	record, err := s.repositoryFor(ctx).FindByID(ctx, auditID)
+	if err != nil {
+		return nil, fmt.Errorf("falha ao buscar registro de auditoria: %w", err)
+	}
+
+	record.Attachments = append(record.Attachments, domain.AuditAttachment{
+		FileID:    fileID,
+		Caption:   caption,
+		CreatedAt: s.clock.Now(),
+	})
+	record.UpdatedAt = s.clock.Now()
+
+	if err := s.repositoryFor(ctx).Save(ctx, record); err != nil {
+		return nil, fmt.Errorf("falha ao anexar foto ao registro de auditoria: %w", err)
+	}
+
+	s.invalidateCache()
+
+	s.logger.WithFields(map[string]any{
+		"audit_id": auditID,
+		"file_id":  fileID,
+	}).Info("Foto anexada ao registro de auditoria")
+
+	return record, nil
+}
+
+// RecordSpeedTest stores the speed test reported for the installation of an audit record
+func (s *AuditService) RecordSpeedTest(ctx context.Context, auditID string, test domain.SpeedTest) (*domain.AuditRecord, error) {
+	record, err := s.repositoryFor(ctx).FindByID(ctx, auditID)
+	if err != nil {
+		return nil, fmt.Errorf("falha ao buscar registro de auditoria: %w", err)
+	}
+
+	test.ReportedAt = s.clock.Now()
+	record.SpeedTest = &test
+	record.UpdatedAt = test.ReportedAt
+
+	if err := s.repositoryFor(ctx).Save(ctx, record); err != nil {
+		return nil, fmt.Errorf("falha ao registrar teste de velocidade no registro de auditoria: %w", err)
+	}
+
+	s.invalidateCache()
+
+	return record, nil
+}
+
+// RecordTroubleshooting stores an answer of the guided diagnosis of an audit record, starting it over on
+// "start" and closing it when an outcome is given
+func (s *AuditService) RecordTroubleshooting(ctx context.Context, auditID, answer, outcome string) (*domain.AuditRecord, error) {
+	record, err := s.repositoryFor(ctx).FindByID(ctx, auditID)
+	if err != nil {
+		return nil, fmt.Errorf("falha ao buscar registro de auditoria: %w", err)
+	}
+
+	now := s.clock.Now()
+	if answer == TroubleshootingStart || record.Troubleshooting == nil {
+		record.Troubleshooting = &domain.Troubleshooting{StartedAt: now}
+	}
+	if answer != TroubleshootingStart {
+		record.Troubleshooting.Answers = append(record.Troubleshooting.Answers, answer)
+	}
+	if outcome != "" {
+		record.Troubleshooting.Outcome = outcome
+		record.Troubleshooting.FinishedAt = &now
+	}
+	record.UpdatedAt = now
+
+	if err := s.repositoryFor(ctx).Save(ctx, record); err != nil {
+		return nil, fmt.Errorf("falha ao registrar diagnóstico no registro de auditoria: %w", err)
+	}
+
+	s.invalidateCache()
+
+	return record, nil
+}
+
+// MarkRestored records that the ONU of an audit record was rolled back to its previous configuration
+func (s *AuditService) MarkRestored(ctx context.Context, auditID, restoredBy string) (*domain.AuditRecord, error) {
+	record, err := s.repositoryFor(ctx).FindByID(ctx, auditID)
+	if err != nil {
+		return nil, fmt.Errorf("falha ao buscar registro de auditoria: %w", err)
+	}
+
+	now := s.clock.Now()
+	record.RestoredAt = &now
+	record.RestoredBy = restoredBy
+	record.UpdatedAt = now
+
+	if err := s.repositoryFor(ctx).Save(ctx, record); err != nil {
+		return nil, fmt.Errorf("falha ao registrar reversão no registro de auditoria: %w", err)
+	}
+
+	s.invalidateCache()
+
+	return record, nil
+}
+
+// MarkRemoved records that the ONU of an audit record was removed from its OLT, as left behind by a swap or
+// by a cancelled contract
+func (s *AuditService) MarkRemoved(ctx context.Context, auditID, removedBy string) (*domain.AuditRecord, error) {
+	record, err := s.repositoryFor(ctx).FindByID(ctx, auditID)
+	if err != nil {
+		return nil, fmt.Errorf("falha ao buscar registro de auditoria: %w", err)
+	}
+
+	now := s.clock.Now()
+	record.RemovedAt = &now
+	record.RemovedBy = removedBy
+	record.UpdatedAt = now
+
+	if err := s.repositoryFor(ctx).Save(ctx, record); err != nil {
+		return nil, fmt.Errorf("falha ao registrar remoção no registro de auditoria: %w", err)
+	}
+
+	s.invalidateCache()
+
+	return record, nil
+}
+
+// AppendCommands adds TL1 commands sent for the ONU of an audit record after the job, such as a rollback
+func (s *AuditService) AppendCommands(ctx context.Context, auditID string, commands []domain.Tl1Command) error {
+	if len(commands) == 0 {
+		return nil
+	}
+
+	record, err := s.repositoryFor(ctx).FindByID(ctx, auditID)
+	if err != nil {
+		return fmt.Errorf("falha ao buscar registro de auditoria: %w", err)
+	}
+
+	record.Commands = append(record.Commands, commands...)
+	record.UpdatedAt = s.clock.Now()
+
+	if err := s.repositoryFor(ctx).Save(ctx, record); err != nil {
+		return fmt.Errorf("falha ao registrar comandos TL1 no registro de auditoria: %w", err)
+	}
+
+	s.invalidateCache()
+
+	return nil
+}
+
+// GetRecord retrieves a single audit record
+func (s *AuditService) GetRecord(ctx context.Context, auditID string) (*domain.AuditRecord, error) {
+	return s.repositoryFor(ctx).FindByID(ctx, auditID)
+}
+
+// FindLatestBySerial returns the most recent successful audit record of an ONU serial
+func (s *AuditService) FindLatestBySerial(ctx context.Context, serial string) (*domain.AuditRecord, error) {
+	records, err := s.repositoryFor(ctx).List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var latest *domain.AuditRecord
+	for _, record := range records {
+		if !record.Success || !strings.EqualFold(record.Serial, serial) {
+			continue
+		}
+		if latest == nil || record.CreatedAt.After(latest.CreatedAt) {
+			latest = record
+		}
+	}
+
+	if latest == nil {
+		return nil, fmt.Errorf("nenhum provisionamento encontrado para o serial %s", serial)
+	}
+
+	return latest, nil
+}
+
+// FindLatestByProtocol returns the most recent successful audit record of an ERP protocol
+func (s *AuditService) FindLatestByProtocol(ctx context.Context, protocol string) (*domain.AuditRecord, error) {
+	records, err := s.repositoryFor(ctx).List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var latest *domain.AuditRecord
+	for _, record := range records {
+		if !record.Success || record.Protocol == "" || record.Protocol != protocol {
+			continue
+		}
+		if latest == nil || record.CreatedAt.After(latest.CreatedAt) {
+			latest = record
+		}
+	}
+
+	if latest == nil {
+		return nil, fmt.Errorf("nenhum provisionamento encontrado para o protocolo %s", protocol)
+	}
+
+	return latest, nil
+}
+
+// FindLatestByContract returns the most recent successful audit record of a contract, the ONU the bot last
+// activated for it
+func (s *AuditService) FindLatestByContract(ctx context.Context, contract string) (*domain.AuditRecord, error) {
+	records, err := s.repositoryFor(ctx).List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var latest *domain.AuditRecord
+	for _, record := range records {
+		if !record.Success || record.Contract == "" || record.Contract != contract {
+			continue
+		}
+		if latest == nil || record.CreatedAt.After(latest.CreatedAt) {
+			latest = record
+		}
+	}
+
+	if latest == nil {
+		return nil, fmt.Errorf("nenhum provisionamento encontrado para o contrato %s", contract)
+	}
+
+	return latest, nil
+}
+
+// ListRecords retrieves all audit records
+func (s *AuditService) ListRecords(ctx context.Context) ([]*domain.AuditRecord, error) {
+	return s.repository.List(ctx)
+}
+
+// ListAfter returns up to limit audit records created after the one with the cursor ID, oldest first.
+// A cursor no longer stored, such as an archived record, resumes from the time encoded in its ULID.
+func (s *AuditService) ListAfter(ctx context.Context, after string, limit int) ([]*domain.AuditRecord, error) {
+	records, err := s.repository.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if after != "" {
+		after = ids.Normalize(after)
+		index := slices.IndexFunc(records, func(record *domain.AuditRecord) bool { return record.ID == after })
+		if index < 0 {
+			issuedAt, ok := ids.Time(after)
+			if !ok {
+				return nil, fmt.Errorf("%w: %s", ErrInvalidAuditCursor, after)
+			}
+			index = slices.IndexFunc(records, func(record *domain.AuditRecord) bool { return record.CreatedAt.After(issuedAt) }) - 1
+			if index < -1 {
+				index = len(records) - 1
+			}
+		}
+		records = records[index+1:]
+	}
+
+	if limit > 0 && len(records) > limit {
+		records = records[:limit]
+	}
+	return records, nil
+}
+
+// ListSince returns the audit records created since the given time ordered by creation, reusing a short-lived cache
+func (s *AuditService) ListSince(ctx context.Context, since time.Time) ([]*domain.AuditRecord, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if c := s.cache; c != nil && c.since.Equal(since) && s.clock.Now().Before(c.expiresAt) {
+		return c.records, nil
+	}
+
+	records, err := s.repository.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var period []*domain.AuditRecord
+	for _, record := range records {
+		if !record.CreatedAt.Before(since) {
+			period = append(period, record)
+		}
+	}
+
+	slices.SortFunc(period, func(a, b *domain.AuditRecord) int {
+		return a.CreatedAt.Compare(b.CreatedAt)
+	})
+
+	s.cache = &auditPeriodCache{
+		since:     since,
+		records:   period,
+		expiresAt: s.clock.Now().Add(AuditCacheTTL),
+	}
+
+	return period, nil
+}
+
+// repositoryFor returns the audit repository for the context, the sandbox one for training sessions
+func (s *AuditService) repositoryFor(ctx context.Context) domain.AuditRepository {
+	if domain.IsTraining(ctx) && s.sandboxRepository != nil {
+		return s.sandboxRepository
+	}
+	return s.repository
+}
+
+// invalidateCache drops the cached period listing after a change
+func (s *AuditService) invalidateCache() {
+	s.mu.Lock()
+	s.cache = nil
+	s.mu.Unlock()
+}
@@ -0,0 +1,369 @@
+package services
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path"
+	"provisioning-assistant/internal/domain"
+	"slices"
+	"time"
+)
+
+const (
+	BackupMagic          = "PABK1"
+	BackupFileExtension  = ".pabk"
+	BackupSaltBytes      = 16
+	BackupKeyBytes       = 32
+	BackupKeyIterations  = 600_000
+	BackupFileTimeLayout = "20060102-150405"
+	BackupArtifactPrefix = "backups/"
+)
+
+// backupStateNamespaces are the state namespaces migrated with a backup. Short-lived state stays behind: the
+// idempotency records, the ERP event IDs, the alerts awaiting acknowledgement, the last jobs, the work order
+// greetings and the Telegram update offset.
+var backupStateNamespaces = []string{
+	featureNamespace,
+	rolloutNamespace,
+	planTemplateNamespace,
+	maintenanceNamespace,
+	ponCapacityNamespace,
+	feedbackNamespace,
+	trainingNamespace,
+	consoleNamespace,
+	orphanOnuNamespace,
+}
+
+var (
+	ErrBackupDisabled      = errors.New("backup desabilitado: BACKUP_PASSPHRASE não definida")
+	ErrBackupInvalid       = errors.New("arquivo de backup inválido")
+	ErrBackupDecryption    = errors.New("falha ao descriptografar backup: senha incorreta ou arquivo corrompido")
+	ErrBackupVersion       = errors.New("versão de backup não suportada")
+	ErrBackupPathForbidden = errors.New("nome de arquivo de backup inválido")
+)
+
+type BackupService struct {
+	auditRepository   domain.AuditRepository
+	bindingRepository domain.BindingRepository
+	tokenRepository   domain.TokenRepository
+	stateRepository   domain.StateRepository
+	templates         *PlanTemplateService
+	artifacts         *ArtifactService
+	passphrase        string
+	logger            domain.Logger
+}
+
+// NewBackupService creates a new encrypted backup service for the bot-owned tables and state, the plan
+// templates in use being reloaded after a restore
+func NewBackupService(
+	auditRepository domain.AuditRepository,
+	bindingRepository domain.BindingRepository,
+	tokenRepository domain.TokenRepository,
+	stateRepository domain.StateRepository,
+	templates *PlanTemplateService,
+	artifacts *ArtifactService,
+	passphrase string,
+	logger domain.Logger,
+) *BackupService {
+	return &BackupService{
+		auditRepository:   auditRepository,
+		bindingRepository: bindingRepository,
+		tokenRepository:   tokenRepository,
+		stateRepository:   stateRepository,
+		templates:         templates,
+		artifacts:         artifacts,
+		passphrase:        passphrase,
+		logger:            logger,
+	}
+}
+
+// IsEnabled reports whether a passphrase is configured for backups
+func (s *BackupService) IsEnabled() bool {
+	return s.passphrase != ""
+}
+
+// Export stores an encrypted archive of the bot tables in the artifact store
+func (s *BackupService) Export(ctx context.Context) (*domain.Artifact, *domain.Backup, error) {
+	if !s.IsEnabled() {
+		return nil, nil, ErrBackupDisabled
+	}
+
+	backup, err := s.snapshot(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	archive, err := s.encrypt(backup)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	key := BackupArtifactPrefix + "backup-" + backup.CreatedAt.Format(BackupFileTimeLayout) + BackupFileExtension
+	artifact, err := s.artifacts.Save(ctx, key, "application/octet-stream", bytes.NewReader(archive), s.artifacts.Retention())
+	if err != nil {
+		return nil, nil, fmt.Errorf("falha ao gravar backup: %w", err)
+	}
+
+	s.logger.WithFields(map[string]any{
+		"file":     artifact.Key,
+		"bindings": len(backup.Bindings),
+		"audits":   len(backup.Audits),
+		"tokens":   len(backup.Tokens),
+		"state":    backup.StateEntries(),
+	}).Info("Backup exportado")
+
+	return artifact, backup, nil
+}
+
+// Restore imports a backup previously exported to the artifact store
+func (s *BackupService) Restore(ctx context.Context, name string) (*domain.Backup, error) {
+	if path.Base(name) != name || path.Ext(name) != BackupFileExtension {
+		return nil, ErrBackupPathForbidden
+	}
+
+	if !s.IsEnabled() {
+		return nil, ErrBackupDisabled
+	}
+
+	reader, _, err := s.artifacts.Open(ctx, BackupArtifactPrefix+name)
+	if err != nil {
+		return nil, fmt.Errorf("falha ao ler backup: %w", err)
+	}
+	defer reader.Close()
+
+	archive, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("falha ao ler backup: %w", err)
+	}
+
+	return s.restore(ctx, archive, name)
+}
+
+// RestoreFile imports a backup from any path on disk, used to seed a fresh installation
+func (s *BackupService) RestoreFile(ctx context.Context, path string) (*domain.Backup, error) {
+	if !s.IsEnabled() {
+		return nil, ErrBackupDisabled
+	}
+
+	archive, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("falha ao ler backup: %w", err)
+	}
+
+	return s.restore(ctx, archive, path)
+}
+
+// restore decrypts an archive and upserts its records into the repositories
+func (s *BackupService) restore(ctx context.Context, archive []byte, source string) (*domain.Backup, error) {
+	backup, err := s.decrypt(archive)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.apply(ctx, backup); err != nil {
+		return nil, err
+	}
+
+	s.logger.WithFields(map[string]any{
+		"file":     source,
+		"bindings": len(backup.Bindings),
+		"audits":   len(backup.Audits),
+		"tokens":   len(backup.Tokens),
+		"state":    backup.StateEntries(),
+	}).Info("Backup restaurado")
+
+	return backup, nil
+}
+
+// snapshot collects the current content of every bot table
+func (s *BackupService) snapshot(ctx context.Context) (*domain.Backup, error) {
+	bindings, err := s.bindingRepository.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("falha ao listar vínculos: %w", err)
+	}
+
+	audits, err := s.auditRepository.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("falha ao listar auditoria: %w", err)
+	}
+
+	tokens, err := s.tokenRepository.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("falha ao listar tokens: %w", err)
+	}
+
+	backup := &domain.Backup{
+		Version:   domain.BackupVersion,
+		CreatedAt: time.Now().UTC(),
+		Bindings:  bindings,
+		Audits:    audits,
+		Tokens:    make([]*domain.BackupToken, 0, len(tokens)),
+		State:     make(map[string]map[string]string),
+	}
+
+	for _, namespace := range backupStateNamespaces {
+		values, err := s.stateRepository.List(ctx, namespace)
+		if err != nil {
+			return nil, fmt.Errorf("falha ao listar estado %s: %w", namespace, err)
+		}
+
+		// An empty value stands for a cleared key
+		maps.DeleteFunc(values, func(_, value string) bool { return value == "" })
+		if len(values) > 0 {
+			backup.State[namespace] = values
+		}
+	}
+
+	for _, token := range tokens {
+		backup.Tokens = append(backup.Tokens, &domain.BackupToken{
+			ServiceAccountToken: token,
+			TokenHash:           token.TokenHash,
+		})
+	}
+
+	return backup, nil
+}
+
+// apply upserts the backup records, keeping their original identifiers
+func (s *BackupService) apply(ctx context.Context, backup *domain.Backup) error {
+	for _, binding := range backup.Bindings {
+		if err := s.bindingRepository.Save(ctx, binding); err != nil {
+			return fmt.Errorf("falha ao restaurar vínculo %d: %w", binding.UserID, err)
+		}
+	}
+
+	for _, record := range backup.Audits {
+		if err := s.auditRepository.Save(ctx, record); err != nil {
+			return fmt.Errorf("falha ao restaurar auditoria %s: %w", record.ID, err)
+		}
+	}
+
+	for _, token := range backup.Tokens {
+		if token.ServiceAccountToken == nil {
+			return ErrBackupInvalid
+		}
+		token.ServiceAccountToken.TokenHash = token.TokenHash
+		if err := s.tokenRepository.Save(ctx, token.ServiceAccountToken); err != nil {
+			return fmt.Errorf("falha ao restaurar token %s: %w", token.ID, err)
+		}
+	}
+
+	for namespace, values := range backup.State {
+		if !slices.Contains(backupStateNamespaces, namespace) {
+			s.logger.WithField("namespace", namespace).Warn("Estado desconhecido no backup ignorado")
+			continue
+		}
+
+		for key, value := range values {
+			if err := s.stateRepository.Set(ctx, namespace, key, value); err != nil {
+				return fmt.Errorf("falha ao restaurar estado %s/%s: %w", namespace, key, err)
+			}
+		}
+	}
+
+	// The templates in use are kept in memory, the restored catalog replaces them right away
+	if _, restored := backup.State[planTemplateNamespace]; restored && s.templates != nil {
+		if err := s.templates.Restore(ctx); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// encrypt compresses the backup and seals it with AES-256-GCM using a passphrase derived key
+func (s *BackupService) encrypt(backup *domain.Backup) ([]byte, error) {
+	var plain bytes.Buffer
+	writer := gzip.NewWriter(&plain)
+	if err := json.NewEncoder(writer).Encode(backup); err != nil {
+		return nil, fmt.Errorf("falha ao serializar backup: %w", err)
+	}
+	if err := writer.Close(); err != nil {
+		return nil, fmt.Errorf("falha ao compactar backup: %w", err)
+	}
+
+	salt := make([]byte, BackupSaltBytes)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+
+	aead, err := s.cipher(salt)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	header := append(append([]byte(BackupMagic), salt...), nonce...)
+	return aead.Seal(header, nonce, plain.Bytes(), []byte(BackupMagic)), nil
+}
+
+// decrypt opens an archive produced by encrypt and decodes its content
+func (s *BackupService) decrypt(archive []byte) (*domain.Backup, error) {
+	if len(archive) < len(BackupMagic)+BackupSaltBytes || string(archive[:len(BackupMagic)]) != BackupMagic {
+		return nil, ErrBackupInvalid
+	}
+
+	archive = archive[len(BackupMagic):]
+	aead, err := s.cipher(archive[:BackupSaltBytes])
+	if err != nil {
+		return nil, err
+	}
+
+	archive = archive[BackupSaltBytes:]
+	if len(archive) < aead.NonceSize() {
+		return nil, ErrBackupInvalid
+	}
+
+	plain, err := aead.Open(nil, archive[:aead.NonceSize()], archive[aead.NonceSize():], []byte(BackupMagic))
+	if err != nil {
+		return nil, ErrBackupDecryption
+	}
+
+	reader, err := gzip.NewReader(bytes.NewReader(plain))
+	if err != nil {
+		return nil, ErrBackupInvalid
+	}
+	defer reader.Close()
+
+	var backup domain.Backup
+	if err := json.NewDecoder(io.LimitReader(reader, 512<<20)).Decode(&backup); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrBackupInvalid, err)
+	}
+
+	if backup.Version != domain.BackupVersion {
+		return nil, fmt.Errorf("%w: %d", ErrBackupVersion, backup.Version)
+	}
+
+	return &backup, nil
+}
+
+// cipher derives the archive key from the passphrase and salt
+func (s *BackupService) cipher(salt []byte) (cipher.AEAD, error) {
+	key, err := pbkdf2.Key(sha256.New, s.passphrase, salt, BackupKeyIterations, BackupKeyBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
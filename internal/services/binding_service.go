@@ -0,0 +1,215 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"provisioning-assistant/internal/domain"
+	"time"
+)
+
+type BindingService struct {
+	repository domain.BindingRepository
+	logger     domain.Logger
+}
+
+// NewBindingService creates a new user binding service instance
+func NewBindingService(repository domain.BindingRepository, logger domain.Logger) *BindingService {
+	return &BindingService{
+		repository: repository,
+		logger:     logger,
+	}
+}
+
+// Get retrieves the binding of a Telegram user, returning nil when absent
+func (s *BindingService) Get(ctx context.Context, userID int64) *domain.Binding {
+	binding, err := s.repository.FindByUserID(ctx, userID)
+	if err != nil {
+		return nil
+	}
+	return binding
+}
+
+// FindByTaxID retrieves the binding of the technician with the given CPF, returning nil when absent
+func (s *BindingService) FindByTaxID(ctx context.Context, taxID string) *domain.Binding {
+	if taxID == "" {
+		return nil
+	}
+
+	bindings, err := s.repository.List(ctx)
+	if err != nil {
+		return nil
+	}
+
+	for _, binding := range bindings {
+		if binding.TaxID == taxID {
+			return binding
+		}
+	}
+	return nil
+}
+
+// HasConsent reports whether the user accepted the given privacy notice version of the tenant
+func (s *BindingService) HasConsent(ctx context.Context, userID int64, tenant, version string) bool {
+	binding := s.Get(ctx, userID)
+	return binding != nil && binding.HasConsent(tenant, version)
+}
+
+// RecordConsent stores a timestamped consent for the privacy notice version of the tenant, keeping the
+// consents given to other tenants and earlier versions
+func (s *BindingService) RecordConsent(ctx context.Context, userID, chatID int64, tenant, version string) error {
+	return s.update(ctx, userID, chatID, func(binding *domain.Binding) {
+		if binding.HasConsent(tenant, version) {
+			return
+		}
+
+		binding.Consents = append(binding.Consents, domain.Consent{
+			Tenant:  tenant,
+			Version: version,
+			At:      time.Now(),
+		})
+	})
+}
+
+// Bind links the Telegram user to the authenticated technician
+func (s *BindingService) Bind(ctx context.Context, userID, chatID int64, taxID, name string) error {
+	return s.update(ctx, userID, chatID, func(binding *domain.Binding) {
+		binding.TaxID = taxID
+		binding.Name = name
+	})
+}
+
+// UpdateProfile stores the Telegram profile of the user, keeping fields not present in the update
+func (s *BindingService) UpdateProfile(ctx context.Context, userID, chatID int64, profile domain.TelegramProfile) error {
+	return s.update(ctx, userID, chatID, func(binding *domain.Binding) {
+		current := domain.TelegramProfile{}
+		if binding.Profile != nil {
+			current = *binding.Profile
+		}
+
+		merged := current.Merge(profile)
+		binding.Profile = &merged
+	})
+}
+
+// MarkVersionSeen records the bot version whose changes the user was shown
+func (s *BindingService) MarkVersionSeen(ctx context.Context, userID, chatID int64, version string) error {
+	return s.update(ctx, userID, chatID, func(binding *domain.Binding) {
+		binding.SeenVersion = version
+	})
+}
+
+// Preferences returns the message preferences of the user, the defaults when unbound
+func (s *BindingService) Preferences(ctx context.Context, userID int64) domain.Preferences {
+	binding := s.Get(ctx, userID)
+	if binding == nil {
+		return domain.Preferences{}
+	}
+	return binding.Preferences
+}
+
+// UpdatePreferences applies a change to the message preferences of the user and returns them
+func (s *BindingService) UpdatePreferences(ctx context.Context, userID, chatID int64, apply func(*domain.Preferences)) (domain.Preferences, error) {
+	var preferences domain.Preferences
+	err := s.update(ctx, userID, chatID, func(binding *domain.Binding) {
+		apply(&binding.Preferences)
+		preferences = binding.Preferences
+	})
+	return preferences, err
+}
+
+// MarkChatBlocked flags the bindings of a chat that blocked the bot, returning how many were affected
+func (s *BindingService) MarkChatBlocked(ctx context.Context, chatID int64) (int, error) {
+	now := time.Now()
+	return s.updateChat(ctx, chatID, func(binding *domain.Binding) {
+		if binding.BlockedAt == nil {
+			binding.BlockedAt = &now
+		}
+	})
+}
+
+// UnbindAll detaches every Telegram account from its technician, keeping consent and profile,
+// and returns how many bindings were detached
+func (s *BindingService) UnbindAll(ctx context.Context) (int, error) {
+	bindings, err := s.repository.List(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("falha ao listar vínculos: %w", err)
+	}
+
+	unbound := 0
+	for _, binding := range bindings {
+		if binding.TaxID == "" {
+			continue
+		}
+
+		binding.TaxID = ""
+		binding.Name = ""
+		binding.UpdatedAt = time.Now()
+
+		if err := s.repository.Save(ctx, binding); err != nil {
+			s.logger.WithError(err).WithField("user_id", binding.UserID).Error("Falha ao salvar vínculo do usuário")
+			return unbound, fmt.Errorf("falha ao salvar vínculo do usuário: %w", err)
+		}
+		unbound++
+	}
+
+	return unbound, nil
+}
+
+// MigrateChat moves the bindings of a group to the supergroup it migrated to
+func (s *BindingService) MigrateChat(ctx context.Context, from, to int64) (int, error) {
+	return s.updateChat(ctx, from, func(binding *domain.Binding) {
+		binding.ChatID = to
+	})
+}
+
+// updateChat applies a change to every binding of a chat
+func (s *BindingService) updateChat(ctx context.Context, chatID int64, apply func(*domain.Binding)) (int, error) {
+	bindings, err := s.repository.List(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("falha ao listar vínculos: %w", err)
+	}
+
+	updated := 0
+	for _, binding := range bindings {
+		if binding.ChatID != chatID {
+			continue
+		}
+
+		apply(binding)
+		binding.UpdatedAt = time.Now()
+
+		if err := s.repository.Save(ctx, binding); err != nil {
+			s.logger.WithError(err).WithField("user_id", binding.UserID).Error("Falha ao salvar vínculo do usuário")
+			return updated, fmt.Errorf("falha ao salvar vínculo do usuário: %w", err)
+		}
+		updated++
+	}
+
+	return updated, nil
+}
+
+// update loads or creates the binding, applies the change and saves it
+func (s *BindingService) update(ctx context.Context, userID, chatID int64, apply func(*domain.Binding)) error {
+	now := time.Now()
+
+	binding := s.Get(ctx, userID)
+	if binding == nil {
+		binding = &domain.Binding{
+			UserID:    userID,
+			CreatedAt: now,
+		}
+	}
+
+	// Any interaction proves the chat reaches the bot again
+	binding.ChatID = chatID
+	binding.BlockedAt = nil
+	binding.UpdatedAt = now
+	apply(binding)
+
+	if err := s.repository.Save(ctx, binding); err != nil {
+		s.logger.WithError(err).WithField("user_id", userID).Error("Falha ao salvar vínculo do usuário")
+		return fmt.Errorf("falha ao salvar vínculo do usuário: %w", err)
+	}
+
+	return nil
+}
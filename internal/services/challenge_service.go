@@ -0,0 +1,100 @@
+package services
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"provisioning-assistant/internal/clock"
+	"slices"
+	"sync"
+	"time"
+)
+
+const (
+	ChallengeOptions  = 4
+	ChallengeMaxValue = 9
+	ChallengeValidity = 24 * time.Hour
+)
+
+// Challenge is an arithmetic anti-bot question answered by tapping a button
+type Challenge struct {
+	Question string
+	Options  []int
+}
+
+type ChallengeService struct {
+	enabled  bool
+	pending  map[int64]int
+	verified map[int64]time.Time
+	clock    clock.Clock
+	mu       sync.Mutex
+}
+
+// NewChallengeService creates a new anti-bot challenge service instance
+func NewChallengeService(enabled bool, clock clock.Clock) *ChallengeService {
+	return &ChallengeService{
+		enabled:  enabled,
+		clock:    clock,
+		pending:  make(map[int64]int),
+		verified: make(map[int64]time.Time),
+	}
+}
+
+// IsRequired reports whether the user must solve a challenge before CPF entry
+func (s *ChallengeService) IsRequired(userID int64) bool {
+	if !s.enabled {
+		return false
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	verifiedAt, exists := s.verified[userID]
+	return !exists || s.clock.Since(verifiedAt) > ChallengeValidity
+}
+
+// NewChallenge generates a new challenge for the user, replacing any pending one
+func (s *ChallengeService) NewChallenge(userID int64) *Challenge {
+	a := rand.IntN(ChallengeMaxValue) + 1
+	b := rand.IntN(ChallengeMaxValue) + 1
+	answer := a + b
+
+	options := []int{answer}
+	for len(options) < ChallengeOptions {
+		candidate := rand.IntN(ChallengeMaxValue*2) + 2
+		if !slices.Contains(options, candidate) {
+			options = append(options, candidate)
+		}
+	}
+	rand.Shuffle(len(options), func(i, j int) {
+		options[i], options[j] = options[j], options[i]
+	})
+
+	s.mu.Lock()
+	s.pending[userID] = answer
+	s.mu.Unlock()
+
+	return &Challenge{
+		Question: fmt.Sprintf("%d + %d", a, b),
+		Options:  options,
+	}
+}
+
+// Verify checks the answer for the pending challenge, consuming it
+func (s *ChallengeService) Verify(userID int64, answer int) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	expected, exists := s.pending[userID]
+	if !exists {
+		return false
+	}
+
+	delete(s.pending, userID)
+
+	if answer != expected {
+		return false
+	}
+
+	s.verified[userID] = s.clock.Now()
+	return true
+}
@@ -0,0 +1,70 @@
+package services
+
+import (
+	"context"
+	"provisioning-assistant/internal/buildinfo"
+	"provisioning-assistant/internal/domain"
+)
+
+type ChangelogService struct {
+	version        string
+	releases       []buildinfo.Release
+	bindingService *BindingService
+	logger         domain.Logger
+}
+
+// NewChangelogService creates the service telling each user once what changed in the running version
+func NewChangelogService(version string, releases []buildinfo.Release, bindingService *BindingService, logger domain.Logger) *ChangelogService {
+	return &ChangelogService{
+		version:        version,
+		releases:       releases,
+		bindingService: bindingService,
+		logger:         logger,
+	}
+}
+
+// IsEnabled reports whether the running build is a release, development builds announce nothing
+func (s *ChangelogService) IsEnabled() bool {
+	_, ok := buildinfo.CompareVersions(s.version, s.version)
+	return ok
+}
+
+// TakeUnseen returns the releases the user has not been shown up to the running version, newest first,
+// and records the running version as seen. A user who never saw any version is only told about the
+// running one.
+func (s *ChangelogService) TakeUnseen(ctx context.Context, userID, chatID int64) []buildinfo.Release {
+	if !s.IsEnabled() {
+		return nil
+	}
+
+	seen := ""
+	if binding := s.bindingService.Get(ctx, userID); binding != nil {
+		seen = binding.SeenVersion
+	}
+	if seen == s.version {
+		return nil
+	}
+
+	var unseen []buildinfo.Release
+	for _, release := range s.releases {
+		if newer, ok := buildinfo.CompareVersions(release.Version, s.version); !ok || newer > 0 {
+			continue
+		}
+
+		if seen == "" {
+			if release.Version == s.version {
+				unseen = append(unseen, release)
+			}
+			continue
+		}
+
+		if older, ok := buildinfo.CompareVersions(release.Version, seen); ok && older > 0 {
+			unseen = append(unseen, release)
+		}
+	}
+
+	if err := s.bindingService.MarkVersionSeen(ctx, userID, chatID, s.version); err != nil {
+		s.logger.WithError(err).WithField("user_id", userID).Warn("Falha ao registrar versão vista pelo usuário")
+	}
+	return unseen
+}
@@ -0,0 +1,171 @@
+package services
+
+import (
+	"provisioning-assistant/internal/clock"
+	"sync"
+	"time"
+)
+
+const (
+	// Provisioning circuit defaults
+	DefaultCircuitWindow         = 30 * time.Minute
+	DefaultCircuitMinSamples     = 5
+	DefaultCircuitMinSuccessRate = 0.5
+	DefaultCircuitCooldown       = 15 * time.Minute
+	CircuitRecoverySuccesses     = 2
+)
+
+// CircuitPolicy defines when automatic provisioning is suspended
+type CircuitPolicy struct {
+	Window         time.Duration
+	MinSamples     int
+	MinSuccessRate float64
+	Cooldown       time.Duration
+}
+
+// CircuitTransition reports a change of the circuit state caused by an outcome
+type CircuitTransition int
+
+const (
+	CircuitUnchanged CircuitTransition = iota
+	CircuitTripped
+	CircuitRecovered
+)
+
+type outcome struct {
+	success bool
+	at      time.Time
+}
+
+// ProvisioningCircuitService tracks the provisioning success rate and suspends
+// automatic provisioning when it drops below the policy threshold
+type ProvisioningCircuitService struct {
+	policy CircuitPolicy
+	clock  clock.Clock
+
+	mu                  sync.Mutex
+	outcomes            []outcome
+	open                bool
+	openedAt            time.Time
+	recoverySuccesses   int
+	lastTripSuccessRate float64
+}
+
+// NewProvisioningCircuitService creates a new success-rate circuit, zero values fall back to defaults
+func NewProvisioningCircuitService(policy CircuitPolicy, clock clock.Clock) *ProvisioningCircuitService {
+	if policy.Window <= 0 {
+		policy.Window = DefaultCircuitWindow
+	}
+	if policy.MinSamples <= 0 {
+		policy.MinSamples = DefaultCircuitMinSamples
+	}
+	if policy.MinSuccessRate <= 0 || policy.MinSuccessRate > 1 {
+		policy.MinSuccessRate = DefaultCircuitMinSuccessRate
+	}
+	if policy.Cooldown <= 0 {
+		policy.Cooldown = DefaultCircuitCooldown
+	}
+
+	return &ProvisioningCircuitService{policy: policy, clock: clock}
+}
+
+// Record registers a provisioning outcome and returns the resulting state transition
+func (s *ProvisioningCircuitService) Record(success bool) CircuitTransition {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.clock.Now()
+
+	if s.open {
+		if !success {
+			s.recoverySuccesses = 0
+			s.openedAt = now
+			return CircuitUnchanged
+		}
+
+		s.recoverySuccesses++
+		if s.recoverySuccesses < CircuitRecoverySuccesses {
+			return CircuitUnchanged
+		}
+
+		s.open = false
+		s.outcomes = nil
+		s.recoverySuccesses = 0
+		return CircuitRecovered
+	}
+
+	s.outcomes = append(s.outcomes, outcome{success: success, at: now})
+	s.prune(now)
+
+	if len(s.outcomes) < s.policy.MinSamples {
+		return CircuitUnchanged
+	}
+
+	rate := s.successRate()
+	if rate >= s.policy.MinSuccessRate {
+		return CircuitUnchanged
+	}
+
+	s.open = true
+	s.openedAt = now
+	s.recoverySuccesses = 0
+	s.lastTripSuccessRate = rate
+	return CircuitTripped
+}
+
+// IsOpen reports whether automatic provisioning is suspended, trial runs are
+// allowed again once the cooldown has elapsed
+func (s *ProvisioningCircuitService) IsOpen() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.open && s.clock.Since(s.openedAt) < s.policy.Cooldown
+}
+
+// IsTripped reports whether the circuit has not recovered yet, including trial periods
+func (s *ProvisioningCircuitService) IsTripped() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.open
+}
+
+// SuccessRate returns the success rate of the current window, or the rate that tripped the circuit
+func (s *ProvisioningCircuitService) SuccessRate() float64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.open {
+		return s.lastTripSuccessRate
+	}
+
+	s.prune(s.clock.Now())
+	return s.successRate()
+}
+
+// prune drops outcomes outside the sliding window
+func (s *ProvisioningCircuitService) prune(now time.Time) {
+	cutoff := now.Add(-s.policy.Window)
+
+	i := 0
+	for i < len(s.outcomes) && s.outcomes[i].at.Before(cutoff) {
+		i++
+	}
+	s.outcomes = s.outcomes[i:]
+}
+
+// successRate computes the ratio of successful outcomes in the window
+func (s *ProvisioningCircuitService) successRate() float64 {
+	if len(s.outcomes) == 0 {
+		return 1
+	}
+
+	successes := 0
+	for _, o := range s.outcomes {
+		if o.success {
+			successes++
+		}
+	}
+
+	return float64(successes) / float64(len(s.outcomes))
+}
@@ -0,0 +1,115 @@
+package services
+
+import (
+	"context"
+	"maps"
+	"provisioning-assistant/internal/domain"
+	"provisioning-assistant/internal/unm"
+	"slices"
+	"time"
+)
+
+// DefaultCredentialWarnDays is how long before the password expiry the admins start being warned
+const DefaultCredentialWarnDays = 7
+
+// CredentialEndpoint opens a dedicated UNM client for the credential check of an endpoint
+type CredentialEndpoint func() (*unm.UNMClient, error)
+
+// CredentialPolicy configures when a credential check result becomes an alert
+type CredentialPolicy struct {
+	// ExpiresAt is the known password expiry date, for UNMs that do not announce it on login
+	ExpiresAt time.Time
+	WarnDays  int
+}
+
+// CredentialCheck is the result of the credential check of one endpoint
+type CredentialCheck struct {
+	Endpoint string
+	Status   *unm.CredentialStatus
+	Err      error
+
+	// ExpiresInDays merges the days announced by the UNM with the configured expiry date, -1 when unknown
+	ExpiresInDays int
+	Warning       bool
+}
+
+type CredentialCheckService struct {
+	endpoints map[string]CredentialEndpoint
+	policy    CredentialPolicy
+	logger    domain.Logger
+}
+
+// NewCredentialCheckService creates the daily verification of the UNM account credentials
+func NewCredentialCheckService(endpoints map[string]CredentialEndpoint, policy CredentialPolicy, logger domain.Logger) *CredentialCheckService {
+	if policy.WarnDays <= 0 {
+		policy.WarnDays = DefaultCredentialWarnDays
+	}
+
+	return &CredentialCheckService{
+		endpoints: endpoints,
+		policy:    policy,
+		logger:    logger,
+	}
+}
+
+// IsEnabled reports whether any endpoint can be checked
+func (s *CredentialCheckService) IsEnabled() bool {
+	return len(s.endpoints) > 0
+}
+
+// Check logs into every endpoint once and reports failures and passwords close to expiring
+func (s *CredentialCheckService) Check(ctx context.Context) []CredentialCheck {
+	results := make([]CredentialCheck, 0, len(s.endpoints))
+
+	for _, name := range slices.Sorted(maps.Keys(s.endpoints)) {
+		result := s.checkEndpoint(ctx, name)
+		results = append(results, result)
+
+		log := s.logger.WithFields(map[string]any{
+			"endpoint":        name,
+			"expires_in_days": result.ExpiresInDays,
+		})
+
+		switch {
+		case result.Err != nil:
+			log.WithError(result.Err).Error("Falha na verificação das credenciais do UNM")
+		case result.Warning:
+			log.Warn("Senha do UNM próxima de expirar")
+		default:
+			log.Info("Credenciais do UNM verificadas")
+		}
+	}
+
+	return results
+}
+
+// checkEndpoint runs the standalone login of one endpoint
+func (s *CredentialCheckService) checkEndpoint(ctx context.Context, name string) CredentialCheck {
+	result := CredentialCheck{Endpoint: name, ExpiresInDays: s.configuredExpiry(time.Now())}
+
+	client, err := s.endpoints[name]()
+	if err != nil {
+		result.Err = err
+		return result
+	}
+
+	result.Status, result.Err = client.CheckCredentials(ctx)
+	if result.Status != nil && result.Status.ExpiresInDays >= 0 {
+		if result.ExpiresInDays < 0 || result.Status.ExpiresInDays < result.ExpiresInDays {
+			result.ExpiresInDays = result.Status.ExpiresInDays
+		}
+	}
+
+	result.Warning = result.ExpiresInDays >= 0 && result.ExpiresInDays <= s.policy.WarnDays
+	return result
+}
+
+// configuredExpiry returns the days left until the configured expiry date, -1 when not configured
+func (s *CredentialCheckService) configuredExpiry(now time.Time) int {
+	if s.policy.ExpiresAt.IsZero() {
+		return -1
+	}
+
+	days := int(s.policy.ExpiresAt.Sub(now).Hours() / 24)
+	return max(days, 0)
+}
@@ -0,0 +1,120 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"provisioning-assistant/internal/domain"
+	"provisioning-assistant/internal/unm"
+	"sync"
+	"time"
+)
+
+// DefaultCredentialSet keys the credential set of the endpoints not listed in the secrets file
+const DefaultCredentialSet = "*"
+
+// CredentialSecrets provides the UNM credential sets of each endpoint from a secrets file, such as a mounted
+// Kubernetes secret, keyed by "host:port", host or "*". The file is read again when it changes, so the
+// password of the UNM can be rotated without restarting the process.
+type CredentialSecrets struct {
+	path     string
+	fallback unm.Credential
+	logger   domain.Logger
+
+	mtx     sync.Mutex
+	modTime time.Time
+	sets    map[string]unm.CredentialSet
+}
+
+// LoadCredentialSecrets reads the secrets file, the fallback account is used by the endpoints it does not list
+func LoadCredentialSecrets(path string, fallback unm.Credential, logger domain.Logger) (*CredentialSecrets, error) {
+	secrets := &CredentialSecrets{
+		path:     path,
+		fallback: fallback,
+		logger:   logger,
+	}
+
+	sets, modTime, err := secrets.read()
+	if err != nil {
+		return nil, err
+	}
+
+	if _, exists := sets[DefaultCredentialSet]; !exists && fallback.IsZero() {
+		return nil, fmt.Errorf("arquivo de credenciais sem conjunto padrão %q e sem UNM_USERNAME/UNM_PASSWORD", DefaultCredentialSet)
+	}
+
+	secrets.sets = sets
+	secrets.modTime = modTime
+	return secrets, nil
+}
+
+// Credentials returns the credential set of the endpoint, reloading the secrets file when it changed
+func (s *CredentialSecrets) Credentials(endpoint string) unm.CredentialSet {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	s.reload()
+
+	if set, exists := s.sets[endpoint]; exists {
+		return set
+	}
+	if host, _, err := net.SplitHostPort(endpoint); err == nil {
+		if set, exists := s.sets[host]; exists {
+			return set
+		}
+	}
+	if set, exists := s.sets[DefaultCredentialSet]; exists {
+		return set
+	}
+	return unm.CredentialSet{Current: s.fallback}
+}
+
+// reload replaces the credential sets when the file was modified, a file that cannot be used keeps the
+// previous sets so a bad edit does not lock the process out of the UNM
+func (s *CredentialSecrets) reload() {
+	info, err := os.Stat(s.path)
+	if err != nil || info.ModTime().Equal(s.modTime) {
+		return
+	}
+
+	sets, modTime, err := s.read()
+	if err != nil {
+		s.logger.WithError(err).Error("Falha ao recarregar credenciais do UNM, mantendo as anteriores")
+		s.modTime = info.ModTime()
+		return
+	}
+
+	s.sets = sets
+	s.modTime = modTime
+	s.logger.WithField("endpoints", len(sets)).Info("Credenciais do UNM recarregadas")
+}
+
+// read parses and validates the secrets file
+func (s *CredentialSecrets) read() (map[string]unm.CredentialSet, time.Time, error) {
+	info, err := os.Stat(s.path)
+	if err != nil {
+		return nil, time.Time{}, fmt.Errorf("falha ao ler arquivo de credenciais: %w", err)
+	}
+
+	content, err := os.ReadFile(s.path)
+	if err != nil {
+		return nil, time.Time{}, fmt.Errorf("falha ao ler arquivo de credenciais: %w", err)
+	}
+
+	var sets map[string]unm.CredentialSet
+	if err := json.Unmarshal(content, &sets); err != nil {
+		return nil, time.Time{}, fmt.Errorf("falha ao interpretar arquivo de credenciais: %w", err)
+	}
+
+	for endpoint, set := range sets {
+		if set.Current.IsZero() {
+			return nil, time.Time{}, fmt.Errorf("credenciais de %s: usuário e senha atuais são obrigatórios", endpoint)
+		}
+		if set.Next != nil && set.Next.IsZero() {
+			return nil, time.Time{}, fmt.Errorf("credenciais de %s: usuário e senha da próxima credencial são obrigatórios", endpoint)
+		}
+	}
+
+	return sets, info.ModTime(), nil
+}
@@ -0,0 +1,131 @@
+package services
+
+import (
+	"context"
+	"maps"
+	"provisioning-assistant/internal/clock"
+	"provisioning-assistant/internal/domain"
+	"slices"
+	"sync"
+	"time"
+)
+
+const (
+	// DependencyProbeTimeout bounds each probe of a watchdog round
+	DependencyProbeTimeout = 5 * time.Second
+
+	// DependencyOutageRounds is how many rounds in a row must find every dependency down before the outage
+	// is declared, a single blip of the network does not turn the bot away
+	DependencyOutageRounds = 2
+)
+
+// DependencyProbe checks a dependency answers
+type DependencyProbe func(ctx context.Context) error
+
+// DependencyTransition reports a change of the outage state caused by a watchdog round
+type DependencyTransition int
+
+const (
+	DependencyUnchanged DependencyTransition = iota
+	DependencyOutage
+	DependencyRecovered
+)
+
+// DependencyStatus is the last reading of the watchdog, with the error of each dependency found down
+type DependencyStatus struct {
+	Outage  bool
+	Since   time.Time
+	Failing map[string]string
+}
+
+// DependencyWatchdogService probes the core dependencies of the flows and declares an outage while all of
+// them are down, no flow can end well then
+type DependencyWatchdogService struct {
+	probes map[string]DependencyProbe
+	clock  clock.Clock
+	logger domain.Logger
+
+	mu           sync.RWMutex
+	failedRounds int
+	outage       bool
+	since        time.Time
+	failing      map[string]string
+}
+
+// NewDependencyWatchdogService creates a new watchdog over the named probes
+func NewDependencyWatchdogService(probes map[string]DependencyProbe, clock clock.Clock, logger domain.Logger) *DependencyWatchdogService {
+	return &DependencyWatchdogService{
+		probes:  probes,
+		clock:   clock,
+		logger:  logger,
+		failing: make(map[string]string),
+	}
+}
+
+// IsEnabled reports whether there is anything to watch
+func (s *DependencyWatchdogService) IsEnabled() bool {
+	return len(s.probes) > 0
+}
+
+// Check runs every probe and returns the resulting state transition
+func (s *DependencyWatchdogService) Check(ctx context.Context) DependencyTransition {
+	failing := make(map[string]string)
+	for _, name := range slices.Sorted(maps.Keys(s.probes)) {
+		probeCtx, cancel := context.WithTimeout(ctx, DependencyProbeTimeout)
+		err := s.probes[name](probeCtx)
+		cancel()
+
+		if err != nil {
+			failing[name] = err.Error()
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.failing = failing
+	allDown := len(s.probes) > 0 && len(failing) == len(s.probes)
+
+	switch {
+	case allDown && !s.outage:
+		s.failedRounds++
+		if s.failedRounds < DependencyOutageRounds {
+			return DependencyUnchanged
+		}
+
+		s.outage = true
+		s.since = s.clock.Now()
+		s.logger.WithField("failing", failing).Error("Dependências fora do ar, fluxos suspensos")
+		return DependencyOutage
+
+	case !allDown && s.outage:
+		s.outage = false
+		s.failedRounds = 0
+		s.logger.WithField("down_for", s.clock.Since(s.since)).Info("Dependências restabelecidas, fluxos retomados")
+		return DependencyRecovered
+
+	case !allDown:
+		s.failedRounds = 0
+	}
+
+	return DependencyUnchanged
+}
+
+// IsOutage reports whether every core dependency was found down
+func (s *DependencyWatchdogService) IsOutage() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.outage
+}
+
+// Status returns the last reading of the watchdog
+func (s *DependencyWatchdogService) Status() DependencyStatus {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return DependencyStatus{
+		Outage:  s.outage,
+		Since:   s.since,
+		Failing: maps.Clone(s.failing),
+	}
+}
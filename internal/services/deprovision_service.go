@@ -0,0 +1,239 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"provisioning-assistant/internal/clock"
+	"provisioning-assistant/internal/domain"
+	"provisioning-assistant/internal/unm"
+	"strings"
+	"time"
+)
+
+const (
+	// Cancelled contract deprovisioning defaults
+	DefaultDeprovisionLookback = 7 * 24 * time.Hour
+	DefaultDeprovisionInterval = 5 * time.Second
+	DefaultDeprovisionMaxBatch = 50
+)
+
+// DeprovisionPolicy bounds a batch removing the ONUs of cancelled contracts, waiting Interval between two
+// removals so the OLTs keep serving the activations. Scheduled runs it every day over the lookback.
+type DeprovisionPolicy struct {
+	Lookback  time.Duration
+	Interval  time.Duration
+	MaxBatch  int
+	Scheduled bool
+}
+
+type DeprovisionService struct {
+	erpService          *ErpService
+	auditService        *AuditService
+	provisioningService *ProvisioningService
+	policy              DeprovisionPolicy
+	clock               clock.Clock
+	logger              domain.Logger
+}
+
+// NewDeprovisionService creates the batch reclaiming the PON ports of the contracts cancelled in the ERP,
+// zero values of the policy fall back to the defaults
+func NewDeprovisionService(
+	erpService *ErpService,
+	auditService *AuditService,
+	provisioningService *ProvisioningService,
+	policy DeprovisionPolicy,
+	clock clock.Clock,
+	logger domain.Logger,
+) *DeprovisionService {
+	if policy.Lookback <= 0 {
+		policy.Lookback = DefaultDeprovisionLookback
+	}
+	if policy.Interval <= 0 {
+		policy.Interval = DefaultDeprovisionInterval
+	}
+	if policy.MaxBatch <= 0 {
+		policy.MaxBatch = DefaultDeprovisionMaxBatch
+	}
+
+	return &DeprovisionService{
+		erpService:          erpService,
+		auditService:        auditService,
+		provisioningService: provisioningService,
+		policy:              policy,
+		clock:               clock,
+		logger:              logger,
+	}
+}
+
+// Policy returns the bounds of the batches
+func (s *DeprovisionService) Policy() DeprovisionPolicy {
+	return s.policy
+}
+
+// Plan matches the ONUs of the contracts cancelled since the given moment on their OLTs. The ones still
+// registered are left pending. The ERP leaves out the equipment bound to an active contract, an ONU the
+// bot provisioned for another contract after the cancellation is also reused equipment and kept. The PON
+// comes from the ERP, or from the latest audit record of the serial when the ERP no longer has it, and
+// each PON is read once.
+func (s *DeprovisionService) Plan(ctx context.Context, since time.Time) (*domain.DeprovisionReport, error) {
+	contracts, err := s.erpService.ListCancelledContracts(ctx, since)
+	if err != nil {
+		return nil, fmt.Errorf("falha ao listar contratos cancelados: %w", err)
+	}
+
+	records, err := s.auditService.ListRecords(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("falha ao listar auditorias: %w", err)
+	}
+	latest := latestBySerial(records)
+
+	report := &domain.DeprovisionReport{Since: since}
+	pons := make(map[string]map[string]*domain.OnuSignalInfo)
+	seen := make(map[string]bool)
+
+	for _, contract := range contracts {
+		serial := strings.ToUpper(strings.TrimSpace(contract.Serial))
+		if seen[serial] {
+			continue
+		}
+		seen[serial] = true
+
+		item := &domain.DeprovisionItem{
+			Contract:    contract.ContractDescription,
+			ClientName:  contract.ClientName,
+			CancelledAt: contract.CancelledAt,
+			Serial:      serial,
+			OltIP:       contract.OltIP,
+			Slot:        contract.OltSlot,
+			Port:        contract.OltPort,
+			Outcome:     domain.DeprovisionPending,
+		}
+		report.Items = append(report.Items, item)
+
+		if record := latest[serial]; record != nil {
+			if record.Contract != contract.ContractDescription && record.CreatedAt.After(contract.CancelledAt) {
+				item.Outcome = domain.DeprovisionReused
+				continue
+			}
+			item.AuditID = record.ID
+			if item.OltIP == "" || item.Slot == "" || item.Port == "" {
+				item.OltIP, item.Slot, item.Port = record.OltIP, record.Slot, record.Port
+			}
+		}
+
+		if item.OltIP == "" || item.Slot == "" || item.Port == "" {
+			item.Outcome = domain.DeprovisionUnknown
+			continue
+		}
+
+		key := item.OltIP + "/" + item.Slot + "/" + item.Port
+		signals, read := pons[key]
+		if !read {
+			signals, err = s.provisioningService.PonSignals(ctx, item.OltIP, item.Slot, item.Port)
+			if err != nil {
+				if ctx.Err() != nil {
+					return report, ctx.Err()
+				}
+				item.Outcome = domain.DeprovisionUnknown
+				item.Err = err.Error()
+				continue
+			}
+			pons[key] = signals
+		}
+
+		if _, onOlt := signals[serial]; !onOlt {
+			item.Outcome = domain.DeprovisionAbsent
+		}
+	}
+
+	return report, nil
+}
+
+// Run removes the pending ONUs of a planned batch, at most MaxBatch of them spaced by the policy interval.
+// The commands sent are kept on the audit record of each ONU, the items left once the context ends or the
+// UNM gets saturated stay pending.
+func (s *DeprovisionService) Run(ctx context.Context, report *domain.DeprovisionReport, removedBy string) *domain.DeprovisionReport {
+	report.StartedAt = s.clock.Now()
+	defer func() { report.FinishedAt = s.clock.Now() }()
+
+	removals := 0
+	for _, item := range report.Items {
+		if item.Outcome != domain.DeprovisionPending {
+			continue
+		}
+		if removals >= s.policy.MaxBatch {
+			break
+		}
+
+		if removals > 0 {
+			if err := clock.Sleep(ctx, s.clock, s.policy.Interval); err != nil {
+				break
+			}
+		}
+		if !s.provisioningService.allowNonCritical(ctx, ShedDeprovision) {
+			break
+		}
+		removals++
+
+		s.remove(ctx, item, removedBy)
+	}
+
+	s.logger.WithFields(map[string]any{
+		"removed": report.Count(domain.DeprovisionRemoved),
+		"failed":  report.Count(domain.DeprovisionFailed),
+		"pending": report.Count(domain.DeprovisionPending),
+	}).Info("Lote de desprovisionamento de contratos cancelados concluído")
+
+	return report
+}
+
+// remove deletes the ONU of an item from its OLT, marking its audit record as removed
+func (s *DeprovisionService) remove(ctx context.Context, item *domain.DeprovisionItem, removedBy string) {
+	log := s.logger.WithFields(map[string]any{
+		"contract": item.Contract,
+		"serial":   item.Serial,
+		"olt":      item.OltIP,
+	})
+
+	removeCtx, commandLog := unm.WithCommandLog(ctx)
+	err := s.provisioningService.RemoveOnu(removeCtx, &domain.LastJob{
+		Serial: item.Serial,
+		OltIP:  item.OltIP,
+		Slot:   item.Slot,
+		Port:   item.Port,
+	})
+
+	if item.AuditID != "" {
+		if logErr := s.auditService.AppendCommands(ctx, item.AuditID, commandLog.Commands()); logErr != nil {
+			log.WithError(logErr).Warn("Falha ao registrar comandos da remoção")
+		}
+	}
+
+	if err != nil {
+		log.WithError(err).Error("Falha ao remover ONU de contrato cancelado")
+		item.Outcome = domain.DeprovisionFailed
+		item.Err = err.Error()
+		return
+	}
+
+	item.Outcome = domain.DeprovisionRemoved
+	log.Info("ONU de contrato cancelado removida")
+
+	if item.AuditID != "" {
+		if _, err := s.auditService.MarkRemoved(ctx, item.AuditID, removedBy); err != nil {
+			log.WithError(err).WithField("audit_id", item.AuditID).Error("Falha ao registrar remoção no registro de auditoria")
+		}
+	}
+}
+
+// latestBySerial indexes the latest audit record of each serial
+func latestBySerial(records []*domain.AuditRecord) map[string]*domain.AuditRecord {
+	latest := make(map[string]*domain.AuditRecord)
+	for _, record := range records {
+		serial := strings.ToUpper(record.Serial)
+		if current := latest[serial]; current == nil || record.CreatedAt.After(current.CreatedAt) {
+			latest[serial] = record
+		}
+	}
+	return latest
+}
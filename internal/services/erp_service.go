@@ -2,21 +2,74 @@ package services
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"provisioning-assistant/internal/clock"
+	"provisioning-assistant/internal/database"
 	"provisioning-assistant/internal/domain"
 	"provisioning-assistant/internal/domain/dto"
+	"provisioning-assistant/internal/validation"
+	"strings"
+	"sync"
+	"time"
 )
 
+const (
+	// ERP health constants
+	ErpDegradedThreshold = 3
+	ErpDegradedWindow    = 10 * time.Minute
+
+	// ERP adaptive retry constants
+	DefaultErpMinTimeout = 5 * time.Second
+	DefaultErpMaxTimeout = 30 * time.Second
+	DefaultErpMaxRetries = 2
+	ErpLatencyWindow     = 200
+	ErpLatencyMinSamples = 10
+	ErpTimeoutFactor     = 3
+	ErpSlowThreshold     = 3 * time.Second
+	ErpRetryBackoff      = 500 * time.Millisecond
+)
+
+// ErpRetryPolicy bounds the adaptive timeouts and retries of ERP lookups
+type ErpRetryPolicy struct {
+	MinTimeout time.Duration
+	MaxTimeout time.Duration
+	MaxRetries int
+}
+
 type ErpService struct {
-	repository domain.ErpRepository
-	logger     domain.Logger
+	repository        domain.ErpRepository
+	sandboxRepository domain.ErpRepository
+	policy            ErpRetryPolicy
+	latency           *LatencyTracker
+	clock             clock.Clock
+	logger            domain.Logger
+
+	mu                  sync.RWMutex
+	consecutiveFailures int
+	lastFailureAt       time.Time
+	schemaErr           error
 }
 
-// NewErpService creates a new ERP service instance
-func NewErpService(repository domain.ErpRepository, logger domain.Logger) *ErpService {
+// NewErpService creates a new ERP service instance, training sessions read the sandbox repository when set
+func NewErpService(repository, sandboxRepository domain.ErpRepository, policy ErpRetryPolicy, clock clock.Clock, logger domain.Logger) *ErpService {
+	if policy.MinTimeout <= 0 {
+		policy.MinTimeout = DefaultErpMinTimeout
+	}
+	if policy.MaxTimeout < policy.MinTimeout {
+		policy.MaxTimeout = max(DefaultErpMaxTimeout, policy.MinTimeout)
+	}
+	if policy.MaxRetries < 0 {
+		policy.MaxRetries = 0
+	}
+
 	return &ErpService{
-		repository: repository,
-		logger:     logger,
+		repository:        repository,
+		sandboxRepository: sandboxRepository,
+		policy:            policy,
+		latency:           NewLatencyTracker(ErpLatencyWindow),
+		clock:             clock,
+		logger:            logger,
 	}
 }
 
@@ -24,18 +77,16 @@ func NewErpService(repository domain.ErpRepository, logger domain.Logger) *ErpSe
 func (s *ErpService) GetConnectionInfo(ctx context.Context, protocol string) (*dto.ConnectionInfo, error) {
 	s.logger.WithField("protocol", protocol).Info("Buscando informações de conexão do ERP")
 
-	connInfo, err := s.repository.GetConnInfoByProtocol(ctx, protocol)
+	connInfo, err := s.lookup(ctx, func(ctx context.Context) (*dto.ConnectionInfo, error) {
+		return s.repositoryFor(ctx).GetConnInfoByProtocol(ctx, protocol)
+	})
 	if err != nil {
 		s.logger.WithError(err).WithField("protocol", protocol).Error("Falha ao buscar informações de conexão")
 		return nil, fmt.Errorf("falha ao buscar informações de conexão: %w", err)
 	}
 
-	if connInfo.ConnectionOltIP == "" {
-		return nil, fmt.Errorf("informações de conexão incompletas: IP da OLT ausente")
-	}
-
-	if connInfo.ConnectionEquipmentSerialNumber == "" {
-		return nil, fmt.Errorf("informações de conexão incompletas: número de série do equipamento ausente")
+	if err := s.validateConnectionInfo(connInfo); err != nil {
+		return nil, err
 	}
 
 	s.logger.
@@ -47,3 +98,227 @@ func (s *ErpService) GetConnectionInfo(ctx context.Context, protocol string) (*d
 
 	return connInfo, nil
 }
+
+// GetConnectionInfoByPPPoE retrieves connection information from ERP by customer PPPoE username
+func (s *ErpService) GetConnectionInfoByPPPoE(ctx context.Context, username string) (*dto.ConnectionInfo, error) {
+	s.logger.WithField("pppoe_user", username).Info("Buscando conexão do ERP por usuário PPPoE")
+
+	connInfo, err := s.lookup(ctx, func(ctx context.Context) (*dto.ConnectionInfo, error) {
+		return s.repositoryFor(ctx).GetConnInfoByPPPoEUsername(ctx, username)
+	})
+	if err != nil {
+		s.logger.WithError(err).WithField("pppoe_user", username).Error("Falha ao buscar conexão por usuário PPPoE")
+		return nil, fmt.Errorf("falha ao buscar conexão por usuário PPPoE: %w", err)
+	}
+
+	if err := s.validateConnectionInfo(connInfo); err != nil {
+		return nil, err
+	}
+
+	return connInfo, nil
+}
+
+// PPPoEPassword returns the password the ERP holds for a PPPoE username, of the main connection of the
+// contract or of one of its extra WAN services
+func (s *ErpService) PPPoEPassword(ctx context.Context, username string) (string, error) {
+	connInfo, err := s.GetConnectionInfoByPPPoE(ctx, username)
+	if err != nil {
+		return "", err
+	}
+
+	if strings.EqualFold(connInfo.ConnectionClientPPPoEUsername, username) {
+		return connInfo.ConnectionClientPPPoEPassword, nil
+	}
+	for _, service := range connInfo.ExtraWanServices {
+		if strings.EqualFold(service.PPPoEUsername, username) {
+			return service.PPPoEPassword, nil
+		}
+	}
+
+	return "", fmt.Errorf("senha PPPoE do usuário %s não encontrada no ERP", username)
+}
+
+// Ping checks the production ERP answers, repositories without a server behind them always pass
+func (s *ErpService) Ping(ctx context.Context) error {
+	pinger, ok := s.repository.(domain.ErpPinger)
+	if !ok {
+		return nil
+	}
+	return pinger.Ping(ctx)
+}
+
+// ListWorkOrders retrieves the protocols assigned to technicians since the given moment, repositories without
+// the field-service schedule, such as the training snapshot, have none
+func (s *ErpService) ListWorkOrders(ctx context.Context, since time.Time) ([]*dto.WorkOrder, error) {
+	lister, ok := s.repository.(domain.ErpWorkOrderLister)
+	if !ok {
+		return nil, nil
+	}
+	return lister.ListWorkOrders(ctx, since)
+}
+
+// ListCancelledContracts retrieves the contracts cancelled since the given moment, repositories without the
+// contract history, such as the training snapshot, have none
+func (s *ErpService) ListCancelledContracts(ctx context.Context, since time.Time) ([]*dto.CancelledContract, error) {
+	lister, ok := s.repository.(domain.ErpCancellationLister)
+	if !ok {
+		return nil, nil
+	}
+	return lister.ListCancelledContracts(ctx, since)
+}
+
+// CheckSchema validates the columns read by the connection queries, keeping a drift for the readiness probe.
+// Repositories that can't describe their schema, such as the training snapshot, always pass.
+func (s *ErpService) CheckSchema(ctx context.Context) error {
+	checker, ok := s.repository.(domain.ErpSchemaChecker)
+	if !ok {
+		return nil
+	}
+
+	err := checker.CheckSchema(ctx)
+
+	s.mu.Lock()
+	s.schemaErr = err
+	s.mu.Unlock()
+
+	switch {
+	case errors.Is(err, domain.ErrErpSchemaDrift):
+		s.logger.WithError(err).Error("Esquema do ERP divergente das consultas de conexão")
+	case err != nil:
+		s.logger.WithError(err).Warn("Não foi possível validar o esquema do ERP")
+	}
+
+	return err
+}
+
+// SchemaReady reports the schema drift found at startup, probing again while the ERP was unreachable
+func (s *ErpService) SchemaReady(ctx context.Context) error {
+	s.mu.RLock()
+	err := s.schemaErr
+	s.mu.RUnlock()
+
+	if err != nil && !errors.Is(err, domain.ErrErpSchemaDrift) {
+		return s.CheckSchema(ctx)
+	}
+
+	return err
+}
+
+// repositoryFor returns the ERP repository for the context, the sandbox copy for training sessions
+func (s *ErpService) repositoryFor(ctx context.Context) domain.ErpRepository {
+	if domain.IsTraining(ctx) && s.sandboxRepository != nil {
+		return s.sandboxRepository
+	}
+	return s.repository
+}
+
+// lookup runs an ERP query with adaptive per-attempt timeouts, retrying connection failures and timeouts within
+// the policy bounds
+func (s *ErpService) lookup(ctx context.Context, query func(ctx context.Context) (*dto.ConnectionInfo, error)) (*dto.ConnectionInfo, error) {
+	timeout := s.AttemptTimeout()
+
+	var lastErr error
+	for attempt := 0; attempt <= s.policy.MaxRetries; attempt++ {
+		if attempt > 0 {
+			if err := clock.Sleep(ctx, s.clock, ErpRetryBackoff); err != nil {
+				return nil, err
+			}
+		}
+
+		attemptCtx, cancel := context.WithTimeout(ctx, timeout)
+		startedAt := s.clock.Now()
+		connInfo, err := query(attemptCtx)
+		elapsed := s.clock.Since(startedAt)
+		cancel()
+
+		if err == nil || errors.Is(err, database.ErrNotFound) {
+			s.latency.Observe(elapsed)
+			if err != nil {
+				return nil, err
+			}
+			s.recordSuccess()
+			return connInfo, nil
+		}
+
+		lastErr = err
+		if ctx.Err() != nil || !database.IsTransient(err) {
+			break
+		}
+
+		if errors.Is(err, context.DeadlineExceeded) {
+			// A timed out attempt only tells the latency was at least the timeout
+			s.latency.Observe(timeout)
+			timeout = min(timeout*2, s.policy.MaxTimeout)
+		}
+
+		s.logger.WithError(err).WithFields(map[string]any{
+			"attempt":    attempt + 1,
+			"timeout_ms": timeout.Milliseconds(),
+		}).Warn("Consulta ao ERP falhou, tentando novamente")
+	}
+
+	s.recordFailure()
+	return nil, lastErr
+}
+
+// AttemptTimeout derives the timeout of a lookup attempt from the recent p95 latency
+func (s *ErpService) AttemptTimeout() time.Duration {
+	if s.latency.Count() < ErpLatencyMinSamples {
+		return s.policy.MaxTimeout
+	}
+
+	timeout := s.latency.Percentile(0.95) * ErpTimeoutFactor
+	return max(s.policy.MinTimeout, min(timeout, s.policy.MaxTimeout))
+}
+
+// IsSlow reports whether the ERP is responding, but with a median latency above the slow threshold
+func (s *ErpService) IsSlow() bool {
+	return s.latency.Count() >= ErpLatencyMinSamples && s.latency.Percentile(0.5) > ErpSlowThreshold
+}
+
+// Latency returns the recent median and p95 ERP lookup latencies
+func (s *ErpService) Latency() (time.Duration, time.Duration) {
+	return s.latency.Percentile(0.5), s.latency.Percentile(0.95)
+}
+
+// validateConnectionInfo ensures the ONU location required by the UNM is present
+func (s *ErpService) validateConnectionInfo(connInfo *dto.ConnectionInfo) error {
+	if problems := validation.ErpConnection(connInfo); problems != nil {
+		return fmt.Errorf("informações de conexão incompletas: %w", problems)
+	}
+	return nil
+}
+
+// IsDegraded reports whether recent ERP lookups have been failing repeatedly
+func (s *ErpService) IsDegraded() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.consecutiveFailures >= ErpDegradedThreshold &&
+		s.clock.Since(s.lastFailureAt) < ErpDegradedWindow
+}
+
+// recordFailure registers a failed ERP lookup
+func (s *ErpService) recordFailure() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.consecutiveFailures++
+	s.lastFailureAt = s.clock.Now()
+
+	if s.consecutiveFailures == ErpDegradedThreshold {
+		s.logger.WithField("failures", s.consecutiveFailures).Warn("Consultas ao ERP degradadas")
+	}
+}
+
+// recordSuccess resets the ERP failure counter after a successful lookup
+func (s *ErpService) recordSuccess() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.consecutiveFailures >= ErpDegradedThreshold {
+		s.logger.Info("Consultas ao ERP restabelecidas")
+	}
+
+	s.consecutiveFailures = 0
+}
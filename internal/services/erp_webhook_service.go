@@ -0,0 +1,240 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"provisioning-assistant/internal/clock"
+	"provisioning-assistant/internal/domain"
+	"provisioning-assistant/internal/validation"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultErpWebhookTolerance is how far the timestamp of a delivery may drift from the clock, older
+	// deliveries are taken for replays
+	DefaultErpWebhookTolerance = 5 * time.Minute
+
+	// ErpWebhookMaxBody bounds the size of a delivery
+	ErpWebhookMaxBody = 64 << 10
+
+	erpEventNamespace = "erp_events"
+
+	// erpEventMargin keeps an event ID a little past the last moment its delivery could still be replayed
+	erpEventMargin = time.Minute
+)
+
+var (
+	ErrErpWebhookDisabled  = errors.New("webhook do ERP desabilitado: ERP_WEBHOOK_SECRET não definida")
+	ErrErpWebhookSignature = errors.New("assinatura do webhook do ERP inválida")
+	ErrErpWebhookExpired   = errors.New("entrega do webhook do ERP fora da janela de tolerância")
+	ErrErpEventDuplicate   = errors.New("evento do ERP já recebido")
+)
+
+// ErpWebhookPolicy configures the verification of the deliveries of the ERP webhook
+type ErpWebhookPolicy struct {
+	Secret    string
+	Tolerance time.Duration
+}
+
+// ErpEventListener receives the events accepted from the ERP, an error leaves the event to the retry of the ERP
+type ErpEventListener func(ctx context.Context, event *domain.ErpEvent) error
+
+type ErpWebhookService struct {
+	repository domain.StateRepository
+	listener   ErpEventListener
+	policy     ErpWebhookPolicy
+	clock      clock.Clock
+	logger     domain.Logger
+}
+
+// NewErpWebhookService creates the intake of the protocol changes pushed by the ERP
+func NewErpWebhookService(
+	repository domain.StateRepository,
+	listener ErpEventListener,
+	policy ErpWebhookPolicy,
+	clock clock.Clock,
+	logger domain.Logger,
+) *ErpWebhookService {
+	if policy.Tolerance <= 0 {
+		policy.Tolerance = DefaultErpWebhookTolerance
+	}
+
+	return &ErpWebhookService{
+		repository: repository,
+		listener:   listener,
+		policy:     policy,
+		clock:      clock,
+		logger:     logger,
+	}
+}
+
+// IsEnabled reports whether the webhook accepts deliveries, a secret is required to verify them
+func (s *ErpWebhookService) IsEnabled() bool {
+	return s.policy.Secret != ""
+}
+
+// Ingest verifies the signature of a delivery, checks its body against the event schema and hands the event
+// to the listener. The ERP retries deliveries, an event already received returns ErrErpEventDuplicate. The event
+// is only recorded as received once the listener took it, so a failed delivery is accepted again on retry.
+func (s *ErpWebhookService) Ingest(ctx context.Context, timestamp, signature string, body []byte) (*domain.ErpEvent, error) {
+	if !s.IsEnabled() {
+		return nil, ErrErpWebhookDisabled
+	}
+
+	if err := s.verify(timestamp, signature, body); err != nil {
+		return nil, err
+	}
+
+	event, err := parseErpEvent(body)
+	if err != nil {
+		return nil, err
+	}
+
+	if value, err := s.repository.Get(ctx, erpEventNamespace, event.ID); err == nil && value != "" {
+		return event, ErrErpEventDuplicate
+	}
+
+	log := s.logger.WithFields(map[string]any{
+		"event_id": event.ID,
+		"type":     event.Type,
+		"protocol": event.Protocol,
+	})
+
+	if s.listener != nil {
+		if err := s.listener(ctx, event); err != nil {
+			return nil, fmt.Errorf("falha ao processar evento do ERP: %w", err)
+		}
+	}
+
+	// The event was handled, a failure here only lets a retry of the ERP through again
+	if err := s.repository.Set(ctx, erpEventNamespace, event.ID, s.clock.Now().Format(time.RFC3339)); err != nil {
+		log.WithError(err).Warn("Falha ao registrar evento do ERP recebido")
+	}
+
+	log.Info("Evento do ERP recebido")
+	return event, nil
+}
+
+// Purge clears the IDs of the events received before any delivery of theirs could pass the timestamp check
+// again, returning how many were cleared
+func (s *ErpWebhookService) Purge(ctx context.Context) (int, error) {
+	values, err := s.repository.List(ctx, erpEventNamespace)
+	if err != nil {
+		return 0, err
+	}
+
+	// A timestamp is accepted that far behind or ahead of the clock
+	retention := 2*s.policy.Tolerance + erpEventMargin
+	now := s.clock.Now()
+	var purged int
+
+	for id, value := range values {
+		received, err := time.Parse(time.RFC3339, value)
+		if err == nil && now.Sub(received) < retention {
+			continue
+		}
+
+		if err := s.repository.Delete(ctx, erpEventNamespace, id); err != nil {
+			return purged, fmt.Errorf("falha ao limpar evento do ERP %s: %w", id, err)
+		}
+		purged++
+	}
+
+	if purged > 0 {
+		s.logger.WithField("events", purged).Debug("Eventos do ERP expirados removidos")
+	}
+
+	return purged, nil
+}
+
+// verify checks the HMAC-SHA256 of "<timestamp>.<body>", sent as "sha256=<hex>", and that the timestamp, in
+// Unix seconds, falls within the tolerance
+func (s *ErpWebhookService) verify(timestamp, signature string, body []byte) error {
+	mac := hmac.New(sha256.New, []byte(s.policy.Secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	expected := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+
+	if !hmac.Equal([]byte(expected), []byte(strings.TrimSpace(signature))) {
+		return ErrErpWebhookSignature
+	}
+
+	unix, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return ErrErpWebhookSignature
+	}
+
+	drift := s.clock.Now().Sub(time.Unix(unix, 0))
+	if drift > s.policy.Tolerance || drift < -s.policy.Tolerance {
+		return ErrErpWebhookExpired
+	}
+	return nil
+}
+
+// parseErpEvent decodes a delivery, rejecting unknown fields, and checks it against the event schema
+func parseErpEvent(body []byte) (*domain.ErpEvent, error) {
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.DisallowUnknownFields()
+
+	var event domain.ErpEvent
+	if err := decoder.Decode(&event); err != nil {
+		return nil, validation.Problems{{Field: "body", Code: validation.CodeFormat, Message: fmt.Sprintf("JSON inválido: %v", err)}}
+	}
+	if _, err := decoder.Token(); !errors.Is(err, io.EOF) {
+		return nil, validation.Problems{{Field: "body", Code: validation.CodeFormat, Message: "conteúdo após o evento"}}
+	}
+
+	if err := validateErpEvent(&event).Err(); err != nil {
+		return nil, err
+	}
+	return &event, nil
+}
+
+// validateErpEvent checks the required fields of an event and the ones its type depends on
+func validateErpEvent(event *domain.ErpEvent) validation.Problems {
+	var problems validation.Problems
+	add := func(field string, code validation.Code, message string) {
+		problems = append(problems, validation.Problem{Field: field, Code: code, Message: message})
+	}
+
+	if strings.TrimSpace(event.ID) == "" {
+		add("id", validation.CodeRequired, "id do evento é obrigatório")
+	}
+
+	switch {
+	case event.Type == "":
+		add("type", validation.CodeRequired, "tipo do evento é obrigatório")
+	case !slices.Contains(domain.ErpEventTypes, event.Type):
+		add("type", validation.CodeFormat, fmt.Sprintf("tipo de evento desconhecido: %s", event.Type))
+	}
+
+	switch {
+	case event.Protocol == "":
+		add("protocol", validation.CodeRequired, "protocolo é obrigatório")
+	case strings.Trim(event.Protocol, "0123456789") != "":
+		add("protocol", validation.CodeFormat, fmt.Sprintf("protocolo não numérico: %s", event.Protocol))
+	}
+
+	if event.Type == domain.ErpEventProtocolCreated && event.TechnicianTaxID == "" {
+		add("technician_cpf", validation.CodeRequired, "CPF do técnico é obrigatório em protocolos criados")
+	}
+	if event.TechnicianTaxID != "" && validation.TaxID(event.TechnicianTaxID) != nil {
+		add("technician_cpf", validation.CodeFormat, "CPF do técnico deve ter 11 dígitos")
+	}
+
+	if event.OccurredAt.IsZero() {
+		add("occurred_at", validation.CodeRequired, "data do evento é obrigatória")
+	}
+
+	return problems
+}
@@ -0,0 +1,84 @@
+package services
+
+import (
+	"provisioning-assistant/internal/clock"
+	"slices"
+	"sync"
+	"time"
+)
+
+const (
+	// ErrorDedupeWindow is how long repeats of a reported error are counted instead of sent again
+	ErrorDedupeWindow = 10 * time.Minute
+
+	// ErrorReportsPerMinute caps the reports sent to the error channel during a burst of distinct failures
+	ErrorReportsPerMinute = 5
+)
+
+// ErrorThrottle deduplicates and rate-limits the failures forwarded to the error channel
+type ErrorThrottle struct {
+	mu      sync.Mutex
+	clock   clock.Clock
+	entries map[string]*errorEntry
+	sent    []time.Time
+}
+
+type errorEntry struct {
+	reportedAt time.Time
+	suppressed int
+}
+
+// NewErrorThrottle creates a new error report throttle
+func NewErrorThrottle(clock clock.Clock) *ErrorThrottle {
+	return &ErrorThrottle{
+		clock:   clock,
+		entries: make(map[string]*errorEntry),
+	}
+}
+
+// Admit decides whether an error is reported now, returning how many repeats of it were held back
+// since its last report
+func (t *ErrorThrottle) Admit(source, message string) (int, bool) {
+	now := t.clock.Now()
+	key := source + "\x00" + message
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	entry, exists := t.entries[key]
+	if !exists {
+		entry = &errorEntry{}
+		t.entries[key] = entry
+	}
+
+	if now.Sub(entry.reportedAt) < ErrorDedupeWindow {
+		entry.suppressed++
+		return 0, false
+	}
+
+	t.sent = slices.DeleteFunc(t.sent, func(at time.Time) bool {
+		return now.Sub(at) >= time.Minute
+	})
+	if len(t.sent) >= ErrorReportsPerMinute {
+		// Never reported, so the next occurrence is admitted as soon as the burst is over
+		entry.suppressed++
+		return 0, false
+	}
+
+	repeats := entry.suppressed
+	entry.reportedAt = now
+	entry.suppressed = 0
+	t.sent = append(t.sent, now)
+
+	t.prune(now)
+	return repeats, true
+}
+
+// prune forgets the errors whose window passed without repeats
+func (t *ErrorThrottle) prune(now time.Time) {
+	for key, entry := range t.entries {
+		if entry.suppressed == 0 && now.Sub(entry.reportedAt) >= ErrorDedupeWindow {
+			delete(t.entries, key)
+		}
+	}
+}
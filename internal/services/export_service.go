@@ -0,0 +1,310 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"math"
+	"provisioning-assistant/internal/domain"
+	"provisioning-assistant/internal/locale"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// MaxExportDays bounds the period of an export
+	MaxExportDays = 366
+
+	// exportSeparator splits the columns, spreadsheets in pt-BR read the comma as the decimal separator
+	exportSeparator = ';'
+)
+
+var ErrInvalidExportPeriod = errors.New("período inválido")
+
+// ExportPeriod is the span of audit records covered by an export, the dates of the files are written in the
+// timezone of its bounds
+type ExportPeriod struct {
+	Since time.Time
+	Until time.Time
+}
+
+// ParseExportPeriod reads "hoje", "ontem", "semana", "mes" or a number of days such as "15d", counted in
+// whole days of the timezone of now up to now
+func ParseExportPeriod(value string, now time.Time) (ExportPeriod, error) {
+	midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	days := 0
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "hoje":
+		days = 1
+	case "ontem":
+		return ExportPeriod{Since: midnight.AddDate(0, 0, -1), Until: midnight}, nil
+	case "semana":
+		days = 7
+	case "mes", "mês":
+		days = 30
+	default:
+		number, found := strings.CutSuffix(strings.ToLower(strings.TrimSpace(value)), "d")
+		parsed, err := strconv.Atoi(number)
+		if !found || err != nil || parsed < 1 || parsed > MaxExportDays {
+			return ExportPeriod{}, fmt.Errorf("%w: %s", ErrInvalidExportPeriod, value)
+		}
+		days = parsed
+	}
+
+	return ExportPeriod{Since: midnight.AddDate(0, 0, 1-days), Until: now}, nil
+}
+
+// Includes reports whether a moment falls in the period
+func (p ExportPeriod) Includes(t time.Time) bool {
+	return !t.Before(p.Since) && t.Before(p.Until)
+}
+
+// Export is the set of CSV files produced for a period
+type Export struct {
+	Period        ExportPeriod
+	Provisionings int
+	Failures      int
+	Artifacts     []*domain.Artifact
+}
+
+// ExportService writes the audit trail of a period as CSV files in the artifact store, for the supervisors
+// who follow the activations in spreadsheets
+type ExportService struct {
+	auditService *AuditService
+	artifacts    *ArtifactService
+	logger       domain.Logger
+}
+
+// NewExportService creates a new CSV export service
+func NewExportService(auditService *AuditService, artifacts *ArtifactService, logger domain.Logger) *ExportService {
+	return &ExportService{
+		auditService: auditService,
+		artifacts:    artifacts,
+		logger:       logger,
+	}
+}
+
+// Export writes the provisionings, the failures and the signal statistics by OLT of the period
+func (s *ExportService) Export(ctx context.Context, period ExportPeriod) (*Export, error) {
+	records, err := s.auditService.ListRecords(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("falha ao listar auditorias: %w", err)
+	}
+
+	records = slices.DeleteFunc(slices.Clone(records), func(record *domain.AuditRecord) bool {
+		return !period.Includes(record.CreatedAt)
+	})
+	slices.SortFunc(records, func(a, b *domain.AuditRecord) int {
+		return a.CreatedAt.Compare(b.CreatedAt)
+	})
+
+	export := &Export{Period: period, Provisionings: len(records)}
+	var failures []*domain.AuditRecord
+	for _, record := range records {
+		if !record.Success {
+			failures = append(failures, record)
+		}
+	}
+	export.Failures = len(failures)
+
+	files := []struct {
+		name string
+		rows [][]string
+	}{
+		{"provisionamentos", provisioningRows(records, period.Since.Location())},
+		{"falhas", failureRows(failures, period.Since.Location())},
+		{"sinal", signalRows(records)},
+	}
+
+	prefix := fmt.Sprintf("exports/%s_%s_", period.Since.Format("20060102"), period.Until.Format("20060102"))
+	for _, file := range files {
+		content, err := encodeCSV(file.rows)
+		if err != nil {
+			return nil, fmt.Errorf("falha ao gerar %s.csv: %w", file.name, err)
+		}
+
+		key := prefix + file.name + ".csv"
+		artifact, err := s.artifacts.Save(ctx, key, "text/csv", bytes.NewReader(content), s.artifacts.Retention())
+		if err != nil {
+			return nil, err
+		}
+		export.Artifacts = append(export.Artifacts, artifact)
+	}
+
+	s.logger.WithFields(map[string]any{
+		"since":         period.Since,
+		"until":         period.Until,
+		"provisionings": export.Provisionings,
+		"failures":      export.Failures,
+	}).Info("Exportação CSV gerada")
+
+	return export, nil
+}
+
+// provisioningRows lists every job of the period
+func provisioningRows(records []*domain.AuditRecord, location *time.Location) [][]string {
+	rows := [][]string{{
+		"id", "data", "protocolo", "contrato", "cliente", "serial", "olt", "slot", "porta", "tecnico",
+		"sucesso", "manual", "reconfigurado", "recepcao_dbm", "nivel_sinal", "duracao_s", "diagnostico",
+	}}
+
+	for _, record := range records {
+		var total time.Duration
+		for _, step := range record.Steps {
+			total += step.Duration
+		}
+
+		diagnosis := ""
+		if record.Troubleshooting != nil {
+			diagnosis = record.Troubleshooting.Outcome
+		}
+
+		rows = append(rows, []string{
+			record.ID,
+			record.CreatedAt.In(location).Format(locale.DateTimeLayout),
+			record.Protocol,
+			record.Contract,
+			record.ClientName,
+			record.Serial,
+			record.OltIP,
+			record.Slot,
+			record.Port,
+			record.TechnicianName,
+			yesNo(record.Success),
+			yesNo(record.Manual),
+			yesNo(record.Reconfigured),
+			strings.ReplaceAll(strings.TrimSpace(record.RxPower), ".", ","),
+			string(record.SignalLevel),
+			csvDecimal(total.Seconds(), 1),
+			diagnosis,
+		})
+	}
+
+	return rows
+}
+
+// failureRows lists the failed jobs with their error and the TL1 commands rejected by the OLT
+func failureRows(records []*domain.AuditRecord, location *time.Location) [][]string {
+	rows := [][]string{{"id", "data", "protocolo", "contrato", "serial", "olt", "tecnico", "erro", "comandos_com_erro"}}
+
+	for _, record := range records {
+		failed := 0
+		for _, command := range record.Commands {
+			if command.Error != "" {
+				failed++
+			}
+		}
+
+		rows = append(rows, []string{
+			record.ID,
+			record.CreatedAt.In(location).Format(locale.DateTimeLayout),
+			record.Protocol,
+			record.Contract,
+			record.Serial,
+			record.OltIP,
+			record.TechnicianName,
+			record.Error,
+			strconv.Itoa(failed),
+		})
+	}
+
+	return rows
+}
+
+// signalRows sums up the reception of the successful jobs by OLT
+func signalRows(records []*domain.AuditRecord) [][]string {
+	type oltSignal struct {
+		readings  []float64
+		warnings  int
+		criticals int
+	}
+
+	byOlt := make(map[string]*oltSignal)
+	for _, record := range records {
+		if !record.Success || record.OltIP == "" {
+			continue
+		}
+
+		rx, err := strconv.ParseFloat(strings.ReplaceAll(strings.TrimSpace(record.RxPower), ",", "."), 64)
+		if err != nil {
+			continue
+		}
+
+		stats, exists := byOlt[record.OltIP]
+		if !exists {
+			stats = &oltSignal{}
+			byOlt[record.OltIP] = stats
+		}
+		stats.readings = append(stats.readings, rx)
+		switch record.SignalLevel {
+		case domain.SignalWarning:
+			stats.warnings++
+		case domain.SignalCritical:
+			stats.criticals++
+		}
+	}
+
+	rows := [][]string{{"olt", "ativacoes", "recepcao_min_dbm", "recepcao_media_dbm", "recepcao_max_dbm", "alertas", "criticos"}}
+
+	olts := make([]string, 0, len(byOlt))
+	for olt := range byOlt {
+		olts = append(olts, olt)
+	}
+	slices.Sort(olts)
+
+	for _, olt := range olts {
+		stats := byOlt[olt]
+
+		var sum float64
+		for _, rx := range stats.readings {
+			sum += rx
+		}
+
+		rows = append(rows, []string{
+			olt,
+			strconv.Itoa(len(stats.readings)),
+			csvDecimal(slices.Min(stats.readings), 2),
+			csvDecimal(sum/float64(len(stats.readings)), 2),
+			csvDecimal(slices.Max(stats.readings), 2),
+			strconv.Itoa(stats.warnings),
+			strconv.Itoa(stats.criticals),
+		})
+	}
+
+	return rows
+}
+
+// encodeCSV writes the rows with a BOM so spreadsheets detect the UTF-8 accents
+func encodeCSV(rows [][]string) ([]byte, error) {
+	var buffer bytes.Buffer
+	buffer.WriteString("\ufeff")
+
+	writer := csv.NewWriter(&buffer)
+	writer.Comma = exportSeparator
+	if err := writer.WriteAll(rows); err != nil {
+		return nil, err
+	}
+
+	return buffer.Bytes(), nil
+}
+
+// csvDecimal writes a number with a comma decimal separator and no thousand separator
+func csvDecimal(value float64, places int) string {
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		return ""
+	}
+	return strings.Replace(strconv.FormatFloat(value, 'f', places, 64), ".", ",", 1)
+}
+
+// yesNo writes a flag as the spreadsheet users read it
+func yesNo(value bool) string {
+	if value {
+		return "sim"
+	}
+	return "não"
+}
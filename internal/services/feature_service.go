@@ -0,0 +1,103 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"provisioning-assistant/internal/clock"
+	"provisioning-assistant/internal/domain"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const featureNamespace = "features"
+
+type FeatureService struct {
+	repository domain.StateRepository
+	defaults   map[string]bool
+	rollout    RolloutPolicy
+	clock      clock.Clock
+	logger     domain.Logger
+
+	rolloutMu       sync.Mutex
+	rolloutOutcomes map[string][]outcome
+}
+
+// NewFeatureService creates a feature flag service, flags listed in enabled start turned on. The steps under
+// a percentage rollout go back to the old path when their error rate breaks the policy.
+func NewFeatureService(repository domain.StateRepository, enabled []string, rollout RolloutPolicy, clock clock.Clock, logger domain.Logger) *FeatureService {
+	defaults := make(map[string]bool, len(enabled))
+	for _, name := range enabled {
+		defaults[normalizeFeature(name)] = true
+	}
+
+	return &FeatureService{
+		repository:      repository,
+		defaults:        defaults,
+		rollout:         rollout.withDefaults(),
+		clock:           clock,
+		logger:          logger,
+		rolloutOutcomes: make(map[string][]outcome),
+	}
+}
+
+// IsEnabled reports whether a feature is on, stored values take precedence over the defaults
+func (s *FeatureService) IsEnabled(ctx context.Context, name string) bool {
+	name = normalizeFeature(name)
+
+	value, err := s.repository.Get(ctx, featureNamespace, name)
+	if err != nil {
+		return s.defaults[name]
+	}
+
+	enabled, err := strconv.ParseBool(value)
+	if err != nil {
+		return s.defaults[name]
+	}
+
+	return enabled
+}
+
+// Set turns a feature on or off
+func (s *FeatureService) Set(ctx context.Context, name string, enabled bool) error {
+	name = normalizeFeature(name)
+	if name == "" {
+		return fmt.Errorf("nome de recurso vazio")
+	}
+
+	if err := s.repository.Set(ctx, featureNamespace, name, strconv.FormatBool(enabled)); err != nil {
+		return fmt.Errorf("falha ao salvar recurso %s: %w", name, err)
+	}
+
+	s.logger.WithFields(map[string]any{
+		"feature": name,
+		"enabled": enabled,
+	}).Info("Recurso atualizado")
+
+	return nil
+}
+
+// List returns the state of every known feature, sorted by name
+func (s *FeatureService) List(ctx context.Context) ([]string, map[string]bool) {
+	states := maps.Clone(s.defaults)
+
+	stored, err := s.repository.List(ctx, featureNamespace)
+	if err != nil {
+		s.logger.WithError(err).Warn("Falha ao listar recursos salvos")
+	}
+
+	for name, value := range stored {
+		if enabled, err := strconv.ParseBool(value); err == nil {
+			states[name] = enabled
+		}
+	}
+
+	return slices.Sorted(maps.Keys(states)), states
+}
+
+// normalizeFeature makes feature names case insensitive
+func normalizeFeature(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
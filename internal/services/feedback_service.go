@@ -0,0 +1,113 @@
+package services
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"provisioning-assistant/internal/domain"
+	"provisioning-assistant/internal/ids"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	feedbackNamespace = "feedback"
+
+	// FeedbackMaxCommentLength bounds the free text kept with a rating
+	FeedbackMaxCommentLength = 1000
+)
+
+type FeedbackService struct {
+	repository domain.StateRepository
+	logger     domain.Logger
+}
+
+// NewFeedbackService creates the store of technician feedback about the bot
+func NewFeedbackService(repository domain.StateRepository, logger domain.Logger) *FeedbackService {
+	return &FeedbackService{
+		repository: repository,
+		logger:     logger,
+	}
+}
+
+// Submit validates and stores a feedback, dropping the identity of the technician when anonymous
+func (s *FeedbackService) Submit(ctx context.Context, feedback *domain.Feedback) error {
+	if feedback.Rating < domain.FeedbackMinRating || feedback.Rating > domain.FeedbackMaxRating {
+		return fmt.Errorf("nota fora do intervalo de %d a %d", domain.FeedbackMinRating, domain.FeedbackMaxRating)
+	}
+
+	feedback.Comment = strings.TrimSpace(feedback.Comment)
+	if len([]rune(feedback.Comment)) > FeedbackMaxCommentLength {
+		feedback.Comment = string([]rune(feedback.Comment)[:FeedbackMaxCommentLength])
+	}
+
+	if feedback.Anonymous {
+		feedback.UserID = 0
+		feedback.UserName = ""
+	}
+
+	feedback.CreatedAt = time.Now()
+	feedback.ID = ids.New()
+
+	value, err := json.Marshal(feedback)
+	if err != nil {
+		return fmt.Errorf("falha ao serializar feedback: %w", err)
+	}
+
+	if err := s.repository.Set(ctx, feedbackNamespace, feedback.ID, string(value)); err != nil {
+		return fmt.Errorf("falha ao salvar feedback: %w", err)
+	}
+
+	s.logger.WithFields(map[string]any{
+		"feedback_id": feedback.ID,
+		"job_id":      feedback.JobID,
+		"rating":      feedback.Rating,
+		"anonymous":   feedback.Anonymous,
+	}).Info("Feedback registrado")
+
+	return nil
+}
+
+// Summarize aggregates the feedback received since the given time, comments newest first
+func (s *FeedbackService) Summarize(ctx context.Context, since time.Time) (*domain.FeedbackSummary, error) {
+	values, err := s.repository.List(ctx, feedbackNamespace)
+	if err != nil {
+		return nil, fmt.Errorf("falha ao listar feedback: %w", err)
+	}
+
+	summary := &domain.FeedbackSummary{Since: since}
+	total := 0
+
+	for key, value := range values {
+		var feedback domain.Feedback
+		if err := json.Unmarshal([]byte(value), &feedback); err != nil {
+			s.logger.WithError(err).WithField("feedback_id", key).Warn("Feedback ilegível ignorado")
+			continue
+		}
+
+		if feedback.CreatedAt.Before(since) || feedback.Rating < domain.FeedbackMinRating || feedback.Rating > domain.FeedbackMaxRating {
+			continue
+		}
+
+		summary.Count++
+		summary.Ratings[feedback.Rating]++
+		total += feedback.Rating
+
+		if feedback.Comment != "" {
+			summary.Comments = append(summary.Comments, &feedback)
+		}
+	}
+
+	if summary.Count > 0 {
+		summary.Average = float64(total) / float64(summary.Count)
+	}
+
+	// Feedback stored before the ULIDs has numeric IDs, the time keeps them in place
+	slices.SortFunc(summary.Comments, func(a, b *domain.Feedback) int {
+		return cmp.Or(b.CreatedAt.Compare(a.CreatedAt), cmp.Compare(b.ID, a.ID))
+	})
+
+	return summary, nil
+}
@@ -0,0 +1,110 @@
+package services
+
+import (
+	"cmp"
+	"provisioning-assistant/internal/clock"
+	"provisioning-assistant/internal/domain"
+	"slices"
+	"sync"
+	"time"
+)
+
+// FlowMetrics records the state transitions of the conversation flow and the time spent in each
+// state, telling which steps technicians struggle with
+type FlowMetrics struct {
+	since       time.Time
+	states      map[domain.SessionState]*domain.StateMetrics
+	transitions map[domain.StateTransition]uint64
+	mu          sync.Mutex
+}
+
+// NewFlowMetrics creates an empty flow metrics recorder
+func NewFlowMetrics(clock clock.Clock) *FlowMetrics {
+	return &FlowMetrics{
+		since:       clock.Now(),
+		states:      make(map[domain.SessionState]*domain.StateMetrics),
+		transitions: make(map[domain.StateTransition]uint64),
+	}
+}
+
+// Enter records a session starting in a state
+func (m *FlowMetrics) Enter(state domain.SessionState) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.state(state).Entries++
+}
+
+// Transition records a session leaving a state after the given dwell time
+func (m *FlowMetrics) Transition(from, to domain.SessionState, dwell time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	left := m.state(from)
+	left.Exits++
+	if dwell > 0 {
+		left.DwellTotal += dwell
+		left.DwellMax = max(left.DwellMax, dwell)
+	}
+
+	m.state(to).Entries++
+	m.transitions[domain.StateTransition{From: from, To: to}]++
+}
+
+// Abandon records a session that expired in a state
+func (m *FlowMetrics) Abandon(state domain.SessionState) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.state(state).Abandoned++
+}
+
+// InvalidInput records an input rejected in a state
+func (m *FlowMetrics) InvalidInput(state domain.SessionState) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.state(state).InvalidInputs++
+}
+
+// Snapshot returns a copy of the metrics, states by name and transitions most frequent first
+func (m *FlowMetrics) Snapshot() *domain.FlowSnapshot {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	snapshot := &domain.FlowSnapshot{
+		Since:       m.since,
+		States:      make([]domain.StateMetrics, 0, len(m.states)),
+		Transitions: make([]domain.TransitionCount, 0, len(m.transitions)),
+	}
+
+	for _, metrics := range m.states {
+		snapshot.States = append(snapshot.States, *metrics)
+	}
+	slices.SortFunc(snapshot.States, func(a, b domain.StateMetrics) int {
+		return cmp.Compare(a.State, b.State)
+	})
+
+	for transition, count := range m.transitions {
+		snapshot.Transitions = append(snapshot.Transitions, domain.TransitionCount{StateTransition: transition, Count: count})
+	}
+	slices.SortFunc(snapshot.Transitions, func(a, b domain.TransitionCount) int {
+		return cmp.Or(
+			cmp.Compare(b.Count, a.Count),
+			cmp.Compare(a.From, b.From),
+			cmp.Compare(a.To, b.To),
+		)
+	})
+
+	return snapshot
+}
+
+// state returns the metrics of a state, creating them on first use
+func (m *FlowMetrics) state(state domain.SessionState) *domain.StateMetrics {
+	metrics, exists := m.states[state]
+	if !exists {
+		metrics = &domain.StateMetrics{State: state}
+		m.states[state] = metrics
+	}
+	return metrics
+}
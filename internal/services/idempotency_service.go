@@ -0,0 +1,201 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"provisioning-assistant/internal/clock"
+	"provisioning-assistant/internal/domain"
+	"sync"
+	"time"
+)
+
+const (
+	idempotencyNamespace      = "idempotency"
+	DefaultIdempotencyWindow  = 30 * time.Minute
+	IdempotencyPendingTimeout = 5 * time.Minute
+)
+
+// ErrProvisioningInFlight tells that the same protocol and serial is being provisioned right now
+var ErrProvisioningInFlight = errors.New("provisionamento do mesmo protocolo e serial em andamento")
+
+type IdempotencyService struct {
+	repository domain.StateRepository
+	window     time.Duration
+	clock      clock.Clock
+	logger     domain.Logger
+	mu         sync.Mutex
+}
+
+// NewIdempotencyService creates the registry of provisioning runs keyed by protocol and serial,
+// a successful run is reused for the lookback window and a non-positive window disables it
+func NewIdempotencyService(repository domain.StateRepository, window time.Duration, clock clock.Clock, logger domain.Logger) *IdempotencyService {
+	return &IdempotencyService{
+		repository: repository,
+		window:     window,
+		clock:      clock,
+		logger:     logger,
+	}
+}
+
+// IsEnabled reports whether a lookback window is configured
+func (s *IdempotencyService) IsEnabled() bool {
+	return s.window > 0
+}
+
+// Begin records a run before it touches the OLT. A run of the same key completed within the window
+// is returned instead, so the caller can reuse its result, and a run still in progress is refused
+// with ErrProvisioningInFlight. Abandoned runs stop blocking after IdempotencyPendingTimeout.
+func (s *IdempotencyService) Begin(ctx context.Context, key, jobID, userName string) (*domain.IdempotencyRecord, error) {
+	if !s.IsEnabled() {
+		return nil, nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.clock.Now()
+
+	previous, err := s.get(ctx, key)
+	if err != nil {
+		s.logger.WithError(err).WithField("key", key).Warn("Falha ao ler registro de idempotência")
+	}
+
+	if previous != nil {
+		switch {
+		case previous.IsCompleted() && now.Sub(*previous.CompletedAt) < s.window:
+			return previous, nil
+		case !previous.IsCompleted() && now.Sub(previous.StartedAt) < IdempotencyPendingTimeout:
+			return nil, ErrProvisioningInFlight
+		}
+	}
+
+	record := &domain.IdempotencyRecord{
+		Key:       key,
+		JobID:     jobID,
+		UserName:  userName,
+		StartedAt: now,
+	}
+
+	return nil, s.put(ctx, record)
+}
+
+// Complete stores the result of a successful run for reuse within the window, without the TL1 commands and
+// the snapshot of the replaced ONU, which carry customer credentials and stay in the audit trail only
+func (s *IdempotencyService) Complete(ctx context.Context, key, auditID string, result *domain.ProvisioningResult) {
+	if !s.IsEnabled() {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	record, err := s.get(ctx, key)
+	if err != nil || record == nil {
+		record = &domain.IdempotencyRecord{Key: key, StartedAt: s.clock.Now()}
+	}
+
+	completedAt := s.clock.Now()
+	record.AuditID = auditID
+	record.Result = replayable(result)
+	record.CompletedAt = &completedAt
+
+	if err := s.put(ctx, record); err != nil {
+		s.logger.WithError(err).WithField("key", key).Warn("Falha ao gravar resultado de idempotência")
+	}
+}
+
+// Release forgets a run that failed, so it can be tried again right away
+func (s *IdempotencyService) Release(ctx context.Context, key string) {
+	if !s.IsEnabled() {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.repository.Delete(ctx, idempotencyNamespace, key); err != nil {
+		s.logger.WithError(err).WithField("key", key).Warn("Falha ao liberar registro de idempotência")
+	}
+}
+
+// Purge clears the records that fell out of the window, returning how many were cleared
+func (s *IdempotencyService) Purge(ctx context.Context) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	values, err := s.repository.List(ctx, idempotencyNamespace)
+	if err != nil {
+		return 0, err
+	}
+
+	now := s.clock.Now()
+	var purged int
+
+	for key := range values {
+		// Unreadable records and the empty values left by earlier releases are cleared as well
+		record, err := s.get(ctx, key)
+		if err == nil && record != nil {
+			reference := record.StartedAt
+			if record.IsCompleted() {
+				reference = *record.CompletedAt
+			}
+			if now.Sub(reference) < max(s.window, IdempotencyPendingTimeout) {
+				continue
+			}
+		}
+
+		if err := s.repository.Delete(ctx, idempotencyNamespace, key); err != nil {
+			return purged, fmt.Errorf("falha ao limpar registro de idempotência %s: %w", key, err)
+		}
+		purged++
+	}
+
+	if purged > 0 {
+		s.logger.WithField("records", purged).Debug("Registros de idempotência expirados removidos")
+	}
+
+	return purged, nil
+}
+
+// get reads the record of a key, nil when there is none
+func (s *IdempotencyService) get(ctx context.Context, key string) (*domain.IdempotencyRecord, error) {
+	value, err := s.repository.Get(ctx, idempotencyNamespace, key)
+	if err != nil || value == "" {
+		return nil, nil
+	}
+
+	var record domain.IdempotencyRecord
+	if err := json.Unmarshal([]byte(value), &record); err != nil {
+		return nil, fmt.Errorf("registro de idempotência %s corrompido: %w", key, err)
+	}
+
+	return &record, nil
+}
+
+// put stores the record of a key
+func (s *IdempotencyService) put(ctx context.Context, record *domain.IdempotencyRecord) error {
+	value, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	if err := s.repository.Set(ctx, idempotencyNamespace, record.Key, string(value)); err != nil {
+		return fmt.Errorf("falha ao gravar registro de idempotência %s: %w", record.Key, err)
+	}
+
+	return nil
+}
+
+// replayable copies a result with what the replay of a run shows only
+func replayable(result *domain.ProvisioningResult) *domain.ProvisioningResult {
+	if result == nil {
+		return nil
+	}
+
+	replayed := *result
+	replayed.Previous = nil
+	replayed.Commands = nil
+	return &replayed
+}
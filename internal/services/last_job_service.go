@@ -0,0 +1,62 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"provisioning-assistant/internal/clock"
+	"provisioning-assistant/internal/domain"
+	"strconv"
+	"time"
+)
+
+const lastJobNamespace = "last_jobs"
+
+const LastJobValidity = 4 * time.Hour
+
+type LastJobService struct {
+	repository domain.StateRepository
+	clock      clock.Clock
+	logger     domain.Logger
+}
+
+// NewLastJobService creates a new last job context store, kept in the state repository so a restart or
+// another process does not lose the re-check of the last ONU
+func NewLastJobService(repository domain.StateRepository, clock clock.Clock, logger domain.Logger) *LastJobService {
+	return &LastJobService{
+		repository: repository,
+		clock:      clock,
+		logger:     logger,
+	}
+}
+
+// Save stores the last successful job of a user, a failure only costs the quick re-check
+func (s *LastJobService) Save(ctx context.Context, job domain.LastJob) {
+	value, err := json.Marshal(job)
+	if err == nil {
+		err = s.repository.Set(ctx, lastJobNamespace, strconv.FormatInt(job.UserID, 10), string(value))
+	}
+
+	if err != nil {
+		s.logger.WithError(err).WithField("user_id", job.UserID).Warn("Falha ao registrar último atendimento do usuário")
+	}
+}
+
+// Get returns the last job of a user while it is still valid for quick re-checks
+func (s *LastJobService) Get(ctx context.Context, userID int64) *domain.LastJob {
+	value, err := s.repository.Get(ctx, lastJobNamespace, strconv.FormatInt(userID, 10))
+	if err != nil {
+		return nil
+	}
+
+	var job domain.LastJob
+	if err := json.Unmarshal([]byte(value), &job); err != nil {
+		s.logger.WithError(err).WithField("user_id", userID).Warn("Último atendimento inválido ignorado")
+		return nil
+	}
+
+	if s.clock.Since(job.FinishedAt) > LastJobValidity {
+		return nil
+	}
+
+	return &job
+}
@@ -0,0 +1,71 @@
+package services
+
+import (
+	"slices"
+	"sync"
+	"time"
+)
+
+// LatencyTracker keeps a rolling window of operation latencies to derive percentiles
+type LatencyTracker struct {
+	samples []time.Duration
+	next    int
+	full    bool
+	mu      sync.RWMutex
+}
+
+// NewLatencyTracker creates a new rolling latency window holding up to size samples
+func NewLatencyTracker(size int) *LatencyTracker {
+	if size <= 0 {
+		size = 1
+	}
+
+	return &LatencyTracker{
+		samples: make([]time.Duration, size),
+	}
+}
+
+// Observe records a latency sample, replacing the oldest one when the window is full
+func (t *LatencyTracker) Observe(latency time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.samples[t.next] = latency
+	t.next = (t.next + 1) % len(t.samples)
+	if t.next == 0 {
+		t.full = true
+	}
+}
+
+// Count returns how many samples are in the window
+func (t *LatencyTracker) Count() int {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	return t.countLocked()
+}
+
+// Percentile returns the latency below which the given fraction of samples fall, zero without samples
+func (t *LatencyTracker) Percentile(p float64) time.Duration {
+	t.mu.RLock()
+	window := slices.Clone(t.samples[:t.countLocked()])
+	t.mu.RUnlock()
+
+	if len(window) == 0 {
+		return 0
+	}
+
+	slices.Sort(window)
+
+	index := int(p*float64(len(window))+0.5) - 1
+	index = max(0, min(index, len(window)-1))
+	return window[index]
+}
+
+// countLocked returns the number of samples, must be called with the lock held
+func (t *LatencyTracker) countLocked() int {
+	if t.full {
+		return len(t.samples)
+	}
+	return t.next
+}
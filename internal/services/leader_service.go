@@ -0,0 +1,142 @@
+package services
+
+import (
+	"context"
+	"maps"
+	"provisioning-assistant/internal/domain"
+	"sync"
+	"time"
+)
+
+const (
+	// Leader election constants
+	LeaderRetryInterval = 15 * time.Second
+	LeaderCheckInterval = 10 * time.Second
+	LeaderLockTimeout   = 5 * time.Second
+)
+
+type LeaderService struct {
+	lock   domain.LeaderLock
+	logger domain.Logger
+
+	mu      sync.RWMutex
+	leading map[string]bool
+}
+
+// NewLeaderService creates a leader election service, a nil lock means a single replica that always leads
+func NewLeaderService(lock domain.LeaderLock, logger domain.Logger) *LeaderService {
+	return &LeaderService{
+		lock:    lock,
+		logger:  logger,
+		leading: make(map[string]bool),
+	}
+}
+
+// RunExclusive runs a background job only while this replica holds its leadership, blocking until ctx is done
+func (s *LeaderService) RunExclusive(ctx context.Context, name string, job func(ctx context.Context)) {
+	log := s.logger.WithField("job", name)
+
+	if s.lock == nil {
+		s.setLeading(name, true)
+		defer s.setLeading(name, false)
+		job(ctx)
+		return
+	}
+
+	for {
+		if s.tryAcquire(ctx, name, log) {
+			log.Info("Liderança adquirida para tarefa em segundo plano")
+			s.setLeading(name, true)
+			s.lead(ctx, name, job, log)
+			s.setLeading(name, false)
+			s.release(name, log)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(LeaderRetryInterval):
+		}
+	}
+}
+
+// IsLeader reports whether this replica currently runs the given job
+func (s *LeaderService) IsLeader(name string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.leading[name]
+}
+
+// Status returns the leadership state of every known job
+func (s *LeaderService) Status() map[string]bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return maps.Clone(s.leading)
+}
+
+// lead runs the job and cancels it as soon as the lock can no longer be confirmed
+func (s *LeaderService) lead(ctx context.Context, name string, job func(ctx context.Context), log domain.Logger) {
+	jobCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		job(jobCtx)
+	}()
+
+	ticker := time.NewTicker(LeaderCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			checkCtx, checkCancel := context.WithTimeout(ctx, LeaderLockTimeout)
+			err := s.lock.Check(checkCtx)
+			checkCancel()
+
+			if err != nil && ctx.Err() == nil {
+				log.WithError(err).Warn("Liderança perdida, interrompendo tarefa")
+				cancel()
+				<-done
+				return
+			}
+		}
+	}
+}
+
+// tryAcquire attempts to take the job lock
+func (s *LeaderService) tryAcquire(ctx context.Context, name string, log domain.Logger) bool {
+	lockCtx, cancel := context.WithTimeout(ctx, LeaderLockTimeout)
+	defer cancel()
+
+	acquired, err := s.lock.TryAcquire(lockCtx, name)
+	if err != nil && ctx.Err() == nil {
+		log.WithError(err).Warn("Falha ao disputar liderança")
+		return false
+	}
+
+	return acquired
+}
+
+// release gives the job lock back so another replica can take over
+func (s *LeaderService) release(name string, log domain.Logger) {
+	ctx, cancel := context.WithTimeout(context.Background(), LeaderLockTimeout)
+	defer cancel()
+
+	if err := s.lock.Release(ctx, name); err != nil {
+		log.WithError(err).Warn("Falha ao liberar liderança")
+	}
+}
+
+// setLeading updates the leadership state of a job
+func (s *LeaderService) setLeading(name string, leading bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.leading[name] = leading
+}
@@ -0,0 +1,266 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"provisioning-assistant/internal/clock"
+	"provisioning-assistant/internal/domain"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultShedLatency is the p95 of the TL1 commands above which the UNM is taken as saturated
+	DefaultShedLatency = 3 * time.Second
+
+	// DefaultShedQueueDepth is how many provisioning jobs waiting for a slot saturate the UNM
+	DefaultShedQueueDepth = 4
+
+	// DefaultShedCooldown is how long the readings must stay healthy before the deferred operations resume
+	DefaultShedCooldown = 2 * time.Minute
+
+	// DefaultShedMaxDeferral bounds how long a scheduled operation waits for the UNM before giving up its run
+	DefaultShedMaxDeferral = 30 * time.Minute
+
+	// shedLatencyWindow is how many TL1 commands the latency is measured over
+	shedLatencyWindow = 50
+
+	// shedMinSamples avoids judging the UNM on a handful of commands
+	shedMinSamples = 10
+
+	// shedPollInterval is how often a deferred operation checks whether it may run
+	shedPollInterval = 30 * time.Second
+
+	// shedDecisionLog bounds the decisions kept for /status
+	shedDecisionLog = 10
+)
+
+// ErrLoadShed is returned to the non-critical operations refused while the UNM is saturated
+var ErrLoadShed = errors.New("operação adiada, UNM saturado")
+
+// Non-critical operations deferred while the UNM is saturated
+const (
+	ShedPonSnapshot     = "pon_occupancy_snapshot"
+	ShedOrphanDetection = "orphan_onu_detection"
+	ShedPonPrefetch     = "pon_prefetch"
+	ShedWifiScan        = "wifi_scan"
+	ShedOnuHistory      = "onu_history"
+	ShedOltHealth       = "olt_health"
+	ShedPonCapacity     = "pon_capacity"
+	ShedDeprovision     = "cancelled_deprovisioning"
+)
+
+// LoadShedPolicy configures when the non-critical operations give way to the activations
+type LoadShedPolicy struct {
+	Enabled     bool
+	Latency     time.Duration
+	QueueDepth  int
+	Cooldown    time.Duration
+	MaxDeferral time.Duration
+}
+
+// LoadShedDecision is an operation deferred or refused while the UNM was saturated
+type LoadShedDecision struct {
+	Operation string
+	Reason    string
+	At        time.Time
+}
+
+// LoadShedStatus is the saturation of the UNM as seen by the shedder
+type LoadShedStatus struct {
+	Enabled   bool
+	Shedding  bool
+	Reason    string
+	Since     time.Time
+	P95       time.Duration
+	Waiting   int
+	Deferred  map[string]int
+	Decisions []LoadShedDecision
+}
+
+type LoadShedder struct {
+	latency *LatencyTracker
+	queue   *ProvisioningQueue
+	policy  LoadShedPolicy
+	clock   clock.Clock
+	logger  domain.Logger
+
+	mu         sync.Mutex
+	observedAt time.Time
+	shedding   bool
+	reason     string
+	since      time.Time
+	healthyAt  time.Time
+	deferred   map[string]int
+	decisions  []LoadShedDecision
+}
+
+// NewLoadShedder creates the guard deferring the non-critical operations while the UNM is saturated, judged
+// by the latency of the TL1 commands and the provisioning jobs waiting for a slot
+func NewLoadShedder(queue *ProvisioningQueue, policy LoadShedPolicy, clock clock.Clock, logger domain.Logger) *LoadShedder {
+	if policy.Latency <= 0 {
+		policy.Latency = DefaultShedLatency
+	}
+	if policy.QueueDepth <= 0 {
+		policy.QueueDepth = DefaultShedQueueDepth
+	}
+	if policy.Cooldown <= 0 {
+		policy.Cooldown = DefaultShedCooldown
+	}
+	if policy.MaxDeferral <= 0 {
+		policy.MaxDeferral = DefaultShedMaxDeferral
+	}
+
+	return &LoadShedder{
+		latency:  NewLatencyTracker(shedLatencyWindow),
+		queue:    queue,
+		policy:   policy,
+		clock:    clock,
+		logger:   logger,
+		deferred: make(map[string]int),
+	}
+}
+
+// ObserveLatency records how long a TL1 command took
+func (s *LoadShedder) ObserveLatency(latency time.Duration) {
+	s.latency.Observe(latency)
+
+	s.mu.Lock()
+	s.observedAt = s.clock.Now()
+	s.mu.Unlock()
+}
+
+// Allow reports whether a non-critical operation may run now, recording the refusal otherwise
+func (s *LoadShedder) Allow(operation string) bool {
+	if !s.evaluate() {
+		return true
+	}
+
+	s.record(operation)
+	return false
+}
+
+// Defer waits for the UNM to leave saturation before a scheduled operation runs, giving up with ErrLoadShed
+// after the maximum deferral
+func (s *LoadShedder) Defer(ctx context.Context, operation string) error {
+	if !s.evaluate() {
+		return nil
+	}
+
+	s.record(operation)
+	deadline := s.clock.Now().Add(s.policy.MaxDeferral)
+
+	for s.evaluate() {
+		if !s.clock.Now().Before(deadline) {
+			return fmt.Errorf("%w: %s não executado após %s", ErrLoadShed, operation, s.policy.MaxDeferral)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(shedPollInterval):
+		}
+	}
+
+	s.logger.WithField("operation", operation).Info("Operação adiada retomada, UNM normalizado")
+	return nil
+}
+
+// Deferring wraps a scheduled run so it waits for the UNM to leave saturation first
+func (s *LoadShedder) Deferring(operation string, run func(ctx context.Context) error) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		if err := s.Defer(ctx, operation); err != nil {
+			return err
+		}
+		return run(ctx)
+	}
+}
+
+// Status returns the current readings and the latest decisions
+func (s *LoadShedder) Status() LoadShedStatus {
+	s.evaluate()
+	_, waiting := s.queue.Jobs()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return LoadShedStatus{
+		Enabled:   s.policy.Enabled,
+		Shedding:  s.shedding,
+		Reason:    s.reason,
+		Since:     s.since,
+		P95:       s.latency.Percentile(0.95),
+		Waiting:   len(waiting),
+		Deferred:  maps.Clone(s.deferred),
+		Decisions: append([]LoadShedDecision(nil), s.decisions...),
+	}
+}
+
+// evaluate compares the readings with the thresholds and reports whether the operations are being shed. The
+// shedding only stops once the readings stayed healthy for the cooldown, so it does not flap.
+func (s *LoadShedder) evaluate() bool {
+	if !s.policy.Enabled {
+		return false
+	}
+
+	reason := s.saturation()
+	now := s.clock.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch {
+	case reason != "" && !s.shedding:
+		s.shedding, s.reason, s.since = true, reason, now
+		s.logger.WithField("reason", reason).Warn("UNM saturado, operações não críticas adiadas")
+	case reason != "":
+		s.reason = reason
+		s.healthyAt = time.Time{}
+	case s.shedding && s.healthyAt.IsZero():
+		s.healthyAt = now
+	case s.shedding && now.Sub(s.healthyAt) >= s.policy.Cooldown:
+		s.logger.WithField("duration", now.Sub(s.since).String()).Info("UNM normalizado, operações não críticas liberadas")
+		s.shedding, s.reason, s.since, s.healthyAt = false, "", time.Time{}, time.Time{}
+	}
+
+	return s.shedding
+}
+
+// saturation describes the reading above its threshold, empty when the UNM is healthy. The latency only counts
+// while commands keep flowing, an idle UNM is not saturated whatever its last commands took.
+func (s *LoadShedder) saturation() string {
+	if _, waiting := s.queue.Jobs(); len(waiting) >= s.policy.QueueDepth {
+		return fmt.Sprintf("%d jobs na fila de provisionamento (limite %d)", len(waiting), s.policy.QueueDepth)
+	}
+
+	s.mu.Lock()
+	recent := s.clock.Since(s.observedAt) < s.policy.Cooldown
+	s.mu.Unlock()
+
+	if recent && s.latency.Count() >= shedMinSamples {
+		if p95 := s.latency.Percentile(0.95); p95 >= s.policy.Latency {
+			return fmt.Sprintf("latência TL1 p95 de %s (limite %s)", p95.Round(time.Millisecond), s.policy.Latency)
+		}
+	}
+
+	return ""
+}
+
+// record logs a deferred operation and keeps it for /status
+func (s *LoadShedder) record(operation string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.deferred[operation]++
+	s.decisions = append(s.decisions, LoadShedDecision{Operation: operation, Reason: s.reason, At: s.clock.Now()})
+	if len(s.decisions) > shedDecisionLog {
+		s.decisions = s.decisions[len(s.decisions)-shedDecisionLog:]
+	}
+
+	s.logger.WithFields(map[string]any{
+		"operation": operation,
+		"reason":    s.reason,
+	}).Warn("Operação não crítica adiada por saturação do UNM")
+}
@@ -0,0 +1,155 @@
+package services
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"provisioning-assistant/internal/clock"
+	"provisioning-assistant/internal/domain"
+	"slices"
+	"time"
+)
+
+const maintenanceNamespace = "maintenance_windows"
+
+// MaxMaintenanceWindow bounds a window, a forgotten one must not silence an OLT for days
+const MaxMaintenanceWindow = 24 * time.Hour
+
+type MaintenanceService struct {
+	repository domain.StateRepository
+	clock      clock.Clock
+	logger     domain.Logger
+}
+
+// NewMaintenanceService creates the store of the maintenance windows of each OLT
+func NewMaintenanceService(repository domain.StateRepository, clock clock.Clock, logger domain.Logger) *MaintenanceService {
+	return &MaintenanceService{
+		repository: repository,
+		clock:      clock,
+		logger:     logger,
+	}
+}
+
+// Schedule stores the window of an OLT, replacing the one it had
+func (s *MaintenanceService) Schedule(ctx context.Context, window domain.MaintenanceWindow) error {
+	if net.ParseIP(window.Olt) == nil {
+		return fmt.Errorf("IP de OLT inválido: %s", window.Olt)
+	}
+
+	if !window.End.After(window.Start) {
+		return fmt.Errorf("janela de manutenção termina antes de começar")
+	}
+
+	if window.End.Sub(window.Start) > MaxMaintenanceWindow {
+		return fmt.Errorf("janela de manutenção maior que %s", MaxMaintenanceWindow)
+	}
+
+	if window.IsOver(s.clock.Now()) {
+		return fmt.Errorf("janela de manutenção já encerrada")
+	}
+
+	if err := s.save(ctx, window); err != nil {
+		return err
+	}
+
+	s.logger.WithFields(map[string]any{
+		"olt":   window.Olt,
+		"start": window.Start,
+		"end":   window.End,
+		"by":    window.CreatedBy,
+	}).Info("Janela de manutenção agendada")
+
+	return nil
+}
+
+// Finish ends the window of an OLT now, a window not started yet is dropped
+func (s *MaintenanceService) Finish(ctx context.Context, olt string) error {
+	window, err := s.get(ctx, olt)
+	if err != nil || window.IsOver(s.clock.Now()) {
+		return fmt.Errorf("nenhuma janela de manutenção aberta para a OLT %s", olt)
+	}
+
+	now := s.clock.Now()
+	window.End = now
+	if window.Start.After(now) {
+		window.Start = now
+	}
+
+	if err := s.save(ctx, *window); err != nil {
+		return err
+	}
+
+	s.logger.WithField("olt", olt).Info("Janela de manutenção encerrada")
+	return nil
+}
+
+// Active returns the window in progress on an OLT
+func (s *MaintenanceService) Active(ctx context.Context, olt string) (*domain.MaintenanceWindow, bool) {
+	window, err := s.get(ctx, olt)
+	if err != nil || !window.Covers(s.clock.Now()) {
+		return nil, false
+	}
+	return window, true
+}
+
+// InMaintenance reports whether an OLT is in a window, the nightly jobs working per OLT skip it
+func (s *MaintenanceService) InMaintenance(ctx context.Context, olt string) bool {
+	_, active := s.Active(ctx, olt)
+	return active
+}
+
+// Windows returns the windows in progress or still to come, sorted by start
+func (s *MaintenanceService) Windows(ctx context.Context) []domain.MaintenanceWindow {
+	stored, err := s.repository.List(ctx, maintenanceNamespace)
+	if err != nil {
+		s.logger.WithError(err).Warn("Falha ao listar janelas de manutenção")
+		return nil
+	}
+
+	now := s.clock.Now()
+	windows := make([]domain.MaintenanceWindow, 0, len(stored))
+	for olt, value := range stored {
+		var window domain.MaintenanceWindow
+		if err := json.Unmarshal([]byte(value), &window); err != nil {
+			s.logger.WithError(err).WithField("olt", olt).Warn("Janela de manutenção inválida ignorada")
+			continue
+		}
+		if !window.IsOver(now) {
+			windows = append(windows, window)
+		}
+	}
+
+	slices.SortFunc(windows, func(a, b domain.MaintenanceWindow) int {
+		return cmp.Or(a.Start.Compare(b.Start), cmp.Compare(a.Olt, b.Olt))
+	})
+	return windows
+}
+
+// get reads the last window stored for an OLT
+func (s *MaintenanceService) get(ctx context.Context, olt string) (*domain.MaintenanceWindow, error) {
+	value, err := s.repository.Get(ctx, maintenanceNamespace, olt)
+	if err != nil {
+		return nil, err
+	}
+
+	var window domain.MaintenanceWindow
+	if err := json.Unmarshal([]byte(value), &window); err != nil {
+		return nil, fmt.Errorf("falha ao interpretar janela de manutenção: %w", err)
+	}
+	return &window, nil
+}
+
+// save stores the window under its OLT
+func (s *MaintenanceService) save(ctx context.Context, window domain.MaintenanceWindow) error {
+	value, err := json.Marshal(window)
+	if err != nil {
+		return fmt.Errorf("falha ao serializar janela de manutenção: %w", err)
+	}
+
+	if err := s.repository.Set(ctx, maintenanceNamespace, window.Olt, string(value)); err != nil {
+		return fmt.Errorf("falha ao salvar janela de manutenção: %w", err)
+	}
+	return nil
+}
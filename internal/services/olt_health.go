@@ -0,0 +1,172 @@
+package services
+
+import (
+	"cmp"
+	"context"
+	"provisioning-assistant/internal/domain"
+	"slices"
+	"time"
+)
+
+const (
+	// OltHealthWindow is the period covered by the OLT health report
+	OltHealthWindow = 7 * 24 * time.Hour
+
+	// OltHealthMinJobs is how many jobs an OLT needs in the window before it is judged
+	OltHealthMinJobs = 3
+
+	// OltSlowFactor flags an OLT whose median job takes this many times the median of the fleet
+	OltSlowFactor = 1.5
+
+	// OltErrorRate flags an OLT where this share of the jobs failed
+	OltErrorRate = 0.2
+)
+
+// OltStepLatency is how long a TL1 step of the provisioning took on an OLT
+type OltStepLatency struct {
+	Step   string        `json:"step"`
+	Count  int           `json:"count"`
+	Median time.Duration `json:"median"`
+	P95    time.Duration `json:"p95"`
+}
+
+// OltHealth sums up the provisioning jobs run against an OLT
+type OltHealth struct {
+	OltIP          string           `json:"olt_ip"`
+	Jobs           int              `json:"jobs"`
+	Failures       int              `json:"failures"`
+	Commands       int              `json:"commands"`
+	FailedCommands int              `json:"failed_commands"`
+	Median         time.Duration    `json:"median"`
+	P95            time.Duration    `json:"p95"`
+	Steps          []OltStepLatency `json:"steps"`
+	Slow           bool             `json:"slow"`
+	ErrorProne     bool             `json:"error_prone"`
+}
+
+// FailureRate returns the share of the jobs that failed
+func (h OltHealth) FailureRate() float64 {
+	if h.Jobs == 0 {
+		return 0
+	}
+	return float64(h.Failures) / float64(h.Jobs)
+}
+
+// flags counts the problems found with the OLT
+func (h OltHealth) flags() int {
+	flags := 0
+	if h.Slow {
+		flags++
+	}
+	if h.ErrorProne {
+		flags++
+	}
+	return flags
+}
+
+// OltHealthReport is the health of every OLT provisioned in a period, the flagged ones first
+type OltHealthReport struct {
+	Since  time.Time     `json:"since"`
+	Median time.Duration `json:"median"`
+	Olts   []OltHealth   `json:"olts"`
+}
+
+// OltHealth aggregates the step timings and the failures of the audit records of the last week by OLT,
+// flagging the OLTs chronically slower than the fleet or failing too often
+func (s *AuditService) OltHealth(ctx context.Context) (*OltHealthReport, error) {
+	records, err := s.repository.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &OltHealthReport{Since: s.clock.Now().Add(-OltHealthWindow)}
+
+	type oltSamples struct {
+		health OltHealth
+		totals []time.Duration
+		steps  map[string][]time.Duration
+	}
+
+	byOlt := make(map[string]*oltSamples)
+	var fleet []time.Duration
+	for _, record := range records {
+		if record.OltIP == "" || record.CreatedAt.Before(report.Since) {
+			continue
+		}
+
+		samples, exists := byOlt[record.OltIP]
+		if !exists {
+			samples = &oltSamples{health: OltHealth{OltIP: record.OltIP}, steps: make(map[string][]time.Duration)}
+			byOlt[record.OltIP] = samples
+		}
+
+		samples.health.Jobs++
+		if !record.Success {
+			samples.health.Failures++
+		}
+		for _, command := range record.Commands {
+			samples.health.Commands++
+			if command.Error != "" {
+				samples.health.FailedCommands++
+			}
+		}
+
+		var total time.Duration
+		for _, step := range record.Steps {
+			if step.Name == domain.StepErpFetch {
+				continue
+			}
+			samples.steps[step.Name] = append(samples.steps[step.Name], step.Duration)
+			total += step.Duration
+		}
+		if total > 0 {
+			samples.totals = append(samples.totals, total)
+			fleet = append(fleet, total)
+		}
+	}
+
+	report.Median = percentile(fleet, 0.5)
+	for _, samples := range byOlt {
+		health := samples.health
+		health.Median = percentile(samples.totals, 0.5)
+		health.P95 = percentile(samples.totals, 0.95)
+
+		for step, durations := range samples.steps {
+			health.Steps = append(health.Steps, OltStepLatency{
+				Step:   step,
+				Count:  len(durations),
+				Median: percentile(durations, 0.5),
+				P95:    percentile(durations, 0.95),
+			})
+		}
+		slices.SortFunc(health.Steps, func(a, b OltStepLatency) int {
+			return cmp.Or(cmp.Compare(b.Median, a.Median), cmp.Compare(a.Step, b.Step))
+		})
+
+		if health.Jobs >= OltHealthMinJobs {
+			health.Slow = report.Median > 0 && float64(health.Median) >= float64(report.Median)*OltSlowFactor
+			health.ErrorProne = health.FailureRate() >= OltErrorRate
+		}
+		report.Olts = append(report.Olts, health)
+	}
+
+	slices.SortFunc(report.Olts, func(a, b OltHealth) int {
+		return cmp.Or(
+			cmp.Compare(b.flags(), a.flags()),
+			cmp.Compare(b.FailureRate(), a.FailureRate()),
+			cmp.Compare(b.Median, a.Median),
+			cmp.Compare(a.OltIP, b.OltIP),
+		)
+	})
+
+	return report, nil
+}
+
+// percentile returns the duration below which the given fraction of the samples fall, zero without samples
+func percentile(samples []time.Duration, p float64) time.Duration {
+	tracker := NewLatencyTracker(len(samples))
+	for _, sample := range samples {
+		tracker.Observe(sample)
+	}
+	return tracker.Percentile(p)
+}
@@ -0,0 +1,97 @@
+package services
+
+import (
+	"cmp"
+	"context"
+	"provisioning-assistant/internal/clock"
+	"provisioning-assistant/internal/domain"
+	"slices"
+	"time"
+)
+
+const (
+	// MaxOltSuggestions bounds the OLT keyboard of the manual wizard
+	MaxOltSuggestions = 6
+
+	// OltHistoryWindow is how far back the audit history is read to rank the OLTs
+	OltHistoryWindow = 90 * 24 * time.Hour
+)
+
+type OltSuggestionService struct {
+	auditService *AuditService
+	thresholds   *SignalThresholdService
+	clock        clock.Clock
+	logger       domain.Logger
+}
+
+// NewOltSuggestionService creates the ranking of the OLTs offered to a technician in the manual wizard
+func NewOltSuggestionService(auditService *AuditService, thresholds *SignalThresholdService, clock clock.Clock, logger domain.Logger) *OltSuggestionService {
+	return &OltSuggestionService{
+		auditService: auditService,
+		thresholds:   thresholds,
+		clock:        clock,
+		logger:       logger,
+	}
+}
+
+// Suggest returns the OLTs the technician most likely works on: the ones of their recent jobs first, then
+// the busiest OLTs of their region. Without a known region every OLT is ranked by how busy it is.
+func (s *OltSuggestionService) Suggest(ctx context.Context, userID int64) []string {
+	records, err := s.auditService.ListSince(ctx, s.clock.Now().Add(-OltHistoryWindow))
+	if err != nil {
+		s.logger.WithError(err).Warn("Falha ao ler histórico para sugestão de OLTs")
+	}
+
+	// Records come ordered by creation, the newest job of the technician ranks first
+	recent := make(map[string]int)
+	usage := make(map[string]int)
+	for i := len(records) - 1; i >= 0; i-- {
+		olt := records[i].OltIP
+		if olt == "" {
+			continue
+		}
+
+		usage[olt]++
+		if _, seen := recent[olt]; !seen && records[i].UserID == userID {
+			recent[olt] = len(recent)
+		}
+	}
+
+	candidates := s.thresholds.Olts()
+	for olt := range usage {
+		if !slices.Contains(candidates, olt) {
+			candidates = append(candidates, olt)
+		}
+	}
+
+	region := ""
+	for olt, rank := range recent {
+		if rank == 0 {
+			region = s.thresholds.Region(olt)
+		}
+	}
+
+	candidates = slices.DeleteFunc(candidates, func(olt string) bool {
+		_, own := recent[olt]
+		return region != "" && !own && s.thresholds.Region(olt) != region
+	})
+
+	slices.SortFunc(candidates, func(a, b string) int {
+		rankA, ownA := recent[a]
+		rankB, ownB := recent[b]
+		switch {
+		case ownA && ownB:
+			return cmp.Compare(rankA, rankB)
+		case ownA:
+			return -1
+		case ownB:
+			return 1
+		}
+		return cmp.Or(cmp.Compare(usage[b], usage[a]), cmp.Compare(a, b))
+	})
+
+	if len(candidates) > MaxOltSuggestions {
+		candidates = candidates[:MaxOltSuggestions]
+	}
+	return candidates
+}
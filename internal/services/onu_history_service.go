@@ -0,0 +1,105 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"provisioning-assistant/internal/domain"
+	"provisioning-assistant/internal/unm"
+	"slices"
+	"strings"
+	"time"
+)
+
+// OnuCommand is a TL1 command of the history of an ONU, with the audit record it was sent for
+type OnuCommand struct {
+	domain.Tl1Command
+	AuditID    string
+	AuditAt    time.Time
+	Technician string
+}
+
+type OnuHistoryService struct {
+	auditService   *AuditService
+	archiveService *ArchiveService
+	logger         domain.Logger
+}
+
+// NewOnuHistoryService creates the service rebuilding the TL1 command history of an ONU from the
+// audit records, archived ones included
+func NewOnuHistoryService(auditService *AuditService, archiveService *ArchiveService, logger domain.Logger) *OnuHistoryService {
+	return &OnuHistoryService{
+		auditService:   auditService,
+		archiveService: archiveService,
+		logger:         logger,
+	}
+}
+
+// History returns the TL1 commands ever sent for a serial, oldest first
+func (s *OnuHistoryService) History(ctx context.Context, serial string) ([]OnuCommand, error) {
+	records, err := s.records(ctx, serial)
+	if err != nil {
+		return nil, err
+	}
+
+	var history []OnuCommand
+	for _, record := range records {
+		for _, command := range record.Commands {
+			history = append(history, OnuCommand{
+				Tl1Command: command,
+				AuditID:    record.ID,
+				AuditAt:    record.CreatedAt,
+				Technician: record.TechnicianName,
+			})
+		}
+	}
+
+	slices.SortStableFunc(history, func(a, b OnuCommand) int {
+		return a.SentAt.Compare(b.SentAt)
+	})
+	return history, nil
+}
+
+// Replay rebuilds the configuration the OLT is meant to hold for a serial, as a dry run
+func (s *OnuHistoryService) Replay(ctx context.Context, serial string) (*unm.ReplayedOnu, error) {
+	history, err := s.History(ctx, serial)
+	if err != nil {
+		return nil, err
+	}
+
+	commands := make([]domain.Tl1Command, 0, len(history))
+	for _, entry := range history {
+		commands = append(commands, entry.Tl1Command)
+	}
+	return unm.Replay(commands), nil
+}
+
+// records gathers the audit records of a serial from the hot store and the archive
+func (s *OnuHistoryService) records(ctx context.Context, serial string) ([]*domain.AuditRecord, error) {
+	live, err := s.auditService.ListRecords(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("falha ao listar registros de auditoria: %w", err)
+	}
+
+	records := slices.DeleteFunc(live, func(record *domain.AuditRecord) bool {
+		return !strings.EqualFold(record.Serial, serial)
+	})
+
+	archived, err := s.archiveService.FindBySerial(ctx, serial)
+	if err != nil {
+		s.logger.WithError(err).WithField("serial", serial).Warn("Falha ao buscar histórico da ONU no arquivo morto")
+	}
+	for _, record := range archived {
+		if !slices.ContainsFunc(records, func(other *domain.AuditRecord) bool { return other.ID == record.ID }) {
+			records = append(records, record)
+		}
+	}
+
+	if len(records) == 0 {
+		return nil, fmt.Errorf("nenhum registro de auditoria encontrado para o serial %s", serial)
+	}
+
+	slices.SortFunc(records, func(a, b *domain.AuditRecord) int {
+		return a.CreatedAt.Compare(b.CreatedAt)
+	})
+	return records, nil
+}
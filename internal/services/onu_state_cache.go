@@ -0,0 +1,145 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"maps"
+	"provisioning-assistant/internal/domain"
+	"provisioning-assistant/internal/unm"
+	"strings"
+	"time"
+)
+
+const (
+	// OnuStateTTL is how long a reading of an ONU answers the status queries before the OLT is asked again
+	OnuStateTTL = 2 * time.Minute
+
+	// OnuOfflineTTL is how long an ONU that did not answer is reported offline, shorter so it is seen coming
+	// back soon
+	OnuOfflineTTL = 30 * time.Second
+
+	// onuStateRetention is how long an expired state is kept, so a later reading still knows the configuration
+	// fingerprint the bot applied
+	onuStateRetention = 24 * time.Hour
+)
+
+// onuStateKey identifies an ONU in the cache, training sessions reading the simulator apart from production
+type onuStateKey struct {
+	training bool
+	serial   string
+}
+
+// onuStateEntry is a cached state with the moment it stops answering the queries
+type onuStateEntry struct {
+	state     domain.OnuState
+	expiresAt time.Time
+}
+
+// OnuState returns the last known state of an ONU, reading it from the OLT only when the cached one expired.
+// An ONU the OLT reports without readings is offline, a query that was denied, malformed or did not reach the
+// UNM returns its error and leaves the cache alone.
+func (s *ProvisioningService) OnuState(ctx context.Context, job *domain.LastJob) (*domain.OnuState, error) {
+	// A cached reading is only shown to a role allowed to query the OLT itself
+	if err := s.permissions.Authorize(ctx, domain.Tl1OperationQuery); err != nil {
+		return nil, err
+	}
+
+	key := onuKey(ctx, job.Serial)
+
+	s.onuStatesMu.Lock()
+	entry, exists := s.onuStates[key]
+	s.onuStatesMu.Unlock()
+
+	if exists && s.clock.Now().Before(entry.expiresAt) {
+		state := entry.state
+		return &state, nil
+	}
+
+	signal, err := s.checkSignal(ctx, job)
+	if err != nil && !isOnuNoAnswer(err) {
+		return nil, err
+	}
+	if err != nil {
+		s.logger.WithError(err).WithField("serial", job.Serial).Debug("ONU sem resposta na consulta de estado")
+	}
+
+	state := s.rememberOnuState(ctx, job, signal, "")
+	return &state, nil
+}
+
+// rememberOnuState caches a fresh reading of an ONU, nil when it did not answer. The fingerprint of the
+// configuration applied by the bot is kept across readings.
+func (s *ProvisioningService) rememberOnuState(ctx context.Context, job *domain.LastJob, signal *domain.OnuSignalInfo, fingerprint string) domain.OnuState {
+	key := onuKey(ctx, job.Serial)
+	now := s.clock.Now()
+
+	state := domain.OnuState{
+		Serial:    job.Serial,
+		OltIP:     job.OltIP,
+		Slot:      job.Slot,
+		Port:      job.Port,
+		Online:    signal != nil,
+		Signal:    signal,
+		CheckedAt: now,
+	}
+
+	ttl := OnuStateTTL
+	if signal == nil {
+		ttl = OnuOfflineTTL
+	}
+
+	s.onuStatesMu.Lock()
+	defer s.onuStatesMu.Unlock()
+
+	maps.DeleteFunc(s.onuStates, func(_ onuStateKey, entry *onuStateEntry) bool {
+		return now.Sub(entry.expiresAt) > onuStateRetention
+	})
+
+	state.Fingerprint = fingerprint
+	if previous, exists := s.onuStates[key]; exists && fingerprint == "" {
+		state.Fingerprint = previous.state.Fingerprint
+	}
+	s.onuStates[key] = &onuStateEntry{state: state, expiresAt: now.Add(ttl)}
+	return state
+}
+
+// isOnuNoAnswer reports whether the OLT answered the query of an ONU without its readings, as it does for an
+// ONU that is offline. Failures to reach or log in to the UNM do not carry the error of the response.
+func isOnuNoAnswer(err error) bool {
+	return errors.Is(err, unm.ErrServerError) || errors.Is(err, unm.ErrInsufficientData)
+}
+
+// InvalidateOnu drops the cached state of an ONU whose registration changed, the next query reads the OLT
+func (s *ProvisioningService) InvalidateOnu(ctx context.Context, serial string) {
+	s.onuStatesMu.Lock()
+	delete(s.onuStates, onuKey(ctx, serial))
+	s.onuStatesMu.Unlock()
+
+	s.logger.WithField("serial", serial).Debug("Estado da ONU invalidado")
+}
+
+// onuKey identifies the ONU of the context, serials are compared in uppercase as the OLT reports them
+func onuKey(ctx context.Context, serial string) onuStateKey {
+	return onuStateKey{training: domain.IsTraining(ctx), serial: strings.ToUpper(strings.TrimSpace(serial))}
+}
+
+// configFingerprint identifies the configuration applied to an ONU, passwords left out
+func configFingerprint(config unm.OnuProvisioningConfig) string {
+	config.PPPoEPass = ""
+	config.Reconfigure = false
+	config.ExtraWanServices = append([]unm.WanService(nil), config.ExtraWanServices...)
+	for i := range config.ExtraWanServices {
+		config.ExtraWanServices[i].PPPoEPass = ""
+	}
+
+	content, err := json.Marshal(config)
+	if err != nil {
+		return ""
+	}
+
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:8])
+}
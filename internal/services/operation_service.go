@@ -0,0 +1,159 @@
+package services
+
+import (
+	"errors"
+	"provisioning-assistant/internal/clock"
+	"provisioning-assistant/internal/domain"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// Destructive operation approval constants
+	DefaultOperationTTL     = 30 * time.Minute
+	OperationPhrasePrefix   = "CONFIRMAR"
+	OperationDecisionWindow = 24 * time.Hour
+)
+
+var (
+	ErrOperationNotFound       = errors.New("operação não encontrada ou expirada")
+	ErrOperationNotRequester   = errors.New("somente o solicitante pode confirmar a operação")
+	ErrOperationPhraseMismatch = errors.New("frase de confirmação incorreta")
+	ErrOperationInvalidStatus  = errors.New("operação não está aguardando esta etapa")
+	ErrOperationSelfApproval   = errors.New("o solicitante não pode aprovar a própria operação")
+)
+
+type OperationService struct {
+	ttl        time.Duration
+	operations map[string]*domain.DestructiveOperation
+	nextID     int
+	clock      clock.Clock
+	mu         sync.Mutex
+}
+
+// NewOperationService creates a new destructive operation approval tracker
+func NewOperationService(ttl time.Duration, clock clock.Clock) *OperationService {
+	if ttl <= 0 {
+		ttl = DefaultOperationTTL
+	}
+
+	return &OperationService{
+		ttl:        ttl,
+		operations: make(map[string]*domain.DestructiveOperation),
+		clock:      clock,
+	}
+}
+
+// Submit registers a new operation and assigns the phrase the requester must type
+func (s *OperationService) Submit(operation domain.DestructiveOperation) domain.DestructiveOperation {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.purge()
+
+	s.nextID++
+	now := s.clock.Now()
+	operation.ID = strconv.Itoa(s.nextID)
+	operation.Phrase = OperationPhrasePrefix + " " + operation.ID
+	operation.Status = domain.OperationPendingPhrase
+	operation.CreatedAt = now
+	operation.ExpiresAt = now.Add(s.ttl)
+
+	s.operations[operation.ID] = &operation
+	return operation
+}
+
+// PendingPhrase returns the operation of a user still waiting for the typed confirmation
+func (s *OperationService) PendingPhrase(userID int64) (domain.DestructiveOperation, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.clock.Now()
+	for _, operation := range s.operations {
+		if operation.RequestedBy == userID &&
+			operation.Status == domain.OperationPendingPhrase &&
+			!operation.IsExpired(now) {
+			return *operation, true
+		}
+	}
+
+	return domain.DestructiveOperation{}, false
+}
+
+// ConfirmPhrase validates the phrase typed by the requester and moves the operation to approval
+func (s *OperationService) ConfirmPhrase(id string, userID int64, phrase string) (domain.DestructiveOperation, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	operation, err := s.find(id, domain.OperationPendingPhrase)
+	if err != nil {
+		return domain.DestructiveOperation{}, err
+	}
+
+	if operation.RequestedBy != userID {
+		return domain.DestructiveOperation{}, ErrOperationNotRequester
+	}
+
+	if strings.Join(strings.Fields(strings.ToUpper(phrase)), " ") != operation.Phrase {
+		return domain.DestructiveOperation{}, ErrOperationPhraseMismatch
+	}
+
+	operation.Status = domain.OperationPendingApproval
+	return *operation, nil
+}
+
+// Approve records the supervisor approval, which must come from someone other than the requester
+func (s *OperationService) Approve(id string, userID int64, name string) (domain.DestructiveOperation, error) {
+	return s.decide(id, userID, name, domain.OperationApproved)
+}
+
+// Reject records the supervisor refusal of an operation
+func (s *OperationService) Reject(id string, userID int64, name string) (domain.DestructiveOperation, error) {
+	return s.decide(id, userID, name, domain.OperationRejected)
+}
+
+// decide moves a pending operation to its final status
+func (s *OperationService) decide(id string, userID int64, name string, status domain.OperationStatus) (domain.DestructiveOperation, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	operation, err := s.find(id, domain.OperationPendingApproval)
+	if err != nil {
+		return domain.DestructiveOperation{}, err
+	}
+
+	if status == domain.OperationApproved && operation.RequestedBy == userID {
+		return domain.DestructiveOperation{}, ErrOperationSelfApproval
+	}
+
+	operation.Status = status
+	operation.DecidedBy = userID
+	operation.DeciderName = name
+	return *operation, nil
+}
+
+// find returns a live operation in the expected status, must be called with the lock held
+func (s *OperationService) find(id string, status domain.OperationStatus) (*domain.DestructiveOperation, error) {
+	operation, exists := s.operations[id]
+	if !exists || operation.IsExpired(s.clock.Now()) {
+		return nil, ErrOperationNotFound
+	}
+
+	if operation.Status != status {
+		return nil, ErrOperationInvalidStatus
+	}
+
+	return operation, nil
+}
+
+// purge drops expired operations after the decision window, must be called with the lock held
+func (s *OperationService) purge() {
+	cutoff := s.clock.Now().Add(-OperationDecisionWindow)
+	for id, operation := range s.operations {
+		if operation.ExpiresAt.Before(cutoff) {
+			delete(s.operations, id)
+		}
+	}
+}
@@ -0,0 +1,293 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"provisioning-assistant/internal/clock"
+	"provisioning-assistant/internal/domain"
+	"provisioning-assistant/internal/unm"
+	"slices"
+	"strings"
+)
+
+const orphanOnuNamespace = "orphan_onus"
+
+var (
+	ErrOrphanOnuNotFound = errors.New("ONU órfã não encontrada")
+	ErrOrphanOnuResolved = errors.New("ONU não está mais órfã")
+)
+
+type OrphanOnuService struct {
+	auditService        *AuditService
+	erpService          *ErpService
+	provisioningService *ProvisioningService
+	repository          domain.StateRepository
+	clock               clock.Clock
+	logger              domain.Logger
+}
+
+// NewOrphanOnuService creates the reconciliation of the ONUs left on the OLTs by failed swaps
+func NewOrphanOnuService(
+	auditService *AuditService,
+	erpService *ErpService,
+	provisioningService *ProvisioningService,
+	repository domain.StateRepository,
+	clock clock.Clock,
+	logger domain.Logger,
+) *OrphanOnuService {
+	return &OrphanOnuService{
+		auditService:        auditService,
+		erpService:          erpService,
+		provisioningService: provisioningService,
+		repository:          repository,
+		clock:               clock,
+		logger:              logger,
+	}
+}
+
+// Detect looks for ONUs still on their OLT whose contract points to another serial in the ERP and returns
+// the ones not reported before. Only contracts provisioned with more than one serial are checked, the ERP
+// is asked through the protocol of their latest provisioning and each PON is read once.
+func (s *OrphanOnuService) Detect(ctx context.Context) ([]*domain.OrphanOnu, error) {
+	records, err := s.auditService.ListRecords(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("falha ao listar auditorias: %w", err)
+	}
+
+	known, err := s.repository.List(ctx, orphanOnuNamespace)
+	if err != nil {
+		return nil, fmt.Errorf("falha ao listar ONUs órfãs: %w", err)
+	}
+
+	pons := make(map[string]map[string]*domain.OnuSignalInfo)
+	var detected []*domain.OrphanOnu
+
+	contracts := swappedContracts(records)
+	for _, contract := range slices.Sorted(maps.Keys(contracts)) {
+		provisioned := contracts[contract]
+		latest := provisioned[len(provisioned)-1]
+		log := s.logger.WithField("contract", contract)
+
+		connInfo, err := s.erpService.GetConnectionInfo(ctx, latest.Protocol)
+		if err != nil {
+			if ctx.Err() != nil {
+				return detected, ctx.Err()
+			}
+			log.WithError(err).Warn("Falha ao consultar serial atual do contrato")
+			continue
+		}
+		current := strings.ToUpper(connInfo.ConnectionEquipmentSerialNumber)
+
+		for _, record := range provisioned {
+			serial := strings.ToUpper(record.Serial)
+			if known[serial] != "" || serial == current {
+				continue
+			}
+
+			signals, err := s.ponSignals(ctx, pons, record)
+			if err != nil {
+				if ctx.Err() != nil {
+					return detected, ctx.Err()
+				}
+				log.WithError(err).WithField("serial", serial).Warn("Falha ao consultar PON da ONU substituída")
+				continue
+			}
+			if _, onOlt := signals[serial]; !onOlt {
+				continue
+			}
+
+			orphan := &domain.OrphanOnu{
+				Serial:        serial,
+				CurrentSerial: current,
+				Contract:      contract,
+				Protocol:      record.Protocol,
+				ClientName:    record.ClientName,
+				OltIP:         record.OltIP,
+				Slot:          record.Slot,
+				Port:          record.Port,
+				AuditID:       record.ID,
+				DetectedAt:    s.clock.Now(),
+			}
+			if err := s.save(ctx, orphan); err != nil {
+				log.WithError(err).WithField("serial", serial).Error("Falha ao registrar ONU órfã")
+				continue
+			}
+
+			log.WithFields(map[string]any{
+				"serial":  serial,
+				"current": current,
+				"olt":     record.OltIP,
+			}).Warn("ONU órfã detectada")
+			detected = append(detected, orphan)
+		}
+	}
+
+	return detected, nil
+}
+
+// Get returns a reported orphan ONU
+func (s *OrphanOnuService) Get(ctx context.Context, serial string) (*domain.OrphanOnu, error) {
+	value, err := s.repository.Get(ctx, orphanOnuNamespace, strings.ToUpper(serial))
+	if err != nil || value == "" {
+		return nil, ErrOrphanOnuNotFound
+	}
+
+	var orphan domain.OrphanOnu
+	if err := json.Unmarshal([]byte(value), &orphan); err != nil {
+		return nil, fmt.Errorf("registro de ONU órfã inválido: %w", err)
+	}
+	return &orphan, nil
+}
+
+// Remove checks again that the ONU is still orphan and deletes it from its OLT, recording who removed it
+// and the commands sent on its audit record. An ONU no longer orphan is forgotten and ErrOrphanOnuResolved
+// returned.
+func (s *OrphanOnuService) Remove(ctx context.Context, serial, removedBy string) (*domain.OrphanOnu, error) {
+	orphan, err := s.Get(ctx, serial)
+	if err != nil {
+		return nil, err
+	}
+	if orphan.IgnoredAt != nil {
+		return nil, ErrOrphanOnuNotFound
+	}
+
+	record, err := s.auditService.GetRecord(ctx, orphan.AuditID)
+	if err != nil {
+		return nil, fmt.Errorf("falha ao buscar registro de auditoria: %w", err)
+	}
+
+	still, err := s.stillOrphan(ctx, orphan, record)
+	if err != nil {
+		return nil, err
+	}
+	if !still {
+		s.forget(ctx, orphan.Serial)
+		return orphan, ErrOrphanOnuResolved
+	}
+
+	removeCtx, commandLog := unm.WithCommandLog(ctx)
+	err = s.provisioningService.RemoveOnu(removeCtx, &domain.LastJob{
+		Serial: record.Serial,
+		OltIP:  record.OltIP,
+		Slot:   record.Slot,
+		Port:   record.Port,
+	})
+	if logErr := s.auditService.AppendCommands(ctx, record.ID, commandLog.Commands()); logErr != nil {
+		s.logger.WithError(logErr).WithField("audit_id", record.ID).Warn("Falha ao registrar comandos da remoção")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("falha ao remover ONU órfã: %w", err)
+	}
+
+	if _, err := s.auditService.MarkRemoved(ctx, record.ID, removedBy); err != nil {
+		s.logger.WithError(err).WithField("audit_id", record.ID).Error("Falha ao registrar remoção no registro de auditoria")
+	}
+	s.forget(ctx, orphan.Serial)
+
+	return orphan, nil
+}
+
+// Ignore keeps an orphan ONU on its OLT and stops reporting it
+func (s *OrphanOnuService) Ignore(ctx context.Context, serial, ignoredBy string) (*domain.OrphanOnu, error) {
+	orphan, err := s.Get(ctx, serial)
+	if err != nil {
+		return nil, err
+	}
+	if orphan.IgnoredAt != nil {
+		return nil, ErrOrphanOnuNotFound
+	}
+
+	now := s.clock.Now()
+	orphan.IgnoredAt = &now
+	orphan.IgnoredBy = ignoredBy
+
+	if err := s.save(ctx, orphan); err != nil {
+		return nil, fmt.Errorf("falha ao registrar ONU órfã ignorada: %w", err)
+	}
+	return orphan, nil
+}
+
+// stillOrphan asks the ERP and the OLT again, the swap may have been finished since the detection
+func (s *OrphanOnuService) stillOrphan(ctx context.Context, orphan *domain.OrphanOnu, record *domain.AuditRecord) (bool, error) {
+	connInfo, err := s.erpService.GetConnectionInfo(ctx, orphan.Protocol)
+	if err != nil {
+		return false, err
+	}
+	if strings.EqualFold(connInfo.ConnectionEquipmentSerialNumber, orphan.Serial) {
+		return false, nil
+	}
+
+	signals, err := s.provisioningService.PonSignals(ctx, record.OltIP, record.Slot, record.Port)
+	if err != nil {
+		return false, err
+	}
+	_, onOlt := signals[orphan.Serial]
+	return onOlt, nil
+}
+
+// ponSignals reads the ONUs of the PON of a record, once per detection run
+func (s *OrphanOnuService) ponSignals(ctx context.Context, pons map[string]map[string]*domain.OnuSignalInfo, record *domain.AuditRecord) (map[string]*domain.OnuSignalInfo, error) {
+	key := record.OltIP + "/" + record.Slot + "/" + record.Port
+	if signals, exists := pons[key]; exists {
+		return signals, nil
+	}
+
+	signals, err := s.provisioningService.PonSignals(ctx, record.OltIP, record.Slot, record.Port)
+	if err != nil {
+		return nil, err
+	}
+	pons[key] = signals
+	return signals, nil
+}
+
+// save stores an orphan ONU keyed by its serial
+func (s *OrphanOnuService) save(ctx context.Context, orphan *domain.OrphanOnu) error {
+	content, err := json.Marshal(orphan)
+	if err != nil {
+		return err
+	}
+	return s.repository.Set(ctx, orphanOnuNamespace, orphan.Serial, string(content))
+}
+
+// forget drops a resolved orphan ONU, it is reported again if it ever shows up orphan once more. The state
+// repository keeps keys for good, an empty value stands for a forgotten ONU.
+func (s *OrphanOnuService) forget(ctx context.Context, serial string) {
+	if err := s.repository.Set(ctx, orphanOnuNamespace, serial, ""); err != nil {
+		s.logger.WithError(err).WithField("serial", serial).Warn("Falha ao remover registro de ONU órfã")
+	}
+}
+
+// swappedContracts groups the latest successful provisioning of each serial by contract, oldest first,
+// keeping only the contracts provisioned with more than one serial. A serial later provisioned for another
+// contract belongs to that one.
+func swappedContracts(records []*domain.AuditRecord) map[string][]*domain.AuditRecord {
+	latest := make(map[string]*domain.AuditRecord)
+	for _, record := range records {
+		if !record.Success || record.Serial == "" || record.Contract == "" || record.RemovedAt != nil {
+			continue
+		}
+		serial := strings.ToUpper(record.Serial)
+		if previous, exists := latest[serial]; !exists || record.CreatedAt.After(previous.CreatedAt) {
+			latest[serial] = record
+		}
+	}
+
+	contracts := make(map[string][]*domain.AuditRecord)
+	for _, record := range latest {
+		contracts[record.Contract] = append(contracts[record.Contract], record)
+	}
+
+	maps.DeleteFunc(contracts, func(_ string, provisioned []*domain.AuditRecord) bool {
+		return len(provisioned) < 2
+	})
+	for _, provisioned := range contracts {
+		slices.SortFunc(provisioned, func(a, b *domain.AuditRecord) int {
+			return a.CreatedAt.Compare(b.CreatedAt)
+		})
+	}
+
+	return contracts
+}
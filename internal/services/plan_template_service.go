@@ -0,0 +1,213 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"provisioning-assistant/internal/domain"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// planSpeedPattern reads the download speed from plan names like "Fibra 500M" or "Fibra 1 Giga"
+var planSpeedPattern = regexp.MustCompile(`(?i)(\d+)\s*(mbps|mega|mb|m|giga|gb|g)\b`)
+
+const (
+	planTemplateNamespace = "plan_templates"
+	planTemplateKey       = "catalog"
+)
+
+type PlanTemplateService struct {
+	repository domain.StateRepository
+	templates  []domain.ProvisioningTemplate
+	logger     domain.Logger
+	mu         sync.RWMutex
+}
+
+// NewPlanTemplateService creates a new plan template service with the given templates, a catalog
+// imported later is kept in the repository and replaces them
+func NewPlanTemplateService(templates []domain.ProvisioningTemplate, repository domain.StateRepository, logger domain.Logger) *PlanTemplateService {
+	return &PlanTemplateService{
+		repository: repository,
+		templates:  templates,
+		logger:     logger,
+	}
+}
+
+// LoadPlanTemplates reads provisioning templates from a JSON file
+func LoadPlanTemplates(path string) ([]domain.ProvisioningTemplate, error) {
+	if path == "" {
+		return nil, nil
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("falha ao ler arquivo de templates: %w", err)
+	}
+
+	var templates []domain.ProvisioningTemplate
+	if err := json.Unmarshal(content, &templates); err != nil {
+		return nil, fmt.Errorf("falha ao interpretar arquivo de templates: %w", err)
+	}
+
+	if err := ValidatePlanTemplates(templates); err != nil {
+		return nil, err
+	}
+
+	return templates, nil
+}
+
+// ValidatePlanTemplates checks that every template is named once, that a single one is the default
+// and that the VLANs are in range
+func ValidatePlanTemplates(templates []domain.ProvisioningTemplate) error {
+	names := make(map[string]bool, len(templates))
+	var fallback string
+
+	for i, template := range templates {
+		if template.Name == "" {
+			return fmt.Errorf("template na posição %d sem nome", i)
+		}
+
+		if names[template.Name] {
+			return fmt.Errorf("template %s repetido", template.Name)
+		}
+		names[template.Name] = true
+
+		if template.Default {
+			if fallback != "" {
+				return fmt.Errorf("templates %s e %s marcados como padrão", fallback, template.Name)
+			}
+			fallback = template.Name
+		}
+
+		if template.Vlan != "" {
+			if vlan, err := strconv.Atoi(template.Vlan); err != nil || vlan < 1 || vlan > 4094 {
+				return fmt.Errorf("template %s com VLAN inválida: %s", template.Name, template.Vlan)
+			}
+		}
+
+		if template.DownloadMbps < 0 || template.UploadMbps < 0 {
+			return fmt.Errorf("template %s com velocidade contratada negativa", template.Name)
+		}
+
+		if template.MulticastVlan != "" {
+			if vlan, err := strconv.Atoi(template.MulticastVlan); err != nil || vlan < 1 || vlan > 4094 {
+				return fmt.Errorf("template %s com VLAN multicast inválida: %s", template.Name, template.MulticastVlan)
+			}
+		}
+
+		for _, port := range template.MulticastPorts {
+			if number, err := strconv.Atoi(port); err != nil || number < 1 {
+				return fmt.Errorf("template %s com porta multicast inválida: %s", template.Name, port)
+			}
+		}
+
+		for service, ports := range template.ServicePorts {
+			if service == "" || len(ports) == 0 {
+				return fmt.Errorf("template %s com portas de serviço WAN vazias: %q", template.Name, service)
+			}
+		}
+	}
+
+	return nil
+}
+
+// Restore loads the catalog imported before the last restart, keeping the configured templates
+// when none was imported
+func (s *PlanTemplateService) Restore(ctx context.Context) error {
+	value, err := s.repository.Get(ctx, planTemplateNamespace, planTemplateKey)
+	if err != nil {
+		return nil
+	}
+
+	var templates []domain.ProvisioningTemplate
+	if err := json.Unmarshal([]byte(value), &templates); err != nil {
+		return fmt.Errorf("falha ao interpretar templates importados: %w", err)
+	}
+
+	s.mu.Lock()
+	s.templates = templates
+	s.mu.Unlock()
+
+	s.logger.WithField("templates", len(templates)).Info("Templates importados restaurados")
+	return nil
+}
+
+// Templates returns a copy of the templates in use
+func (s *PlanTemplateService) Templates() []domain.ProvisioningTemplate {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return append([]domain.ProvisioningTemplate(nil), s.templates...)
+}
+
+// Replace validates and stores a new set of templates, used from then on by every provisioning
+func (s *PlanTemplateService) Replace(ctx context.Context, templates []domain.ProvisioningTemplate) error {
+	if err := ValidatePlanTemplates(templates); err != nil {
+		return err
+	}
+
+	value, err := json.Marshal(templates)
+	if err != nil {
+		return fmt.Errorf("falha ao serializar templates: %w", err)
+	}
+
+	if err := s.repository.Set(ctx, planTemplateNamespace, planTemplateKey, string(value)); err != nil {
+		return fmt.Errorf("falha ao salvar templates: %w", err)
+	}
+
+	s.mu.Lock()
+	s.templates = templates
+	s.mu.Unlock()
+
+	s.logger.WithField("templates", len(templates)).Info("Templates de provisionamento substituídos")
+	return nil
+}
+
+// Resolve returns the template for a contract plan, falling back to the default template
+func (s *PlanTemplateService) Resolve(planID uint64, planName string) *domain.ProvisioningTemplate {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var fallback *domain.ProvisioningTemplate
+
+	for i := range s.templates {
+		template := &s.templates[i]
+		if template.Matches(planID, planName) {
+			s.logger.WithFields(map[string]any{
+				"plan_id":   planID,
+				"plan_name": planName,
+				"template":  template.Name,
+			}).Debug("Template de provisionamento selecionado")
+			return template
+		}
+
+		if template.Default && fallback == nil {
+			fallback = template
+		}
+	}
+
+	return fallback
+}
+
+// ContractedSpeed returns the download and upload speeds in Mbps sold with a plan, taken from its template or,
+// for the download, read from the plan name. Zero means the speed is unknown.
+func (s *PlanTemplateService) ContractedSpeed(planID uint64, planName string) (download, upload int) {
+	if template := s.Resolve(planID, planName); template != nil {
+		download, upload = template.DownloadMbps, template.UploadMbps
+	}
+
+	if download == 0 {
+		if match := planSpeedPattern.FindStringSubmatch(planName); match != nil {
+			download, _ = strconv.Atoi(match[1])
+			if strings.HasPrefix(strings.ToLower(match[2]), "g") {
+				download *= 1000
+			}
+		}
+	}
+
+	return download, upload
+}
@@ -0,0 +1,272 @@
+package services
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"provisioning-assistant/internal/clock"
+	"provisioning-assistant/internal/domain"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	ponCapacityNamespace = "pon_occupancy"
+
+	// DefaultPonSize is how many ONUs a PON takes when the deployment does not say otherwise
+	DefaultPonSize = 128
+
+	// PonCapacityRetention bounds the occupancy samples kept for each PON
+	PonCapacityRetention = 400 * 24 * time.Hour
+
+	// PonCapacityWindow is the period over which the growth of a PON is measured
+	PonCapacityWindow = 90 * 24 * time.Hour
+
+	// PonCapacityMinSpan is how long a PON must have been sampled before its exhaustion is projected
+	PonCapacityMinSpan = 7 * 24 * time.Hour
+
+	// PonCapacityHorizon is how far ahead an exhaustion is projected, a slower growth is reported without a date
+	PonCapacityHorizon = 5 * 365 * 24 * time.Hour
+
+	// ponCapacityMonth turns the daily growth into the monthly one shown in the reports
+	ponCapacityMonth = 30
+)
+
+// PonOccupancySample is how many ONUs answered on a PON at a moment
+type PonOccupancySample struct {
+	At   time.Time `json:"at"`
+	Onus int       `json:"onus"`
+}
+
+// PonCapacity is the occupancy trend of a PON with the date it is projected to run out of room
+type PonCapacity struct {
+	OltIP          string     `json:"olt_ip"`
+	Slot           string     `json:"slot"`
+	Port           string     `json:"port"`
+	Onus           int        `json:"onus"`
+	Size           int        `json:"size"`
+	Utilization    float64    `json:"utilization"`
+	GrowthPerMonth float64    `json:"growth_per_month"`
+	ExhaustsAt     *time.Time `json:"exhausts_at,omitempty"`
+	Samples        int        `json:"samples"`
+	MeasuredAt     time.Time  `json:"measured_at"`
+}
+
+// PonCapacityReport lists the sampled PONs, the closest to exhaustion first
+type PonCapacityReport struct {
+	GeneratedAt time.Time     `json:"generated_at"`
+	PonSize     int           `json:"pon_size"`
+	Pons        []PonCapacity `json:"pons"`
+}
+
+type PonCapacityService struct {
+	auditService        *AuditService
+	provisioningService *ProvisioningService
+	maintenanceService  *MaintenanceService
+	repository          domain.StateRepository
+	ponSize             int
+	clock               clock.Clock
+	logger              domain.Logger
+}
+
+// NewPonCapacityService creates the capacity planning of the PONs, sampling how many ONUs each one holds
+func NewPonCapacityService(
+	auditService *AuditService,
+	provisioningService *ProvisioningService,
+	maintenanceService *MaintenanceService,
+	repository domain.StateRepository,
+	ponSize int,
+	clock clock.Clock,
+	logger domain.Logger,
+) *PonCapacityService {
+	if ponSize <= 0 {
+		ponSize = DefaultPonSize
+	}
+
+	return &PonCapacityService{
+		auditService:        auditService,
+		provisioningService: provisioningService,
+		maintenanceService:  maintenanceService,
+		repository:          repository,
+		ponSize:             ponSize,
+		clock:               clock,
+		logger:              logger,
+	}
+}
+
+// Snapshot counts the ONUs of every PON provisioned by the bot with one query per PON, skipping the OLTs in
+// maintenance, and returns how many PONs were sampled. A day keeps a single sample, the last one taken.
+func (s *PonCapacityService) Snapshot(ctx context.Context) (int, error) {
+	records, err := s.auditService.ListRecords(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("falha ao listar auditorias: %w", err)
+	}
+
+	pons := make(map[string]PonCapacity)
+	for _, record := range records {
+		if !record.Success || record.OltIP == "" || record.Slot == "" || record.Port == "" {
+			continue
+		}
+		pon := PonCapacity{OltIP: record.OltIP, Slot: record.Slot, Port: record.Port}
+		pons[ponCapacityKey(pon)] = pon
+	}
+
+	now := s.clock.Now()
+	sampled := 0
+	for _, key := range slices.Sorted(maps.Keys(pons)) {
+		pon := pons[key]
+		log := s.logger.WithFields(map[string]any{"olt": pon.OltIP, "slot": pon.Slot, "port": pon.Port})
+
+		if s.maintenanceService.InMaintenance(ctx, pon.OltIP) {
+			continue
+		}
+
+		signals, err := s.provisioningService.PonSignals(ctx, pon.OltIP, pon.Slot, pon.Port)
+		if err != nil {
+			if ctx.Err() != nil {
+				return sampled, ctx.Err()
+			}
+			log.WithError(err).Warn("Falha ao contar ONUs da PON")
+			continue
+		}
+
+		if err := s.record(ctx, key, PonOccupancySample{At: now, Onus: len(signals)}); err != nil {
+			log.WithError(err).Error("Falha ao salvar ocupação da PON")
+			continue
+		}
+		sampled++
+	}
+
+	s.logger.WithField("pons", sampled).Info("Ocupação das PONs registrada")
+	return sampled, nil
+}
+
+// Report measures the growth of every sampled PON over the last window and projects when it fills up
+func (s *PonCapacityService) Report(ctx context.Context) (*PonCapacityReport, error) {
+	stored, err := s.repository.List(ctx, ponCapacityNamespace)
+	if err != nil {
+		return nil, fmt.Errorf("falha ao listar ocupação das PONs: %w", err)
+	}
+
+	now := s.clock.Now()
+	report := &PonCapacityReport{GeneratedAt: now, PonSize: s.ponSize, Pons: []PonCapacity{}}
+
+	for key, value := range stored {
+		var samples []PonOccupancySample
+		if err := json.Unmarshal([]byte(value), &samples); err != nil || len(samples) == 0 {
+			s.logger.WithField("pon", key).Warn("Ocupação de PON inválida ignorada")
+			continue
+		}
+
+		oltIP, slotPort, _ := strings.Cut(key, "/")
+		slot, port, _ := strings.Cut(slotPort, "/")
+		report.Pons = append(report.Pons, s.trend(oltIP, slot, port, samples, now))
+	}
+
+	slices.SortFunc(report.Pons, func(a, b PonCapacity) int {
+		switch {
+		case a.ExhaustsAt != nil && b.ExhaustsAt != nil:
+			return a.ExhaustsAt.Compare(*b.ExhaustsAt)
+		case a.ExhaustsAt != nil:
+			return -1
+		case b.ExhaustsAt != nil:
+			return 1
+		}
+		return cmp.Or(cmp.Compare(b.Utilization, a.Utilization), cmp.Compare(ponCapacityKey(a), ponCapacityKey(b)))
+	})
+
+	return report, nil
+}
+
+// trend fits a line over the samples of the window, the slope is the daily growth of the PON
+func (s *PonCapacityService) trend(oltIP, slot, port string, samples []PonOccupancySample, now time.Time) PonCapacity {
+	window := slices.DeleteFunc(slices.Clone(samples), func(sample PonOccupancySample) bool {
+		return now.Sub(sample.At) > PonCapacityWindow
+	})
+	if len(window) == 0 {
+		window = samples[len(samples)-1:]
+	}
+
+	last := window[len(window)-1]
+	capacity := PonCapacity{
+		OltIP:       oltIP,
+		Slot:        slot,
+		Port:        port,
+		Onus:        last.Onus,
+		Size:        s.ponSize,
+		Utilization: float64(last.Onus) / float64(s.ponSize),
+		Samples:     len(window),
+		MeasuredAt:  last.At,
+	}
+
+	if last.Onus >= s.ponSize {
+		capacity.ExhaustsAt = &last.At
+		return capacity
+	}
+
+	if last.At.Sub(window[0].At) < PonCapacityMinSpan {
+		return capacity
+	}
+
+	var sumX, sumY, sumXY, sumXX float64
+	for _, sample := range window {
+		x := sample.At.Sub(window[0].At).Hours() / 24
+		y := float64(sample.Onus)
+		sumX += x
+		sumY += y
+		sumXY += x * y
+		sumXX += x * x
+	}
+
+	n := float64(len(window))
+	daily := (n*sumXY - sumX*sumY) / (n*sumXX - sumX*sumX)
+	capacity.GrowthPerMonth = daily * ponCapacityMonth
+
+	if daily <= 0 {
+		return capacity
+	}
+
+	days := float64(s.ponSize-last.Onus) / daily
+	if days*float64(24*time.Hour) <= float64(PonCapacityHorizon) {
+		exhaustsAt := last.At.Add(time.Duration(days * float64(24*time.Hour)))
+		capacity.ExhaustsAt = &exhaustsAt
+	}
+
+	return capacity
+}
+
+// record stores a sample of a PON, replacing the one of the same day and dropping the expired ones
+func (s *PonCapacityService) record(ctx context.Context, key string, sample PonOccupancySample) error {
+	var samples []PonOccupancySample
+	if value, err := s.repository.Get(ctx, ponCapacityNamespace, key); err == nil {
+		if err := json.Unmarshal([]byte(value), &samples); err != nil {
+			s.logger.WithError(err).WithField("pon", key).Warn("Ocupação de PON inválida descartada")
+			samples = nil
+		}
+	}
+
+	samples = slices.DeleteFunc(samples, func(stored PonOccupancySample) bool {
+		return sample.At.Sub(stored.At) > PonCapacityRetention || sameDay(stored.At, sample.At)
+	})
+	samples = append(samples, sample)
+
+	content, err := json.Marshal(samples)
+	if err != nil {
+		return err
+	}
+	return s.repository.Set(ctx, ponCapacityNamespace, key, string(content))
+}
+
+// ponCapacityKey identifies a PON in the state repository
+func ponCapacityKey(pon PonCapacity) string {
+	return pon.OltIP + "/" + pon.Slot + "/" + pon.Port
+}
+
+// sameDay reports whether two moments fall on the same calendar day of the first one's timezone
+func sameDay(a, b time.Time) bool {
+	b = b.In(a.Location())
+	return a.Year() == b.Year() && a.YearDay() == b.YearDay()
+}
@@ -0,0 +1,122 @@
+package services
+
+import (
+	"context"
+	"maps"
+	"provisioning-assistant/internal/domain"
+	"provisioning-assistant/internal/domain/dto"
+	"provisioning-assistant/internal/pon"
+	"time"
+)
+
+const (
+	// PonPrefetchTTL is how long a PON read while the confirmation is on screen is reused by the provisioning
+	PonPrefetchTTL = 2 * time.Minute
+
+	// ponPrefetchTimeout bounds the background read of a PON
+	ponPrefetchTimeout = 20 * time.Second
+)
+
+// ponPrefetchKey identifies a PON, training sessions reading the simulator apart from production
+type ponPrefetchKey struct {
+	training bool
+	oltIP    string
+	location pon.Location
+}
+
+// ponPrefetch is a read of the ONUs online on a PON started ahead of the provisioning, done is closed once
+// signals and err are set
+type ponPrefetch struct {
+	startedAt time.Time
+	done      chan struct{}
+	signals   map[string]*domain.OnuSignalInfo
+	err       error
+}
+
+// PrefetchPon reads the ONUs online on the PON of a request in the background while the technician reviews
+// the confirmation, so the UNM session is logged in and the PON known when the provisioning starts.
+// A recent read of the same PON is not repeated, and none is started while the UNM is saturated.
+func (s *ProvisioningService) PrefetchPon(ctx context.Context, connInfo *dto.ConnectionInfo) {
+	key, ok := prefetchKey(ctx, connInfo.ConnectionOltIP, connInfo.ConnectionOltSlot, connInfo.ConnectionOltPort)
+	if !ok || !s.allowNonCritical(ctx, ShedPonPrefetch) {
+		return
+	}
+
+	s.prefetchMu.Lock()
+	maps.DeleteFunc(s.prefetches, func(_ ponPrefetchKey, prefetch *ponPrefetch) bool {
+		return s.clock.Since(prefetch.startedAt) > PonPrefetchTTL
+	})
+	if _, exists := s.prefetches[key]; exists {
+		s.prefetchMu.Unlock()
+		return
+	}
+	prefetch := &ponPrefetch{startedAt: s.clock.Now(), done: make(chan struct{})}
+	s.prefetches[key] = prefetch
+	s.prefetchMu.Unlock()
+
+	// The read outlives the handling of the message that showed the confirmation
+	readCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), ponPrefetchTimeout)
+	go func() {
+		defer cancel()
+		defer close(prefetch.done)
+
+		prefetch.signals, prefetch.err = s.PonSignals(readCtx, key.oltIP, connInfo.ConnectionOltSlot, connInfo.ConnectionOltPort)
+
+		log := s.logger.WithFields(map[string]any{
+			"olt":      key.oltIP,
+			"pon":      key.location.String(),
+			"duration": s.clock.Since(prefetch.startedAt).String(),
+		})
+		if prefetch.err != nil {
+			log.WithError(prefetch.err).Debug("Falha ao pré-carregar PON")
+			return
+		}
+		log.WithField("onus", len(prefetch.signals)).Debug("PON pré-carregada")
+	}()
+}
+
+// PrefetchedPon returns the signal of the ONUs online on a PON, keyed by uppercase serial, as read by
+// PrefetchPon, waiting for a read still running. False when the PON was not read recently or the read failed.
+func (s *ProvisioningService) PrefetchedPon(ctx context.Context, oltIP, slot, port string) (map[string]*domain.OnuSignalInfo, bool) {
+	key, ok := prefetchKey(ctx, oltIP, slot, port)
+	if !ok {
+		return nil, false
+	}
+
+	s.prefetchMu.Lock()
+	prefetch, exists := s.prefetches[key]
+	s.prefetchMu.Unlock()
+
+	if !exists || s.clock.Since(prefetch.startedAt) > PonPrefetchTTL {
+		return nil, false
+	}
+
+	select {
+	case <-prefetch.done:
+	case <-ctx.Done():
+		return nil, false
+	}
+
+	if prefetch.err != nil {
+		return nil, false
+	}
+	return prefetch.signals, true
+}
+
+// forgetPrefetch drops the read of a PON whose ONUs were just changed
+func (s *ProvisioningService) forgetPrefetch(ctx context.Context, oltIP, slot, port string) {
+	if key, ok := prefetchKey(ctx, oltIP, slot, port); ok {
+		s.prefetchMu.Lock()
+		delete(s.prefetches, key)
+		s.prefetchMu.Unlock()
+	}
+}
+
+// prefetchKey identifies the PON of the context, false when the slot and port cannot be read
+func prefetchKey(ctx context.Context, oltIP, slot, port string) (ponPrefetchKey, bool) {
+	location, err := pon.ParseSlotPort(slot, port)
+	if err != nil || oltIP == "" {
+		return ponPrefetchKey{}, false
+	}
+	return ponPrefetchKey{training: domain.IsTraining(ctx), oltIP: oltIP, location: location}, true
+}
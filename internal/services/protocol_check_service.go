@@ -0,0 +1,85 @@
+package services
+
+import (
+	"context"
+	"provisioning-assistant/internal/domain"
+	"provisioning-assistant/internal/domain/dto"
+	"provisioning-assistant/internal/naming"
+	"slices"
+	"strings"
+)
+
+var (
+	DefaultClosedStatuses    = []string{"Encerrado", "Concluído", "Finalizado", "Fechado"}
+	DefaultCancelledStatuses = []string{"Cancelado"}
+)
+
+// ProtocolStatusPolicy maps ERP assignment statuses to the issues that block provisioning
+type ProtocolStatusPolicy struct {
+	ClosedStatuses    []string
+	CancelledStatuses []string
+}
+
+type ProtocolCheckService struct {
+	policy       ProtocolStatusPolicy
+	auditService *AuditService
+	logger       domain.Logger
+}
+
+// NewProtocolCheckService creates a new protocol pre-check service, empty status lists use the defaults
+func NewProtocolCheckService(policy ProtocolStatusPolicy, auditService *AuditService, logger domain.Logger) *ProtocolCheckService {
+	if len(policy.ClosedStatuses) == 0 {
+		policy.ClosedStatuses = DefaultClosedStatuses
+	}
+	if len(policy.CancelledStatuses) == 0 {
+		policy.CancelledStatuses = DefaultCancelledStatuses
+	}
+
+	return &ProtocolCheckService{
+		policy:       policy,
+		auditService: auditService,
+		logger:       logger,
+	}
+}
+
+// Check classifies the ERP status of an assignment and looks for a previous successful provisioning of the protocol
+func (s *ProtocolCheckService) Check(ctx context.Context, protocol string, connInfo *dto.ConnectionInfo) domain.ProtocolCheck {
+	check := domain.ProtocolCheck{Status: connInfo.AssignmentStatus}
+
+	switch {
+	case matchesStatus(s.policy.CancelledStatuses, connInfo.AssignmentStatus):
+		check.Issue = domain.ProtocolIssueCancelled
+	case matchesStatus(s.policy.ClosedStatuses, connInfo.AssignmentStatus):
+		check.Issue = domain.ProtocolIssueClosed
+	}
+
+	previous, err := s.auditService.FindLatestByProtocol(ctx, protocol)
+	if err == nil {
+		check.PreviousAudit = previous
+		if check.Issue == domain.ProtocolIssueNone {
+			check.Issue = domain.ProtocolIssueProvisioned
+		}
+	}
+
+	if check.RequiresOverride() {
+		s.logger.WithFields(map[string]any{
+			"protocol": protocol,
+			"status":   connInfo.AssignmentStatus,
+			"issue":    check.Issue,
+		}).Warn("Protocolo requer liberação para provisionamento")
+	}
+
+	return check
+}
+
+// matchesStatus compares ERP statuses ignoring case and accents
+func matchesStatus(statuses []string, status string) bool {
+	normalized := strings.ToLower(naming.Transliterate(strings.TrimSpace(status)))
+	if normalized == "" {
+		return false
+	}
+
+	return slices.ContainsFunc(statuses, func(candidate string) bool {
+		return strings.ToLower(naming.Transliterate(strings.TrimSpace(candidate))) == normalized
+	})
+}
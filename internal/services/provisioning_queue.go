@@ -0,0 +1,204 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"time"
+)
+
+// DefaultProvisioningSlots bounds how many provisioning jobs talk to the UNM at the same time
+const DefaultProvisioningSlots = 4
+
+// ErrQueueDraining is returned to the jobs refused while the process shuts down
+var ErrQueueDraining = errors.New("fila de provisionamento encerrando, nenhum job novo é aceito")
+
+// QueuedJob describes a provisioning job waiting for or holding a slot
+type QueuedJob struct {
+	UserID     int64
+	UserName   string
+	Protocol   string
+	Contract   string
+	VIP        bool
+	EnqueuedAt time.Time
+	StartedAt  time.Time
+}
+
+// ProvisioningQueue hands out a bounded number of provisioning slots, VIP jobs are served before the others
+type ProvisioningQueue struct {
+	slots   int
+	mu      sync.Mutex
+	running []*QueueTicket
+	waiting []*QueueTicket
+
+	// Closed once the queue is draining and the last running job freed its slot
+	drained chan struct{}
+}
+
+// QueueTicket is the place of a job in the provisioning queue
+type QueueTicket struct {
+	job     QueuedJob
+	queue   *ProvisioningQueue
+	ready   chan struct{}
+	refused bool
+}
+
+// NewProvisioningQueue creates a new provisioning queue with the given number of concurrent slots
+func NewProvisioningQueue(slots int) *ProvisioningQueue {
+	if slots <= 0 {
+		slots = DefaultProvisioningSlots
+	}
+
+	return &ProvisioningQueue{slots: slots}
+}
+
+// Enqueue adds a job to the queue, VIP jobs go ahead of every regular job already waiting
+func (q *ProvisioningQueue) Enqueue(job QueuedJob) *QueueTicket {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	job.EnqueuedAt = time.Now()
+	ticket := &QueueTicket{job: job, queue: q, ready: make(chan struct{})}
+
+	if q.drained != nil {
+		ticket.refused = true
+		close(ticket.ready)
+		return ticket
+	}
+
+	position := len(q.waiting)
+	if job.VIP {
+		position = slices.IndexFunc(q.waiting, func(waiting *QueueTicket) bool {
+			return !waiting.job.VIP
+		})
+		if position < 0 {
+			position = len(q.waiting)
+		}
+	}
+	q.waiting = slices.Insert(q.waiting, position, ticket)
+
+	q.dispatch()
+	return ticket
+}
+
+// Jobs returns the jobs holding a slot and the ones waiting, in the order they will run
+func (q *ProvisioningQueue) Jobs() (running, waiting []QueuedJob) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for _, ticket := range q.running {
+		running = append(running, ticket.job)
+	}
+	for _, ticket := range q.waiting {
+		waiting = append(waiting, ticket.job)
+	}
+
+	return running, waiting
+}
+
+// Drain refuses the jobs waiting and every new one, then blocks until the running jobs free their slots or
+// the context ends
+func (q *ProvisioningQueue) Drain(ctx context.Context) error {
+	q.mu.Lock()
+	if q.drained == nil {
+		q.drained = make(chan struct{})
+		for _, ticket := range q.waiting {
+			ticket.refused = true
+			close(ticket.ready)
+		}
+		q.waiting = nil
+		q.checkDrained()
+	}
+	drained := q.drained
+	q.mu.Unlock()
+
+	select {
+	case <-drained:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Slots returns the number of concurrent provisioning slots
+func (q *ProvisioningQueue) Slots() int {
+	return q.slots
+}
+
+// Position returns how many jobs are ahead of the ticket, zero once it holds a slot
+func (t *QueueTicket) Position() int {
+	t.queue.mu.Lock()
+	defer t.queue.mu.Unlock()
+
+	return slices.Index(t.queue.waiting, t) + 1
+}
+
+// Wait blocks until the ticket holds a slot, leaving the queue when the context ends first
+func (t *QueueTicket) Wait(ctx context.Context) error {
+	select {
+	case <-t.ready:
+		t.queue.mu.Lock()
+		defer t.queue.mu.Unlock()
+
+		if t.refused {
+			return ErrQueueDraining
+		}
+		return nil
+	case <-ctx.Done():
+	}
+
+	t.queue.mu.Lock()
+	defer t.queue.mu.Unlock()
+
+	// The slot may have been handed out while the context was ending
+	if index := slices.Index(t.queue.waiting, t); index >= 0 {
+		t.queue.waiting = slices.Delete(t.queue.waiting, index, index+1)
+		return ctx.Err()
+	}
+
+	t.queue.release(t)
+	return ctx.Err()
+}
+
+// Done frees the slot held by the ticket
+func (t *QueueTicket) Done() {
+	t.queue.mu.Lock()
+	defer t.queue.mu.Unlock()
+
+	t.queue.release(t)
+}
+
+// release removes a ticket from the running jobs and hands its slot to the next one
+func (q *ProvisioningQueue) release(ticket *QueueTicket) {
+	if index := slices.Index(q.running, ticket); index >= 0 {
+		q.running = slices.Delete(q.running, index, index+1)
+	}
+	q.dispatch()
+	q.checkDrained()
+}
+
+// checkDrained tells a draining queue that the last running job is gone
+func (q *ProvisioningQueue) checkDrained() {
+	if q.drained == nil || len(q.running) > 0 {
+		return
+	}
+
+	select {
+	case <-q.drained:
+	default:
+		close(q.drained)
+	}
+}
+
+// dispatch moves waiting jobs into the free slots
+func (q *ProvisioningQueue) dispatch() {
+	for len(q.running) < q.slots && len(q.waiting) > 0 {
+		next := q.waiting[0]
+		q.waiting = q.waiting[1:]
+
+		next.job.StartedAt = time.Now()
+		q.running = append(q.running, next)
+		close(next.ready)
+	}
+}
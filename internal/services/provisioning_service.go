@@ -1,37 +1,94 @@
 package services
 
 import (
+	"cmp"
 	"context"
+	"errors"
 	"fmt"
+	"maps"
+	"provisioning-assistant/internal/clock"
 	"provisioning-assistant/internal/domain"
 	"provisioning-assistant/internal/domain/dto"
+	"provisioning-assistant/internal/naming"
+	"provisioning-assistant/internal/pon"
 	"provisioning-assistant/internal/unm"
-	"strconv"
+	"provisioning-assistant/internal/validation"
+	"slices"
 	"strings"
+	"sync"
+	"time"
 )
 
+// ErrInvalidConnectionInfo marks a provisioning refused for the ERP data it was given, before reaching the UNM
+var ErrInvalidConnectionInfo = errors.New("informações de conexão inválidas")
+
 type ProvisioningService struct {
-	unmClient *unm.UNMClient
-	logger    domain.Logger
+	unmClient        *unm.UNMClient
+	sandboxClient    *unm.UNMClient
+	templateService  *PlanTemplateService
+	thresholdService *SignalThresholdService
+	namingPolicy     *naming.Policy
+	budget           unm.CommandBudget
+	permissions      *Tl1PermissionService
+	loadShedder      *LoadShedder
+	features         *FeatureService
+	clock            clock.Clock
+	logger           domain.Logger
+
+	prefetchMu sync.Mutex
+	prefetches map[ponPrefetchKey]*ponPrefetch
+
+	onuStatesMu sync.Mutex
+	onuStates   map[onuStateKey]*onuStateEntry
 }
 
-// NewProvisioningService creates a new provisioning service instance
-func NewProvisioningService(unmClient *unm.UNMClient, logger domain.Logger) *ProvisioningService {
+// NewProvisioningService creates a new provisioning service instance, training sessions use the sandbox client.
+// Each job runs within the TL1 command budget and every operation is checked against the role of the context.
+func NewProvisioningService(
+	unmClient *unm.UNMClient,
+	sandboxClient *unm.UNMClient,
+	templateService *PlanTemplateService,
+	thresholdService *SignalThresholdService,
+	namingPolicy *naming.Policy,
+	budget unm.CommandBudget,
+	permissions *Tl1PermissionService,
+	loadShedder *LoadShedder,
+	features *FeatureService,
+	clock clock.Clock,
+	logger domain.Logger,
+) *ProvisioningService {
 	return &ProvisioningService{
-		unmClient: unmClient,
-		logger:    logger,
+		unmClient:        unmClient,
+		sandboxClient:    sandboxClient,
+		templateService:  templateService,
+		thresholdService: thresholdService,
+		namingPolicy:     namingPolicy,
+		budget:           budget,
+		permissions:      permissions,
+		loadShedder:      loadShedder,
+		features:         features,
+		clock:            clock,
+		logger:           logger,
+		prefetches:       make(map[ponPrefetchKey]*ponPrefetch),
+		onuStates:        make(map[onuStateKey]*onuStateEntry),
 	}
 }
 
-// ProvisionEquipment provisions an ONU equipment and returns signal information
-func (s *ProvisioningService) ProvisionEquipment(ctx context.Context, connInfo *dto.ConnectionInfo) (*domain.OnuSignalInfo, error) {
+// ProvisionEquipment provisions an ONU equipment and returns signal information with the duration of each step.
+// Replacing is where the contract's ONU was registered before, nil when unknown. On failure the result is
+// still returned with the configuration captured before the ONU was replaced.
+func (s *ProvisioningService) ProvisionEquipment(ctx context.Context, connInfo *dto.ConnectionInfo, replacing *domain.LastJob) (*domain.ProvisioningResult, error) {
+	if err := s.permissions.Authorize(ctx, domain.Tl1OperationProvision); err != nil {
+		return nil, err
+	}
+
 	if err := s.validateConnectionInfo(connInfo); err != nil {
-		return nil, fmt.Errorf("informações de conexão inválidas: %w", err)
+		return nil, fmt.Errorf("%w: %w", ErrInvalidConnectionInfo, err)
 	}
 
 	slot, port, err := s.parseOltSlotPort(connInfo.ConnectionOltSlot, connInfo.ConnectionOltPort)
 	if err != nil {
-		return nil, fmt.Errorf("falha ao analisar slot/porta da OLT: %w", err)
+		return nil, fmt.Errorf("%w: falha ao analisar slot/porta da OLT: %w", ErrInvalidConnectionInfo, err)
 	}
 
 	config := unm.OnuProvisioningConfig{
@@ -48,29 +105,197 @@ func (s *ProvisioningService) ProvisionEquipment(ctx context.Context, connInfo *
 		Model:        "AN5506-01-A1",
 	}
 
+	config.Name = s.namingPolicy.Name(naming.Fields{
+		Splitter:     connInfo.ConnectionClientSplitterName,
+		SplitterPort: connInfo.ConnectionClientSplitterPort,
+		Contract:     connInfo.ContractDescription,
+		Client:       connInfo.ClientName,
+		Serial:       connInfo.ConnectionEquipmentSerialNumber,
+		Plan:         connInfo.ContractPlanName,
+	})
+
+	template := s.templateService.Resolve(connInfo.ContractPlanID, connInfo.ContractPlanName)
+	s.applyTemplate(&config, template)
+	config.ExtraWanServices = extraWanServices(connInfo.ExtraWanServices, template)
+
 	s.logger.WithFields(map[string]any{
 		"olt":       config.OltIP,
 		"serial":    config.Serial,
 		"cliente":   config.ClientName,
 		"protocolo": connInfo.AssignmentErpID,
+		"plano":     connInfo.ContractPlanName,
+		"template":  templateName(template),
 	}).Info("Iniciando provisionamento do equipamento")
 
-	if err := s.unmClient.OnuProvisioning(ctx, config); err != nil {
-		return nil, fmt.Errorf("falha no provisionamento: %w", err)
+	// The ONUs of the PON change with the job, a read taken before it no longer holds
+	defer s.forgetPrefetch(ctx, connInfo.ConnectionOltIP, connInfo.ConnectionOltSlot, connInfo.ConnectionOltPort)
+
+	// Every command of the job, restores included, is kept with the audit record
+	ctx, commandLog := unm.WithCommandLog(ctx)
+
+	result := &domain.ProvisioningResult{Template: template}
+	defer func() { result.Commands = commandLog.Commands() }()
+
+	// The registration on the target PON is the one the DEL/ADD overwrites and a failure puts back, the
+	// one the job replaces, an ONU swapped out or moved from another PON, is what a rollback puts back
+	current := s.snapshotOnu(ctx, config.OltIP, config.PonSlot, config.PonPort, config.Serial)
+	result.Previous = cmp.Or(s.replacedSnapshot(ctx, config, replacing), current)
+	result.Overwrote = current != nil
+
+	// An ONU already authorized for this contract keeps its registration, skipping the DEL/ADD
+	// that would take the client offline while the services are reapplied
+	config.Reconfigure = sameRegistration(current, config) && s.features.InRollout(ctx, RolloutReconfigure, config.OltIP, config.Serial)
+	result.Reconfigured = config.Reconfigure
+
+	jobCtx, cancel := unm.WithCommandBudget(ctx, s.budget)
+	steps, wanServices, err := s.client(ctx).OnuProvisioning(jobCtx, config)
+	cancel()
+
+	if config.Reconfigure {
+		s.recordRollout(ctx, result, RolloutReconfigure, err == nil)
 	}
 
+	result.Steps = steps
+	result.WanServices = wanServices
+	if err != nil {
+		// An aborted run puts back the configuration the ONU had before it was deleted, a runaway or
+		// timed out one with nothing to put back does not leave a half configured ONU behind
+		switch {
+		case current != nil:
+			result.Restored = s.restoreOnu(ctx, current)
+		case errors.Is(err, unm.ErrCommandBudgetExceeded), errors.Is(err, unm.ErrStepTimeout):
+			result.Discarded = s.discardOnu(ctx, config)
+		}
+		s.InvalidateOnu(ctx, config.Serial)
+		return result, fmt.Errorf("falha no provisionamento: %w", err)
+	}
+
+	started := time.Now()
 	signalInfo, err := s.fetchOnuSignal(ctx, config)
+	verification := time.Since(started)
+
+	domain.Benchmark(s.logger, "verification", verification)
+	result.Steps = append(result.Steps, domain.StepTiming{Name: "verification", Duration: verification})
+
+	// The verification reading is the state of the ONU just provisioned, the status queries start from it
+	s.rememberOnuState(ctx, &domain.LastJob{
+		Serial: config.Serial,
+		OltIP:  config.OltIP,
+		Slot:   connInfo.ConnectionOltSlot,
+		Port:   connInfo.ConnectionOltPort,
+	}, signalInfo, configFingerprint(config))
+
 	if err != nil {
 		s.logger.WithError(err).Warn("Falha ao obter informações de sinal da ONU")
-		return nil, nil
+		return result, nil
+	}
+
+	result.Signal = signalInfo
+	return result, nil
+}
+
+// snapshotOnu captures the current OLT-side configuration of an ONU about to be replaced, nil when there is none
+func (s *ProvisioningService) snapshotOnu(ctx context.Context, oltIP string, slot, port uint, serial string) *domain.OnuSnapshot {
+	started := time.Now()
+	snapshot, err := s.client(ctx).SnapshotOnu(ctx, slot, port, oltIP, serial)
+	domain.Benchmark(s.logger, "snapshot", time.Since(started))
+
+	if errors.Is(err, unm.ErrOnuNotFound) {
+		s.logger.WithField("serial", serial).Debug("ONU sem configuração anterior na OLT")
+		return nil
+	}
+	if err != nil {
+		s.logger.WithError(err).WithField("serial", serial).Warn("Falha ao capturar configuração anterior da ONU")
+		return nil
+	}
+
+	s.logger.WithFields(map[string]any{
+		"serial": serial,
+		"name":   snapshot.Name,
+		"wan":    len(snapshot.WanServices),
+	}).Info("Configuração anterior da ONU capturada")
+
+	return snapshot
+}
+
+// replacedSnapshot captures the registration the job replaces when it is not the one on the target PON,
+// nil when it is, when it is unknown or when it is gone from the OLT
+func (s *ProvisioningService) replacedSnapshot(ctx context.Context, config unm.OnuProvisioningConfig, replacing *domain.LastJob) *domain.OnuSnapshot {
+	if replacing == nil || replacing.Serial == "" || replacing.OltIP == "" {
+		return nil
+	}
+
+	slot, port, err := s.parseOltSlotPort(replacing.Slot, replacing.Port)
+	if err != nil {
+		s.logger.WithError(err).WithField("serial", replacing.Serial).Warn("Localização da ONU substituída inválida")
+		return nil
+	}
+
+	inPlace := strings.EqualFold(replacing.Serial, config.Serial) && replacing.OltIP == config.OltIP && slot == config.PonSlot && port == config.PonPort
+	if inPlace {
+		return nil
+	}
+
+	return s.snapshotOnu(ctx, replacing.OltIP, slot, port, replacing.Serial)
+}
+
+// sameRegistration reports whether the ONU found on the PON is registered for the contract being
+// provisioned, with the same model and either the same name or the same PPPoE user
+func sameRegistration(snapshot *domain.OnuSnapshot, config unm.OnuProvisioningConfig) bool {
+	if snapshot == nil || !strings.EqualFold(snapshot.Model, config.Model) {
+		return false
+	}
+
+	if snapshot.Name != "" && snapshot.Name == config.Name {
+		return true
+	}
+
+	return slices.ContainsFunc(snapshot.WanServices, func(service domain.WanServiceSnapshot) bool {
+		return service.PPPoEUser != "" && service.PPPoEUser == config.PPPoEUser
+	})
+}
+
+// restoreOnu puts the captured configuration back after an aborted provisioning, reporting whether it worked
+func (s *ProvisioningService) restoreOnu(ctx context.Context, snapshot *domain.OnuSnapshot) bool {
+	restoreCtx, cancel := unm.RestoreContext(ctx)
+	defer cancel()
+
+	if err := s.client(ctx).RestoreOnu(restoreCtx, snapshot); err != nil {
+		s.logger.WithError(err).WithField("serial", snapshot.Serial).Error("Falha ao restaurar configuração anterior da ONU")
+		return false
 	}
+	return true
+}
 
-	return signalInfo, nil
+// discardOnu removes the ONU an aborted run left half configured, reporting whether it worked
+func (s *ProvisioningService) discardOnu(ctx context.Context, config unm.OnuProvisioningConfig) bool {
+	discardCtx, cancel := unm.RestoreContext(ctx)
+	defer cancel()
+
+	if err := s.client(ctx).DiscardOnu(discardCtx, config); err != nil {
+		s.logger.WithError(err).WithField("serial", config.Serial).Error("Falha ao remover ONU parcialmente provisionada")
+		return false
+	}
+	return true
+}
+
+// RestoreSnapshot rolls an ONU back to a configuration captured before a swap, replacing the current one
+func (s *ProvisioningService) RestoreSnapshot(ctx context.Context, snapshot *domain.OnuSnapshot) error {
+	if err := s.permissions.Authorize(ctx, domain.Tl1OperationDelete, domain.Tl1OperationProvision); err != nil {
+		return err
+	}
+
+	defer s.InvalidateOnu(ctx, snapshot.Serial)
+
+	if err := s.client(ctx).RestoreOnu(ctx, snapshot); err != nil {
+		return fmt.Errorf("falha ao restaurar configuração anterior: %w", err)
+	}
+	return nil
 }
 
 // fetchOnuSignal retrieves optical signal information from the ONU
 func (s *ProvisioningService) fetchOnuSignal(ctx context.Context, config unm.OnuProvisioningConfig) (*domain.OnuSignalInfo, error) {
-	opticalInfo, err := s.unmClient.OnuInfo(
+	opticalInfo, err := s.client(ctx).OnuInfo(
 		ctx,
 		config.PonSlot,
 		config.PonPort,
@@ -81,46 +306,296 @@ func (s *ProvisioningService) fetchOnuSignal(ctx context.Context, config unm.Onu
 		return nil, fmt.Errorf("falha ao obter informações ópticas: %w", err)
 	}
 
-	return &domain.OnuSignalInfo{
-		TxPower: opticalInfo.TxPower,
-		RxPower: opticalInfo.RxPower,
-	}, nil
+	signal := &domain.OnuSignalInfo{
+		TxPower:     opticalInfo.TxPower,
+		RxPower:     opticalInfo.RxPower,
+		Voltage:     opticalInfo.Voltage,
+		Temperature: opticalInfo.Temperature,
+	}
+	s.thresholdService.Evaluate(config.OltIP, signal)
+
+	return signal, nil
+}
+
+// CheckSignal retrieves the current optical signal of an already provisioned ONU, refreshing its cached state
+// when the OLT answered for it
+func (s *ProvisioningService) CheckSignal(ctx context.Context, job *domain.LastJob) (*domain.OnuSignalInfo, error) {
+	signal, err := s.checkSignal(ctx, job)
+	if err == nil || isOnuNoAnswer(err) {
+		s.rememberOnuState(ctx, job, signal, "")
+	}
+	return signal, err
+}
+
+// checkSignal reads the optical signal of an ONU from the OLT
+func (s *ProvisioningService) checkSignal(ctx context.Context, job *domain.LastJob) (*domain.OnuSignalInfo, error) {
+	if err := s.permissions.Authorize(ctx, domain.Tl1OperationQuery); err != nil {
+		return nil, err
+	}
+
+	slot, port, err := s.parseOltSlotPort(job.Slot, job.Port)
+	if err != nil {
+		return nil, fmt.Errorf("falha ao analisar slot/porta da OLT: %w", err)
+	}
+
+	s.logger.WithFields(map[string]any{
+		"olt":    job.OltIP,
+		"serial": job.Serial,
+	}).Info("Verificando sinal da ONU")
+
+	return s.fetchOnuSignal(ctx, unm.OnuProvisioningConfig{
+		OltIP:   job.OltIP,
+		PonSlot: slot,
+		PonPort: port,
+		Serial:  job.Serial,
+	})
+}
+
+// PonSignals retrieves the optical signal of every ONU of a PON with a single query, keyed by serial
+func (s *ProvisioningService) PonSignals(ctx context.Context, oltIP, slotStr, portStr string) (map[string]*domain.OnuSignalInfo, error) {
+	if err := s.permissions.Authorize(ctx, domain.Tl1OperationQuery); err != nil {
+		return nil, err
+	}
+
+	slot, port, err := s.parseOltSlotPort(slotStr, portStr)
+	if err != nil {
+		return nil, fmt.Errorf("falha ao analisar slot/porta da OLT: %w", err)
+	}
+
+	readings, err := s.client(ctx).PonOpticalInfo(ctx, slot, port, oltIP)
+	if err != nil {
+		return nil, fmt.Errorf("falha ao obter informações ópticas da PON: %w", err)
+	}
+
+	signals := make(map[string]*domain.OnuSignalInfo, len(readings))
+	for serial, reading := range readings {
+		signal := &domain.OnuSignalInfo{
+			TxPower:     reading.TxPower,
+			RxPower:     reading.RxPower,
+			Voltage:     reading.Voltage,
+			Temperature: reading.Temperature,
+		}
+		s.thresholdService.Evaluate(oltIP, signal)
+		signals[serial] = signal
+	}
+
+	return signals, nil
+}
+
+// RemoveOnu deletes an ONU registration from the OLT, used for orphan ONUs and the ONUs of cancelled contracts
+func (s *ProvisioningService) RemoveOnu(ctx context.Context, job *domain.LastJob) error {
+	if err := s.permissions.Authorize(ctx, domain.Tl1OperationDelete); err != nil {
+		return err
+	}
+
+	return s.deleteOnu(ctx, job)
+}
+
+// RemoveReplacedOnu deletes the ONU a swap took the place of. The removal finishes the provisioning of the
+// new ONU, so whoever may provision may also run it.
+func (s *ProvisioningService) RemoveReplacedOnu(ctx context.Context, job *domain.LastJob) error {
+	if err := s.permissions.Authorize(ctx, domain.Tl1OperationProvision); err != nil {
+		return err
+	}
+
+	return s.deleteOnu(ctx, job)
+}
+
+// deleteOnu deletes an ONU registration from the OLT, forgetting what was read about it
+func (s *ProvisioningService) deleteOnu(ctx context.Context, job *domain.LastJob) error {
+	slot, port, err := s.parseOltSlotPort(job.Slot, job.Port)
+	if err != nil {
+		return fmt.Errorf("falha ao analisar slot/porta da OLT: %w", err)
+	}
+
+	defer s.forgetPrefetch(ctx, job.OltIP, job.Slot, job.Port)
+	defer s.InvalidateOnu(ctx, job.Serial)
+
+	return s.client(ctx).DiscardOnu(ctx, unm.OnuProvisioningConfig{
+		OltIP:   job.OltIP,
+		PonSlot: slot,
+		PonPort: port,
+		Serial:  job.Serial,
+	})
+}
+
+// IsUnmFailure reports whether a provisioning error comes from the UNM or the connection to it. Jobs refused
+// for their data, their role or a missing approval, and jobs given up by the user, say nothing of the OLTs.
+func IsUnmFailure(err error) bool {
+	switch {
+	case err == nil,
+		errors.Is(err, ErrTl1OperationDenied),
+		errors.Is(err, ErrInvalidConnectionInfo),
+		errors.Is(err, unm.ErrInvalidConfig),
+		errors.Is(err, unm.ErrInvalidPonID),
+		errors.Is(err, unm.ErrNoServicePorts),
+		errors.Is(err, unm.ErrMulticastUnsupported),
+		errors.Is(err, unm.ErrApprovalRequired),
+		errors.Is(err, context.Canceled):
+		return false
+	}
+	return true
+}
+
+// WatchdogTrips returns how many times the production UNM connection was found wedged and re-dialed
+func (s *ProvisioningService) WatchdogTrips() uint64 {
+	return s.unmClient.WatchdogTrips()
+}
+
+// UnmReachable reports whether the production UNM answered the last command sent to it
+func (s *ProvisioningService) UnmReachable() bool {
+	return s.unmClient.Reachable()
+}
+
+// PingUnm checks the production UNM answers
+func (s *ProvisioningService) PingUnm(ctx context.Context) error {
+	return s.unmClient.Ping(ctx)
+}
+
+// SupportsWifiScan reports whether the UNM in use can list the Wi-Fi networks around an ONU
+func (s *ProvisioningService) SupportsWifiScan(ctx context.Context) bool {
+	return s.client(ctx).Capabilities().Has(unm.CapabilityWifiScan)
+}
+
+// ScanWifi lists the Wi-Fi networks seen by an already provisioned ONU and suggests the best channels
+func (s *ProvisioningService) ScanWifi(ctx context.Context, job *domain.LastJob) (*domain.WifiScanReport, error) {
+	if err := s.permissions.Authorize(ctx, domain.Tl1OperationQuery); err != nil {
+		return nil, err
+	}
+
+	if !s.allowNonCritical(ctx, ShedWifiScan) {
+		return nil, ErrLoadShed
+	}
+
+	slot, port, err := s.parseOltSlotPort(job.Slot, job.Port)
+	if err != nil {
+		return nil, fmt.Errorf("falha ao analisar slot/porta da OLT: %w", err)
+	}
+
+	s.logger.WithFields(map[string]any{
+		"olt":    job.OltIP,
+		"serial": job.Serial,
+	}).Info("Consultando redes Wi-Fi vizinhas da ONU")
+
+	neighbors, err := s.client(ctx).WifiScan(ctx, slot, port, job.OltIP, job.Serial)
+	if err != nil {
+		return nil, err
+	}
+
+	return AnalyzeWifiScan(neighbors), nil
+}
+
+// client returns the UNM client for the context, the sandbox one for training sessions
+func (s *ProvisioningService) client(ctx context.Context) *unm.UNMClient {
+	if domain.IsTraining(ctx) && s.sandboxClient != nil {
+		return s.sandboxClient
+	}
+	return s.unmClient
 }
 
 // validateConnectionInfo validates the connection information structure
 func (s *ProvisioningService) validateConnectionInfo(connInfo *dto.ConnectionInfo) error {
-	if connInfo == nil {
-		return fmt.Errorf("informações de conexão são nulas")
+	return validation.Provisioning(connInfo).Err()
+}
+
+// applyTemplate fills provisioning parameters from the contract plan template
+func (s *ProvisioningService) applyTemplate(config *unm.OnuProvisioningConfig, template *domain.ProvisioningTemplate) {
+	if template == nil {
+		return
 	}
-	if connInfo.ConnectionOltIP == "" {
-		return fmt.Errorf("IP da OLT é obrigatório")
+
+	if config.Vlan == "" {
+		config.Vlan = template.Vlan
 	}
-	if connInfo.ConnectionEquipmentSerialNumber == "" {
-		return fmt.Errorf("número de série do equipamento é obrigatório")
+
+	config.UpstreamProfile = template.UpstreamProfile
+	config.DownstreamProfile = template.DownstreamProfile
+	config.DBAProfile = template.DBAProfile
+
+	wanPorts := template.WanPorts
+	if len(wanPorts) == 0 {
+		wanPorts = unm.DefaultWanPorts
 	}
-	if connInfo.ConnectionClientPPPoEUsername == "" {
-		return fmt.Errorf("nome de usuário PPPoE é obrigatório")
+
+	if template.WifiEnabled != nil && !*template.WifiEnabled {
+		wanPorts = slices.DeleteFunc(slices.Clone(wanPorts), func(port string) bool {
+			return strings.HasPrefix(port, "SSID=")
+		})
 	}
-	if connInfo.ConnectionClientPPPoEPassword == "" {
-		return fmt.Errorf("senha PPPoE é obrigatória")
+
+	config.WanPorts = wanPorts
+	config.MulticastVlan = template.MulticastVlan
+	config.MulticastPorts = template.MulticastPorts
+}
+
+// extraWanServices binds each extra WAN service of the contract to the ports the plan template
+// lists for it, the first service_ports key found in the service name wins
+func extraWanServices(services []dto.WanService, template *domain.ProvisioningTemplate) []unm.WanService {
+	var keys []string
+	if template != nil {
+		keys = slices.Sorted(maps.Keys(template.ServicePorts))
 	}
-	if connInfo.ConnectionClientVlan == "" {
-		return fmt.Errorf("VLAN é obrigatória")
+
+	extra := make([]unm.WanService, 0, len(services))
+	for _, service := range services {
+		wanService := unm.WanService{
+			Name:      service.ServiceName,
+			Vlan:      service.Vlan,
+			PPPoEUser: service.PPPoEUsername,
+			PPPoEPass: service.PPPoEPassword,
+		}
+
+		for _, key := range keys {
+			if strings.Contains(strings.ToLower(service.ServiceName), strings.ToLower(key)) {
+				wanService.Ports = template.ServicePorts[key]
+				break
+			}
+		}
+
+		extra = append(extra, wanService)
 	}
-	return nil
+
+	return extra
 }
 
-// parseOltSlotPort parses string slot and port values to unsigned integers
+// templateName returns the template name for logging
+func templateName(template *domain.ProvisioningTemplate) string {
+	if template == nil {
+		return ""
+	}
+	return template.Name
+}
+
+// parseOltSlotPort parses the slot and port as stored by the ERP, see pon.ParseSlotPort for the accepted formats
 func (s *ProvisioningService) parseOltSlotPort(slotStr, portStr string) (uint, uint, error) {
-	slot, err := strconv.ParseUint(strings.TrimSpace(slotStr), 10, 32)
+	location, err := pon.ParseSlotPort(slotStr, portStr)
 	if err != nil {
-		return 0, 0, fmt.Errorf("slot inválido: %w", err)
+		return 0, 0, err
 	}
 
-	port, err := strconv.ParseUint(strings.TrimSpace(portStr), 10, 32)
-	if err != nil {
-		return 0, 0, fmt.Errorf("porta inválida: %w", err)
+	if raw := strings.TrimSpace(slotStr) + "/" + strings.TrimSpace(portStr); raw != location.String() {
+		s.logger.WithFields(map[string]any{
+			"slot":  slotStr,
+			"porta": portStr,
+			"pon":   location.String(),
+		}).Debug("Slot/porta da OLT normalizados")
 	}
 
-	return uint(slot), uint(port), nil
+	return location.Slot, location.Port, nil
+}
+
+// recordRollout feeds the error rate of a step the job took the new path of, simulated runs say nothing about it
+func (s *ProvisioningService) recordRollout(ctx context.Context, result *domain.ProvisioningResult, step string, success bool) {
+	if domain.IsTraining(ctx) {
+		return
+	}
+	if s.features.RecordRollout(ctx, step, success) {
+		result.RolledBack = append(result.RolledBack, step)
+	}
+}
+
+// allowNonCritical reports whether an operation the activations do not need may reach the UNM now, the
+// simulator of the training sessions is never saturated
+func (s *ProvisioningService) allowNonCritical(ctx context.Context, operation string) bool {
+	return domain.IsTraining(ctx) || s.loadShedder.Allow(operation)
 }
@@ -0,0 +1,252 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	rolloutNamespace = "rollouts"
+
+	// rolloutBackKey marks a step rolled back to the old path, cleared by the next percentage set by an admin
+	rolloutBackKey = "!rollback"
+
+	// Rollout defaults
+	DefaultRolloutMaxErrorRate = 0.2
+	DefaultRolloutMinSamples   = 10
+	DefaultRolloutWindow       = time.Hour
+)
+
+// Pipeline steps launched through a percentage rollout
+const (
+	// RolloutReconfigure keeps an ONU already authorized for the contract and only reapplies its services
+	RolloutReconfigure = "reconfigure"
+)
+
+// DefaultRollouts is the share of jobs, in percent, taking the new path of each step until an admin sets another
+var DefaultRollouts = map[string]int{
+	RolloutReconfigure: 100,
+}
+
+// RolloutPolicy defines when a step is rolled back to the old path, once the error rate of the jobs taking the
+// new one exceeds MaxErrorRate over at least MinSamples of them within the window
+type RolloutPolicy struct {
+	MaxErrorRate float64
+	MinSamples   int
+	Window       time.Duration
+}
+
+// RolloutState is the rollout of a step, the percentage of every OLT set apart from the global one included
+type RolloutState struct {
+	Name       string
+	Percent    int
+	Olts       map[string]int
+	RolledBack bool
+	ErrorRate  float64
+	Samples    int
+}
+
+// IsRollout reports whether the name is a step launched through a percentage rollout
+func IsRollout(name string) bool {
+	_, exists := DefaultRollouts[normalizeFeature(name)]
+	return exists
+}
+
+// InRollout reports whether a job takes the new path of a step, the key keeping the same equipment on the
+// same side of the rollout between retries
+func (s *FeatureService) InRollout(ctx context.Context, name, olt, key string) bool {
+	percent := s.Rollout(ctx, name, olt)
+	switch {
+	case percent <= 0:
+		return false
+	case percent >= 100:
+		return true
+	}
+
+	hash := fnv.New32a()
+	_, _ = hash.Write([]byte(normalizeFeature(name) + ":" + key))
+	return int(hash.Sum32()%100) < percent
+}
+
+// Rollout returns the percentage of jobs on the OLT taking the new path of a step, zero once rolled back
+func (s *FeatureService) Rollout(ctx context.Context, name, olt string) int {
+	name = normalizeFeature(name)
+
+	stored, err := s.repository.List(ctx, rolloutNamespace)
+	if err != nil {
+		s.logger.WithError(err).Warn("Falha ao ler rollouts salvos")
+	}
+
+	state := s.rolloutState(name, stored)
+	if state.RolledBack {
+		return 0
+	}
+	if percent, exists := state.Olts[olt]; exists {
+		return percent
+	}
+	return state.Percent
+}
+
+// SetRollout sets the percentage of jobs taking the new path of a step, on a single OLT when one is given.
+// Any percentage set resumes a step rolled back and starts its error rate over.
+func (s *FeatureService) SetRollout(ctx context.Context, name, olt string, percent int) error {
+	name = normalizeFeature(name)
+	if !IsRollout(name) {
+		return fmt.Errorf("etapa %s não tem lançamento gradual", name)
+	}
+	if percent < 0 || percent > 100 {
+		return fmt.Errorf("percentual %d fora do intervalo de 0 a 100", percent)
+	}
+
+	key := name
+	if olt != "" {
+		key = name + "@" + olt
+	}
+	if err := s.repository.Set(ctx, rolloutNamespace, key, strconv.Itoa(percent)); err != nil {
+		return fmt.Errorf("falha ao salvar rollout %s: %w", name, err)
+	}
+	if err := s.repository.Set(ctx, rolloutNamespace, name+rolloutBackKey, "false"); err != nil {
+		return fmt.Errorf("falha ao retomar rollout %s: %w", name, err)
+	}
+
+	s.rolloutMu.Lock()
+	delete(s.rolloutOutcomes, name)
+	s.rolloutMu.Unlock()
+
+	s.logger.WithFields(map[string]any{
+		"step":    name,
+		"olt":     olt,
+		"percent": percent,
+	}).Info("Rollout atualizado")
+
+	return nil
+}
+
+// RecordRollout registers the outcome of a job that took the new path of a step, reporting true when its error
+// rate rolled the step back to the old path on every OLT
+func (s *FeatureService) RecordRollout(ctx context.Context, name string, success bool) bool {
+	name = normalizeFeature(name)
+
+	s.rolloutMu.Lock()
+	now := s.clock.Now()
+	outcomes := pruneOutcomes(append(s.rolloutOutcomes[name], outcome{success: success, at: now}), now.Add(-s.rollout.Window))
+	s.rolloutOutcomes[name] = outcomes
+	rate := errorRate(outcomes)
+	trip := len(outcomes) >= s.rollout.MinSamples && rate > s.rollout.MaxErrorRate
+	if trip {
+		delete(s.rolloutOutcomes, name)
+	}
+	s.rolloutMu.Unlock()
+
+	if !trip {
+		return false
+	}
+
+	if err := s.repository.Set(ctx, rolloutNamespace, name+rolloutBackKey, "true"); err != nil {
+		s.logger.WithError(err).WithField("step", name).Error("Falha ao reverter rollout")
+		return false
+	}
+
+	s.logger.WithFields(map[string]any{
+		"step":       name,
+		"error_rate": rate * 100,
+		"samples":    len(outcomes),
+	}).Warn("Rollout revertido para o caminho antigo por taxa de erro")
+
+	return true
+}
+
+// Rollouts returns the state of every step launched through a percentage rollout, sorted by name
+func (s *FeatureService) Rollouts(ctx context.Context) []RolloutState {
+	stored, err := s.repository.List(ctx, rolloutNamespace)
+	if err != nil {
+		s.logger.WithError(err).Warn("Falha ao listar rollouts salvos")
+	}
+
+	s.rolloutMu.Lock()
+	defer s.rolloutMu.Unlock()
+
+	cutoff := s.clock.Now().Add(-s.rollout.Window)
+
+	var states []RolloutState
+	for _, name := range slices.Sorted(maps.Keys(DefaultRollouts)) {
+		state := s.rolloutState(name, stored)
+		outcomes := pruneOutcomes(s.rolloutOutcomes[name], cutoff)
+		state.ErrorRate = errorRate(outcomes)
+		state.Samples = len(outcomes)
+		states = append(states, state)
+	}
+	return states
+}
+
+// RolloutPolicy returns the policy rolling the steps back
+func (s *FeatureService) RolloutPolicy() RolloutPolicy {
+	return s.rollout
+}
+
+// rolloutState reads the stored rollout of a step, falling back to its default percentage
+func (s *FeatureService) rolloutState(name string, stored map[string]string) RolloutState {
+	state := RolloutState{Name: name, Percent: DefaultRollouts[name], Olts: make(map[string]int)}
+
+	for key, value := range stored {
+		if key == name+rolloutBackKey {
+			state.RolledBack, _ = strconv.ParseBool(value)
+			continue
+		}
+
+		percent, err := strconv.Atoi(value)
+		if err != nil {
+			continue
+		}
+		if key == name {
+			state.Percent = percent
+		} else if olt, found := strings.CutPrefix(key, name+"@"); found {
+			state.Olts[olt] = percent
+		}
+	}
+	return state
+}
+
+// withDefaults fills the zero values of the rollout policy
+func (p RolloutPolicy) withDefaults() RolloutPolicy {
+	if p.MaxErrorRate <= 0 || p.MaxErrorRate > 1 {
+		p.MaxErrorRate = DefaultRolloutMaxErrorRate
+	}
+	if p.MinSamples <= 0 {
+		p.MinSamples = DefaultRolloutMinSamples
+	}
+	if p.Window <= 0 {
+		p.Window = DefaultRolloutWindow
+	}
+	return p
+}
+
+// pruneOutcomes drops the outcomes before the cutoff, oldest first
+func pruneOutcomes(outcomes []outcome, cutoff time.Time) []outcome {
+	i := 0
+	for i < len(outcomes) && outcomes[i].at.Before(cutoff) {
+		i++
+	}
+	return outcomes[i:]
+}
+
+// errorRate computes the ratio of failed outcomes
+func errorRate(outcomes []outcome) float64 {
+	if len(outcomes) == 0 {
+		return 0
+	}
+
+	failures := 0
+	for _, o := range outcomes {
+		if !o.success {
+			failures++
+		}
+	}
+	return float64(failures) / float64(len(outcomes))
+}
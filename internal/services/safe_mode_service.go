@@ -0,0 +1,31 @@
+package services
+
+import "slices"
+
+// Degradation is an optional subsystem left off at startup because its configuration is invalid
+type Degradation struct {
+	Setting string `json:"setting"`
+	Feature string `json:"feature"`
+	Reason  string `json:"reason"`
+}
+
+// SafeModeService reports the optional subsystems disabled at startup, the process runs in safe mode
+// while any of them is off instead of refusing to start
+type SafeModeService struct {
+	degradations []Degradation
+}
+
+// NewSafeModeService creates a new safe mode report of the subsystems left off
+func NewSafeModeService(degradations []Degradation) *SafeModeService {
+	return &SafeModeService{degradations: slices.Clone(degradations)}
+}
+
+// IsActive reports whether any optional subsystem was left off
+func (s *SafeModeService) IsActive() bool {
+	return len(s.degradations) > 0
+}
+
+// Degradations returns the subsystems left off, in the order they were found
+func (s *SafeModeService) Degradations() []Degradation {
+	return slices.Clone(s.degradations)
+}
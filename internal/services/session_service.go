@@ -1,62 +1,141 @@
 package services
 
 import (
+	"errors"
+	"provisioning-assistant/internal/clock"
 	"provisioning-assistant/internal/domain"
+	"provisioning-assistant/internal/ids"
+	"slices"
 	"sync"
 	"time"
 )
 
+const SessionTTL = 30 * time.Minute
+
+var ErrSessionNotFound = errors.New("sessão não encontrada")
+
+// SessionService stores user sessions, handing out copies so callers never
+// share state, and applying changes atomically through UpdateFn
 type SessionService struct {
 	sessions map[int64]*domain.Session
-	mu       sync.RWMutex
+	metrics  *FlowMetrics
+	clock    clock.Clock
+	mu       sync.Mutex
 }
 
 // NewSessionService creates a new session service instance
-func NewSessionService() *SessionService {
+func NewSessionService(clock clock.Clock) *SessionService {
 	return &SessionService{
 		sessions: make(map[int64]*domain.Session),
+		metrics:  NewFlowMetrics(clock),
+		clock:    clock,
 	}
 }
 
-// CreateSession creates a new user session with idle state
+// Metrics returns the state transition and dwell time metrics of the sessions
+func (s *SessionService) Metrics() *FlowMetrics {
+	return s.metrics
+}
+
+// CreateSession creates a new user session with idle state and returns a copy
 func (s *SessionService) CreateSession(userID, chatID int64) *domain.Session {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
+	now := s.clock.Now()
 	session := &domain.Session{
-		UserID:    userID,
-		ChatID:    chatID,
-		State:     domain.StateIdle,
-		CreatedAt: time.Now(),
-		UpdatedAt: time.Now(),
+		ID:             ids.New(),
+		UserID:         userID,
+		ChatID:         chatID,
+		State:          domain.StateIdle,
+		StateEnteredAt: now,
+		CreatedAt:      now,
+		UpdatedAt:      now,
 	}
 
 	s.sessions[userID] = session
-	return session
+	s.metrics.Enter(session.State)
+	return session.Clone()
 }
 
-// GetSession retrieves a session by user ID, returns nil if expired
+// GetSession retrieves a copy of the session by user ID, returns nil if expired
 func (s *SessionService) GetSession(userID int64) *domain.Session {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
+	s.mu.Lock()
+	defer s.mu.Unlock()
 
-	if session, exists := s.sessions[userID]; exists {
-		if time.Since(session.UpdatedAt) > 30*time.Minute {
-			delete(s.sessions, userID)
-			return nil
+	session, exists := s.lookup(userID)
+	if !exists {
+		return nil
+	}
+	return session.Clone()
+}
+
+// WithProtocol returns copies of the active sessions working on a protocol of the ERP
+func (s *SessionService) WithProtocol(protocol string) []*domain.Session {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var matched []*domain.Session
+	for userID, session := range s.sessions {
+		if session.Manual || session.Protocol != protocol {
+			continue
+		}
+		if _, exists := s.lookup(userID); exists {
+			matched = append(matched, session.Clone())
+		}
+	}
+
+	return matched
+}
+
+// UpdateFn applies changes to the stored session atomically and returns a copy of the result,
+// resetting the invalid attempt counter whenever the state changes. State changes and invalid
+// attempts are recorded in the flow metrics.
+func (s *SessionService) UpdateFn(userID int64, fn func(session *domain.Session)) (*domain.Session, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	session, exists := s.lookup(userID)
+	if !exists {
+		return nil, ErrSessionNotFound
+	}
+
+	previousState := session.State
+	previousAttempts := session.InvalidAttempts
+	fn(session)
+	session.UpdatedAt = s.clock.Now()
+
+	switch {
+	case session.State != previousState:
+		var dwell time.Duration
+		if !session.StateEnteredAt.IsZero() {
+			dwell = session.UpdatedAt.Sub(session.StateEnteredAt)
 		}
-		return session
+		s.metrics.Transition(previousState, session.State, dwell)
+		session.StateEnteredAt = session.UpdatedAt
+		session.InvalidAttempts = 0
+	case session.InvalidAttempts > previousAttempts:
+		s.metrics.InvalidInput(session.State)
 	}
-	return nil
+
+	return session.Clone(), nil
 }
 
-// UpdateSession updates session timestamp and saves changes
-func (s *SessionService) UpdateSession(session *domain.Session) {
+// Restore stores a copy of a session only when none is active for the user,
+// allowing a flow to continue after its session expired
+func (s *SessionService) Restore(session *domain.Session) *domain.Session {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	session.UpdatedAt = time.Now()
-	s.sessions[session.UserID] = session
+	if current, exists := s.lookup(session.UserID); exists {
+		return current.Clone()
+	}
+
+	stored := session.Clone()
+	stored.UpdatedAt = s.clock.Now()
+	s.sessions[stored.UserID] = stored
+
+	return stored.Clone()
 }
 
 // DeleteSession removes a session from memory
@@ -66,3 +145,90 @@ func (s *SessionService) DeleteSession(userID int64) {
 
 	delete(s.sessions, userID)
 }
+
+// PurgeExpired removes every expired session from memory and returns how many were removed
+func (s *SessionService) PurgeExpired() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	purged := 0
+	for userID, session := range s.sessions {
+		if s.clock.Since(session.UpdatedAt) > SessionTTL {
+			if session.State != domain.StateIdle {
+				s.metrics.Abandon(session.State)
+			}
+			delete(s.sessions, userID)
+			purged++
+		}
+	}
+
+	return purged
+}
+
+// ExpireAuthenticated removes the sessions of authenticated users, except those in one of the kept
+// states, and returns copies of the removed sessions that had not expired yet
+func (s *SessionService) ExpireAuthenticated(keep ...domain.SessionState) []*domain.Session {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var expired []*domain.Session
+	for userID, session := range s.sessions {
+		if session.UserTaxID == "" || slices.Contains(keep, session.State) {
+			continue
+		}
+
+		delete(s.sessions, userID)
+		if s.clock.Since(session.UpdatedAt) > SessionTTL {
+			continue
+		}
+
+		if session.State != domain.StateIdle {
+			s.metrics.Abandon(session.State)
+		}
+		expired = append(expired, session.Clone())
+	}
+
+	return expired
+}
+
+// Stalled returns copies of the sessions silent for longer than the delay their state allows,
+// a zero delay skips the state. Each silence is reported once and the last activity is kept,
+// so a nudge neither repeats nor postpones the expiry.
+func (s *SessionService) Stalled(delay func(state domain.SessionState) time.Duration) []*domain.Session {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.clock.Now()
+
+	var stalled []*domain.Session
+	for _, session := range s.sessions {
+		idle := now.Sub(session.UpdatedAt)
+		if idle > SessionTTL || session.NudgedAt.After(session.UpdatedAt) {
+			continue
+		}
+
+		if after := delay(session.State); after <= 0 || idle < after {
+			continue
+		}
+
+		session.NudgedAt = now
+		stalled = append(stalled, session.Clone())
+	}
+
+	return stalled
+}
+
+// lookup returns the stored session while it is not expired, must be called with the lock held
+func (s *SessionService) lookup(userID int64) (*domain.Session, bool) {
+	session, exists := s.sessions[userID]
+	if !exists {
+		return nil, false
+	}
+
+	if s.clock.Since(session.UpdatedAt) > SessionTTL {
+		delete(s.sessions, userID)
+		return nil, false
+	}
+
+	return session, true
+}
@@ -0,0 +1,142 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"provisioning-assistant/internal/domain"
+	"slices"
+)
+
+// DefaultSignalThreshold applies to OLTs without a configured threshold, a class B+ budget with margin
+var DefaultSignalThreshold = domain.SignalThreshold{Warn: -25, Critical: -27}
+
+// SignalRegion groups OLTs that share the same signal threshold
+type SignalRegion struct {
+	Name string   `json:"name"`
+	Olts []string `json:"olts"`
+	domain.SignalThreshold
+}
+
+// SignalThresholdPolicy holds the acceptable reception power of each network segment, an OLT entry
+// taking precedence over its region and the region over the default
+type SignalThresholdPolicy struct {
+	Default *domain.SignalThreshold           `json:"default,omitempty"`
+	Regions []SignalRegion                    `json:"regions,omitempty"`
+	Olts    map[string]domain.SignalThreshold `json:"olts,omitempty"`
+}
+
+type SignalThresholdService struct {
+	fallback domain.SignalThreshold
+	byOlt    map[string]domain.SignalThreshold
+	regions  map[string]string
+}
+
+// NewSignalThresholdService creates the resolver of the signal thresholds of each OLT
+func NewSignalThresholdService(policy SignalThresholdPolicy) *SignalThresholdService {
+	service := &SignalThresholdService{
+		fallback: DefaultSignalThreshold,
+		byOlt:    make(map[string]domain.SignalThreshold),
+		regions:  make(map[string]string),
+	}
+
+	if policy.Default != nil {
+		service.fallback = *policy.Default
+	}
+
+	for _, region := range policy.Regions {
+		for _, olt := range region.Olts {
+			service.byOlt[olt] = region.SignalThreshold
+			service.regions[olt] = region.Name
+		}
+	}
+
+	// OLT entries are applied last so they override the region
+	for olt, threshold := range policy.Olts {
+		service.byOlt[olt] = threshold
+	}
+
+	return service
+}
+
+// LoadSignalThresholds reads the signal threshold policy from a JSON file
+func LoadSignalThresholds(path string) (SignalThresholdPolicy, error) {
+	var policy SignalThresholdPolicy
+	if path == "" {
+		return policy, nil
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return policy, fmt.Errorf("falha ao ler arquivo de limites de sinal: %w", err)
+	}
+
+	if err := json.Unmarshal(content, &policy); err != nil {
+		return policy, fmt.Errorf("falha ao interpretar arquivo de limites de sinal: %w", err)
+	}
+
+	if err := policy.Validate(); err != nil {
+		return policy, err
+	}
+
+	return policy, nil
+}
+
+// Validate checks that every critical threshold is below its warning threshold
+func (p *SignalThresholdPolicy) Validate() error {
+	check := func(name string, threshold domain.SignalThreshold) error {
+		if threshold.Critical > threshold.Warn {
+			return fmt.Errorf("limite de sinal %s: crítico (%.2f dBm) acima do alerta (%.2f dBm)", name, threshold.Critical, threshold.Warn)
+		}
+		return nil
+	}
+
+	if p.Default != nil {
+		if err := check("padrão", *p.Default); err != nil {
+			return err
+		}
+	}
+
+	for _, region := range p.Regions {
+		if region.Name == "" {
+			return fmt.Errorf("região de limite de sinal sem nome")
+		}
+		if err := check(region.Name, region.SignalThreshold); err != nil {
+			return err
+		}
+	}
+
+	for olt, threshold := range p.Olts {
+		if err := check(olt, threshold); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Resolve returns the signal threshold of an OLT
+func (s *SignalThresholdService) Resolve(oltIP string) domain.SignalThreshold {
+	if threshold, exists := s.byOlt[oltIP]; exists {
+		return threshold
+	}
+	return s.fallback
+}
+
+// Region returns the name of the region an OLT belongs to, empty when it has none
+func (s *SignalThresholdService) Region(oltIP string) string {
+	return s.regions[oltIP]
+}
+
+// Olts returns the OLTs with a configured threshold, directly or through their region
+func (s *SignalThresholdService) Olts() []string {
+	return slices.Sorted(maps.Keys(s.byOlt))
+}
+
+// Evaluate rates the reception power of a signal reading taken on an OLT
+func (s *SignalThresholdService) Evaluate(oltIP string, signal *domain.OnuSignalInfo) {
+	threshold := s.Resolve(oltIP)
+	signal.Threshold = &threshold
+	signal.Level = threshold.Classify(signal.RxPower)
+}
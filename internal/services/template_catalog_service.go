@@ -0,0 +1,156 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"provisioning-assistant/internal/domain"
+	"reflect"
+	"strings"
+	"time"
+)
+
+const (
+	TemplateCatalogExtension      = ".json"
+	TemplateCatalogArtifactPrefix = "templates/"
+)
+
+var (
+	ErrTemplateCatalogInvalid       = errors.New("arquivo de templates inválido")
+	ErrTemplateCatalogVersion       = errors.New("versão do arquivo de templates não suportada")
+	ErrTemplateCatalogPathForbidden = errors.New("nome de arquivo de templates inválido")
+)
+
+type TemplateCatalogService struct {
+	templates *PlanTemplateService
+	artifacts *ArtifactService
+	logger    domain.Logger
+}
+
+// NewTemplateCatalogService creates the service that moves the provisioning templates between
+// environments as JSON files in the artifact store
+func NewTemplateCatalogService(templates *PlanTemplateService, artifacts *ArtifactService, logger domain.Logger) *TemplateCatalogService {
+	return &TemplateCatalogService{
+		templates: templates,
+		artifacts: artifacts,
+		logger:    logger,
+	}
+}
+
+// Export stores the templates in use as a JSON catalog in the artifact store
+func (s *TemplateCatalogService) Export(ctx context.Context) (*domain.Artifact, *domain.TemplateCatalog, error) {
+	catalog := &domain.TemplateCatalog{
+		Version:    domain.TemplateCatalogVersion,
+		ExportedAt: time.Now(),
+		Templates:  s.templates.Templates(),
+	}
+
+	content, err := json.MarshalIndent(catalog, "", "  ")
+	if err != nil {
+		return nil, nil, fmt.Errorf("falha ao serializar templates: %w", err)
+	}
+
+	key := TemplateCatalogArtifactPrefix + "templates-" + catalog.ExportedAt.Format(BackupFileTimeLayout) + TemplateCatalogExtension
+	artifact, err := s.artifacts.Save(ctx, key, "application/json", bytes.NewReader(content), s.artifacts.Retention())
+	if err != nil {
+		return nil, nil, fmt.Errorf("falha ao gravar templates: %w", err)
+	}
+
+	s.logger.WithFields(map[string]any{
+		"file":      artifact.Key,
+		"templates": len(catalog.Templates),
+	}).Info("Templates exportados")
+
+	return artifact, catalog, nil
+}
+
+// Load reads and validates a catalog from the artifact store and compares it with the templates
+// in use, nothing is changed until Apply
+func (s *TemplateCatalogService) Load(ctx context.Context, name string) (*domain.TemplateCatalog, []domain.TemplateChange, error) {
+	if path.Base(name) != name || path.Ext(name) != TemplateCatalogExtension {
+		return nil, nil, ErrTemplateCatalogPathForbidden
+	}
+
+	reader, _, err := s.artifacts.Open(ctx, TemplateCatalogArtifactPrefix+name)
+	if err != nil {
+		return nil, nil, fmt.Errorf("falha ao ler templates: %w", err)
+	}
+	defer reader.Close()
+
+	content, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, nil, fmt.Errorf("falha ao ler templates: %w", err)
+	}
+
+	var catalog domain.TemplateCatalog
+	if err := json.Unmarshal(content, &catalog); err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", ErrTemplateCatalogInvalid, err)
+	}
+
+	if catalog.Version != domain.TemplateCatalogVersion {
+		return nil, nil, fmt.Errorf("%w: %d", ErrTemplateCatalogVersion, catalog.Version)
+	}
+
+	if err := ValidatePlanTemplates(catalog.Templates); err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", ErrTemplateCatalogInvalid, err)
+	}
+
+	return &catalog, DiffTemplates(s.templates.Templates(), catalog.Templates), nil
+}
+
+// Apply replaces the templates in use with the ones of the catalog
+func (s *TemplateCatalogService) Apply(ctx context.Context, catalog *domain.TemplateCatalog) error {
+	return s.templates.Replace(ctx, catalog.Templates)
+}
+
+// DiffTemplates lists the templates added, removed or changed from current to next, by name
+func DiffTemplates(current, next []domain.ProvisioningTemplate) []domain.TemplateChange {
+	byName := make(map[string]domain.ProvisioningTemplate, len(current))
+	for _, template := range current {
+		byName[template.Name] = template
+	}
+
+	var changes []domain.TemplateChange
+	for _, template := range next {
+		previous, exists := byName[template.Name]
+		delete(byName, template.Name)
+
+		if !exists {
+			changes = append(changes, domain.TemplateChange{Name: template.Name, Kind: domain.TemplateAdded})
+			continue
+		}
+
+		if fields := changedTemplateFields(previous, template); len(fields) > 0 {
+			changes = append(changes, domain.TemplateChange{Name: template.Name, Kind: domain.TemplateChanged, Fields: fields})
+		}
+	}
+
+	for _, template := range current {
+		if _, removed := byName[template.Name]; removed {
+			changes = append(changes, domain.TemplateChange{Name: template.Name, Kind: domain.TemplateRemoved})
+		}
+	}
+
+	return changes
+}
+
+// changedTemplateFields names, by JSON key, the fields that differ between two templates
+func changedTemplateFields(a, b domain.ProvisioningTemplate) []string {
+	var fields []string
+
+	va, vb := reflect.ValueOf(a), reflect.ValueOf(b)
+	for i := range va.NumField() {
+		if reflect.DeepEqual(va.Field(i).Interface(), vb.Field(i).Interface()) {
+			continue
+		}
+
+		name, _, _ := strings.Cut(va.Type().Field(i).Tag.Get("json"), ",")
+		fields = append(fields, name)
+	}
+
+	return fields
+}
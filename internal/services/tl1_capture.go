@@ -0,0 +1,78 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"provisioning-assistant/internal/domain"
+	"provisioning-assistant/internal/unm"
+	"strings"
+)
+
+const (
+	// Tl1DumpCategory keeps the TL1 responses the client could not understand
+	Tl1DumpCategory = "tl1-dumps"
+
+	// Tl1TranscriptCategory keeps the full responses of the console commands
+	Tl1TranscriptCategory = "tl1-transcripts"
+
+	// Tl1CaptureTimeLayout names the captures so they sort by the time they were taken
+	Tl1CaptureTimeLayout = "20060102T150405.000000000"
+)
+
+// Tl1CaptureService keeps the TL1 exchanges worth a second look in the artifact store, where they get the
+// retention and quota of their category instead of growing the state store
+type Tl1CaptureService struct {
+	artifacts *ArtifactService
+	logger    domain.Logger
+}
+
+// NewTl1CaptureService creates a new TL1 capture service over the artifact store
+func NewTl1CaptureService(artifacts *ArtifactService, logger domain.Logger) *Tl1CaptureService {
+	return &Tl1CaptureService{
+		artifacts: artifacts,
+		logger:    logger,
+	}
+}
+
+// SaveDump keeps a response the UNM client could not understand, with the command that got it
+func (s *Tl1CaptureService) SaveDump(ctx context.Context, dump unm.ResponseDump) (*domain.Artifact, error) {
+	verb, _, _ := strings.Cut(dump.Command, ":")
+	key := fmt.Sprintf("%s/%s_%s.txt", Tl1DumpCategory, dump.At.UTC().Format(Tl1CaptureTimeLayout), verb)
+
+	var content strings.Builder
+	fmt.Fprintf(&content, "# endpoint: %s\n", dump.Endpoint)
+	fmt.Fprintf(&content, "# comando: %s\n", dump.Command)
+	fmt.Fprintf(&content, "# erro: %v\n", dump.Err)
+	fmt.Fprintf(&content, "# recebido em: %s\n\n", dump.At.Format("2006-01-02T15:04:05.000Z07:00"))
+	content.WriteString(dump.Response)
+
+	artifact, err := s.artifacts.Save(ctx, key, "text/plain", strings.NewReader(content.String()), s.artifacts.RetentionFor(Tl1DumpCategory))
+	if err != nil {
+		return nil, fmt.Errorf("falha ao gravar resposta TL1 não reconhecida: %w", err)
+	}
+
+	s.logger.WithFields(map[string]any{
+		"key":      artifact.Key,
+		"endpoint": dump.Endpoint,
+	}).Info("Resposta TL1 não reconhecida guardada para análise")
+
+	return artifact, nil
+}
+
+// SaveTranscript keeps the full response of a console command
+func (s *Tl1CaptureService) SaveTranscript(ctx context.Context, record *domain.ConsoleCommand, response string) (*domain.Artifact, error) {
+	key := fmt.Sprintf("%s/%s.txt", Tl1TranscriptCategory, record.ID)
+
+	var content strings.Builder
+	fmt.Fprintf(&content, "# endpoint: %s\n", record.Endpoint)
+	fmt.Fprintf(&content, "# comando: %s\n", record.Command)
+	fmt.Fprintf(&content, "# operador: %s (%d)\n", record.UserName, record.UserID)
+	fmt.Fprintf(&content, "# enviado em: %s\n\n", record.CreatedAt.Format("2006-01-02T15:04:05.000Z07:00"))
+	content.WriteString(response)
+
+	artifact, err := s.artifacts.Save(ctx, key, "text/plain", strings.NewReader(content.String()), s.artifacts.RetentionFor(Tl1TranscriptCategory))
+	if err != nil {
+		return nil, fmt.Errorf("falha ao gravar transcrição do console TL1: %w", err)
+	}
+	return artifact, nil
+}
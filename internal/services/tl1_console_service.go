@@ -0,0 +1,188 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"provisioning-assistant/internal/domain"
+	"provisioning-assistant/internal/unm"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	consoleNamespace = "tl1_console"
+
+	// ConsoleInlineResponse is how much of a console response is kept in its record, the full response
+	// going to the artifact store
+	ConsoleInlineResponse = 4 << 10
+)
+
+var ErrConsoleEndpointUnknown = errors.New("endpoint do UNM desconhecido")
+
+type Tl1ConsoleService struct {
+	endpoints   map[string]*unm.UNMClient
+	operators   []int64
+	verbs       []string
+	permissions *Tl1PermissionService
+	captures    *Tl1CaptureService
+	repository  domain.StateRepository
+	logger      domain.Logger
+}
+
+// NewTl1ConsoleService creates the raw TL1 console, restricted to the operator Telegram IDs and the allowed verbs
+func NewTl1ConsoleService(
+	endpoints map[string]*unm.UNMClient,
+	operators []int64,
+	verbs []string,
+	permissions *Tl1PermissionService,
+	captures *Tl1CaptureService,
+	repository domain.StateRepository,
+	logger domain.Logger,
+) *Tl1ConsoleService {
+	if len(verbs) == 0 {
+		verbs = unm.DefaultConsoleVerbs
+	}
+
+	return &Tl1ConsoleService{
+		endpoints:   endpoints,
+		operators:   operators,
+		verbs:       verbs,
+		permissions: permissions,
+		captures:    captures,
+		repository:  repository,
+		logger:      logger,
+	}
+}
+
+// IsOperator reports whether the user may open the console, nobody may when no operator is configured
+func (s *Tl1ConsoleService) IsOperator(userID int64) bool {
+	return slices.Contains(s.operators, userID)
+}
+
+// Endpoints returns the names of the UNM endpoints reachable from the console, sorted
+func (s *Tl1ConsoleService) Endpoints() []string {
+	return slices.Sorted(maps.Keys(s.endpoints))
+}
+
+// HasEndpoint reports whether the endpoint name is known
+func (s *Tl1ConsoleService) HasEndpoint(name string) bool {
+	_, exists := s.endpoints[name]
+	return exists
+}
+
+// Verbs returns the TL1 verbs allowed in the console
+func (s *Tl1ConsoleService) Verbs() []string {
+	return s.verbs
+}
+
+// Validate checks a raw command against the allowlist and returns the normalized command
+func (s *Tl1ConsoleService) Validate(input string) (string, error) {
+	return unm.ParseConsoleCommand(input, s.verbs)
+}
+
+// Execute sends a validated command to the endpoint and records it, successful or not. The role of the
+// context must be granted the console and the operation class of the command.
+func (s *Tl1ConsoleService) Execute(ctx context.Context, session *domain.Session, endpoint, command string) (*domain.ConsoleCommand, error) {
+	client, exists := s.endpoints[endpoint]
+	if !exists {
+		return nil, fmt.Errorf("%w: %s", ErrConsoleEndpointUnknown, endpoint)
+	}
+
+	if err := s.permissions.Authorize(ctx, domain.Tl1OperationConsole, CommandOperation(command)); err != nil {
+		return nil, err
+	}
+
+	approvalID, _ := unm.ApprovalFrom(ctx)
+	record := &domain.ConsoleCommand{
+		ID:         time.Now().UTC().Format("20060102T150405.000000000"),
+		UserID:     session.UserID,
+		UserName:   session.UserName,
+		TaxID:      session.UserTaxID,
+		Endpoint:   endpoint,
+		Command:    command,
+		Radius:     unm.ClassifyCommand(command).String(),
+		ApprovalID: approvalID,
+		CreatedAt:  time.Now(),
+	}
+
+	log := s.logger.WithFields(map[string]any{
+		"console_id":  record.ID,
+		"user_id":     record.UserID,
+		"endpoint":    endpoint,
+		"command":     command,
+		"approval_id": approvalID,
+	})
+	log.Warn("Comando TL1 enviado pelo console")
+
+	started := time.Now()
+	response, err := client.Execute(ctx, command)
+	record.Duration = time.Since(started)
+	record.Response = response
+	if err != nil {
+		record.Error = err.Error()
+		log.WithError(err).Warn("Comando TL1 do console falhou")
+	}
+
+	s.keepTranscript(ctx, record)
+	s.save(ctx, record)
+
+	return record, err
+}
+
+// keepTranscript moves a response too long for the state store to the artifact store, the record keeping
+// its beginning. The full response stays in the record when it cannot be stored.
+func (s *Tl1ConsoleService) keepTranscript(ctx context.Context, record *domain.ConsoleCommand) {
+	if len(record.Response) <= ConsoleInlineResponse {
+		return
+	}
+
+	artifact, err := s.captures.SaveTranscript(ctx, record, record.Response)
+	if err != nil {
+		s.logger.WithError(err).WithField("console_id", record.ID).Warn("Falha ao guardar transcrição do console TL1")
+		return
+	}
+
+	record.TranscriptKey = artifact.Key
+	record.Response = strings.ToValidUTF8(record.Response[:ConsoleInlineResponse], "")
+}
+
+// List returns the recorded console commands, newest first
+func (s *Tl1ConsoleService) List(ctx context.Context) ([]*domain.ConsoleCommand, error) {
+	values, err := s.repository.List(ctx, consoleNamespace)
+	if err != nil {
+		return nil, err
+	}
+
+	records := make([]*domain.ConsoleCommand, 0, len(values))
+	for _, value := range values {
+		var record domain.ConsoleCommand
+		if err := json.Unmarshal([]byte(value), &record); err != nil {
+			continue
+		}
+		records = append(records, &record)
+	}
+
+	sort.Slice(records, func(i, j int) bool {
+		return records[i].CreatedAt.After(records[j].CreatedAt)
+	})
+
+	return records, nil
+}
+
+// save stores the console command in the audit trail, failures are only logged so the answer still reaches the operator
+func (s *Tl1ConsoleService) save(ctx context.Context, record *domain.ConsoleCommand) {
+	data, err := json.Marshal(record)
+	if err != nil {
+		s.logger.WithError(err).Error("Falha ao serializar comando do console TL1")
+		return
+	}
+
+	if err := s.repository.Set(ctx, consoleNamespace, record.ID, string(data)); err != nil {
+		s.logger.WithError(err).WithField("console_id", record.ID).Error("Falha ao registrar comando do console TL1")
+	}
+}
@@ -0,0 +1,98 @@
+package services
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"provisioning-assistant/internal/domain"
+	"provisioning-assistant/internal/unm"
+	"slices"
+	"strings"
+)
+
+var ErrTl1OperationDenied = errors.New("operação TL1 não permitida")
+
+// Tl1PermissionPolicy maps each role to the TL1 operation classes it may run, roles left out keep the defaults
+type Tl1PermissionPolicy map[domain.Role][]domain.Tl1Operation
+
+// DefaultTl1PermissionPolicy lets technicians query and provision, supervisors also delete and admins
+// also use the raw console. Scheduled jobs query and delete, e.g. the ONUs of cancelled contracts.
+func DefaultTl1PermissionPolicy() Tl1PermissionPolicy {
+	return Tl1PermissionPolicy{
+		domain.RoleTechnician: {domain.Tl1OperationQuery, domain.Tl1OperationProvision},
+		domain.RoleSupervisor: {domain.Tl1OperationQuery, domain.Tl1OperationProvision, domain.Tl1OperationDelete},
+		domain.RoleAdmin:      slices.Clone(domain.Tl1Operations),
+		domain.RoleSystem:     {domain.Tl1OperationQuery, domain.Tl1OperationDelete},
+	}
+}
+
+// ParseTl1Operations reads a list of operation class names such as "query,provision"
+func ParseTl1Operations(values []string) ([]domain.Tl1Operation, error) {
+	operations := make([]domain.Tl1Operation, 0, len(values))
+	for _, value := range values {
+		operation := domain.Tl1Operation(strings.ToLower(strings.TrimSpace(value)))
+		if !slices.Contains(domain.Tl1Operations, operation) {
+			return nil, fmt.Errorf("operação TL1 desconhecida: %s (use query, provision, delete ou console)", value)
+		}
+		operations = append(operations, operation)
+	}
+	return operations, nil
+}
+
+type Tl1PermissionService struct {
+	policy Tl1PermissionPolicy
+	logger domain.Logger
+}
+
+// NewTl1PermissionService creates the check of the TL1 operations against the role carried by the context
+func NewTl1PermissionService(policy Tl1PermissionPolicy, logger domain.Logger) *Tl1PermissionService {
+	merged := DefaultTl1PermissionPolicy()
+	for role, operations := range policy {
+		merged[role] = operations
+	}
+
+	return &Tl1PermissionService{
+		policy: merged,
+		logger: logger,
+	}
+}
+
+// Allows reports whether the role may run the operation class. Operations without a role come from the
+// system and may only query.
+func (s *Tl1PermissionService) Allows(role domain.Role, operation domain.Tl1Operation) bool {
+	if role == "" {
+		return operation == domain.Tl1OperationQuery
+	}
+	return slices.Contains(s.policy[role], operation)
+}
+
+// Authorize checks the role of the context may run every given operation class
+func (s *Tl1PermissionService) Authorize(ctx context.Context, operations ...domain.Tl1Operation) error {
+	role := domain.RoleFrom(ctx)
+	for _, operation := range operations {
+		if s.Allows(role, operation) {
+			continue
+		}
+
+		s.logger.WithFields(map[string]any{
+			"role":      role,
+			"operation": operation,
+		}).Warn("Operação TL1 bloqueada para o perfil")
+		return fmt.Errorf("%w: %s para o perfil %s", ErrTl1OperationDenied, operation, cmp.Or(string(role), "sistema"))
+	}
+	return nil
+}
+
+// CommandOperation returns the operation class of a raw TL1 command
+func CommandOperation(command string) domain.Tl1Operation {
+	verb, _, _ := strings.Cut(strings.ToUpper(strings.TrimSpace(command)), ":")
+	switch {
+	case unm.ClassifyCommand(command) == unm.BlastRadiusNone:
+		return domain.Tl1OperationQuery
+	case strings.HasPrefix(verb, "DEL-"):
+		return domain.Tl1OperationDelete
+	default:
+		return domain.Tl1OperationProvision
+	}
+}
@@ -0,0 +1,152 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"provisioning-assistant/internal/domain"
+	"strings"
+	"time"
+)
+
+const (
+	TokenPrefix      = "pa_"
+	TokenSecretBytes = 32
+	TokenIDBytes     = 4
+)
+
+var (
+	ErrInvalidToken = errors.New("token inválido")
+	ErrRevokedToken = errors.New("token revogado")
+)
+
+type TokenService struct {
+	repository domain.TokenRepository
+	logger     domain.Logger
+}
+
+// NewTokenService creates a new service-account token service instance
+func NewTokenService(repository domain.TokenRepository, logger domain.Logger) *TokenService {
+	return &TokenService{
+		repository: repository,
+		logger:     logger,
+	}
+}
+
+// Issue creates a new token and returns its plaintext secret, which is never stored
+func (s *TokenService) Issue(ctx context.Context, name string, scopes []domain.Scope, createdBy int64) (*domain.ServiceAccountToken, string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, "", errors.New("nome do token é obrigatório")
+	}
+
+	if len(scopes) == 0 {
+		return nil, "", errors.New("ao menos um escopo é obrigatório")
+	}
+
+	for _, scope := range scopes {
+		if !scope.IsValid() {
+			return nil, "", fmt.Errorf("escopo desconhecido: %s", scope)
+		}
+	}
+
+	id, err := randomHex(TokenIDBytes)
+	if err != nil {
+		return nil, "", fmt.Errorf("falha ao gerar identificador do token: %w", err)
+	}
+
+	secret, err := randomHex(TokenSecretBytes)
+	if err != nil {
+		return nil, "", fmt.Errorf("falha ao gerar segredo do token: %w", err)
+	}
+
+	plaintext := TokenPrefix + secret
+	token := &domain.ServiceAccountToken{
+		ID:        id,
+		Name:      name,
+		Scopes:    scopes,
+		TokenHash: hashToken(plaintext),
+		CreatedBy: createdBy,
+		CreatedAt: time.Now(),
+	}
+
+	if err := s.repository.Save(ctx, token); err != nil {
+		return nil, "", fmt.Errorf("falha ao salvar token: %w", err)
+	}
+
+	s.logger.WithFields(map[string]any{
+		"token_id":   token.ID,
+		"name":       token.Name,
+		"scopes":     token.Scopes,
+		"created_by": createdBy,
+	}).Info("Token de conta de serviço emitido")
+
+	return token, plaintext, nil
+}
+
+// Verify validates a plaintext token and checks it grants the required scope
+func (s *TokenService) Verify(ctx context.Context, plaintext string, scope domain.Scope) (*domain.ServiceAccountToken, error) {
+	if !strings.HasPrefix(plaintext, TokenPrefix) {
+		return nil, ErrInvalidToken
+	}
+
+	token, err := s.repository.FindByHash(ctx, hashToken(plaintext))
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	if token.IsRevoked() {
+		return nil, ErrRevokedToken
+	}
+
+	if !token.HasScope(scope) {
+		return nil, fmt.Errorf("token sem o escopo %s", scope)
+	}
+
+	return token, nil
+}
+
+// Revoke disables a token immediately
+func (s *TokenService) Revoke(ctx context.Context, id string) error {
+	token, err := s.repository.FindByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if token.IsRevoked() {
+		return nil
+	}
+
+	now := time.Now()
+	token.RevokedAt = &now
+
+	if err := s.repository.Save(ctx, token); err != nil {
+		return fmt.Errorf("falha ao revogar token: %w", err)
+	}
+
+	s.logger.WithField("token_id", id).Info("Token de conta de serviço revogado")
+	return nil
+}
+
+// List returns all issued tokens
+func (s *TokenService) List(ctx context.Context) ([]*domain.ServiceAccountToken, error) {
+	return s.repository.List(ctx)
+}
+
+// hashToken returns the hex encoded SHA-256 of a plaintext token
+func hashToken(plaintext string) string {
+	sum := sha256.Sum256([]byte(plaintext))
+	return hex.EncodeToString(sum[:])
+}
+
+// randomHex returns n random bytes hex encoded
+func randomHex(n int) (string, error) {
+	buffer := make([]byte, n)
+	if _, err := rand.Read(buffer); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buffer), nil
+}
@@ -0,0 +1,71 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"provisioning-assistant/internal/domain"
+	"slices"
+	"strconv"
+)
+
+const trainingNamespace = "training"
+
+type TrainingService struct {
+	repository domain.StateRepository
+	logger     domain.Logger
+}
+
+// NewTrainingService creates the registry of technicians switched to the training sandbox
+func NewTrainingService(repository domain.StateRepository, logger domain.Logger) *TrainingService {
+	return &TrainingService{
+		repository: repository,
+		logger:     logger,
+	}
+}
+
+// IsEnabled reports whether the user's operations run in the training sandbox
+func (s *TrainingService) IsEnabled(ctx context.Context, userID int64) bool {
+	value, err := s.repository.Get(ctx, trainingNamespace, strconv.FormatInt(userID, 10))
+	if err != nil {
+		return false
+	}
+
+	enabled, _ := strconv.ParseBool(value)
+	return enabled
+}
+
+// Set switches the user into or out of the training sandbox
+func (s *TrainingService) Set(ctx context.Context, userID int64, enabled bool) error {
+	if err := s.repository.Set(ctx, trainingNamespace, strconv.FormatInt(userID, 10), strconv.FormatBool(enabled)); err != nil {
+		return fmt.Errorf("falha ao salvar modo treinamento do usuário %d: %w", userID, err)
+	}
+
+	s.logger.WithFields(map[string]any{
+		"user_id": userID,
+		"enabled": enabled,
+	}).Info("Modo treinamento atualizado")
+
+	return nil
+}
+
+// List returns the users currently in the training sandbox, sorted by ID
+func (s *TrainingService) List(ctx context.Context) ([]int64, error) {
+	values, err := s.repository.List(ctx, trainingNamespace)
+	if err != nil {
+		return nil, err
+	}
+
+	var users []int64
+	for key, value := range values {
+		userID, err := strconv.ParseInt(key, 10, 64)
+		if err != nil {
+			continue
+		}
+		if enabled, _ := strconv.ParseBool(value); enabled {
+			users = append(users, userID)
+		}
+	}
+
+	slices.Sort(users)
+	return users, nil
+}
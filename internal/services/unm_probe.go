@@ -0,0 +1,243 @@
+package services
+
+import (
+	"context"
+	"maps"
+	"provisioning-assistant/internal/clock"
+	"provisioning-assistant/internal/domain"
+	"slices"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultProbeFailures is how many probes in a row must fail before an endpoint is declared down
+	DefaultProbeFailures = 2
+
+	// UnmProbeTimeout bounds each probe, a read slower than this is as bad as no answer for a technician
+	UnmProbeTimeout = 10 * time.Second
+
+	// probeWindow is how many probes of an endpoint its availability and latency are measured over
+	probeWindow = 60
+)
+
+// UnmProbePolicy configures the synthetic probe of the UNM endpoints
+type UnmProbePolicy struct {
+	Enabled  bool
+	Failures int
+}
+
+// ProbeTransition reports a change of the state of an endpoint caused by a probe
+type ProbeTransition int
+
+const (
+	ProbeUnchanged ProbeTransition = iota
+	ProbeDown
+	ProbeRecovered
+)
+
+// ProbeResult is a probe of an endpoint with the state changes it caused
+type ProbeResult struct {
+	Endpoint   string
+	Latency    time.Duration
+	Err        error
+	Transition ProbeTransition
+	Circuit    CircuitTransition
+	Failures   int
+	DownFor    time.Duration
+}
+
+// ProbeStatus is the availability and latency of an endpoint over the latest probes
+type ProbeStatus struct {
+	Endpoint     string
+	Up           bool
+	Since        time.Time
+	LastProbe    time.Time
+	LastError    string
+	Probes       int
+	Availability float64
+	P95          time.Duration
+}
+
+type probeState struct {
+	latency   *LatencyTracker
+	outcomes  []bool
+	failures  int
+	down      bool
+	since     time.Time
+	lastProbe time.Time
+	lastError string
+}
+
+// UnmProbeService issues a harmless read to each UNM endpoint on a schedule, so an outage is found by the
+// probe before a technician finds it in the middle of an activation
+type UnmProbeService struct {
+	endpoints map[string]DependencyProbe
+	circuit   *ProvisioningCircuitService
+	policy    UnmProbePolicy
+	clock     clock.Clock
+	logger    domain.Logger
+
+	mu     sync.Mutex
+	states map[string]*probeState
+}
+
+// NewUnmProbeService creates a new prober over the named endpoints, their failures feeding the provisioning
+// circuit. Zero values of the policy fall back to the defaults.
+func NewUnmProbeService(
+	endpoints map[string]DependencyProbe,
+	circuit *ProvisioningCircuitService,
+	policy UnmProbePolicy,
+	clock clock.Clock,
+	logger domain.Logger,
+) *UnmProbeService {
+	if policy.Failures <= 0 {
+		policy.Failures = DefaultProbeFailures
+	}
+
+	states := make(map[string]*probeState, len(endpoints))
+	for name := range endpoints {
+		states[name] = &probeState{latency: NewLatencyTracker(probeWindow)}
+	}
+
+	return &UnmProbeService{
+		endpoints: endpoints,
+		circuit:   circuit,
+		policy:    policy,
+		clock:     clock,
+		logger:    logger,
+		states:    states,
+	}
+}
+
+// IsEnabled reports whether the probe is on and has any endpoint to probe
+func (s *UnmProbeService) IsEnabled() bool {
+	return s.policy.Enabled && len(s.endpoints) > 0
+}
+
+// Probe reads every endpoint once and returns how each answered. A failed probe counts as a failed
+// provisioning for the circuit, the successful ones are left out so a minute of reads does not outweigh
+// the activations of the window.
+func (s *UnmProbeService) Probe(ctx context.Context) []ProbeResult {
+	results := make([]ProbeResult, 0, len(s.endpoints))
+
+	for _, name := range slices.Sorted(maps.Keys(s.endpoints)) {
+		probeCtx, cancel := context.WithTimeout(ctx, UnmProbeTimeout)
+		started := s.clock.Now()
+		err := s.endpoints[name](probeCtx)
+		latency := s.clock.Since(started)
+		cancel()
+
+		// The probe interrupted by the shutdown says nothing about the endpoint
+		if ctx.Err() != nil {
+			break
+		}
+
+		result := s.record(name, latency, err)
+		if err != nil && s.circuit != nil {
+			result.Circuit = s.circuit.Record(false)
+		}
+		results = append(results, result)
+	}
+
+	return results
+}
+
+// record stores the outcome of a probe and tells whether it changed the state of the endpoint
+func (s *UnmProbeService) record(name string, latency time.Duration, err error) ProbeResult {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	state := s.states[name]
+	now := s.clock.Now()
+	result := ProbeResult{Endpoint: name, Latency: latency, Err: err}
+	log := s.logger.WithFields(map[string]any{
+		"endpoint": name,
+		"latency":  latency.String(),
+	})
+
+	state.lastProbe = now
+	state.outcomes = append(state.outcomes, err == nil)
+	if len(state.outcomes) > probeWindow {
+		state.outcomes = state.outcomes[len(state.outcomes)-probeWindow:]
+	}
+
+	if err != nil {
+		state.failures++
+		state.lastError = err.Error()
+		log.WithError(err).Warn("Sonda TL1 sem resposta do UNM")
+
+		if !state.down && state.failures >= s.policy.Failures {
+			state.down, state.since = true, now
+			result.Transition = ProbeDown
+			result.Failures = state.failures
+			log.WithField("failures", state.failures).Error("Endpoint do UNM fora do ar segundo a sonda TL1")
+		}
+		return result
+	}
+
+	state.latency.Observe(latency)
+	state.failures = 0
+	state.lastError = ""
+
+	if state.down {
+		result.Transition = ProbeRecovered
+		result.DownFor = now.Sub(state.since)
+		state.down, state.since = false, time.Time{}
+		log.WithField("down_for", result.DownFor.String()).Info("Endpoint do UNM voltou a responder à sonda TL1")
+	}
+
+	return result
+}
+
+// Status returns the latest readings of every endpoint, sorted by name
+func (s *UnmProbeService) Status() []ProbeStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	statuses := make([]ProbeStatus, 0, len(s.states))
+	for _, name := range slices.Sorted(maps.Keys(s.states)) {
+		state := s.states[name]
+		statuses = append(statuses, ProbeStatus{
+			Endpoint:     name,
+			Up:           !state.down,
+			Since:        state.since,
+			LastProbe:    state.lastProbe,
+			LastError:    state.lastError,
+			Probes:       len(state.outcomes),
+			Availability: availability(state.outcomes),
+			P95:          state.latency.Percentile(0.95),
+		})
+	}
+
+	return statuses
+}
+
+// Availability returns the lowest availability and the highest p95 among the probed endpoints, full
+// availability before any probe
+func (s *UnmProbeService) Availability() (float64, time.Duration) {
+	lowest, slowest := 1.0, time.Duration(0)
+	for _, status := range s.Status() {
+		if status.Probes == 0 {
+			continue
+		}
+		lowest = min(lowest, status.Availability)
+		slowest = max(slowest, status.P95)
+	}
+	return lowest, slowest
+}
+
+// availability is the ratio of answered probes, full without probes
+func availability(outcomes []bool) float64 {
+	if len(outcomes) == 0 {
+		return 1
+	}
+
+	answered := 0
+	for _, ok := range outcomes {
+		if ok {
+			answered++
+		}
+	}
+	return float64(answered) / float64(len(outcomes))
+}
@@ -7,13 +7,16 @@ import (
 )
 
 type UserService struct {
-	authorizedCPF string
+	authorizedUsers map[string]domain.User
 }
 
-// NewUserService creates a new user service instance with test authorization
+// NewUserService creates a new user service instance with test authorization, an admin and a technician
 func NewUserService() *UserService {
 	return &UserService{
-		authorizedCPF: "12345678901",
+		authorizedUsers: map[string]domain.User{
+			"12345678901": {ID: 1, Name: "Raykavin Meireles", Role: domain.RoleAdmin},
+			"10987654321": {ID: 2, Name: "Técnico de Campo", Role: domain.RoleTechnician},
+		},
 	}
 }
 
@@ -21,15 +24,13 @@ func NewUserService() *UserService {
 func (s *UserService) ValidateTaxID(taxID string) *domain.User {
 	taxID = strings.TrimSpace(taxID)
 
-	if taxID == s.authorizedCPF {
-		return &domain.User{
-			ID:        1,
-			CPF:       taxID,
-			Name:      "Raykavin Meireles",
-			IsValid:   true,
-			CreatedAt: time.Now(),
-		}
+	user, exists := s.authorizedUsers[taxID]
+	if !exists {
+		return nil
 	}
 
-	return nil
+	user.CPF = taxID
+	user.IsValid = true
+	user.CreatedAt = time.Now()
+	return &user
 }
@@ -0,0 +1,95 @@
+package services
+
+import (
+	"provisioning-assistant/internal/domain"
+	"provisioning-assistant/internal/unm"
+	"sort"
+)
+
+// Channels suggested to the technician: the non-overlapping 2.4 GHz ones and the 5 GHz ones free of DFS
+var wifiCandidateChannels = map[string][]int{
+	"2.4G": {1, 6, 11},
+	"5G":   {36, 40, 44, 48, 149, 153, 157, 161},
+}
+
+// wifiBandOrder lists the bands in the order they are reported
+var wifiBandOrder = []string{"2.4G", "5G"}
+
+// AnalyzeWifiScan groups the neighbor networks by band and channel and picks the least interfered candidate channel
+func AnalyzeWifiScan(neighbors []unm.WifiNeighbor) *domain.WifiScanReport {
+	report := &domain.WifiScanReport{Networks: len(neighbors)}
+
+	for _, band := range wifiBandOrder {
+		usage := map[int]*domain.WifiChannelUsage{}
+		var bandNeighbors []unm.WifiNeighbor
+
+		for _, neighbor := range neighbors {
+			if neighbor.Band != band {
+				continue
+			}
+			bandNeighbors = append(bandNeighbors, neighbor)
+
+			channel, exists := usage[neighbor.Channel]
+			if !exists {
+				channel = &domain.WifiChannelUsage{Channel: neighbor.Channel, Strongest: neighbor.RSSI}
+				usage[neighbor.Channel] = channel
+			}
+			channel.Networks++
+			channel.Strongest = max(channel.Strongest, neighbor.RSSI)
+		}
+
+		bandReport := domain.WifiBandReport{
+			Band:        band,
+			Recommended: recommendWifiChannel(band, bandNeighbors),
+		}
+		for _, channel := range usage {
+			bandReport.Channels = append(bandReport.Channels, *channel)
+		}
+		sort.Slice(bandReport.Channels, func(i, j int) bool {
+			return bandReport.Channels[i].Channel < bandReport.Channels[j].Channel
+		})
+
+		report.Bands = append(report.Bands, bandReport)
+	}
+
+	return report
+}
+
+// recommendWifiChannel returns the candidate channel with the lowest interference,
+// weighting each neighbor by its signal strength and by how much its channel overlaps
+func recommendWifiChannel(band string, neighbors []unm.WifiNeighbor) int {
+	candidates := wifiCandidateChannels[band]
+	best, bestScore := candidates[0], -1
+
+	for _, candidate := range candidates {
+		score := 0
+		for _, neighbor := range neighbors {
+			score += wifiOverlap(band, candidate, neighbor.Channel) * wifiStrength(neighbor.RSSI)
+		}
+
+		if bestScore < 0 || score < bestScore {
+			best, bestScore = candidate, score
+		}
+	}
+
+	return best
+}
+
+// wifiOverlap rates how much two channels interfere, 2.4 GHz channels spread over four neighbors on each side
+func wifiOverlap(band string, a, b int) int {
+	distance := max(a-b, b-a)
+
+	if band == "2.4G" {
+		return max(5-distance, 0)
+	}
+
+	if distance == 0 {
+		return 5
+	}
+	return 0
+}
+
+// wifiStrength turns an RSSI in dBm into a positive weight, networks below -90 dBm barely count
+func wifiStrength(rssi int) int {
+	return max(rssi+100, 1)
+}
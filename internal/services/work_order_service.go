@@ -0,0 +1,152 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"provisioning-assistant/internal/clock"
+	"provisioning-assistant/internal/domain"
+	"provisioning-assistant/internal/domain/dto"
+	"time"
+)
+
+const (
+	// DefaultWorkOrderLookback is how far back the assignments are read, an assignment older than that is
+	// not greeted anymore
+	DefaultWorkOrderLookback = 12 * time.Hour
+
+	workOrderNamespace  = "work_orders"
+	workOrderGreetedKey = "greeted"
+)
+
+// WorkOrderPolicy configures the greeting of the technicians assigned to a protocol in the ERP
+type WorkOrderPolicy struct {
+	Enabled  bool
+	Lookback time.Duration
+}
+
+// WorkOrderGreeting is a protocol assigned to a technician who has a bot binding and was not greeted yet
+type WorkOrderGreeting struct {
+	WorkOrder *dto.WorkOrder
+	Binding   *domain.Binding
+}
+
+type WorkOrderService struct {
+	erpService     *ErpService
+	bindingService *BindingService
+	repository     domain.StateRepository
+	policy         WorkOrderPolicy
+	clock          clock.Clock
+	logger         domain.Logger
+}
+
+// NewWorkOrderService creates the watcher of the field-service assignments of the ERP
+func NewWorkOrderService(
+	erpService *ErpService,
+	bindingService *BindingService,
+	repository domain.StateRepository,
+	policy WorkOrderPolicy,
+	clock clock.Clock,
+	logger domain.Logger,
+) *WorkOrderService {
+	if policy.Lookback <= 0 {
+		policy.Lookback = DefaultWorkOrderLookback
+	}
+
+	return &WorkOrderService{
+		erpService:     erpService,
+		bindingService: bindingService,
+		repository:     repository,
+		policy:         policy,
+		clock:          clock,
+		logger:         logger,
+	}
+}
+
+// IsEnabled reports whether the assigned technicians are greeted
+func (s *WorkOrderService) IsEnabled() bool {
+	return s.policy.Enabled
+}
+
+// Pending lists the assignments of the lookback window not greeted yet whose technician has a binding
+func (s *WorkOrderService) Pending(ctx context.Context) ([]WorkOrderGreeting, error) {
+	workOrders, err := s.erpService.ListWorkOrders(ctx, s.clock.Now().Add(-s.policy.Lookback))
+	if err != nil {
+		return nil, err
+	}
+
+	greeted := s.greeted(ctx)
+
+	var pending []WorkOrderGreeting
+	for _, workOrder := range workOrders {
+		if greeting, ok := s.greeting(ctx, greeted, workOrder); ok {
+			pending = append(pending, greeting)
+		}
+	}
+
+	return pending, nil
+}
+
+// Greeting returns the greeting of a single assignment, as pushed by the ERP webhook, unless it was already
+// greeted or its technician has no binding
+func (s *WorkOrderService) Greeting(ctx context.Context, workOrder *dto.WorkOrder) (WorkOrderGreeting, bool) {
+	return s.greeting(ctx, s.greeted(ctx), workOrder)
+}
+
+// greeting pairs an assignment not greeted yet with the binding of its technician
+func (s *WorkOrderService) greeting(ctx context.Context, greeted map[string]time.Time, workOrder *dto.WorkOrder) (WorkOrderGreeting, bool) {
+	if _, done := greeted[workOrderKey(workOrder)]; done || workOrder.Protocol == "" {
+		return WorkOrderGreeting{}, false
+	}
+
+	binding := s.bindingService.FindByTaxID(ctx, workOrder.TechnicianTaxID)
+	if binding == nil || binding.IsBlocked() {
+		return WorkOrderGreeting{}, false
+	}
+
+	return WorkOrderGreeting{WorkOrder: workOrder, Binding: binding}, true
+}
+
+// MarkGreeted records the greeting of an assignment, forgetting the ones older than the lookback window
+func (s *WorkOrderService) MarkGreeted(ctx context.Context, workOrder *dto.WorkOrder) error {
+	now := s.clock.Now()
+
+	greeted := s.greeted(ctx)
+	maps.DeleteFunc(greeted, func(_ string, at time.Time) bool {
+		return now.Sub(at) > s.policy.Lookback
+	})
+	greeted[workOrderKey(workOrder)] = now
+
+	content, err := json.Marshal(greeted)
+	if err != nil {
+		return err
+	}
+
+	if err := s.repository.Set(ctx, workOrderNamespace, workOrderGreetedKey, string(content)); err != nil {
+		return fmt.Errorf("falha ao salvar ordens de serviço notificadas: %w", err)
+	}
+	return nil
+}
+
+// greeted loads the assignments already greeted with when, a missing or unreadable record greets them again
+func (s *WorkOrderService) greeted(ctx context.Context) map[string]time.Time {
+	greeted := make(map[string]time.Time)
+
+	value, err := s.repository.Get(ctx, workOrderNamespace, workOrderGreetedKey)
+	if err != nil {
+		return greeted
+	}
+
+	if err := json.Unmarshal([]byte(value), &greeted); err != nil {
+		s.logger.WithError(err).Warn("Registro de ordens de serviço notificadas inválido ignorado")
+		return make(map[string]time.Time)
+	}
+	return greeted
+}
+
+// workOrderKey identifies an assignment to a technician, a reassignment greets the new one. The webhook of the
+// ERP does not carry the assignment ID, so the protocol and the technician are enough for both to agree.
+func workOrderKey(workOrder *dto.WorkOrder) string {
+	return workOrder.Protocol + ":" + workOrder.TechnicianTaxID
+}
@@ -0,0 +1,60 @@
+package telegram
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	// callbackDedupeTTL covers the window Telegram re-delivers a callback whose answer was slow
+	callbackDedupeTTL = 2 * time.Minute
+
+	// callbackDedupeMaxEntries bounds the cache should a burst of callbacks outpace the expiry
+	callbackDedupeMaxEntries = 10_000
+)
+
+// callbackCache remembers the IDs of recently handled callback queries, so a re-delivered
+// callback is dropped instead of running the same button press twice
+type callbackCache struct {
+	ttl  time.Duration
+	mu   sync.Mutex
+	seen map[string]time.Time
+}
+
+// newCallbackCache creates an empty cache keeping IDs for the given time
+func newCallbackCache(ttl time.Duration) *callbackCache {
+	return &callbackCache{
+		ttl:  ttl,
+		seen: make(map[string]time.Time),
+	}
+}
+
+// Seen records a callback ID and reports whether it was already handled within the TTL
+func (c *callbackCache) Seen(id string, now time.Time) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if handledAt, exists := c.seen[id]; exists && now.Sub(handledAt) < c.ttl {
+		return true
+	}
+
+	if len(c.seen) >= callbackDedupeMaxEntries {
+		c.sweep(now)
+	}
+
+	c.seen[id] = now
+	return false
+}
+
+// sweep drops the expired IDs, and everything when they are all recent, keeping the cache bounded
+func (c *callbackCache) sweep(now time.Time) {
+	for id, handledAt := range c.seen {
+		if now.Sub(handledAt) >= c.ttl {
+			delete(c.seen, id)
+		}
+	}
+
+	if len(c.seen) >= callbackDedupeMaxEntries {
+		clear(c.seen)
+	}
+}
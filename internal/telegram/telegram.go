@@ -1,39 +1,77 @@
 package telegram
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"provisioning-assistant/internal/domain"
+	"runtime/debug"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/go-telegram/bot"
 	"github.com/go-telegram/bot/models"
 	"github.com/gookit/event"
 )
 
+const (
+	offsetNamespace = "offsets"
+	offsetKey       = "telegram_updates"
+
+	// drainPollInterval is how often a draining adapter checks for updates still being handled
+	drainPollInterval = 200 * time.Millisecond
+)
+
 type Telegram struct {
 	bot          *bot.Bot
 	eventManager *event.Manager
+	offsets      domain.StateRepository
+	lastUpdateID atomic.Int64
+	inFlight     atomic.Int64
+	callbacks    *callbackCache
 	logger       domain.Logger
+
+	// Chats that blocked the bot and groups that became supergroups, so doomed sends are not retried
+	mu         sync.RWMutex
+	blocked    map[int64]bool
+	migrations map[int64]int64
 }
 
-// NewTelegram creates a new Telegram bot adapter with event integration
-func NewTelegram(token string, logger domain.Logger, eventManager *event.Manager) (*Telegram, error) {
+// NewTelegram creates a new Telegram bot adapter with event integration.
+// When offsets is set, the last handled update is stored so a restart resumes after it.
+func NewTelegram(token string, offsets domain.StateRepository, logger domain.Logger, eventManager *event.Manager) (*Telegram, error) {
+	adapter := &Telegram{
+		offsets:      offsets,
+		logger:       logger,
+		eventManager: eventManager,
+		callbacks:    newCallbackCache(callbackDedupeTTL),
+		blocked:      make(map[int64]bool),
+		migrations:   make(map[int64]int64),
+	}
+
 	opts := []bot.Option{
 		bot.WithDefaultHandler(func(ctx context.Context, bot *bot.Bot, update *models.Update) {
 			logger.Warnf("Update não tratado: %+v", update)
 		}),
+		bot.WithErrorsHandler(adapter.handleBotError),
+		bot.WithMiddlewares(adapter.trackInFlight, adapter.recoverPanics),
+	}
+
+	if offsets != nil {
+		if offset := adapter.loadOffset(); offset > 0 {
+			opts = append(opts, bot.WithInitialOffset(offset))
+		}
+		opts = append(opts, bot.WithMiddlewares(adapter.trackOffset))
 	}
 
 	b, err := bot.New(token, opts...)
 	if err != nil {
 		return nil, err
 	}
-
-	adapter := &Telegram{
-		bot:          b,
-		logger:       logger,
-		eventManager: eventManager,
-	}
+	adapter.bot = b
 
 	adapter.registerHandlers()
 	adapter.registerEventListeners()
@@ -48,8 +86,180 @@ func (t *Telegram) Start(ctx context.Context) {
 
 // registerHandlers registers bot handlers for messages and callbacks
 func (t *Telegram) registerHandlers() {
-	t.bot.RegisterHandler(bot.HandlerTypeMessageText, "", bot.MatchTypePrefix, t.handleMessage)
+	// The first matching handler wins, so the catch-all text prefix must come last
+	t.bot.RegisterHandlerMatchFunc(isPhotoMessage, t.handlePhoto)
+	t.bot.RegisterHandlerMatchFunc(isContactMessage, t.handleContact)
+	t.bot.RegisterHandlerMatchFunc(isLocationMessage, t.handleLocation)
+	t.bot.RegisterHandlerMatchFunc(isInlineQuery, t.handleInlineQuery)
+	t.bot.RegisterHandlerMatchFunc(isChatMemberUpdate, t.handleChatMember)
+	t.bot.RegisterHandlerMatchFunc(isMigrationMessage, t.handleMigrationMessage)
 	t.bot.RegisterHandler(bot.HandlerTypeCallbackQueryData, "", bot.MatchTypePrefix, t.handleCallback)
+	t.bot.RegisterHandler(bot.HandlerTypeMessageText, "", bot.MatchTypePrefix, t.handleMessage)
+}
+
+// loadOffset reads the last handled update ID, zero when unknown
+func (t *Telegram) loadOffset() int64 {
+	value, err := t.offsets.Get(context.Background(), offsetNamespace, offsetKey)
+	if err != nil {
+		return 0
+	}
+
+	offset, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		t.logger.WithError(err).Warn("Offset de updates do Telegram inválido, ignorando")
+		return 0
+	}
+
+	t.lastUpdateID.Store(offset)
+	t.logger.WithField("offset", offset).Info("Retomando updates do Telegram a partir do último offset salvo")
+
+	return offset
+}
+
+// trackOffset stores the highest handled update ID after each update
+func (t *Telegram) trackOffset(next bot.HandlerFunc) bot.HandlerFunc {
+	return func(ctx context.Context, b *bot.Bot, update *models.Update) {
+		next(ctx, b, update)
+
+		for {
+			last := t.lastUpdateID.Load()
+			if update.ID <= last {
+				return
+			}
+			if t.lastUpdateID.CompareAndSwap(last, update.ID) {
+				break
+			}
+		}
+
+		if err := t.offsets.Set(ctx, offsetNamespace, offsetKey, strconv.FormatInt(update.ID, 10)); err != nil {
+			t.logger.WithError(err).Warn("Falha ao salvar offset de updates do Telegram")
+		}
+	}
+}
+
+// trackInFlight counts the updates being handled, so a draining process waits for them before stopping
+func (t *Telegram) trackInFlight(next bot.HandlerFunc) bot.HandlerFunc {
+	return func(ctx context.Context, b *bot.Bot, update *models.Update) {
+		t.inFlight.Add(1)
+		defer t.inFlight.Add(-1)
+
+		next(ctx, b, update)
+	}
+}
+
+// waitIdle blocks until no update is being handled or the context ends
+func (t *Telegram) waitIdle(ctx context.Context) error {
+	ticker := time.NewTicker(drainPollInterval)
+	defer ticker.Stop()
+
+	for t.inFlight.Load() > 0 {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+	return nil
+}
+
+// recoverPanics keeps a failing update from taking the process down, reporting it to the error channel
+func (t *Telegram) recoverPanics(next bot.HandlerFunc) bot.HandlerFunc {
+	return func(ctx context.Context, b *bot.Bot, update *models.Update) {
+		defer func() {
+			recovered := recover()
+			if recovered == nil {
+				return
+			}
+
+			err, ok := recovered.(error)
+			if !ok {
+				err = fmt.Errorf("%v", recovered)
+			}
+
+			// Replies to a chat that blocked the bot abort the handler, nothing went wrong
+			if errors.Is(err, domain.ErrChatUnavailable) {
+				t.logger.WithField("update_id", update.ID).Debug("Tratamento interrompido, chat indisponível")
+				return
+			}
+
+			stack := string(debug.Stack())
+			t.logger.WithError(err).WithFields(map[string]any{
+				"update_id": update.ID,
+				"stack":     stack,
+			}).Error("Pânico ao tratar update do Telegram")
+			t.reportError(ctx, "telegram.update", err, stack)
+		}()
+
+		next(ctx, b, update)
+	}
+}
+
+// handleBotError reports the polling and request failures of the Telegram client
+func (t *Telegram) handleBotError(err error) {
+	t.logger.WithError(err).Error("Erro no cliente do Telegram")
+	t.reportError(context.Background(), "telegram.client", err, "")
+}
+
+// dispatch fires an event for the handlers, reporting a listener failure instead of panicking
+func (t *Telegram) dispatch(ctx context.Context, name string, params event.M) {
+	err, _ := t.eventManager.Fire(name, params)
+	if err == nil || errors.Is(err, domain.ErrChatUnavailable) {
+		return
+	}
+
+	t.logger.WithError(err).WithField("event", name).Error("Falha ao tratar evento do Telegram")
+	t.reportError(ctx, name, err, "")
+}
+
+// reportError hands a failure to the error channel
+func (t *Telegram) reportError(ctx context.Context, source string, err error, stack string) {
+	t.eventManager.Fire("app.error", event.M{
+		"ctx":   context.WithoutCancel(ctx),
+		"event": &domain.ErrorEvent{Source: source, Err: err, Stack: stack},
+	})
+}
+
+// isInlineQuery reports whether the update carries an inline query
+func isInlineQuery(update *models.Update) bool {
+	return update.InlineQuery != nil
+}
+
+// isPhotoMessage reports whether the update carries a photo
+func isPhotoMessage(update *models.Update) bool {
+	return update.Message != nil && len(update.Message.Photo) > 0
+}
+
+// isContactMessage reports whether the update carries a shared contact
+func isContactMessage(update *models.Update) bool {
+	return update.Message != nil && update.Message.Contact != nil
+}
+
+// isLocationMessage reports whether the update carries a shared location, live locations included
+func isLocationMessage(update *models.Update) bool {
+	return update.Message != nil && update.Message.Location != nil
+}
+
+// isChatMemberUpdate reports whether the update changes the bot membership in a chat
+func isChatMemberUpdate(update *models.Update) bool {
+	return update.MyChatMember != nil
+}
+
+// isMigrationMessage reports whether the update is the service message of a group becoming a supergroup
+func isMigrationMessage(update *models.Update) bool {
+	return update.Message != nil && update.Message.MigrateToChatID != 0
+}
+
+// userProfile extracts the public profile of the Telegram user
+func userProfile(user *models.User) domain.TelegramProfile {
+	if user == nil {
+		return domain.TelegramProfile{}
+	}
+
+	return domain.TelegramProfile{
+		Username:  user.Username,
+		FirstName: user.FirstName,
+		LastName:  user.LastName,
+	}
 }
 
 // handleMessage processes incoming text messages from users
@@ -61,28 +271,141 @@ func (t *Telegram) handleMessage(ctx context.Context, b *bot.Bot, update *models
 	userID := update.Message.From.ID
 	chatID := update.Message.Chat.ID
 	text := update.Message.Text
+	t.markReachable(chatID)
 	t.logger.Infof("Mensagem recebida do usuário %d: %s", userID, text)
 
 	msgEvent := &domain.MessageEvent{
 		UserID:  userID,
 		ChatID:  chatID,
 		Message: text,
+		Profile: userProfile(update.Message.From),
+	}
+
+	t.dispatch(ctx, "telegram.message.received", event.M{
+		"ctx":   ctx,
+		"event": msgEvent,
+	})
+}
+
+// handlePhoto processes incoming photo messages, keeping the largest available size
+func (t *Telegram) handlePhoto(ctx context.Context, b *bot.Bot, update *models.Update) {
+	if !isPhotoMessage(update) {
+		return
+	}
+
+	userID := update.Message.From.ID
+	chatID := update.Message.Chat.ID
+	photo := update.Message.Photo[len(update.Message.Photo)-1]
+	t.markReachable(chatID)
+	t.logger.Infof("Foto recebida do usuário %d: %s", userID, photo.FileID)
+
+	msgEvent := &domain.MessageEvent{
+		UserID:       userID,
+		ChatID:       chatID,
+		Message:      update.Message.Caption,
+		PhotoFileID:  photo.FileID,
+		PhotoCaption: update.Message.Caption,
+		Profile:      userProfile(update.Message.From),
+	}
+
+	t.dispatch(ctx, "telegram.message.received", event.M{
+		"ctx":   ctx,
+		"event": msgEvent,
+	})
+}
+
+// handleContact processes a shared contact, accepting only the sender's own phone number
+func (t *Telegram) handleContact(ctx context.Context, b *bot.Bot, update *models.Update) {
+	if !isContactMessage(update) {
+		return
+	}
+
+	userID := update.Message.From.ID
+	contact := update.Message.Contact
+	if contact.UserID != userID {
+		t.logger.Warnf("Contato de terceiro ignorado, enviado pelo usuário %d", userID)
+		return
+	}
+
+	t.logger.Infof("Telefone compartilhado pelo usuário %d", userID)
+
+	profile := userProfile(update.Message.From)
+	profile.Phone = contact.PhoneNumber
+
+	msgEvent := &domain.MessageEvent{
+		UserID:  userID,
+		ChatID:  update.Message.Chat.ID,
+		Profile: profile,
+	}
+
+	t.dispatch(ctx, "telegram.message.received", event.M{
+		"ctx":   ctx,
+		"event": msgEvent,
+	})
+}
+
+// handleLocation processes a shared location
+func (t *Telegram) handleLocation(ctx context.Context, b *bot.Bot, update *models.Update) {
+	if !isLocationMessage(update) {
+		return
 	}
 
-	t.eventManager.MustFire("telegram.message.received", event.M{
+	userID := update.Message.From.ID
+	chatID := update.Message.Chat.ID
+	location := update.Message.Location
+	t.markReachable(chatID)
+	t.logger.Infof("Localização compartilhada pelo usuário %d", userID)
+
+	msgEvent := &domain.MessageEvent{
+		UserID:   userID,
+		ChatID:   chatID,
+		Location: &domain.Location{Latitude: location.Latitude, Longitude: location.Longitude},
+		Profile:  userProfile(update.Message.From),
+	}
+
+	t.dispatch(ctx, "telegram.message.received", event.M{
+		"ctx":   ctx,
 		"event": msgEvent,
 	})
 }
 
+// handleInlineQuery processes inline queries typed as @bot <consulta> in any chat
+func (t *Telegram) handleInlineQuery(ctx context.Context, b *bot.Bot, update *models.Update) {
+	if !isInlineQuery(update) {
+		return
+	}
+
+	userID := update.InlineQuery.From.ID
+	t.logger.Infof("Consulta inline recebida do usuário %d: %s", userID, update.InlineQuery.Query)
+
+	queryEvent := &domain.InlineQueryEvent{
+		QueryID: update.InlineQuery.ID,
+		UserID:  userID,
+		Query:   update.InlineQuery.Query,
+	}
+
+	t.dispatch(ctx, "telegram.inline.received", event.M{
+		"ctx":   ctx,
+		"event": queryEvent,
+	})
+}
+
 // handleCallback processes incoming callback queries from inline keyboards
 func (t *Telegram) handleCallback(ctx context.Context, b *bot.Bot, update *models.Update) {
 	if update.CallbackQuery == nil {
 		return
 	}
 
+	// Telegram re-delivers a callback when the answer is slow, the press was already handled
+	if t.callbacks.Seen(update.CallbackQuery.ID, time.Now()) {
+		t.logger.WithField("callback_id", update.CallbackQuery.ID).Debug("Callback repetido descartado")
+		return
+	}
+
 	userID := update.CallbackQuery.From.ID
 	chatID := update.CallbackQuery.Message.Message.Chat.ID
 	data := update.CallbackQuery.Data
+	t.markReachable(chatID)
 
 	_, _ = b.AnswerCallbackQuery(ctx, &bot.AnswerCallbackQueryParams{
 		CallbackQueryID: update.CallbackQuery.ID,
@@ -91,18 +414,33 @@ func (t *Telegram) handleCallback(ctx context.Context, b *bot.Bot, update *model
 	t.logger.Infof("Callback recebido do usuário %d: %s", userID, data)
 
 	callbackEvent := &domain.CallbackEvent{
-		UserID: userID,
-		ChatID: chatID,
-		Data:   data,
+		UserID:  userID,
+		ChatID:  chatID,
+		Data:    data,
+		Profile: userProfile(&update.CallbackQuery.From),
 	}
 
-	t.eventManager.MustFire("telegram.callback.received", event.M{
+	t.dispatch(ctx, "telegram.callback.received", event.M{
+		"ctx":   ctx,
 		"event": callbackEvent,
 	})
 }
 
-// registerEventListeners registers event listeners for outgoing messages and actions
+// registerEventListeners registers event listeners for outgoing messages, actions and the application lifecycle
 func (t *Telegram) registerEventListeners() {
+	// Polling stops with the components, the updates already handed to a handler finish first
+	t.eventManager.On("app.lifecycle", event.ListenerFunc(func(e event.Event) error {
+		lifecycle, ok := e.Get("event").(*domain.LifecycleEvent)
+		if !ok || lifecycle.Phase != domain.LifecycleDraining {
+			return nil
+		}
+
+		if err := t.waitIdle(eventContext(e)); err != nil {
+			t.logger.WithField("in_flight", t.inFlight.Load()).Warn("Encerrando com updates do Telegram ainda em tratamento")
+		}
+		return nil
+	}))
+
 	t.eventManager.On("telegram.send.message", event.ListenerFunc(func(e event.Event) error {
 		data, ok := e.Get("response").(*domain.MessageResponse)
 		if !ok {
@@ -118,9 +456,67 @@ func (t *Telegram) registerEventListeners() {
 			params.ReplyMarkup = t.buildKeyboard(data.Keyboard)
 		}
 
-		_, err := t.bot.SendMessage(context.Background(), params)
+		return t.sendMessage(eventContext(e), params)
+	}))
+
+	t.eventManager.On("telegram.send.document", event.ListenerFunc(func(e event.Event) error {
+		document, ok := e.Get("document").(*domain.Document)
+		if !ok {
+			return fmt.Errorf("tipo de documento inválido")
+		}
+
+		chatID := document.ChatID
+		if migrated, exists := t.migratedChat(chatID); exists {
+			chatID = migrated
+		}
+
+		if t.isBlocked(chatID) {
+			return domain.ErrChatUnavailable
+		}
+
+		_, err := t.bot.SendDocument(eventContext(e), &bot.SendDocumentParams{
+			ChatID:   chatID,
+			Document: &models.InputFileUpload{Filename: document.Name, Data: bytes.NewReader(document.Content)},
+			Caption:  document.Caption,
+		})
+
+		if errors.Is(err, bot.ErrorForbidden) {
+			t.markBlocked(eventContext(e), chatID)
+			return domain.ErrChatUnavailable
+		}
+
 		if err != nil {
-			t.logger.Errorf("Erro ao enviar mensagem: %v", err)
+			t.logger.Errorf("Erro ao enviar documento: %v", err)
+			return err
+		}
+
+		return nil
+	}))
+
+	t.eventManager.On("telegram.answer.inline", event.ListenerFunc(func(e event.Event) error {
+		answer, ok := e.Get("answer").(*domain.InlineAnswer)
+		if !ok {
+			return fmt.Errorf("tipo de resposta inline inválido")
+		}
+
+		results := make([]models.InlineQueryResult, len(answer.Results))
+		for i, result := range answer.Results {
+			results[i] = &models.InlineQueryResultArticle{
+				ID:                  result.ID,
+				Title:               result.Title,
+				Description:         result.Description,
+				InputMessageContent: &models.InputTextMessageContent{MessageText: result.Text},
+			}
+		}
+
+		_, err := t.bot.AnswerInlineQuery(eventContext(e), &bot.AnswerInlineQueryParams{
+			InlineQueryID: answer.QueryID,
+			Results:       results,
+			CacheTime:     int(answer.CacheTime / time.Second),
+			IsPersonal:    true,
+		})
+		if err != nil {
+			t.logger.Errorf("Erro ao responder consulta inline: %v", err)
 			return err
 		}
 
@@ -133,11 +529,24 @@ func (t *Telegram) registerEventListeners() {
 			return fmt.Errorf("tipo de chatID inválido")
 		}
 
-		_, err := t.bot.SendChatAction(context.Background(), &bot.SendChatActionParams{
+		if migrated, exists := t.migratedChat(chatID); exists {
+			chatID = migrated
+		}
+
+		if t.isBlocked(chatID) {
+			return domain.ErrChatUnavailable
+		}
+
+		_, err := t.bot.SendChatAction(eventContext(e), &bot.SendChatActionParams{
 			ChatID: chatID,
 			Action: models.ChatActionTyping,
 		})
 
+		if errors.Is(err, bot.ErrorForbidden) {
+			t.markBlocked(eventContext(e), chatID)
+			return domain.ErrChatUnavailable
+		}
+
 		if err != nil {
 			t.logger.Errorf("Erro ao enviar ação de digitação: %v", err)
 			return err
@@ -147,6 +556,134 @@ func (t *Telegram) registerEventListeners() {
 	}))
 }
 
+// sendMessage delivers a message, following group migrations and skipping chats that blocked the bot
+func (t *Telegram) sendMessage(ctx context.Context, params *bot.SendMessageParams) error {
+	chatID, _ := params.ChatID.(int64)
+	if migrated, exists := t.migratedChat(chatID); exists {
+		chatID = migrated
+		params.ChatID = migrated
+	}
+
+	if t.isBlocked(chatID) {
+		t.logger.WithField("chat_id", chatID).Debug("Mensagem descartada, chat bloqueou o bot")
+		return domain.ErrChatUnavailable
+	}
+
+	_, err := t.bot.SendMessage(ctx, params)
+
+	var migrateErr *bot.MigrateError
+	if errors.As(err, &migrateErr) {
+		to := int64(migrateErr.MigrateToChatID)
+		t.markMigrated(ctx, chatID, to)
+
+		params.ChatID = to
+		_, err = t.bot.SendMessage(ctx, params)
+	}
+
+	if errors.Is(err, bot.ErrorForbidden) {
+		t.markBlocked(ctx, chatID)
+		return domain.ErrChatUnavailable
+	}
+
+	if err != nil {
+		t.logger.Errorf("Erro ao enviar mensagem: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+// handleChatMember tracks the user blocking or unblocking the bot, and the bot leaving groups
+func (t *Telegram) handleChatMember(ctx context.Context, b *bot.Bot, update *models.Update) {
+	if !isChatMemberUpdate(update) {
+		return
+	}
+
+	chatID := update.MyChatMember.Chat.ID
+	switch update.MyChatMember.NewChatMember.Type {
+	case models.ChatMemberTypeBanned, models.ChatMemberTypeLeft:
+		t.markBlocked(ctx, chatID)
+	case models.ChatMemberTypeMember, models.ChatMemberTypeAdministrator:
+		t.markReachable(chatID)
+	}
+}
+
+// handleMigrationMessage follows a group that became a supergroup
+func (t *Telegram) handleMigrationMessage(ctx context.Context, b *bot.Bot, update *models.Update) {
+	if !isMigrationMessage(update) {
+		return
+	}
+
+	t.markMigrated(ctx, update.Message.Chat.ID, update.Message.MigrateToChatID)
+}
+
+// markBlocked stops deliveries to a chat and tells the handlers once
+func (t *Telegram) markBlocked(ctx context.Context, chatID int64) {
+	t.mu.Lock()
+	already := t.blocked[chatID]
+	t.blocked[chatID] = true
+	t.mu.Unlock()
+
+	if already {
+		return
+	}
+
+	t.logger.WithField("chat_id", chatID).Warn("Chat bloqueou ou removeu o bot, entregas suspensas")
+	t.dispatch(ctx, "telegram.chat.blocked", event.M{
+		"ctx":    ctx,
+		"chatID": chatID,
+	})
+}
+
+// markReachable resumes deliveries to a chat that talked to the bot again
+func (t *Telegram) markReachable(chatID int64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.blocked[chatID] {
+		delete(t.blocked, chatID)
+		t.logger.WithField("chat_id", chatID).Info("Chat voltou a aceitar mensagens do bot")
+	}
+}
+
+// markMigrated redirects the deliveries of a group to its new supergroup and tells the handlers once
+func (t *Telegram) markMigrated(ctx context.Context, from, to int64) {
+	t.mu.Lock()
+	_, already := t.migrations[from]
+	t.migrations[from] = to
+	t.mu.Unlock()
+
+	if already {
+		return
+	}
+
+	t.logger.WithFields(map[string]any{
+		"from_chat_id": from,
+		"to_chat_id":   to,
+	}).Warn("Grupo migrado para supergrupo")
+
+	t.dispatch(ctx, "telegram.chat.migrated", event.M{
+		"ctx":  ctx,
+		"from": from,
+		"to":   to,
+	})
+}
+
+// isBlocked reports whether the chat blocked the bot
+func (t *Telegram) isBlocked(chatID int64) bool {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.blocked[chatID]
+}
+
+// migratedChat returns the supergroup a group migrated to
+func (t *Telegram) migratedChat(chatID int64) (int64, bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	to, exists := t.migrations[chatID]
+	return to, exists
+}
+
 // buildKeyboard converts domain keyboard to Telegram keyboard markup
 func (t *Telegram) buildKeyboard(keyboard *domain.Keyboard) models.ReplyMarkup {
 	if keyboard.Inline {
@@ -154,10 +691,16 @@ func (t *Telegram) buildKeyboard(keyboard *domain.Keyboard) models.ReplyMarkup {
 		for _, row := range keyboard.Buttons {
 			var buttons []models.InlineKeyboardButton
 			for _, btn := range row {
-				buttons = append(buttons, models.InlineKeyboardButton{
-					Text:         btn.Text,
-					CallbackData: btn.Data,
-				})
+				button := models.InlineKeyboardButton{Text: btn.Text}
+				switch btn.Kind {
+				case domain.ButtonURL:
+					button.URL = btn.URL
+				case domain.ButtonWebApp:
+					button.WebApp = &models.WebAppInfo{URL: btn.URL}
+				default:
+					button.CallbackData = btn.Data
+				}
+				buttons = append(buttons, button)
 			}
 			rows = append(rows, buttons)
 		}
@@ -170,9 +713,15 @@ func (t *Telegram) buildKeyboard(keyboard *domain.Keyboard) models.ReplyMarkup {
 	for _, row := range keyboard.Buttons {
 		var buttons []models.KeyboardButton
 		for _, btn := range row {
-			buttons = append(buttons, models.KeyboardButton{
-				Text: btn.Text,
-			})
+			button := models.KeyboardButton{
+				Text:            btn.Text,
+				RequestContact:  btn.Kind == domain.ButtonRequestContact,
+				RequestLocation: btn.Kind == domain.ButtonRequestLocation,
+			}
+			if btn.Kind == domain.ButtonWebApp {
+				button.WebApp = &models.WebAppInfo{URL: btn.URL}
+			}
+			buttons = append(buttons, button)
 		}
 		rows = append(rows, buttons)
 	}
@@ -181,3 +730,11 @@ func (t *Telegram) buildKeyboard(keyboard *domain.Keyboard) models.ReplyMarkup {
 		ResizeKeyboard: true,
 	}
 }
+
+// eventContext extracts the propagated context from an event, defaulting to background
+func eventContext(e event.Event) context.Context {
+	if ctx, ok := e.Get("ctx").(context.Context); ok && ctx != nil {
+		return ctx
+	}
+	return context.Background()
+}
@@ -18,15 +18,33 @@ const (
 	ReadBufferSize           = 4096
 	CommandTerminator        = ";"
 	ConnectionCheckTimeout   = 500 * time.Millisecond
+
+	// DefaultMaxResponseSize bounds a single response, far above the largest listing of a full OLT
+	DefaultMaxResponseSize = 8 << 20
 )
 
 var (
-	ErrNotConnected    = errors.New("not connected to server")
-	ErrConnectionLost  = errors.New("connection lost")
-	ErrReadTimeout     = errors.New("read timeout")
-	ErrInvalidResponse = errors.New("invalid response format")
+	ErrNotConnected     = errors.New("not connected to server")
+	ErrConnectionLost   = errors.New("connection lost")
+	ErrReadTimeout      = errors.New("read timeout")
+	ErrInvalidResponse  = errors.New("invalid response format")
+	ErrResponseTooLarge = errors.New("response too large")
 )
 
+// ResponseTooLargeError reports a response cut short for going over the size limit
+type ResponseTooLargeError struct {
+	Limit int
+	Read  int
+}
+
+func (e *ResponseTooLargeError) Error() string {
+	return fmt.Sprintf("response exceeded %d bytes after reading %d bytes", e.Limit, e.Read)
+}
+
+func (e *ResponseTooLargeError) Unwrap() error {
+	return ErrResponseTooLarge
+}
+
 // TL1Transport represents a TL1 protocol transport layer
 type TL1Transport struct {
 	hostname string
@@ -34,6 +52,8 @@ type TL1Transport struct {
 	conn     net.Conn
 	mu       sync.RWMutex
 	closed   bool
+
+	maxResponseSize int
 }
 
 // NewTL1Transport creates a new TL1Transport instance and establishes connection
@@ -46,8 +66,9 @@ func NewTransport(hostname string, port uint16) (*TL1Transport, error) {
 	}
 
 	tl1 := &TL1Transport{
-		hostname: hostname,
-		port:     port,
+		hostname:        hostname,
+		port:            port,
+		maxResponseSize: DefaultMaxResponseSize,
 	}
 
 	if err := tl1.connect(); err != nil {
@@ -57,6 +78,17 @@ func NewTransport(hostname string, port uint16) (*TL1Transport, error) {
 	return tl1, nil
 }
 
+// SetMaxResponseSize bounds the bytes read for a single response, zero or less keeps the default
+func (t *TL1Transport) SetMaxResponseSize(size int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if size <= 0 {
+		size = DefaultMaxResponseSize
+	}
+	t.maxResponseSize = size
+}
+
 // connect establishes a TCP connection to the TL1 server
 func (t *TL1Transport) connect() error {
 	address := net.JoinHostPort(t.hostname, fmt.Sprint(t.port))
@@ -106,6 +138,11 @@ func (t *TL1Transport) ensureConnection() error {
 		return ErrNotConnected
 	}
 
+	// Connection dropped after an interrupted command
+	if t.conn == nil {
+		return t.connect()
+	}
+
 	if err := t.isConnectionAlive(); err != nil {
 		// If connection is dead, try to reconnect
 		if !errors.Is(err, ErrNotConnected) {
@@ -139,6 +176,11 @@ func (t *TL1Transport) readResponse() (string, error) {
 			return "", fmt.Errorf("failed to read response: %w", err)
 		}
 
+		// Stop before buffering what a misbehaving server keeps streaming
+		if response.Len()+n > t.maxResponseSize {
+			return "", &ResponseTooLargeError{Limit: t.maxResponseSize, Read: response.Len() + n}
+		}
+
 		chunk := string(buffer[:n])
 		response.WriteString(chunk)
 
@@ -158,10 +200,19 @@ func (t *TL1Transport) readResponse() (string, error) {
 
 // Cmd sends a command to the TL1 server and returns the response
 func (t *TL1Transport) Cmd(command string) (string, error) {
+	return t.Send(context.Background(), command)
+}
+
+// Send sends a command with context support for cancellation/timeout
+func (t *TL1Transport) Send(ctx context.Context, command string) (string, error) {
 	if command == "" {
 		return "", errors.New("command cannot be empty")
 	}
 
+	if err := ctx.Err(); err != nil {
+		return "", fmt.Errorf("command cancelled: %w", err)
+	}
+
 	t.mu.Lock()
 	defer t.mu.Unlock()
 
@@ -174,47 +225,55 @@ func (t *TL1Transport) Cmd(command string) (string, error) {
 		return "", fmt.Errorf("connection check failed: %w", err)
 	}
 
+	conn := t.conn
+
+	// Bound I/O by the context deadline and interrupt it on cancellation
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+	stop := context.AfterFunc(ctx, func() {
+		conn.SetDeadline(time.Now())
+	})
+	defer func() {
+		stop()
+		conn.SetDeadline(time.Time{})
+	}()
+
 	// Send the command
-	if _, err := t.conn.Write([]byte(command)); err != nil {
-		return "", fmt.Errorf("failed to send command: %w", err)
+	if _, err := conn.Write([]byte(command)); err != nil {
+		return "", t.interruptedErr(ctx, fmt.Errorf("failed to send command: %w", err))
 	}
 
 	// Read and return the response
 	response, err := t.readResponse()
-	if err != nil {
+	if errors.Is(err, ErrResponseTooLarge) {
+		t.resync()
 		return "", fmt.Errorf("failed to read response: %w", err)
 	}
+	if err != nil {
+		return "", t.interruptedErr(ctx, fmt.Errorf("failed to read response: %w", err))
+	}
 
 	return response, nil
 }
 
-// Send sends a command with context support for cancellation/timeout
-func (t *TL1Transport) Send(ctx context.Context, command string) (string, error) {
-	if command == "" {
-		return "", errors.New("command cannot be empty")
+// interruptedErr drops a connection left mid-response by a cancelled context,
+// so the next command does not read stale data
+func (t *TL1Transport) interruptedErr(ctx context.Context, err error) error {
+	if ctx.Err() == nil {
+		return err
 	}
 
-	// Create a channel to receive the result
-	resultChan := make(chan struct {
-		response string
-		err      error
-	}, 1)
-
-	// Execute command in goroutine
-	go func() {
-		response, err := t.Cmd(command)
-		resultChan <- struct {
-			response string
-			err      error
-		}{response, err}
-	}()
+	t.resync()
+	return fmt.Errorf("command cancelled: %w", ctx.Err())
+}
 
-	// Wait for either completion or context cancellation
-	select {
-	case result := <-resultChan:
-		return result.response, result.err
-	case <-ctx.Done():
-		return "", fmt.Errorf("command cancelled: %w", ctx.Err())
+// resync drops a connection whose response was abandoned midway, the rest of it would be read as the
+// answer to the next command
+func (t *TL1Transport) resync() {
+	if t.conn != nil {
+		t.conn.Close()
+		t.conn = nil
 	}
 }
 
@@ -0,0 +1,72 @@
+package unm
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"strings"
+)
+
+// BlastRadius classifies how many ONUs a TL1 command may affect
+type BlastRadius int
+
+const (
+	BlastRadiusNone BlastRadius = iota
+	BlastRadiusSingleONU
+	BlastRadiusMultiONU
+)
+
+var ErrApprovalRequired = errors.New("comando afeta mais de uma ONU e exige aprovação de supervisor")
+
+// ReadOnlyVerbs lists the TL1 command prefixes that never change OLT state
+var ReadOnlyVerbs = []string{"LST-", "LOGIN", "LOGOUT"}
+
+var onuTargetPattern = regexp.MustCompile(`(?i)(^|[,:])ONUID=([^,:;]+)`)
+
+type approvalKey struct{}
+
+// String returns the blast radius label used in logs and messages
+func (r BlastRadius) String() string {
+	switch r {
+	case BlastRadiusNone:
+		return "leitura"
+	case BlastRadiusSingleONU:
+		return "uma ONU"
+	default:
+		return "múltiplas ONUs"
+	}
+}
+
+// ClassifyCommand reports the blast radius of a TL1 command, anything not scoped to a single ONU is treated as multi-ONU
+func ClassifyCommand(command string) BlastRadius {
+	normalized := strings.ToUpper(strings.TrimSpace(command))
+
+	for _, verb := range ReadOnlyVerbs {
+		if strings.HasPrefix(normalized, verb) {
+			return BlastRadiusNone
+		}
+	}
+
+	matches := onuTargetPattern.FindAllStringSubmatch(normalized, -1)
+	if len(matches) != 1 {
+		return BlastRadiusMultiONU
+	}
+
+	target := strings.TrimSpace(matches[0][2])
+	if target == "" || strings.ContainsAny(target, "*&") || strings.EqualFold(target, "ALL") {
+		return BlastRadiusMultiONU
+	}
+
+	return BlastRadiusSingleONU
+}
+
+// WithApproval marks the context as carrying a supervisor approval for multi-ONU commands
+func WithApproval(ctx context.Context, approvalID string) context.Context {
+	return context.WithValue(ctx, approvalKey{}, approvalID)
+}
+
+// ApprovalFrom returns the approval carried by the context, if any
+func ApprovalFrom(ctx context.Context) (string, bool) {
+	approvalID, ok := ctx.Value(approvalKey{}).(string)
+	return approvalID, ok && approvalID != ""
+}
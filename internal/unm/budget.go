@@ -0,0 +1,74 @@
+package unm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// DefaultMaxJobCommands and DefaultMaxJobDuration bound a provisioning job, well above what a valid
+	// template expands to
+	DefaultMaxJobCommands = 60
+	DefaultMaxJobDuration = 3 * time.Minute
+)
+
+// ErrCommandBudgetExceeded aborts a job whose TL1 commands ran past the budget, usually a profile
+// expanding to far more commands than intended
+var ErrCommandBudgetExceeded = errors.New("orçamento de comandos TL1 do job excedido")
+
+// CommandBudget bounds how many TL1 commands a job may send and for how long it may run,
+// a zero limit is not enforced
+type CommandBudget struct {
+	MaxCommands int
+	MaxDuration time.Duration
+}
+
+type budgetKey struct{}
+
+type commandBudget struct {
+	limits   CommandBudget
+	commands atomic.Int64
+}
+
+// WithCommandBudget bounds the commands sent under the returned context, which is cancelled once the
+// duration ceiling is reached
+func WithCommandBudget(ctx context.Context, budget CommandBudget) (context.Context, context.CancelFunc) {
+	ctx = context.WithValue(ctx, budgetKey{}, &commandBudget{limits: budget})
+	if budget.MaxDuration > 0 {
+		return context.WithTimeoutCause(ctx, budget.MaxDuration, ErrCommandBudgetExceeded)
+	}
+	return context.WithCancel(ctx)
+}
+
+// spendBudget counts a command against the budget of the context
+func spendBudget(ctx context.Context) error {
+	budget, _ := ctx.Value(budgetKey{}).(*commandBudget)
+	if budget == nil {
+		return nil
+	}
+
+	if err := budgetExpired(ctx); err != nil {
+		return err
+	}
+
+	if sent := budget.commands.Add(1); budget.limits.MaxCommands > 0 && sent > int64(budget.limits.MaxCommands) {
+		return fmt.Errorf("%w: limite de %d comandos", ErrCommandBudgetExceeded, budget.limits.MaxCommands)
+	}
+	return nil
+}
+
+// budgetExpired returns the budget error once the duration ceiling of the context was reached
+func budgetExpired(ctx context.Context) error {
+	if !errors.Is(context.Cause(ctx), ErrCommandBudgetExceeded) {
+		return nil
+	}
+
+	budget, _ := ctx.Value(budgetKey{}).(*commandBudget)
+	if budget == nil {
+		return ErrCommandBudgetExceeded
+	}
+	return fmt.Errorf("%w: limite de %s", ErrCommandBudgetExceeded, budget.limits.MaxDuration)
+}
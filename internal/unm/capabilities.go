@@ -0,0 +1,125 @@
+package unm
+
+import (
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Capability identifies an optional UNM feature that depends on the server version
+type Capability string
+
+const (
+	CapabilityWifi      Capability = "wifi"
+	CapabilityBandwidth Capability = "bandwidth"
+	CapabilityWifiScan  Capability = "wifi_scan"
+)
+
+// CapabilityMinVersions lists the first UNM version supporting each capability
+var CapabilityMinVersions = map[Capability]string{
+	CapabilityWifi:      "3.0",
+	CapabilityBandwidth: "3.0",
+	CapabilityWifiScan:  "5.0",
+}
+
+// BaselineCapabilities are assumed when the server version cannot be detected
+var BaselineCapabilities = []Capability{
+	CapabilityWifi,
+	CapabilityBandwidth,
+}
+
+var (
+	versionPattern       = regexp.MustCompile(`(?i)VERSION\s*[=:]\s*"?([^\s",;]+)`)
+	versionNumberPattern = regexp.MustCompile(`\d+`)
+)
+
+// Capabilities holds the features detected on a UNM endpoint
+type Capabilities struct {
+	Version  string
+	Detected bool
+	features map[Capability]bool
+}
+
+// newCapabilities derives the supported features from a server version, empty means unknown
+func newCapabilities(version string) Capabilities {
+	caps := Capabilities{
+		Version:  version,
+		Detected: version != "",
+		features: make(map[Capability]bool),
+	}
+
+	if !caps.Detected {
+		for _, capability := range BaselineCapabilities {
+			caps.features[capability] = true
+		}
+		return caps
+	}
+
+	for capability, minVersion := range CapabilityMinVersions {
+		caps.features[capability] = compareVersions(version, minVersion) >= 0
+	}
+
+	return caps
+}
+
+// Has reports whether the endpoint supports a capability
+func (c Capabilities) Has(capability Capability) bool {
+	return c.features[capability]
+}
+
+// List returns the supported capabilities
+func (c Capabilities) List() []Capability {
+	list := make([]Capability, 0, len(c.features))
+	for capability, supported := range c.features {
+		if supported {
+			list = append(list, capability)
+		}
+	}
+	slices.Sort(list)
+	return list
+}
+
+// parseVersion extracts the server version from a version query response
+func parseVersion(response string) string {
+	if matches := versionPattern.FindStringSubmatch(response); len(matches) > 1 {
+		return matches[1]
+	}
+
+	lines := splitAndTrimLines(strings.ReplaceAll(response, "\r", ""))
+	if len(lines) <= HeaderLines {
+		return ""
+	}
+
+	fields := strings.Split(lines[HeaderLines], "\t")
+	version := strings.TrimSpace(fields[len(fields)-1])
+	if !versionNumberPattern.MatchString(version) {
+		return ""
+	}
+	return version
+}
+
+// compareVersions compares dotted versions numerically, ignoring prefixes such as "V" or "R"
+func compareVersions(a, b string) int {
+	partsA := versionNumberPattern.FindAllString(a, -1)
+	partsB := versionNumberPattern.FindAllString(b, -1)
+
+	for i := range max(len(partsA), len(partsB)) {
+		var numA, numB int
+		if i < len(partsA) {
+			numA, _ = strconv.Atoi(partsA[i])
+		}
+		if i < len(partsB) {
+			numB, _ = strconv.Atoi(partsB[i])
+		}
+
+		if numA != numB {
+			if numA < numB {
+				return -1
+			}
+			return 1
+		}
+	}
+
+	return 0
+}
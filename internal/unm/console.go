@@ -0,0 +1,108 @@
+package unm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+const (
+	// MaxConsoleCommandLength bounds a raw TL1 command typed in the console
+	MaxConsoleCommandLength = 512
+
+	commandTerminator = ";"
+)
+
+// DefaultConsoleVerbs allows only read commands in the raw console
+var DefaultConsoleVerbs = []string{"LST-"}
+
+var (
+	ErrConsoleCommandInvalid = errors.New("comando TL1 inválido")
+	ErrConsoleVerbNotAllowed = errors.New("verbo TL1 não permitido no console")
+
+	// Session commands would hijack the shared UNM login
+	consoleForbiddenVerbs = []string{"LOGIN", "LOGOUT", "CANC-USER"}
+)
+
+// ParseConsoleCommand validates a raw TL1 command against the verb allowlist and returns it
+// as a template ready to be tagged. Entries ending in "-" allow every verb with that prefix.
+func ParseConsoleCommand(input string, allowedVerbs []string) (string, error) {
+	command := strings.TrimSpace(input)
+	command = strings.TrimSuffix(command, commandTerminator)
+
+	if command == "" {
+		return "", fmt.Errorf("%w: comando vazio", ErrConsoleCommandInvalid)
+	}
+
+	if len(command) > MaxConsoleCommandLength {
+		return "", fmt.Errorf("%w: mais de %d caracteres", ErrConsoleCommandInvalid, MaxConsoleCommandLength)
+	}
+
+	if strings.Contains(command, commandTerminator) {
+		return "", fmt.Errorf("%w: apenas um comando por vez", ErrConsoleCommandInvalid)
+	}
+
+	if strings.IndexFunc(command, func(r rune) bool { return !unicode.IsPrint(r) || r > unicode.MaxASCII }) >= 0 {
+		return "", fmt.Errorf("%w: caracteres não permitidos", ErrConsoleCommandInvalid)
+	}
+
+	// VERB:TID:AID:CTAG::PARAMETERS, missing trailing fields are filled in
+	fields := strings.Split(command, ":")
+	for len(fields) < 5 {
+		fields = append(fields, "")
+	}
+
+	verb := strings.ToUpper(strings.TrimSpace(fields[0]))
+	if verb == "" || strings.ContainsAny(verb, " \t=,") {
+		return "", fmt.Errorf("%w: verbo ausente", ErrConsoleCommandInvalid)
+	}
+
+	for _, forbidden := range consoleForbiddenVerbs {
+		if verb == forbidden {
+			return "", fmt.Errorf("%w: %s", ErrConsoleVerbNotAllowed, verb)
+		}
+	}
+
+	if !verbAllowed(verb, allowedVerbs) {
+		return "", fmt.Errorf("%w: %s", ErrConsoleVerbNotAllowed, verb)
+	}
+
+	// The CTAG is always assigned by the client so the response can be matched
+	fields[0] = verb
+	fields[3] = "CTAG"
+
+	return strings.Join(fields, ":") + commandTerminator, nil
+}
+
+// verbAllowed reports whether the verb matches an exact entry or a prefix entry of the allowlist
+func verbAllowed(verb string, allowedVerbs []string) bool {
+	for _, allowed := range allowedVerbs {
+		allowed = strings.ToUpper(strings.TrimSpace(allowed))
+		if allowed == "" {
+			continue
+		}
+
+		if verb == allowed || (strings.HasSuffix(allowed, "-") && strings.HasPrefix(verb, allowed)) {
+			return true
+		}
+	}
+	return false
+}
+
+// Execute sends a command validated by ParseConsoleCommand once and returns the raw response. A typed command
+// is never repeated, an expired login is dropped so the next one logs in again.
+func (us *UNMClient) Execute(ctx context.Context, command string) (string, error) {
+	if err := us.ensureConnection(ctx); err != nil {
+		return "", err
+	}
+
+	response, err := us.sendCommand(ctx, command)
+	if us.isIllegalSessionError(err) {
+		us.mtx.Lock()
+		us.connected = false
+		us.mtx.Unlock()
+	}
+	return response, err
+}
@@ -0,0 +1,160 @@
+package unm
+
+import (
+	"encoding/json"
+	"flag"
+	"net"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+const fixturesDir = "testdata/fixtures"
+
+var update = flag.Bool("update", false, "rewrite the expected parse results of the UNM fixtures")
+
+// fixtureAddressPattern finds the IPv4 addresses a capture must have replaced by private ones
+var fixtureAddressPattern = regexp.MustCompile(`\b\d{1,3}(?:\.\d{1,3}){3}\b`)
+
+// fixtureParser reads a captured response the way the client does after sending the command
+type fixtureParser func(us *UNMClient, response string) (any, error)
+
+// fixtureParsers maps each fixture directory, named after the TL1 command, to its parser
+var fixtureParsers = map[string]fixtureParser{
+	"LST-OMDDM": func(us *UNMClient, response string) (any, error) {
+		return us.buildONUInfoFromResponse(response)
+	},
+	// The PON-wide form of LST-OMDDM, without ONUID, answered with one row per ONU
+	"LST-OMDDM-PON": func(us *UNMClient, response string) (any, error) {
+		return parsePonOpticalInfo(response), nil
+	},
+	"LST-ONU": func(us *UNMClient, response string) (any, error) {
+		return parseTable(response, "NAME"), nil
+	},
+	"LST-WANSERVICE": func(us *UNMClient, response string) (any, error) {
+		return parseTable(response, "VLAN"), nil
+	},
+	"LST-WIFINEIGHBOR": func(us *UNMClient, response string) (any, error) {
+		return parseWifiNeighbors(response), nil
+	},
+	"LST-VERSION": func(us *UNMClient, response string) (any, error) {
+		capabilities := newCapabilities(parseVersion(response))
+		return map[string]any{
+			"version":      capabilities.Version,
+			"capabilities": capabilities.List(),
+		}, nil
+	},
+	"LOGIN": func(us *UNMClient, response string) (any, error) {
+		days, notice := parsePasswordExpiry(response)
+		return map[string]any{
+			"expires_in_days": days,
+			"notice":          notice,
+		}, nil
+	},
+}
+
+// fixtureResult is what the client makes of a captured response, kept next to it as JSON
+type fixtureResult struct {
+	Error   string `json:"error,omitempty"`
+	Expired bool   `json:"expired,omitempty"`
+	Locked  bool   `json:"locked,omitempty"`
+	Result  any    `json:"result,omitempty"`
+}
+
+// TestUnmResponseContracts parses every captured UNM response under testdata/fixtures and compares
+// the outcome with the expected one, so server upgrades and parser changes are checked against real formats.
+// New captures go in the directory of their command with addresses and PPPoE passwords masked,
+// running with -update records what the parser makes of them.
+func TestUnmResponseContracts(t *testing.T) {
+	dirs, err := os.ReadDir(fixturesDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client := New("", "", nil, nil)
+
+	for _, dir := range dirs {
+		parser, exists := fixtureParsers[dir.Name()]
+		if !exists {
+			t.Errorf("capturas de %s sem parser associado", dir.Name())
+			continue
+		}
+
+		captures, err := filepath.Glob(filepath.Join(fixturesDir, dir.Name(), "*.tl1"))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, capture := range captures {
+			name := dir.Name() + "/" + strings.TrimSuffix(filepath.Base(capture), ".tl1")
+			t.Run(name, func(t *testing.T) {
+				runContract(t, client, parser, capture)
+			})
+		}
+	}
+}
+
+// runContract parses one capture and compares or rewrites its expected result
+func runContract(t *testing.T, client *UNMClient, parser fixtureParser, capture string) {
+	content, err := os.ReadFile(capture)
+	if err != nil {
+		t.Fatal(err)
+	}
+	response := string(content)
+
+	checkSanitized(t, response)
+
+	// Responses carrying an error never reach the parsers, just like in sendCommand
+	var result fixtureResult
+	if err := client.isResponseErr(response); err != nil {
+		result.Error = err.Error()
+		result.Expired = passwordExpiredPattern.MatchString(err.Error())
+		result.Locked = accountLockedPattern.MatchString(err.Error())
+	} else if parsed, err := parser(client, response); err != nil {
+		result.Error = err.Error()
+	} else {
+		result.Result = parsed
+	}
+
+	got, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got = append(got, '\n')
+
+	expectedFile := strings.TrimSuffix(capture, ".tl1") + ".json"
+	if *update {
+		if err := os.WriteFile(expectedFile, got, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+
+	expected, err := os.ReadFile(expectedFile)
+	if err != nil {
+		t.Fatalf("resultado esperado ausente, gere com -update: %v", err)
+	}
+
+	if string(got) != string(expected) {
+		t.Errorf("resultado divergente da captura\nesperado:\n%s\nobtido:\n%s", expected, got)
+	}
+}
+
+// checkSanitized rejects captures still holding public addresses or unmasked passwords
+func checkSanitized(t *testing.T, response string) {
+	t.Helper()
+
+	for _, address := range fixtureAddressPattern.FindAllString(response, -1) {
+		if ip := net.ParseIP(address); ip != nil && !ip.IsPrivate() {
+			t.Errorf("captura com endereço público %s, substitua por um endereço privado", address)
+		}
+	}
+
+	for _, row := range parseTable(response, "PPPOEPASSWD") {
+		if password := row["PPPOEPASSWD"]; password != "" && password != "--" && strings.Trim(password, "*") != "" {
+			t.Errorf("captura com senha PPPoE exposta, substitua por asteriscos")
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package unm
+
+import (
+	"time"
+)
+
+// Credential is a UNM account used to log in
+type Credential struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+// IsZero reports whether the credential has no account
+func (c Credential) IsZero() bool {
+	return c.Username == "" || c.Password == ""
+}
+
+// CredentialSet is the account of a UNM endpoint during a password rotation. Both credentials are
+// accepted while the UNM changes the password, the preferred one swaps at RotateAt.
+type CredentialSet struct {
+	Current  Credential  `json:"current"`
+	Next     *Credential `json:"next,omitempty"`
+	RotateAt time.Time   `json:"rotate_at"`
+}
+
+// Candidates lists the credentials to try in order at the given moment
+func (s CredentialSet) Candidates(now time.Time) []Credential {
+	if s.Next == nil || s.Next.IsZero() {
+		return []Credential{s.Current}
+	}
+
+	if s.RotateAt.IsZero() || now.Before(s.RotateAt) {
+		return []Credential{s.Current, *s.Next}
+	}
+	return []Credential{*s.Next, s.Current}
+}
+
+// CredentialProvider returns the credentials of a UNM endpoint, read again at every login so a
+// rotation takes effect without a restart
+type CredentialProvider interface {
+	Credentials(endpoint string) CredentialSet
+}
+
+// StaticCredentials is a provider with the same account for every endpoint
+type StaticCredentials Credential
+
+// Credentials returns the static account
+func (c StaticCredentials) Credentials(string) CredentialSet {
+	return CredentialSet{Current: Credential(c)}
+}
@@ -0,0 +1,93 @@
+package unm
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	// Password ageing notices seen in LOGIN responses, e.g. "PASSWORD WILL EXPIRE IN 5 DAYS"
+	passwordExpiryPattern = regexp.MustCompile(`(?i)(?:password|pwd|senha)[^\r\n]*?expir[^\r\n]*?(\d+)\s*(?:day|dia)`)
+
+	// Login refusals that will not go away by retrying
+	passwordExpiredPattern = regexp.MustCompile(`(?i)(?:password|pwd|senha)[^\r\n]*?(?:has\s+)?expired|expirad[ao]`)
+	accountLockedPattern   = regexp.MustCompile(`(?i)(?:user|account|usu[aá]rio|conta)[^\r\n]*?(?:locked|blocked|bloquead[ao])`)
+)
+
+// CredentialStatus is the outcome of a standalone login with the configured UNM account
+type CredentialStatus struct {
+	Endpoint  string
+	Username  string
+	CheckedAt time.Time
+	Duration  time.Duration
+
+	// ExpiresInDays is the remaining password validity announced by the UNM, -1 when not announced
+	ExpiresInDays int
+	Expired       bool
+	Locked        bool
+	Notice        string
+}
+
+// CheckCredentials logs in and out on the client's own transport, without touching the shared
+// provisioning session. The client must be dedicated to the check and is closed afterwards.
+// It makes a single login attempt, so repeated failures never add up to an account lockout.
+func (us *UNMClient) CheckCredentials(ctx context.Context) (*CredentialStatus, error) {
+	status := &CredentialStatus{
+		Endpoint:      us.transporter.GetAddress(),
+		CheckedAt:     time.Now(),
+		ExpiresInDays: -1,
+	}
+
+	defer us.transporter.Close()
+
+	if !us.transporter.IsConnected() {
+		if err := us.transporter.Reconnect(); err != nil {
+			return status, fmt.Errorf("falha ao conectar ao UNM: %w", err)
+		}
+	}
+
+	// The preferred credential is the one the provisioning session logs in with
+	credential := us.credentials.Credentials(status.Endpoint).Candidates(status.CheckedAt)[0]
+	status.Username = credential.Username
+
+	started := time.Now()
+	response, err := us.sendCommand(ctx, fmt.Sprintf(LoginCommand, credential.Username, credential.Password))
+	status.Duration = time.Since(started)
+
+	if err != nil {
+		status.Expired = passwordExpiredPattern.MatchString(err.Error())
+		status.Locked = accountLockedPattern.MatchString(err.Error())
+		return status, fmt.Errorf("falha no login: %w", err)
+	}
+
+	status.ExpiresInDays, status.Notice = parsePasswordExpiry(response)
+
+	if _, err := us.sendCommand(ctx, LogoutCommand); err != nil {
+		us.logger.WithError(err).WithField("endpoint", status.Endpoint).Debug("Falha no logout da verificação de credenciais")
+	}
+
+	return status, nil
+}
+
+// parsePasswordExpiry extracts the remaining password days and the notice line from a LOGIN response
+func parsePasswordExpiry(response string) (int, string) {
+	for _, line := range splitAndTrimLines(response) {
+		matches := passwordExpiryPattern.FindStringSubmatch(line)
+		if len(matches) < 2 {
+			continue
+		}
+
+		days, err := strconv.Atoi(matches[1])
+		if err != nil {
+			continue
+		}
+
+		return days, strings.TrimSpace(line)
+	}
+
+	return -1, ""
+}
@@ -0,0 +1,163 @@
+package unm
+
+import (
+	"context"
+	"provisioning-assistant/internal/domain"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// secretParamPattern finds the parameters carrying passwords, kept out of the stored history
+var secretParamPattern = regexp.MustCompile(`(?i)((?:^|[,:])(?:PWD|PPPOEPASSWD)=)[^,:;]*`)
+
+const redactedValue = "***"
+
+type commandLogKey struct{}
+
+// CommandLog collects the TL1 commands sent under a context, so a job can keep them with its audit record
+type CommandLog struct {
+	mu       sync.Mutex
+	commands []domain.Tl1Command
+}
+
+// WithCommandLog records the commands sent under the returned context in the log
+func WithCommandLog(ctx context.Context) (context.Context, *CommandLog) {
+	log := &CommandLog{}
+	return context.WithValue(ctx, commandLogKey{}, log), log
+}
+
+// Commands returns the commands recorded so far, in the order they were sent
+func (l *CommandLog) Commands() []domain.Tl1Command {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return slices.Clone(l.commands)
+}
+
+// recordCommand adds a command sent to an ONU to the log of the context, with its passwords redacted.
+// Session commands such as the login are left out.
+func recordCommand(ctx context.Context, command, tag string, sentAt time.Time, err error) {
+	log, _ := ctx.Value(commandLogKey{}).(*CommandLog)
+	if log == nil || !onuTargetPattern.MatchString(command) {
+		return
+	}
+
+	entry := domain.Tl1Command{Command: RedactCommand(command), Ctag: tag, SentAt: sentAt}
+	if err != nil {
+		entry.Error = err.Error()
+	}
+
+	log.mu.Lock()
+	log.commands = append(log.commands, entry)
+	log.mu.Unlock()
+}
+
+// RedactCommand hides the password parameters of a command
+func RedactCommand(command string) string {
+	return secretParamPattern.ReplaceAllString(command, "${1}"+redactedValue)
+}
+
+// ReplayedOnu is the OLT-side configuration of an ONU rebuilt from the commands sent for it
+type ReplayedOnu struct {
+	Registered    bool
+	OltIP         string
+	PonID         string
+	Name          string
+	Model         string
+	UpBandwidth   string
+	DownBandwidth string
+	DBAProfile    string
+	WanServices   []string
+	LanPorts      []string
+	IptvPorts     []string
+
+	// Commands are the ones that would rebuild this configuration on a clean PON, passwords redacted
+	Commands []string
+}
+
+// Replay rebuilds the configuration an ONU is meant to have from its command history, oldest first.
+// Nothing is sent to the OLT: queries and failed commands are skipped and a deletion starts over.
+func Replay(history []domain.Tl1Command) *ReplayedOnu {
+	onu := &ReplayedOnu{}
+	wanByVlan := make(map[string]string)
+	var vlans []string
+
+	for _, entry := range history {
+		if entry.Error != "" || ClassifyCommand(entry.Command) == BlastRadiusNone {
+			continue
+		}
+
+		verb := strings.ToUpper(commandVerb(entry.Command))
+		params := commandParams(entry.Command)
+
+		switch verb {
+		case "DEL-ONU":
+			onu = &ReplayedOnu{}
+			wanByVlan = make(map[string]string)
+			vlans = nil
+			continue
+		case "ADD-ONU":
+			onu.Registered = true
+			onu.OltIP = params["OLTID"]
+			onu.PonID = params["PONID"]
+			onu.Name = params["NAME"]
+			onu.Model = params["ONUTYPE"]
+		case "CFG-ONUBW":
+			onu.UpBandwidth = params["UPBW"]
+			onu.DownBandwidth = params["DOWNBW"]
+		case "CFG-ONUDBA":
+			onu.DBAProfile = params["DBAPROFILE"]
+		case "SET-WANSERVICE":
+			vlan := params["VLAN"]
+			if _, seen := wanByVlan[vlan]; !seen {
+				vlans = append(vlans, vlan)
+			}
+			wanByVlan[vlan] = wanSummary(params)
+		case "ACT-LANPORT":
+			onu.LanPorts = appendUnique(onu.LanPorts, params["ONUPORT"])
+		case "ADD-LANIPTVPORT":
+			onu.IptvPorts = appendUnique(onu.IptvPorts, params["ONUPORT"])
+		}
+
+		onu.Commands = append(onu.Commands, entry.Command)
+	}
+
+	for _, vlan := range vlans {
+		onu.WanServices = append(onu.WanServices, wanByVlan[vlan])
+	}
+	return onu
+}
+
+// commandParams reads the KEY=VALUE parameters of every block of a command
+func commandParams(command string) map[string]string {
+	params := make(map[string]string)
+	fields := strings.FieldsFunc(strings.TrimSuffix(command, ";"), func(r rune) bool {
+		return r == ':' || r == ','
+	})
+
+	for _, field := range fields {
+		if key, value, found := strings.Cut(field, "="); found {
+			params[strings.ToUpper(key)] = value
+		}
+	}
+	return params
+}
+
+// wanSummary describes a WAN service by its VLAN, mode and PPPoE user
+func wanSummary(params map[string]string) string {
+	summary := "VLAN " + params["VLAN"]
+	if user := params["PPPOEUSER"]; user != "" {
+		return summary + " PPPoE " + user
+	}
+	return summary + " bridge"
+}
+
+// appendUnique adds a value to the list when it is not there yet
+func appendUnique(values []string, value string) []string {
+	if value == "" || slices.Contains(values, value) {
+		return values
+	}
+	return append(values, value)
+}
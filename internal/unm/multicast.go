@@ -0,0 +1,86 @@
+package unm
+
+import (
+	"context"
+	"fmt"
+	"provisioning-assistant/internal/domain"
+	"slices"
+	"strings"
+)
+
+// DefaultMulticastPorts lists the LAN ports joined to the multicast VLAN when the template names none
+var DefaultMulticastPorts = []string{"1"}
+
+// MulticastOnuModels lists the ONU models able to snoop IGMP and forward the multicast VLAN,
+// the others are skipped and reported
+var MulticastOnuModels = []string{
+	"AN5506-01-A1",
+	"AN5506-04-F1",
+	"AN5506-04-FA",
+	"HG6143D",
+	"HG6145F",
+}
+
+// SupportsMulticast reports whether the ONU model can be configured for IPTV multicast
+func SupportsMulticast(model string) bool {
+	return slices.ContainsFunc(MulticastOnuModels, func(supported string) bool {
+		return strings.EqualFold(supported, model)
+	})
+}
+
+// configureMulticast enables IGMP snooping on the ONU and binds its LAN ports to the multicast VLAN,
+// a failure is reported like the extra WAN services and does not stop the provisioning
+func (us *UNMClient) configureMulticast(ctx context.Context, config OnuProvisioningConfig) domain.WanServiceStatus {
+	status := domain.WanServiceStatus{Vlan: config.MulticastVlan, Multicast: true}
+
+	log := us.logger.WithFields(map[string]any{
+		"olt":    config.OltIP,
+		"serial": config.Serial,
+		"model":  config.Model,
+		"mvlan":  config.MulticastVlan,
+	})
+
+	if !SupportsMulticast(config.Model) {
+		log.Warn("Modelo da ONU não suporta multicast, IPTV não configurado")
+		status.Err = ErrMulticastUnsupported.Error()
+		return status
+	}
+
+	if err := us.setMulticast(ctx, config); err != nil {
+		log.WithError(err).Warn("Falha ao configurar multicast da ONU")
+		status.Err = err.Error()
+	}
+
+	return status
+}
+
+// setMulticast sends the IGMP mode and then the multicast VLAN of every LAN port
+func (us *UNMClient) setMulticast(ctx context.Context, config OnuProvisioningConfig) error {
+	ponID := us.ponID(config.PonSlot, config.PonPort)
+
+	command := fmt.Sprintf(SetIgmpModeCommand, config.OltIP, ponID, config.Serial)
+	if _, err := us.sendCommand(ctx, command); err != nil {
+		return fmt.Errorf("falha ao habilitar IGMP: %w", err)
+	}
+
+	ports := config.MulticastPorts
+	if len(ports) == 0 {
+		ports = DefaultMulticastPorts
+	}
+
+	for _, port := range ports {
+		command := fmt.Sprintf(AddIptvPortCommand, config.OltIP, ponID, config.Serial, port, config.MulticastVlan)
+
+		us.logger.WithFields(map[string]any{
+			"serial": config.Serial,
+			"port":   port,
+			"mvlan":  config.MulticastVlan,
+		}).Debug("Associando porta LAN à VLAN multicast")
+
+		if _, err := us.sendCommand(ctx, command); err != nil {
+			return fmt.Errorf("falha ao associar porta LAN %s à VLAN multicast: %w", port, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,77 @@
+package unm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const (
+	// ctagPlaceholder is the CTAG field of the command templates, replaced by the command tag on send
+	ctagPlaceholder = ":CTAG:"
+
+	// jobTagLength is how many trailing characters of the job ID go into the CTAG, the random end of
+	// the ULID is enough to tell concurrent jobs apart while keeping the tag short
+	jobTagLength = 6
+)
+
+var (
+	ErrCtagMismatch = errors.New("resposta do UNM pertence a outro comando")
+
+	// TL1 responses echo the CTAG in the acknowledgement line, e.g. "M  J1A2B3CN4 COMPLD"
+	ctagResponsePattern = regexp.MustCompile(`(?m)^\s*M\s+(\S+)\s+(?:COMPLD|DENY|PRTL|DELAY)`)
+)
+
+type originKey struct{}
+
+// Origin identifies who a TL1 command runs for, so commands sharing a UNM session
+// can be told apart in the OLT logs and correlated with the audit trail
+type Origin struct {
+	UserID int64
+	JobID  string
+}
+
+// WithOrigin marks the context with the technician and job the commands run for
+func WithOrigin(ctx context.Context, origin Origin) context.Context {
+	return context.WithValue(ctx, originKey{}, origin)
+}
+
+// originFrom returns the origin carried by the context, zero when the command comes from the system
+func originFrom(ctx context.Context) Origin {
+	origin, _ := ctx.Value(originKey{}).(Origin)
+	return origin
+}
+
+// commandTag builds the alphanumeric CTAG of a command: J<job> for provisioning jobs,
+// U<user> for other technician commands and S for system ones, followed by N<sequence>
+func commandTag(origin Origin, sequence uint64) string {
+	var prefix string
+	switch {
+	case origin.JobID != "":
+		prefix = "J" + origin.JobID[max(len(origin.JobID)-jobTagLength, 0):]
+	case origin.UserID != 0:
+		prefix = "U" + strconv.FormatInt(origin.UserID, 10)
+	default:
+		prefix = "S"
+	}
+
+	return prefix + "N" + strconv.FormatUint(sequence, 10)
+}
+
+// tagCommand replaces the CTAG placeholder of a command template with the tag
+func tagCommand(command, tag string) string {
+	return strings.Replace(command, ctagPlaceholder, ":"+tag+":", 1)
+}
+
+// checkResponseTag ensures a response echoing a CTAG answers the command that was sent
+func checkResponseTag(response, tag string) error {
+	matches := ctagResponsePattern.FindStringSubmatch(response)
+	if len(matches) < 2 || matches[1] == tag {
+		return nil
+	}
+
+	return fmt.Errorf("%w: CTAG %s recebido, %s esperado", ErrCtagMismatch, matches[1], tag)
+}
@@ -0,0 +1,125 @@
+package unm
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	PonIDFormatFiberhome     = "fiberhome"
+	PonIDFormatFrameSlotPort = "frame-slot-port"
+)
+
+var ErrInvalidPonID = errors.New("identificador de PON inválido")
+
+// PonID addresses a PON interface of an OLT. Rack and frame only matter to vendors that number
+// them, Fiberhome chassis leave them out.
+type PonID struct {
+	Rack  uint
+	Frame uint
+	Slot  uint
+	Port  uint
+}
+
+// PonIDFormat renders and reads the PON identifier in the TL1 dialect of a vendor
+type PonIDFormat interface {
+	Format(id PonID) string
+	Parse(value string) (PonID, error)
+}
+
+// NewPonIDFormat returns the PON identifier format registered under the name, empty meaning Fiberhome
+func NewPonIDFormat(name string) (PonIDFormat, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "", PonIDFormatFiberhome:
+		return FiberhomePonIDFormat{}, nil
+	case PonIDFormatFrameSlotPort:
+		return FrameSlotPortPonIDFormat{}, nil
+	default:
+		return nil, fmt.Errorf("formato de PONID desconhecido: %s (use %s ou %s)", name, PonIDFormatFiberhome, PonIDFormatFrameSlotPort)
+	}
+}
+
+// FiberhomePonIDFormat writes "rack-frame-slot-port", with NA standing for the rack and frame
+// the chassis does not number, e.g. "NA-NA-1-4"
+type FiberhomePonIDFormat struct{}
+
+// Format renders the identifier, rack and frame zero read as NA
+func (FiberhomePonIDFormat) Format(id PonID) string {
+	return strings.Join([]string{
+		naField(id.Rack),
+		naField(id.Frame),
+		strconv.FormatUint(uint64(id.Slot), 10),
+		strconv.FormatUint(uint64(id.Port), 10),
+	}, "-")
+}
+
+// Parse reads "NA-NA-1-4" or a fully numbered "1-1-1-4"
+func (FiberhomePonIDFormat) Parse(value string) (PonID, error) {
+	fields := strings.Split(strings.TrimSpace(value), "-")
+	if len(fields) != 4 {
+		return PonID{}, fmt.Errorf("%w: %q", ErrInvalidPonID, value)
+	}
+
+	numbers, err := parsePonFields(value, fields, true)
+	if err != nil {
+		return PonID{}, err
+	}
+
+	return PonID{Rack: numbers[0], Frame: numbers[1], Slot: numbers[2], Port: numbers[3]}, nil
+}
+
+// FrameSlotPortPonIDFormat writes "frame/slot/port", as used by Huawei-style TL1 gateways, e.g. "0/1/4"
+type FrameSlotPortPonIDFormat struct{}
+
+// Format renders the identifier, the rack is not part of this notation
+func (FrameSlotPortPonIDFormat) Format(id PonID) string {
+	return fmt.Sprintf("%d/%d/%d", id.Frame, id.Slot, id.Port)
+}
+
+// Parse reads "0/1/4", a missing frame in "1/4" reads as frame zero
+func (FrameSlotPortPonIDFormat) Parse(value string) (PonID, error) {
+	fields := strings.Split(strings.TrimSpace(value), "/")
+	if len(fields) == 2 {
+		fields = append([]string{"0"}, fields...)
+	}
+	if len(fields) != 3 {
+		return PonID{}, fmt.Errorf("%w: %q", ErrInvalidPonID, value)
+	}
+
+	numbers, err := parsePonFields(value, fields, false)
+	if err != nil {
+		return PonID{}, err
+	}
+
+	return PonID{Frame: numbers[0], Slot: numbers[1], Port: numbers[2]}, nil
+}
+
+// naField renders an unnumbered field as NA
+func naField(value uint) string {
+	if value == 0 {
+		return "NA"
+	}
+	return strconv.FormatUint(uint64(value), 10)
+}
+
+// parsePonFields converts the identifier fields to numbers, NA reading as zero when allowed
+func parsePonFields(value string, fields []string, allowNA bool) ([]uint, error) {
+	numbers := make([]uint, len(fields))
+
+	for i, field := range fields {
+		field = strings.TrimSpace(field)
+		if allowNA && strings.EqualFold(field, "NA") {
+			continue
+		}
+
+		number, err := strconv.ParseUint(field, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidPonID, value)
+		}
+		numbers[i] = uint(number)
+	}
+
+	return numbers, nil
+}
@@ -0,0 +1,102 @@
+package unm
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// SimulatorVersion is the UNM version reported by the simulator, recent enough for every capability
+const SimulatorVersion = "V5.1"
+
+// Simulator is an in-process stand-in for the UNM that accepts every TL1 command,
+// used by training sessions so no command ever reaches a real OLT
+type Simulator struct {
+	connected bool
+}
+
+// NewSimulator creates a new simulated UNM transport
+func NewSimulator() *Simulator {
+	return &Simulator{}
+}
+
+// Close disconnects the simulator
+func (s *Simulator) Close() error {
+	s.connected = false
+	return nil
+}
+
+// Reconnect connects the simulator
+func (s *Simulator) Reconnect() error {
+	s.connected = true
+	return nil
+}
+
+// IsConnected reports whether the simulator is connected
+func (s *Simulator) IsConnected() bool {
+	return s.connected
+}
+
+// GetAddress identifies the simulator in logs and capability lookups
+func (s *Simulator) GetAddress() string {
+	return "simulador"
+}
+
+// Send answers a command with a successful response echoing its CTAG
+func (s *Simulator) Send(ctx context.Context, cmd string) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+
+	tag := simulatedTag(cmd)
+	header := fmt.Sprintf("\r\n\r\n   SIMULADOR %s\r\nM  %s COMPLD\r\n   EN=0   ENDESC=COMPLD\r\n", SimulatorVersion, tag)
+
+	switch {
+	case strings.HasPrefix(cmd, "LST-VERSION"):
+		return header + fmt.Sprintf("   VERSION=%s\r\n;", SimulatorVersion), nil
+	case strings.HasPrefix(cmd, "LST-OMDDM"):
+		return header + simulatedOpticalInfo, nil
+	case strings.HasPrefix(cmd, "LST-WIFINEIGHBOR"):
+		return header + simulatedWifiNeighbors, nil
+	default:
+		return header + ";", nil
+	}
+}
+
+// simulatedTag extracts the CTAG, the fourth colon-separated field of a TL1 command
+func simulatedTag(cmd string) string {
+	fields := strings.SplitN(cmd, ":", 5)
+	if len(fields) < 4 {
+		return ""
+	}
+	return fields[3]
+}
+
+// simulatedOpticalInfo completes the LST-OMDDM response with healthy optical levels,
+// laid out as HeaderLines lines before the data row and two footer lines after it
+var simulatedOpticalInfo = strings.Join([]string{
+	"   total_blocks=1",
+	"   block_number=1",
+	"   block_records=1",
+	"   ------------------------------------------------------------",
+	"ONUID\tRxPower\tRxPowerR\tTxPower\tTxPowerR\tCurrTxBias\tCurrTxBiasR\tTemperature\tTemperatureR\tVoltage\tVoltageR\tPTxPower\tPRxPower",
+	"SIM\t-19.50\tnormal\t2.30\tnormal\t12.00\tnormal\t45.00\tnormal\t3.30\tnormal\t4.10\t-21.00",
+	"   ------------------------------------------------------------",
+	";",
+}, "\r\n")
+
+// simulatedWifiNeighbors answers the Wi-Fi scan with a crowded 2.4 GHz band and a quiet 5 GHz one
+var simulatedWifiNeighbors = strings.Join([]string{
+	"   total_blocks=1",
+	"   block_number=1",
+	"   block_records=5",
+	"   ------------------------------------------------------------",
+	"SSID\tBSSID\tBAND\tCHANNEL\tRSSI",
+	"VIZINHO-1\t00:11:22:33:44:01\t2.4G\t1\t-48",
+	"VIZINHO-2\t00:11:22:33:44:02\t2.4G\t6\t-62",
+	"VIZINHO-3\t00:11:22:33:44:03\t2.4G\t1\t-70",
+	"VIZINHO-4\t00:11:22:33:44:04\t2.4G\t3\t-81",
+	"VIZINHO-5G\t00:11:22:33:44:05\t5G\t36\t-67",
+	"   ------------------------------------------------------------",
+	";",
+}, "\r\n")
@@ -0,0 +1,163 @@
+package unm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"provisioning-assistant/internal/domain"
+	"strings"
+	"time"
+)
+
+const (
+	OnuConfigCommand   = "LST-ONU::OLTID=%s,PONID=%s,ONUIDTYPE=MAC,ONUID=%s:CTAG::;"
+	WanServiceCommand  = "LST-WANSERVICE::OLTID=%s,PONID=%s,ONUIDTYPE=MAC,ONUID=%s:CTAG::;"
+	restoreStepTimeout = 30 * time.Second
+)
+
+var ErrOnuNotFound = errors.New("ONU não encontrada na OLT")
+
+// SnapshotOnu reads the OLT-side configuration of an ONU (name, model and WAN services) before it is replaced,
+// returning ErrOnuNotFound when the ONU is not authorized on the PON port
+func (us *UNMClient) SnapshotOnu(ctx context.Context, ponSlot, ponNumber uint, olt, physicalAddr string) (*domain.OnuSnapshot, error) {
+	var snapshot *domain.OnuSnapshot
+
+	return snapshot, us.execRetry(ctx, func(ctx context.Context) error {
+		response, err := us.sendCommand(ctx, fmt.Sprintf(OnuConfigCommand, olt, us.ponID(ponSlot, ponNumber), physicalAddr))
+		if err != nil {
+			// The UNM refuses the listing of an ONU that is not authorized
+			if strings.Contains(strings.ToLower(err.Error()), "not exist") {
+				return ErrOnuNotFound
+			}
+			return fmt.Errorf("falha ao consultar configuração da ONU: %w", err)
+		}
+
+		rows := parseTable(response, "NAME")
+		if len(rows) == 0 {
+			return ErrOnuNotFound
+		}
+
+		current := &domain.OnuSnapshot{
+			OltIP:      olt,
+			Slot:       ponSlot,
+			Port:       ponNumber,
+			Serial:     physicalAddr,
+			Name:       rows[0]["NAME"],
+			Model:      rows[0]["ONUTYPE"],
+			CapturedAt: time.Now(),
+		}
+
+		response, err = us.sendCommand(ctx, fmt.Sprintf(WanServiceCommand, olt, us.ponID(ponSlot, ponNumber), physicalAddr))
+		if err != nil {
+			return fmt.Errorf("falha ao consultar serviços WAN da ONU: %w", err)
+		}
+
+		for _, row := range parseTable(response, "VLAN") {
+			current.WanServices = append(current.WanServices, domain.WanServiceSnapshot{
+				Port:          row["BINDPORT"],
+				Vlan:          row["VLAN"],
+				PPPoEUser:     row["PPPOEUSER"],
+				PPPoEPassword: row["PPPOEPASSWD"],
+			})
+		}
+
+		snapshot = current
+		return nil
+	})
+}
+
+// RestoreOnu puts an ONU back exactly as captured by SnapshotOnu, replacing whatever is configured on the OLT now
+func (us *UNMClient) RestoreOnu(ctx context.Context, snapshot *domain.OnuSnapshot) error {
+	if snapshot == nil || snapshot.Serial == "" || snapshot.Model == "" {
+		return fmt.Errorf("%w: snapshot da ONU incompleto", ErrInvalidConfig)
+	}
+
+	config := OnuProvisioningConfig{
+		OltIP:   snapshot.OltIP,
+		PonSlot: snapshot.Slot,
+		PonPort: snapshot.Port,
+		Serial:  snapshot.Serial,
+		Name:    snapshot.Name,
+		Model:   snapshot.Model,
+	}
+
+	log := us.logger.WithFields(map[string]any{
+		"olt":    snapshot.OltIP,
+		"serial": snapshot.Serial,
+		"wan":    len(snapshot.WanServices),
+	})
+	log.Info("Restaurando configuração anterior da ONU")
+
+	return us.execRetry(ctx, func(ctx context.Context) error {
+		if err := us.deleteONU(ctx, config); err != nil {
+			us.logger.WithError(err).Debug("Falha ao deletar ONU antes da restauração (pode não existir)")
+		}
+
+		if err := us.addONU(ctx, config); err != nil {
+			return fmt.Errorf("falha ao restaurar ONU: %w", err)
+		}
+
+		for _, service := range snapshot.WanServices {
+			wan := config
+			wan.Vlan = service.Vlan
+			wan.PPPoEUser = service.PPPoEUser
+			wan.PPPoEPass = service.PPPoEPassword
+
+			if err := us.setWanService(ctx, wan, service.Port); err != nil {
+				return fmt.Errorf("falha ao restaurar serviço WAN %s: %w", service.Port, err)
+			}
+		}
+
+		if len(snapshot.WanServices) > 0 {
+			if err := us.activateLanPort(ctx, config); err != nil {
+				return fmt.Errorf("falha ao restaurar porta LAN: %w", err)
+			}
+		}
+
+		log.Info("Configuração anterior da ONU restaurada")
+		return nil
+	})
+}
+
+// RestoreContext detaches a restoration from the cancellation of the job that triggered it,
+// an aborted provisioning still has to put the previous configuration back
+func RestoreContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.WithoutCancel(ctx), restoreStepTimeout)
+}
+
+// parseTable reads a tab-separated TL1 listing into rows keyed by the upper-cased header,
+// the header row is the first one holding the key column
+func parseTable(response, keyColumn string) []map[string]string {
+	lines := splitAndTrimLines(strings.ReplaceAll(response, "\r", ""))
+
+	var header []string
+	var rows []map[string]string
+
+	for _, line := range lines {
+		fields := strings.Split(line, "\t")
+
+		if header == nil {
+			for _, field := range fields {
+				if strings.EqualFold(strings.TrimSpace(field), keyColumn) {
+					header = fields
+					break
+				}
+			}
+			continue
+		}
+
+		if len(fields) < 2 {
+			continue
+		}
+
+		row := make(map[string]string, len(header))
+		for i, name := range header {
+			if i < len(fields) {
+				row[strings.ToUpper(strings.TrimSpace(name))] = strings.TrimSpace(fields[i])
+			}
+		}
+		rows = append(rows, row)
+	}
+
+	return rows
+}
@@ -0,0 +1,47 @@
+package unm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// DefaultStepTimeouts bound each TL1 step of a job so a hung command fails its own step instead of using up
+// the time left for the next ones, the deadline of the whole job still applies on top of them
+var DefaultStepTimeouts = StepTimeouts{
+	Login: 10 * time.Second,
+	Add:   20 * time.Second,
+	Wan:   20 * time.Second,
+	Lan:   10 * time.Second,
+	Info:  15 * time.Second,
+}
+
+// ErrStepTimeout reports a TL1 step whose commands ran past the timeout of its type
+var ErrStepTimeout = errors.New("etapa TL1 excedeu o tempo limite")
+
+// StepTimeouts bound the TL1 steps by type, a zero timeout is not enforced. Add covers the registration of
+// the ONU with its bandwidth profile, Wan every WAN service and Info the optical queries.
+type StepTimeouts struct {
+	Login time.Duration
+	Add   time.Duration
+	Wan   time.Duration
+	Lan   time.Duration
+	Info  time.Duration
+}
+
+// withStepTimeout bounds the commands of a step, the context ends with ErrStepTimeout naming the step
+func withStepTimeout(ctx context.Context, step string, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeoutCause(ctx, timeout, fmt.Errorf("%w: %s após %s", ErrStepTimeout, step, timeout))
+}
+
+// stepExpired returns the timeout error of the step once the context ran past it
+func stepExpired(ctx context.Context) error {
+	if cause := context.Cause(ctx); errors.Is(cause, ErrStepTimeout) {
+		return cause
+	}
+	return nil
+}
@@ -5,9 +5,13 @@ import (
 	"errors"
 	"fmt"
 	"provisioning-assistant/internal/domain"
+	"provisioning-assistant/internal/tl1"
 	"regexp"
+	"slices"
 	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 )
 
 const (
@@ -18,11 +22,18 @@ const (
 
 	LoginCommand           = "LOGIN:::CTAG::UN=%s,PWD=%s;"
 	LogoutCommand          = "LOGOUT:::CTAG::;"
-	OnuInfoCommand         = "LST-OMDDM::OLTID=%s,PONID=NA-NA-%d-%d,ONUIDTYPE=MAC,ONUID=%s:CTAG::;"
-	DeleteOnuCommand       = "DEL-ONU::OLTID=%s,PONID=NA-NA-%d-%d:CTAG::ONUIDTYPE=MAC,ONUID=%s;"
-	AddOnuCommand          = "ADD-ONU::OLTID=%s,PONID=NA-NA-%d-%d:CTAG::AUTHTYPE=MAC,ONUID=%s,NAME=%s | %s - %s,ONUTYPE=%s;"
-	SetWanServiceCommand   = "SET-WANSERVICE::OLTID=%s,PONID=NA-NA-%d-%d,ONUIDTYPE=MAC,ONUID=%s:CTAG::STATUS=1,MODE=3,CONNTYPE=2,VLAN=%s,COS=0,QOS=2,NAT=1,IPMODE=3,IPSTACKMODE=1,IP6SRCTYPE=0,PPPOEPROXY=2,PPPOEUSER=%s,PPPOEPASSWD=%s,PPPOENAME=%s,PPPOEMODE=1,%s;"
-	ActivateLanPortCommand = "ACT-LANPORT::OLTID=%s,PONID=NA-NA-%d-%d,ONUIDTYPE=MAC,ONUID=%s,ONUPORT=NA-NA-NA-1:CTAG::;"
+	VersionCommand         = "LST-VERSION:::CTAG::;"
+	OnuInfoCommand         = "LST-OMDDM::OLTID=%s,PONID=%s,ONUIDTYPE=MAC,ONUID=%s:CTAG::;"
+	PonOpticalInfoCommand  = "LST-OMDDM::OLTID=%s,PONID=%s:CTAG::;"
+	DeleteOnuCommand       = "DEL-ONU::OLTID=%s,PONID=%s:CTAG::ONUIDTYPE=MAC,ONUID=%s;"
+	AddOnuCommand          = "ADD-ONU::OLTID=%s,PONID=%s:CTAG::AUTHTYPE=MAC,ONUID=%s,NAME=%s,ONUTYPE=%s;"
+	SetWanServiceCommand   = "SET-WANSERVICE::OLTID=%s,PONID=%s,ONUIDTYPE=MAC,ONUID=%s:CTAG::STATUS=1,MODE=3,CONNTYPE=2,VLAN=%s,COS=0,QOS=2,NAT=1,IPMODE=3,IPSTACKMODE=1,IP6SRCTYPE=0,PPPOEPROXY=2,PPPOEUSER=%s,PPPOEPASSWD=%s,PPPOENAME=%s,PPPOEMODE=1,%s;"
+	SetBridgeWanCommand    = "SET-WANSERVICE::OLTID=%s,PONID=%s,ONUIDTYPE=MAC,ONUID=%s:CTAG::STATUS=1,MODE=4,CONNTYPE=1,VLAN=%s,COS=0,QOS=2,%s;"
+	ActivateLanPortCommand = "ACT-LANPORT::OLTID=%s,PONID=%s,ONUIDTYPE=MAC,ONUID=%s,ONUPORT=NA-NA-NA-1:CTAG::;"
+	SetBandwidthCommand    = "CFG-ONUBW::OLTID=%s,PONID=%s,ONUIDTYPE=MAC,ONUID=%s:CTAG::UPBW=%s,DOWNBW=%s;"
+	SetDBAProfileCommand   = "CFG-ONUDBA::OLTID=%s,PONID=%s,ONUIDTYPE=MAC,ONUID=%s:CTAG::DBAPROFILE=%s;"
+	SetIgmpModeCommand     = "CFG-ONUIGMP::OLTID=%s,PONID=%s,ONUIDTYPE=MAC,ONUID=%s:CTAG::MODE=SNOOPING;"
+	AddIptvPortCommand     = "ADD-LANIPTVPORT::OLTID=%s,PONID=%s,ONUIDTYPE=MAC,ONUID=%s,ONUPORT=NA-NA-NA-%s:CTAG::MVLAN=%s,MCOS=0;"
 
 	MaxRetryAttempts = 3
 )
@@ -35,12 +46,16 @@ var (
 	ErrIllegalSession           = errors.New("sessão ilegal")
 	ErrMaxRetriesExceeded       = errors.New("número máximo de tentativas excedido")
 	ErrInvalidConfig            = errors.New("configuração de provisionamento inválida")
+	ErrNoServicePorts           = errors.New("nenhuma porta definida para o serviço")
+	ErrMulticastUnsupported     = errors.New("modelo da ONU não suporta multicast")
+	ErrServerError              = errors.New("erro do servidor UNM")
 )
 
 type Transporter interface {
 	Close() error
 	Reconnect() error
 	IsConnected() bool
+	GetAddress() string
 	Send(ctx context.Context, cmd string) (string, error)
 }
 
@@ -52,42 +67,240 @@ type OnuProvisioningConfig struct {
 	SplitterName string
 	SplitterPort string
 	ClientName   string
+	Name         string
 	Model        string
 	Vlan         string
 	PPPoEUser    string
 	PPPoEPass    string
+
+	// Plan template parameters
+	UpstreamProfile   string
+	DownstreamProfile string
+	DBAProfile        string
+	WanPorts          []string
+
+	// Other WAN connections of the contract, configured after the PPPoE data service
+	ExtraWanServices []WanService
+
+	// IPTV multicast VLAN joined by the LAN ports, left unconfigured when empty
+	MulticastVlan  string
+	MulticastPorts []string
+
+	// Reconfigure keeps the ONU already authorized on the PON and only reapplies its services
+	Reconfigure bool
+}
+
+// WanService is an additional WAN connection of the ONU, such as an IPTV or VoIP VLAN,
+// bridged when it has no PPPoE user
+type WanService struct {
+	Name      string
+	Vlan      string
+	PPPoEUser string
+	PPPoEPass string
+	Ports     []string
+}
+
+// DefaultWanPorts lists the LAN ports and SSIDs bound to the WAN service by default
+var DefaultWanPorts = []string{
+	"UPORT=1",
+	"UPORT=2",
+	"UPORT=3",
+	"UPORT=4",
+	"SSID=1",
+	"SSID=5",
+}
+
+// ResponseDump is a TL1 response the client could not understand, kept to check the parser against it
+type ResponseDump struct {
+	Endpoint string
+	Command  string
+	Response string
+	Err      error
+	At       time.Time
 }
 
 type UNMClient struct {
-	username    string
-	password    string
+	credentials CredentialProvider
 	transporter Transporter
 	mtx         sync.Mutex
 	connected   bool
 	logger      domain.Logger
 	errorRegex  *regexp.Regexp
+	sequence    atomic.Uint64
+	ponIDFormat PonIDFormat
+	watchdog    watchdog
+	connecting  atomic.Bool
+	unreachable atomic.Bool
+	errorHook   func(error)
+	latencyHook func(time.Duration)
+	dumpHook    func(ResponseDump)
+	timeouts    StepTimeouts
+
+	capabilities map[string]Capabilities
+	capMtx       sync.RWMutex
 }
 
 // New creates a new UNM client instance
 func New(username, password string, transporter Transporter, logger domain.Logger) *UNMClient {
 	return &UNMClient{
-		username:    username,
-		password:    password,
+		credentials: StaticCredentials{Username: username, Password: password},
 		logger:      logger,
 		transporter: transporter,
 		errorRegex:  regexp.MustCompile(ErrorPattern),
+		ponIDFormat: FiberhomePonIDFormat{},
+		timeouts:    DefaultStepTimeouts,
+
+		capabilities: make(map[string]Capabilities),
+	}
+}
+
+// SetPonIDFormat selects how PON interfaces are written in the commands, nil keeps the Fiberhome default
+func (us *UNMClient) SetPonIDFormat(format PonIDFormat) {
+	if format != nil {
+		us.ponIDFormat = format
+	}
+}
+
+// SetStepTimeouts bounds the TL1 steps by type, it must be set before the client is used
+func (us *UNMClient) SetStepTimeouts(timeouts StepTimeouts) {
+	us.timeouts = timeouts
+}
+
+// SetCredentialProvider reads the accounts of each endpoint from the provider instead of the credentials
+// given to New, nil keeps them
+func (us *UNMClient) SetCredentialProvider(provider CredentialProvider) {
+	if provider != nil {
+		us.credentials = provider
 	}
 }
 
-// Login authenticates with the UNM server
+// ponID writes the slot and port of a PON interface in the configured format
+func (us *UNMClient) ponID(slot, port uint) string {
+	return us.ponIDFormat.Format(PonID{Slot: slot, Port: port})
+}
+
+// Login authenticates with the UNM server. During a password rotation the other credential of the endpoint
+// is tried when the server refuses the preferred one.
 func (us *UNMClient) Login(ctx context.Context) error {
-	command := fmt.Sprintf(LoginCommand, us.username, us.password)
+	endpoint := us.transporter.GetAddress()
+	candidates := us.credentials.Credentials(endpoint).Candidates(time.Now())
+
+	var err error
+	for i, credential := range candidates {
+		if _, err = us.sendCommand(ctx, fmt.Sprintf(LoginCommand, credential.Username, credential.Password)); err == nil {
+			if i > 0 {
+				us.logger.WithFields(map[string]any{
+					"endpoint": endpoint,
+					"username": credential.Username,
+				}).Warn("Login no UNM com a credencial alternativa da rotação")
+			}
+			return nil
+		}
 
-	if _, err := us.sendCommand(ctx, command); err != nil {
-		return fmt.Errorf("falha no login: %w", err)
+		// Only a refused account is worth the other credential, a lost connection fails both
+		if !errors.Is(err, ErrServerError) {
+			break
+		}
 	}
 
-	return nil
+	return fmt.Errorf("falha no login: %w", err)
+}
+
+// Ping checks the UNM server answers a harmless query, reconnecting when the session was lost
+func (us *UNMClient) Ping(ctx context.Context) error {
+	return us.execRetry(ctx, func(ctx context.Context) error {
+		_, err := us.sendCommand(ctx, VersionCommand)
+		return err
+	})
+}
+
+// Capabilities returns the features detected on the current UNM endpoint
+func (us *UNMClient) Capabilities() Capabilities {
+	us.capMtx.RLock()
+	defer us.capMtx.RUnlock()
+
+	caps, exists := us.capabilities[us.transporter.GetAddress()]
+	if !exists {
+		return newCapabilities("")
+	}
+	return caps
+}
+
+// discoverCapabilities queries the UNM version after login and stores the endpoint capabilities
+func (us *UNMClient) discoverCapabilities(ctx context.Context) {
+	endpoint := us.transporter.GetAddress()
+	log := us.logger.WithField("endpoint", endpoint)
+
+	version := ""
+	response, err := us.sendCommand(ctx, VersionCommand)
+	if err != nil {
+		log.WithError(err).Warn("Falha ao consultar versão do UNM, usando capacidades básicas")
+	} else {
+		version = parseVersion(response)
+	}
+
+	caps := newCapabilities(version)
+
+	us.capMtx.Lock()
+	us.capabilities[endpoint] = caps
+	us.capMtx.Unlock()
+
+	log.WithFields(map[string]any{
+		"version":      caps.Version,
+		"capabilities": caps.List(),
+	}).Info("Capacidades do UNM detectadas")
+}
+
+// SetTransportErrorHook calls the hook with every failure to reach the UNM server, it must be set
+// before the client is used
+func (us *UNMClient) SetTransportErrorHook(hook func(error)) {
+	us.errorHook = hook
+}
+
+// SetLatencyHook calls the hook with the time each command took to be answered, waiting for the connection
+// included, it must be set before the client is used
+func (us *UNMClient) SetLatencyHook(hook func(time.Duration)) {
+	us.latencyHook = hook
+}
+
+// SetResponseDumpHook calls the hook with every response the client could not understand, its command
+// redacted, it must be set before the client is used
+func (us *UNMClient) SetResponseDumpHook(hook func(ResponseDump)) {
+	us.dumpHook = hook
+}
+
+// dumpResponse hands a response the client could not understand to the hook, if any
+func (us *UNMClient) dumpResponse(command, response string, err error) {
+	if us.dumpHook == nil {
+		return
+	}
+
+	us.dumpHook(ResponseDump{
+		Endpoint: us.transporter.GetAddress(),
+		Command:  RedactCommand(command),
+		Response: response,
+		Err:      err,
+		At:       time.Now(),
+	})
+}
+
+// reportTransportError hands a connection failure to the hook, if any
+func (us *UNMClient) reportTransportError(err error) {
+	if us.errorHook != nil {
+		us.errorHook(err)
+	}
+}
+
+// commandVerb returns the verb of a TL1 command, the only part safe to report since the
+// parameters may carry passwords
+func commandVerb(command string) string {
+	verb, _, _ := strings.Cut(command, ":")
+	return verb
+}
+
+// Reachable reports whether the last attempt to reach the UNM server got an answer
+func (us *UNMClient) Reachable() bool {
+	return !us.unreachable.Load()
 }
 
 // Logout logs out from the UNM server
@@ -116,7 +329,10 @@ func (us *UNMClient) OnuInfo(ctx context.Context, ponSlot, ponNumber uint, olt,
 	var result *OpticalNetworkUnitInfo
 
 	return result, us.execRetry(ctx, func(ctx context.Context) error {
-		command := fmt.Sprintf(OnuInfoCommand, olt, ponSlot, ponNumber, physicalAddr)
+		ctx, cancel := withStepTimeout(ctx, "tl1_onu_info", us.timeouts.Info)
+		defer cancel()
+
+		command := fmt.Sprintf(OnuInfoCommand, olt, us.ponID(ponSlot, ponNumber), physicalAddr)
 
 		response, err := us.sendCommand(ctx, command)
 		if err != nil {
@@ -125,6 +341,7 @@ func (us *UNMClient) OnuInfo(ctx context.Context, ponSlot, ponNumber uint, olt,
 
 		onuInfo, err := us.buildONUInfoFromResponse(response)
 		if err != nil {
+			us.dumpResponse(command, response, err)
 			return fmt.Errorf("falha ao interpretar resposta das informações da ONU: %w", err)
 		}
 
@@ -133,37 +350,117 @@ func (us *UNMClient) OnuInfo(ctx context.Context, ponSlot, ponNumber uint, olt,
 	})
 }
 
-// OnuProvisioning orchestrates the complete ONU provisioning process
-func (us *UNMClient) OnuProvisioning(ctx context.Context, config OnuProvisioningConfig) error {
+// PonOpticalInfo retrieves the optical information of every ONU of a PON in a single command, keyed by
+// the uppercase serial. Sweeps over dense PONs use it instead of one OnuInfo per ONU.
+func (us *UNMClient) PonOpticalInfo(ctx context.Context, ponSlot, ponNumber uint, olt string) (map[string]*OpticalNetworkUnitInfo, error) {
+	var result map[string]*OpticalNetworkUnitInfo
+
+	return result, us.execRetry(ctx, func(ctx context.Context) error {
+		ctx, cancel := withStepTimeout(ctx, "tl1_pon_info", us.timeouts.Info)
+		defer cancel()
+
+		command := fmt.Sprintf(PonOpticalInfoCommand, olt, us.ponID(ponSlot, ponNumber))
+
+		response, err := us.sendCommand(ctx, command)
+		if err != nil {
+			return fmt.Errorf("falha ao consultar informações ópticas da PON: %w", err)
+		}
+
+		result = parsePonOpticalInfo(response)
+		return nil
+	})
+}
+
+// OnuProvisioning orchestrates the complete ONU provisioning process and returns the duration of each TL1 step
+// with the outcome of every WAN service. Only the data service is required, the extra ones are reported.
+func (us *UNMClient) OnuProvisioning(ctx context.Context, config OnuProvisioningConfig) ([]domain.StepTiming, []domain.WanServiceStatus, error) {
 	if err := us.validateProvisioningConfig(config); err != nil {
-		return fmt.Errorf("configuração de provisionamento inválida: %w", err)
+		return nil, nil, fmt.Errorf("configuração de provisionamento inválida: %w", err)
 	}
 
-	return us.execRetry(ctx, func(ctx context.Context) error {
-		if err := us.deleteONU(ctx, config); err != nil {
-			us.logger.WithError(err).Debug("Falha ao deletar ONU (pode não existir)")
+	var steps []domain.StepTiming
+	var services []domain.WanServiceStatus
+
+	err := us.execRetry(ctx, func(ctx context.Context) error {
+		// A retried attempt runs every step again, only its timings are kept
+		steps = steps[:0]
+		services = services[:0]
+
+		if !config.Reconfigure {
+			if err := us.timeStep(ctx, &steps, "tl1_delete_onu", us.timeouts.Add, func(ctx context.Context) error { return us.deleteONU(ctx, config) }); err != nil {
+				us.logger.WithError(err).Debug("Falha ao deletar ONU (pode não existir)")
+			}
+
+			if err := us.timeStep(ctx, &steps, "tl1_add_onu", us.timeouts.Add, func(ctx context.Context) error { return us.addONU(ctx, config) }); err != nil {
+				return fmt.Errorf("falha ao adicionar ONU: %w", err)
+			}
 		}
 
-		if err := us.addONU(ctx, config); err != nil {
-			return fmt.Errorf("falha ao adicionar ONU: %w", err)
+		if err := us.timeStep(ctx, &steps, "tl1_bandwidth", us.timeouts.Add, func(ctx context.Context) error { return us.configureBandwidth(ctx, config) }); err != nil {
+			return fmt.Errorf("falha ao configurar perfil de banda: %w", err)
 		}
 
-		if err := us.configureWanServices(ctx, config); err != nil {
+		if err := us.timeStep(ctx, &steps, "tl1_wan_services", us.timeouts.Wan, func(ctx context.Context) error { return us.configureWanServices(ctx, config) }); err != nil {
 			return fmt.Errorf("falha ao configurar serviços WAN: %w", err)
 		}
+		services = append(services, domain.WanServiceStatus{Vlan: config.Vlan, Primary: true})
+
+		if len(config.ExtraWanServices) > 0 {
+			_ = us.timeStep(ctx, &steps, "tl1_extra_wan_services", us.timeouts.Wan, func(ctx context.Context) error {
+				services = append(services, us.configureExtraWanServices(ctx, config)...)
+				return nil
+			})
+		}
+
+		if config.MulticastVlan != "" {
+			_ = us.timeStep(ctx, &steps, "tl1_multicast", us.timeouts.Wan, func(ctx context.Context) error {
+				services = append(services, us.configureMulticast(ctx, config))
+				return nil
+			})
+		}
 
-		if err := us.activateLanPort(ctx, config); err != nil {
+		if err := us.timeStep(ctx, &steps, "tl1_lan_port", us.timeouts.Lan, func(ctx context.Context) error { return us.activateLanPort(ctx, config) }); err != nil {
 			return fmt.Errorf("falha ao ativar porta LAN: %w", err)
 		}
 
 		us.logger.WithFields(map[string]any{
-			"olt":    config.OltIP,
-			"serial": config.Serial,
-			"client": config.ClientName,
+			"olt":         config.OltIP,
+			"serial":      config.Serial,
+			"client":      config.ClientName,
+			"reconfigure": config.Reconfigure,
 		}).Info("Provisionamento da ONU concluído com sucesso")
 
 		return nil
 	})
+
+	return steps, services, err
+}
+
+// DiscardOnu removes an ONU registration, such as one left half configured by an aborted job
+func (us *UNMClient) DiscardOnu(ctx context.Context, config OnuProvisioningConfig) error {
+	us.logger.WithFields(map[string]any{
+		"olt":    config.OltIP,
+		"serial": config.Serial,
+	}).Warn("Removendo ONU da OLT")
+
+	return us.execRetry(ctx, func(ctx context.Context) error {
+		return us.deleteONU(ctx, config)
+	})
+}
+
+// timeStep runs a provisioning step within its timeout, logging and recording its duration
+func (us *UNMClient) timeStep(ctx context.Context, steps *[]domain.StepTiming, name string, timeout time.Duration, step func(ctx context.Context) error) error {
+	stepCtx, cancel := withStepTimeout(ctx, name, timeout)
+	defer cancel()
+
+	started := time.Now()
+	err := step(stepCtx)
+	duration := time.Since(started)
+
+	domain.Benchmark(us.logger, name, duration)
+	*steps = append(*steps, domain.StepTiming{Name: name, Duration: duration})
+
+	return err
 }
 
 // isIllegalSessionError checks if the error indicates an illegal session
@@ -179,6 +476,10 @@ func (us *UNMClient) execRetry(ctx context.Context, operation func(ctx context.C
 	var lastErr error
 
 	for attempt := range MaxRetryAttempts {
+		if err := ctx.Err(); err != nil {
+			return fmt.Errorf("operação cancelada: %w", err)
+		}
+
 		if err := us.ensureConnection(ctx); err != nil {
 			lastErr = err
 			continue
@@ -208,12 +509,74 @@ func (us *UNMClient) execRetry(ctx context.Context, operation func(ctx context.C
 }
 
 // sendCommand sends a command to the UNM server and validates the response
-func (us *UNMClient) sendCommand(ctx context.Context, command string) (string, error) {
+func (us *UNMClient) sendCommand(ctx context.Context, command string) (_ string, err error) {
+	if ClassifyCommand(command) == BlastRadiusMultiONU {
+		approvalID, approved := ApprovalFrom(ctx)
+		if !approved {
+			us.logger.WithField("command", RedactCommand(command)).Warn("Comando de múltiplas ONUs bloqueado sem aprovação")
+			return "", ErrApprovalRequired
+		}
+		us.logger.WithFields(map[string]any{
+			"command":     RedactCommand(command),
+			"approval_id": approvalID,
+		}).Warn("Executando comando aprovado de múltiplas ONUs")
+	}
+
+	if err := spendBudget(ctx); err != nil {
+		us.logger.WithError(err).WithField("command", RedactCommand(command)).Warn("Comando TL1 bloqueado pelo orçamento do job")
+		return "", err
+	}
+
+	origin := originFrom(ctx)
+	tag := commandTag(origin, us.sequence.Add(1))
+	sentAt := time.Now()
+	defer func(untagged string) { recordCommand(ctx, untagged, tag, sentAt, err) }(command)
+	command = tagCommand(command, tag)
+
+	log := us.logger.WithFields(map[string]any{
+		"ctag":    tag,
+		"user_id": origin.UserID,
+		"job_id":  origin.JobID,
+	})
+	log.Debug("Enviando comando TL1")
+
 	response, err := us.transporter.Send(ctx, command)
+	if us.latencyHook != nil && err == nil {
+		us.latencyHook(time.Since(sentAt))
+	}
+	if budgetErr := budgetExpired(ctx); err != nil && budgetErr != nil {
+		// The job ran out of time, the connection is not to blame
+		return "", budgetErr
+	}
+	us.observeCommand(ctx, err)
+	if !errors.Is(err, context.Canceled) {
+		us.unreachable.Store(err != nil)
+	}
+	if errors.Is(err, tl1.ErrResponseTooLarge) {
+		// The transport dropped the connection to resync, the next command must log in again
+		log.WithError(err).Error("Resposta TL1 acima do limite, conexão descartada")
+		us.mtx.Lock()
+		us.connected = false
+		us.mtx.Unlock()
+	}
 	if err != nil {
+		if !errors.Is(err, context.Canceled) {
+			us.reportTransportError(fmt.Errorf("falha no comando %s: %w", commandVerb(command), err))
+		}
+		if stepErr := stepExpired(ctx); stepErr != nil {
+			// A hung command fails its own step, the time left is kept for the rest of the job
+			log.WithError(stepErr).Warn("Comando TL1 excedeu o tempo limite da etapa")
+			return "", stepErr
+		}
 		return "", fmt.Errorf("falha no comando: %w", err)
 	}
 
+	if err := checkResponseTag(response, tag); err != nil {
+		log.WithError(err).Error("Resposta TL1 de outro comando, sessão do UNM possivelmente compartilhada")
+		us.dumpResponse(command, response, err)
+		return "", err
+	}
+
 	if err := us.isResponseErr(response); err != nil {
 		return "", err
 	}
@@ -249,7 +612,16 @@ func (us *UNMClient) ensureConnection(ctx context.Context) error {
 
 // reconnectAndLogin handles the reconnection and login process
 func (us *UNMClient) reconnectAndLogin(ctx context.Context) error {
+	// Timeouts while connecting are the connection attempt failing, not a wedged session
+	us.connecting.Store(true)
+	defer us.connecting.Store(false)
+
+	ctx, cancel := withStepTimeout(ctx, "tl1_login", us.timeouts.Login)
+	defer cancel()
+
 	if err := us.transporter.Reconnect(); err != nil {
+		us.unreachable.Store(true)
+		us.reportTransportError(fmt.Errorf("falha na reconexão com %s: %w", us.transporter.GetAddress(), err))
 		return fmt.Errorf("falha na reconexão: %w", err)
 	}
 
@@ -258,6 +630,7 @@ func (us *UNMClient) reconnectAndLogin(ctx context.Context) error {
 		return fmt.Errorf("falha no login após reconexão: %w", err)
 	}
 
+	us.discoverCapabilities(ctx)
 	return nil
 }
 
@@ -266,7 +639,7 @@ func (us *UNMClient) isResponseErr(response string) error {
 	if matches := us.errorRegex.FindStringSubmatch(response); len(matches) > 1 {
 		errorMsg := strings.TrimSpace(matches[1])
 		if errorMsg != "" {
-			return fmt.Errorf("erro do servidor UNM: %s", errorMsg)
+			return fmt.Errorf("%w: %s", ErrServerError, errorMsg)
 		}
 	}
 
@@ -320,8 +693,7 @@ func (us *UNMClient) validateProvisioningConfig(config OnuProvisioningConfig) er
 func (us *UNMClient) deleteONU(ctx context.Context, config OnuProvisioningConfig) error {
 	command := fmt.Sprintf(DeleteOnuCommand,
 		config.OltIP,
-		config.PonSlot,
-		config.PonPort,
+		us.ponID(config.PonSlot, config.PonPort),
 		config.Serial,
 	)
 
@@ -342,12 +714,9 @@ func (us *UNMClient) deleteONU(ctx context.Context, config OnuProvisioningConfig
 func (us *UNMClient) addONU(ctx context.Context, config OnuProvisioningConfig) error {
 	command := fmt.Sprintf(AddOnuCommand,
 		config.OltIP,
-		config.PonSlot,
-		config.PonPort,
+		us.ponID(config.PonSlot, config.PonPort),
 		config.Serial,
-		config.SplitterName,
-		config.SplitterPort,
-		config.ClientName,
+		config.Name,
 		config.Model,
 	)
 
@@ -355,6 +724,7 @@ func (us *UNMClient) addONU(ctx context.Context, config OnuProvisioningConfig) e
 		"olt":    config.OltIP,
 		"serial": config.Serial,
 		"client": config.ClientName,
+		"name":   config.Name,
 		"model":  config.Model,
 	}).Debug("Adicionando ONU")
 
@@ -366,18 +736,73 @@ func (us *UNMClient) addONU(ctx context.Context, config OnuProvisioningConfig) e
 	return nil
 }
 
-// configureWanServices configures WAN services for all ports and SSIDs
+// configureBandwidth assigns the plan bandwidth and DBA profiles when defined
+func (us *UNMClient) configureBandwidth(ctx context.Context, config OnuProvisioningConfig) error {
+	if !us.Capabilities().Has(CapabilityBandwidth) {
+		if config.UpstreamProfile != "" || config.DownstreamProfile != "" || config.DBAProfile != "" {
+			us.logger.WithFields(map[string]any{
+				"olt":    config.OltIP,
+				"serial": config.Serial,
+			}).Warn("UNM não suporta perfis de banda, etapa ignorada")
+		}
+		return nil
+	}
+
+	if config.UpstreamProfile != "" || config.DownstreamProfile != "" {
+		if config.UpstreamProfile == "" || config.DownstreamProfile == "" {
+			return fmt.Errorf("%w: perfis de upload e download devem ser informados juntos", ErrInvalidConfig)
+		}
+
+		command := fmt.Sprintf(SetBandwidthCommand,
+			config.OltIP,
+			us.ponID(config.PonSlot, config.PonPort),
+			config.Serial,
+			config.UpstreamProfile,
+			config.DownstreamProfile,
+		)
+
+		us.logger.WithFields(map[string]any{
+			"olt":        config.OltIP,
+			"serial":     config.Serial,
+			"upstream":   config.UpstreamProfile,
+			"downstream": config.DownstreamProfile,
+		}).Debug("Configurando perfil de banda")
+
+		if _, err := us.sendCommand(ctx, command); err != nil {
+			return fmt.Errorf("falha ao configurar banda: %w", err)
+		}
+	}
+
+	if config.DBAProfile != "" {
+		command := fmt.Sprintf(SetDBAProfileCommand,
+			config.OltIP,
+			us.ponID(config.PonSlot, config.PonPort),
+			config.Serial,
+			config.DBAProfile,
+		)
+
+		us.logger.WithFields(map[string]any{
+			"olt":    config.OltIP,
+			"serial": config.Serial,
+			"dba":    config.DBAProfile,
+		}).Debug("Atribuindo perfil DBA")
+
+		if _, err := us.sendCommand(ctx, command); err != nil {
+			return fmt.Errorf("falha ao atribuir perfil DBA: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// configureWanServices configures the PPPoE data service for all ports and SSIDs
 func (us *UNMClient) configureWanServices(ctx context.Context, config OnuProvisioningConfig) error {
-	portConfigs := []string{
-		"UPORT=1",
-		"UPORT=2",
-		"UPORT=3",
-		"UPORT=4",
-		"SSID=1",
-		"SSID=5",
+	portConfigs := config.WanPorts
+	if len(portConfigs) == 0 {
+		portConfigs = DefaultWanPorts
 	}
 
-	for _, portConfig := range portConfigs {
+	for _, portConfig := range us.supportedPorts(config.Serial, portConfigs) {
 		if err := us.setWanService(ctx, config, portConfig); err != nil {
 			return fmt.Errorf("falha ao configurar serviço WAN para %s: %w", portConfig, err)
 		}
@@ -386,12 +811,61 @@ func (us *UNMClient) configureWanServices(ctx context.Context, config OnuProvisi
 	return nil
 }
 
+// configureExtraWanServices configures every extra WAN service on its own ports, a failed service
+// is reported and does not stop the others
+func (us *UNMClient) configureExtraWanServices(ctx context.Context, config OnuProvisioningConfig) []domain.WanServiceStatus {
+	statuses := make([]domain.WanServiceStatus, 0, len(config.ExtraWanServices))
+
+	for _, service := range config.ExtraWanServices {
+		status := domain.WanServiceStatus{Name: service.Name, Vlan: service.Vlan}
+
+		portConfigs := us.supportedPorts(config.Serial, service.Ports)
+		if len(portConfigs) == 0 {
+			status.Err = ErrNoServicePorts.Error()
+		}
+
+		for _, portConfig := range portConfigs {
+			if err := us.setExtraWanService(ctx, config, service, portConfig); err != nil {
+				status.Err = fmt.Sprintf("%s: %v", portConfig, err)
+				break
+			}
+		}
+
+		if status.Err != "" {
+			us.logger.WithFields(map[string]any{
+				"serial":  config.Serial,
+				"service": service.Name,
+				"vlan":    service.Vlan,
+				"error":   status.Err,
+			}).Warn("Falha ao configurar serviço WAN adicional")
+		}
+
+		statuses = append(statuses, status)
+	}
+
+	return statuses
+}
+
+// supportedPorts drops the SSIDs when the UNM can't configure Wi-Fi
+func (us *UNMClient) supportedPorts(serial string, portConfigs []string) []string {
+	if us.Capabilities().Has(CapabilityWifi) {
+		return portConfigs
+	}
+
+	supported := slices.DeleteFunc(slices.Clone(portConfigs), func(portConfig string) bool {
+		return strings.HasPrefix(portConfig, "SSID=")
+	})
+	if len(supported) < len(portConfigs) {
+		us.logger.WithField("serial", serial).Warn("UNM não suporta configuração Wi-Fi, SSIDs ignorados")
+	}
+	return supported
+}
+
 // setWanService configures a WAN service for a specific port
 func (us *UNMClient) setWanService(ctx context.Context, config OnuProvisioningConfig, portConfig string) error {
 	command := fmt.Sprintf(SetWanServiceCommand,
 		config.OltIP,
-		config.PonSlot,
-		config.PonPort,
+		us.ponID(config.PonSlot, config.PonPort),
 		config.Serial,
 		config.Vlan,
 		config.PPPoEUser,
@@ -415,12 +889,49 @@ func (us *UNMClient) setWanService(ctx context.Context, config OnuProvisioningCo
 	return nil
 }
 
+// setExtraWanService configures an extra WAN service for a specific port, over PPPoE when it has a user
+func (us *UNMClient) setExtraWanService(ctx context.Context, config OnuProvisioningConfig, service WanService, portConfig string) error {
+	command := fmt.Sprintf(SetBridgeWanCommand,
+		config.OltIP,
+		us.ponID(config.PonSlot, config.PonPort),
+		config.Serial,
+		service.Vlan,
+		portConfig,
+	)
+
+	if service.PPPoEUser != "" {
+		command = fmt.Sprintf(SetWanServiceCommand,
+			config.OltIP,
+			us.ponID(config.PonSlot, config.PonPort),
+			config.Serial,
+			service.Vlan,
+			service.PPPoEUser,
+			service.PPPoEPass,
+			service.PPPoEUser,
+			portConfig,
+		)
+	}
+
+	us.logger.WithFields(map[string]any{
+		"olt":        config.OltIP,
+		"serial":     config.Serial,
+		"service":    service.Name,
+		"portConfig": portConfig,
+		"vlan":       service.Vlan,
+	}).Debug("Configurando serviço WAN adicional")
+
+	if _, err := us.sendCommand(ctx, command); err != nil {
+		return fmt.Errorf("falha ao configurar serviço WAN: %w", err)
+	}
+
+	return nil
+}
+
 // activateLanPort activates the LAN port on the ONU
 func (us *UNMClient) activateLanPort(ctx context.Context, config OnuProvisioningConfig) error {
 	command := fmt.Sprintf(ActivateLanPortCommand,
 		config.OltIP,
-		config.PonSlot,
-		config.PonPort,
+		us.ponID(config.PonSlot, config.PonPort),
 		config.Serial,
 	)
 
@@ -483,14 +994,46 @@ func (us *UNMClient) buildONUInfoFromResponse(response string) (*OpticalNetworkU
 	}, nil
 }
 
-// splitAndTrimLines extracts non-empty, trimmed lines from input string
+// parsePonOpticalInfo maps the rows of a PON-wide LST-OMDDM response to their serials. The UNM may split
+// the rows in several blocks, each repeating the column header.
+func parsePonOpticalInfo(response string) map[string]*OpticalNetworkUnitInfo {
+	readings := make(map[string]*OpticalNetworkUnitInfo)
+
+	for _, row := range parseTable(response, "ONUID") {
+		serial := strings.ToUpper(row["ONUID"])
+		if serial == "" || serial == "ONUID" {
+			continue
+		}
+
+		readings[serial] = &OpticalNetworkUnitInfo{
+			OnuID:             row["ONUID"],
+			RxPower:           row["RXPOWER"],
+			RxPowerStatus:     row["RXPOWERR"],
+			TxPower:           row["TXPOWER"],
+			TxPowerStatus:     row["TXPOWERR"],
+			CurrTxBias:        row["CURRTXBIAS"],
+			CurrTxBiasStatus:  row["CURRTXBIASR"],
+			Temperature:       row["TEMPERATURE"],
+			TemperatureStatus: row["TEMPERATURER"],
+			Voltage:           row["VOLTAGE"],
+			VoltageStatus:     row["VOLTAGER"],
+			PTxPower:          row["PTXPOWER"],
+			PRxPower:          row["PRXPOWER"],
+		}
+	}
+
+	return readings
+}
+
+// splitAndTrimLines extracts non-empty, trimmed lines from input string.
+// Tabs are kept since they separate the columns, a row may start with an empty one such as a hidden SSID.
 func splitAndTrimLines(input string) []string {
 	lines := strings.Split(input, "\n")
 	nonEmptyLines := make([]string, 0, len(lines))
 
 	for _, line := range lines {
-		if trimmedLine := strings.TrimSpace(line); trimmedLine != "" {
-			nonEmptyLines = append(nonEmptyLines, trimmedLine)
+		if strings.TrimSpace(line) != "" {
+			nonEmptyLines = append(nonEmptyLines, strings.Trim(line, " \r"))
 		}
 	}
 
@@ -0,0 +1,142 @@
+package unm
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// DefaultWatchdogThreshold is how many commands in a row may time out before the connection is dropped
+	DefaultWatchdogThreshold = 3
+
+	watchdogRedialTimeout = 30 * time.Second
+)
+
+// WatchdogEvent describes a connection torn down by the watchdog
+type WatchdogEvent struct {
+	Endpoint    string
+	Timeouts    int
+	Reconnected bool
+	Err         error
+	FiredAt     time.Time
+}
+
+// watchdog counts the consecutive command timeouts of a connection. A half-open TCP connection keeps
+// passing the liveness check while every command times out, only this count tells it is wedged.
+type watchdog struct {
+	threshold int
+	hook      func(WatchdogEvent)
+	mu        sync.Mutex
+	timeouts  int
+	trips     atomic.Uint64
+}
+
+// SetWatchdog drops and re-dials the connection after threshold consecutive command timeouts, calling
+// the hook every time it fires. A non-positive threshold disables the watchdog.
+func (us *UNMClient) SetWatchdog(threshold int, hook func(WatchdogEvent)) {
+	us.watchdog.mu.Lock()
+	defer us.watchdog.mu.Unlock()
+
+	us.watchdog.threshold = threshold
+	us.watchdog.hook = hook
+	us.watchdog.timeouts = 0
+}
+
+// WatchdogTrips returns how many times the watchdog forced a reconnection
+func (us *UNMClient) WatchdogTrips() uint64 {
+	return us.watchdog.trips.Load()
+}
+
+// observeCommand feeds the outcome of a command to the watchdog, any answer from the server
+// proves the connection alive
+func (us *UNMClient) observeCommand(ctx context.Context, err error) {
+	if us.connecting.Load() {
+		return
+	}
+
+	us.watchdog.mu.Lock()
+
+	if us.watchdog.threshold <= 0 {
+		us.watchdog.mu.Unlock()
+		return
+	}
+
+	if !isTimeout(err) {
+		us.watchdog.timeouts = 0
+		us.watchdog.mu.Unlock()
+		return
+	}
+
+	us.watchdog.timeouts++
+	timeouts := us.watchdog.timeouts
+	if timeouts < us.watchdog.threshold {
+		us.watchdog.mu.Unlock()
+		return
+	}
+
+	us.watchdog.timeouts = 0
+	hook := us.watchdog.hook
+	us.watchdog.mu.Unlock()
+
+	us.watchdog.trips.Add(1)
+	fired := WatchdogEvent{
+		Endpoint: us.transporter.GetAddress(),
+		Timeouts: timeouts,
+		FiredAt:  time.Now(),
+	}
+
+	us.logger.WithFields(map[string]any{
+		"endpoint": fired.Endpoint,
+		"timeouts": timeouts,
+	}).Warn("Conexão TL1 travada, forçando reconexão")
+
+	// The command context has usually expired by now, the re-dial gets its own time
+	redialCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), watchdogRedialTimeout)
+	defer cancel()
+
+	fired.Err = us.redial(redialCtx)
+	fired.Reconnected = fired.Err == nil
+
+	if fired.Err != nil {
+		us.logger.WithError(fired.Err).WithField("endpoint", fired.Endpoint).Error("Falha ao reconectar ao UNM após travamento")
+	}
+
+	if hook != nil {
+		hook(fired)
+	}
+}
+
+// redial tears the connection down without the logout a wedged connection would not answer, then
+// connects and logs in again
+func (us *UNMClient) redial(ctx context.Context) error {
+	us.mtx.Lock()
+	defer us.mtx.Unlock()
+
+	us.connected = false
+	_ = us.transporter.Close()
+
+	if err := us.reconnectAndLogin(ctx); err != nil {
+		return err
+	}
+
+	us.connected = true
+	return nil
+}
+
+// isTimeout reports whether a command failed for lack of an answer, cancellations do not count
+func isTimeout(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
@@ -0,0 +1,107 @@
+package unm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const WifiScanCommand = "LST-WIFINEIGHBOR::OLTID=%s,PONID=%s,ONUIDTYPE=MAC,ONUID=%s:CTAG::;"
+
+var ErrWifiScanUnsupported = errors.New("ONU não suporta varredura de redes Wi-Fi")
+
+// WifiNeighbor is a Wi-Fi network seen by the ONT radio
+type WifiNeighbor struct {
+	SSID    string
+	BSSID   string
+	Band    string
+	Channel int
+	RSSI    int
+}
+
+// WifiScan lists the Wi-Fi networks around an ONU, as seen by its own radio
+func (us *UNMClient) WifiScan(ctx context.Context, ponSlot, ponNumber uint, olt, physicalAddr string) ([]WifiNeighbor, error) {
+	if !us.Capabilities().Has(CapabilityWifiScan) {
+		return nil, ErrWifiScanUnsupported
+	}
+
+	var neighbors []WifiNeighbor
+
+	return neighbors, us.execRetry(ctx, func(ctx context.Context) error {
+		command := fmt.Sprintf(WifiScanCommand, olt, us.ponID(ponSlot, ponNumber), physicalAddr)
+
+		response, err := us.sendCommand(ctx, command)
+		if err != nil {
+			// Models without the feature or with the radio off are refused by the UNM
+			if strings.Contains(strings.ToLower(err.Error()), "not support") {
+				return ErrWifiScanUnsupported
+			}
+			return fmt.Errorf("falha ao consultar redes Wi-Fi vizinhas: %w", err)
+		}
+
+		neighbors = parseWifiNeighbors(response)
+		return nil
+	})
+}
+
+// parseWifiNeighbors reads the neighbor table, locating the columns by the header row
+func parseWifiNeighbors(response string) []WifiNeighbor {
+	lines := splitAndTrimLines(strings.ReplaceAll(response, "\r", ""))
+
+	columns := map[string]int{}
+	var neighbors []WifiNeighbor
+
+	for _, line := range lines {
+		fields := strings.Split(line, "\t")
+
+		if len(columns) == 0 {
+			for i, field := range fields {
+				columns[strings.ToUpper(strings.TrimSpace(field))] = i
+			}
+			if _, isHeader := columns["CHANNEL"]; !isHeader {
+				clear(columns)
+			}
+			continue
+		}
+
+		channel, err := strconv.Atoi(wifiField(fields, columns, "CHANNEL"))
+		if err != nil || channel <= 0 {
+			continue
+		}
+
+		rssi, _ := strconv.Atoi(wifiField(fields, columns, "RSSI"))
+		neighbor := WifiNeighbor{
+			SSID:    wifiField(fields, columns, "SSID"),
+			BSSID:   wifiField(fields, columns, "BSSID"),
+			Band:    wifiField(fields, columns, "BAND"),
+			Channel: channel,
+			RSSI:    rssi,
+		}
+		if neighbor.Band == "" {
+			neighbor.Band = WifiBandForChannel(channel)
+		}
+
+		neighbors = append(neighbors, neighbor)
+	}
+
+	return neighbors
+}
+
+// wifiField returns a column of a table row, empty when the column is missing
+func wifiField(fields []string, columns map[string]int, name string) string {
+	index, exists := columns[name]
+	if !exists || index >= len(fields) {
+		return ""
+	}
+	return strings.TrimSpace(fields[index])
+}
+
+// WifiBandForChannel names the band of a Wi-Fi channel
+func WifiBandForChannel(channel int) string {
+	if channel <= 14 {
+		return "2.4G"
+	}
+	return "5G"
+}
@@ -0,0 +1,257 @@
+// Package validation checks the data typed by technicians and read from the ERP, returning the
+// problems found as a list the bot, the REST API and the reports can each render their own way
+package validation
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"provisioning-assistant/internal/domain/dto"
+	"provisioning-assistant/internal/pon"
+	"strconv"
+	"strings"
+)
+
+// Code classifies a problem so callers can react to it without parsing the message
+type Code string
+
+const (
+	CodeRequired   Code = "required"
+	CodeFormat     Code = "invalid_format"
+	CodeOutOfRange Code = "out_of_range"
+	CodeConflict   Code = "conflict"
+)
+
+// Fields reported in the problems
+const (
+	FieldConnection    = "connection"
+	FieldTaxID         = "cpf"
+	FieldSerial        = "serial"
+	FieldOlt           = "olt_ip"
+	FieldSlot          = "slot"
+	FieldPort          = "port"
+	FieldVlan          = "vlan"
+	FieldPPPoEUser     = "pppoe_username"
+	FieldPPPoEPassword = "pppoe_password"
+)
+
+// Problem is one reason a value was rejected
+type Problem struct {
+	Field   string `json:"field"`
+	Code    Code   `json:"code"`
+	Message string `json:"message"`
+}
+
+// Problems lists everything wrong with a value or a record, empty when it is valid
+type Problems []Problem
+
+// Error joins the messages of the problems
+func (p Problems) Error() string {
+	messages := make([]string, 0, len(p))
+	for _, problem := range p {
+		messages = append(messages, problem.Message)
+	}
+	return strings.Join(messages, "; ")
+}
+
+// Err returns the problems as an error, nil when there are none
+func (p Problems) Err() error {
+	if len(p) == 0 {
+		return nil
+	}
+	return p
+}
+
+// Has reports whether a field has a problem
+func (p Problems) Has(field string) bool {
+	for _, problem := range p {
+		if problem.Field == field {
+			return true
+		}
+	}
+	return false
+}
+
+// From extracts the problems wrapped in an error
+func From(err error) Problems {
+	var problems Problems
+	if errors.As(err, &problems) {
+		return problems
+	}
+	return nil
+}
+
+// TaxID checks that a CPF, already stripped of punctuation, has 11 digits
+func TaxID(value string) Problems {
+	switch {
+	case value == "":
+		return problem(FieldTaxID, CodeRequired, "CPF é obrigatório")
+	case len(value) != 11 || !isDigits(value):
+		return problem(FieldTaxID, CodeFormat, "CPF deve ter 11 dígitos")
+	}
+	return nil
+}
+
+// Serial checks that an ONU serial has 8 to 16 alphanumeric characters
+func Serial(value string) Problems {
+	if value == "" {
+		return problem(FieldSerial, CodeRequired, "número de série do equipamento é obrigatório")
+	}
+
+	valid := len(value) >= 8 && len(value) <= 16
+	for _, r := range value {
+		if !(r >= '0' && r <= '9' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z') {
+			valid = false
+		}
+	}
+
+	if !valid {
+		return problem(FieldSerial, CodeFormat, "número de série deve ter de 8 a 16 letras ou números")
+	}
+	return nil
+}
+
+// OltIP checks that the OLT address is an IP
+func OltIP(value string) Problems {
+	switch {
+	case value == "":
+		return problem(FieldOlt, CodeRequired, "IP da OLT é obrigatório")
+	case net.ParseIP(value) == nil:
+		return problem(FieldOlt, CodeFormat, fmt.Sprintf("IP da OLT inválido: %s", value))
+	}
+	return nil
+}
+
+// Vlan checks that a VLAN ID is between 1 and 4094
+func Vlan(value string) Problems {
+	if value == "" {
+		return problem(FieldVlan, CodeRequired, "VLAN é obrigatória")
+	}
+
+	vlan, err := strconv.Atoi(value)
+	switch {
+	case err != nil:
+		return problem(FieldVlan, CodeFormat, fmt.Sprintf("VLAN não numérica: %s", value))
+	case vlan < 1 || vlan > 4094:
+		return problem(FieldVlan, CodeOutOfRange, fmt.Sprintf("VLAN fora do intervalo 1-4094: %s", value))
+	}
+	return nil
+}
+
+// PPPoEUser checks that the PPPoE username is filled and has no spaces
+func PPPoEUser(value string) Problems {
+	return credential(FieldPPPoEUser, value, "usuário PPPoE é obrigatório", "usuário PPPoE não pode conter espaços")
+}
+
+// PPPoEPassword checks that the PPPoE password is filled and has no spaces
+func PPPoEPassword(value string) Problems {
+	return credential(FieldPPPoEPassword, value, "senha PPPoE é obrigatória", "senha PPPoE não pode conter espaços")
+}
+
+// SlotPort checks the slot and port of a PON interface as the ERP or the technician wrote them
+func SlotPort(slot, port string) Problems {
+	if _, err := pon.ParseSlotPort(slot, port); err != nil {
+		return fromPon(err)
+	}
+	return nil
+}
+
+// Slot checks a single slot value
+func Slot(value string) Problems {
+	if _, err := pon.ParseSlot(value); err != nil {
+		return fromPon(err)
+	}
+	return nil
+}
+
+// Port checks a single port value
+func Port(value string) Problems {
+	if _, err := pon.ParsePort(value); err != nil {
+		return fromPon(err)
+	}
+	return nil
+}
+
+// ErpConnection checks that the ERP returned the ONU location the UNM needs
+func ErpConnection(info *dto.ConnectionInfo) Problems {
+	if info == nil {
+		return problem(FieldConnection, CodeRequired, "informações de conexão ausentes")
+	}
+
+	var problems Problems
+	if info.ConnectionOltIP == "" {
+		problems = append(problems, Problem{FieldOlt, CodeRequired, "IP da OLT ausente"})
+	}
+	if info.ConnectionEquipmentSerialNumber == "" {
+		problems = append(problems, Problem{FieldSerial, CodeRequired, "número de série do equipamento ausente"})
+	}
+	return problems
+}
+
+// Provisioning checks that a connection carries everything a provisioning sends to the OLT
+func Provisioning(info *dto.ConnectionInfo) Problems {
+	if info == nil {
+		return problem(FieldConnection, CodeRequired, "informações de conexão são nulas")
+	}
+
+	var problems Problems
+	if info.ConnectionOltIP == "" {
+		problems = append(problems, Problem{FieldOlt, CodeRequired, "IP da OLT é obrigatório"})
+	}
+	if info.ConnectionEquipmentSerialNumber == "" {
+		problems = append(problems, Problem{FieldSerial, CodeRequired, "número de série do equipamento é obrigatório"})
+	}
+	if info.ConnectionClientPPPoEUsername == "" {
+		problems = append(problems, Problem{FieldPPPoEUser, CodeRequired, "nome de usuário PPPoE é obrigatório"})
+	}
+	if info.ConnectionClientPPPoEPassword == "" {
+		problems = append(problems, Problem{FieldPPPoEPassword, CodeRequired, "senha PPPoE é obrigatória"})
+	}
+	return problems
+}
+
+// credential checks a PPPoE credential
+func credential(field, value, required, spaced string) Problems {
+	switch {
+	case value == "":
+		return problem(field, CodeRequired, required)
+	case strings.ContainsAny(value, " \t"):
+		return problem(field, CodeFormat, spaced)
+	}
+	return nil
+}
+
+// fromPon converts a slot or port parsing error into a problem
+func fromPon(err error) Problems {
+	var fieldErr *pon.FieldError
+	if !errors.As(err, &fieldErr) {
+		return problem(FieldSlot, CodeFormat, err.Error())
+	}
+
+	field := FieldSlot
+	if fieldErr.Field == "porta" {
+		field = FieldPort
+	}
+
+	code := CodeFormat
+	switch {
+	case errors.Is(err, pon.ErrEmpty):
+		code = CodeRequired
+	case errors.Is(err, pon.ErrOutOfRange):
+		code = CodeOutOfRange
+	case errors.Is(err, pon.ErrConflict):
+		code = CodeConflict
+	}
+
+	return problem(field, code, fieldErr.Error())
+}
+
+// problem returns a list with a single problem
+func problem(field string, code Code, message string) Problems {
+	return Problems{{Field: field, Code: code, Message: message}}
+}
+
+// isDigits reports whether a value has only ASCII digits
+func isDigits(value string) bool {
+	return strings.Trim(value, "0123456789") == ""
+}